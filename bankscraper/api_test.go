@@ -0,0 +1,170 @@
+package bankscraper
+
+import (
+	"flag"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/api.txt from the current API")
+
+// apiGolden lists the package's exported API, one symbol per line, as
+// apiSymbols renders it.
+var apiGolden = filepath.Join("testdata", "api.txt")
+
+// TestAPICompatibility guards downstream importers against accidental
+// breakage: it fails when an exported symbol of this package, or of a type
+// it exposes, is removed or changes (renamed field, new parameter, new
+// interface method, ...). Intended changes are recorded by rewriting the
+// golden list with -update, so they show up in review.
+func TestAPICompatibility(t *testing.T) {
+	got := apiSymbols(t)
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(apiGolden), 0o755))
+		require.NoError(t, os.WriteFile(apiGolden, []byte(strings.Join(got, "\n")+"\n"), 0o644))
+		return
+	}
+
+	data, err := os.ReadFile(apiGolden)
+	require.NoError(t, err)
+	want := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	var removed, added []string
+	for _, line := range want {
+		if !slices.Contains(got, line) {
+			removed = append(removed, line)
+		}
+	}
+	for _, line := range got {
+		if !slices.Contains(want, line) {
+			added = append(added, line)
+		}
+	}
+	const hint = "if intended, run go test ./bankscraper -run TestAPICompatibility -update"
+	assert.Empty(t, removed, "exported API removed or changed incompatibly; this breaks importers. "+hint)
+	assert.Empty(t, added, "exported API added; "+hint)
+}
+
+// apiSymbols type-checks the package and renders its exported API: every
+// exported constant, variable, function and type, and the exported fields
+// and methods of the types it exposes, following them into the internal
+// packages the aliases point at.
+func apiSymbols(t *testing.T) []string {
+	t.Helper()
+	pkg, err := importer.ForCompiler(token.NewFileSet(), "source", nil).Import("github.com/aynifx/bank-scraper/bankscraper")
+	require.NoError(t, err)
+
+	qual := func(p *types.Package) string { return p.Name() }
+	module := strings.TrimSuffix(pkg.Path(), "/bankscraper")
+
+	var out []string
+	seen := map[*types.Named]bool{}
+	var queue []*types.Named
+	// expose queues the module's exported named types found in typ.
+	var expose func(typ types.Type)
+	expose = func(typ types.Type) {
+		switch typ := types.Unalias(typ).(type) {
+		case *types.Named:
+			obj := typ.Obj()
+			if obj.Exported() && obj.Pkg() != nil && strings.HasPrefix(obj.Pkg().Path(), module) && !seen[typ] {
+				seen[typ] = true
+				queue = append(queue, typ)
+			}
+		case *types.Pointer:
+			expose(typ.Elem())
+		case *types.Slice:
+			expose(typ.Elem())
+		case *types.Array:
+			expose(typ.Elem())
+		case *types.Map:
+			expose(typ.Key())
+			expose(typ.Elem())
+		case *types.Signature:
+			for i := range typ.Params().Len() {
+				expose(typ.Params().At(i).Type())
+			}
+			for i := range typ.Results().Len() {
+				expose(typ.Results().At(i).Type())
+			}
+		}
+	}
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		typ := types.TypeString(obj.Type(), qual)
+		switch obj := obj.(type) {
+		case *types.Const:
+			out = append(out, fmt.Sprintf("const %s %s = %s", name, typ, obj.Val()))
+		case *types.Var:
+			out = append(out, fmt.Sprintf("var %s %s", name, typ))
+		case *types.Func:
+			out = append(out, fmt.Sprintf("func %s%s", name, strings.TrimPrefix(typ, "func")))
+		case *types.TypeName:
+			if obj.IsAlias() {
+				out = append(out, fmt.Sprintf("type %s = %s", name, types.TypeString(types.Unalias(obj.Type()), qual)))
+			} else {
+				out = append(out, fmt.Sprintf("type %s %s", name, types.TypeString(obj.Type().Underlying(), qual)))
+			}
+		}
+		expose(obj.Type())
+	}
+
+	for len(queue) > 0 {
+		named := queue[0]
+		queue = queue[1:]
+		name := types.TypeString(named, qual)
+		switch u := named.Underlying().(type) {
+		case *types.Struct:
+			for i := range u.NumFields() {
+				f := u.Field(i)
+				if !f.Exported() {
+					continue
+				}
+				line := fmt.Sprintf("field %s.%s %s", name, f.Name(), types.TypeString(f.Type(), qual))
+				if tag := u.Tag(i); tag != "" {
+					line += " `" + tag + "`"
+				}
+				out = append(out, line)
+				expose(f.Type())
+			}
+		case *types.Interface:
+			for i := range u.NumMethods() {
+				m := u.Method(i)
+				if m.Exported() {
+					out = append(out, fmt.Sprintf("method %s.%s%s", name, m.Name(), strings.TrimPrefix(types.TypeString(m.Type(), qual), "func")))
+					expose(m.Type())
+				}
+			}
+			continue
+		default:
+			out = append(out, fmt.Sprintf("underlying %s %s", name, types.TypeString(u, qual)))
+			expose(u)
+		}
+		methods := types.NewMethodSet(types.NewPointer(named))
+		for i := range methods.Len() {
+			m := methods.At(i).Obj()
+			if m.Exported() {
+				out = append(out, fmt.Sprintf("method %s.%s%s", name, m.Name(), strings.TrimPrefix(types.TypeString(m.Type(), qual), "func")))
+				expose(m.Type())
+			}
+		}
+	}
+
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,224 @@
+// Package bankscraper is the supported public API of the bank scraper.
+//
+// Everything else in this module lives under internal/ and may change without
+// notice. External programs should import this package instead: it re-exports
+// the bank-agnostic types (Scraper, Balance, Transaction, ...), the sentinel
+// errors, and a constructor that builds the right scraper for a bank code.
+//
+//	s, err := bankscraper.New(bankscraper.BankBBVA, bankscraper.WithTimeout(time.Minute))
+//	if err != nil { ... }
+//	defer s.Close()
+//	session, err := s.Login(ctx, map[string]string{"company_code": ..., "user_code": ..., "password": ...})
+//
+// To run the browser in a container instead of on the host, start
+// browserless (docker compose --profile browser up) and connect to it:
+//
+//	s, err := bankscraper.NewBBVAScraperRemote("ws://localhost:3000", bankscraper.WithTimeout(time.Minute))
+package bankscraper
+
+import (
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/bbva"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/factory"
+)
+
+// Core types. These are aliases, so values are interchangeable with the
+// internal bank package and no conversion is needed.
+type (
+	// Scraper is the bank-agnostic scraping interface (see bank.Scraper).
+	Scraper = bank.Scraper
+	// ScraperFactory creates a Scraper for a bank code.
+	ScraperFactory = bank.ScraperFactory
+	// Session is an authenticated bank session.
+	Session = bank.Session
+	// Balance is the balance of one account, in cents.
+	Balance = bank.Balance
+	// Transaction is a single account movement, in cents.
+	Transaction = bank.Transaction
+	// Code identifies a supported bank.
+	Code = bank.Code
+	// Currency is a monetary currency code.
+	Currency = bank.Currency
+	// TransactionType is CREDIT (money in) or DEBIT (money out).
+	TransactionType = bank.TransactionType
+	// ScraperError carries the bank, operation and cause of a failure.
+	ScraperError = bank.ScraperError
+	// Document is the versioned envelope for serialized scrape output.
+	Document = bank.Document
+	// ScheduledOperation is a future-dated transfer, direct debit or payment.
+	ScheduledOperation = bank.ScheduledOperation
+	// ScheduledKind classifies a ScheduledOperation.
+	ScheduledKind = bank.ScheduledKind
+	// ScheduledOperationsScraper is implemented by scrapers that can list
+	// scheduled operations; type-assert a Scraper to check for support.
+	ScheduledOperationsScraper = bank.ScheduledOperationsScraper
+	// PageCapturer is implemented by scrapers that can return a portal
+	// page's flattened HTML and screenshot; type-assert a Scraper to check
+	// for support.
+	PageCapturer = bank.PageCapturer
+	// BBVAMeta is the typed view of a BBVA transaction's Extra metadata;
+	// see Transaction.BBVAMeta.
+	BBVAMeta = bank.BBVAMeta
+	// SortField orders transactions by one key; see SortTransactions.
+	SortField = bank.SortField
+	// Enricher post-processes each transaction a scraper returns; see
+	// WithEnrichers.
+	Enricher = bank.Enricher
+	// EnricherFunc adapts a function to Enricher.
+	EnricherFunc = bank.EnricherFunc
+)
+
+// SchemaVersion is the data schema version written by this build.
+const SchemaVersion = schema.Version
+
+// Supported bank codes.
+const (
+	BankBBVA      = bank.BankBBVA
+	BankInterbank = bank.BankInterbank
+	BankBCP       = bank.BankBCP
+)
+
+// Supported currencies.
+const (
+	CurrencyUSD = bank.CurrencyUSD
+	CurrencyPEN = bank.CurrencyPEN
+)
+
+// Transaction types.
+const (
+	TransactionCredit = bank.TransactionCredit
+	TransactionDebit  = bank.TransactionDebit
+)
+
+// Scheduled operation kinds.
+const (
+	ScheduledTransfer    = bank.ScheduledTransfer
+	ScheduledDirectDebit = bank.ScheduledDirectDebit
+	ScheduledPayment     = bank.ScheduledPayment
+	ScheduledOther       = bank.ScheduledOther
+)
+
+// Pages for PageCapturer.CapturePage.
+const (
+	PageCurrent   = bank.PageCurrent
+	PageAccounts  = bank.PageAccounts
+	PageScheduled = bank.PageScheduled
+)
+
+// Transaction sort keys.
+const (
+	SortByDate      = bank.SortByDate
+	SortByValueDate = bank.SortByValueDate
+	SortByID        = bank.SortByID
+	SortByAmount    = bank.SortByAmount
+)
+
+// Sentinel errors. Match them with errors.Is; ScraperError unwraps to its cause.
+var (
+	ErrInvalidCredentials = bank.ErrInvalidCredentials
+	ErrSessionExpired     = bank.ErrSessionExpired
+	ErrBankUnavailable    = bank.ErrBankUnavailable
+	ErrAccountNotFound    = bank.ErrAccountNotFound
+	ErrBotDetection       = bank.ErrBotDetection
+	ErrUnknown            = bank.ErrUnknown
+	ErrParsingFailed      = bank.ErrParsingFailed
+	ErrTimeout            = bank.ErrTimeout
+	ErrUnsupportedPage    = bank.ErrUnsupportedPage
+)
+
+// SortTransactions sorts txns in place by order. Without an order it uses
+// the one scrapers return: newest first, then highest document number.
+func SortTransactions(txns []Transaction, order ...SortField) {
+	bank.SortTransactions(txns, order...)
+}
+
+// NewDocument wraps scrape output in an envelope stamped with SchemaVersion.
+func NewDocument(code Code, balances []Balance, transactions []Transaction) *Document {
+	return bank.NewDocument(code, balances, transactions)
+}
+
+// DecodeDocument parses a serialized Document, migrating older schema
+// versions to SchemaVersion first.
+func DecodeDocument(data []byte) (*Document, error) {
+	return bank.DecodeDocument(data)
+}
+
+const defaultTimeout = 30 * time.Second
+
+// options holds the bank-agnostic construction settings.
+type options struct {
+	timeout   time.Duration
+	headless  bool
+	enrichers []Enricher
+}
+
+// Option configures scrapers built by New and NewFactory.
+type Option func(*options)
+
+// WithTimeout sets the per-operation timeout. Default is 30s.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithHeadless controls whether the browser launches in headless mode.
+// Default is true. Set to false for visual debugging.
+func WithHeadless(headless bool) Option {
+	return func(o *options) {
+		o.headless = headless
+	}
+}
+
+// WithEnrichers runs enrichers, in order, over every transaction
+// GetTransactions returns, e.g. to look up merchants or tag projects. A
+// failing enricher keeps the transaction as enriched so far.
+func WithEnrichers(enrichers ...Enricher) Option {
+	return func(o *options) {
+		o.enrichers = append(o.enrichers, enrichers...)
+	}
+}
+
+func buildOptions(opts []Option) options {
+	o := options{
+		timeout:  defaultTimeout,
+		headless: true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// NewFactory returns a ScraperFactory that builds scrapers with the given options.
+// Useful when the caller creates scrapers lazily, one per bank.
+func NewFactory(opts ...Option) ScraperFactory {
+	o := buildOptions(opts)
+	return factory.New(o.timeout, o.headless, factory.WithEnrichers(o.enrichers...))
+}
+
+// New creates a scraper for the given bank. The browser is launched immediately;
+// call Close on the returned Scraper to release it.
+func New(code Code, opts ...Option) (Scraper, error) {
+	return NewFactory(opts...)(code)
+}
+
+// NewBBVAScraperRemote creates a BBVA scraper on a containerized Chrome
+// (browserless or alpine-chrome) at wsURL instead of launching one. Pages get
+// a 1920x1080 viewport and Lima's timezone, whatever the container's. Only
+// WithTimeout and WithEnrichers apply; call Close to end the remote session.
+func NewBBVAScraperRemote(wsURL string, opts ...Option) (Scraper, error) {
+	o := buildOptions(opts)
+	s, err := bbva.NewScraper(
+		bbva.WithTimeout(o.timeout),
+		bbva.WithRemoteBrowser(browser.Remote{URL: wsURL}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return bank.Enrich(bank.BankBBVA, s, o.enrichers...), nil
+}
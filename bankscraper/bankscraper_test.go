@@ -0,0 +1,52 @@
+package bankscraper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases_InterchangeableWithInternalTypes(t *testing.T) {
+	// A value of the internal type must be assignable to the public alias
+	// (and vice versa) without conversion.
+	var s Scraper = &banktest.MockScraper{}
+	var _ bank.Scraper = s
+
+	tx := Transaction{Amount: 100, Type: TransactionCredit}
+	var internal bank.Transaction = tx
+	assert.Equal(t, bank.TransactionCredit, internal.Type)
+}
+
+func TestErrors_MatchInternalSentinels(t *testing.T) {
+	err := &ScraperError{Code: BankBBVA, Operation: "Login", Cause: bank.ErrInvalidCredentials}
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+}
+
+func TestBuildOptions_Defaults(t *testing.T) {
+	o := buildOptions(nil)
+	assert.Equal(t, defaultTimeout, o.timeout)
+	assert.True(t, o.headless)
+
+	o = buildOptions([]Option{WithTimeout(time.Minute), WithHeadless(false)})
+	assert.Equal(t, time.Minute, o.timeout)
+	assert.False(t, o.headless)
+}
+
+func TestNew_UnsupportedBank(t *testing.T) {
+	s, err := New(Code("NOPE"))
+	require.Error(t, err)
+	assert.Nil(t, s)
+	assert.Contains(t, err.Error(), "unsupported bank")
+}
+
+func TestNewBBVAScraperRemote_Unreachable(t *testing.T) {
+	s, err := NewBBVAScraperRemote("ws://127.0.0.1:1")
+	require.Error(t, err)
+	assert.Nil(t, s)
+	assert.Contains(t, err.Error(), "connect to remote browser")
+}
@@ -5,6 +5,11 @@
 //	api serve           Start the HTTP server
 //	api create-key      Create an API key
 //	api discover        Trigger account discovery for a bank
+//	api forecast        Print an N-day cash position forecast
+//	api openapi         Print the OpenAPI 3 document for client generation
+//	api set-priority    Mark an account high or normal scrape priority
+//	api backup          Write the store and configuration to an encrypted archive
+//	api restore         Load an archive written by backup into an empty store
 //	api migrate         Run all pending database migrations
 //	api migrate-down    Rollback the last migration
 //	api version         Show the current migration version
@@ -15,26 +20,44 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/aynifx/bank-scraper/internal/api"
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/api/graphql"
 	"github.com/aynifx/bank-scraper/internal/api/handler"
+	"github.com/aynifx/bank-scraper/internal/api/jobs"
+	"github.com/aynifx/bank-scraper/internal/api/middleware"
+	"github.com/aynifx/bank-scraper/internal/api/notify"
 	"github.com/aynifx/bank-scraper/internal/api/resilience"
 	"github.com/aynifx/bank-scraper/internal/api/service"
 	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/aynifx/bank-scraper/internal/calendar"
 	"github.com/aynifx/bank-scraper/internal/config"
 	"github.com/aynifx/bank-scraper/internal/credmgr/crypto"
 	credservice "github.com/aynifx/bank-scraper/internal/credmgr/service"
+	"github.com/aynifx/bank-scraper/internal/export"
+	"github.com/aynifx/bank-scraper/internal/logging"
+	"github.com/aynifx/bank-scraper/internal/pipeline"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	scraperfactory "github.com/aynifx/bank-scraper/internal/scraper/factory"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
 	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 )
@@ -43,12 +66,23 @@ func main() {
 	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
 		log.Fatalf("failed to load .env: %v", err)
 	}
+	defer logging.Init()()
 
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
+	// Needs no configuration, so it works without a database.
+	if os.Args[1] == "openapi" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(api.OpenAPI()); err != nil {
+			log.Fatalf("openapi failed: %v", err)
+		}
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
@@ -84,6 +118,26 @@ func main() {
 			log.Fatalf("discover failed: %v", err)
 		}
 
+	case "forecast":
+		if err := forecast(cfg); err != nil {
+			log.Fatalf("forecast failed: %v", err)
+		}
+
+	case "set-priority":
+		if err := setPriority(cfg); err != nil {
+			log.Fatalf("set-priority failed: %v", err)
+		}
+
+	case "backup":
+		if err := backup(cfg); err != nil {
+			log.Fatalf("backup failed: %v", err)
+		}
+
+	case "restore":
+		if err := restore(cfg); err != nil {
+			log.Fatalf("restore failed: %v", err)
+		}
+
 	case "serve":
 		if err := serve(cfg); err != nil {
 			log.Fatalf("serve failed: %v", err)
@@ -99,11 +153,64 @@ func main() {
 func serve(cfg *config.Config) error {
 	logger := slog.Default()
 
-	mk, err := crypto.ParseMasterKey(cfg.EncryptionKey)
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
 	if err != nil {
 		return fmt.Errorf("parse encryption key: %w", err)
 	}
 
+	dedup, err := store.ParseDedupConfig(cfg.DedupStrategies)
+	if err != nil {
+		return fmt.Errorf("DEDUP_STRATEGIES: %w", err)
+	}
+	aliases, err := store.ParseAccountAliases(cfg.AccountAliases)
+	if err != nil {
+		return fmt.Errorf("ACCOUNT_ALIASES: %w", err)
+	}
+	profiles, err := store.ParseProfiles(cfg.Profiles)
+	if err != nil {
+		return fmt.Errorf("PROFILES: %w", err)
+	}
+	for p := range cfg.ProfileScrapeIntervals {
+		if !slices.Contains(profiles, p) {
+			return fmt.Errorf("PROFILE_SCRAPE_INTERVALS: profile %q is not in PROFILES", p)
+		}
+	}
+	holidays, err := calendar.ForCountry(cfg.ScrapeCalendar, cfg.ScrapeHolidays...)
+	if err != nil {
+		return fmt.Errorf("SCRAPE_CALENDAR: %w", err)
+	}
+	channels, err := notify.ParseChannels(cfg.NotifyWebhooks, cfg.NotifyDigest)
+	if err != nil {
+		return fmt.Errorf("NOTIFY_WEBHOOKS: %w", err)
+	}
+	gl, err := export.ParseGLMapping(cfg.GLAccounts, cfg.GLCategories)
+	if err != nil {
+		return fmt.Errorf("GL_ACCOUNTS/GL_CATEGORIES: %w", err)
+	}
+	slos, err := notify.ParseFreshnessSLOs(cfg.FreshnessSLOs)
+	if err != nil {
+		return fmt.Errorf("FRESHNESS_SLOS: %w", err)
+	}
+	scrapeHooks, err := middleware.ParseWebhooks(cfg.ScrapeHooks, cfg.ScrapeHookProfiles)
+	if err != nil {
+		return fmt.Errorf("SCRAPE_HOOKS: %w", err)
+	}
+	scrapeLimiter, err := middleware.NewRateLimiter(cfg.APIScrapeRateLimit, cfg.APIScrapeRateWindow)
+	if err != nil {
+		return fmt.Errorf("API_SCRAPE_RATE_LIMIT/API_SCRAPE_RATE_WINDOW: %w", err)
+	}
+	var steps *pipeline.Config
+	if cfg.PipelineConfig != "" {
+		if steps, err = pipeline.Load(cfg.PipelineConfig); err != nil {
+			return fmt.Errorf("PIPELINE_CONFIG: %w", err)
+		}
+		for p := range steps.Profiles {
+			if !slices.Contains(profiles, p) {
+				return fmt.Errorf("PIPELINE_CONFIG: profile %q is not in PROFILES", p)
+			}
+		}
+	}
+
 	db, err := connectDB(cfg.DatabaseURL)
 	if err != nil {
 		return err
@@ -113,15 +220,26 @@ func serve(cfg *config.Config) error {
 	pool := db.Pool()
 
 	// Repositories
-	accountRepo := store.NewAccountRepo(pool)
+	accountRepo := store.WithAccountAliases(store.NewAccountRepo(pool), aliases)
 	credRepo := store.NewCredentialRepo(pool)
 	apiKeyRepo := store.NewAPIKeyRepo(pool)
 
 	// Credential service (read-only — used as CredentialProvider)
-	credSvc := newCredService(pool, mk, logger)
+	credSvc := newCredService(pool, keys, logger)
+
+	// Step latencies learned by earlier runs size the scrapers' waits
+	timingRepo := store.NewTimingProfileRepo(pool)
+	learned, err := timingRepo.Load(context.Background())
+	if err != nil {
+		return err
+	}
+	timings := bank.NewTimings(learned)
 
 	// Scraper factory
-	factory := scraperfactory.New(cfg.ScraperTimeout, cfg.ScraperHeadless)
+	factory, err := scraperfactory.NewFromConfig(cfg, scraperfactory.WithTimings(timings))
+	if err != nil {
+		return err
+	}
 
 	// Session manager
 	sessionMgr := session.NewManager(credSvc, factory, logger)
@@ -141,16 +259,162 @@ func serve(cfg *config.Config) error {
 	})
 	resilientProvider := resilience.NewResilientProvider(sessionMgr, retryCfg, breakers)
 
+	// Event stream: every fetch through the provider publishes scrape events
+	eventHub := events.NewHub(events.DefaultHistory)
+	scrapers := events.NewProvider(resilientProvider, eventHub).WithDedup(dedup).WithAliases(aliases)
+
+	// Reports read through the same provider as the data endpoints
+	forecastSvc := service.NewForecastService(accountRepo, scrapers, logger)
+
+	txRepo := store.NewTransactionRepo(pool)
+	if cfg.StoreEncryption {
+		txRepo = store.NewEncryptedTransactionRepo(pool, func(profile string) store.FieldCipher {
+			return keys.FieldCipher(profile)
+		})
+	}
+
+	// Exports read stored data on their own schedule
+	var sinks []export.Sink
+	if cfg.ExportCSVDir != "" {
+		sinks = append(sinks, export.CSVDir{Dir: cfg.ExportCSVDir, GL: gl, FullAccountIDs: cfg.FullAccountIDs})
+	}
+	if cfg.ExportParquetDir != "" {
+		sinks = append(sinks, export.ParquetDir{Dir: cfg.ExportParquetDir, GL: gl, FullAccountIDs: cfg.FullAccountIDs})
+	}
+	if cfg.FireflyURL != "" {
+		firefly, err := export.NewFirefly(cfg.FireflyURL, cfg.FireflyToken, cfg.FireflyAccounts)
+		if err != nil {
+			return fmt.Errorf("FIREFLY_URL/FIREFLY_TOKEN/FIREFLY_ACCOUNTS: %w", err)
+		}
+		sinks = append(sinks, firefly)
+	}
+	exporters := map[string][]*export.Exporter{}
+	for _, sink := range sinks {
+		for _, p := range profiles {
+			exporters[p] = append(exporters[p], export.NewExporter(sink, accountRepo, txRepo, logger).WithProfile(p))
+		}
+	}
+
+	// Scrape jobs (API and scheduler) run on a worker pool through the same
+	// provider, and through the pipeline when one is configured
+	queueOpts := []jobs.Option{
+		jobs.WithWorkers(cfg.ScrapeWorkers),
+		jobs.WithBankConcurrency(cfg.ScrapeBankConcurrency, cfg.ScrapeBankLimits),
+		jobs.WithPublisher(eventHub),
+		jobs.WithRawHTML(cfg.ScraperRawHTML),
+		jobs.WithRetention(cfg.ScrapeJobRetention),
+		jobs.WithLogger(logger),
+	}
+	var jobScrapers jobs.ScraperSource = scrapers
+	if steps != nil {
+		gapThreshold, err := parseutil.ParseAmount(cfg.BackfillGapThreshold)
+		if err != nil {
+			return fmt.Errorf("BACKFILL_GAP_THRESHOLD: %w", err)
+		}
+		p, err := pipeline.New(steps, map[string]pipeline.Step{
+			pipeline.StepScrape:     pipeline.Scrape(),
+			pipeline.StepBackfill:   pipeline.Backfill(txRepo, dedup, gapThreshold),
+			pipeline.StepDedupe:     pipeline.Dedupe(txRepo, dedup),
+			pipeline.StepCategorize: pipeline.Categorize(),
+			pipeline.StepEnrich:     pipeline.Enrich(),
+			pipeline.StepStore:      pipeline.Store(txRepo, dedup),
+			pipeline.StepExport: pipeline.Export(func(ctx context.Context, profile string) error {
+				var errs []error
+				for _, e := range exporters[profile] {
+					errs = append(errs, e.Run(ctx))
+				}
+				return errors.Join(errs...)
+			}),
+			pipeline.StepNotify: pipeline.Notify(eventHub, aliases),
+		})
+		if err != nil {
+			return fmt.Errorf("PIPELINE_CONFIG: %w", err)
+		}
+		queueOpts = append(queueOpts, jobs.WithPipeline(p))
+		// The notify step announces new transactions, against the store
+		jobScrapers = events.NewProvider(resilientProvider, eventHub).WithAliases(aliases).WithNewTransactions(false)
+	}
+	var jobStore store.ScrapeJobRepository
+	switch cfg.ScrapeJobStore {
+	case "memory":
+		jobStore = jobs.NewMemoryStore()
+	case "postgres":
+		jobStore = store.NewScrapeJobRepo(pool)
+	default:
+		return fmt.Errorf("unknown SCRAPE_JOB_STORE %q (use memory or postgres)", cfg.ScrapeJobStore)
+	}
+	jobQueue := jobs.NewQueue(jobStore, accountRepo, jobScrapers, queueOpts...)
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if err := jobQueue.Start(jobsCtx); err != nil {
+		return fmt.Errorf("start scrape queue: %w", err)
+	}
+	for _, p := range profiles {
+		interval := cfg.ScrapeInterval
+		if d, ok := cfg.ProfileScrapeIntervals[p]; ok {
+			interval = d
+		}
+		go jobs.NewScheduler(jobQueue, accountRepo, interval, logger).WithProfile(p).
+			WithCalendar(holidays, cfg.ScrapeQuietInterval).Run(jobsCtx)
+	}
+
+	go func() {
+		ticker := time.NewTicker(timingsSaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobsCtx.Done():
+				return
+			case <-ticker.C:
+				saveTimings(jobsCtx, timingRepo, timings, logger)
+			}
+		}
+	}()
+
+	// Scheduled exports, whatever the pipeline's export step does
+	for _, p := range profiles {
+		for _, exporter := range exporters[p] {
+			go exporter.RunEvery(jobsCtx, cfg.ExportInterval)
+		}
+	}
+
+	// New transactions go out to webhooks, instantly or as digests, along
+	// with staleness alerts
+	notifier := notify.NewNotifier(eventHub, channels, logger).WithFullAccountIDs(cfg.FullAccountIDs)
+	if cfg.NotifyAnomalies {
+		notifier.WithAnomalyDetection(report.NewAnomalyDetector()).
+			WithAnomalyHistory(notify.AnomalyHistory{Accounts: accountRepo, Transactions: txRepo, Profiles: profiles})
+	}
+	if len(slos) > 0 {
+		notifier.WithFreshness(notify.Freshness{Accounts: accountRepo, SLOs: slos, Profiles: profiles, Calendar: holidays})
+	}
+	notifyDone := notifier.Start(context.Background())
+
+	var gqlSchema *graphql.Schema
+	if cfg.APIGraphQL {
+		gqlSchema = graphql.NewSchema(accountRepo, txRepo)
+	}
+
 	// Router
 	router := api.SetupRouter(api.RouterDeps{
-		AccountRepo: accountRepo,
-		APIKeyRepo:  apiKeyRepo,
-		CredRepo:    credRepo,
-		Scrapers:    resilientProvider,
-		Discovery:   discoverySvc,
-		Creds:       credSvc,
-		PingDB:      func() error { return db.Ping(context.Background()) },
-		Sessions:    sessionMgr,
+		AccountRepo:   accountRepo,
+		APIKeyRepo:    apiKeyRepo,
+		CredRepo:      credRepo,
+		Scrapers:      scrapers,
+		Discovery:     discoverySvc,
+		Creds:         credSvc,
+		PingDB:        func() error { return db.Ping(context.Background()) },
+		Sessions:      sessionMgr,
+		Forecaster:    forecastSvc,
+		Events:        eventHub,
+		Jobs:          jobQueue,
+		JobStats:      jobQueue,
+		Timings:       timings,
+		Audit:         credservice.NewAuditWriter(store.NewAuditLogRepo(pool), logger),
+		ScrapeLimiter: scrapeLimiter,
+		Dashboard:     cfg.APIDashboard,
+		GraphQL:       gqlSchema,
+		Webhooks:      scrapeHooks,
 	})
 
 	// Start server with graceful shutdown
@@ -182,24 +446,57 @@ func serve(cfg *config.Config) error {
 		logger.Info("shutting down", slog.String("signal", sig.String()))
 	}
 
-	// Graceful shutdown: drain HTTP requests first, then close scrapers
+	// Graceful shutdown: end event streams, drain HTTP requests, stop scrape
+	// jobs, then close scrapers
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	eventHub.Close()
+	<-notifyDone // sends pending digests
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server shutdown error", slog.Any("error", err))
 	}
+	stopJobs()
+	jobQueue.Wait()
 	sessionMgr.Shutdown(shutdownCtx)
+	saveTimings(shutdownCtx, timingRepo, timings, logger)
 
 	logger.Info("API gateway stopped")
 	return nil
 }
 
+// timingsSaveInterval is how often the learned step latencies are persisted.
+const timingsSaveInterval = 5 * time.Minute
+
+// saveTimings persists the timing profiles observed since the last save.
+func saveTimings(ctx context.Context, repo store.TimingProfileRepository, timings *bank.Timings, logger *slog.Logger) {
+	for code, profile := range timings.Changed() {
+		if err := repo.Save(ctx, code, profile); err != nil {
+			logger.Warn("save timing profile", slog.String("bank", string(code)), slog.Any("error", err))
+		}
+	}
+}
+
 func createKey(cfg *config.Config) error {
 	clientID := parseFlag("--client-id")
 	description := parseFlag("--description")
 	if clientID == "" {
-		return fmt.Errorf("--client-id is required\nUsage: api create-key --client-id=<id> [--description=<desc>]")
+		return fmt.Errorf("--client-id is required\nUsage: api create-key --client-id=<id> [--description=<desc>] [--scopes=read,scrape,admin] [--profile=<name>]")
+	}
+	profile := store.DefaultProfile
+	if p := parseFlag("--profile"); p != "" {
+		if err := store.ValidateProfile(p); err != nil {
+			return err
+		}
+		profile = p
+	}
+	scopesFlag := parseFlag("--scopes")
+	if scopesFlag == "" {
+		scopesFlag = store.APIKeyScopeRead
+	}
+	scopes, err := store.ParseAPIKeyScopes(scopesFlag)
+	if err != nil {
+		return err
 	}
 
 	// Generate random 32-byte key, display as hex
@@ -226,7 +523,9 @@ func createKey(cfg *config.Config) error {
 	k := &store.APIKey{
 		KeyHash:     hash[:],
 		ClientID:    clientID,
+		Profile:     profile,
 		Description: desc,
+		Scopes:      scopes,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -239,6 +538,8 @@ func createKey(cfg *config.Config) error {
 	fmt.Printf("\nAPI key created successfully!\n\n")
 	fmt.Printf("  Client ID:   %s\n", clientID)
 	fmt.Printf("  Key ID:      %s\n", k.ID)
+	fmt.Printf("  Profile:     %s\n", k.Profile)
+	fmt.Printf("  Scopes:      %s\n", strings.Join(k.Scopes, ","))
 	fmt.Printf("  API Key:     %s\n\n", rawKeyHex)
 	fmt.Println("Save this key now — it will not be shown again.")
 	fmt.Println("Use it in requests: X-API-Key: " + rawKeyHex)
@@ -249,10 +550,10 @@ func createKey(cfg *config.Config) error {
 func discover(cfg *config.Config) error {
 	bankCode := parseFlag("--bank")
 	if bankCode == "" {
-		return fmt.Errorf("--bank is required\nUsage: api discover --bank=BBVA")
+		return fmt.Errorf("--bank is required\nUsage: api discover --bank=BBVA [--profile=<name>]")
 	}
 
-	mk, err := crypto.ParseMasterKey(cfg.EncryptionKey)
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
 	if err != nil {
 		return fmt.Errorf("parse encryption key: %w", err)
 	}
@@ -265,13 +566,21 @@ func discover(cfg *config.Config) error {
 
 	pool := db.Pool()
 	credRepo := store.NewCredentialRepo(pool)
-	accountRepo := store.NewAccountRepo(pool)
+	aliases, err := store.ParseAccountAliases(cfg.AccountAliases)
+	if err != nil {
+		return fmt.Errorf("ACCOUNT_ALIASES: %w", err)
+	}
+	accountRepo := store.WithAccountAliases(store.NewAccountRepo(pool), aliases)
 	logger := slog.Default()
 
-	credSvc := newCredService(pool, mk, logger)
+	credSvc := newCredService(pool, keys, logger)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return err
+	}
 
 	creds, err := credSvc.GetCredentials(ctx, bankCode)
 	if err != nil {
@@ -283,7 +592,11 @@ func discover(cfg *config.Config) error {
 		return fmt.Errorf("get credential record: %w", err)
 	}
 
-	discoverySvc := service.NewDiscoveryService(accountRepo, scraperfactory.New(cfg.ScraperTimeout, cfg.ScraperHeadless), logger)
+	factory, err := scraperfactory.NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	discoverySvc := service.NewDiscoveryService(accountRepo, factory, logger)
 
 	fmt.Printf("Discovering accounts for %s...\n", bankCode)
 	accounts, err := discoverySvc.Discover(ctx, bankCode, creds, cred.ID)
@@ -293,9 +606,77 @@ func discover(cfg *config.Config) error {
 
 	fmt.Printf("\nDiscovered %d account(s):\n", len(accounts))
 	for i, a := range accounts {
-		fmt.Printf("  [%d] %s %s (%s)\n", i+1, a.BankCode, handler.MaskAccountNumber(a.AccountNumber), a.Currency)
+		fmt.Printf("  [%d] %s %s (%s)", i+1, a.BankCode, handler.MaskAccountNumber(a.AccountNumber), a.Currency)
+		if a.Alias != "" {
+			fmt.Printf(" %q", a.Alias)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func forecast(cfg *config.Config) error {
+	days := handler.DefaultForecastDays
+	if v := parseFlag("--days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > handler.MaxForecastDays {
+			return fmt.Errorf("--days must be between 1 and %d\nUsage: api forecast [--days=30] [--json] [--profile=<name>]", handler.MaxForecastDays)
+		}
+		days = n
+	}
+
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return fmt.Errorf("parse encryption key: %w", err)
+	}
+
+	db, err := connectDB(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pool := db.Pool()
+	logger := slog.Default()
+
+	credSvc := newCredService(pool, keys, logger)
+	factory, err := scraperfactory.NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	sessionMgr := session.NewManager(credSvc, factory, logger)
+	defer sessionMgr.Shutdown(context.Background())
+
+	forecastSvc := service.NewForecastService(store.NewAccountRepo(pool), sessionMgr, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	fc, err := forecastSvc.Forecast(ctx, time.Now(), days)
+	if err != nil {
+		return err
+	}
+
+	resp := handler.ToForecastResponse(fc)
+	if hasFlag("--json") {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
 	}
 
+	fmt.Printf("Cash forecast for %d day(s) from %s\n", resp.Days, fc.AsOf.Format(time.DateOnly))
+	for _, cf := range resp.Currencies {
+		fmt.Printf("\n%s  opening %s\n", cf.Currency, cf.Opening)
+		fmt.Printf("  %-10s  %14s  %14s  %14s\n", "DATE", "IN", "OUT", "CLOSING")
+		for _, d := range cf.Days {
+			fmt.Printf("  %-10s  %14s  %14s  %14s\n", d.Date, d.Inflows, d.Outflows, d.Closing)
+		}
+	}
 	return nil
 }
 
@@ -313,12 +694,149 @@ func connectDB(databaseURL string) (*store.DB, error) {
 	return db, nil
 }
 
+// setPriority marks an account as high or normal scrape priority. High
+// priority accounts are scraped first by the job queue and scheduler.
+func setPriority(cfg *config.Config) error {
+	accountFlag := parseFlag("--account")
+	priority := strings.ToLower(parseFlag("--priority"))
+	if accountFlag == "" || priority == "" {
+		return fmt.Errorf("--account and --priority are required\nUsage: api set-priority --account=<account-id> --priority=high|normal [--profile=<name>]")
+	}
+	accountID, err := uuid.Parse(accountFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --account: %w", err)
+	}
+
+	db, err := connectDB(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := store.NewAccountRepo(db.Pool()).SetPriority(ctx, accountID, priority); err != nil {
+		return err
+	}
+	fmt.Printf("account %s priority set to %s\n", accountID, priority)
+	return nil
+}
+
+// backupConfigFile is the name of the redacted configuration in a backup.
+const backupConfigFile = "config.json"
+
+// backup writes the whole store and the configuration minus secrets to one
+// archive, sealed with ENCRYPTION_KEY, for moving to another host. Bank
+// sessions live in the server's browsers and aren't included: the new host
+// logs in again.
+func backup(cfg *config.Config) error {
+	out := parseFlag("--out")
+	if out == "" {
+		return fmt.Errorf("--out is required\nUsage: api backup --out=<file>")
+	}
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return fmt.Errorf("parse encryption key: %w", err)
+	}
+	conf, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+
+	db, err := connectDB(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	m, err := store.Backup(ctx, db.Pool(), f, keys.FieldCipher(store.DefaultProfile), map[string][]byte{backupConfigFile: conf})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(out)
+		return err
+	}
+
+	fmt.Printf("Backed up migration %d to %s:\n", m.MigrationVersion, out)
+	for _, table := range slices.Sorted(maps.Keys(m.Rows)) {
+		fmt.Printf("  %-18s %d rows\n", table, m.Rows[table])
+	}
+	return nil
+}
+
+// restore loads a backup into this host's store, which must be migrated and
+// empty, and writes the backed-up configuration next to the archive for
+// review.
+func restore(cfg *config.Config) error {
+	in := parseFlag("--in")
+	if in == "" {
+		return fmt.Errorf("--in is required\nUsage: api restore --in=<file>")
+	}
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return fmt.Errorf("parse encryption key: %w", err)
+	}
+
+	db, err := connectDB(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	m, files, err := store.Restore(ctx, db.Pool(), f, keys.FieldCipher(store.DefaultProfile))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored migration %d from a backup taken %s\n", m.MigrationVersion, m.CreatedAt.Local().Format(time.DateTime))
+	if conf, ok := files[backupConfigFile]; ok {
+		path := in + "." + backupConfigFile
+		if err := os.WriteFile(path, conf, 0o600); err != nil {
+			return fmt.Errorf("write backed-up config: %w", err)
+		}
+		fmt.Printf("Backed-up configuration (secrets removed) written to %s; merge it into .env\n", path)
+	}
+	return nil
+}
+
 // newCredService creates a read-only credential service (no tester, for decryption only).
-func newCredService(pool *pgxpool.Pool, mk crypto.MasterKey, logger *slog.Logger) *credservice.CredentialService {
+func newCredService(pool *pgxpool.Pool, keys *crypto.Keyring, logger *slog.Logger) *credservice.CredentialService {
 	credRepo := store.NewCredentialRepo(pool)
 	auditRepo := store.NewAuditLogRepo(pool)
 	aw := credservice.NewAuditWriter(auditRepo, logger)
-	return credservice.NewCredentialService(credRepo, aw, mk, nil, logger)
+	return credservice.NewCredentialService(credRepo, aw, keys, nil, logger)
+}
+
+// profileContext scopes ctx to the profile named by --profile, if any (see
+// store.WithProfile).
+func profileContext(ctx context.Context) (context.Context, error) {
+	p := parseFlag("--profile")
+	if p == "" {
+		return ctx, nil
+	}
+	if err := store.ValidateProfile(p); err != nil {
+		return nil, err
+	}
+	return store.WithProfile(ctx, p), nil
 }
 
 // parseFlag extracts a CLI flag value from os.Args.
@@ -335,12 +853,28 @@ func parseFlag(name string) string {
 	return ""
 }
 
+// hasFlag reports whether a boolean CLI flag (e.g. --json) is present in os.Args.
+func hasFlag(name string) bool {
+	for _, arg := range os.Args {
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, "Usage: api <command>\n\nCommands:\n")
 	fmt.Fprintf(os.Stderr, "  serve         Start the API gateway\n")
 	fmt.Fprintf(os.Stderr, "  create-key    Create an API key\n")
 	fmt.Fprintf(os.Stderr, "  discover      Trigger account discovery for a bank\n")
+	fmt.Fprintf(os.Stderr, "  forecast      Print an N-day cash position forecast\n")
+	fmt.Fprintf(os.Stderr, "  openapi       Print the OpenAPI 3 document (also served at /api/v1/openapi.json)\n")
+	fmt.Fprintf(os.Stderr, "  set-priority  Mark an account high or normal scrape priority\n")
+	fmt.Fprintf(os.Stderr, "  backup        Write the store and configuration to an encrypted archive\n")
+	fmt.Fprintf(os.Stderr, "  restore       Load an archive written by backup into an empty store\n")
 	fmt.Fprintf(os.Stderr, "  migrate       Run all pending database migrations\n")
 	fmt.Fprintf(os.Stderr, "  migrate-down  Rollback the last migration\n")
 	fmt.Fprintf(os.Stderr, "  version       Show the current migration version\n")
+	fmt.Fprintf(os.Stderr, "\nLogging: -v (debug), -q (warnings and errors), --log-json, --log-file=PATH; or LOG_LEVEL, LOG_FORMAT, LOG_FILE\n")
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/aynifx/bank-scraper/internal/credmgr/crypto"
 	"github.com/aynifx/bank-scraper/internal/credmgr/handler"
 	"github.com/aynifx/bank-scraper/internal/credmgr/service"
+	"github.com/aynifx/bank-scraper/internal/logging"
 	scraperfactory "github.com/aynifx/bank-scraper/internal/scraper/factory"
 	"github.com/aynifx/bank-scraper/internal/store"
 	"github.com/joho/godotenv"
@@ -35,6 +36,7 @@ func main() {
 	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
 		log.Fatalf("failed to load .env: %v", err)
 	}
+	defer logging.Init()()
 
 	if len(os.Args) < 2 {
 		printUsage()
@@ -87,10 +89,12 @@ func serve(cfg *config.Config) error {
 	if cfg.EncryptionKey == "" {
 		return fmt.Errorf("ENCRYPTION_KEY env var is required")
 	}
-	mk, err := crypto.ParseMasterKey(cfg.EncryptionKey)
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
 	if err != nil {
 		return fmt.Errorf("parse encryption key: %w", err)
 	}
+	// User TOTP secrets aren't profile data: they stay under the root key.
+	mk := keys.Key(store.DefaultProfile)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -116,10 +120,14 @@ func serve(cfg *config.Config) error {
 	aw := service.NewAuditWriter(auditRepo, logger)
 	authSvc := service.NewAuthService(userRepo, sessionRepo, aw, mk, cfg.SessionTTL, logger)
 	tester := service.NewScraperCredentialTester()
-	credSvc := service.NewCredentialService(credRepo, aw, mk, tester, logger)
+	credSvc := service.NewCredentialService(credRepo, aw, keys, tester, logger)
 
 	// Account discovery service
-	discoverySvc := apiservice.NewDiscoveryService(accountRepo, scraperfactory.New(cfg.ScraperTimeout, cfg.ScraperHeadless), logger)
+	scrapers, err := scraperfactory.NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	discoverySvc := apiservice.NewDiscoveryService(accountRepo, scrapers, logger)
 
 	// Setup and start router
 	router := handler.SetupRouter(handler.RouterDeps{
@@ -240,4 +248,5 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "  migrate       Run all pending database migrations\n")
 	fmt.Fprintf(os.Stderr, "  migrate-down  Rollback the last migration\n")
 	fmt.Fprintf(os.Stderr, "  version       Show the current migration version\n")
+	fmt.Fprintf(os.Stderr, "\nLogging: -v (debug), -q (warnings and errors), --log-json, --log-file=PATH; or LOG_LEVEL, LOG_FORMAT, LOG_FILE\n")
 }
@@ -1,20 +1,1583 @@
-// Package main is the bank-scraper entrypoint.
+// Package main is the bank-scraper CLI entrypoint.
+//
+// Usage:
+//
+//	bank-scraper report summary   Consolidated balances across all configured banks
+//	bank-scraper report monthly   Per-account monthly closing statement from stored data
+//	bank-scraper import <file>    Load a downloaded statement into the store
+//	bank-scraper export csv       Write stored transactions to one CSV per account
+//	bank-scraper export parquet   Write stored transactions and daily balances as Parquet tables
+//	bank-scraper export firefly   Push stored transactions to Firefly III
+//	bank-scraper query "SELECT"   Run a read-only SQL query over the store
+//	bank-scraper backfill         Scrape and store each account's full history, with progress
+//	bank-scraper watch            Poll an account until an expected transfer arrives, then notify
+//	bank-scraper doctor           Check configuration, browser and database
+//	bank-scraper reparse          Run the parsers over an archived HTML page
+//	bank-scraper selftest         Check the parsers against the bundled fixtures
+//	bank-scraper support-bundle   Archive redacted logs, run summaries and versions for a bug report
+//
+// bank-scraper --demo runs scrape, store, export and notify against a
+// synthetic bank, to try the CSV exports and webhooks without credentials
+// or a database.
+//
+// Every command takes --output=json to print one JSON document to stdout
+// instead of text: {"schema_version", "command", "ok", "exit_code", "result",
+// "error"}, with logs and progress on stderr, for shell pipelines and cron
+// jobs.
+//
+// With --run-dir=PATH (or RUN_DIR), every run also leaves a run.json in a
+// timestamped folder under PATH: config hash, per-bank outcomes, counts,
+// warnings and the files it wrote.
+//
+// Exit codes tell wrapper scripts and schedulers what went wrong:
+//
+//	0  success
+//	1  any other error
+//	2  usage: unknown command, missing or invalid flag
+//	3  the bank rejected the credential; fix it before retrying
+//	4  the bank flagged the session as automated
+//	5  a bank page could not be parsed; the scraper needs updating
+//	6  partial success: some accounts could not be read
+//	7  transient: the bank or network is slow or down; retry later
 package main
 
 import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/api/handler"
+	"github.com/aynifx/bank-scraper/internal/api/notify"
+	"github.com/aynifx/bank-scraper/internal/api/service"
+	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/aynifx/bank-scraper/internal/calendar"
+	"github.com/aynifx/bank-scraper/internal/config"
+	"github.com/aynifx/bank-scraper/internal/credmgr/crypto"
+	credservice "github.com/aynifx/bank-scraper/internal/credmgr/service"
+	"github.com/aynifx/bank-scraper/internal/demo"
+	"github.com/aynifx/bank-scraper/internal/export"
+	"github.com/aynifx/bank-scraper/internal/importer"
+	"github.com/aynifx/bank-scraper/internal/logging"
+	"github.com/aynifx/bank-scraper/internal/money"
+	"github.com/aynifx/bank-scraper/internal/progress"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/runlog"
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/bbva"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	scraperfactory "github.com/aynifx/bank-scraper/internal/scraper/factory"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
+	reparser "github.com/aynifx/bank-scraper/internal/scraper/reparse"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/aynifx/bank-scraper/internal/support"
+	"github.com/aynifx/bank-scraper/internal/watch"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
 )
 
 func main() {
-	fmt.Println("Hello bank scraper!")
-	// err := godotenv.Load()
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to load .env file: %w", err)
-	// }
-
-	// companyCode := os.Getenv("BBVA_COMPANY_CODE")
-	// userCode := os.Getenv("BBVA_USER_CODE")
-	// password := os.Getenv("BBVA_PASSWORD")
-	//
-	// scraper := bbva.NewScraper()
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		log.Fatalf("failed to load .env: %v", err)
+	}
+	defer logging.Init()()
+	if o := parseFlag("--output"); o != "" && o != "text" && o != "json" {
+		fmt.Fprintf(os.Stderr, "unknown --output %q: want text or json\n\n", o)
+		printUsage()
+		os.Exit(exitUsage)
+	}
+
+	// Runs against a synthetic bank, so it needs no configuration at all.
+	if hasFlag("--demo") {
+		res, err := runDemo()
+		finish("demo", res, err)
+		return
+	}
+
+	// Reports configuration problems instead of failing on them.
+	if len(os.Args) >= 2 && os.Args[1] == "doctor" {
+		checks, ok := doctor()
+		var err error
+		if !ok {
+			err = errors.New("some checks failed")
+		}
+		finish("doctor", checks, err)
+		return
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "reparse" {
+		res, err := reparse()
+		finish("reparse", res, err)
+		return
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "selftest" {
+		checks, err := selftest()
+		finish("selftest", checks, err)
+		return
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "support-bundle" {
+		m, err := supportBundle()
+		finish("support-bundle", m, err)
+		return
+	}
+
+	if len(os.Args) < 3 {
+		printUsage()
+		os.Exit(exitUsage)
+	}
+
+	command := os.Args[1]
+	if command == "report" {
+		command += " " + os.Args[2]
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		finish(command, nil, fmt.Errorf("load config: %w", err))
+	}
+	runConfig = cfg
+
+	switch os.Args[1] {
+	case "report":
+		switch os.Args[2] {
+		case "summary":
+			s, err := summary(cfg)
+			finish(command, s, err)
+		case "monthly":
+			res, err := monthlyStatement(cfg)
+			finish(command, res, err)
+
+		default:
+			fmt.Fprintf(os.Stderr, "unknown report: %s\n\n", os.Args[2])
+			printUsage()
+			os.Exit(exitUsage)
+		}
+
+	case "import":
+		res, err := importStatement(cfg, os.Args[2])
+		finish(command, res, err)
+
+	case "export":
+		res, err := exportStored(cfg, os.Args[2])
+		finish(command, res, err)
+
+	case "backfill":
+		res, err := backfill(cfg)
+		finish(command, res, err)
+
+	case "query":
+		res, err := query(cfg, os.Args[2])
+		finish(command, res, err)
+
+	case "watch":
+		res, err := watchTransfer(cfg)
+		finish(command, res, err)
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
+		printUsage()
+		os.Exit(exitUsage)
+	}
+}
+
+// Exit codes; see the package documentation.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitAuth        = 3
+	exitBotDetected = 4
+	exitParse       = 5
+	exitPartial     = 6
+	exitTransient   = 7
+)
+
+// errPartial marks a command that finished with some accounts missing.
+var errPartial = errors.New("partial success")
+
+// usageError is a mistake in the command line.
+type usageError struct{ err error }
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{fmt.Errorf(format, args...)}
+}
+
+// exitCode maps a command's error to its exit code.
+func exitCode(err error) int {
+	var ue *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &ue), errors.Is(err, store.ErrInvalidProfile):
+		return exitUsage
+	case errors.Is(err, errPartial):
+		return exitPartial
+	case errors.Is(err, bank.ErrBotDetection):
+		return exitBotDetected
+	case errors.Is(err, bank.ErrParsingFailed), errors.Is(err, bank.ErrUnsupportedPage):
+		return exitParse
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTransient
+	}
+	switch bank.Classify(err) {
+	case bank.ClassCredential:
+		return exitAuth
+	case bank.ClassRetryable:
+		return exitTransient
+	}
+	return exitError
+}
+
+// cliOutput is the document every command prints with --output=json. Its
+// shape is stable; Result is the command's own JSON (the report, or what
+// was imported or written) and follows schema.Version.
+type cliOutput struct {
+	SchemaVersion string `json:"schema_version"`
+	Command       string `json:"command"`
+	OK            bool   `json:"ok"`
+	ExitCode      int    `json:"exit_code"`
+	Result        any    `json:"result"` // null on error
+	Error         string `json:"error,omitempty"`
+}
+
+// finish ends a command. With --output=json it prints result and err as a
+// cliOutput; in text mode the command printed its result already and only
+// err is logged. It writes the run summary, if asked for, and exits with
+// exitCode(err).
+func finish(command string, result any, err error) {
+	code := exitCode(err)
+	writeRunSummary(command, result, err, code)
+	if jsonOutput() {
+		out := cliOutput{SchemaVersion: schema.Version, Command: command, OK: err == nil, ExitCode: code, Result: result}
+		if err != nil {
+			out.Error = err.Error()
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(out); encErr != nil {
+			log.Fatalf("%s: write output: %v", command, encErr)
+		}
+	} else if code == exitPartial {
+		log.Printf("%s: %v", command, err)
+	} else if err != nil {
+		log.Printf("%s failed: %v", command, err)
+	}
+	if code != exitOK {
+		os.Exit(code)
+	}
+}
+
+// runStarted is when this run began, for its run summary.
+var runStarted = time.Now()
+
+// runConfig is the configuration the command loaded, if any, for the run
+// summary's config hash.
+var runConfig *config.Config
+
+// writeRunSummary writes the run's run.json under --run-dir (or RUN_DIR),
+// if set. Failing to write it is only logged: the run itself is over.
+func writeRunSummary(command string, result any, err error, code int) {
+	dir := cmp.Or(parseFlag("--run-dir"), os.Getenv("RUN_DIR"))
+	if dir == "" {
+		return
+	}
+	s := &runlog.Summary{
+		Command:    command,
+		StartedAt:  runStarted,
+		FinishedAt: time.Now(),
+		OK:         err == nil,
+		ExitCode:   code,
+		ConfigHash: configHash(runConfig),
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	describeRun(s, result)
+	path, err := runlog.Write(dir, s)
+	if err != nil {
+		slog.Warn("run summary not written", slog.Any("error", err))
+		return
+	}
+	slog.Debug("run summary written", slog.String("path", path))
+}
+
+// describeRun fills in s's per-bank outcomes, counts and artifacts from a
+// command's result.
+func describeRun(s *runlog.Summary, result any) {
+	switch r := result.(type) {
+	case *report.Summary:
+		if r == nil {
+			return
+		}
+		for _, a := range r.Accounts {
+			s.AddAccount(string(a.BankCode), a.AccountNumber, a.Error)
+		}
+		s.Count("accounts", len(r.Accounts))
+		s.Count("stale", r.Stale)
+	case *backfillResult:
+		if r == nil {
+			return
+		}
+		for _, a := range r.Accounts {
+			s.AddAccount(r.Bank, a.AccountNumber, a.Error)
+			s.Count("fetched", a.Fetched)
+		}
+		s.Count("accounts", len(r.Accounts))
+		s.Count("inserted", r.Inserted)
+	case *importResult:
+		if r == nil {
+			return
+		}
+		s.Count("parsed", r.Parsed)
+		s.Count("inserted", r.Inserted)
+		s.Count("skipped", r.Skipped)
+	case *exportResult:
+		if r == nil || r.Dir == "" {
+			return
+		}
+		s.Artifacts = append(s.Artifacts, r.Dir)
+	case *store.QueryResult:
+		if r == nil {
+			return
+		}
+		s.Count("rows", len(r.Rows))
+	case *watch.Result:
+		if r == nil {
+			return
+		}
+		s.Count("polls", r.Polls)
+	case *support.Manifest:
+		if r == nil {
+			return
+		}
+		s.Count("files", len(r.Files))
+		s.Artifacts = append(s.Artifacts, r.Path)
+	case *demo.Result:
+		if r == nil {
+			return
+		}
+		s.Banks = append(s.Banks, runlog.BankOutcome{Bank: string(r.Bank), Status: runlog.StatusOK, Accounts: r.Accounts})
+		s.Count("accounts", r.Accounts)
+		s.Count("inserted", r.History+r.New)
+		s.Count("notified", r.Notified)
+		s.Artifacts = append(s.Artifacts, r.ExportDir)
+	}
+	if out := parseFlag("--out"); out != "" {
+		s.Artifacts = append(s.Artifacts, out)
+	}
+}
+
+// configHash fingerprints cfg for run summaries. Secrets, and settings that
+// may embed them (URLs, hook secrets), are left out of the fingerprint.
+func configHash(cfg *config.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	c := *cfg
+	c.DatabaseURL, c.EncryptionKey = "", ""
+	c.ProfileEncryptionKeys = nil
+	c.ScrapeHooks, c.NotifyWebhooks = nil, nil
+	c.BBVA = config.BBVAConfig{}
+	return runlog.Hash(c)
+}
+
+// jsonOutput reports whether --output=json was given.
+func jsonOutput() bool {
+	return parseFlag("--output") == "json"
+}
+
+// progressf prints a status line meant for people: to stdout in text mode,
+// to stderr with --output=json so stdout stays one JSON document. -q
+// silences it.
+func progressf(format string, args ...any) {
+	if !slog.Default().Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
+	w := os.Stdout
+	if jsonOutput() {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+func summary(cfg *config.Config) (*report.Summary, error) {
+	rate, err := usdPENRate(cfg)
+	if err != nil {
+		return nil, usageErrorf("%w\nUsage: bank-scraper report summary [--json] [--usd-pen=3.75] [--profile=NAME]", err)
+	}
+
+	aliases, err := store.ParseAccountAliases(cfg.AccountAliases)
+	if err != nil {
+		return nil, fmt.Errorf("ACCOUNT_ALIASES: %w", err)
+	}
+
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("parse encryption key: %w", err)
+	}
+
+	db, err := connectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	pool := db.Pool()
+	logger := slog.Default()
+
+	sessionMgr, err := sessionManager(cfg, pool, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer sessionMgr.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := service.NewSummaryService(store.WithAccountAliases(store.NewAccountRepo(pool), aliases), sessionMgr, logger).Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := report.Summarize(rows, rate, time.Now())
+
+	switch {
+	case jsonOutput():
+	case hasFlag("--json"):
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s); err != nil {
+			return s, err
+		}
+	default:
+		printSummary(s)
+	}
+	return s, summaryOutcome(s)
+}
+
+// sessionManager returns a session manager logging in with the stored
+// credentials, as the API server does.
+func sessionManager(cfg *config.Config, pool *pgxpool.Pool, keys *crypto.Keyring) (*session.Manager, error) {
+	logger := slog.Default()
+	credSvc := credservice.NewCredentialService(
+		store.NewCredentialRepo(pool),
+		credservice.NewAuditWriter(store.NewAuditLogRepo(pool), logger),
+		keys, nil, logger,
+	)
+	scrapers, err := scraperfactory.NewFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return session.NewManager(credSvc, scrapers, logger), nil
+}
+
+// summaryOutcome is the error of a summary with stale rows: the error of
+// the first failed account when none could be read, errPartial otherwise.
+func summaryOutcome(s *report.Summary) error {
+	if s.Stale == 0 {
+		return nil
+	}
+	if s.Stale < len(s.Accounts) {
+		return fmt.Errorf("%w: %d of %d accounts could not be refreshed", errPartial, s.Stale, len(s.Accounts))
+	}
+	for _, a := range s.Accounts {
+		if a.Err != nil {
+			return fmt.Errorf("no account could be refreshed: %w", a.Err)
+		}
+	}
+	return errors.New("no account could be refreshed")
+}
+
+func printSummary(s *report.Summary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "BANK\tACCOUNT\tCCY\tAVAILABLE\tCURRENT\tLAST TXN\tFRESHNESS\t")
+	for _, a := range s.Accounts {
+		available, current := "-", "-"
+		if a.Fresh() {
+			available = money.FormatMoney(money.Money{Cents: a.AvailableBalance, Currency: a.Currency}, money.LocalePE)
+			current = money.FormatMoney(money.Money{Cents: a.CurrentBalance, Currency: a.Currency}, money.LocalePE)
+		}
+		lastTxn := "-"
+		if a.LastTransaction != nil {
+			lastTxn = a.LastTransaction.Format(time.DateOnly)
+		}
+		account := a.Alias
+		if account == "" {
+			account = handler.MaskAccountNumber(a.AccountNumber)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			a.BankCode, account, a.Currency,
+			available, current, lastTxn, freshness(a, s.GeneratedAt))
+	}
+	_ = w.Flush()
+
+	fmt.Println()
+	for _, c := range sortedCurrencies(s.Totals) {
+		fmt.Printf("Total %s: %s\n", c, money.FormatMoney(money.Money{Cents: s.Totals[c], Currency: c}, money.LocalePE))
+	}
+	fmt.Printf("PEN equivalent: %s", money.FormatMoney(money.Money{Cents: s.PENEquivalent, Currency: bank.CurrencyPEN}, money.LocalePE))
+	if s.USDPENRate > 0 {
+		fmt.Printf(" (USD/PEN %.4f)", s.USDPENRate)
+	}
+	fmt.Println()
+	if len(s.Excluded) > 0 {
+		fmt.Printf("Excluded from PEN equivalent (no rate): %v — set FX_USD_PEN or --usd-pen\n", s.Excluded)
+	}
+	if s.Stale > 0 {
+		fmt.Printf("%d account(s) could not be refreshed and are not in the totals\n", s.Stale)
+	}
+}
+
+// usdPENRate returns --usd-pen, or FX_USD_PEN without it.
+func usdPENRate(cfg *config.Config) (float64, error) {
+	v := parseFlag("--usd-pen")
+	if v == "" {
+		return cfg.USDPENRate, nil
+	}
+	r, err := strconv.ParseFloat(v, 64)
+	if err != nil || r <= 0 {
+		return 0, errors.New("--usd-pen must be a positive number")
+	}
+	return r, nil
+}
+
+// monthlyStatement writes the closing statement of one month for every
+// account of the profile, from stored transactions only: no bank is
+// contacted, so it can run for past months and against a restored backup.
+func monthlyStatement(cfg *config.Config) (any, error) {
+	const usage = "Usage: bank-scraper report monthly --month=YYYY-MM [--format=json|csv|pdf] [--out=FILE] [--usd-pen=3.75] [--profile=NAME]"
+
+	month, err := time.Parse("2006-01", parseFlag("--month"))
+	if err != nil {
+		return nil, usageErrorf("--month must be YYYY-MM\n%s", usage)
+	}
+	format := cmp.Or(strings.ToLower(parseFlag("--format")), "json")
+	if format != "json" && format != "csv" && format != "pdf" {
+		return nil, usageErrorf("unknown format %q\n%s", format, usage)
+	}
+	out := parseFlag("--out")
+	if format == "pdf" && out == "" {
+		return nil, usageErrorf("--out is required for PDF\n%s", usage)
+	}
+	if jsonOutput() && format != "json" && out == "" {
+		return nil, usageErrorf("--output=json needs --out with --format=%s\n%s", format, usage)
+	}
+	rate, err := usdPENRate(cfg)
+	if err != nil {
+		return nil, usageErrorf("%w\n%s", err, usage)
+	}
+	aliases, err := store.ParseAccountAliases(cfg.AccountAliases)
+	if err != nil {
+		return nil, fmt.Errorf("ACCOUNT_ALIASES: %w", err)
+	}
+
+	db, err := connectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	pool := db.Pool()
+
+	txRepo, err := transactionRepo(cfg, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := store.WithAccountAliases(store.NewAccountRepo(pool), aliases).List(ctx, store.AccountFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var in []report.StatementAccount
+	for _, a := range accounts {
+		stored, err := txRepo.ListByAccount(ctx, a.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list transactions of %s: %w", a.ID, err)
+		}
+		sa := report.StatementAccount{
+			BankCode:      bank.Code(a.BankCode),
+			AccountNumber: a.AccountNumber,
+			Alias:         a.Alias,
+			Currency:      bank.Currency(a.Currency),
+		}
+		for _, t := range stored {
+			tx := bank.Transaction{
+				ID:           t.BankID,
+				Reference:    t.Reference,
+				Date:         t.OperationDate,
+				Description:  t.Description,
+				Amount:       t.Amount,
+				Type:         bank.TransactionType(t.Type),
+				BalanceAfter: t.BalanceAfter,
+			}
+			if t.ValueDate != nil {
+				tx.ValueDate = *t.ValueDate
+			}
+			sa.Transactions = append(sa.Transactions, tx)
+		}
+		in = append(in, sa)
+	}
+	s := report.NewMonthlyStatement(month, in, rate, time.Now())
+
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(s)
+	case "csv":
+		err = s.WriteCSV(&buf)
+	case "pdf":
+		err = s.WriteHTML(&buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	if format == "pdf" {
+		if data, err = statementPDF(ctx, buf.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case out == "" && jsonOutput():
+		return s, nil
+	case out == "":
+		_, err = os.Stdout.Write(data)
+		return s, err
+	}
+	if err := os.WriteFile(out, data, 0o600); err != nil {
+		return nil, err
+	}
+	progressf("Wrote %s statement for %s (%d accounts) to %s\n", strings.ToUpper(format), s.Month, len(s.Accounts), out)
+	return writtenFile{Path: out, Format: format, Month: s.Month, Accounts: len(s.Accounts)}, nil
+}
+
+// writtenFile is the result of a command that wrote its output to a file.
+type writtenFile struct {
+	Path     string `json:"path"`
+	Format   string `json:"format"`
+	Month    string `json:"month,omitempty"`
+	Accounts int    `json:"accounts"`
+}
+
+// statementPDF prints an HTML statement with the browser the scrapers use
+// (see BROWSER_POLICY). A remote browser isn't used: the document is local.
+func statementPDF(ctx context.Context, html string) ([]byte, error) {
+	bc, err := config.LoadBrowser()
+	if err != nil {
+		return nil, err
+	}
+	r, err := scraperfactory.Resolver(*bc)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return browser.RenderPDF(res.Path, html)
+}
+
+// freshness renders how old a row's data is, e.g. "live", "stale 26h".
+func freshness(a report.AccountSummary, now time.Time) string {
+	age, ok := a.Age(now)
+	switch {
+	case a.Fresh():
+		return "live"
+	case !ok:
+		return "never synced"
+	default:
+		return "stale " + age.Truncate(time.Minute).String()
+	}
+}
+
+func sortedCurrencies[V any](m map[bank.Currency]V) []bank.Currency {
+	out := make([]bank.Currency, 0, len(m))
+	for c := range m {
+		out = append(out, c)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// importStatement loads a downloaded statement into the store for one
+// account. Rows already stored (scraped or imported earlier) are skipped by
+// dedup hash, so overlapping statements and later scrapes continue one history.
+func importStatement(cfg *config.Config, path string) (*importResult, error) {
+	const usage = "Usage: bank-scraper import <file> --bank=CODE --account=NUMBER [--format=bbva|csv|json] [--map=SPEC] [--profile=NAME] [--dry-run]"
+
+	bankCode, accountNumber := strings.ToUpper(parseFlag("--bank")), parseFlag("--account")
+	if bankCode == "" || accountNumber == "" {
+		return nil, usageErrorf("--bank and --account are required\n%s", usage)
+	}
+
+	format := parseFlag("--format")
+	if format == "" {
+		format = "bbva"
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = "json"
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var txns []bank.Transaction
+	switch format {
+	case "json":
+		txns, err = importer.ReadJSON(f)
+	case "bbva":
+		txns, err = readTabular(f, path, importer.BBVAMapping)
+	case "csv":
+		m, mapErr := importer.ParseMapping(parseFlag("--map"))
+		if mapErr != nil {
+			return nil, usageErrorf("%w\n%s", mapErr, usage)
+		}
+		txns, err = readTabular(f, path, m)
+	default:
+		return nil, usageErrorf("unknown format %q\n%s", format, usage)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(txns) == 0 {
+		progressf("No transactions found.\n")
+		return &importResult{DryRun: hasFlag("--dry-run")}, nil
+	}
+
+	first, last := txns[0].Date, txns[0].Date
+	for _, tx := range txns {
+		first, last = minTime(first, tx.Date), maxTime(last, tx.Date)
+	}
+	res := &importResult{Parsed: len(txns), From: first.Format(time.DateOnly), To: last.Format(time.DateOnly), DryRun: hasFlag("--dry-run")}
+	progressf("Parsed %d transactions (%s to %s)\n", res.Parsed, res.From, res.To)
+	if res.DryRun {
+		return res, nil
+	}
+
+	db, err := connectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	pool := db.Pool()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := store.NewAccountRepo(pool).List(ctx, store.AccountFilter{BankCode: &bankCode})
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(accounts, func(a store.Account) bool { return a.AccountNumber == accountNumber })
+	if i < 0 {
+		return nil, fmt.Errorf("account %s/%s not found (run `api discover` first): %w", bankCode, accountNumber, store.ErrNotFound)
+	}
+
+	txRepo, err := transactionRepo(cfg, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	dedup, err := store.ParseDedupConfig(cfg.DedupStrategies)
+	if err != nil {
+		return nil, fmt.Errorf("DEDUP_STRATEGIES: %w", err)
+	}
+	records := dedup.For(bankCode).NewTransactions(accounts[i].ID, store.TransactionSourceImport, txns)
+	inserted, err := txRepo.InsertBatch(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+	res.Inserted, res.Skipped = inserted, len(records)-inserted
+	progressf("Imported %d new transactions, skipped %d already stored\n", res.Inserted, res.Skipped)
+	return res, nil
+}
+
+// importResult is what import parsed from a statement and stored.
+type importResult struct {
+	Parsed   int    `json:"parsed"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Inserted int    `json:"inserted"`
+	Skipped  int    `json:"skipped"` // already stored
+	DryRun   bool   `json:"dry_run"`
+}
+
+// transactionRepo returns the transaction store, encrypting with each
+// profile's key when STORE_ENCRYPTION is set.
+func transactionRepo(cfg *config.Config, pool *pgxpool.Pool) (*store.TransactionRepo, error) {
+	if !cfg.StoreEncryption {
+		return store.NewTransactionRepo(pool), nil
+	}
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("STORE_ENCRYPTION needs ENCRYPTION_KEY: %w", err)
+	}
+	return store.NewEncryptedTransactionRepo(pool, func(profile string) store.FieldCipher {
+		return keys.FieldCipher(profile)
+	}), nil
+}
+
+// exportStored writes the stored transactions of every active account to
+// sink once, for exports run from cron rather than by the API server's
+// EXPORT_INTERVAL.
+func exportStored(cfg *config.Config, sink string) (*exportResult, error) {
+	const usage = "Usage: bank-scraper export csv|parquet|firefly [--dir=PATH] [--profile=NAME]"
+	gl, err := export.ParseGLMapping(cfg.GLAccounts, cfg.GLCategories)
+	if err != nil {
+		return nil, fmt.Errorf("GL_ACCOUNTS/GL_CATEGORIES: %w", err)
+	}
+	var (
+		dir  = parseFlag("--dir")
+		dest export.Sink
+	)
+	switch sink {
+	case "csv":
+		dir = cmp.Or(dir, cfg.ExportCSVDir)
+		dest = export.CSVDir{Dir: dir, GL: gl, FullAccountIDs: cfg.FullAccountIDs}
+	case "parquet":
+		dir = cmp.Or(dir, cfg.ExportParquetDir)
+		dest = export.ParquetDir{Dir: dir, GL: gl, FullAccountIDs: cfg.FullAccountIDs}
+	case "firefly":
+		dir = ""
+		if dest, err = export.NewFirefly(cfg.FireflyURL, cfg.FireflyToken, cfg.FireflyAccounts); err != nil {
+			return nil, fmt.Errorf("FIREFLY_URL/FIREFLY_TOKEN/FIREFLY_ACCOUNTS: %w", err)
+		}
+	default:
+		return nil, usageErrorf("unknown export %q\n%s", sink, usage)
+	}
+	if dir == "" && sink != "firefly" {
+		return nil, usageErrorf("--dir or EXPORT_%s_DIR is required\n%s", strings.ToUpper(sink), usage)
+	}
+	aliases, err := store.ParseAccountAliases(cfg.AccountAliases)
+	if err != nil {
+		return nil, fmt.Errorf("ACCOUNT_ALIASES: %w", err)
+	}
+
+	db, err := connectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	pool := db.Pool()
+
+	txRepo, err := transactionRepo(cfg, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exporter := export.NewExporter(dest, store.WithAccountAliases(store.NewAccountRepo(pool), aliases), txRepo, slog.Default()).WithProfile(store.ProfileFrom(ctx))
+	if err := exporter.Run(ctx); err != nil {
+		return nil, err
+	}
+	res := &exportResult{Profile: store.ProfileFrom(ctx)}
+	if dir == "" {
+		progressf("Pushed to Firefly III\n")
+		return res, nil
+	}
+	res.Dir = filepath.Join(dir, res.Profile)
+	progressf("Exported to %s\n", res.Dir)
+	return res, nil
+}
+
+// defaultQueryRows is how many rows query prints unless --max-rows says
+// otherwise.
+const defaultQueryRows = 1000
+
+// query runs one read-only SQL statement over the store and prints its rows
+// as a table, CSV or JSON, for ad-hoc analysis without a SQL client. See
+// store.DB.Query for what the statement can see.
+func query(cfg *config.Config, sql string) (*store.QueryResult, error) {
+	const usage = `Usage: bank-scraper query "SELECT ..." [--format=table|csv|json] [--max-rows=N]`
+	format := cmp.Or(strings.ToLower(parseFlag("--format")), "table")
+	if format != "table" && format != "csv" && format != "json" {
+		return nil, usageErrorf("unknown format %q\n%s", format, usage)
+	}
+	limit := defaultQueryRows
+	if v := parseFlag("--max-rows"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, usageErrorf("--max-rows must be a number, 0 for all\n%s", usage)
+		}
+		limit = n
+	}
+	if strings.TrimSpace(sql) == "" || strings.HasPrefix(sql, "--") {
+		return nil, usageErrorf("missing SQL statement\n%s", usage)
+	}
+
+	db, err := connectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	res, err := db.Query(ctx, sql, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	if res.Truncated {
+		progressf("Showing the first %d rows; use --max-rows to see more\n", limit)
+	}
+	if jsonOutput() {
+		return res, nil
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(res)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(res.Columns)
+		for _, row := range res.Rows {
+			_ = w.Write(queryCells(row, ""))
+		}
+		w.Flush()
+		err = w.Error()
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(res.Columns, "\t"))
+		for _, row := range res.Rows {
+			fmt.Fprintln(w, strings.Join(queryCells(row, "NULL"), "\t"))
+		}
+		err = w.Flush()
+		progressf("(%d rows)\n", len(res.Rows))
+	}
+	return res, err
+}
+
+// queryCells formats a query row for text output, with null for NULLs.
+// Times at midnight UTC are dates.
+func queryCells(row []any, null string) []string {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		switch v := v.(type) {
+		case nil:
+			cells[i] = null
+		case time.Time:
+			if v.Equal(v.Truncate(24 * time.Hour)) {
+				cells[i] = v.Format(time.DateOnly)
+			} else {
+				cells[i] = v.Format(time.RFC3339)
+			}
+		default:
+			cells[i] = fmt.Sprint(v)
+		}
+	}
+	return cells
+}
+
+// exportResult is where export wrote a profile's files.
+type exportResult struct {
+	Dir     string `json:"dir,omitempty"` // Empty for Firefly III
+	Profile string `json:"profile"`
+}
+
+// backfillCount is how many transactions backfill asks for per account by
+// default: the most any scraper returns.
+const backfillCount = 250
+
+// backfill scrapes as much history as the bank shows for each active
+// account of one bank and stores what isn't stored yet, for filling the
+// store after adding an account or after an outage. Scraping every account
+// can take an hour, so it shows per-account progress, pages fetched and an
+// ETA on stderr: a redrawn bar on a terminal, plain lines when redirected.
+func backfill(cfg *config.Config) (*backfillResult, error) {
+	const usage = "Usage: bank-scraper backfill --bank=CODE [--account=NUMBER] [--count=N] [--profile=NAME]"
+	bankCode := strings.ToUpper(parseFlag("--bank"))
+	if bankCode == "" {
+		return nil, usageErrorf("--bank is required\n%s", usage)
+	}
+	count := backfillCount
+	if v := parseFlag("--count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, usageErrorf("invalid --count %q\n%s", v, usage)
+		}
+		count = n
+	}
+	dedup, err := store.ParseDedupConfig(cfg.DedupStrategies)
+	if err != nil {
+		return nil, fmt.Errorf("DEDUP_STRATEGIES: %w", err)
+	}
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("parse encryption key: %w", err)
+	}
+
+	db, err := connectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	pool := db.Pool()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountRepo := store.NewAccountRepo(pool)
+	accounts, err := accountRepo.List(ctx, store.AccountFilter{BankCode: &bankCode})
+	if err != nil {
+		return nil, err
+	}
+	only := parseFlag("--account")
+	accounts = slices.DeleteFunc(accounts, func(a store.Account) bool {
+		return a.Status != "" && a.Status != store.AccountStatusActive || only != "" && a.AccountNumber != only
+	})
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no active %s accounts to backfill (run `api discover` first): %w", bankCode, store.ErrNotFound)
+	}
+
+	txRepo, err := transactionRepo(cfg, pool)
+	if err != nil {
+		return nil, err
+	}
+	sessionMgr, err := sessionManager(cfg, pool, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer sessionMgr.Shutdown(context.Background())
+	scraper, err := sessionMgr.GetScraper(ctx, bank.Code(bankCode))
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+
+	var w io.Writer = os.Stderr
+	if !slog.Default().Enabled(ctx, slog.LevelInfo) {
+		w = io.Discard
+	}
+	tracker := progress.New(w, len(accounts))
+	res := &backfillResult{Bank: bankCode, Profile: store.ProfileFrom(ctx)}
+	var failed int
+	var firstErr error
+	for i, a := range accounts {
+		label := handler.MaskAccountNumber(a.AccountNumber)
+		tracker.Update(float64(i), label+": connecting")
+		actx := bank.WithProgress(ctx, func(p bank.PageProgress) {
+			done := float64(i)
+			if p.Target > 0 {
+				done += min(1, float64(p.Rows)/float64(p.Target))
+			}
+			tracker.Update(done, fmt.Sprintf("%s: page %d, %d/%d rows", label, p.Pages, p.Rows, p.Target))
+		})
+
+		ar := backfillAccount{AccountNumber: a.AccountNumber}
+		txns, err := scraper.GetTransactions(actx, a.AccountNumber, count)
+		if err != nil {
+			ar.Error = err.Error()
+			res.Accounts = append(res.Accounts, ar)
+			failed++
+			firstErr = cmp.Or(firstErr, fmt.Errorf("%s: %w", label, err))
+			slog.Warn("backfill account failed", slog.String("account", label), slog.Any("error", err))
+			continue
+		}
+		records := dedup.For(bankCode).NewTransactions(a.ID, store.TransactionSourceScrape, txns)
+		inserted, err := txRepo.InsertBatch(ctx, records)
+		if err != nil {
+			return res, err
+		}
+		if err := accountRepo.UpdateLastSynced(ctx, a.ID); err != nil {
+			slog.Warn("update last synced", slog.String("account", label), slog.Any("error", err))
+		}
+		ar.Fetched, ar.Inserted = len(txns), inserted
+		res.Accounts = append(res.Accounts, ar)
+		res.Inserted += inserted
+	}
+	tracker.Finish(fmt.Sprintf("Backfilled %d %s accounts (%d new transactions)", len(accounts)-failed, bankCode, res.Inserted))
+
+	switch {
+	case failed == len(accounts):
+		return res, firstErr
+	case failed > 0:
+		return res, fmt.Errorf("%w: %d of %d accounts failed, first: %w", errPartial, failed, len(accounts), firstErr)
+	}
+	return res, nil
+}
+
+// backfillResult is what backfill fetched and stored.
+type backfillResult struct {
+	Bank     string            `json:"bank"`
+	Profile  string            `json:"profile"`
+	Inserted int               `json:"inserted"`
+	Accounts []backfillAccount `json:"accounts"`
+}
+
+// backfillAccount is one account's part of a backfill.
+type backfillAccount struct {
+	AccountNumber string `json:"account_number"`
+	Fetched       int    `json:"fetched"`
+	Inserted      int    `json:"inserted"` // the rest were stored already
+	Error         string `json:"error,omitempty"`
+}
+
+// Watch defaults.
+const (
+	watchDeadline = 2 * time.Hour
+	watchInterval = 5 * time.Minute
+)
+
+// watchTransfer polls an account until a credit matching --amount and
+// --from arrives (see package watch), then tells the NOTIFY_WEBHOOKS
+// channels and stops. It fails with watch.ErrNotArrived once --deadline
+// passes. Credits from before --since (today by default) don't count.
+func watchTransfer(cfg *config.Config) (*watch.Result, error) {
+	const usage = "Usage: bank-scraper watch --bank=CODE --account=NUMBER [--amount=1500.00] [--from=NAME] [--since=YYYY-MM-DD] [--deadline=2h] [--interval=5m] [--profile=NAME]"
+	bankCode := strings.ToUpper(parseFlag("--bank"))
+	e := watch.Expectation{
+		AccountNumber: parseFlag("--account"),
+		Counterparty:  parseFlag("--from"),
+		Since:         time.Now().In(calendar.Lima),
+	}
+	if bankCode == "" || e.AccountNumber == "" {
+		return nil, usageErrorf("--bank and --account are required\n%s", usage)
+	}
+	if v := parseFlag("--amount"); v != "" {
+		cents, err := parseutil.ParseAmount(v)
+		if err != nil {
+			return nil, usageErrorf("invalid --amount %q\n%s", v, usage)
+		}
+		e.Amount = cents
+	}
+	if v := parseFlag("--since"); v != "" {
+		since, err := time.ParseInLocation(time.DateOnly, v, calendar.Lima)
+		if err != nil {
+			return nil, usageErrorf("invalid --since %q\n%s", v, usage)
+		}
+		e.Since = since
+	}
+	if err := e.Validate(); err != nil {
+		return nil, usageErrorf("%w\n%s", err, usage)
+	}
+	deadline, interval := watchDeadline, watchInterval
+	for flag, d := range map[string]*time.Duration{"--deadline": &deadline, "--interval": &interval} {
+		if v := parseFlag(flag); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return nil, usageErrorf("invalid %s %q\n%s", flag, v, usage)
+			}
+			*d = parsed
+		}
+	}
+	if interval < watch.MinInterval {
+		return nil, usageErrorf("--interval must be at least %s\n%s", watch.MinInterval, usage)
+	}
+	channels, err := notify.ParseChannels(cfg.NotifyWebhooks, cfg.NotifyDigest)
+	if err != nil {
+		return nil, fmt.Errorf("NOTIFY_WEBHOOKS: %w", err)
+	}
+	aliases, err := store.ParseAccountAliases(cfg.AccountAliases)
+	if err != nil {
+		return nil, fmt.Errorf("ACCOUNT_ALIASES: %w", err)
+	}
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("parse encryption key: %w", err)
+	}
+
+	db, err := connectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+	ctx, err = profileContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessionMgr, err := sessionManager(cfg, db.Pool(), keys)
+	if err != nil {
+		return nil, err
+	}
+	defer sessionMgr.Shutdown(context.Background())
+	scraper, err := sessionMgr.GetScraper(ctx, bank.Code(bankCode))
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+
+	label := handler.MaskAccountNumber(e.AccountNumber)
+	progressf("Watching %s %s every %s until %s\n", bankCode, label, interval, time.Now().Add(deadline).Format(time.Kitchen))
+	res, err := watch.Wait(ctx, scraper, e, interval)
+	if err != nil {
+		return res, err
+	}
+	tx := *res.Transaction
+	progressf("Arrived: %s %s on %s after %d polls\n", money.FormatCents(tx.Amount), tx.Description, tx.Date.Format(time.DateOnly), res.Polls)
+
+	sendCtx, cancelSend := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelSend()
+	notify.NewNotifier(nil, channels, nil).WithFullAccountIDs(cfg.FullAccountIDs).Broadcast(sendCtx, notify.Message{Type: notify.TypeArrived, Event: &events.Event{
+		Type:         notify.TypeArrived,
+		Profile:      store.ProfileFrom(ctx),
+		BankCode:     bankCode,
+		AccountID:    e.AccountNumber,
+		AccountAlias: aliases.For(e.AccountNumber),
+		Operation:    "GetTransactions",
+		Data:         tx,
+	}})
+	return res, nil
+}
+
+// readTabular reads f as XLSX or CSV depending on the file extension.
+func readTabular(f *os.File, path string, m importer.Mapping) ([]bank.Transaction, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".xlsx") {
+		return importer.ReadCSV(f, m)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return importer.ReadXLSX(f, info.Size(), m)
+}
+
+// doctor checks what the scrapers need to run, printing one line per check.
+// It returns the checks and false if any failed. With --download it fetches
+// the pinned browser revision when the policy would anyway.
+func doctor() ([]doctorCheck, bool) {
+	ok := true
+	var checks []doctorCheck
+	add := func(status, name, detail string) {
+		checks = append(checks, doctorCheck{Name: name, Status: status, Detail: detail})
+		progressf("%-5s %-9s %s\n", status, name, detail)
+	}
+	report := func(name string, err error, detail string) {
+		status := "ok"
+		if err != nil {
+			status, detail, ok = "FAIL", err.Error(), false
+		}
+		add(status, name, detail)
+	}
+
+	cfg, cfgErr := config.Load()
+	report("config", cfgErr, "environment loaded")
+
+	bc, err := config.LoadBrowser()
+	if err == nil && bc.RemoteURL != "" {
+		report("browser", nil, fmt.Sprintf("remote %s (not contacted)", bc.Redacted().RemoteURL))
+	} else if err == nil {
+		var r browser.Resolver
+		if r, err = scraperfactory.Resolver(*bc); err == nil {
+			var res browser.Resolution
+			if hasFlag("--download") {
+				res, err = r.Resolve(context.Background())
+			} else {
+				res, err = r.Check()
+			}
+			switch {
+			case err == nil && res.Revision != 0:
+				report("browser", nil, fmt.Sprintf("%s (%s, policy %s, revision %d, sha256 %s)", res.Path, res.Source, r.Policy, res.Revision, res.SHA256))
+			case err == nil:
+				report("browser", nil, fmt.Sprintf("%s (%s, policy %s)", res.Path, res.Source, r.Policy))
+			case errors.Is(err, browser.ErrBrowserNotFound) && r.Bin == "" && r.Policy != browser.PolicySystem:
+				// Not a failure: the first scraper downloads it.
+				add("warn", "browser", err.Error()+"; downloaded on first use, or now with --download")
+				err = nil
+			}
+		}
+	}
+	if err != nil {
+		report("browser", err, "")
+	}
+
+	if cfgErr == nil && len(cfg.FeatureFlags) > 0 {
+		_, err := bank.ParseFlags(cfg.FeatureFlags, scraperfactory.KnownFlags)
+		report("flags", err, strings.Join(cfg.FeatureFlags, ", ")+" (experimental)")
+	}
+
+	if cfgErr == nil {
+		db, err := connectDB(cfg)
+		if err == nil {
+			db.Close()
+		}
+		report("database", err, "reachable")
+	}
+	return checks, ok
+}
+
+// doctorCheck is the outcome of one doctor check.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok, warn or FAIL
+	Detail string `json:"detail"`
+}
+
+// reparse runs the current parsers over an archived page (a debug dump, a
+// fixture, or HTML kept with SCRAPER_RAW_HTML) and prints what they extract
+// as JSON, so a parser fix can be checked against the page that exposed the
+// bug. Needs no configuration, browser or database.
+func reparse() (*reparser.Result, error) {
+	code := bank.Code(strings.ToUpper(parseFlag("--bank")))
+	path := parseFlag("--file")
+	if code == "" || path == "" {
+		return nil, usageErrorf("--bank and --file are required\nUsage: bank-scraper reparse --bank=BBVA --file=page.html [--page=auto|%s]", strings.Join(reparser.Pages, "|"))
+	}
+	page := strings.ToLower(parseFlag("--page"))
+
+	html, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *reparser.Result
+	if page == "" || page == "auto" {
+		res, err = reparser.Auto(code, string(html))
+	} else {
+		res, err = reparser.Parse(code, page, string(html))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if jsonOutput() {
+		return res, nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return res, enc.Encode(res)
+}
+
+// selftest runs every bank's parsers over the corpus built into the binary
+// (with -tags fixtures, as make build does), or the one under --dir (a
+// checkout's internal/scraper/bank), printing one line per page. A page that no longer parses to its golden output fails
+// with the parse exit code.
+func selftest() ([]reparser.Check, error) {
+	corpus := bank.Corpus()
+	if dir := parseFlag("--dir"); dir != "" {
+		corpus = os.DirFS(dir)
+	} else if corpus == nil {
+		return nil, usageErrorf("this binary was built without fixtures (go build -tags fixtures); pass --dir=PATH to a checkout's internal/scraper/bank")
+	}
+	checks, err := reparser.SelfTest(corpus)
+	if err != nil {
+		return nil, err
+	}
+	failed := 0
+	for _, c := range checks {
+		status, detail := "ok", c.Page
+		if !c.OK {
+			status, detail = "FAIL", c.Error
+			failed++
+		}
+		progressf("%-5s %s/%-28s %s\n", status, c.Bank, c.Name, detail)
+	}
+	if failed > 0 {
+		return checks, fmt.Errorf("%w: %d of %d corpus pages failed", bank.ErrParsingFailed, failed, len(checks))
+	}
+	return checks, nil
+}
+
+// supportBundle writes a support bundle (see package support) to --file,
+// by default bank-scraper-support-TIMESTAMP.tar.gz in the current
+// directory, with the log file of --logs (or LOG_FILE) and the runs under
+// --run-dir (or RUN_DIR). --artifacts adds the scraper's debug artifacts,
+// from --artifacts-dir or the BBVA scraper's directory. Like doctor, it
+// records what it can't load instead of failing on it.
+func supportBundle() (*support.Manifest, error) {
+	path := cmp.Or(parseFlag("--file"), "bank-scraper-support-"+time.Now().UTC().Format("20060102T150405Z")+".tar.gz")
+	opts := support.Options{
+		Documents: map[string]any{},
+		LogFile:   cmp.Or(parseFlag("--logs"), os.Getenv("LOG_FILE")),
+		RunDir:    cmp.Or(parseFlag("--run-dir"), os.Getenv("RUN_DIR")),
+	}
+	if hasFlag("--artifacts") {
+		opts.Artifacts = cmp.Or(parseFlag("--artifacts-dir"), bbva.DebugDir())
+	}
+
+	if cfg, err := config.Load(); err != nil {
+		opts.Documents["config"] = map[string]string{"error": err.Error()}
+	} else {
+		runConfig = cfg
+		opts.Documents["config"] = cfg.Redacted()
+	}
+	if corpus := bank.Corpus(); corpus == nil {
+		opts.Documents["selftest"] = map[string]string{"error": "built without fixtures"}
+	} else if checks, err := reparser.SelfTest(corpus); err != nil {
+		opts.Documents["selftest"] = map[string]string{"error": err.Error()}
+	} else {
+		opts.Documents["selftest"] = checks
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := support.Build(f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	m.Path = path
+
+	for _, s := range m.Skipped {
+		progressf("skipped %s\n", s)
+	}
+	progressf("wrote %s (%d files); check it before sharing\n", path, len(m.Files))
+	return m, nil
+}
+
+// runDemo runs the whole pipeline against the synthetic bank of package
+// demo, with no credentials or database. The CSV files go to --dir, a new
+// temporary directory by default; --notify sends the notifications to a
+// webhook instead of logging them.
+func runDemo() (*demo.Result, error) {
+	const usage = "Usage: bank-scraper --demo [--dir=PATH] [--notify=URL]"
+	var channels []notify.Channel
+	if u := parseFlag("--notify"); u != "" {
+		var err error
+		if channels, err = notify.ParseChannels([]string{"demo=" + u}, nil); err != nil {
+			return nil, usageErrorf("%w\n%s", err, usage)
+		}
+	}
+	dir := parseFlag("--dir")
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "bank-scraper-demo-"); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	res, err := demo.Run(ctx, demo.Options{Dir: dir, Channels: channels})
+	if err != nil {
+		return nil, err
+	}
+	progressf("Demo bank %s: %d accounts, %d transactions of history, %d new a day later\n", res.Bank, res.Accounts, res.History, res.New)
+	if len(channels) == 0 {
+		progressf("Notifications: %d logged above (pass --notify=URL to send them to a webhook)\n", res.Notified)
+	} else {
+		progressf("Notifications: sent to %s\n", channels[0].URL)
+	}
+	progressf("CSV export: %s\n", res.ExportDir)
+	return res, nil
+}
+
+func connectDB(cfg *config.Config) (*store.DB, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db, err := store.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	return db, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// profileContext scopes ctx to the profile named by --profile, if any (see
+// store.WithProfile).
+func profileContext(ctx context.Context) (context.Context, error) {
+	p := parseFlag("--profile")
+	if p == "" {
+		return ctx, nil
+	}
+	if err := store.ValidateProfile(p); err != nil {
+		return nil, err
+	}
+	return store.WithProfile(ctx, p), nil
+}
+
+// parseFlag extracts a CLI flag value from os.Args.
+// Supports both --flag=value and --flag value forms.
+func parseFlag(name string) string {
+	for i, arg := range os.Args {
+		if v, ok := strings.CutPrefix(arg, name+"="); ok {
+			return v
+		}
+		if arg == name && i+1 < len(os.Args) {
+			return os.Args[i+1]
+		}
+	}
+	return ""
+}
+
+// hasFlag reports whether a boolean CLI flag (e.g. --json) is present in os.Args.
+func hasFlag(name string) bool {
+	return slices.Contains(os.Args, name)
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n  bank-scraper report <report> [flags]\n  bank-scraper import <file> --bank=CODE --account=NUMBER [flags]\n  bank-scraper export csv|parquet|firefly [--dir=PATH] [--profile=NAME]\n  bank-scraper query \"SELECT ...\" [--format=table|csv|json] [--max-rows=N]\n  bank-scraper backfill --bank=CODE [--account=NUMBER] [--count=N] [--profile=NAME]\n  bank-scraper watch --bank=CODE --account=NUMBER [--amount=X] [--from=NAME] [--since=YYYY-MM-DD] [--deadline=2h] [--interval=5m]\n  bank-scraper doctor [--download]\n  bank-scraper reparse --bank=CODE --file=PAGE.html [--page=auto|accounts|transactions|scheduled|detail]\n  bank-scraper selftest [--dir=PATH]\n  bank-scraper support-bundle [--file=PATH] [--logs=PATH] [--artifacts [--artifacts-dir=PATH]]\n  bank-scraper --demo [--dir=PATH] [--notify=URL]\n\nReports:\n")
+	fmt.Fprintf(os.Stderr, "  summary   Per-account balance, last transaction and freshness across all banks\n")
+	fmt.Fprintf(os.Stderr, "            --json          print JSON instead of a table\n")
+	fmt.Fprintf(os.Stderr, "            --usd-pen=RATE  soles per dollar for the PEN-equivalent total (default $FX_USD_PEN)\n")
+	fmt.Fprintf(os.Stderr, "  monthly   Opening balance, transactions, closing balance, ITF and FX totals per account for one month\n")
+	fmt.Fprintf(os.Stderr, "            --month=YYYY-MM         month to close (required)\n")
+	fmt.Fprintf(os.Stderr, "            --format=json|csv|pdf   output format (default json; pdf needs --out and a browser)\n")
+	fmt.Fprintf(os.Stderr, "            --out=FILE              write to FILE instead of stdout\n")
+	fmt.Fprintf(os.Stderr, "            --usd-pen=RATE          soles per dollar for the FX summary (default $FX_USD_PEN)\n")
+	fmt.Fprintf(os.Stderr, "\nEvery command:\n")
+	fmt.Fprintf(os.Stderr, "  --output=text|json      json prints one {schema_version, command, ok, exit_code, result, error} document to stdout, logs to stderr\n")
+	fmt.Fprintf(os.Stderr, "  -v, -q                  debug logging, or warnings and errors only (also LOG_LEVEL)\n")
+	fmt.Fprintf(os.Stderr, "  --log-json              log JSON lines (also LOG_FORMAT=json)\n")
+	fmt.Fprintf(os.Stderr, "  --log-file=PATH         append logs to PATH instead of stderr (also LOG_FILE)\n")
+	fmt.Fprintf(os.Stderr, "  --log-full-account-ids  log account numbers in full, not just their last 4 digits (also LOG_FULL_ACCOUNT_IDS)\n")
+	fmt.Fprintf(os.Stderr, "  --run-dir=PATH          write a run.json summary into a timestamped folder under PATH (also RUN_DIR)\n")
+	fmt.Fprintf(os.Stderr, "\nImport flags:\n")
+	fmt.Fprintf(os.Stderr, "  --format=bbva|csv|json  statement format (default: json for .json files, bbva otherwise)\n")
+	fmt.Fprintf(os.Stderr, "  --map=SPEC              column mapping for --format=csv, e.g. date=Fecha,description=Detalle,amount=Monto\n")
+	fmt.Fprintf(os.Stderr, "  --dry-run               parse and report without writing to the database\n")
 }
@@ -0,0 +1,159 @@
+// Read-only dashboard: everything comes from GET endpoints of /api/v1, with
+// the API key the user enters. Balances and transactions are taken from the
+// latest scrape job results, so opening the page never causes a bank login.
+(function () {
+  'use strict';
+
+  const KEY = 'bank-scraper.api-key';
+  const REFRESH_MS = 60 * 1000;
+  const RUNS = 20;
+  const RECENT_TRANSACTIONS = 25;
+
+  const $ = (id) => document.getElementById(id);
+
+  async function get(path) {
+    const res = await fetch('/api/v1' + path, { headers: { 'X-API-Key': sessionStorage.getItem(KEY) } });
+    if (res.status === 401 || res.status === 403) {
+      sessionStorage.removeItem(KEY);
+      throw new Error('API key rejected');
+    }
+    if (!res.ok) throw new Error(path + ': HTTP ' + res.status);
+    return res.json();
+  }
+
+  function el(tag, attrs, ...children) {
+    const node = document.createElement(tag);
+    Object.entries(attrs || {}).forEach(([k, v]) => { node[k] = v; });
+    children.forEach((c) => node.append(c instanceof Node ? c : document.createTextNode(c == null ? '' : String(c))));
+    return node;
+  }
+
+  function table(node, headers, rows) {
+    node.replaceChildren(el('tr', {}, ...headers.map((h) => el('th', {}, h))), ...rows);
+    if (rows.length === 0) node.append(el('tr', {}, el('td', { colSpan: headers.length, className: 'muted' }, 'Nothing yet')));
+  }
+
+  function when(iso) {
+    return iso ? new Date(iso).toLocaleString() : '-';
+  }
+
+  function statusClass(status) {
+    switch (status) {
+      case 'healthy': case 'succeeded': case 'active': return 'ok';
+      case 'degraded': case 'queued': case 'running': return 'warn';
+      default: return 'bad';
+    }
+  }
+
+  function renderHealth(h) {
+    const items = [el('span', { className: 'badge ' + statusClass(h.status) }, 'System ' + h.status)];
+    Object.entries(h.banks || {}).sort().forEach(([bank, b]) => {
+      items.push(el('span', { className: 'badge ' + statusClass(b.status),
+        title: b.error_message || ('last connected ' + when(b.last_successful_connection)) }, bank + ' ' + b.status));
+    });
+    if (h.scrape_jobs) {
+      const j = h.scrape_jobs;
+      items.push(el('span', { className: 'badge muted' },
+        `Jobs: ${j.queued} queued, ${j.running} running, ${j.succeeded} ok, ${j.degraded} degraded, ${j.failed} failed`));
+    }
+    $('health').replaceChildren(...items);
+  }
+
+  // latestByAccount picks, per account, the most recent job result that has
+  // a balance. jobs are newest first.
+  function latestByAccount(jobs) {
+    const out = {};
+    jobs.forEach((j) => (j.accounts || []).forEach((a) => {
+      if (!out[a.account_id] && a.available_balance != null) out[a.account_id] = { job: j, result: a };
+    }));
+    return out;
+  }
+
+  function accountName(a) {
+    return a ? (a.alias || a.account_number) : '?';
+  }
+
+  function render(health, accounts, jobs) {
+    renderHealth(health);
+
+    const byID = {};
+    accounts.forEach((a) => { byID[a.account_id] = a; });
+    const latest = latestByAccount(jobs);
+
+    table($('balances'), ['Bank', 'Account', 'Currency', 'Available', 'Current', 'As of'],
+      accounts.map((a) => {
+        const l = latest[a.account_id];
+        return el('tr', {},
+          el('td', {}, a.bank_code), el('td', {}, accountName(a)), el('td', {}, a.currency),
+          el('td', { className: 'num' }, l ? l.result.available_balance : '-'),
+          el('td', { className: 'num' }, l ? l.result.current_balance : '-'),
+          el('td', {}, when(l ? l.job.finished_at : a.last_sync)));
+      }));
+
+    const seen = new Set();
+    const txns = [];
+    Object.values(latest).forEach(({ result }) => (result.transactions || []).forEach((t) => {
+      const key = result.account_id + '/' + t.id + '/' + t.date + '/' + t.amount;
+      if (seen.has(key)) return;
+      seen.add(key);
+      txns.push({ account: byID[result.account_id], t: t });
+    }));
+    txns.sort((a, b) => (a.t.date < b.t.date ? 1 : a.t.date > b.t.date ? -1 : 0));
+    table($('transactions'), ['Date', 'Account', 'Description', 'Amount', 'Balance'],
+      txns.slice(0, RECENT_TRANSACTIONS).map(({ account, t }) => el('tr', {},
+        el('td', {}, t.date.slice(0, 10)), el('td', {}, accountName(account)),
+        el('td', {}, t.clean_description || t.description),
+        el('td', { className: 'num ' + (t.type === 'DEBIT' ? 'bad' : 'ok') }, (t.type === 'DEBIT' ? '-' : '') + t.amount),
+        el('td', { className: 'num' }, t.balance_after || ''))));
+
+    table($('runs'), ['Created', 'Bank', 'Source', 'Status', 'Finished', 'Log'],
+      jobs.map((j) => {
+        const log = [];
+        if (j.error) log.push(j.error);
+        (j.accounts || []).filter((a) => a.error).forEach((a) => log.push(accountName(byID[a.account_id]) + ': ' + a.error));
+        (j.warnings || []).forEach((w) => log.push(w.operation + ': ' + w.message));
+        (j.requests || []).filter((r) => r.error || r.status >= 400).forEach((r) =>
+          log.push(`${r.operation}: ${r.method} ${r.url} ${r.status || ''} ${r.error || ''}`));
+        return el('tr', {},
+          el('td', {}, when(j.created_at)), el('td', {}, j.bank_code), el('td', {}, j.source),
+          el('td', { className: statusClass(j.status) }, j.status), el('td', {}, when(j.finished_at)),
+          el('td', {}, log.length === 0 ? '' : el('details', {}, el('summary', {}, log.length + ' message(s)'),
+            ...log.map((m) => el('div', { className: 'muted' }, m)))));
+      }));
+  }
+
+  async function refresh() {
+    try {
+      const [health, accounts, runs] = await Promise.all([get('/health'), get('/accounts'), get('/scrape?limit=' + RUNS)]);
+      render(health, accounts.accounts || [], runs.jobs || []);
+      $('status').textContent = 'Updated ' + new Date().toLocaleTimeString();
+    } catch (err) {
+      $('status').textContent = err.message;
+      if (!sessionStorage.getItem(KEY)) show();
+    }
+  }
+
+  let timer;
+  function show() {
+    const authed = !!sessionStorage.getItem(KEY);
+    $('login').hidden = authed;
+    $('content').hidden = !authed;
+    clearInterval(timer);
+    if (authed) {
+      refresh();
+      timer = setInterval(refresh, REFRESH_MS);
+    }
+  }
+
+  $('login').addEventListener('submit', (e) => {
+    e.preventDefault();
+    sessionStorage.setItem(KEY, $('key').value.trim());
+    $('key').value = '';
+    show();
+  });
+  $('logout').addEventListener('click', () => {
+    sessionStorage.removeItem(KEY);
+    show();
+  });
+  show();
+})();
@@ -0,0 +1,15 @@
+// Package dashboard embeds the read-only web dashboard the API server
+// serves at /dashboard/. It is a static page that reads the REST API with an
+// API key the user enters, so it shows nothing the key couldn't read.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// FS holds the dashboard's static files, index.html at its root.
+var FS, _ = fs.Sub(assets, "assets")
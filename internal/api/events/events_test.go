@@ -0,0 +1,276 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_PublishSubscribe(t *testing.T) {
+	hub := NewHub(3)
+	hub.Publish(Event{Type: "a"})
+
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+
+	hub.Publish(Event{Type: "b", BankCode: "BBVA"})
+	e := <-ch
+	assert.Equal(t, uint64(2), e.ID)
+	assert.Equal(t, "b", e.Type)
+	assert.False(t, e.Time.IsZero())
+}
+
+func TestHub_Replay(t *testing.T) {
+	hub := NewHub(3)
+	for _, typ := range []string{"a", "b", "c", "d", "e"} {
+		hub.Publish(Event{Type: typ})
+	}
+
+	replay, _, cancel := hub.Subscribe(3)
+	defer cancel()
+	require.Len(t, replay, 2)
+	assert.Equal(t, []string{"d", "e"}, []string{replay[0].Type, replay[1].Type})
+
+	replay, _, cancel2 := hub.Subscribe(1)
+	defer cancel2()
+	require.Len(t, replay, 3, "only what the history still holds")
+	assert.Equal(t, "c", replay[0].Type)
+}
+
+func TestHub_SlowSubscriberDisconnected(t *testing.T) {
+	hub := NewHub(0)
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+
+	for range subscriberBuffer + 1 {
+		hub.Publish(Event{Type: "x"})
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.Equal(t, subscriberBuffer, n, "channel closed after the buffer filled")
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(0)
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+
+	hub.Close()
+	_, open := <-ch
+	assert.False(t, open)
+
+	hub.Publish(Event{Type: "ignored"})
+	_, ch2, _ := hub.Subscribe(0)
+	_, open = <-ch2
+	assert.False(t, open)
+
+	var nilHub *Hub
+	nilHub.Publish(Event{Type: "noop"})
+}
+
+type mockProvider struct {
+	scraper bank.Scraper
+	err     error
+}
+
+func (m *mockProvider) GetScraper(_ context.Context, _ bank.Code) (bank.Scraper, error) {
+	return m.scraper, m.err
+}
+
+func (m *mockProvider) Invalidate(context.Context, bank.Code) {}
+
+// drain returns the events currently buffered on ch.
+func drain(ch <-chan Event) []Event {
+	var out []Event
+	for {
+		select {
+		case e := <-ch:
+			out = append(out, e)
+		default:
+			return out
+		}
+	}
+}
+
+func types(evs []Event) []string {
+	out := make([]string, len(evs))
+	for i, e := range evs {
+		out[i] = e.Type
+	}
+	return out
+}
+
+func TestProvider_NewTransactions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	mock := &banktest.MockScraper{Transactions: []bank.Transaction{
+		{Date: day(2), Description: "B", Amount: 200, Type: bank.TransactionDebit},
+		{Date: day(1), Description: "A", Amount: 100, Type: bank.TransactionCredit},
+	}}
+	hub := NewHub(0)
+	p := NewProvider(&mockProvider{scraper: mock}, hub)
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+	ctx := context.Background()
+
+	s, err := p.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+	_, err = s.GetTransactions(ctx, "001", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TypeScrapeStarted, TypeScrapeCompleted}, types(drain(ch)), "first fetch is the baseline")
+
+	// Two new movements on top, plus an older one that only appears because
+	// this fetch reaches further back.
+	mock.Transactions = append([]bank.Transaction{
+		{Date: day(4), Description: "D", Amount: 400, Type: bank.TransactionCredit},
+		{Date: day(3), Description: "C", Amount: 300, Type: bank.TransactionDebit},
+	}, append(mock.Transactions,
+		bank.Transaction{Date: day(1).AddDate(0, 0, -5), Description: "OLD", Amount: 1, Type: bank.TransactionDebit})...)
+
+	_, err = s.GetTransactions(ctx, "001", 50)
+	require.NoError(t, err)
+	evs := drain(ch)
+	require.Equal(t, []string{TypeScrapeStarted, TypeScrapeCompleted, TypeTransactionNew, TypeTransactionNew}, types(evs))
+	assert.Equal(t, 2, evs[1].Data.(map[string]any)["new"])
+	assert.Equal(t, "C", evs[2].Data.(bank.Transaction).Description, "oldest first")
+	assert.Equal(t, "D", evs[3].Data.(bank.Transaction).Description)
+	assert.Equal(t, "001", evs[3].AccountID)
+	assert.Equal(t, "BBVA", evs[3].BankCode)
+
+	_, err = s.GetTransactions(ctx, "001", 50)
+	require.NoError(t, err)
+	assert.Len(t, drain(ch), 2, "nothing new on refetch")
+}
+
+func TestProvider_OverlappingFetches(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	fee := bank.Transaction{Date: day(3), Description: "ITF", Amount: 5, Type: bank.TransactionDebit}
+	mock := &banktest.MockScraper{Transactions: []bank.Transaction{
+		fee, fee,
+		{Date: day(2), Description: "B", Amount: 200, Type: bank.TransactionDebit},
+		{Date: day(1), Description: "A1", Amount: 100, Type: bank.TransactionCredit},
+	}}
+	hub := NewHub(0)
+	p := NewProvider(&mockProvider{scraper: mock}, hub)
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+	ctx := context.Background()
+	s, err := p.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+
+	newTxns := func(count int) []string {
+		t.Helper()
+		_, err := s.GetTransactions(ctx, "001", count)
+		require.NoError(t, err)
+		var out []string
+		for _, e := range drain(ch) {
+			if e.Type == TypeTransactionNew {
+				out = append(out, e.Data.(bank.Transaction).Description)
+			}
+		}
+		return out
+	}
+
+	// A full fetch: day 1 may have been cut short.
+	assert.Empty(t, newTxns(4), "baseline")
+
+	// Reaching further back reveals more of day 1, and day 0: not news.
+	mock.Transactions = append(mock.Transactions,
+		bank.Transaction{Date: day(1), Description: "A2", Amount: 150, Type: bank.TransactionCredit},
+		bank.Transaction{Date: day(1).AddDate(0, 0, -1), Description: "Z", Amount: 1, Type: bank.TransactionDebit})
+	assert.Empty(t, newTxns(50))
+
+	// The bank reorders same-day rows and a third fee arrives.
+	mock.Transactions = append([]bank.Transaction{
+		mock.Transactions[2], fee, fee, fee,
+	}, mock.Transactions[3:]...)
+	assert.Equal(t, []string{"ITF"}, newTxns(50), "only the extra fee")
+}
+
+func TestProvider_WithDedup(t *testing.T) {
+	fee := func(doc string) bank.Transaction {
+		return bank.Transaction{ID: doc, Date: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Description: "ITF", Amount: 5, Type: bank.TransactionDebit}
+	}
+	mock := &banktest.MockScraper{Transactions: []bank.Transaction{fee("2"), fee("1")}}
+	hub := NewHub(0)
+	p := NewProvider(&mockProvider{scraper: mock}, hub).WithDedup(store.DedupConfig{"BBVA": store.DedupDocument})
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+	ctx := context.Background()
+	s, err := p.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+
+	_, err = s.GetTransactions(ctx, "001", 50)
+	require.NoError(t, err)
+	mock.Transactions = []bank.Transaction{fee("3"), fee("2"), fee("1")}
+	_, err = s.GetTransactions(ctx, "001", 50)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, e := range drain(ch) {
+		if e.Type == TypeTransactionNew {
+			ids = append(ids, e.Data.(bank.Transaction).ID)
+		}
+	}
+	assert.Equal(t, []string{"3"}, ids, "the fee with the new document number")
+}
+
+func TestProvider_WithNewTransactionsDisabled(t *testing.T) {
+	mock := &banktest.MockScraper{}
+	hub := NewHub(0)
+	p := NewProvider(&mockProvider{scraper: mock}, hub).WithNewTransactions(false)
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+	ctx := context.Background()
+	s, err := p.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+
+	_, err = s.GetTransactions(ctx, "001", 50)
+	require.NoError(t, err)
+	mock.Transactions = []bank.Transaction{{Date: time.Now(), Description: "A", Amount: 100, Type: bank.TransactionCredit}}
+	_, err = s.GetTransactions(ctx, "001", 50)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TypeScrapeStarted, TypeScrapeCompleted, TypeScrapeStarted, TypeScrapeCompleted}, types(drain(ch)))
+}
+
+func TestProvider_Failures(t *testing.T) {
+	hub := NewHub(0)
+	_, ch, cancel := hub.Subscribe(0)
+	defer cancel()
+	ctx := context.Background()
+
+	_, err := NewProvider(&mockProvider{err: bank.ErrBankUnavailable}, hub).GetScraper(ctx, bank.BankBBVA)
+	require.ErrorIs(t, err, bank.ErrBankUnavailable)
+
+	mock := &banktest.MockScraper{BalanceErr: errors.New("boom")}
+	s, err := NewProvider(&mockProvider{scraper: mock}, hub).GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+	_, err = s.GetBalance(ctx)
+	require.Error(t, err)
+
+	evs := drain(ch)
+	require.Equal(t, []string{TypeSessionFailed, TypeScrapeStarted, TypeScrapeFailed}, types(evs))
+	assert.Equal(t, "boom", evs[2].Data.(map[string]any)["error"])
+	assert.Equal(t, "GetBalance", evs[2].Operation)
+}
+
+func TestProvider_KeepsScheduledCapability(t *testing.T) {
+	mock := &banktest.MockScraper{Scheduled: []bank.ScheduledOperation{{ID: "1"}}}
+	s, err := NewProvider(&mockProvider{scraper: mock}, NewHub(0)).GetScraper(context.Background(), bank.BankBBVA)
+	require.NoError(t, err)
+
+	so, ok := s.(bank.ScheduledOperationsScraper)
+	require.True(t, ok)
+	ops, err := so.GetScheduledOperations(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, ops, 1)
+}
@@ -0,0 +1,159 @@
+// Package events publishes scrape lifecycle events and newly seen
+// transactions to live subscribers (the SSE endpoint), so dashboards can
+// update without polling.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	TypeScrapeStarted   = "scrape.started"
+	TypeScrapeCompleted = "scrape.completed"
+	TypeScrapeFailed    = "scrape.failed"
+	TypeSessionFailed   = "session.failed"
+	TypeTransactionNew  = "transaction.new"
+	TypeJobSucceeded    = "job.succeeded"
+	TypeJobDegraded     = "job.degraded" // some accounts failed
+	TypeJobFailed       = "job.failed"
+)
+
+// Event is one published occurrence. ID increases monotonically per Hub so
+// clients can resume with Last-Event-ID.
+type Event struct {
+	ID           uint64    `json:"id"`
+	Type         string    `json:"type"`
+	Profile      string    `json:"profile,omitempty"` // Profile whose session or job it is
+	BankCode     string    `json:"bank_code,omitempty"`
+	AccountID    string    `json:"account_id,omitempty"`    // Bank account number the event concerns
+	AccountAlias string    `json:"account_alias,omitempty"` // Its name from ACCOUNT_ALIASES
+	Operation    string    `json:"operation,omitempty"`     // Scraper method, e.g. "GetTransactions"
+	Time         time.Time `json:"time"`
+	Data         any       `json:"data,omitempty"`
+}
+
+// DefaultHistory is how many recent events a Hub keeps for replay.
+const DefaultHistory = 256
+
+// subscriberBuffer is each subscriber's channel capacity. A subscriber that
+// falls this far behind is disconnected rather than slowing publishers.
+const subscriberBuffer = 64
+
+// Hub fans events out to subscribers. Publish never blocks on a slow
+// subscriber: its channel is closed and it has to reconnect, replaying what
+// it missed from the history via Last-Event-ID.
+type Hub struct {
+	mu      sync.Mutex
+	nextID  uint64
+	history []Event // ring buffer, oldest first once full
+	start   int
+	size    int
+	subs    map[chan Event]struct{}
+	closed  bool
+	now     func() time.Time
+}
+
+// NewHub creates a hub keeping the last history events for replay.
+func NewHub(history int) *Hub {
+	if history < 1 {
+		history = DefaultHistory
+	}
+	return &Hub{
+		history: make([]Event, history),
+		subs:    make(map[chan Event]struct{}),
+		now:     time.Now,
+	}
+}
+
+// Publish assigns e an ID and timestamp and delivers it to all subscribers.
+// Publishing on a nil or closed Hub is a no-op.
+func (h *Hub) Publish(e Event) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.nextID++
+	e.ID = h.nextID
+	if e.Time.IsZero() {
+		e.Time = h.now()
+	}
+
+	idx := (h.start + h.size) % len(h.history)
+	h.history[idx] = e
+	if h.size < len(h.history) {
+		h.size++
+	} else {
+		h.start = (h.start + 1) % len(h.history)
+	}
+
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. Events after lastID still in the history
+// are returned as replay; live events arrive on the channel, which is closed
+// when the subscriber falls behind or the hub closes. cancel must be called
+// to unsubscribe.
+func (h *Hub) Subscribe(lastID uint64) (replay []Event, ch <-chan Event, cancel func()) {
+	c := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if lastID > 0 {
+		for i := 0; i < h.size; i++ {
+			if e := h.history[(h.start+i)%len(h.history)]; e.ID > lastID {
+				replay = append(replay, e)
+			}
+		}
+	}
+	if h.closed {
+		close(c)
+		return replay, c, func() {}
+	}
+	h.subs[c] = struct{}{}
+
+	return replay, c, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[c]; ok {
+			delete(h.subs, c)
+			close(c)
+		}
+	}
+}
+
+// Closed reports whether Close was called.
+func (h *Hub) Closed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+// Close disconnects all subscribers and stops accepting events. Call it
+// before shutting the HTTP server down so open streams end.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
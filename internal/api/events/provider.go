@@ -0,0 +1,255 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// seenRetention bounds the per-account memory of seen transactions: hashes of
+// transactions older than this (relative to the newest seen) are dropped.
+const seenRetention = 180 * 24 * time.Hour
+
+// ScraperProvider matches the handler.ScraperProvider interface.
+type ScraperProvider interface {
+	GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error)
+	Invalidate(ctx context.Context, bankCode bank.Code)
+}
+
+// Provider wraps a ScraperProvider so every balance and transaction fetch
+// publishes lifecycle events, and transactions not seen in earlier fetches of
+// the same account publish TypeTransactionNew. It satisfies
+// handler.ScraperProvider.
+//
+// The first fetch of an account only records a baseline: what the bank
+// returns then is history, not news.
+type Provider struct {
+	inner   ScraperProvider
+	hub     *Hub
+	dedup   store.DedupConfig
+	aliases store.AccountAliases
+	quiet   bool // No TypeTransactionNew events
+
+	mu   sync.Mutex
+	seen map[string]*seenAccount // keyed by profile/bank/account
+}
+
+// seenAccount is what earlier fetches of an account returned.
+type seenAccount struct {
+	known       map[string]time.Time // dedup hash → operation date
+	coveredFrom time.Time            // earliest day fetched completely; zero for all
+}
+
+// NewProvider creates an event-publishing wrapper around inner.
+func NewProvider(inner ScraperProvider, hub *Hub) *Provider {
+	return &Provider{
+		inner: inner,
+		hub:   hub,
+		seen:  make(map[string]*seenAccount),
+	}
+}
+
+// WithDedup tells the same-day duplicates of each bank apart the way stored
+// transactions do, so what counts as new matches what an import would insert.
+func (p *Provider) WithDedup(c store.DedupConfig) *Provider {
+	p.dedup = c
+	return p
+}
+
+// WithAliases names the account of each event (Event.AccountAlias).
+func (p *Provider) WithAliases(a store.AccountAliases) *Provider {
+	p.aliases = a
+	return p
+}
+
+// WithNewTransactions(false) stops publishing TypeTransactionNew, for
+// callers that publish them after a pipeline's own dedupe (see
+// pipeline.Notify). Scrape events still go out.
+func (p *Provider) WithNewTransactions(enabled bool) *Provider {
+	p.quiet = !enabled
+	return p
+}
+
+// GetScraper returns the inner provider's scraper, instrumented. Scrapers
+// implementing bank.ScheduledOperationsScraper keep doing so.
+func (p *Provider) GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error) {
+	s, err := p.inner.GetScraper(ctx, bankCode)
+	if errors.Is(err, session.ErrLoginNotAllowed) {
+		return nil, err // a read-only caller, not a failed session
+	}
+	if err != nil {
+		p.hub.Publish(Event{
+			Type:     TypeSessionFailed,
+			Profile:  store.ProfileFrom(ctx),
+			BankCode: string(bankCode),
+			Data:     map[string]string{"error": err.Error()},
+		})
+		return nil, err
+	}
+
+	w := &scraper{Scraper: s, provider: p, profile: store.ProfileFrom(ctx), bankCode: bankCode}
+	if so, ok := s.(bank.ScheduledOperationsScraper); ok {
+		return &scheduledScraper{scraper: w, sched: so}, nil
+	}
+	return w, nil
+}
+
+// Invalidate passes through to the inner provider.
+func (p *Provider) Invalidate(ctx context.Context, bankCode bank.Code) {
+	p.inner.Invalidate(ctx, bankCode)
+}
+
+// observe records a fetch of count transactions as seen for the account and
+// returns those that are new: unseen, and on a day earlier fetches covered
+// completely. Rows older than that are backfill from a fetch reaching
+// further back, and rows on the cut-off oldest day of a full fetch may have
+// existed all along, so neither is news.
+func (p *Provider) observe(profile string, bankCode bank.Code, accountID string, txns []bank.Transaction, count int) []bank.Transaction {
+	key := profile + "/" + string(bankCode) + "/" + accountID
+	records := p.dedup.For(string(bankCode)).NewTransactions(uuid.Nil, "", txns)
+	covered := store.CoveredFrom(txns, count)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	acct, hasBaseline := p.seen[key]
+	if !hasBaseline {
+		acct = &seenAccount{known: make(map[string]time.Time, len(txns)), coveredFrom: covered}
+		p.seen[key] = acct
+	}
+
+	var newest time.Time
+	for _, d := range acct.known {
+		if d.After(newest) {
+			newest = d
+		}
+	}
+
+	var fresh []bank.Transaction
+	for i, rec := range records {
+		h := string(rec.DedupHash)
+		if _, ok := acct.known[h]; ok {
+			continue
+		}
+		acct.known[h] = rec.OperationDate
+		if rec.OperationDate.After(newest) {
+			newest = rec.OperationDate
+		}
+		if hasBaseline && !rec.OperationDate.Before(acct.coveredFrom) {
+			fresh = append(fresh, txns[i])
+		}
+	}
+	if !acct.coveredFrom.IsZero() && !covered.IsZero() && covered.Before(acct.coveredFrom) {
+		acct.coveredFrom = covered
+	}
+
+	if newest.IsZero() {
+		return fresh
+	}
+	cutoff := newest.Add(-seenRetention)
+	for h, d := range acct.known {
+		if d.Before(cutoff) {
+			delete(acct.known, h)
+		}
+	}
+	// Forgotten days are no longer covered.
+	if acct.coveredFrom.Before(cutoff) {
+		acct.coveredFrom = cutoff
+	}
+	return fresh
+}
+
+// scraper publishes events around the data-fetching methods of a bank.Scraper.
+type scraper struct {
+	bank.Scraper
+	provider *Provider
+	profile  string
+	bankCode bank.Code
+}
+
+func (s *scraper) publish(typ, accountID, operation string, data any) {
+	s.provider.hub.Publish(Event{
+		Type:         typ,
+		Profile:      s.profile,
+		BankCode:     string(s.bankCode),
+		AccountID:    accountID,
+		AccountAlias: s.provider.aliases.For(accountID),
+		Operation:    operation,
+		Data:         data,
+	})
+}
+
+// finish publishes the completed or failed event for an operation.
+func (s *scraper) finish(accountID, operation string, started time.Time, err error, data map[string]any) {
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["duration_ms"] = time.Since(started).Milliseconds()
+	if err != nil {
+		data["error"] = err.Error()
+		s.publish(TypeScrapeFailed, accountID, operation, data)
+		return
+	}
+	s.publish(TypeScrapeCompleted, accountID, operation, data)
+}
+
+// GetBalance wraps the inner GetBalance with scrape events.
+func (s *scraper) GetBalance(ctx context.Context) ([]bank.Balance, error) {
+	const op = "GetBalance"
+	started := time.Now()
+	s.publish(TypeScrapeStarted, "", op, nil)
+
+	balances, err := s.Scraper.GetBalance(ctx)
+	s.finish("", op, started, err, map[string]any{"accounts": len(balances)})
+	return balances, err
+}
+
+// GetTransactions wraps the inner GetTransactions with scrape events and
+// publishes a TypeTransactionNew event per newly seen transaction, oldest
+// first (scrapers return newest first).
+func (s *scraper) GetTransactions(ctx context.Context, accountID string, count int) ([]bank.Transaction, error) {
+	const op = "GetTransactions"
+	started := time.Now()
+	s.publish(TypeScrapeStarted, accountID, op, nil)
+
+	txns, err := s.Scraper.GetTransactions(ctx, accountID, count)
+	if err != nil {
+		s.finish(accountID, op, started, err, nil)
+		return txns, err
+	}
+	if s.provider.quiet {
+		s.finish(accountID, op, started, nil, map[string]any{"transactions": len(txns)})
+		return txns, nil
+	}
+
+	fresh := s.provider.observe(s.profile, s.bankCode, accountID, txns, count)
+	s.finish(accountID, op, started, nil, map[string]any{"transactions": len(txns), "new": len(fresh)})
+	for i := len(fresh) - 1; i >= 0; i-- {
+		s.publish(TypeTransactionNew, accountID, op, fresh[i])
+	}
+	return txns, nil
+}
+
+// scheduledScraper keeps the optional ScheduledOperationsScraper capability
+// visible through the wrapper.
+type scheduledScraper struct {
+	*scraper
+	sched bank.ScheduledOperationsScraper
+}
+
+// GetScheduledOperations wraps the inner call with scrape events.
+func (s *scheduledScraper) GetScheduledOperations(ctx context.Context) ([]bank.ScheduledOperation, error) {
+	const op = "GetScheduledOperations"
+	started := time.Now()
+	s.publish(TypeScrapeStarted, "", op, nil)
+
+	ops, err := s.sched.GetScheduledOperations(ctx)
+	s.finish("", op, started, err, map[string]any{"operations": len(ops)})
+	return ops, err
+}
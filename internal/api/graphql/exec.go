@@ -0,0 +1,350 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Object is a GraphQL object type.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field is a field of an Object. Type is the object type it resolves to, nil
+// for scalars (strings, numbers, booleans). A field whose Resolve returns a
+// slice is a list of Type. Sig is its arguments and type in SDL, e.g.
+// "(first: Int = 50): [Account!]!"; the arguments it names are the ones
+// the field accepts.
+type Field struct {
+	Type    *Object
+	Sig     string
+	Resolve func(p Params) (any, error)
+}
+
+// acceptsArg reports whether f's signature declares the argument name.
+func (f *Field) acceptsArg(name string) bool {
+	args, _, ok := strings.Cut(strings.TrimPrefix(f.Sig, "("), ")")
+	if !ok || !strings.HasPrefix(f.Sig, "(") {
+		return false
+	}
+	for _, a := range strings.Split(args, ",") {
+		if n, _, _ := strings.Cut(a, ":"); strings.TrimSpace(n) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Params is what a resolver gets: the parent value and the field's
+// arguments, with variables already substituted.
+type Params struct {
+	Context context.Context
+	Source  any
+	Args    map[string]any
+}
+
+// String returns a string argument, or "" when not given.
+func (p Params) String(name string) (string, error) {
+	switch v := p.Args[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case enumValue:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("argument %q must be a string", name)
+	}
+}
+
+// Int returns an integer argument, or def when not given.
+func (p Params) Int(name string, def int) (int, error) {
+	switch v := p.Args[name].(type) {
+	case nil:
+		return def, nil
+	case int64:
+		return int(v), nil
+	case float64: // JSON variables
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %q must be an integer", name)
+}
+
+// Request is the body of a GraphQL HTTP request.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is nil when the request could
+// not be executed at all.
+type Response struct {
+	Data   any     `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error, with the path of the field that failed.
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Schema is an executable schema with a root query type.
+type Schema struct {
+	Query *Object
+}
+
+// Execute runs a query request. Validation errors (unknown fields, bad
+// selections) fail the whole request; resolver errors null their field and
+// are reported next to the data that did resolve.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	ops, err := parse(req.Query)
+	if err != nil {
+		return errorResponse(err)
+	}
+	op, err := pickOperation(ops, req.OperationName)
+	if err != nil {
+		return errorResponse(err)
+	}
+	vars, err := coerceVariables(op.vars, req.Variables)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if err := validate(s.Query, op.selections); err != nil {
+		return errorResponse(err)
+	}
+
+	e := &executor{ctx: ctx, vars: vars}
+	data := e.object(s.Query, nil, op.selections, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func errorResponse(err error) *Response {
+	return &Response{Errors: []Error{{Message: err.Error()}}}
+}
+
+func pickOperation(ops []*operation, name string) (*operation, error) {
+	if name == "" {
+		if len(ops) > 1 {
+			return nil, errors.New("operationName is required for documents with several operations")
+		}
+		return ops[0], nil
+	}
+	for _, op := range ops {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+func coerceVariables(defs []varDef, given map[string]any) (map[string]any, error) {
+	vars := map[string]any{}
+	for _, d := range defs {
+		v, ok := given[d.name]
+		switch {
+		case ok:
+			vars[d.name] = v
+		case d.hasDef:
+			vars[d.name] = d.def
+		}
+		if vars[d.name] == nil && d.nonNull {
+			return nil, fmt.Errorf("variable $%s of type %s! is required", d.name, d.typeName)
+		}
+	}
+	return vars, nil
+}
+
+func validate(obj *Object, sels []*selection) error {
+	for _, sel := range sels {
+		if sel.name == "__typename" {
+			continue
+		}
+		if strings.HasPrefix(sel.name, "__") {
+			return fmt.Errorf("introspection (%s) is not supported; see GET /api/v1/graphql for the schema", sel.name)
+		}
+		f, ok := obj.Fields[sel.name]
+		if !ok {
+			return fmt.Errorf("cannot query field %q on type %s", sel.name, obj.Name)
+		}
+		for _, a := range sel.args {
+			if !f.acceptsArg(a.name) {
+				return fmt.Errorf("unknown argument %q on field %s.%s", a.name, obj.Name, sel.name)
+			}
+		}
+		switch {
+		case f.Type == nil && sel.selections != nil:
+			return fmt.Errorf("field %s.%s is a scalar and can't have a selection", obj.Name, sel.name)
+		case f.Type != nil && sel.selections == nil:
+			return fmt.Errorf("field %s.%s of type %s needs a selection", obj.Name, sel.name, f.Type.Name)
+		case f.Type != nil:
+			if err := validate(f.Type, sel.selections); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type executor struct {
+	ctx    context.Context
+	vars   map[string]any
+	errors []Error
+}
+
+// object resolves sels on src, an object of type obj.
+func (e *executor) object(obj *Object, src any, sels []*selection, path []any) *orderedMap {
+	out := &orderedMap{}
+	for _, sel := range sels {
+		fieldPath := append(append([]any(nil), path...), sel.key())
+		if sel.name == "__typename" {
+			out.set(sel.key(), obj.Name)
+			continue
+		}
+		f := obj.Fields[sel.name]
+		args := map[string]any{}
+		for _, a := range sel.args {
+			args[a.name] = e.resolveValue(a.value)
+		}
+		v, err := f.Resolve(Params{Context: e.ctx, Source: src, Args: args})
+		if err != nil {
+			e.errors = append(e.errors, Error{Message: err.Error(), Path: fieldPath})
+			out.set(sel.key(), nil)
+			continue
+		}
+		out.set(sel.key(), e.complete(f.Type, v, sel.selections, fieldPath))
+	}
+	return out
+}
+
+// complete turns a resolved value into its response: scalars as they are,
+// objects and lists of objects by resolving the selection on them.
+func (e *executor) complete(typ *Object, v any, sels []*selection, path []any) any {
+	if typ == nil || v == nil {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+	if rv.Kind() != reflect.Slice {
+		return e.object(typ, v, sels, path)
+	}
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = e.complete(typ, rv.Index(i).Interface(), sels, append(append([]any(nil), path...), i))
+	}
+	return list
+}
+
+func (e *executor) resolveValue(v any) any {
+	switch v := v.(type) {
+	case variable:
+		return e.vars[string(v)]
+	case []any:
+		out := make([]any, len(v))
+		for i := range v {
+			out[i] = e.resolveValue(v[i])
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k := range v {
+			out[k] = e.resolveValue(v[k])
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// orderedMap is a JSON object that keeps its keys in selection order, as
+// GraphQL responses must.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(k string, v any) {
+	if m.values == nil {
+		m.values = map[string]any{}
+	}
+	if _, ok := m.values[k]; !ok {
+		m.keys = append(m.keys, k)
+	}
+	m.values[k] = v
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// SDL renders the schema in GraphQL schema definition language, for people
+// writing queries.
+func (s *Schema) SDL() string {
+	seen := map[string]bool{}
+	var objs []*Object
+	var walk func(o *Object)
+	walk = func(o *Object) {
+		if seen[o.Name] {
+			return
+		}
+		seen[o.Name] = true
+		objs = append(objs, o)
+		for _, name := range sortedKeys(o.Fields) {
+			if t := o.Fields[name].Type; t != nil {
+				walk(t)
+			}
+		}
+	}
+	walk(s.Query)
+
+	var b strings.Builder
+	for i, o := range objs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "type %s {\n", o.Name)
+		for _, name := range sortedKeys(o.Fields) {
+			fmt.Fprintf(&b, "  %s%s\n", name, o.Fields[name].Sig)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,210 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccountRepo struct {
+	accounts []store.Account
+}
+
+func (m *mockAccountRepo) Create(_ context.Context, _ *store.Account) error { return nil }
+func (m *mockAccountRepo) UpsertBatch(_ context.Context, _ uuid.UUID, _ []store.Account) error {
+	return nil
+}
+func (m *mockAccountRepo) UpdateLastSynced(_ context.Context, _ uuid.UUID) error { return nil }
+
+func (m *mockAccountRepo) GetByID(_ context.Context, id uuid.UUID) (*store.Account, error) {
+	for _, a := range m.accounts {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *mockAccountRepo) List(_ context.Context, f store.AccountFilter) ([]store.Account, error) {
+	var out []store.Account
+	for _, a := range m.accounts {
+		if f.BankCode == nil || *f.BankCode == a.BankCode {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+type mockTransactionRepo struct {
+	txns map[uuid.UUID][]store.Transaction
+}
+
+func (m *mockTransactionRepo) InsertBatch(_ context.Context, _ []store.Transaction) (int, error) {
+	return 0, nil
+}
+
+func (m *mockTransactionRepo) ListByAccount(_ context.Context, id uuid.UUID) ([]store.Transaction, error) {
+	return m.txns[id], nil
+}
+
+func testSchema() (*Schema, store.Account) {
+	acct := store.Account{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "PE001101190100064607", Currency: "PEN", Status: "active"}
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	bal := func(v int64) *int64 { return &v }
+	txns := []store.Transaction{
+		{ID: uuid.New(), OperationDate: day(1), Description: "ABONO CLIENTE", Amount: 100_000, Type: "CREDIT", BalanceAfter: bal(100_000), Source: "scrape"},
+		{ID: uuid.New(), OperationDate: day(2), Description: "PAGO PROVEEDOR", Amount: 20_000, Type: "DEBIT", BalanceAfter: bal(80_000), Source: "scrape"},
+		{ID: uuid.New(), OperationDate: day(2), Description: "ITF", Amount: 10, Type: "DEBIT", BalanceAfter: bal(79_990), Source: "scrape"},
+		{ID: uuid.New(), OperationDate: day(5), Description: "ABONO CLIENTE", Amount: 5_000, Type: "CREDIT", Source: "import"},
+	}
+	return NewSchema(&mockAccountRepo{accounts: []store.Account{acct}}, &mockTransactionRepo{txns: map[uuid.UUID][]store.Transaction{acct.ID: txns}}), acct
+}
+
+func execute(t *testing.T, s *Schema, query string, vars map[string]any) (string, []Error) {
+	t.Helper()
+	resp := s.Execute(context.Background(), Request{Query: query, Variables: vars})
+	data, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	return string(data), resp.Errors
+}
+
+func TestSchema_Accounts(t *testing.T) {
+	s, acct := testSchema()
+
+	data, errs := execute(t, s, `{ accounts(bankCode: "bbva") { id number: accountNumber currency __typename
+		balanceHistory(from: "2026-03-02") { date balance } } }`, nil)
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"accounts":[{"id":"`+acct.ID.String()+`","number":"XXXXXXXXXXXXXXXX4607","currency":"PEN","__typename":"Account",
+		"balanceHistory":[{"date":"2026-03-02","balance":"799.90"}]}]}`, data)
+	assert.True(t, strings.Index(data, `"id"`) < strings.Index(data, `"number"`), "fields in selection order")
+
+	data, errs = execute(t, s, `query($id: ID!) { account(id: $id) { bankCode } }`, map[string]any{"id": uuid.NewString()})
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"account":null}`, data)
+}
+
+func TestSchema_TransactionsPagination(t *testing.T) {
+	s, acct := testSchema()
+	query := `query Page($acct: ID!, $after: String) {
+		transactions(accountId: $acct, first: 2, after: $after, type: DEBIT) {
+			totalCount
+			nodes { description amount type taxTag }
+			pageInfo { endCursor hasNextPage }
+		}
+	}`
+
+	resp := s.Execute(context.Background(), Request{Query: query, Variables: map[string]any{"acct": acct.ID.String()}})
+	require.Empty(t, resp.Errors)
+	page := resp.Data.(*orderedMap).values["transactions"].(*orderedMap)
+	assert.Equal(t, 2, page.values["totalCount"])
+	nodes := page.values["nodes"].([]any)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "ITF", nodes[0].(*orderedMap).values["description"], "newest first")
+	assert.Equal(t, "itf", nodes[0].(*orderedMap).values["taxTag"])
+	info := page.values["pageInfo"].(*orderedMap)
+	assert.Equal(t, false, info.values["hasNextPage"])
+
+	data, errs := execute(t, s, `{ transactions(accountId: "`+acct.ID.String()+`", first: 1, search: "abono") {
+		nodes { date } pageInfo { endCursor hasNextPage } } }`, nil)
+	require.Empty(t, errs)
+	var first struct {
+		Transactions struct {
+			Nodes    []struct{ Date string }
+			PageInfo struct {
+				EndCursor   string
+				HasNextPage bool
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(data), &first))
+	assert.Equal(t, "2026-03-05", first.Transactions.Nodes[0].Date)
+	require.True(t, first.Transactions.PageInfo.HasNextPage)
+
+	data, errs = execute(t, s, `query($after: String) { transactions(accountId: "`+acct.ID.String()+`", first: 1, search: "abono", after: $after) {
+		nodes { date } } }`, map[string]any{"after": first.Transactions.PageInfo.EndCursor})
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"transactions":{"nodes":[{"date":"2026-03-01"}]}}`, data)
+}
+
+func TestSchema_Errors(t *testing.T) {
+	s, acct := testSchema()
+
+	for query, want := range map[string]string{
+		`{ accounts { password } }`:                   `cannot query field "password" on type Account`,
+		`{ accounts }`:                                "needs a selection",
+		`{ accounts { id { x } } }`:                   "is a scalar",
+		`{ accounts(limit: 1) { id } }`:               `unknown argument "limit"`,
+		`{ __schema { types { name } } }`:             "introspection",
+		`mutation { deleteAccount }`:                  "read-only",
+		`{ accounts { ...f } }`:                       "fragments are not supported",
+		`query($id: ID!) { account(id: $id) { id } }`: "variable $id of type ID! is required",
+		`{ accounts { id }`:                           "expected",
+	} {
+		resp := s.Execute(context.Background(), Request{Query: query})
+		assert.Nil(t, resp.Data, query)
+		require.Len(t, resp.Errors, 1, query)
+		assert.Contains(t, resp.Errors[0].Message, want, query)
+	}
+
+	// Resolver errors null the field and keep the rest.
+	data, errs := execute(t, s, `{ accounts { id } transactions(accountId: "`+acct.ID.String()+`", first: 999) { totalCount } }`, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, []any{"transactions"}, errs[0].Path)
+	assert.Contains(t, data, `"transactions":null`)
+	assert.Contains(t, data, acct.ID.String())
+}
+
+func TestParse_Depth(t *testing.T) {
+	_, err := parse(`{ accounts(ids: ` + strings.Repeat("[", 100_000) + `) { id } }`)
+	assert.ErrorContains(t, err, "nests deeper than 32 levels")
+
+	_, err = parse(strings.Repeat("{ a ", 100_000) + strings.Repeat("}", 100_000))
+	assert.ErrorContains(t, err, "nests deeper than 32 levels")
+
+	_, err = parse(strings.Repeat("{ a ", 31) + `{ a(v: 1) }` + strings.Repeat("}", 31))
+	assert.NoError(t, err, "32 levels")
+	_, err = parse(strings.Repeat("{ a ", 31) + `{ a(v: [1]) }` + strings.Repeat("}", 31))
+	assert.Error(t, err, "33 levels")
+}
+
+func TestHandler(t *testing.T) {
+	s, _ := testSchema()
+	r := gin.New()
+	r.GET("/graphql", Handler(s))
+	r.POST("/graphql", Handler(s))
+	r.GET("/off", Handler(nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ accounts { bankCode } }"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"accounts":[{"bankCode":"BBVA"}]}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ nope }"), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "type Query {")
+	assert.Contains(t, w.Body.String(), "  transactions(accountId: ID!, from: String")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"`+strings.Repeat(" ", MaxRequestBytes)+`{ accounts { id } }"}`)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/off", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/aynifx/bank-scraper/internal/api/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxRequestBytes caps the size of a POSTed request body.
+const MaxRequestBytes = 64 << 10
+
+// Handler serves s over HTTP: POST runs a query from a JSON body, GET with
+// ?query= runs one from the URL, and GET without it returns the schema as
+// SDL. A nil schema means the endpoint is turned off.
+func Handler(s *Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s == nil {
+			handler.ErrorJSON(c, http.StatusNotFound, "GraphQL endpoint is disabled")
+			return
+		}
+
+		var req Request
+		switch {
+		case c.Request.Method == http.MethodPost:
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxRequestBytes)
+			err := c.ShouldBindJSON(&req)
+			if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+				handler.ErrorJSON(c, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			if err != nil || req.Query == "" {
+				handler.ErrorJSON(c, http.StatusBadRequest, "invalid request body")
+				return
+			}
+		case c.Query("query") != "":
+			req.Query, req.OperationName = c.Query("query"), c.Query("operationName")
+			if v := c.Query("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					handler.ErrorJSON(c, http.StatusBadRequest, "invalid variables")
+					return
+				}
+			}
+		default:
+			c.String(http.StatusOK, s.SDL())
+			return
+		}
+
+		resp := s.Execute(c.Request.Context(), req)
+		status := http.StatusOK
+		if resp.Data == nil {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, resp)
+	}
+}
@@ -0,0 +1,455 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The parser covers the query subset of GraphQL dashboards need: named or
+// anonymous queries, variables with defaults, aliases, arguments and nested
+// selections. Fragments, directives, mutations and subscriptions are
+// rejected with an error rather than ignored.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	var out []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			out = append(out, token{tokPunct, "...", i})
+			i += 3
+		case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+			out = append(out, token{tokPunct, string(c), i})
+			i++
+		case c == '_' || isLetter(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || isLetter(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			out = append(out, token{tokName, src[i:j], i})
+			i = j
+		case c == '-' || isDigit(c):
+			j, kind := i+1, tokInt
+			for j < len(src) && (isDigit(src[j]) || strings.IndexByte(".eE+-", src[j]) >= 0) {
+				if !isDigit(src[j]) {
+					kind = tokFloat
+				}
+				j++
+			}
+			out = append(out, token{kind, src[i:j], i})
+			i = j
+		case c == '"':
+			if strings.HasPrefix(src[i:], `"""`) {
+				return nil, fmt.Errorf("block strings are not supported (at %d)", i)
+			}
+			j := i + 1
+			for j < len(src) && src[j] != '"' && src[j] != '\n' {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) || src[j] != '"' {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			var s string
+			if err := json.Unmarshal([]byte(src[i:j+1]), &s); err != nil {
+				return nil, fmt.Errorf("invalid string at %d", i)
+			}
+			out = append(out, token{tokString, s, i})
+			i = j + 1
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return append(out, token{tokEOF, "", len(src)}), nil
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// operation is one query of a document.
+type operation struct {
+	name       string
+	vars       []varDef
+	selections []*selection
+}
+
+type varDef struct {
+	name     string
+	nonNull  bool
+	def      any
+	hasDef   bool
+	typeName string
+}
+
+// selection is a field with its alias, arguments and sub-selections.
+type selection struct {
+	alias      string
+	name       string
+	args       []argument
+	selections []*selection
+}
+
+// key is the field's name in the response.
+func (s *selection) key() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+type argument struct {
+	name  string
+	value any // literal Go value, variable, enumValue, []any or map[string]any
+}
+
+type variable string
+type enumValue string
+
+// maxDepth caps how deeply selection sets and list or object values nest.
+// The parser recurses once per level, so without it a query of nested
+// brackets could exhaust the stack.
+const maxDepth = 32
+
+type parser struct {
+	toks  []token
+	i     int
+	depth int // Selection sets and values currently open
+}
+
+// enter opens a nesting level at t, failing past maxDepth; leave closes it.
+func (p *parser) enter(t token) error {
+	if p.depth++; p.depth > maxDepth {
+		return fmt.Errorf("query nests deeper than %d levels (at %d)", maxDepth, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) leave() { p.depth-- }
+
+// parse returns the operations of a query document.
+func parse(src string) ([]*operation, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	var ops []*operation
+	for p.peek().kind != tokEOF {
+		op, err := p.operation()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("document has no operations")
+	}
+	return ops, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.kind != tokPunct || t.text != text {
+		return p.unexpected(t, text)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokName {
+		return "", p.unexpected(t, "a name")
+	}
+	return t.text, nil
+}
+
+func (p *parser) unexpected(t token, want string) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("expected %s, got end of document", want)
+	}
+	return fmt.Errorf("expected %s, got %q at %d", want, t.text, t.pos)
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{}
+	if t := p.peek(); t.kind == tokName {
+		switch t.text {
+		case "query":
+			p.next()
+		case "mutation", "subscription":
+			return nil, fmt.Errorf("%ss are not supported: the endpoint is read-only", t.text)
+		case "fragment":
+			return nil, fmt.Errorf("fragments are not supported")
+		default:
+			return nil, p.unexpected(t, "an operation")
+		}
+		if p.peek().kind == tokName {
+			op.name = p.next().text
+		}
+		if p.is("(") {
+			vars, err := p.varDefs()
+			if err != nil {
+				return nil, err
+			}
+			op.vars = vars
+		}
+	}
+	if p.is("@") {
+		return nil, fmt.Errorf("directives are not supported")
+	}
+	sels, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = sels
+	return op, nil
+}
+
+func (p *parser) varDefs() ([]varDef, error) {
+	p.next() // (
+	var out []varDef
+	for !p.is(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v := varDef{name: name}
+		typeName, nonNull, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		v.typeName, v.nonNull = typeName, nonNull
+		if p.is("=") {
+			p.next()
+			if v.def, err = p.value(true); err != nil {
+				return nil, err
+			}
+			v.hasDef = true
+		}
+		out = append(out, v)
+	}
+	p.next() // )
+	return out, nil
+}
+
+// typeRef reads a variable type such as ID!, [String] or Int.
+func (p *parser) typeRef() (string, bool, error) {
+	var name string
+	if p.is("[") {
+		p.next()
+		inner, _, err := p.typeRef()
+		if err != nil {
+			return "", false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", false, err
+		}
+		name = "[" + inner + "]"
+	} else {
+		n, err := p.name()
+		if err != nil {
+			return "", false, err
+		}
+		name = n
+	}
+	if p.is("!") {
+		p.next()
+		return name, true, nil
+	}
+	return name, false, nil
+}
+
+func (p *parser) selectionSet() ([]*selection, error) {
+	if err := p.enter(p.peek()); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var out []*selection
+	for !p.is("}") {
+		if p.is("...") {
+			return nil, fmt.Errorf("fragments are not supported")
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	p.next() // }
+	if len(out) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return out, nil
+}
+
+func (p *parser) selection() (*selection, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	s := &selection{name: name}
+	if p.is(":") {
+		p.next()
+		if s.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		s.alias = name
+	}
+	if p.is("(") {
+		p.next()
+		for !p.is(")") {
+			n, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			v, err := p.value(false)
+			if err != nil {
+				return nil, err
+			}
+			s.args = append(s.args, argument{name: n, value: v})
+		}
+		p.next() // )
+	}
+	if p.is("@") {
+		return nil, fmt.Errorf("directives are not supported")
+	}
+	if p.is("{") {
+		if s.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// value reads an argument or default value. Constants can't reference
+// variables.
+func (p *parser) value(constant bool) (any, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return t.text, nil
+	case tokInt:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q at %d", t.text, t.pos)
+		}
+		return n, nil
+	case tokFloat:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return f, nil
+	case tokName:
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return enumValue(t.text), nil
+	case tokPunct:
+		switch t.text {
+		case "$":
+			if constant {
+				return nil, fmt.Errorf("variables are not allowed in default values (at %d)", t.pos)
+			}
+			n, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			return variable(n), nil
+		case "[":
+			if err := p.enter(t); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+			list := []any{}
+			for !p.is("]") {
+				v, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			if err := p.enter(t); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+			obj := map[string]any{}
+			for !p.is("}") {
+				n, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if obj[n], err = p.value(constant); err != nil {
+					return nil, err
+				}
+			}
+			p.next()
+			return obj, nil
+		}
+	}
+	return nil, p.unexpected(t, "a value")
+}
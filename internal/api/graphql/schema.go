@@ -0,0 +1,338 @@
+// Package graphql serves a read-only GraphQL endpoint over the store, for
+// dashboard builders who want accounts, balance history and filtered,
+// paginated transactions in one round trip. It complements the REST API:
+// it reads only what scrapes and imports already stored and never contacts
+// a bank.
+//
+// The engine is deliberately small: queries with variables, aliases and
+// arguments, no fragments, directives or introspection. GET /api/v1/graphql
+// returns the schema in SDL instead.
+package graphql
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/handler"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// Page sizes of transaction connections.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 250
+)
+
+// connection is one page of an account's transactions, newest first.
+type connection struct {
+	total     int
+	nodes     []store.Transaction
+	endCursor string
+	hasNext   bool
+}
+
+// balancePoint is an account's balance at the end of a day.
+type balancePoint struct {
+	date    time.Time
+	balance int64
+}
+
+// transactionArgs are the filters shared by Query.transactions and
+// Account.transactions.
+const transactionArgs = "from: String, to: String, type: String, search: String, first: Int = 50, after: String"
+
+// NewSchema returns the schema over accounts and txns. Queries are scoped to
+// the profile of their context, like the REST API.
+func NewSchema(accounts store.AccountRepository, txns store.TransactionRepository) *Schema {
+	r := &resolver{accounts: accounts, txns: txns}
+
+	pageInfo := &Object{Name: "PageInfo", Fields: map[string]*Field{
+		"endCursor":   {Sig: ": String", Resolve: func(p Params) (any, error) { return nullable(p.Source.(connection).endCursor), nil }},
+		"hasNextPage": {Sig: ": Boolean!", Resolve: func(p Params) (any, error) { return p.Source.(connection).hasNext, nil }},
+	}}
+	transaction := &Object{Name: "Transaction", Fields: map[string]*Field{
+		"id":          txField(": ID!", func(t store.Transaction) any { return t.ID.String() }),
+		"bankId":      txField(": String", func(t store.Transaction) any { return nullable(t.BankID) }),
+		"reference":   txField(": String", func(t store.Transaction) any { return nullable(t.Reference) }),
+		"date":        txField(": String!", func(t store.Transaction) any { return t.OperationDate.Format(time.DateOnly) }),
+		"valueDate":   txField(": String", func(t store.Transaction) any { return formatDate(t.ValueDate) }),
+		"description": txField(": String!", func(t store.Transaction) any { return t.Description }),
+		"amount":      txField(": String! # always positive; see type", func(t store.Transaction) any { return handler.FormatAmount(t.Amount) }),
+		"type":        txField(": String! # CREDIT or DEBIT", func(t store.Transaction) any { return t.Type }),
+		"balanceAfter": txField(": String", func(t store.Transaction) any {
+			if t.BalanceAfter == nil {
+				return nil
+			}
+			return handler.FormatAmount(*t.BalanceAfter)
+		}),
+		"source": txField(": String! # scrape or import", func(t store.Transaction) any { return t.Source }),
+		"taxTag": txField(": String # detraccion, igv, itf or tributo", func(t store.Transaction) any {
+			return nullable(string(report.TaxTagFor(t.Description)))
+		}),
+	}}
+	conn := &Object{Name: "TransactionConnection", Fields: map[string]*Field{
+		"totalCount": {Sig: ": Int!", Resolve: func(p Params) (any, error) { return p.Source.(connection).total, nil }},
+		"nodes":      {Sig: ": [Transaction!]!", Type: transaction, Resolve: func(p Params) (any, error) { return p.Source.(connection).nodes, nil }},
+		"pageInfo":   {Sig: ": PageInfo!", Type: pageInfo, Resolve: func(p Params) (any, error) { return p.Source, nil }},
+	}}
+	point := &Object{Name: "BalancePoint", Fields: map[string]*Field{
+		"date":    {Sig: ": String!", Resolve: func(p Params) (any, error) { return p.Source.(balancePoint).date.Format(time.DateOnly), nil }},
+		"balance": {Sig: ": String!", Resolve: func(p Params) (any, error) { return handler.FormatAmount(p.Source.(balancePoint).balance), nil }},
+	}}
+	account := &Object{Name: "Account", Fields: map[string]*Field{
+		"id":            accountField(": ID!", func(a store.Account) any { return a.ID.String() }),
+		"bankCode":      accountField(": String!", func(a store.Account) any { return a.BankCode }),
+		"accountNumber": accountField(": String! # masked", func(a store.Account) any { return handler.MaskAccountNumber(a.AccountNumber) }),
+		"alias":         accountField(": String", func(a store.Account) any { return nullable(a.Alias) }),
+		"currency":      accountField(": String!", func(a store.Account) any { return a.Currency }),
+		"accountType":   accountField(": String!", func(a store.Account) any { return a.AccountType }),
+		"status":        accountField(": String!", func(a store.Account) any { return a.Status }),
+		"lastSync": accountField(": String", func(a store.Account) any {
+			if a.LastSyncedAt == nil {
+				return nil
+			}
+			return a.LastSyncedAt.Format(time.RFC3339)
+		}),
+		"balanceHistory": {
+			Sig: "(from: String, to: String): [BalancePoint!]! # end-of-day balances the bank reported", Type: point,
+			Resolve: func(p Params) (any, error) { return r.balanceHistory(p, p.Source.(store.Account).ID) },
+		},
+		"transactions": {
+			Sig: "(" + transactionArgs + "): TransactionConnection!", Type: conn,
+			Resolve: func(p Params) (any, error) { return r.transactions(p, p.Source.(store.Account).ID) },
+		},
+	}}
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"accounts": {
+			Sig: "(bankCode: String, currency: String): [Account!]!", Type: account,
+			Resolve: r.listAccounts,
+		},
+		"account": {
+			Sig: "(id: ID!): Account", Type: account,
+			Resolve: func(p Params) (any, error) {
+				a, err := r.account(p, "id")
+				if errors.Is(err, store.ErrNotFound) {
+					return nil, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				return *a, nil
+			},
+		},
+		"transactions": {
+			Sig: "(accountId: ID!, " + transactionArgs + "): TransactionConnection!", Type: conn,
+			Resolve: func(p Params) (any, error) {
+				a, err := r.account(p, "accountId")
+				if err != nil {
+					return nil, err
+				}
+				return r.transactions(p, a.ID)
+			},
+		},
+	}}
+	return &Schema{Query: query}
+}
+
+func accountField(sig string, get func(store.Account) any) *Field {
+	return &Field{Sig: sig, Resolve: func(p Params) (any, error) { return get(p.Source.(store.Account)), nil }}
+}
+
+func txField(sig string, get func(store.Transaction) any) *Field {
+	return &Field{Sig: sig, Resolve: func(p Params) (any, error) { return get(p.Source.(store.Transaction)), nil }}
+}
+
+// nullable returns nil for "", so optional strings come out as null.
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func formatDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(time.DateOnly)
+}
+
+type resolver struct {
+	accounts store.AccountRepository
+	txns     store.TransactionRepository
+}
+
+func (r *resolver) listAccounts(p Params) (any, error) {
+	var filter store.AccountFilter
+	bankCode, err := p.String("bankCode")
+	if err != nil {
+		return nil, err
+	}
+	currency, err := p.String("currency")
+	if err != nil {
+		return nil, err
+	}
+	if bankCode != "" {
+		bankCode = strings.ToUpper(bankCode)
+		filter.BankCode = &bankCode
+	}
+	if currency != "" {
+		currency = strings.ToUpper(currency)
+		filter.Currency = &currency
+	}
+	accounts, err := r.accounts.List(p.Context, filter)
+	if err != nil {
+		return nil, errors.New("failed to list accounts")
+	}
+	return accounts, nil
+}
+
+// account looks up the account whose ID is in argument arg.
+func (r *resolver) account(p Params, arg string) (*store.Account, error) {
+	s, err := p.String(arg)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", arg)
+	}
+	a, err := r.accounts.GetByID(p.Context, id)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return nil, fmt.Errorf("account %s: %w", id, store.ErrNotFound)
+	case err != nil:
+		return nil, errors.New("failed to look up account")
+	}
+	return a, nil
+}
+
+// dateRange reads the from and to arguments (YYYY-MM-DD, inclusive).
+func dateRange(p Params) (from, to time.Time, err error) {
+	for _, d := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		s, err := p.String(d.name)
+		if err != nil {
+			return from, to, err
+		}
+		if s == "" {
+			continue
+		}
+		if *d.t, err = time.Parse(time.DateOnly, s); err != nil {
+			return from, to, fmt.Errorf("argument %q must be a date (YYYY-MM-DD)", d.name)
+		}
+	}
+	return from, to, nil
+}
+
+func inRange(t, from, to time.Time) bool {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return (from.IsZero() || !day.Before(from)) && (to.IsZero() || !day.After(to))
+}
+
+func (r *resolver) transactions(p Params, accountID uuid.UUID) (any, error) {
+	from, to, err := dateRange(p)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := p.String("type")
+	if err != nil {
+		return nil, err
+	}
+	search, err := p.String("search")
+	if err != nil {
+		return nil, err
+	}
+	first, err := p.Int("first", DefaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if first < 1 || first > MaxPageSize {
+		return nil, fmt.Errorf("argument \"first\" must be between 1 and %d", MaxPageSize)
+	}
+	after, err := p.String("after")
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := r.txns.ListByAccount(p.Context, accountID)
+	if err != nil {
+		return nil, errors.New("failed to list transactions")
+	}
+	typ, search = strings.ToUpper(typ), strings.ToUpper(search)
+	var matched []store.Transaction
+	for i := len(txns) - 1; i >= 0; i-- { // Newest first
+		t := txns[i]
+		if !inRange(t.OperationDate, from, to) ||
+			typ != "" && t.Type != typ ||
+			search != "" && !strings.Contains(strings.ToUpper(t.Description), search) {
+			continue
+		}
+		matched = append(matched, t)
+	}
+
+	c := connection{total: len(matched), nodes: []store.Transaction{}}
+	if offset < len(matched) {
+		end := min(offset+first, len(matched))
+		c.nodes = matched[offset:end]
+		c.hasNext = end < len(matched)
+		c.endCursor = encodeCursor(end)
+	}
+	return c, nil
+}
+
+func (r *resolver) balanceHistory(p Params, accountID uuid.UUID) (any, error) {
+	from, to, err := dateRange(p)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := r.txns.ListByAccount(p.Context, accountID)
+	if err != nil {
+		return nil, errors.New("failed to list transactions")
+	}
+	points := []balancePoint{}
+	for _, t := range txns { // Oldest first: the day's last balance wins
+		if t.BalanceAfter == nil || !inRange(t.OperationDate, from, to) {
+			continue
+		}
+		day := t.OperationDate.Truncate(24 * time.Hour)
+		if n := len(points); n > 0 && points[n-1].date.Equal(day) {
+			points[n-1].balance = *t.BalanceAfter
+			continue
+		}
+		points = append(points, balancePoint{date: day, balance: *t.BalanceAfter})
+	}
+	return points, nil
+}
+
+// Cursors are opaque to clients; they encode the offset of the next node.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		if n, ok := strings.CutPrefix(string(b), "offset:"); ok {
+			if offset, err := strconv.Atoi(n); err == nil && offset >= 0 {
+				return offset, nil
+			}
+		}
+	}
+	return 0, errors.New("invalid cursor")
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/schema"
 	"github.com/aynifx/bank-scraper/internal/store"
 )
 
@@ -39,5 +40,5 @@ func (h *AccountHandler) List(c *gin.Context) {
 		resp[i] = ToAccountResponse(a)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"accounts": resp})
+	c.JSON(http.StatusOK, gin.H{"schema_version": schema.Version, "accounts": resp})
 }
@@ -8,6 +8,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/aynifx/bank-scraper/internal/api/middleware"
+	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/aynifx/bank-scraper/internal/schema"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/store"
 )
@@ -15,7 +18,23 @@ import (
 // ScraperProvider returns a logged-in scraper for a bank.
 type ScraperProvider interface {
 	GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error)
-	Invalidate(bankCode bank.Code)
+	Invalidate(ctx context.Context, bankCode bank.Code)
+}
+
+// scraperUnavailable responds 503 to a failed GetScraper, telling read-only
+// callers apart from a bank that is actually down, or 429 to a login over
+// the rate limit.
+func scraperUnavailable(c *gin.Context, err error) {
+	if limited := (*middleware.RateLimitError)(nil); errors.As(err, &limited) {
+		c.Header("Retry-After", limited.RetryAfterHeader())
+		ErrorJSON(c, http.StatusTooManyRequests, limited.Error())
+		return
+	}
+	if errors.Is(err, session.ErrLoginNotAllowed) {
+		ErrorJSON(c, http.StatusServiceUnavailable, "no active bank session; a key with the scrape scope must open one")
+		return
+	}
+	ErrorJSON(c, http.StatusServiceUnavailable, "bank connection unavailable")
 }
 
 // BalanceHandler handles balance retrieval endpoints.
@@ -50,13 +69,13 @@ func (h *BalanceHandler) Get(c *gin.Context) {
 
 	scraper, err := h.scrapers.GetScraper(c.Request.Context(), bank.Code(acct.BankCode))
 	if err != nil {
-		ErrorJSON(c, http.StatusServiceUnavailable, "bank connection unavailable")
+		scraperUnavailable(c, err)
 		return
 	}
 
 	balances, err := scraper.GetBalance(c.Request.Context())
 	if err != nil {
-		h.scrapers.Invalidate(bank.Code(acct.BankCode))
+		h.scrapers.Invalidate(c.Request.Context(), bank.Code(acct.BankCode))
 		ErrorJSON(c, http.StatusServiceUnavailable, "failed to fetch balance")
 		return
 	}
@@ -64,7 +83,9 @@ func (h *BalanceHandler) Get(c *gin.Context) {
 	for _, b := range balances {
 		if b.AccountID == acct.AccountNumber {
 			c.JSON(http.StatusOK, BalanceResponse{
+				SchemaVersion:    schema.Version,
 				AccountID:        acct.ID.String(),
+				Alias:            acct.Alias,
 				BankCode:         acct.BankCode,
 				Currency:         string(b.Currency),
 				AvailableBalance: FormatAmount(b.AvailableBalance),
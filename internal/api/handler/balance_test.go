@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/aynifx/bank-scraper/internal/schema"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
 	"github.com/aynifx/bank-scraper/internal/store"
@@ -32,7 +35,7 @@ func (m *mockScraperProvider) GetScraper(_ context.Context, _ bank.Code) (bank.S
 	return m.scraper, nil
 }
 
-func (m *mockScraperProvider) Invalidate(_ bank.Code) {}
+func (m *mockScraperProvider) Invalidate(context.Context, bank.Code) {}
 
 // --- Helpers ---
 
@@ -93,6 +96,7 @@ func TestBalanceHandler_Get_Success(t *testing.T) {
 	assert.Equal(t, "PEN", resp.Currency)
 	assert.Equal(t, "1234.56", resp.AvailableBalance)
 	assert.Equal(t, "1234.00", resp.CurrentBalance)
+	assert.Equal(t, schema.Version, resp.SchemaVersion)
 }
 
 func TestBalanceHandler_Get_AccountNotFound(t *testing.T) {
@@ -132,6 +136,20 @@ func TestBalanceHandler_Get_ScraperError(t *testing.T) {
 	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 }
 
+func TestBalanceHandler_Get_LoginNotAllowed(t *testing.T) {
+	acct := testAccount()
+	repo := &mockAccountRepo{accounts: []store.Account{acct}}
+	sp := &mockScraperProvider{err: fmt.Errorf("BBVA: %w", session.ErrLoginNotAllowed)}
+
+	router := setupBalanceRouter(repo, sp)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+acct.ID.String()+"/balance", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "scrape scope")
+}
+
 func TestBalanceHandler_Get_BalanceNotInResults(t *testing.T) {
 	acct := testAccount()
 	repo := &mockAccountRepo{accounts: []store.Account{acct}}
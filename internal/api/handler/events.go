@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// eventsKeepAlive is how often an idle stream gets a comment line, so proxies
+// and load balancers don't close it.
+const eventsKeepAlive = 15 * time.Second
+
+// EventSubscriber subscribes to the live event stream.
+// Satisfied by events.Hub.
+type EventSubscriber interface {
+	Subscribe(lastID uint64) (replay []events.Event, ch <-chan events.Event, cancel func())
+}
+
+// EventsHandler streams scrape events over server-sent events.
+type EventsHandler struct {
+	hub       EventSubscriber
+	keepAlive time.Duration
+}
+
+// NewEventsHandler creates a new EventsHandler.
+func NewEventsHandler(hub EventSubscriber) *EventsHandler {
+	return &EventsHandler{hub: hub, keepAlive: eventsKeepAlive}
+}
+
+// Stream sends scrape lifecycle events and new transactions of the API
+// key's profile as they happen. types filters by event type or type prefix (e.g. "scrape" for all scrape.*
+// events); bank_code filters by bank. Clients reconnecting with a
+// Last-Event-ID header receive the recent events they missed.
+// GET /api/v1/events?types=transaction.new,scrape.failed&bank_code=BBVA
+func (h *EventsHandler) Stream(c *gin.Context) {
+	var lastID uint64
+	if v := c.GetHeader("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			ErrorJSON(c, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+		lastID = id
+	}
+
+	var typeFilters []string
+	if v := c.Query("types"); v != "" {
+		for t := range strings.SplitSeq(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				typeFilters = append(typeFilters, t)
+			}
+		}
+	}
+	bankCode := strings.ToUpper(c.Query("bank_code"))
+	profile := store.ProfileFrom(c.Request.Context())
+
+	match := func(e events.Event) bool {
+		if cmp.Or(e.Profile, store.DefaultProfile) != profile {
+			return false
+		}
+		if bankCode != "" && e.BankCode != bankCode {
+			return false
+		}
+		if len(typeFilters) == 0 {
+			return true
+		}
+		for _, t := range typeFilters {
+			if e.Type == t || strings.HasPrefix(e.Type, t+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	replay, ch, cancel := h.hub.Subscribe(lastID)
+	defer cancel()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range replay {
+		if match(e) {
+			writeEvent(w, e)
+		}
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(h.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if match(e) {
+				writeEvent(w, e)
+				w.Flush()
+			}
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			w.Flush()
+		}
+	}
+}
+
+// writeEvent writes e in SSE wire format. Transactions are rendered like
+// the transactions endpoint does.
+func writeEvent(w gin.ResponseWriter, e events.Event) {
+	if tx, ok := e.Data.(bank.Transaction); ok {
+		e.Data = ToTransactionResponse(tx)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupEventsRouter(h *EventsHandler) *gin.Engine {
+	r := gin.New()
+	r.GET("/api/v1/events", h.Stream)
+	return r
+}
+
+// closedHub returns a hub holding evs whose subscribers end immediately, so
+// a stream request returns after the replay.
+func closedHub(evs ...events.Event) *events.Hub {
+	hub := events.NewHub(0)
+	for _, e := range evs {
+		hub.Publish(e)
+	}
+	hub.Close()
+	return hub
+}
+
+func TestEventsHandler_Stream_ReplayAndFilter(t *testing.T) {
+	hub := closedHub(
+		events.Event{Type: events.TypeScrapeStarted, BankCode: "BBVA"},
+		events.Event{Type: events.TypeScrapeFailed, BankCode: "BCP"},
+		events.Event{Type: events.TypeScrapeCompleted, BankCode: "BBVA"},
+		events.Event{Type: events.TypeTransactionNew, BankCode: "BBVA", AccountID: "001",
+			Data: bank.Transaction{ID: "9", Amount: 12_345, Type: bank.TransactionCredit}},
+	)
+	r := setupEventsRouter(NewEventsHandler(hub))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events?types=scrape.completed,transaction&bank_code=bbva", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.NotContains(t, body, "scrape.started", "before Last-Event-ID")
+	assert.NotContains(t, body, "scrape.failed", "filtered out")
+	assert.Contains(t, body, "id: 3\nevent: scrape.completed\n")
+	assert.Contains(t, body, "id: 4\nevent: transaction.new\n")
+	assert.Contains(t, body, `"amount":"123.45"`, "transactions use the API representation")
+	assert.Equal(t, 2, strings.Count(body, "data: "))
+}
+
+func TestEventsHandler_Stream_Live(t *testing.T) {
+	hub := events.NewHub(0)
+	h := NewEventsHandler(hub)
+	h.keepAlive = 10 * time.Millisecond
+	r := setupEventsRouter(h)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/events", nil))
+		done <- w
+	}()
+
+	// Let the stream attach and idle past a few keep-alives.
+	time.Sleep(50 * time.Millisecond)
+	hub.Publish(events.Event{Type: events.TypeSessionFailed, BankCode: "BBVA"})
+	hub.Close()
+
+	select {
+	case w := <-done:
+		assert.Contains(t, w.Body.String(), ": keep-alive")
+		assert.Contains(t, w.Body.String(), "event: session.failed")
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end when the hub closed")
+	}
+}
+
+func TestEventsHandler_Stream_InvalidLastEventID(t *testing.T) {
+	r := setupEventsRouter(NewEventsHandler(closedHub()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/schema"
+)
+
+// Forecast horizon limits, in days.
+const (
+	DefaultForecastDays = 30
+	MaxForecastDays     = 180
+)
+
+// Forecaster projects the cash position across all accounts.
+type Forecaster interface {
+	Forecast(ctx context.Context, asOf time.Time, days int) (*report.CashForecast, error)
+}
+
+// ForecastHandler handles the cash-flow forecast report endpoint.
+type ForecastHandler struct {
+	forecaster Forecaster
+	now        func() time.Time
+}
+
+// NewForecastHandler creates a new ForecastHandler.
+func NewForecastHandler(forecaster Forecaster) *ForecastHandler {
+	return &ForecastHandler{forecaster: forecaster, now: time.Now}
+}
+
+// Get returns an N-day cash position projection per currency. It reads
+// every bank, so it may log in to one.
+// GET /api/v1/reports/forecast?days=30
+func (h *ForecastHandler) Get(c *gin.Context) {
+	days := DefaultForecastDays
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxForecastDays {
+			ErrorJSON(c, http.StatusBadRequest, "days must be between 1 and "+strconv.Itoa(MaxForecastDays))
+			return
+		}
+		days = n
+	}
+
+	fc, err := h.forecaster.Forecast(c.Request.Context(), h.now(), days)
+	if err != nil {
+		scraperUnavailable(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ToForecastResponse(fc))
+}
+
+// ToForecastResponse converts a report.CashForecast to its API representation.
+func ToForecastResponse(fc *report.CashForecast) ForecastResponse {
+	resp := ForecastResponse{
+		SchemaVersion: schema.Version,
+		AsOf:          fc.AsOf.Format(time.RFC3339),
+		Days:          fc.Days,
+		Currencies:    make([]CurrencyForecastResponse, len(fc.Currencies)),
+	}
+	for i, cf := range fc.Currencies {
+		cr := CurrencyForecastResponse{
+			Currency: string(cf.Currency),
+			Opening:  FormatAmount(cf.Opening),
+			Days:     make([]DayPositionResponse, len(cf.Days)),
+			Items:    make([]ForecastItemResponse, len(cf.Items)),
+		}
+		for j, d := range cf.Days {
+			cr.Days[j] = DayPositionResponse{
+				Date:     d.Date.Format(time.DateOnly),
+				Inflows:  FormatAmount(d.Inflows),
+				Outflows: FormatAmount(d.Outflows),
+				Closing:  FormatAmount(d.Closing),
+			}
+		}
+		for j, it := range cf.Items {
+			cr.Items[j] = ForecastItemResponse{
+				Date:        it.Date.Format(time.DateOnly),
+				Amount:      FormatAmount(it.Amount),
+				Source:      it.Source,
+				Description: it.Description,
+			}
+		}
+		resp.Currencies[i] = cr
+	}
+	return resp
+}
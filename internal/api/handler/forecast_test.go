@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/api/middleware"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockForecaster struct {
+	gotDays int
+	result  *report.CashForecast
+	err     error
+}
+
+func (m *mockForecaster) Forecast(_ context.Context, asOf time.Time, days int) (*report.CashForecast, error) {
+	m.gotDays = days
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.result != nil {
+		return m.result, nil
+	}
+	return report.Forecast(nil, nil, asOf, days), nil
+}
+
+func setupForecastRouter(h *ForecastHandler) *gin.Engine {
+	r := gin.New()
+	r.GET("/api/v1/reports/forecast", h.Get)
+	return r
+}
+
+func TestForecastHandler_Get_Success(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	fc := report.Forecast(
+		[]bank.Balance{{Currency: bank.CurrencyPEN, AvailableBalance: 100_000}},
+		[]report.Item{{Date: asOf.AddDate(0, 0, 1), Currency: bank.CurrencyPEN, Amount: -2_550, Source: report.SourceScheduled, Description: "Transferencia"}},
+		asOf, 2,
+	)
+	fm := &mockForecaster{result: fc}
+	router := setupForecastRouter(NewForecastHandler(fm))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/forecast?days=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, fm.gotDays)
+
+	var resp ForecastResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, schema.Version, resp.SchemaVersion)
+	require.Len(t, resp.Currencies, 1)
+	pen := resp.Currencies[0]
+	assert.Equal(t, "PEN", pen.Currency)
+	assert.Equal(t, "1000.00", pen.Opening)
+	require.Len(t, pen.Days, 2)
+	assert.Equal(t, "2026-03-02", pen.Days[1].Date)
+	assert.Equal(t, "25.50", pen.Days[1].Outflows)
+	assert.Equal(t, "974.50", pen.Days[1].Closing)
+	require.Len(t, pen.Items, 1)
+	assert.Equal(t, "-25.50", pen.Items[0].Amount)
+	assert.Equal(t, "scheduled", pen.Items[0].Source)
+}
+
+func TestForecastHandler_Get_DefaultDays(t *testing.T) {
+	fm := &mockForecaster{}
+	router := setupForecastRouter(NewForecastHandler(fm))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/forecast", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, DefaultForecastDays, fm.gotDays)
+}
+
+func TestForecastHandler_Get_InvalidDays(t *testing.T) {
+	for _, days := range []string{"0", "-1", "abc", "181"} {
+		t.Run(days, func(t *testing.T) {
+			router := setupForecastRouter(NewForecastHandler(&mockForecaster{}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/forecast?days="+days, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestForecastHandler_Get_ForecastError(t *testing.T) {
+	router := setupForecastRouter(NewForecastHandler(&mockForecaster{err: errors.New("bank down")}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/forecast", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestForecastHandler_Get_RateLimited(t *testing.T) {
+	limited := fmt.Errorf("connect to bbva: %w", &middleware.RateLimitError{RetryAfter: 90 * time.Second})
+	router := setupForecastRouter(NewForecastHandler(&mockForecaster{err: limited}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/forecast", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+}
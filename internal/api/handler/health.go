@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/api/jobs"
 	"github.com/aynifx/bank-scraper/internal/api/session"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 )
@@ -21,11 +22,25 @@ type SessionStatusProvider interface {
 // Used by the health endpoint to report "unavailable" for banks with no active session.
 type KnownBanksProvider func() []bank.Code
 
+// JobStatsProvider counts scrape jobs by state.
+// Satisfied by jobs.Queue.
+type JobStatsProvider interface {
+	Stats() jobs.Stats
+}
+
+// TimingsProvider returns the step latencies learned for each bank.
+// Satisfied by *bank.Timings.
+type TimingsProvider interface {
+	Profiles() map[bank.Code]bank.TimingProfile
+}
+
 // HealthHandler handles the health check endpoint.
 type HealthHandler struct {
 	pingDB     DBPinger
 	sessions   SessionStatusProvider
 	knownBanks KnownBanksProvider
+	jobStats   JobStatsProvider
+	timings    TimingsProvider
 }
 
 // NewHealthHandler creates a new HealthHandler.
@@ -38,6 +53,19 @@ func NewHealthHandler(pingDB DBPinger, sessions SessionStatusProvider, knownBank
 	return h
 }
 
+// WithJobStats makes the health check report scrape job counts.
+func (h *HealthHandler) WithJobStats(p JobStatsProvider) *HealthHandler {
+	h.jobStats = p
+	return h
+}
+
+// WithTimings makes the health check report each bank's learned step
+// latencies.
+func (h *HealthHandler) WithTimings(p TimingsProvider) *HealthHandler {
+	h.timings = p
+	return h
+}
+
 // Check returns system health status.
 // GET /api/v1/health
 func (h *HealthHandler) Check(c *gin.Context) {
@@ -56,21 +84,58 @@ func (h *HealthHandler) Check(c *gin.Context) {
 		}
 	}
 
+	// A bank is healthy while any profile has an active session with it.
 	for _, info := range h.sessions.SessionStatus() {
+		if banks[string(info.BankCode)].Status == StatusHealthy {
+			continue
+		}
 		bi := BankHealthInfo{}
 		if info.Active {
 			bi.Status = StatusHealthy
-			exp := info.ExpiresAt.Format(time.RFC3339)
-			bi.LastSuccessfulConnection = &exp
+			if !info.ExpiresAt.IsZero() {
+				exp := info.ExpiresAt.Format(time.RFC3339)
+				bi.LastSuccessfulConnection = &exp
+			}
+			bi.LoginVariant = info.LoginVariant
 		} else {
 			bi.Status = StatusDegraded
 		}
 		banks[string(info.BankCode)] = bi
 	}
 
-	c.JSON(http.StatusOK, HealthResponse{
+	if h.timings != nil {
+		for code, profile := range h.timings.Profiles() {
+			bi, ok := banks[string(code)]
+			if !ok {
+				continue
+			}
+			bi.Timings = make(map[string]StepTimingResponse, len(profile))
+			for step, st := range profile {
+				bi.Timings[step] = StepTimingResponse{
+					Samples: st.Samples,
+					MeanMS:  st.Mean.Milliseconds(),
+					MaxMS:   st.Max.Milliseconds(),
+				}
+			}
+			banks[string(code)] = bi
+		}
+	}
+
+	resp := HealthResponse{
 		Status:    overall,
 		Timestamp: time.Now().Format(time.RFC3339),
 		Banks:     banks,
-	})
+	}
+	if h.jobStats != nil {
+		st := h.jobStats.Stats()
+		resp.ScrapeJobs = &ScrapeJobStatsResponse{
+			Queued:    st.Queued,
+			Running:   st.Running,
+			Succeeded: st.Succeeded,
+			Degraded:  st.Degraded,
+			Failed:    st.Failed,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
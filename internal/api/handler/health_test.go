@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/api/jobs"
 	"github.com/aynifx/bank-scraper/internal/api/session"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,12 @@ func (m *mockSessionStatus) SessionStatus() []session.Info {
 	return m.infos
 }
 
+type mockJobStats jobs.Stats
+
+func (m mockJobStats) Stats() jobs.Stats {
+	return jobs.Stats(m)
+}
+
 // --- Tests ---
 
 func TestHealthHandler_Check_Healthy(t *testing.T) {
@@ -126,3 +133,44 @@ func TestHealthHandler_Check_ExpiredSession(t *testing.T) {
 	assert.Equal(t, StatusHealthy, resp.Status)
 	assert.Equal(t, StatusDegraded, resp.Banks["BBVA"].Status)
 }
+
+func TestHealthHandler_Check_JobStats(t *testing.T) {
+	h := NewHealthHandler(func() error { return nil }, &mockSessionStatus{}).
+		WithJobStats(mockJobStats{Queued: 2, Succeeded: 5, Degraded: 1})
+
+	r := gin.New()
+	r.GET("/health", h.Check)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.ScrapeJobs)
+	assert.Equal(t, ScrapeJobStatsResponse{Queued: 2, Succeeded: 5, Degraded: 1}, *resp.ScrapeJobs)
+}
+
+func TestHealthHandler_Check_Timings(t *testing.T) {
+	timings := bank.NewTimings(map[bank.Code]bank.TimingProfile{
+		bank.BankBBVA: {bank.StepLogin: {Samples: 8, Mean: 2500 * time.Millisecond, Max: 4 * time.Second}},
+	})
+	h := NewHealthHandler(func() error { return nil }, &mockSessionStatus{},
+		func() []bank.Code { return []bank.Code{bank.BankBBVA} }).
+		WithTimings(timings)
+
+	r := gin.New()
+	r.GET("/health", h.Check)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, StepTimingResponse{Samples: 8, MeanMS: 2500, MaxMS: 4000}, resp.Banks["BBVA"].Timings[bank.StepLogin])
+}
@@ -2,10 +2,11 @@
 package handler
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/money"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/store"
 )
 
@@ -25,29 +26,17 @@ func ErrorJSON(c *gin.Context, status int, message string) {
 	})
 }
 
-// FormatAmount converts an int64 cents value to a decimal string.
+// FormatAmount converts an int64 cents value to the API's decimal string
+// (money.FormatCents).
 // e.g., 123456 → "1234.56", -50 → "-0.50", 0 → "0.00"
 func FormatAmount(cents int64) string {
-	sign := ""
-	if cents < 0 {
-		sign = "-"
-		cents = -cents
-	}
-	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+	return money.FormatCents(cents)
 }
 
-// MaskAccountNumber masks all but the last 4 characters.
+// MaskAccountNumber masks all but the last 4 characters (bank.MaskAccountID).
 // e.g., "PE001101190100064607" → "XXXXXXXXXXXXXXXX4607"
 func MaskAccountNumber(num string) string {
-	if len(num) <= 4 {
-		return num
-	}
-	masked := make([]byte, len(num))
-	for i := range masked {
-		masked[i] = 'X'
-	}
-	copy(masked[len(masked)-4:], num[len(num)-4:])
-	return string(masked)
+	return bank.MaskAccountID(num)
 }
 
 // AccountResponse is the API representation of a bank account.
@@ -57,13 +46,17 @@ type AccountResponse struct {
 	Currency      string     `json:"currency"`
 	AccountType   string     `json:"account_type"`
 	Status        string     `json:"status"`
-	AccountNumber string     `json:"account_number"` // masked
+	Priority      string     `json:"priority"`        // scrape priority: normal or high
+	AccountNumber string     `json:"account_number"`  // masked
+	Alias         string     `json:"alias,omitempty"` // from ACCOUNT_ALIASES
 	LastSync      *time.Time `json:"last_sync"`
 }
 
 // BalanceResponse is the API representation of an account balance.
 type BalanceResponse struct {
+	SchemaVersion    string `json:"schema_version"`
 	AccountID        string `json:"account_id"`
+	Alias            string `json:"alias,omitempty"` // from ACCOUNT_ALIASES
 	BankCode         string `json:"bank_code"`
 	Currency         string `json:"currency"`
 	AvailableBalance string `json:"available_balance"`
@@ -73,26 +66,28 @@ type BalanceResponse struct {
 
 // TransactionResponse is the API representation of a single transaction.
 type TransactionResponse struct {
-	ID           string            `json:"id"`
-	Reference    string            `json:"reference,omitempty"`
-	Date         string            `json:"date"` // ISO 8601
-	Description  string            `json:"description"`
-	Amount       string            `json:"amount"`
-	Type         string            `json:"type"` // CREDIT or DEBIT
-	BalanceAfter *string           `json:"balance_after,omitempty"`
-	Extra        map[string]string `json:"extra,omitempty"`
+	ID               string            `json:"id"`
+	Reference        string            `json:"reference,omitempty"`
+	Date             string            `json:"date"` // ISO 8601
+	Description      string            `json:"description"`
+	CleanDescription string            `json:"clean_description,omitempty"`
+	Amount           string            `json:"amount"`
+	Type             string            `json:"type"` // CREDIT or DEBIT
+	BalanceAfter     *string           `json:"balance_after,omitempty"`
+	Extra            map[string]string `json:"extra,omitempty"`
 }
 
 // TransactionsListResponse wraps a list of transactions with metadata.
 type TransactionsListResponse struct {
-	AccountID    string                `json:"account_id"`
-	BankCode     string                `json:"bank_code"`
-	Currency     string                `json:"currency"`
-	FromDate     string                `json:"from_date"`
-	ToDate       string                `json:"to_date"`
-	Transactions []TransactionResponse `json:"transactions"`
-	Pagination   PaginationResponse    `json:"pagination"`
-	FetchedAt    string                `json:"fetched_at"` // ISO 8601
+	SchemaVersion string                `json:"schema_version"`
+	AccountID     string                `json:"account_id"`
+	BankCode      string                `json:"bank_code"`
+	Currency      string                `json:"currency"`
+	FromDate      string                `json:"from_date"`
+	ToDate        string                `json:"to_date"`
+	Transactions  []TransactionResponse `json:"transactions"`
+	Pagination    PaginationResponse    `json:"pagination"`
+	FetchedAt     string                `json:"fetched_at"` // ISO 8601
 }
 
 // PaginationResponse contains pagination metadata.
@@ -103,11 +98,116 @@ type PaginationResponse struct {
 	HasMore    bool `json:"has_more"`
 }
 
+// ForecastResponse is the API representation of a cash-flow forecast.
+type ForecastResponse struct {
+	SchemaVersion string                     `json:"schema_version"`
+	AsOf          string                     `json:"as_of"` // ISO 8601
+	Days          int                        `json:"days"`
+	Currencies    []CurrencyForecastResponse `json:"currencies"`
+}
+
+// CurrencyForecastResponse is the projection for one currency.
+type CurrencyForecastResponse struct {
+	Currency string                 `json:"currency"`
+	Opening  string                 `json:"opening"`
+	Days     []DayPositionResponse  `json:"days"`
+	Items    []ForecastItemResponse `json:"items"`
+}
+
+// DayPositionResponse is the projected position at the end of one day.
+type DayPositionResponse struct {
+	Date     string `json:"date"` // YYYY-MM-DD
+	Inflows  string `json:"inflows"`
+	Outflows string `json:"outflows"`
+	Closing  string `json:"closing"`
+}
+
+// ForecastItemResponse is a pending or scheduled movement in the forecast.
+type ForecastItemResponse struct {
+	Date        string `json:"date"` // YYYY-MM-DD
+	Amount      string `json:"amount"`
+	Source      string `json:"source"` // pending or scheduled
+	Description string `json:"description"`
+}
+
+// ScrapeJobResponse is the API representation of a scrape job.
+type ScrapeJobResponse struct {
+	JobID        string                     `json:"job_id"`
+	BankCode     string                     `json:"bank_code"`
+	Status       string                     `json:"status"`   // queued, running, succeeded, degraded, failed
+	Priority     string                     `json:"priority"` // normal or high
+	Source       string                     `json:"source"`   // api or scheduler
+	Error        string                     `json:"error,omitempty"`
+	CreatedAt    string                     `json:"created_at"` // ISO 8601
+	StartedAt    *string                    `json:"started_at,omitempty"`
+	FinishedAt   *string                    `json:"finished_at,omitempty"`
+	Accounts     []ScrapeJobAccountResponse `json:"accounts,omitempty"`
+	Warnings     []ScrapeWarningResponse    `json:"warnings,omitempty"`
+	LoginVariant string                     `json:"login_variant,omitempty"` // login page the bank session got, e.g. senda or legacy
+	Requests     []ScrapeRequestResponse    `json:"requests,omitempty"`      // only when SCRAPER_NETWORK_LOG is on
+	RawHTML      []ScrapeRawHTMLResponse    `json:"raw_html,omitempty"`      // only when SCRAPER_RAW_HTML is on
+}
+
+// ScrapeJobsListResponse is the body of GET /api/v1/scrape.
+type ScrapeJobsListResponse struct {
+	Jobs []ScrapeJobResponse `json:"jobs"`
+}
+
+// ScrapeRawHTMLResponse is the HTML one scraper operation parsed, as given
+// to the parser.
+type ScrapeRawHTMLResponse struct {
+	Operation  string `json:"operation"`
+	Account    string `json:"account,omitempty"` // bank account number
+	HTML       string `json:"html"`
+	CapturedAt string `json:"captured_at"` // ISO 8601
+}
+
+// ScrapeRequestResponse is one request the scraper's browser made.
+type ScrapeRequestResponse struct {
+	Operation  string `json:"operation"`
+	Method     string `json:"method"`
+	URL        string `json:"url"`              // without query string
+	Status     int    `json:"status,omitempty"` // absent when no response arrived
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at"` // ISO 8601
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// ScrapeWarningResponse is data a scrape job skipped or could not interpret
+// without failing. New warnings usually mean the bank changed its portal.
+type ScrapeWarningResponse struct {
+	Operation string `json:"operation"`
+	Account   string `json:"account,omitempty"` // bank account number
+	Message   string `json:"message"`
+}
+
+// ScrapeJobAccountResponse is what a scrape job fetched for one account.
+type ScrapeJobAccountResponse struct {
+	AccountID        string                `json:"account_id"`
+	Currency         string                `json:"currency,omitempty"`
+	AvailableBalance *string               `json:"available_balance,omitempty"`
+	CurrentBalance   *string               `json:"current_balance,omitempty"`
+	Transactions     []TransactionResponse `json:"transactions"`
+	Error            string                `json:"error,omitempty"` // set when this account failed; the job is then degraded
+}
+
 // HealthResponse is the API representation of system health.
 type HealthResponse struct {
 	Status    string                    `json:"status"` // healthy, degraded, unavailable
 	Timestamp string                    `json:"timestamp"`
 	Banks     map[string]BankHealthInfo `json:"banks"`
+
+	ScrapeJobs *ScrapeJobStatsResponse `json:"scrape_jobs,omitempty"`
+}
+
+// ScrapeJobStatsResponse counts scrape jobs waiting and running now, and
+// those finished since the server started, by outcome.
+type ScrapeJobStatsResponse struct {
+	Queued    int `json:"queued"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Degraded  int `json:"degraded"`
+	Failed    int `json:"failed"`
 }
 
 // BankHealthInfo describes the health of a single bank integration.
@@ -115,6 +215,17 @@ type BankHealthInfo struct {
 	Status                   string  `json:"status"`
 	LastSuccessfulConnection *string `json:"last_successful_connection"`
 	ErrorMessage             *string `json:"error_message"`
+	LoginVariant             string  `json:"login_variant,omitempty"` // login page the active session got, e.g. senda or legacy
+
+	Timings map[string]StepTimingResponse `json:"timings,omitempty"` // learned latency per navigation step
+}
+
+// StepTimingResponse is the latency the scrapers have observed for one
+// navigation step of a bank's portal.
+type StepTimingResponse struct {
+	Samples int   `json:"samples"`
+	MeanMS  int64 `json:"mean_ms"`
+	MaxMS   int64 `json:"max_ms"`
 }
 
 // ToAccountResponse converts a store.Account to an API response with masked account number.
@@ -125,7 +236,9 @@ func ToAccountResponse(a store.Account) AccountResponse {
 		Currency:      a.Currency,
 		AccountType:   a.AccountType,
 		Status:        a.Status,
+		Priority:      a.Priority,
 		AccountNumber: MaskAccountNumber(a.AccountNumber),
+		Alias:         a.Alias,
 		LastSync:      a.LastSyncedAt,
 	}
 }
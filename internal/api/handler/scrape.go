@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/aynifx/bank-scraper/internal/api/jobs"
+	"github.com/aynifx/bank-scraper/internal/api/middleware"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// ScrapeQueue accepts scrape jobs and reports their progress.
+// Satisfied by jobs.Queue.
+type ScrapeQueue interface {
+	Enqueue(ctx context.Context, j *store.ScrapeJob) error
+	Get(ctx context.Context, id uuid.UUID) (*store.ScrapeJob, error)
+	Recent(ctx context.Context, limit int) ([]store.ScrapeJob, error)
+}
+
+// Limits of GET /api/v1/scrape.
+const (
+	DefaultScrapeJobsLimit = 20
+	MaxScrapeJobsLimit     = 100
+)
+
+// ScrapeHandler handles asynchronous scrape jobs.
+type ScrapeHandler struct {
+	queue    ScrapeQueue
+	accounts store.AccountRepository
+}
+
+// NewScrapeHandler creates a new ScrapeHandler.
+func NewScrapeHandler(queue ScrapeQueue) *ScrapeHandler {
+	return &ScrapeHandler{queue: queue}
+}
+
+// WithAccounts lets requests name accounts by number (account_numbers), as
+// external systems calling the webhook know them.
+func (h *ScrapeHandler) WithAccounts(repo store.AccountRepository) *ScrapeHandler {
+	h.accounts = repo
+	return h
+}
+
+// ScrapeRequest is the body of POST /api/v1/scrape.
+type ScrapeRequest struct {
+	BankCode         string   `json:"bank_code"`
+	AccountIDs       []string `json:"account_ids,omitempty"`     // default: every active account of the bank
+	AccountNumbers   []string `json:"account_numbers,omitempty"` // full number or its last 4+ digits
+	TransactionCount int      `json:"transaction_count,omitempty"`
+	Priority         string   `json:"priority,omitempty"` // normal or high; default from the accounts' priority
+}
+
+// Enqueue queues a scrape of a bank's accounts and returns the job to poll.
+// POST /api/v1/scrape
+func (h *ScrapeHandler) Enqueue(c *gin.Context) {
+	h.enqueue(c, store.ScrapeJobSourceAPI, "", "/api/v1/scrape/")
+}
+
+// Webhook queues a scrape on behalf of a signed inbound webhook, such as a
+// payroll tool refreshing balances before a run. It takes the same body as
+// Enqueue, defaults to high priority, and returns the job to poll through
+// the webhook.
+// POST /api/v1/hooks/:hook/scrape
+func (h *ScrapeHandler) Webhook(c *gin.Context) {
+	h.enqueue(c, store.ScrapeJobSourceWebhook, store.PriorityHigh, "/api/v1/hooks/"+c.Param("hook")+"/scrape/")
+}
+
+func (h *ScrapeHandler) enqueue(c *gin.Context, source, priority, location string) {
+	var req ScrapeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ErrorJSON(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Priority == "" {
+		req.Priority = priority
+	}
+
+	j := &store.ScrapeJob{
+		BankCode:         strings.ToUpper(req.BankCode),
+		TransactionCount: req.TransactionCount,
+		Priority:         strings.ToLower(req.Priority),
+		Source:           source,
+		ClientID:         middleware.GetClientID(c),
+	}
+	middleware.SetAuditTarget(c, j.BankCode)
+	for _, s := range req.AccountIDs {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			ErrorJSON(c, http.StatusBadRequest, "invalid account_ids")
+			return
+		}
+		j.AccountIDs = append(j.AccountIDs, id)
+	}
+	if len(req.AccountNumbers) > 0 {
+		ids, status, msg := h.resolveAccountNumbers(c.Request.Context(), j.BankCode, req.AccountNumbers)
+		if status != 0 {
+			ErrorJSON(c, status, msg)
+			return
+		}
+		j.AccountIDs = append(j.AccountIDs, ids...)
+	}
+
+	if err := h.queue.Enqueue(c.Request.Context(), j); err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrInvalidJob):
+			ErrorJSON(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, jobs.ErrQueueFull):
+			ErrorJSON(c, http.StatusServiceUnavailable, "scrape queue is full, retry later")
+		default:
+			ErrorJSON(c, http.StatusInternalServerError, "failed to enqueue scrape")
+		}
+		return
+	}
+
+	c.Header("Location", location+j.ID.String())
+	c.JSON(http.StatusAccepted, ToScrapeJobResponse(j))
+}
+
+// resolveAccountNumbers maps account numbers, or unambiguous suffixes of at
+// least 4 digits, to the IDs of the bank's accounts. A non-zero status is
+// the error to answer with.
+func (h *ScrapeHandler) resolveAccountNumbers(ctx context.Context, bankCode string, numbers []string) ([]uuid.UUID, int, string) {
+	if h.accounts == nil {
+		return nil, http.StatusBadRequest, "account_numbers is not supported"
+	}
+	accounts, err := h.accounts.List(ctx, store.AccountFilter{BankCode: &bankCode})
+	if err != nil {
+		return nil, http.StatusInternalServerError, "failed to list accounts"
+	}
+	var ids []uuid.UUID
+	for _, n := range numbers {
+		n = strings.TrimSpace(n)
+		if len(n) < 4 {
+			return nil, http.StatusBadRequest, "account number " + n + " is too short; give at least 4 digits"
+		}
+		var match []uuid.UUID
+		for _, a := range accounts {
+			if a.AccountNumber == n {
+				match = []uuid.UUID{a.ID}
+				break
+			}
+			if strings.HasSuffix(a.AccountNumber, n) {
+				match = append(match, a.ID)
+			}
+		}
+		switch len(match) {
+		case 0:
+			return nil, http.StatusNotFound, "no " + bankCode + " account ending in " + n
+		case 1:
+			ids = append(ids, match[0])
+		default:
+			return nil, http.StatusBadRequest, "account number " + n + " matches several accounts"
+		}
+	}
+	return ids, 0, ""
+}
+
+// Get returns a scrape job's status and, once it finished, its results. A
+// degraded job includes the accounts that worked alongside the errors of
+// those that didn't.
+// GET /api/v1/scrape/:job_id
+func (h *ScrapeHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		ErrorJSON(c, http.StatusBadRequest, "invalid job_id")
+		return
+	}
+
+	j, err := h.queue.Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			ErrorJSON(c, http.StatusNotFound, "job not found")
+			return
+		}
+		ErrorJSON(c, http.StatusInternalServerError, "failed to lookup job")
+		return
+	}
+
+	c.JSON(http.StatusOK, ToScrapeJobResponse(j))
+}
+
+// List returns the latest scrape jobs, newest first, with their results,
+// warnings and errors: what the last runs did.
+// GET /api/v1/scrape?limit=20
+func (h *ScrapeHandler) List(c *gin.Context) {
+	limit := DefaultScrapeJobsLimit
+	if l := c.Query("limit"); l != "" {
+		v, err := strconv.Atoi(l)
+		if err != nil || v < 1 || v > MaxScrapeJobsLimit {
+			ErrorJSON(c, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = v
+	}
+
+	list, err := h.queue.Recent(c.Request.Context(), limit)
+	if err != nil {
+		ErrorJSON(c, http.StatusInternalServerError, "failed to list jobs")
+		return
+	}
+
+	resp := ScrapeJobsListResponse{Jobs: make([]ScrapeJobResponse, len(list))}
+	for i := range list {
+		resp.Jobs[i] = ToScrapeJobResponse(&list[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ToScrapeJobResponse converts a store.ScrapeJob to its API representation.
+func ToScrapeJobResponse(j *store.ScrapeJob) ScrapeJobResponse {
+	formatTime := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		s := t.Format(time.RFC3339)
+		return &s
+	}
+
+	resp := ScrapeJobResponse{
+		JobID:      j.ID.String(),
+		BankCode:   j.BankCode,
+		Status:     j.Status,
+		Priority:   j.Priority,
+		Source:     j.Source,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt.Format(time.RFC3339),
+		StartedAt:  formatTime(j.StartedAt),
+		FinishedAt: formatTime(j.FinishedAt),
+	}
+	if j.Result == nil {
+		return resp
+	}
+	for _, a := range j.Result.Accounts {
+		ar := ScrapeJobAccountResponse{
+			AccountID:    a.AccountID.String(),
+			Transactions: make([]TransactionResponse, len(a.Transactions)),
+			Error:        a.Error,
+		}
+		if b := a.Balance; b != nil {
+			avail, curr := FormatAmount(b.AvailableBalance), FormatAmount(b.CurrentBalance)
+			ar.Currency = string(b.Currency)
+			ar.AvailableBalance, ar.CurrentBalance = &avail, &curr
+		}
+		for i, tx := range a.Transactions {
+			ar.Transactions[i] = ToTransactionResponse(tx)
+		}
+		resp.Accounts = append(resp.Accounts, ar)
+	}
+	resp.LoginVariant = j.Result.LoginVariant
+	for _, w := range j.Result.Warnings {
+		resp.Warnings = append(resp.Warnings, ScrapeWarningResponse{
+			Operation: w.Operation,
+			Account:   w.AccountID,
+			Message:   w.Message,
+		})
+	}
+	for _, r := range j.Result.Requests {
+		resp.Requests = append(resp.Requests, ScrapeRequestResponse{
+			Operation:  r.Operation,
+			Method:     r.Method,
+			URL:        r.URL,
+			Status:     r.Status,
+			Error:      r.Error,
+			StartedAt:  r.StartedAt.Format(time.RFC3339Nano),
+			DurationMS: r.Duration.Milliseconds(),
+		})
+	}
+	for _, p := range j.Result.RawHTML {
+		resp.RawHTML = append(resp.RawHTML, ScrapeRawHTMLResponse{
+			Operation:  p.Operation,
+			Account:    p.AccountID,
+			HTML:       p.HTML,
+			CapturedAt: p.CapturedAt.Format(time.RFC3339Nano),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/aynifx/bank-scraper/internal/api/jobs"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockScrapeQueue struct {
+	enqueued *store.ScrapeJob
+	err      error
+	job      *store.ScrapeJob
+}
+
+func (m *mockScrapeQueue) Enqueue(_ context.Context, j *store.ScrapeJob) error {
+	if m.err != nil {
+		return m.err
+	}
+	j.ID = uuid.New()
+	j.Status = store.ScrapeJobQueued
+	m.enqueued = j
+	return nil
+}
+
+func (m *mockScrapeQueue) Get(_ context.Context, id uuid.UUID) (*store.ScrapeJob, error) {
+	if m.job == nil || m.job.ID != id {
+		return nil, store.ErrNotFound
+	}
+	return m.job, nil
+}
+
+func (m *mockScrapeQueue) Recent(_ context.Context, limit int) ([]store.ScrapeJob, error) {
+	if m.job == nil {
+		return nil, nil
+	}
+	return []store.ScrapeJob{*m.job}[:min(1, limit)], nil
+}
+
+func setupScrapeRouter(q ScrapeQueue) *gin.Engine {
+	r := gin.New()
+	h := NewScrapeHandler(q)
+	r.POST("/api/v1/scrape", h.Enqueue)
+	r.GET("/api/v1/scrape", h.List)
+	r.GET("/api/v1/scrape/:job_id", h.Get)
+	return r
+}
+
+func TestScrapeHandler_Enqueue(t *testing.T) {
+	q := &mockScrapeQueue{}
+	accountID := uuid.New()
+	body := `{"bank_code":"bbva","account_ids":["` + accountID.String() + `"],"transaction_count":20}`
+
+	w := httptest.NewRecorder()
+	setupScrapeRouter(q).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/scrape", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.NotNil(t, q.enqueued)
+	assert.Equal(t, "BBVA", q.enqueued.BankCode)
+	assert.Equal(t, []uuid.UUID{accountID}, q.enqueued.AccountIDs)
+	assert.Equal(t, 20, q.enqueued.TransactionCount)
+	assert.Equal(t, store.ScrapeJobSourceAPI, q.enqueued.Source)
+	assert.Equal(t, "/api/v1/scrape/"+q.enqueued.ID.String(), w.Header().Get("Location"))
+
+	var resp ScrapeJobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, store.ScrapeJobQueued, resp.Status)
+}
+
+func TestScrapeHandler_Webhook(t *testing.T) {
+	q := &mockScrapeQueue{}
+	a1 := store.Account{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "0011-0119-0100064607"}
+	a2 := store.Account{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "0011-0119-0200064607"}
+	r := gin.New()
+	h := NewScrapeHandler(q).WithAccounts(&mockAccountRepo{accounts: []store.Account{a1, a2}})
+	r.POST("/api/v1/hooks/:hook/scrape", h.Webhook)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/hooks/payroll/scrape", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`{"bank_code":"bbva","account_numbers":["0100064607"]}`)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Equal(t, []uuid.UUID{a1.ID}, q.enqueued.AccountIDs)
+	assert.Equal(t, store.PriorityHigh, q.enqueued.Priority)
+	assert.Equal(t, store.ScrapeJobSourceWebhook, q.enqueued.Source)
+	assert.Equal(t, "/api/v1/hooks/payroll/scrape/"+q.enqueued.ID.String(), w.Header().Get("Location"))
+
+	w = post(`{"bank_code":"bbva","priority":"normal"}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, store.PriorityNormal, q.enqueued.Priority)
+	assert.Empty(t, q.enqueued.AccountIDs)
+
+	assert.Equal(t, http.StatusNotFound, post(`{"bank_code":"bbva","account_numbers":["9999"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"bank_code":"bbva","account_numbers":["4607"]}`).Code, "ambiguous")
+	assert.Equal(t, http.StatusBadRequest, post(`{"bank_code":"bbva","account_numbers":["07"]}`).Code, "too short")
+}
+
+func TestScrapeHandler_Enqueue_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{"bad json", `{`, nil, http.StatusBadRequest},
+		{"bad account id", `{"bank_code":"BBVA","account_ids":["x"]}`, nil, http.StatusBadRequest},
+		{"invalid job", `{}`, jobs.ErrInvalidJob, http.StatusBadRequest},
+		{"queue full", `{"bank_code":"BBVA"}`, jobs.ErrQueueFull, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			setupScrapeRouter(&mockScrapeQueue{err: tt.err}).ServeHTTP(w,
+				httptest.NewRequest(http.MethodPost, "/api/v1/scrape", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestScrapeHandler_Get(t *testing.T) {
+	accountID := uuid.New()
+	finished := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	job := &store.ScrapeJob{
+		ID: uuid.New(), BankCode: "BBVA", Status: store.ScrapeJobSucceeded, Source: store.ScrapeJobSourceAPI,
+		CreatedAt: finished, FinishedAt: &finished,
+		Result: &store.ScrapeResult{Accounts: []store.ScrapeAccountResult{{
+			AccountID:    accountID,
+			Balance:      &bank.Balance{Currency: bank.CurrencyPEN, AvailableBalance: 123456, CurrentBalance: 100},
+			Transactions: []bank.Transaction{{ID: "1", Amount: 5000, Type: bank.TransactionDebit}},
+		}}, Warnings: []bank.Warning{{Code: bank.BankBBVA, Operation: "GetBalance", Message: "card skipped"}},
+			Requests: []bank.Request{{Operation: "GetBalance", Method: "GET", URL: "https://bank.test/accounts",
+				Status: 403, StartedAt: finished, Duration: 1500 * time.Millisecond}},
+			RawHTML: []bank.RawHTML{{Operation: "GetTransactions", AccountID: "0011-0123", HTML: "<table></table>", CapturedAt: finished}}},
+	}
+	r := setupScrapeRouter(&mockScrapeQueue{job: job})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/scrape/"+job.ID.String(), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ScrapeJobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, store.ScrapeJobSucceeded, resp.Status)
+	require.Len(t, resp.Accounts, 1)
+	assert.Equal(t, accountID.String(), resp.Accounts[0].AccountID)
+	assert.Equal(t, "1234.56", *resp.Accounts[0].AvailableBalance)
+	assert.Equal(t, "50.00", resp.Accounts[0].Transactions[0].Amount)
+	assert.Equal(t, "2026-03-01T12:00:00Z", *resp.FinishedAt)
+	assert.Equal(t, []ScrapeWarningResponse{{Operation: "GetBalance", Message: "card skipped"}}, resp.Warnings)
+	assert.Equal(t, []ScrapeRequestResponse{{Operation: "GetBalance", Method: "GET", URL: "https://bank.test/accounts",
+		Status: 403, StartedAt: "2026-03-01T12:00:00Z", DurationMS: 1500}}, resp.Requests)
+	assert.Equal(t, []ScrapeRawHTMLResponse{{Operation: "GetTransactions", Account: "0011-0123",
+		HTML: "<table></table>", CapturedAt: "2026-03-01T12:00:00Z"}}, resp.RawHTML)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/scrape/"+uuid.NewString(), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/scrape/nope", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestScrapeHandler_List(t *testing.T) {
+	job := &store.ScrapeJob{ID: uuid.New(), BankCode: "BBVA", Status: store.ScrapeJobFailed, Error: "login failed", CreatedAt: time.Now()}
+	r := setupScrapeRouter(&mockScrapeQueue{job: job})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/scrape", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ScrapeJobsListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Jobs, 1)
+	assert.Equal(t, job.ID.String(), resp.Jobs[0].JobID)
+	assert.Equal(t, "login failed", resp.Jobs[0].Error)
+
+	for _, limit := range []string{"0", "101", "x"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/scrape?limit="+limit, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, limit)
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"cmp"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// SessionHandler lets scrape-scoped clients open bank sessions that
+// read-only clients then reuse.
+type SessionHandler struct {
+	scrapers ScraperProvider
+	sessions SessionStatusProvider
+}
+
+// NewSessionHandler creates a new SessionHandler.
+func NewSessionHandler(scrapers ScraperProvider, sessions SessionStatusProvider) *SessionHandler {
+	return &SessionHandler{scrapers: scrapers, sessions: sessions}
+}
+
+// SessionResponse is the API representation of a bank session.
+type SessionResponse struct {
+	BankCode     string `json:"bank_code"`
+	Active       bool   `json:"active"`
+	ExpiresAt    string `json:"expires_at,omitempty"`    // ISO 8601
+	LoginVariant string `json:"login_variant,omitempty"` // login page the bank served, e.g. senda or legacy
+}
+
+// Open logs in to a bank with the API key's profile unless a session is
+// already active. force=true discards the current session first.
+// POST /api/v1/sessions/:bank_code?force=true
+func (h *SessionHandler) Open(c *gin.Context) {
+	code := bank.Code(strings.ToUpper(c.Param("bank_code")))
+
+	if c.Query("force") == "true" {
+		h.scrapers.Invalidate(c.Request.Context(), code)
+	}
+	if _, err := h.scrapers.GetScraper(c.Request.Context(), code); err != nil {
+		scraperUnavailable(c, err)
+		return
+	}
+
+	resp := SessionResponse{BankCode: string(code), Active: true}
+	profile := store.ProfileFrom(c.Request.Context())
+	for _, info := range h.sessions.SessionStatus() {
+		if info.BankCode == code && cmp.Or(info.Profile, store.DefaultProfile) == profile {
+			resp.Active, resp.LoginVariant = info.Active, info.LoginVariant
+			if !info.ExpiresAt.IsZero() {
+				resp.ExpiresAt = info.ExpiresAt.UTC().Format(time.RFC3339)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
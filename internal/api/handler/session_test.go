@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingScraperProvider struct {
+	mockScraperProvider
+	requested   bank.Code
+	invalidated bool
+}
+
+func (m *recordingScraperProvider) GetScraper(ctx context.Context, code bank.Code) (bank.Scraper, error) {
+	m.requested = code
+	return m.mockScraperProvider.GetScraper(ctx, code)
+}
+
+func (m *recordingScraperProvider) Invalidate(context.Context, bank.Code) { m.invalidated = true }
+
+func TestSessionHandler_Open(t *testing.T) {
+	expires := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sp := &recordingScraperProvider{mockScraperProvider: mockScraperProvider{scraper: &banktest.MockScraper{}}}
+	h := NewSessionHandler(sp, &mockSessionStatus{infos: []session.Info{
+		{BankCode: bank.BankBBVA, Active: true, ExpiresAt: expires},
+	}})
+	r := gin.New()
+	r.POST("/api/v1/sessions/:bank_code", h.Open)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/sessions/bbva?force=true", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp SessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, SessionResponse{BankCode: "BBVA", Active: true, ExpiresAt: "2026-03-01T12:00:00Z"}, resp)
+	assert.Equal(t, bank.BankBBVA, sp.requested)
+	assert.True(t, sp.invalidated)
+}
+
+func TestSessionHandler_Open_LoginFails(t *testing.T) {
+	sp := &recordingScraperProvider{mockScraperProvider: mockScraperProvider{err: errors.New("bad password")}}
+	r := gin.New()
+	r.POST("/api/v1/sessions/:bank_code", NewSessionHandler(sp, &mockSessionStatus{}).Open)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/sessions/BBVA", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.False(t, sp.invalidated)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/aynifx/bank-scraper/internal/schema"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/store"
 )
@@ -32,7 +33,9 @@ func NewTransactionHandler(accounts store.AccountRepository, scrapers ScraperPro
 }
 
 // List returns transactions for a specific account.
-// GET /api/v1/accounts/:account_id/transactions?from_date=2026-03-01&to_date=2026-03-15&page=1&page_size=50
+// sort orders the results, e.g. "date:desc,id" (see bank.ParseSortOrder);
+// the default is newest first.
+// GET /api/v1/accounts/:account_id/transactions?from_date=2026-03-01&to_date=2026-03-15&page=1&page_size=50&sort=amount:desc
 func (h *TransactionHandler) List(c *gin.Context) {
 	accountID, err := uuid.Parse(c.Param("account_id"))
 	if err != nil {
@@ -71,6 +74,12 @@ func (h *TransactionHandler) List(c *gin.Context) {
 		return
 	}
 
+	order, err := bank.ParseSortOrder(c.Query("sort"))
+	if err != nil {
+		ErrorJSON(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Parse pagination
 	page := 1
 	pageSize := defaultPageSize
@@ -97,37 +106,29 @@ func (h *TransactionHandler) List(c *gin.Context) {
 
 	scraper, err := h.scrapers.GetScraper(c.Request.Context(), bank.Code(acct.BankCode))
 	if err != nil {
-		ErrorJSON(c, http.StatusServiceUnavailable, "bank connection unavailable")
+		scraperUnavailable(c, err)
 		return
 	}
 
 	txns, err := scraper.GetTransactions(c.Request.Context(), acct.AccountNumber, scraperFetchCount)
 	if err != nil {
-		h.scrapers.Invalidate(bank.Code(acct.BankCode))
+		h.scrapers.Invalidate(c.Request.Context(), bank.Code(acct.BankCode))
 		ErrorJSON(c, http.StatusServiceUnavailable, "failed to fetch transactions")
 		return
 	}
 
-	// Filter by date range and build response
-	var items []TransactionResponse
+	// Filter by date range, sort and build response
+	var inRange []bank.Transaction
 	for _, tx := range txns {
 		if tx.Date.Before(fromDate) || tx.Date.After(toDate.AddDate(0, 0, 1)) {
 			continue
 		}
-		item := TransactionResponse{
-			ID:          tx.ID,
-			Reference:   tx.Reference,
-			Date:        tx.Date.Format(time.RFC3339),
-			Description: tx.Description,
-			Amount:      FormatAmount(tx.Amount),
-			Type:        string(tx.Type),
-			Extra:       tx.Extra,
-		}
-		if tx.BalanceAfter != nil {
-			s := FormatAmount(*tx.BalanceAfter)
-			item.BalanceAfter = &s
-		}
-		items = append(items, item)
+		inRange = append(inRange, tx)
+	}
+	bank.SortTransactions(inRange, order...)
+	var items []TransactionResponse
+	for _, tx := range inRange {
+		items = append(items, ToTransactionResponse(tx))
 	}
 
 	// Apply pagination
@@ -143,12 +144,13 @@ func (h *TransactionHandler) List(c *gin.Context) {
 	pageItems := items[start:end]
 
 	c.JSON(http.StatusOK, TransactionsListResponse{
-		AccountID:    acct.ID.String(),
-		BankCode:     acct.BankCode,
-		Currency:     acct.Currency,
-		FromDate:     fromDate.Format(dateLayout),
-		ToDate:       toDate.Format(dateLayout),
-		Transactions: pageItems,
+		SchemaVersion: schema.Version,
+		AccountID:     acct.ID.String(),
+		BankCode:      acct.BankCode,
+		Currency:      acct.Currency,
+		FromDate:      fromDate.Format(dateLayout),
+		ToDate:        toDate.Format(dateLayout),
+		Transactions:  pageItems,
 		Pagination: PaginationResponse{
 			TotalCount: total,
 			Page:       page,
@@ -158,3 +160,22 @@ func (h *TransactionHandler) List(c *gin.Context) {
 		FetchedAt: time.Now().Format(time.RFC3339),
 	})
 }
+
+// ToTransactionResponse converts a bank.Transaction to its API representation.
+func ToTransactionResponse(tx bank.Transaction) TransactionResponse {
+	item := TransactionResponse{
+		ID:               tx.ID,
+		Reference:        tx.Reference,
+		Date:             tx.Date.Format(time.RFC3339),
+		Description:      tx.Description,
+		CleanDescription: tx.CleanDescription,
+		Amount:           FormatAmount(tx.Amount),
+		Type:             string(tx.Type),
+		Extra:            tx.Extra,
+	}
+	if tx.BalanceAfter != nil {
+		s := FormatAmount(*tx.BalanceAfter)
+		item.BalanceAfter = &s
+	}
+	return item
+}
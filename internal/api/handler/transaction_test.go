@@ -134,3 +134,23 @@ func TestTransactionHandler_List_AccountNotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestTransactionHandler_List_Sort(t *testing.T) {
+	acct := testAccount()
+	repo := &mockAccountRepo{accounts: []store.Account{acct}}
+	ms := &banktest.MockScraper{Transactions: sampleTransactions()}
+	router := setupTransactionRouter(repo, &mockScraperProvider{scraper: ms})
+	base := "/api/v1/accounts/" + acct.ID.String() + "/transactions?from_date=2026-03-15&to_date=2026-03-25"
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, base+"&sort=amount", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp TransactionsListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Transactions, 2)
+	assert.Equal(t, "DOC002", resp.Transactions[0].ID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, base+"&sort=color", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,523 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/calendar"
+	"github.com/aynifx/bank-scraper/internal/pipeline"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccountRepo struct {
+	accounts []store.Account
+
+	mu     sync.Mutex
+	synced []uuid.UUID
+}
+
+func (m *mockAccountRepo) Create(_ context.Context, _ *store.Account) error { return nil }
+func (m *mockAccountRepo) GetByID(_ context.Context, _ uuid.UUID) (*store.Account, error) {
+	return nil, store.ErrNotFound
+}
+func (m *mockAccountRepo) UpsertBatch(_ context.Context, _ uuid.UUID, _ []store.Account) error {
+	return nil
+}
+
+func (m *mockAccountRepo) List(_ context.Context, filter store.AccountFilter) ([]store.Account, error) {
+	var out []store.Account
+	for _, a := range m.accounts {
+		if filter.BankCode == nil || a.BankCode == *filter.BankCode {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAccountRepo) UpdateLastSynced(_ context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.synced = append(m.synced, id)
+	return nil
+}
+
+type mockScraperSource struct {
+	scraper bank.Scraper
+	err     error
+}
+
+func (m *mockScraperSource) GetScraper(_ context.Context, _ bank.Code) (bank.Scraper, error) {
+	return m.scraper, m.err
+}
+
+func testAccounts() []store.Account {
+	return []store.Account{
+		{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "001", Status: store.AccountStatusActive},
+		{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "002", Status: store.AccountStatusActive},
+		{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "003", Status: store.AccountStatusInactive},
+		{ID: uuid.New(), BankCode: "BCP", AccountNumber: "900", Status: store.AccountStatusActive},
+	}
+}
+
+// waitDone polls until the job has finished.
+func waitDone(t *testing.T, q *Queue, id uuid.UUID) *store.ScrapeJob {
+	t.Helper()
+	var j *store.ScrapeJob
+	require.Eventually(t, func() bool {
+		var err error
+		j, err = q.Get(context.Background(), id)
+		require.NoError(t, err)
+		return j.Done()
+	}, 2*time.Second, 5*time.Millisecond)
+	return j
+}
+
+func TestQueue_RunsJob(t *testing.T) {
+	accounts := &mockAccountRepo{accounts: testAccounts()}
+	mock := &banktest.MockScraper{
+		Balances:     []bank.Balance{{AccountID: "001", AvailableBalance: 100}, {AccountID: "002", AvailableBalance: 200}},
+		Transactions: []bank.Transaction{{ID: "1", Amount: 50, Type: bank.TransactionCredit}},
+	}
+	q := NewQueue(NewMemoryStore(), accounts, &mockScraperSource{scraper: mock})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA", Source: store.ScrapeJobSourceAPI}
+	require.NoError(t, q.Enqueue(ctx, j))
+	assert.Equal(t, store.ScrapeJobQueued, j.Status)
+
+	done := waitDone(t, q, j.ID)
+	require.Equal(t, store.ScrapeJobSucceeded, done.Status, done.Error)
+	require.NotNil(t, done.StartedAt)
+	require.Len(t, done.Result.Accounts, 2, "inactive and other-bank accounts skipped")
+	assert.Equal(t, int64(200), done.Result.Accounts[1].Balance.AvailableBalance)
+	assert.Len(t, done.Result.Accounts[0].Transactions, 1)
+	assert.Len(t, accounts.synced, 2)
+}
+
+func TestQueue_Pipeline(t *testing.T) {
+	accounts := &mockAccountRepo{accounts: testAccounts()}
+	mock := &banktest.MockScraper{Transactions: []bank.Transaction{{ID: "1", Amount: 50, Type: bank.TransactionCredit}}}
+	steps, err := pipeline.Parse([]byte("steps: [scrape, store]"))
+	require.NoError(t, err)
+	var stored []string
+	p, err := pipeline.New(steps, map[string]pipeline.Step{
+		pipeline.StepScrape: pipeline.Scrape(),
+		pipeline.StepStore: pipeline.StepFunc(func(_ context.Context, b *pipeline.Batch) error {
+			if b.Account.AccountNumber == "002" {
+				return errors.New("disk full")
+			}
+			stored = append(stored, b.Account.AccountNumber)
+			b.Transactions = nil
+			return nil
+		}),
+	})
+	require.NoError(t, err)
+	q := NewQueue(NewMemoryStore(), accounts, &mockScraperSource{scraper: mock}, WithPipeline(p))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA"}
+	require.NoError(t, q.Enqueue(ctx, j))
+
+	done := waitDone(t, q, j.ID)
+	assert.Equal(t, store.ScrapeJobDegraded, done.Status)
+	assert.Equal(t, []string{"001"}, stored)
+	assert.Len(t, done.Result.Accounts[0].Transactions, 1, "every fetched transaction")
+	assert.Contains(t, done.Result.Accounts[1].Error, "store: disk full")
+	assert.Len(t, accounts.synced, 1)
+}
+
+func TestQueue_SelectedAccounts(t *testing.T) {
+	accts := testAccounts()
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{accounts: accts}, &mockScraperSource{scraper: &banktest.MockScraper{}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA", AccountIDs: []uuid.UUID{accts[1].ID}}
+	require.NoError(t, q.Enqueue(ctx, j))
+
+	done := waitDone(t, q, j.ID)
+	require.Len(t, done.Result.Accounts, 1)
+	assert.Equal(t, accts[1].ID, done.Result.Accounts[0].AccountID)
+	assert.Nil(t, done.Result.Accounts[0].Balance, "bank returned no balance for it")
+}
+
+func TestQueue_Failure(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{accounts: testAccounts()},
+		&mockScraperSource{err: errors.New("login failed")})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA"}
+	require.NoError(t, q.Enqueue(ctx, j))
+
+	done := waitDone(t, q, j.ID)
+	assert.Equal(t, store.ScrapeJobFailed, done.Status)
+	assert.Contains(t, done.Error, "login failed")
+	assert.Nil(t, done.Result)
+}
+
+// failingAccountScraper fails GetTransactions for one account number.
+type failingAccountScraper struct {
+	*banktest.MockScraper
+	account string
+}
+
+func (s *failingAccountScraper) GetTransactions(ctx context.Context, accountID string, limit int) ([]bank.Transaction, error) {
+	if accountID == s.account {
+		return nil, errors.New("parse row 3: bad amount")
+	}
+	return s.MockScraper.GetTransactions(ctx, accountID, limit)
+}
+
+func TestQueue_PartialFailure(t *testing.T) {
+	scraper := &failingAccountScraper{
+		MockScraper: &banktest.MockScraper{
+			Balances:     []bank.Balance{{AccountID: "001", AvailableBalance: 100}, {AccountID: "002", AvailableBalance: 200}},
+			Transactions: []bank.Transaction{{ID: "t1", Amount: 10}},
+		},
+		account: "001",
+	}
+	accounts := &mockAccountRepo{accounts: testAccounts()}
+	hub := events.NewHub(0)
+	_, ch, unsubscribe := hub.Subscribe(0)
+	defer unsubscribe()
+	q := NewQueue(NewMemoryStore(), accounts, &mockScraperSource{scraper: scraper}, WithPublisher(hub))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA"}
+	require.NoError(t, q.Enqueue(ctx, j))
+
+	done := waitDone(t, q, j.ID)
+	assert.Equal(t, store.ScrapeJobDegraded, done.Status)
+	assert.Equal(t, "1 of 2 accounts failed", done.Error)
+	require.Len(t, done.Result.Accounts, 2)
+	failed := done.Result.Accounts[0]
+	assert.Contains(t, failed.Error, "bad amount")
+	assert.Equal(t, int64(100), failed.Balance.AvailableBalance, "balance still reported")
+	assert.Empty(t, failed.Transactions)
+	assert.Empty(t, done.Result.Accounts[1].Error)
+	assert.Len(t, done.Result.Accounts[1].Transactions, 1)
+	assert.Equal(t, []uuid.UUID{done.Result.Accounts[1].AccountID}, accounts.synced, "only the account that worked")
+
+	e := <-ch
+	assert.Equal(t, events.TypeJobDegraded, e.Type)
+	assert.Equal(t, "BBVA", e.BankCode)
+	assert.Equal(t, map[string]string{failed.AccountID.String(): failed.Error}, e.Data.(map[string]any)["account_errors"])
+	assert.Equal(t, Stats{Degraded: 1}, q.Stats())
+
+	// Every account failing fails the job, keeping the per-account errors.
+	scraper.TransactionsErr = errors.New("session expired")
+	j = &store.ScrapeJob{BankCode: "BBVA"}
+	require.NoError(t, q.Enqueue(ctx, j))
+	done = waitDone(t, q, j.ID)
+	assert.Equal(t, store.ScrapeJobFailed, done.Status)
+	assert.Contains(t, done.Error, "all 2 accounts failed")
+	assert.Len(t, done.Result.Failed(), 2)
+	assert.Equal(t, events.TypeJobFailed, (<-ch).Type)
+}
+
+// warningScraper reports a parse warning, a request, raw HTML and the
+// bank's clock from GetBalance.
+type warningScraper struct {
+	*banktest.MockScraper
+}
+
+func (s *warningScraper) GetBalance(ctx context.Context) ([]bank.Balance, error) {
+	bank.Warn(ctx, bank.Warning{Code: bank.BankBBVA, Operation: "GetBalance", Message: "card skipped"})
+	bank.LogRequests(ctx, bank.Request{Operation: "GetBalance", Method: "GET", URL: "https://bank.test/accounts", Status: 200})
+	bank.RecordHTML(ctx, "GetBalance", "", "<table>accounts</table>")
+	now := time.Now()
+	bank.ReportClockSkew(ctx, bank.NewClockSkew(bank.BankBBVA, now.Add(-3*time.Minute), now))
+	return s.MockScraper.GetBalance(ctx)
+}
+
+func TestQueue_Warnings(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{accounts: testAccounts()},
+		&mockScraperSource{scraper: &warningScraper{MockScraper: &banktest.MockScraper{}}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA"}
+	require.NoError(t, q.Enqueue(ctx, j))
+
+	done := waitDone(t, q, j.ID)
+	assert.Equal(t, store.ScrapeJobSucceeded, done.Status, "warnings don't fail a job")
+	require.Len(t, done.Result.Warnings, 1)
+	assert.Equal(t, "card skipped", done.Result.Warnings[0].Message)
+	require.Len(t, done.Result.Requests, 1)
+	assert.Equal(t, 200, done.Result.Requests[0].Status)
+	assert.Empty(t, done.Result.RawHTML, "raw HTML is kept only WithRawHTML")
+	require.NotNil(t, done.Result.ClockSkew)
+	assert.Equal(t, -180.0, done.Result.ClockSkew.Seconds)
+}
+
+func TestQueue_RawHTML(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{accounts: testAccounts()},
+		&mockScraperSource{scraper: &warningScraper{MockScraper: &banktest.MockScraper{}}}, WithRawHTML(true))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA"}
+	require.NoError(t, q.Enqueue(ctx, j))
+
+	done := waitDone(t, q, j.ID)
+	require.Len(t, done.Result.RawHTML, 1)
+	assert.Equal(t, "GetBalance", done.Result.RawHTML[0].Operation)
+	assert.Equal(t, "<table>accounts</table>", done.Result.RawHTML[0].HTML)
+}
+
+// variantScraperSource reports a login page variant like session.Manager.
+type variantScraperSource struct {
+	mockScraperSource
+	variant string
+}
+
+func (m *variantScraperSource) LoginVariant(_ context.Context, _ bank.Code) string {
+	return m.variant
+}
+
+func TestQueue_LoginVariant(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{accounts: testAccounts()},
+		&variantScraperSource{mockScraperSource: mockScraperSource{scraper: &banktest.MockScraper{}}, variant: "legacy"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA"}
+	require.NoError(t, q.Enqueue(ctx, j))
+
+	done := waitDone(t, q, j.ID)
+	assert.Equal(t, "legacy", done.Result.LoginVariant)
+}
+
+func TestQueue_Enqueue_Validation(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{}, &mockScraperSource{}, WithBacklog(1))
+	ctx := context.Background()
+
+	assert.ErrorIs(t, q.Enqueue(ctx, &store.ScrapeJob{}), ErrInvalidJob)
+	assert.ErrorIs(t, q.Enqueue(ctx, &store.ScrapeJob{BankCode: "BBVA", TransactionCount: -1}), ErrInvalidJob)
+
+	require.NoError(t, q.Enqueue(ctx, &store.ScrapeJob{BankCode: "BBVA"}))
+	assert.ErrorIs(t, q.Enqueue(ctx, &store.ScrapeJob{BankCode: "BBVA"}), ErrQueueFull, "not started, backlog of one")
+}
+
+func TestQueue_Start_Recovers(t *testing.T) {
+	jobs := NewMemoryStore()
+	ctx := context.Background()
+	running := &store.ScrapeJob{BankCode: "BBVA", Status: store.ScrapeJobRunning}
+	queued := &store.ScrapeJob{BankCode: "BBVA", Status: store.ScrapeJobQueued}
+	require.NoError(t, jobs.Create(ctx, running))
+	require.NoError(t, jobs.Create(ctx, queued))
+
+	q := NewQueue(jobs, &mockAccountRepo{accounts: testAccounts()}, &mockScraperSource{scraper: &banktest.MockScraper{}})
+	runCtx, cancel := context.WithCancel(ctx)
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(runCtx))
+
+	got, err := q.Get(ctx, running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.ScrapeJobFailed, got.Status)
+	assert.Contains(t, got.Error, "interrupted")
+
+	assert.Equal(t, store.ScrapeJobSucceeded, waitDone(t, q, queued.ID).Status)
+}
+
+func TestQueue_Retention(t *testing.T) {
+	jobs := NewMemoryStore()
+	ctx := context.Background()
+	finished := func(ago time.Duration) *store.ScrapeJob {
+		at := time.Now().Add(-ago)
+		j := &store.ScrapeJob{BankCode: "BBVA"}
+		require.NoError(t, jobs.Create(ctx, j))
+		j.Status, j.FinishedAt = store.ScrapeJobSucceeded, &at
+		require.NoError(t, jobs.Update(ctx, j))
+		return j
+	}
+	old, recent := finished(8*24*time.Hour), finished(time.Hour)
+
+	q := NewQueue(jobs, &mockAccountRepo{accounts: testAccounts()}, &mockScraperSource{scraper: &banktest.MockScraper{}},
+		WithRetention(24*time.Hour))
+	runCtx, cancel := context.WithCancel(ctx)
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(runCtx))
+
+	j := &store.ScrapeJob{BankCode: "BBVA", Source: store.ScrapeJobSourceAPI}
+	require.NoError(t, q.Enqueue(ctx, j))
+	waitDone(t, q, j.ID)
+	require.Eventually(t, func() bool {
+		_, err := jobs.Get(ctx, old.ID)
+		return errors.Is(err, store.ErrNotFound)
+	}, 2*time.Second, 5*time.Millisecond, "pruned when a job finishes")
+	_, err := jobs.Get(ctx, recent.ID)
+	assert.NoError(t, err)
+	_, err = jobs.Get(ctx, j.ID)
+	assert.NoError(t, err)
+}
+
+func TestScheduler_EnqueuesActiveBanks(t *testing.T) {
+	jobs := NewMemoryStore()
+	accounts := &mockAccountRepo{accounts: testAccounts()}
+	q := NewQueue(jobs, accounts, &mockScraperSource{})
+
+	NewScheduler(q, accounts, time.Minute, nil).enqueueAll(context.Background())
+
+	queued, err := jobs.ListByStatus(context.Background(), store.ScrapeJobQueued)
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	assert.ElementsMatch(t, []string{"BBVA", "BCP"}, []string{queued[0].BankCode, queued[1].BankCode})
+	assert.Equal(t, store.ScrapeJobSourceScheduler, queued[0].Source)
+}
+
+func TestQueue_PriorityAndBankLimits(t *testing.T) {
+	accts := testAccounts()
+	accts[1].Priority = store.PriorityHigh
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{accounts: accts}, &mockScraperSource{},
+		WithBankConcurrency(1, map[string]int{"bcp": 2}))
+	ctx := context.Background()
+
+	enqueue := func(j *store.ScrapeJob) uuid.UUID {
+		require.NoError(t, q.Enqueue(ctx, j))
+		return j.ID
+	}
+	normal := enqueue(&store.ScrapeJob{BankCode: "BBVA", AccountIDs: []uuid.UUID{accts[0].ID}})
+	bcp1 := enqueue(&store.ScrapeJob{BankCode: "BCP"})
+	bcp2 := enqueue(&store.ScrapeJob{BankCode: "BCP"})
+	inferred := enqueue(&store.ScrapeJob{BankCode: "BBVA"}) // covers the high-priority account
+	explicit := enqueue(&store.ScrapeJob{BankCode: "BCP", Priority: store.PriorityHigh})
+
+	j, err := q.Get(ctx, inferred)
+	require.NoError(t, err)
+	assert.Equal(t, store.PriorityHigh, j.Priority)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var order []uuid.UUID
+	for _, p := range q.pending {
+		order = append(order, p.id)
+	}
+	assert.Equal(t, []uuid.UUID{inferred, explicit, normal, bcp1, bcp2}, order, "high first, FIFO within a priority")
+
+	var started []uuid.UUID
+	for {
+		p, ok := q.next()
+		if !ok {
+			break
+		}
+		started = append(started, p.id)
+	}
+	assert.Equal(t, []uuid.UUID{inferred, explicit, bcp1}, started, "one BBVA job, two BCP jobs at a time")
+}
+
+func TestQueue_Enqueue_InvalidPriority(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{}, &mockScraperSource{})
+	err := q.Enqueue(context.Background(), &store.ScrapeJob{BankCode: "BBVA", Priority: "urgent"})
+	assert.ErrorIs(t, err, ErrInvalidJob)
+}
+
+func TestScheduler_HighPriorityFirst(t *testing.T) {
+	accts := testAccounts()
+	accts[1].Priority = store.PriorityHigh
+	accounts := &mockAccountRepo{accounts: accts}
+	q := NewQueue(NewMemoryStore(), accounts, &mockScraperSource{})
+
+	NewScheduler(q, accounts, time.Minute, nil).enqueueAll(context.Background())
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	require.Len(t, q.pending, 3)
+	assert.True(t, q.pending[0].high)
+	j, err := q.jobs.Get(context.Background(), q.pending[0].id)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{accts[1].ID}, j.AccountIDs)
+}
+
+// profileScraperSource records the profile each scraper was requested for.
+type profileScraperSource struct {
+	mu       sync.Mutex
+	profiles []string
+}
+
+func (m *profileScraperSource) GetScraper(ctx context.Context, _ bank.Code) (bank.Scraper, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profiles = append(m.profiles, store.ProfileFrom(ctx))
+	return &banktest.MockScraper{}, nil
+}
+
+func TestQueue_Profiles(t *testing.T) {
+	scrapers := &profileScraperSource{}
+	q := NewQueue(NewMemoryStore(), &mockAccountRepo{accounts: testAccounts()}, scrapers)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); q.Wait() }()
+	require.NoError(t, q.Start(ctx))
+
+	acme := store.WithProfile(ctx, "acme")
+	j := &store.ScrapeJob{BankCode: "BBVA", Source: store.ScrapeJobSourceAPI}
+	require.NoError(t, q.Enqueue(acme, j))
+	assert.Equal(t, "acme", j.Profile)
+
+	_, err := q.Get(ctx, j.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound, "other profiles' jobs are hidden")
+	require.Eventually(t, func() bool {
+		done, err := q.Get(acme, j.ID)
+		require.NoError(t, err)
+		return done.Done()
+	}, 2*time.Second, 5*time.Millisecond)
+
+	scrapers.mu.Lock()
+	defer scrapers.mu.Unlock()
+	assert.Equal(t, []string{"acme"}, scrapers.profiles, "job runs with its profile's session")
+}
+
+func TestScheduler_WithProfile(t *testing.T) {
+	jobs := NewMemoryStore()
+	accounts := &mockAccountRepo{accounts: testAccounts()}
+	q := NewQueue(jobs, accounts, &mockScraperSource{})
+
+	NewScheduler(q, accounts, time.Minute, nil).WithProfile("acme").enqueueAll(context.Background())
+
+	queued, err := jobs.ListByStatus(context.Background(), store.ScrapeJobQueued)
+	require.NoError(t, err)
+	require.NotEmpty(t, queued)
+	for _, j := range queued {
+		assert.Equal(t, "acme", j.Profile)
+	}
+}
+
+func TestScheduler_Calendar(t *testing.T) {
+	cal, err := calendar.Peru()
+	require.NoError(t, err)
+	weekday := time.Date(2026, 10, 15, 9, 0, 0, 0, calendar.Lima)
+	holiday := time.Date(2026, 7, 28, 9, 0, 0, 0, calendar.Lima)
+
+	s := NewScheduler(nil, nil, time.Minute, nil)
+	assert.True(t, s.due(holiday), "no calendar: every day")
+
+	s.WithCalendar(cal, 0)
+	assert.True(t, s.due(weekday))
+	assert.False(t, s.due(holiday), "zero quiet interval skips holidays")
+
+	s.WithCalendar(cal, 6*time.Hour)
+	s.last = holiday.Add(-time.Hour)
+	assert.False(t, s.due(holiday))
+	assert.True(t, s.due(holiday.Add(5*time.Hour)))
+}
@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// MemoryStore keeps jobs in process memory. It is the default store: jobs
+// and their results are lost on restart, and kept until then unless the
+// queue prunes them (WithRetention). Satisfies store.ScrapeJobRepository.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]store.ScrapeJob
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[uuid.UUID]store.ScrapeJob)}
+}
+
+// Create stores a new job, assigning its ID and creation time.
+func (s *MemoryStore) Create(ctx context.Context, j *store.ScrapeJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j.ID = uuid.New()
+	j.CreatedAt = time.Now()
+	if j.Status == "" {
+		j.Status = store.ScrapeJobQueued
+	}
+	if j.Profile == "" {
+		j.Profile = store.ProfileFrom(ctx)
+	}
+	s.jobs[j.ID] = *j
+	return nil
+}
+
+// Get returns a copy of the job with the given ID.
+func (s *MemoryStore) Get(_ context.Context, id uuid.UUID) (*store.ScrapeJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("scrape job %s: %w", id, store.ErrNotFound)
+	}
+	return &j, nil
+}
+
+// Update replaces the stored job.
+func (s *MemoryStore) Update(_ context.Context, j *store.ScrapeJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[j.ID]; !ok {
+		return fmt.Errorf("scrape job %s: %w", j.ID, store.ErrNotFound)
+	}
+	s.jobs[j.ID] = *j
+	return nil
+}
+
+// ListByStatus returns jobs with the given status, oldest first.
+func (s *MemoryStore) ListByStatus(_ context.Context, status string) ([]store.ScrapeJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.ScrapeJob
+	for _, j := range s.jobs {
+		if j.Status == status {
+			out = append(out, j)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].CreatedAt.Before(out[b].CreatedAt) })
+	return out, nil
+}
+
+// ListRecent returns the latest limit jobs of the context's profile, newest
+// first.
+func (s *MemoryStore) ListRecent(ctx context.Context, limit int) ([]store.ScrapeJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.ScrapeJob
+	for _, j := range s.jobs {
+		if j.Profile == store.ProfileFrom(ctx) {
+			out = append(out, j)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].CreatedAt.After(out[b].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// DeleteFinishedBefore removes jobs that finished before before, keeping
+// queued and running ones.
+func (s *MemoryStore) DeleteFinishedBefore(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, j := range s.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(before) {
+			delete(s.jobs, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,628 @@
+// Package jobs runs scrape requests asynchronously. Callers — the REST API
+// and the periodic scheduler — enqueue a job and poll it by ID; a fixed pool
+// of workers fetches balances and transactions through the shared scraper
+// provider, so bank logins are serialized no matter who asks.
+//
+// High-priority jobs (explicitly requested, or covering an account marked
+// high priority) jump ahead of normal ones. The worker count caps
+// concurrency globally; per-bank limits cap it for each bank.
+//
+// A job that fails for some of its accounts but not all is degraded rather
+// than failed: the accounts that worked keep their results and the others
+// carry their own error.
+//
+// Each job belongs to the profile it was enqueued under (see
+// store.WithProfile) and runs with that profile's accounts and bank session.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/pipeline"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// Queue defaults.
+const (
+	DefaultWorkers          = 1
+	DefaultBankConcurrency  = 1
+	DefaultBacklog          = 100
+	DefaultTransactionCount = 50
+)
+
+// Sentinel errors for enqueueing.
+var (
+	ErrQueueFull  = errors.New("scrape queue is full")
+	ErrInvalidJob = errors.New("invalid scrape job")
+)
+
+// ScraperSource returns a logged-in scraper for a bank.
+// Satisfied by session.Manager and its wrappers.
+type ScraperSource interface {
+	GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error)
+}
+
+// loginVariantSource is implemented by scraper sources that know which
+// login page variant a bank session got, such as session.Manager.
+type loginVariantSource interface {
+	LoginVariant(ctx context.Context, bankCode bank.Code) string
+}
+
+// Publisher notifies subscribers of finished jobs.
+// Satisfied by events.Hub.
+type Publisher interface {
+	Publish(e events.Event)
+}
+
+// Stats counts the queue's jobs: those waiting and running now, and those
+// finished since the process started, by outcome.
+type Stats struct {
+	Queued    int `json:"queued"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Degraded  int `json:"degraded"`
+	Failed    int `json:"failed"`
+}
+
+// pendingJob is a job waiting for a worker.
+type pendingJob struct {
+	id       uuid.UUID
+	bankCode string
+	high     bool
+}
+
+// Queue accepts scrape jobs and runs them on a worker pool.
+type Queue struct {
+	jobs     store.ScrapeJobRepository
+	accounts store.AccountRepository
+	scrapers ScraperSource
+	logger   *slog.Logger
+	events   Publisher
+	workers  int
+	backlog  int
+	txCount  int
+	rawHTML  bool // Keep the HTML each scraper parsed in the job result
+	pipeline *pipeline.Pipeline
+	keep     time.Duration // How long finished jobs are kept; zero is forever
+	now      func() time.Time
+
+	bankLimit   int            // per-bank concurrency unless overridden
+	bankLimits  map[string]int // per-bank overrides
+	mu          sync.Mutex
+	cond        *sync.Cond
+	pending     []pendingJob // high priority first, FIFO within a priority
+	runningBank map[string]int
+	finished    map[string]int // outcome counts by job status
+	wg          sync.WaitGroup
+}
+
+// Option configures a Queue.
+type Option func(*Queue)
+
+// WithWorkers sets how many jobs run concurrently.
+func WithWorkers(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.workers = n
+		}
+	}
+}
+
+// WithBacklog sets how many jobs may wait before Enqueue returns ErrQueueFull.
+func WithBacklog(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.backlog = n
+		}
+	}
+}
+
+// WithBankConcurrency sets how many jobs may run at once against a single
+// bank: def for every bank, overridden per bank code by limits.
+func WithBankConcurrency(def int, limits map[string]int) Option {
+	return func(q *Queue) {
+		if def > 0 {
+			q.bankLimit = def
+		}
+		for code, n := range limits {
+			if n > 0 {
+				q.bankLimits[strings.ToUpper(code)] = n
+			}
+		}
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(l *slog.Logger) Option {
+	return func(q *Queue) {
+		if l != nil {
+			q.logger = l
+		}
+	}
+}
+
+// WithPublisher publishes a job.succeeded, job.degraded or job.failed event
+// whenever a job finishes.
+func WithPublisher(p Publisher) Option {
+	return func(q *Queue) {
+		q.events = p
+	}
+}
+
+// WithRawHTML keeps the HTML each scraper operation parsed in the job
+// result, so a reported parse discrepancy can be replayed offline. Results
+// grow by megabytes per account.
+func WithRawHTML(enabled bool) Option {
+	return func(q *Queue) {
+		q.rawHTML = enabled
+	}
+}
+
+// WithPipeline runs each account's transactions through p (fetch, dedupe,
+// store, notify, ...) instead of only fetching them. The job result keeps
+// every fetched transaction, whatever later steps filter out.
+func WithPipeline(p *pipeline.Pipeline) Option {
+	return func(q *Queue) {
+		q.pipeline = p
+	}
+}
+
+// WithRetention deletes finished jobs, with their results, once they have
+// been finished for d, checking whenever a job finishes. Results carry every
+// scraped transaction, so without it the job store only grows. Zero keeps
+// jobs forever.
+func WithRetention(d time.Duration) Option {
+	return func(q *Queue) {
+		if d > 0 {
+			q.keep = d
+		}
+	}
+}
+
+// NewQueue creates a Queue persisting jobs in jobs. Call Start to begin
+// processing.
+func NewQueue(jobs store.ScrapeJobRepository, accounts store.AccountRepository, scrapers ScraperSource, opts ...Option) *Queue {
+	q := &Queue{
+		jobs:     jobs,
+		accounts: accounts,
+		scrapers: scrapers,
+		logger:   slog.Default(),
+		workers:  DefaultWorkers,
+		backlog:  DefaultBacklog,
+		txCount:  DefaultTransactionCount,
+		now:      time.Now,
+
+		bankLimit:   DefaultBankConcurrency,
+		bankLimits:  map[string]int{},
+		runningBank: map[string]int{},
+		finished:    map[string]int{},
+	}
+	q.cond = sync.NewCond(&q.mu)
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Enqueue validates and stores j as queued, populating its ID. The job runs
+// once a worker is free, in the context's profile unless j names one.
+func (q *Queue) Enqueue(ctx context.Context, j *store.ScrapeJob) error {
+	if j.BankCode == "" {
+		return fmt.Errorf("%w: bank_code is required", ErrInvalidJob)
+	}
+	if j.TransactionCount < 0 {
+		return fmt.Errorf("%w: transaction_count must not be negative", ErrInvalidJob)
+	}
+	if j.Priority != "" && !store.ValidPriority(j.Priority) {
+		return fmt.Errorf("%w: priority must be %s or %s", ErrInvalidJob, store.PriorityNormal, store.PriorityHigh)
+	}
+	if q.full() {
+		return ErrQueueFull
+	}
+
+	if j.Profile == "" {
+		j.Profile = store.ProfileFrom(ctx)
+	}
+	ctx = store.WithProfile(ctx, j.Profile)
+	if j.Priority == "" {
+		p, err := q.inferPriority(ctx, j)
+		if err != nil {
+			return fmt.Errorf("enqueue: %w", err)
+		}
+		j.Priority = p
+	}
+	j.Status = store.ScrapeJobQueued
+	j.Result, j.Error, j.StartedAt, j.FinishedAt = nil, "", nil, nil
+	if err := q.jobs.Create(ctx, j); err != nil {
+		return fmt.Errorf("enqueue: %w", err)
+	}
+
+	if !q.push(j) {
+		q.finish(ctx, j, nil, ErrQueueFull)
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// inferPriority returns high if the job covers an active high-priority account.
+func (q *Queue) inferPriority(ctx context.Context, j *store.ScrapeJob) (string, error) {
+	code := j.BankCode
+	accounts, err := q.accounts.List(ctx, store.AccountFilter{BankCode: &code})
+	if err != nil {
+		return "", fmt.Errorf("list accounts: %w", err)
+	}
+	for _, a := range accounts {
+		covered := len(j.AccountIDs) == 0 || slices.Contains(j.AccountIDs, a.ID)
+		if covered && a.Priority == store.PriorityHigh && isActive(a) {
+			return store.PriorityHigh, nil
+		}
+	}
+	return store.PriorityNormal, nil
+}
+
+func (q *Queue) full() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) >= q.backlog
+}
+
+// push adds j to the pending list behind jobs of the same or higher
+// priority. It returns false if the backlog is full.
+func (q *Queue) push(j *store.ScrapeJob) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) >= q.backlog {
+		return false
+	}
+
+	p := pendingJob{id: j.ID, bankCode: j.BankCode, high: j.Priority == store.PriorityHigh}
+	i := len(q.pending)
+	if p.high {
+		i = slices.IndexFunc(q.pending, func(o pendingJob) bool { return !o.high })
+		if i < 0 {
+			i = len(q.pending)
+		}
+	}
+	q.pending = slices.Insert(q.pending, i, p)
+	q.cond.Broadcast()
+	return true
+}
+
+// next removes and returns the first pending job whose bank has spare
+// capacity. Must hold q.mu.
+func (q *Queue) next() (pendingJob, bool) {
+	for i, p := range q.pending {
+		limit, ok := q.bankLimits[p.bankCode]
+		if !ok {
+			limit = q.bankLimit
+		}
+		if q.runningBank[p.bankCode] < limit {
+			q.pending = slices.Delete(q.pending, i, i+1)
+			q.runningBank[p.bankCode]++
+			return p, true
+		}
+	}
+	return pendingJob{}, false
+}
+
+// Get returns a job by ID. Jobs of other profiles than the context's are
+// reported as not found.
+func (q *Queue) Get(ctx context.Context, id uuid.UUID) (*store.ScrapeJob, error) {
+	j, err := q.jobs.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if j.Profile != store.ProfileFrom(ctx) {
+		return nil, fmt.Errorf("scrape job %s: %w", id, store.ErrNotFound)
+	}
+	return j, nil
+}
+
+// Recent returns the latest limit jobs of the context's profile, newest
+// first, finished or not.
+func (q *Queue) Recent(ctx context.Context, limit int) ([]store.ScrapeJob, error) {
+	return q.jobs.ListRecent(ctx, limit)
+}
+
+// Start resumes jobs left queued by a previous run, marks jobs it was
+// running when it stopped as failed, and starts the workers. Workers stop
+// when ctx is cancelled; Wait blocks until they have.
+func (q *Queue) Start(ctx context.Context) error {
+	interrupted, err := q.jobs.ListByStatus(ctx, store.ScrapeJobRunning)
+	if err != nil {
+		return fmt.Errorf("list interrupted jobs: %w", err)
+	}
+	for i := range interrupted {
+		q.finish(ctx, &interrupted[i], nil, errors.New("interrupted by server restart"))
+	}
+
+	queued, err := q.jobs.ListByStatus(ctx, store.ScrapeJobQueued)
+	if err != nil {
+		return fmt.Errorf("list queued jobs: %w", err)
+	}
+	for i := range queued {
+		if !q.push(&queued[i]) {
+			q.finish(ctx, &queued[i], nil, ErrQueueFull)
+		}
+	}
+
+	// Wake idle workers so they notice cancellation.
+	go func() {
+		<-ctx.Done()
+		q.mu.Lock()
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}()
+
+	for range q.workers {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+	return nil
+}
+
+// Stats returns the current job counts.
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	st := Stats{
+		Queued:    len(q.pending),
+		Succeeded: q.finished[store.ScrapeJobSucceeded],
+		Degraded:  q.finished[store.ScrapeJobDegraded],
+		Failed:    q.finished[store.ScrapeJobFailed],
+	}
+	for _, n := range q.runningBank {
+		st.Running += n
+	}
+	return st
+}
+
+// Wait blocks until all workers have stopped.
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+	for {
+		q.mu.Lock()
+		var (
+			p  pendingJob
+			ok bool
+		)
+		for {
+			if ctx.Err() != nil {
+				q.mu.Unlock()
+				return
+			}
+			if p, ok = q.next(); ok {
+				break
+			}
+			q.cond.Wait()
+		}
+		q.mu.Unlock()
+
+		q.process(ctx, p.id)
+
+		q.mu.Lock()
+		q.runningBank[p.bankCode]--
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+func (q *Queue) process(ctx context.Context, id uuid.UUID) {
+	j, err := q.jobs.Get(ctx, id)
+	if err != nil {
+		q.logger.Error("scrape job vanished", slog.String("job_id", id.String()), slog.Any("error", err))
+		return
+	}
+	ctx = store.WithProfile(ctx, j.Profile)
+
+	started := q.now()
+	j.Status, j.StartedAt = store.ScrapeJobRunning, &started
+	if err := q.jobs.Update(ctx, j); err != nil {
+		q.logger.Error("mark scrape job running", slog.String("job_id", id.String()), slog.Any("error", err))
+	}
+
+	result, err := q.run(ctx, j)
+	q.finish(ctx, j, result, err)
+}
+
+// finish records a job's outcome: failed if err is set, degraded if some
+// accounts failed, succeeded otherwise. It uses a context detached from ctx's
+// cancellation so a job interrupted by shutdown is still marked failed.
+func (q *Queue) finish(ctx context.Context, j *store.ScrapeJob, result *store.ScrapeResult, err error) {
+	finished := q.now()
+	j.FinishedAt = &finished
+	j.Result = result
+	failed := result.Failed()
+	switch {
+	case err != nil:
+		j.Status, j.Error = store.ScrapeJobFailed, err.Error()
+		q.logger.Warn("scrape job failed",
+			slog.String("job_id", j.ID.String()),
+			slog.String("bank", j.BankCode),
+			slog.Any("error", err))
+	case len(failed) > 0:
+		j.Status = store.ScrapeJobDegraded
+		j.Error = fmt.Sprintf("%d of %d accounts failed", len(failed), len(result.Accounts))
+		q.logger.Warn("scrape job degraded",
+			slog.String("job_id", j.ID.String()),
+			slog.String("bank", j.BankCode),
+			slog.Int("failed_accounts", len(failed)),
+			slog.Int("accounts", len(result.Accounts)))
+	default:
+		j.Status = store.ScrapeJobSucceeded
+	}
+	if result != nil && len(result.Warnings) > 0 {
+		q.logger.Warn("scrape job had parse warnings",
+			slog.String("job_id", j.ID.String()),
+			slog.String("bank", j.BankCode),
+			slog.Int("warnings", len(result.Warnings)))
+	}
+	if err := q.jobs.Update(context.WithoutCancel(ctx), j); err != nil {
+		q.logger.Error("save scrape job", slog.String("job_id", j.ID.String()), slog.Any("error", err))
+	}
+	if q.keep > 0 {
+		if n, err := q.jobs.DeleteFinishedBefore(context.WithoutCancel(ctx), finished.Add(-q.keep)); err != nil {
+			q.logger.Error("prune scrape jobs", slog.Any("error", err))
+		} else if n > 0 {
+			q.logger.Debug("pruned scrape jobs", slog.Int64("jobs", n), slog.Duration("retention", q.keep))
+		}
+	}
+
+	q.mu.Lock()
+	q.finished[j.Status]++
+	q.mu.Unlock()
+	q.publish(j, failed)
+}
+
+// publish announces a finished job, listing the accounts that failed.
+func (q *Queue) publish(j *store.ScrapeJob, failed []store.ScrapeAccountResult) {
+	if q.events == nil {
+		return
+	}
+	data := map[string]any{"job_id": j.ID.String(), "status": j.Status}
+	if j.Error != "" {
+		data["error"] = j.Error
+	}
+	if j.Result != nil && len(j.Result.Warnings) > 0 {
+		data["warnings"] = len(j.Result.Warnings)
+	}
+	if len(failed) > 0 {
+		errs := make(map[string]string, len(failed))
+		for _, a := range failed {
+			errs[a.AccountID.String()] = a.Error
+		}
+		data["account_errors"] = errs
+	}
+	typ := events.TypeJobSucceeded
+	switch j.Status {
+	case store.ScrapeJobDegraded:
+		typ = events.TypeJobDegraded
+	case store.ScrapeJobFailed:
+		typ = events.TypeJobFailed
+	}
+	q.events.Publish(events.Event{Type: typ, Profile: j.Profile, BankCode: j.BankCode, Data: data})
+}
+
+// run fetches the balance and recent transactions of every requested account.
+// A failure that concerns only some accounts is recorded on their results
+// and the others are still fetched; run returns an error only when nothing
+// could be fetched at all.
+func (q *Queue) run(ctx context.Context, j *store.ScrapeJob) (*store.ScrapeResult, error) {
+	code := j.BankCode
+	accounts, err := q.accounts.List(ctx, store.AccountFilter{BankCode: &code})
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+	accounts = slices.DeleteFunc(accounts, func(a store.Account) bool {
+		return !isActive(a) || len(j.AccountIDs) > 0 && !slices.Contains(j.AccountIDs, a.ID)
+	})
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no active %s accounts match the job", code)
+	}
+	// High-priority accounts first, so they're fresh even if a later one fails.
+	slices.SortStableFunc(accounts, func(a, b store.Account) int {
+		return boolRank(b.Priority == store.PriorityHigh) - boolRank(a.Priority == store.PriorityHigh)
+	})
+
+	scraper, err := q.scrapers.GetScraper(ctx, bank.Code(code))
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	warnings := &bank.Warnings{}
+	ctx = bank.WithWarnings(ctx, warnings)
+	requests := &bank.RequestLog{}
+	ctx = bank.WithRequestLog(ctx, requests)
+	var skew *bank.ClockSkew
+	ctx = bank.WithClockSkew(ctx, func(c bank.ClockSkew) { skew = &c })
+	var rawHTML *bank.RawHTMLLog
+	if q.rawHTML {
+		rawHTML = &bank.RawHTMLLog{}
+		ctx = bank.WithRawHTML(ctx, rawHTML)
+	}
+	// Balances come from one page for all accounts; without them the
+	// transactions are still worth having.
+	balances, balanceErr := scraper.GetBalance(ctx)
+
+	count := j.TransactionCount
+	if count == 0 {
+		count = q.txCount
+	}
+
+	result := &store.ScrapeResult{}
+	if lv, ok := q.scrapers.(loginVariantSource); ok {
+		result.LoginVariant = lv.LoginVariant(ctx, bank.Code(code))
+	}
+	for _, a := range accounts {
+		if err := ctx.Err(); err != nil {
+			result.Warnings, result.Requests, result.ClockSkew = warnings.List(), requests.List(), skew
+			if rawHTML != nil {
+				result.RawHTML = rawHTML.List()
+			}
+			return result, fmt.Errorf("interrupted: %w", err)
+		}
+
+		ar := store.ScrapeAccountResult{AccountID: a.ID}
+		var errs []string
+		if balanceErr != nil {
+			errs = append(errs, fmt.Sprintf("get balance: %v", balanceErr))
+		} else if i := slices.IndexFunc(balances, func(b bank.Balance) bool { return b.AccountID == a.AccountNumber }); i >= 0 {
+			ar.Balance = &balances[i]
+		}
+
+		if q.pipeline != nil {
+			b := &pipeline.Batch{Profile: j.Profile, BankCode: bank.Code(code), Account: a, Scraper: scraper, Count: count, Balance: ar.Balance}
+			err = q.pipeline.Run(ctx, b)
+			ar.Transactions, ar.Gap = b.Fetched, b.Gap
+			if b.Gap != nil {
+				q.logger.Warn("balance gap", slog.String("account_id", a.ID.String()),
+					slog.Time("from", b.Gap.From), slog.Time("to", b.Gap.To),
+					slog.Int64("unexplained", b.Gap.Unexplained), slog.Bool("backfilled", b.Gap.Backfilled))
+			}
+		} else {
+			ar.Transactions, err = scraper.GetTransactions(ctx, a.AccountNumber, count)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("get transactions: %v", err))
+		} else {
+			if err := q.accounts.UpdateLastSynced(ctx, a.ID); err != nil {
+				q.logger.Warn("update last synced", slog.String("account_id", a.ID.String()), slog.Any("error", err))
+			}
+		}
+		ar.Error = strings.Join(errs, "; ")
+		result.Accounts = append(result.Accounts, ar)
+	}
+	result.Warnings, result.Requests, result.ClockSkew = warnings.List(), requests.List(), skew
+	if rawHTML != nil {
+		result.RawHTML = rawHTML.List()
+	}
+
+	if failed := result.Failed(); len(failed) == len(result.Accounts) {
+		return result, fmt.Errorf("all %d accounts failed: %s", len(failed), failed[0].Error)
+	}
+	return result, nil
+}
+
+func isActive(a store.Account) bool {
+	return a.Status == "" || a.Status == store.AccountStatusActive
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
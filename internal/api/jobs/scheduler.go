@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/calendar"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// Scheduler periodically enqueues a scrape of every bank with active
+// accounts of one profile. A bank's high-priority accounts get their own
+// high-priority job, so they are fetched ahead of everything else.
+type Scheduler struct {
+	queue    *Queue
+	accounts store.AccountRepository
+	interval time.Duration
+	profile  string
+	logger   *slog.Logger
+
+	calendar *calendar.Calendar // Days without postings; nil scrapes every day
+	quiet    time.Duration      // Interval on those days; zero skips them
+	last     time.Time          // Last enqueue, for pacing quiet days
+}
+
+// NewScheduler creates a Scheduler enqueueing into queue every interval.
+func NewScheduler(queue *Queue, accounts store.AccountRepository, interval time.Duration, logger *slog.Logger) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Scheduler{queue: queue, accounts: accounts, interval: interval, profile: store.DefaultProfile, logger: logger}
+}
+
+// WithProfile schedules the accounts of profile instead of the default
+// profile's. Run one Scheduler per profile, each with its own interval.
+func (s *Scheduler) WithProfile(profile string) *Scheduler {
+	s.profile = profile
+	return s
+}
+
+// WithCalendar slows the schedule down on days cal has no postings on
+// (weekends and holidays): scrapes are enqueued every quiet instead, or not
+// at all when quiet is zero. Nothing new shows up on those days, so
+// scraping them only spends logins.
+func (s *Scheduler) WithCalendar(cal *calendar.Calendar, quiet time.Duration) *Scheduler {
+	s.calendar, s.quiet = cal, quiet
+	return s
+}
+
+// Run enqueues on every tick until ctx is cancelled. It does nothing if the
+// interval isn't positive.
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !s.due(now) {
+				s.logger.Debug("scheduler: no postings today, skipping", slog.String("profile", s.profile))
+				continue
+			}
+			s.last = now
+			s.enqueueAll(ctx)
+		}
+	}
+}
+
+// due reports whether a tick at now should enqueue: always on business
+// days, and once per quiet interval on the others.
+func (s *Scheduler) due(now time.Time) bool {
+	if s.calendar.BusinessDay(now) {
+		return true
+	}
+	return s.quiet > 0 && now.Sub(s.last) >= s.quiet
+}
+
+// enqueueAll enqueues the high-priority accounts of every bank, then the
+// rest.
+func (s *Scheduler) enqueueAll(ctx context.Context) {
+	ctx = store.WithProfile(ctx, s.profile)
+	accounts, err := s.accounts.List(ctx, store.AccountFilter{})
+	if err != nil {
+		s.logger.Error("scheduler: list accounts", slog.String("profile", s.profile), slog.Any("error", err))
+		return
+	}
+
+	var (
+		banks     []string
+		high, low = map[string][]uuid.UUID{}, map[string][]uuid.UUID{}
+	)
+	for _, a := range accounts {
+		if !isActive(a) {
+			continue
+		}
+		if !slices.Contains(banks, a.BankCode) {
+			banks = append(banks, a.BankCode)
+		}
+		if a.Priority == store.PriorityHigh {
+			high[a.BankCode] = append(high[a.BankCode], a.ID)
+		} else {
+			low[a.BankCode] = append(low[a.BankCode], a.ID)
+		}
+	}
+
+	enqueue := func(code, priority string, ids []uuid.UUID) {
+		if len(ids) == 0 {
+			return
+		}
+		j := &store.ScrapeJob{BankCode: code, AccountIDs: ids, Priority: priority, Source: store.ScrapeJobSourceScheduler}
+		if err := s.queue.Enqueue(ctx, j); err != nil {
+			s.logger.Warn("scheduler: enqueue", slog.String("profile", s.profile), slog.String("bank", code), slog.Any("error", err))
+		}
+	}
+	for _, code := range banks {
+		enqueue(code, store.PriorityHigh, high[code])
+	}
+	for _, code := range banks {
+		enqueue(code, store.PriorityNormal, low[code])
+	}
+}
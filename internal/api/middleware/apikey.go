@@ -8,12 +8,14 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/aynifx/bank-scraper/internal/api/session"
 	"github.com/aynifx/bank-scraper/internal/store"
 )
 
 const (
 	headerAPIKey     = "X-API-Key"
 	contextKeyClient = "client_id"
+	contextKeyAPIKey = "api_key"
 )
 
 // errorJSON sends a standard error response and aborts the request.
@@ -24,7 +26,8 @@ func errorJSON(c *gin.Context, status int, message string) {
 	})
 }
 
-// APIKeyAuth returns Gin middleware that validates the X-API-Key header.
+// APIKeyAuth returns Gin middleware that validates the X-API-Key header and
+// scopes the request to the key's profile (see store.WithProfile).
 func APIKeyAuth(repo store.APIKeyRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		rawKey := c.GetHeader(headerAPIKey)
@@ -53,10 +56,54 @@ func APIKeyAuth(repo store.APIKeyRepository) gin.HandlerFunc {
 		go func() { _ = repo.UpdateLastUsed(context.Background(), apiKey.ID) }()
 
 		c.Set(contextKeyClient, apiKey.ClientID)
+		c.Set(contextKeyAPIKey, apiKey)
+		c.Request = c.Request.WithContext(store.WithProfile(c.Request.Context(), apiKey.Profile))
 		c.Next()
 	}
 }
 
+// RequireScope returns Gin middleware that rejects requests whose API key
+// lacks scope with 403. It must run after APIKeyAuth.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasScope(c, scope) {
+			errorJSON(c, http.StatusForbidden, "API key lacks the "+scope+" scope")
+			return
+		}
+		c.Next()
+	}
+}
+
+// ScrapeScopeLogin returns Gin middleware that stops keys without the scrape
+// scope from opening bank sessions: their requests are served from existing
+// sessions only (see session.WithoutLogin). It must run after APIKeyAuth.
+func ScrapeScopeLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasScope(c, store.APIKeyScopeScrape) {
+			c.Request = c.Request.WithContext(session.WithoutLogin(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
+
+// HasScope reports whether the authenticated API key grants scope.
+// Returns false if the API key middleware was not applied.
+func HasScope(c *gin.Context, scope string) bool {
+	k := GetAPIKey(c)
+	return k != nil && k.HasScope(scope)
+}
+
+// GetAPIKey retrieves the authenticated API key from the Gin context.
+// Returns nil if the API key middleware was not applied.
+func GetAPIKey(c *gin.Context) *store.APIKey {
+	v, exists := c.Get(contextKeyAPIKey)
+	if !exists {
+		return nil
+	}
+	k, _ := v.(*store.APIKey)
+	return k
+}
+
 // GetClientID retrieves the authenticated client ID from the Gin context.
 // Returns empty string if the API key middleware was not applied.
 func GetClientID(c *gin.Context) string {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/aynifx/bank-scraper/internal/api/session"
 	"github.com/aynifx/bank-scraper/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,7 +65,11 @@ func setupRouter(repo store.APIKeyRepository) *gin.Engine {
 }
 
 func makeRequest(router *gin.Engine, apiKey string) *httptest.ResponseRecorder {
-	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	return makeRequestPath(router, "/test", apiKey)
+}
+
+func makeRequestPath(router *gin.Engine, path, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
 	if apiKey != "" {
 		req.Header.Set("X-API-Key", apiKey)
 	}
@@ -121,6 +126,22 @@ func TestAPIKeyAuth_ValidKey(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuth_ScopesProfile(t *testing.T) {
+	repo := &mockAPIKeyRepo{key: &store.APIKey{ID: uuid.New(), ClientID: "c", Profile: "acme"}}
+	r := gin.New()
+	r.Use(APIKeyAuth(repo))
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"profile": store.ProfileFrom(c.Request.Context())})
+	})
+
+	w := makeRequest(r, "key")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "acme", resp["profile"])
+}
+
 func TestAPIKeyAuth_MissingHeader(t *testing.T) {
 	repo := &mockAPIKeyRepo{}
 	router := setupRouter(repo)
@@ -171,3 +192,58 @@ func TestAPIKeyAuth_GetClientID_NoAuth(t *testing.T) {
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	assert.Equal(t, "", GetClientID(c))
 }
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		wantStatus int
+	}{
+		{"has scope", []string{store.APIKeyScopeRead, store.APIKeyScopeScrape}, http.StatusOK},
+		{"missing scope", []string{store.APIKeyScopeRead}, http.StatusForbidden},
+		{"admin implies all", []string{store.APIKeyScopeAdmin}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockAPIKeyRepo{key: &store.APIKey{ID: uuid.New(), ClientID: "dash", Scopes: tt.scopes}}
+			r := gin.New()
+			r.Use(APIKeyAuth(repo), RequireScope(store.APIKeyScopeScrape))
+			r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := makeRequest(r, "key")
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.Contains(t, parseError(t, w).Message, "scrape scope")
+			}
+		})
+	}
+}
+
+func TestHasScope_NoAuth(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.False(t, HasScope(c, store.APIKeyScopeRead))
+}
+
+func TestScrapeScopeLogin(t *testing.T) {
+	for _, tc := range []struct {
+		scopes []string
+		login  bool
+	}{
+		{[]string{store.APIKeyScopeRead}, false},
+		{[]string{store.APIKeyScopeRead, store.APIKeyScopeScrape}, true},
+		{[]string{store.APIKeyScopeAdmin}, true},
+	} {
+		repo := &mockAPIKeyRepo{key: &store.APIKey{ID: uuid.New(), ClientID: "c", Scopes: tc.scopes}}
+		r := gin.New()
+		r.Use(APIKeyAuth(repo), ScrapeScopeLogin())
+		r.GET("/test", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"login": session.LoginAllowed(c.Request.Context())})
+		})
+
+		w := makeRequest(r, "key")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct{ Login bool }
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, tc.login, resp.Login, "scopes %v", tc.scopes)
+	}
+}
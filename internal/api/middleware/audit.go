@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Audit action constants for API-triggered bank access.
+const (
+	AuditAPIScrape     = "api_scrape"
+	AuditAPISession    = "api_session_opened"
+	AuditAPIDiscover   = "api_discovery"
+	AuditAPIScrapeJob  = "api_scrape_job"
+	AuditWebhookScrape = "webhook_scrape"
+)
+
+const contextKeyAuditTarget = "audit_target"
+
+// SetAuditTarget sets the audit entry's target ID for routes whose target
+// isn't a path parameter, such as one named in the request body.
+func SetAuditTarget(c *gin.Context, id string) {
+	c.Set(contextKeyAuditTarget, id)
+}
+
+// AuditLogger records audit log entries.
+// Satisfied by credmgr/service.AuditWriter.
+type AuditLogger interface {
+	Log(ctx context.Context, userID *uuid.UUID, action, targetType, targetID, ip, ua string, success bool, details map[string]any)
+}
+
+// Audit returns Gin middleware that writes an audit log entry once the
+// request completes, including requests rejected further down the chain
+// (scope, rate limit). targetParam names the route parameter identifying
+// the target; without one the target is whatever the handler passed to
+// SetAuditTarget. API keys aren't users, so the entry carries the key and client
+// in its details. A nil logger disables auditing.
+func Audit(logger AuditLogger, action, targetType, targetParam string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if logger == nil {
+			return
+		}
+
+		target := c.GetString(contextKeyAuditTarget)
+		if targetParam != "" {
+			target = c.Param(targetParam)
+		}
+		status := c.Writer.Status()
+		details := map[string]any{
+			"client_id": GetClientID(c),
+			"method":    c.Request.Method,
+			"path":      c.Request.URL.Path,
+			"status":    status,
+		}
+		if k := GetAPIKey(c); k != nil {
+			details["api_key_id"] = k.ID.String()
+		}
+		logger.Log(context.WithoutCancel(c.Request.Context()), nil, action, targetType, target,
+			c.ClientIP(), c.Request.UserAgent(), status < http.StatusBadRequest, details)
+	}
+}
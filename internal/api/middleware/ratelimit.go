@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidRateLimit is returned for a window too short to refill the
+// limit's requests.
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// bucket is one API key's token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter allows each API key a burst of limit requests, refilled evenly
+// over window. It guards endpoints that make the server log in to a bank, so
+// a misbehaving integration can't lock out the bank user.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewRateLimiter creates a RateLimiter. A limit of zero or less disables it;
+// otherwise window must be at least a nanosecond per request.
+func NewRateLimiter(limit int, window time.Duration) (*RateLimiter, error) {
+	if limit > 0 && window < time.Duration(limit) {
+		return nil, fmt.Errorf("%w: %d requests per %s", ErrInvalidRateLimit, limit, window)
+	}
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}, nil
+}
+
+// RateLimitError is returned through session.WithLoginGate for a login over
+// the limit.
+type RateLimitError struct {
+	RetryAfter time.Duration // Until the next login is allowed
+}
+
+func (e *RateLimitError) Error() string {
+	return "rate limit exceeded"
+}
+
+// RetryAfterHeader returns RetryAfter as a Retry-After header value, in
+// whole seconds rounded up.
+func (e *RateLimitError) RetryAfterHeader() string {
+	return strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// allow takes a token from key's bucket. When none is left it returns how
+// long until one is.
+func (l *RateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	perToken := l.window / time.Duration(l.limit)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.limit), b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Middleware returns Gin middleware that rejects requests over the limit with
+// 429 and a Retry-After header. Requests are counted per API key, so it must
+// run after APIKeyAuth.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.limit <= 0 {
+			c.Next()
+			return
+		}
+		if ok, wait := l.allow(limitKey(c)); !ok {
+			err := &RateLimitError{RetryAfter: wait}
+			c.Header("Retry-After", err.RetryAfterHeader())
+			errorJSON(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		c.Next()
+	}
+}
+
+// LoginGate returns Gin middleware that counts a request against the limit
+// only if it logs in to a bank, through session.WithLoginGate; one served
+// from an open session isn't counted. A login over the limit fails with a
+// *RateLimitError for the handler to answer. It must run after APIKeyAuth.
+func (l *RateLimiter) LoginGate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.limit <= 0 {
+			c.Next()
+			return
+		}
+		key := limitKey(c)
+		c.Request = c.Request.WithContext(session.WithLoginGate(c.Request.Context(), func() error {
+			if ok, wait := l.allow(key); !ok {
+				return &RateLimitError{RetryAfter: wait}
+			}
+			return nil
+		}))
+		c.Next()
+	}
+}
+
+// limitKey is what requests are counted under: the API key, or the client
+// ID of a webhook.
+func limitKey(c *gin.Context) string {
+	if k := GetAPIKey(c); k != nil {
+		return k.ID.String()
+	}
+	return GetClientID(c)
+}
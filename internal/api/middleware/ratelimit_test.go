@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/session"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l, err := NewRateLimiter(2, time.Minute)
+	require.NoError(t, err)
+	l.now = func() time.Time { return now }
+
+	repo := &mockAPIKeyRepo{key: &store.APIKey{ID: uuid.New(), ClientID: "c"}}
+	r := gin.New()
+	r.Use(APIKeyAuth(repo), l.Middleware())
+	r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	assert.Equal(t, http.StatusOK, makeRequest(r, "key").Code)
+	assert.Equal(t, http.StatusOK, makeRequest(r, "key").Code)
+
+	w := makeRequest(r, "key")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, http.StatusOK, makeRequest(r, "key").Code, "one token refilled")
+	assert.Equal(t, http.StatusTooManyRequests, makeRequest(r, "key").Code)
+
+	repo.key = &store.APIKey{ID: uuid.New(), ClientID: "c"}
+	assert.Equal(t, http.StatusOK, makeRequest(r, "other").Code, "limits are per key")
+}
+
+func TestRateLimiter_LoginGate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l, err := NewRateLimiter(1, time.Minute)
+	require.NoError(t, err)
+	l.now = func() time.Time { return now }
+
+	// Every session has expired by the next request, so each logs in
+	// unless ?open=1 finds one still open.
+	ms := &banktest.MockScraper{LoginSession: &bank.Session{ID: "s", Code: bank.BankBBVA, ExpiresAt: time.Now().Add(time.Hour)}}
+	mgr := session.NewManager(credsFunc(func() map[string]string { return map[string]string{} }),
+		func(bank.Code) (bank.Scraper, error) { return ms, nil }, slog.New(slog.DiscardHandler))
+
+	repo := &mockAPIKeyRepo{key: &store.APIKey{ID: uuid.New(), ClientID: "c"}}
+	r := gin.New()
+	r.Use(APIKeyAuth(repo), l.LoginGate())
+	r.GET("/test", func(c *gin.Context) {
+		if c.Query("open") == "" {
+			mgr.Invalidate(c.Request.Context(), bank.BankBBVA)
+		}
+		_, err := mgr.GetScraper(c.Request.Context(), bank.BankBBVA)
+		if limited := (*RateLimitError)(nil); errors.As(err, &limited) {
+			c.Header("Retry-After", limited.RetryAfterHeader())
+			c.Status(http.StatusTooManyRequests)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusOK, makeRequest(r, "key").Code)
+	for range 3 {
+		assert.Equal(t, http.StatusOK, makeRequestPath(r, "/test?open=1", "key").Code, "served from the open session")
+	}
+	w := makeRequest(r, "key")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "a second login")
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, 1, ms.LoginCalled)
+}
+
+type credsFunc func() map[string]string
+
+func (f credsFunc) GetCredentials(context.Context, string) (map[string]string, error) {
+	return f(), nil
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	l, err := NewRateLimiter(0, 0)
+	require.NoError(t, err)
+	r := gin.New()
+	r.Use(l.Middleware())
+	r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for range 5 {
+		assert.Equal(t, http.StatusOK, makeRequest(r, "").Code)
+	}
+}
+
+func TestNewRateLimiter_Invalid(t *testing.T) {
+	for _, window := range []time.Duration{0, -time.Minute, 9} {
+		_, err := NewRateLimiter(10, window)
+		assert.ErrorIs(t, err, ErrInvalidRateLimit, "window %s", window)
+	}
+	_, err := NewRateLimiter(10, 10)
+	assert.NoError(t, err)
+}
+
+type auditEntry struct {
+	action, targetType, targetID string
+	success                      bool
+	details                      map[string]any
+}
+
+type mockAuditLogger struct {
+	entries []auditEntry
+}
+
+func (m *mockAuditLogger) Log(_ context.Context, _ *uuid.UUID, action, targetType, targetID, _, _ string, success bool, details map[string]any) {
+	m.entries = append(m.entries, auditEntry{action, targetType, targetID, success, details})
+}
+
+func TestAudit(t *testing.T) {
+	keyID := uuid.New()
+	repo := &mockAPIKeyRepo{key: &store.APIKey{ID: keyID, ClientID: "erp", Scopes: []string{store.APIKeyScopeRead}}}
+	audit := &mockAuditLogger{}
+	r := gin.New()
+	r.Use(APIKeyAuth(repo), Audit(audit, AuditAPISession, "bank", "bank_code"), RequireScope(store.APIKeyScopeScrape))
+	r.GET("/sessions/:bank_code", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := makeRequestPath(r, "/sessions/BBVA", "key")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	require.Len(t, audit.entries, 1)
+	e := audit.entries[0]
+	assert.Equal(t, AuditAPISession, e.action)
+	assert.Equal(t, "bank", e.targetType)
+	assert.Equal(t, "BBVA", e.targetID)
+	assert.False(t, e.success, "rejected requests are recorded as failures")
+	assert.Equal(t, "erp", e.details["client_id"])
+	assert.Equal(t, keyID.String(), e.details["api_key_id"])
+	assert.Equal(t, http.StatusForbidden, e.details["status"])
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderWebhookSignature carries an inbound webhook's signature, as
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<METHOD>.<path>.<body>">".
+// The method and path are signed too, so a signature over an empty body
+// (e.g. a GET for one job) can't be replayed against another request.
+const HeaderWebhookSignature = "X-Bank-Scraper-Signature"
+
+// WebhookTolerance is how far a signature's timestamp may be from the
+// server's clock. Older requests are rejected so a captured one can't be
+// replayed later.
+const WebhookTolerance = 5 * time.Minute
+
+// maxWebhookBody caps the body read to verify a signature.
+const maxWebhookBody = 64 << 10
+
+// ErrInvalidWebhook is returned for malformed webhook configuration.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// Webhook is an external system allowed to call the inbound webhook
+// endpoints, for example a payroll tool refreshing balances before a run.
+// It signs its requests with Secret and acts in Profile.
+type Webhook struct {
+	Name    string
+	Secret  string
+	Profile string
+}
+
+// ParseWebhooks reads webhooks from "name=secret" entries and per-name
+// profiles; names without one act in the default profile.
+func ParseWebhooks(entries []string, profiles map[string]string) (map[string]Webhook, error) {
+	out := make(map[string]Webhook, len(entries))
+	for _, e := range entries {
+		name, secret, ok := strings.Cut(e, "=")
+		name, secret = strings.TrimSpace(name), strings.TrimSpace(secret)
+		if !ok || name == "" || secret == "" {
+			return nil, fmt.Errorf("%w: %q is not name=secret", ErrInvalidWebhook, e)
+		}
+		if len(secret) < 16 {
+			return nil, fmt.Errorf("%w: secret of %q must be at least 16 characters", ErrInvalidWebhook, name)
+		}
+		if _, dup := out[name]; dup {
+			return nil, fmt.Errorf("%w: %q configured twice", ErrInvalidWebhook, name)
+		}
+		out[name] = Webhook{Name: name, Secret: secret, Profile: store.DefaultProfile}
+	}
+	for name, profile := range profiles {
+		w, ok := out[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: profile set for unknown webhook %q", ErrInvalidWebhook, name)
+		}
+		if err := store.ValidateProfile(profile); err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidWebhook, name, err)
+		}
+		w.Profile = profile
+		out[name] = w
+	}
+	return out, nil
+}
+
+// SignWebhook returns the signature header value for a request to path
+// (without the query) with method and body at t, as callers must compute it.
+func SignWebhook(secret string, t time.Time, method, path string, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(webhookMAC(secret, ts, method, path, body))
+}
+
+func webhookMAC(secret, ts, method, path string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + method + "." + path + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// WebhookAuth returns Gin middleware that authenticates the webhook named by
+// the :hook route parameter from its signature over the request, then
+// scopes the request to the webhook's profile. The client ID of the request
+// is "webhook:<name>", which rate limiting and auditing key on.
+func WebhookAuth(hooks map[string]Webhook) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hook, ok := hooks[c.Param("hook")]
+		if !ok {
+			errorJSON(c, http.StatusNotFound, "unknown webhook")
+			return
+		}
+		c.Set(contextKeyClient, "webhook:"+hook.Name)
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody+1))
+		if err != nil || len(body) > maxWebhookBody {
+			errorJSON(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := verifyWebhook(hook.Secret, c.GetHeader(HeaderWebhookSignature),
+			c.Request.Method, c.Request.URL.Path, body, time.Now()); err != nil {
+			errorJSON(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		c.Request = c.Request.WithContext(store.WithProfile(c.Request.Context(), hook.Profile))
+		c.Next()
+	}
+}
+
+func verifyWebhook(secret, header, method, path string, body []byte, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	if ts == "" || sig == "" {
+		return errors.New("missing webhook signature")
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("invalid webhook signature")
+	}
+	if math.Abs(now.Sub(time.Unix(sec, 0)).Seconds()) > WebhookTolerance.Seconds() {
+		return errors.New("webhook signature expired")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, webhookMAC(secret, ts, method, path, body)) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
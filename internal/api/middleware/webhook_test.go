@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWebhooks(t *testing.T) {
+	hooks, err := ParseWebhooks([]string{"payroll=0123456789abcdef", " erp = fedcba9876543210 "},
+		map[string]string{"erp": "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, Webhook{Name: "payroll", Secret: "0123456789abcdef", Profile: store.DefaultProfile}, hooks["payroll"])
+	assert.Equal(t, "acme", hooks["erp"].Profile)
+
+	for _, bad := range [][]string{{"payroll"}, {"payroll=short"}, {"a=0123456789abcdef", "a=0123456789abcdef"}} {
+		_, err := ParseWebhooks(bad, nil)
+		assert.ErrorIs(t, err, ErrInvalidWebhook, bad)
+	}
+	_, err = ParseWebhooks(nil, map[string]string{"nope": "acme"})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+}
+
+func TestWebhookAuth(t *testing.T) {
+	const secret = "0123456789abcdef"
+	auth := WebhookAuth(map[string]Webhook{"payroll": {Name: "payroll", Secret: secret, Profile: "acme"}})
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{
+			"client":  GetClientID(c),
+			"profile": store.ProfileFrom(c.Request.Context()),
+			"body":    string(body),
+		})
+	}
+	r := gin.New()
+	r.POST("/hooks/:hook", auth, echo)
+	r.GET("/hooks/:hook/jobs/:id", auth, echo)
+
+	send := func(method, path, sig, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if sig != "" {
+			req.Header.Set(HeaderWebhookSignature, sig)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"bank_code":"BBVA"}`
+	sign := func(secret string, t time.Time, method, path, body string) string {
+		return SignWebhook(secret, t, method, path, []byte(body))
+	}
+	w := send(http.MethodPost, "/hooks/payroll", sign(secret, time.Now(), http.MethodPost, "/hooks/payroll", body), body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"client":"webhook:payroll","profile":"acme","body":"{\"bank_code\":\"BBVA\"}"}`, w.Body.String())
+
+	w = send(http.MethodGet, "/hooks/payroll/jobs/1?verbose=1", sign(secret, time.Now(), http.MethodGet, "/hooks/payroll/jobs/1", ""), "")
+	require.Equal(t, http.StatusOK, w.Code, "query not signed: %s", w.Body.String())
+
+	tests := []struct {
+		name         string
+		method, path string
+		sig          string
+		body         string
+		status       int
+	}{
+		{"unknown webhook", http.MethodPost, "/hooks/erp", sign(secret, time.Now(), http.MethodPost, "/hooks/erp", body), body, http.StatusNotFound},
+		{"missing signature", http.MethodPost, "/hooks/payroll", "", body, http.StatusUnauthorized},
+		{"wrong secret", http.MethodPost, "/hooks/payroll", sign("fedcba9876543210", time.Now(), http.MethodPost, "/hooks/payroll", body), body, http.StatusUnauthorized},
+		{"other body", http.MethodPost, "/hooks/payroll", sign(secret, time.Now(), http.MethodPost, "/hooks/payroll", `{}`), body, http.StatusUnauthorized},
+		{"expired", http.MethodPost, "/hooks/payroll", sign(secret, time.Now().Add(-WebhookTolerance-time.Minute), http.MethodPost, "/hooks/payroll", body), body, http.StatusUnauthorized},
+		{"other path", http.MethodGet, "/hooks/payroll/jobs/2", sign(secret, time.Now(), http.MethodGet, "/hooks/payroll/jobs/1", ""), "", http.StatusUnauthorized},
+		{"other method", http.MethodPost, "/hooks/payroll", sign(secret, time.Now(), http.MethodGet, "/hooks/payroll", ""), "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, send(tt.method, tt.path, tt.sig, tt.body).Code)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// Digest summarizes the new transactions of one period, per account.
+type Digest struct {
+	Period   string           `json:"period"` // ModeDaily or ModeWeekly
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Accounts []*AccountDigest `json:"accounts"`
+}
+
+// AccountDigest is one account's movement over a digest period. Amounts are
+// in cents, like transaction amounts.
+type AccountDigest struct {
+	Profile      string `json:"profile,omitempty"`
+	BankCode     string `json:"bank_code"`
+	AccountID    string `json:"account_id"`
+	AccountAlias string `json:"account_alias,omitempty"`
+	Transactions int    `json:"transactions"`
+	Credits      int64  `json:"credits"`
+	Debits       int64  `json:"debits"`
+	Net          int64  `json:"net"`
+
+	// Running balance before the period's first and after its last
+	// transaction, when the bank reports one
+	OpeningBalance *int64 `json:"opening_balance,omitempty"`
+	ClosingBalance *int64 `json:"closing_balance,omitempty"`
+
+	Flagged []FlaggedTransaction `json:"flagged,omitempty"` // Unusual transactions of the period
+}
+
+// FlaggedTransaction is a transaction the anomaly detector found unusual.
+type FlaggedTransaction struct {
+	Transaction bank.Transaction `json:"transaction"`
+	Anomalies   []report.Anomaly `json:"anomalies"`
+}
+
+// newDigest starts the digest of the day or week (from Monday) containing
+// now, in now's location.
+func newDigest(mode string, now time.Time) *Digest {
+	y, m, d := now.Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	to := from.AddDate(0, 0, 1)
+	if mode == ModeWeekly {
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		to = from.AddDate(0, 0, 7)
+	}
+	return &Digest{Period: mode, From: from, To: to}
+}
+
+// add counts a TypeTransactionNew event towards its account, keeping it
+// among the flagged ones if it has anomalies.
+func (d *Digest) add(e events.Event, anomalies []report.Anomaly) {
+	tx, ok := e.Data.(bank.Transaction)
+	if !ok {
+		return
+	}
+	var a *AccountDigest
+	for _, x := range d.Accounts {
+		if x.Profile == e.Profile && x.BankCode == e.BankCode && x.AccountID == e.AccountID {
+			a = x
+			break
+		}
+	}
+	if a == nil {
+		a = &AccountDigest{Profile: e.Profile, BankCode: e.BankCode, AccountID: e.AccountID, AccountAlias: e.AccountAlias}
+		d.Accounts = append(d.Accounts, a)
+	}
+
+	signed := tx.Amount
+	if tx.Type == bank.TransactionDebit {
+		a.Debits += tx.Amount
+		signed = -signed
+	} else {
+		a.Credits += tx.Amount
+	}
+	a.Transactions++
+	a.Net += signed
+	if tx.BalanceAfter != nil {
+		if a.Transactions == 1 {
+			opening := *tx.BalanceAfter - signed
+			a.OpeningBalance = &opening
+		}
+		closing := *tx.BalanceAfter
+		a.ClosingBalance = &closing
+	}
+	if len(anomalies) > 0 {
+		a.Flagged = append(a.Flagged, FlaggedTransaction{Transaction: tx, Anomalies: anomalies})
+	}
+}
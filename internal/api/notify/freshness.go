@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/calendar"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// ErrInvalidSLO is returned by ParseFreshnessSLOs for a bad key or duration.
+var ErrInvalidSLO = errors.New("invalid freshness SLO")
+
+// Freshness message types.
+const (
+	TypeStale = "stale" // An account's data got older than its SLO
+	TypeFresh = "fresh" // A stale account was synced again
+)
+
+// minSLOKey is the shortest account number suffix a FreshnessSLOs key may
+// be, as for account aliases.
+const minSLOKey = 4
+
+// FreshnessSLOs is how old each account's stored data may get, keyed by
+// account number, its last four or more digits, or account type
+// ("checking", "savings"). A number key wins over the account's type.
+type FreshnessSLOs map[string]time.Duration
+
+// ParseFreshnessSLOs validates SLOs, e.g. from FRESHNESS_SLOS
+// (4607:2h,savings:24h).
+func ParseFreshnessSLOs(m map[string]time.Duration) (FreshnessSLOs, error) {
+	slos := make(FreshnessSLOs, len(m))
+	for key, d := range m {
+		key = strings.TrimSpace(key)
+		isType := key == store.AccountTypeChecking || key == store.AccountTypeSavings
+		if !isType && len(key) < minSLOKey {
+			return nil, fmt.Errorf("%w: key %q is neither an account type nor %d or more digits", ErrInvalidSLO, key, minSLOKey)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("%w: %s: %s is not positive", ErrInvalidSLO, key, d)
+		}
+		slos[key] = d
+	}
+	return slos, nil
+}
+
+// For returns a's SLO: its number's, else the longest key its number ends
+// with, else its type's.
+func (s FreshnessSLOs) For(a store.Account) (time.Duration, bool) {
+	if d, ok := s[a.AccountNumber]; ok {
+		return d, true
+	}
+	var best string
+	for key := range s {
+		if len(key) > len(best) && strings.HasSuffix(a.AccountNumber, key) {
+			best = key
+		}
+	}
+	if best != "" {
+		return s[best], true
+	}
+	d, ok := s[a.AccountType]
+	return d, ok
+}
+
+// Freshness configures staleness alerts: an account whose data is older
+// than its SLO gets a TypeStale message on every channel, whatever its
+// mode, once per breach, and a TypeFresh one when it is synced again. This
+// is independent of scrape failures: a scheduler that stopped running
+// alerts too.
+type Freshness struct {
+	Accounts store.AccountRepository
+	SLOs     FreshnessSLOs
+	Profiles []string           // Profiles whose accounts are checked
+	Calendar *calendar.Calendar // Weekends and holidays don't count towards age; nil counts every day
+}
+
+// FreshnessAlert is the account a TypeStale or TypeFresh message concerns.
+type FreshnessAlert struct {
+	Profile      string        `json:"profile,omitempty"`
+	BankCode     string        `json:"bank_code"`
+	AccountID    string        `json:"account_id"` // Bank account number
+	AccountAlias string        `json:"account_alias,omitempty"`
+	LastSyncedAt *time.Time    `json:"last_synced_at,omitempty"` // nil when never synced
+	Age          time.Duration `json:"age_ns"`                   // Business time since the last sync
+	SLO          time.Duration `json:"slo_ns"`
+}
+
+// WithFreshness alerts on accounts whose data breaches its SLO, checked
+// every minute while the notifier runs.
+func (n *Notifier) WithFreshness(f Freshness) *Notifier {
+	n.freshness = &f
+	n.stale = map[string]bool{}
+	return n
+}
+
+// checkFreshness sends the alerts of accounts that went stale or fresh
+// since the last check.
+func (n *Notifier) checkFreshness(ctx context.Context) {
+	f := n.freshness
+	if f == nil || len(f.SLOs) == 0 {
+		return
+	}
+	now := n.now()
+	for _, p := range f.Profiles {
+		accounts, err := f.Accounts.List(store.WithProfile(ctx, p), store.AccountFilter{})
+		if err != nil {
+			n.logger.Warn("notify: list accounts", slog.String("profile", p), slog.Any("error", err))
+			continue
+		}
+		for _, a := range accounts {
+			slo, ok := f.SLOs.For(a)
+			if !ok || a.Status == store.AccountStatusInactive {
+				continue
+			}
+			synced := a.CreatedAt
+			if a.LastSyncedAt != nil {
+				synced = *a.LastSyncedAt
+			}
+			age := f.Calendar.BusinessTime(synced, now)
+			key := p + "/" + a.ID.String()
+			stale := age > slo
+			if stale == n.stale[key] {
+				continue
+			}
+			n.stale[key] = stale
+			typ := TypeFresh
+			if stale {
+				typ = TypeStale
+			}
+			alert := &FreshnessAlert{
+				Profile: a.Profile, BankCode: a.BankCode, AccountID: a.AccountNumber, AccountAlias: a.Alias,
+				LastSyncedAt: a.LastSyncedAt, Age: age, SLO: slo,
+			}
+			for _, c := range n.channels {
+				n.send(ctx, c, Message{Type: typ, Freshness: alert})
+			}
+		}
+	}
+}
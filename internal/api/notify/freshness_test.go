@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/calendar"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccountRepo struct {
+	store.AccountRepository
+	accounts []store.Account
+}
+
+func (m *mockAccountRepo) List(ctx context.Context, _ store.AccountFilter) ([]store.Account, error) {
+	var out []store.Account
+	for _, a := range m.accounts {
+		if a.Profile == store.ProfileFrom(ctx) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func TestParseFreshnessSLOs(t *testing.T) {
+	slos, err := ParseFreshnessSLOs(map[string]time.Duration{"4607": 2 * time.Hour, "savings": 24 * time.Hour})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2*time.Hour, must(slos.For(store.Account{AccountNumber: "PE0011000100064607", AccountType: store.AccountTypeSavings})),
+		"number wins over type")
+	assert.Equal(t, 24*time.Hour, must(slos.For(store.Account{AccountNumber: "PE0011000100064615", AccountType: store.AccountTypeSavings})))
+	_, ok := slos.For(store.Account{AccountNumber: "PE0011000100064615", AccountType: store.AccountTypeChecking})
+	assert.False(t, ok)
+
+	_, err = ParseFreshnessSLOs(map[string]time.Duration{"46": time.Hour})
+	assert.ErrorIs(t, err, ErrInvalidSLO)
+	_, err = ParseFreshnessSLOs(map[string]time.Duration{"4607": 0})
+	assert.ErrorIs(t, err, ErrInvalidSLO)
+}
+
+func must(d time.Duration, ok bool) time.Duration {
+	if !ok {
+		return -1
+	}
+	return d
+}
+
+func TestNotifier_Freshness(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	synced := time.Date(2026, 10, 16, 17, 0, 0, 0, calendar.Lima) // Friday
+	repo := &mockAccountRepo{accounts: []store.Account{
+		{ID: uuid.New(), Profile: store.DefaultProfile, BankCode: "BBVA", AccountNumber: "PE0011000100064607", LastSyncedAt: &synced},
+		{ID: uuid.New(), Profile: store.DefaultProfile, BankCode: "BBVA", AccountNumber: "PE0011000100069999", LastSyncedAt: &synced},
+	}}
+	cal, err := calendar.Peru()
+	require.NoError(t, err)
+	now := synced.Add(90 * time.Minute)
+	n := NewNotifier(events.NewHub(0), []Channel{{Name: "finance", URL: srv.URL, Mode: ModeWeekly}}, nil).
+		WithFreshness(Freshness{
+			Accounts: repo,
+			SLOs:     FreshnessSLOs{"4607": 2 * time.Hour},
+			Profiles: []string{store.DefaultProfile},
+			Calendar: cal,
+		})
+	n.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	n.checkFreshness(ctx)
+	assert.Empty(t, rec.types())
+
+	now = synced.Add(3 * time.Hour)
+	n.checkFreshness(ctx)
+	n.checkFreshness(ctx)
+	require.Equal(t, []string{TypeStale}, rec.types(), "once per breach, on digest channels too")
+	alert := rec.msgs[0].Freshness
+	require.NotNil(t, alert)
+	assert.Equal(t, "XXXXXXXXXXXXXX4607", alert.AccountID)
+	assert.Equal(t, 3*time.Hour, alert.Age)
+
+	synced2 := time.Date(2026, 10, 16, 23, 0, 0, 0, calendar.Lima)
+	repo.accounts[0].LastSyncedAt = &synced2
+	now = synced2
+	n.checkFreshness(ctx)
+	assert.Equal(t, []string{TypeStale, TypeFresh}, rec.types())
+
+	// The weekend doesn't age the data: Monday 00:30 is 90 business
+	// minutes after Friday 23:00.
+	now = time.Date(2026, 10, 19, 0, 30, 0, 0, calendar.Lima)
+	n.checkFreshness(ctx)
+	assert.Len(t, rec.types(), 2)
+}
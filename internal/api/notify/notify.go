@@ -0,0 +1,373 @@
+// Package notify forwards newly seen transactions from the event hub to
+// webhook channels, either as they arrive or as a daily or weekly digest
+// per account, and alerts them when an account's stored data gets older
+// than its freshness SLO.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// ErrInvalidChannel is returned by ParseChannels for a bad URL or mode.
+var ErrInvalidChannel = errors.New("invalid notification channel")
+
+// Delivery modes.
+const (
+	ModeInstant = "instant" // One message per new transaction
+	ModeDaily   = "daily"   // One digest per day
+	ModeWeekly  = "weekly"  // One digest per week, starting Monday
+)
+
+// Channel is a webhook notifications are POSTed to as JSON.
+type Channel struct {
+	Name string
+	URL  string
+	Mode string
+}
+
+// ParseChannels validates webhook channels, e.g. from NOTIFY_WEBHOOKS
+// ("name=URL" entries) and NOTIFY_DIGEST (name → mode). Channels without a
+// mode are instant.
+func ParseChannels(webhooks []string, modes map[string]string) ([]Channel, error) {
+	urls := make(map[string]string, len(webhooks))
+	for _, entry := range webhooks {
+		name, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: %q is not name=URL", ErrInvalidChannel, entry)
+		}
+		if _, dup := urls[name]; dup {
+			return nil, fmt.Errorf("%w: channel %q listed twice", ErrInvalidChannel, name)
+		}
+		urls[name] = raw
+	}
+	for name := range modes {
+		if _, ok := urls[name]; !ok {
+			return nil, fmt.Errorf("%w: digest mode for unknown channel %q", ErrInvalidChannel, name)
+		}
+	}
+	channels := make([]Channel, 0, len(urls))
+	for name, raw := range urls {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: %s: %q is not an http(s) URL", ErrInvalidChannel, name, raw)
+		}
+		mode := modes[name]
+		switch mode {
+		case "":
+			mode = ModeInstant
+		case ModeInstant, ModeDaily, ModeWeekly:
+		default:
+			return nil, fmt.Errorf("%w: %s: unknown mode %q (use instant, daily or weekly)", ErrInvalidChannel, name, mode)
+		}
+		channels = append(channels, Channel{Name: name, URL: raw, Mode: mode})
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels, nil
+}
+
+// TypeArrived is the message type of an expected transfer that arrived
+// (see package watch); its Event carries the transaction.
+const TypeArrived = "arrived"
+
+// Message is the body POSTed to a channel.
+type Message struct {
+	Type      string           `json:"type"`                // "transaction", "digest", TypeStale, TypeFresh or TypeArrived
+	Event     *events.Event    `json:"event,omitempty"`     // For "transaction" and TypeArrived
+	Anomalies []report.Anomaly `json:"anomalies,omitempty"` // Why the transaction looks unusual, if it does
+	Freshness *FreshnessAlert  `json:"freshness,omitempty"` // For TypeStale and TypeFresh
+	*Digest
+}
+
+// Notifier delivers the hub's new-transaction events to its channels.
+type Notifier struct {
+	hub      *events.Hub
+	channels []Channel
+	client   *http.Client
+	logger   *slog.Logger
+	now      func() time.Time
+	detector *report.AnomalyDetector // nil flags nothing
+	history  *AnomalyHistory         // Seeds detector before delivery; nil starts it empty
+	fullIDs  bool                    // Send account numbers unmasked (WithFullAccountIDs)
+
+	freshness *Freshness      // nil alerts on no staleness
+	stale     map[string]bool // Profile/account ID → alerted stale; only the delivery goroutine touches it
+
+	mu      sync.Mutex
+	pending map[string]*Digest // Channel name → digest being collected
+}
+
+// NewNotifier creates a Notifier for channels.
+func NewNotifier(hub *events.Hub, channels []Channel, logger *slog.Logger) *Notifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Notifier{
+		hub:      hub,
+		channels: channels,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger,
+		now:      time.Now,
+		pending:  make(map[string]*Digest),
+	}
+}
+
+// WithAnomalyDetection flags unusual transactions (see report.AnomalyDetector)
+// in instant messages and digests.
+func (n *Notifier) WithAnomalyDetection(d *report.AnomalyDetector) *Notifier {
+	n.detector = d
+	return n
+}
+
+// AnomalyHistory is where anomaly detection learns what usual looks like
+// before the first new transaction, so a restart doesn't forget it.
+type AnomalyHistory struct {
+	Accounts     store.AccountRepository
+	Transactions store.TransactionRepository
+	Profiles     []string // Profiles whose accounts are learned
+}
+
+// WithAnomalyHistory seeds anomaly detection (see WithAnomalyDetection) with
+// the stored transactions of h's accounts when delivery starts.
+func (n *Notifier) WithAnomalyHistory(h AnomalyHistory) *Notifier {
+	n.history = &h
+	return n
+}
+
+// WithFullAccountIDs sends account numbers in full. By default messages
+// carry them masked to their last 4 digits (bank.MaskAccountID), since
+// webhooks hand them to systems outside the store.
+func (n *Notifier) WithFullAccountIDs(full bool) *Notifier {
+	n.fullIDs = full
+	return n
+}
+
+// Run delivers events until ctx is cancelled or the hub closes, then sends
+// whatever digests are pending. A subscription the hub dropped for falling
+// behind is resumed from the last event handled.
+func (n *Notifier) Run(ctx context.Context) {
+	<-n.Start(ctx)
+}
+
+// Start subscribes to the hub and delivers its events in the background, as
+// Run does. Unlike go n.Run(ctx), every event published after Start returns
+// is delivered. The returned channel is closed when delivery has ended.
+func (n *Notifier) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	if len(n.channels) == 0 {
+		close(done)
+		return done
+	}
+	replay, ch, cancel := n.hub.Subscribe(0)
+	go func() {
+		defer close(done)
+		n.deliver(ctx, replay, ch, cancel)
+	}()
+	return done
+}
+
+// deliver handles events from a first subscription and the ones resuming it.
+func (n *Notifier) deliver(ctx context.Context, replay []events.Event, ch <-chan events.Event, cancel func()) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	defer n.flush(context.WithoutCancel(ctx), true)
+	n.seedAnomalies(ctx)
+
+	var lastID uint64
+	for {
+		for _, e := range replay {
+			n.handle(ctx, e)
+			lastID = e.ID
+		}
+	live:
+		for {
+			select {
+			case <-ctx.Done():
+				cancel()
+				return
+			case <-ticker.C:
+				n.flush(ctx, false)
+				n.checkFreshness(ctx)
+			case e, ok := <-ch:
+				if !ok {
+					break live
+				}
+				n.handle(ctx, e)
+				lastID = e.ID
+			}
+		}
+		cancel()
+		closed := n.hub.Closed()
+		// A subscription dropped just before the hub closed still has its
+		// missed events in the history.
+		replay, ch, cancel = n.hub.Subscribe(lastID)
+		if closed {
+			cancel()
+			for _, e := range replay {
+				n.handle(ctx, e)
+			}
+			return
+		}
+	}
+}
+
+// seedAnomalies teaches the detector the stored transactions of the
+// history's accounts. An account that fails to load starts empty.
+func (n *Notifier) seedAnomalies(ctx context.Context) {
+	h := n.history
+	if h == nil || n.detector == nil {
+		return
+	}
+	for _, p := range h.Profiles {
+		pctx := store.WithProfile(ctx, p)
+		accounts, err := h.Accounts.List(pctx, store.AccountFilter{})
+		if err != nil {
+			n.logger.Warn("notify: list accounts", slog.String("profile", p), slog.Any("error", err))
+			continue
+		}
+		for _, a := range accounts {
+			txns, err := h.Transactions.ListByAccount(pctx, a.ID)
+			if err != nil {
+				n.logger.Warn("notify: load anomaly history", slog.String("account", a.ID.String()), slog.Any("error", err))
+				continue
+			}
+			key := anomalyKey(a.Profile, a.BankCode, a.AccountNumber)
+			for _, t := range txns {
+				n.detector.Learn(key, t.BankTransaction())
+			}
+		}
+	}
+}
+
+// anomalyKey names an account to the anomaly detector.
+func anomalyKey(profile, bankCode, accountNumber string) string {
+	return profile + "/" + bankCode + "/" + accountNumber
+}
+
+// handle sends e to instant channels and adds it to the digests of the
+// others. Only new transactions are notified.
+func (n *Notifier) handle(ctx context.Context, e events.Event) {
+	if e.Type != events.TypeTransactionNew {
+		return
+	}
+	var anomalies []report.Anomaly
+	if tx, ok := e.Data.(bank.Transaction); ok && n.detector != nil {
+		anomalies = n.detector.Observe(anomalyKey(e.Profile, e.BankCode, e.AccountID), tx)
+	}
+	for _, c := range n.channels {
+		if c.Mode == ModeInstant {
+			n.send(ctx, c, Message{Type: "transaction", Event: &e, Anomalies: anomalies})
+			continue
+		}
+		now := n.now()
+		n.mu.Lock()
+		d, due := n.pending[c.Name], (*Digest)(nil)
+		if d != nil && !now.Before(d.To) {
+			due, d = d, nil // Its period ended before the ticker noticed
+		}
+		if d == nil {
+			d = newDigest(c.Mode, now)
+			n.pending[c.Name] = d
+		}
+		d.add(e, anomalies)
+		n.mu.Unlock()
+		if due != nil {
+			n.send(ctx, c, Message{Type: "digest", Digest: due})
+		}
+	}
+}
+
+// Broadcast sends m to every channel now, whatever its mode, for messages
+// that don't come from the hub. A notifier used only this way needs no hub.
+func (n *Notifier) Broadcast(ctx context.Context, m Message) {
+	for _, c := range n.channels {
+		n.send(ctx, c, m)
+	}
+}
+
+// flush sends the digests whose period has ended, or all of them if force
+// is set. Empty periods send nothing.
+func (n *Notifier) flush(ctx context.Context, force bool) {
+	now := n.now()
+	for _, c := range n.channels {
+		n.mu.Lock()
+		d, ok := n.pending[c.Name]
+		if ok && (force || !now.Before(d.To)) {
+			delete(n.pending, c.Name)
+		} else {
+			d = nil
+		}
+		n.mu.Unlock()
+		if d != nil {
+			n.send(ctx, c, Message{Type: "digest", Digest: d})
+		}
+	}
+}
+
+// send POSTs m to c, logging failures: a notification is best effort and
+// never holds up the others.
+func (n *Notifier) send(ctx context.Context, c Channel, m Message) {
+	if !n.fullIDs {
+		m = maskAccounts(m)
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		n.logger.Error("notify: marshal", slog.String("channel", c.Name), slog.Any("error", err))
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		n.logger.Error("notify: request", slog.String("channel", c.Name), slog.Any("error", err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.logger.Warn("notify: send", slog.String("channel", c.Name), slog.Any("error", err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		n.logger.Warn("notify: rejected", slog.String("channel", c.Name), slog.Int("status", resp.StatusCode))
+	}
+}
+
+// maskAccounts returns m with its account numbers masked, leaving the
+// event, digest and alert it shares with the caller untouched.
+func maskAccounts(m Message) Message {
+	if m.Event != nil {
+		e := *m.Event
+		e.AccountID = bank.MaskAccountID(e.AccountID)
+		m.Event = &e
+	}
+	if m.Freshness != nil {
+		f := *m.Freshness
+		f.AccountID = bank.MaskAccountID(f.AccountID)
+		m.Freshness = &f
+	}
+	if m.Digest != nil {
+		d := *m.Digest
+		d.Accounts = make([]*AccountDigest, len(m.Digest.Accounts))
+		for i, a := range m.Digest.Accounts {
+			masked := *a
+			masked.AccountID = bank.MaskAccountID(a.AccountID)
+			d.Accounts[i] = &masked
+		}
+		m.Digest = &d
+	}
+	return m
+}
@@ -0,0 +1,249 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChannels(t *testing.T) {
+	channels, err := ParseChannels(
+		[]string{"ops=https://hooks.example/ops", "finance=https://hooks.example/fin"},
+		map[string]string{"finance": "weekly"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []Channel{
+		{Name: "finance", URL: "https://hooks.example/fin", Mode: ModeWeekly},
+		{Name: "ops", URL: "https://hooks.example/ops", Mode: ModeInstant},
+	}, channels)
+
+	_, err = ParseChannels([]string{"ops=hooks.example"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	_, err = ParseChannels([]string{"https://hooks.example"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	_, err = ParseChannels([]string{"ops=https://hooks.example"}, map[string]string{"ops": "hourly"})
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	_, err = ParseChannels(nil, map[string]string{"ops": "daily"})
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+}
+
+func TestNewDigest_Period(t *testing.T) {
+	wed := time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC)
+
+	d := newDigest(ModeDaily, wed)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), d.From)
+	assert.Equal(t, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), d.To)
+
+	w := newDigest(ModeWeekly, wed)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), w.From, "Monday")
+	assert.Equal(t, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), w.To)
+}
+
+func txEvent(account string, amount int64, typ bank.TransactionType, balance int64) events.Event {
+	return events.Event{
+		Type:      events.TypeTransactionNew,
+		BankCode:  "BBVA",
+		AccountID: account,
+		Data:      bank.Transaction{Amount: amount, Type: typ, BalanceAfter: &balance},
+	}
+}
+
+func TestDigest_Add(t *testing.T) {
+	d := newDigest(ModeDaily, time.Now())
+	d.add(txEvent("0011", 10_000, bank.TransactionCredit, 60_000), nil)
+	d.add(txEvent("0011", 2_500, bank.TransactionDebit, 57_500), []report.Anomaly{{Kind: report.AnomalyNewPayee}})
+	d.add(txEvent("0022", 100, bank.TransactionCredit, 100), nil)
+	d.add(events.Event{Type: events.TypeTransactionNew, AccountID: "0011"}, nil) // no transaction
+
+	require.Len(t, d.Accounts, 2)
+	a := d.Accounts[0]
+	assert.Equal(t, 2, a.Transactions)
+	assert.Equal(t, int64(10_000), a.Credits)
+	assert.Equal(t, int64(2_500), a.Debits)
+	assert.Equal(t, int64(7_500), a.Net)
+	assert.Equal(t, int64(50_000), *a.OpeningBalance)
+	assert.Equal(t, int64(57_500), *a.ClosingBalance)
+	require.Len(t, a.Flagged, 1)
+	assert.Equal(t, int64(2_500), a.Flagged[0].Transaction.Amount)
+}
+
+// recorder collects the messages POSTed to it.
+type recorder struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var m Message
+	_ = json.NewDecoder(req.Body).Decode(&m)
+	r.mu.Lock()
+	r.msgs = append(r.msgs, m)
+	r.mu.Unlock()
+}
+
+func (r *recorder) types() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []string
+	for _, m := range r.msgs {
+		out = append(out, m.Type)
+	}
+	return out
+}
+
+func TestNotifier_InstantAndDigest(t *testing.T) {
+	instant, digest := &recorder{}, &recorder{}
+	instantSrv, digestSrv := httptest.NewServer(instant), httptest.NewServer(digest)
+	defer instantSrv.Close()
+	defer digestSrv.Close()
+
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	n := NewNotifier(events.NewHub(0), []Channel{
+		{Name: "ops", URL: instantSrv.URL, Mode: ModeInstant},
+		{Name: "finance", URL: digestSrv.URL, Mode: ModeDaily},
+	}, nil)
+	n.now = func() time.Time { return now }
+	n.WithAnomalyDetection(report.NewAnomalyDetector())
+	ctx := context.Background()
+
+	n.handle(ctx, events.Event{Type: events.TypeScrapeCompleted})
+	n.handle(ctx, txEvent("0011", 10_000, bank.TransactionCredit, 60_000))
+	n.handle(ctx, txEvent("0011", 2_500, bank.TransactionDebit, 57_500))
+	assert.Equal(t, []string{"transaction", "transaction"}, instant.types())
+
+	n.flush(ctx, false)
+	assert.Empty(t, digest.types(), "day not over")
+
+	now = now.Add(15 * time.Hour)
+	n.flush(ctx, false)
+	require.Equal(t, []string{"digest"}, digest.types())
+	d := digest.msgs[0].Digest
+	require.NotNil(t, d)
+	require.Len(t, d.Accounts, 1)
+	assert.Equal(t, 2, d.Accounts[0].Transactions)
+	assert.Equal(t, int64(7_500), d.Accounts[0].Net)
+
+	n.flush(ctx, false)
+	assert.Len(t, digest.types(), 1, "empty periods send nothing")
+}
+
+func TestNotifier_Broadcast(t *testing.T) {
+	instant, digest := &recorder{}, &recorder{}
+	instantSrv, digestSrv := httptest.NewServer(instant), httptest.NewServer(digest)
+	defer instantSrv.Close()
+	defer digestSrv.Close()
+	n := NewNotifier(nil, []Channel{
+		{Name: "ops", URL: instantSrv.URL, Mode: ModeInstant},
+		{Name: "finance", URL: digestSrv.URL, Mode: ModeWeekly},
+	}, nil)
+
+	n.Broadcast(context.Background(), Message{Type: TypeArrived, Event: &events.Event{AccountID: "0011"}})
+	assert.Equal(t, []string{TypeArrived}, instant.types())
+	assert.Equal(t, []string{TypeArrived}, digest.types(), "whatever the mode")
+}
+
+func TestNotifier_MasksAccounts(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	channels := []Channel{{Name: "ops", URL: srv.URL, Mode: ModeInstant}}
+	e := &events.Event{AccountID: "0011-0130-0100012345"}
+
+	NewNotifier(nil, channels, nil).Broadcast(context.Background(), Message{Type: TypeArrived, Event: e})
+	NewNotifier(nil, channels, nil).WithFullAccountIDs(true).
+		Broadcast(context.Background(), Message{Type: TypeArrived, Event: e})
+
+	require.Len(t, rec.msgs, 2)
+	assert.Equal(t, "XXXXXXXXXXXXXXXX2345", rec.msgs[0].Event.AccountID)
+	assert.Equal(t, "0011-0130-0100012345", rec.msgs[1].Event.AccountID)
+	assert.Equal(t, "0011-0130-0100012345", e.AccountID, "caller's event untouched")
+}
+
+func TestNotifier_FlagsAnomalies(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	n := NewNotifier(events.NewHub(0), []Channel{{Name: "ops", URL: srv.URL, Mode: ModeInstant}}, nil).
+		WithAnomalyDetection(report.NewAnomalyDetector())
+	ctx := context.Background()
+
+	for range 20 {
+		n.handle(ctx, txEvent("0011", 10_000, bank.TransactionDebit, 0))
+	}
+	e := txEvent("0011", 10_000, bank.TransactionDebit, 0)
+	e.Data = bank.Transaction{Description: "RETIRO CAJERO", Amount: 10_000, Type: bank.TransactionDebit}
+	n.handle(ctx, e)
+
+	require.Len(t, rec.msgs, 21)
+	assert.Empty(t, rec.msgs[19].Anomalies)
+	require.Len(t, rec.msgs[20].Anomalies, 1)
+	assert.Equal(t, report.AnomalyNewPayee, rec.msgs[20].Anomalies[0].Kind)
+}
+
+type mockTxRepo struct {
+	store.TransactionRepository
+	txns map[uuid.UUID][]store.Transaction
+}
+
+func (m *mockTxRepo) ListByAccount(_ context.Context, id uuid.UUID) ([]store.Transaction, error) {
+	return m.txns[id], nil
+}
+
+func TestNotifier_SeedsAnomalyHistory(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	acct := store.Account{ID: uuid.New(), Profile: "acme", BankCode: "BBVA", AccountNumber: "0011"}
+	var stored []store.Transaction
+	for range 20 {
+		stored = append(stored, store.Transaction{Amount: 10_000, Type: string(bank.TransactionDebit)})
+	}
+	n := NewNotifier(events.NewHub(0), []Channel{{Name: "ops", URL: srv.URL, Mode: ModeInstant}}, nil).
+		WithAnomalyDetection(report.NewAnomalyDetector()).
+		WithAnomalyHistory(AnomalyHistory{
+			Accounts:     &mockAccountRepo{accounts: []store.Account{acct}},
+			Transactions: &mockTxRepo{txns: map[uuid.UUID][]store.Transaction{acct.ID: stored}},
+			Profiles:     []string{"acme"},
+		})
+	ctx := context.Background()
+	n.seedAnomalies(ctx)
+
+	e := txEvent("0011", 10_000, bank.TransactionDebit, 0)
+	e.Profile = "acme"
+	e.Data = bank.Transaction{Description: "RETIRO CAJERO", Amount: 10_000, Type: bank.TransactionDebit}
+	n.handle(ctx, e)
+
+	require.Len(t, rec.msgs, 1)
+	require.Len(t, rec.msgs[0].Anomalies, 1, "flagged against the stored history")
+	assert.Equal(t, report.AnomalyNewPayee, rec.msgs[0].Anomalies[0].Kind)
+}
+
+func TestNotifier_StartDeliversEverythingBeforeClose(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	hub := events.NewHub(0)
+	done := NewNotifier(hub, []Channel{{Name: "ops", URL: srv.URL, Mode: ModeInstant}}, nil).Start(context.Background())
+
+	// Published faster than they're sent, so the subscription falls behind
+	// and is dropped; the hub closes before the notifier resumes it.
+	for range 100 {
+		hub.Publish(txEvent("0011", 100, bank.TransactionCredit, 0))
+	}
+	hub.Close()
+	<-done
+
+	assert.Len(t, rec.types(), 100)
+}
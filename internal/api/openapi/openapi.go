@@ -0,0 +1,278 @@
+// Package openapi builds an OpenAPI 3 document from a list of operations.
+// Request and response schemas are derived from the Go types the handlers
+// serialize, so the document can't drift from the JSON actually sent.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the OpenAPI specification version of generated documents.
+const Version = "3.0.3"
+
+// Param describes a query or path parameter. Path parameters not listed on
+// an Operation are documented as required strings.
+type Param struct {
+	Name        string
+	In          string // "query", "path" or "header"
+	Description string
+	Type        string // JSON Schema type, default "string"
+	Format      string // e.g. "date", "uuid"
+	Required    bool
+}
+
+// Operation describes one endpoint.
+type Operation struct {
+	Method      string
+	Path        string // gin syntax, e.g. /accounts/:account_id
+	ID          string
+	Summary     string
+	Description string
+	Tag         string
+	Scope       string // API key scope required; empty for public endpoints
+	Params      []Param
+	Body        any   // value of the JSON request body type; nil for none
+	Response    any   // value of the success response body type; nil for no body
+	Status      int   // success status, default 200
+	Stream      bool  // response is text/event-stream of Response values
+	Errors      []int // documented error statuses
+}
+
+// Info is the document's metadata.
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// Build returns the OpenAPI document for ops, ready to be marshaled to JSON.
+func Build(info Info, ops []Operation) map[string]any {
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type":     "object",
+			"required": []string{"status", "message"},
+			"properties": map[string]any{
+				"status":  map[string]any{"type": "string", "example": "error"},
+				"message": map[string]any{"type": "string"},
+			},
+		},
+	}
+
+	paths := map[string]any{}
+	for _, op := range ops {
+		path := oasPath(op.Path)
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = buildOperation(op, schemas)
+	}
+
+	infoDoc := map[string]any{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		infoDoc["description"] = info.Description
+	}
+	return map[string]any{
+		"openapi": Version,
+		"info":    infoDoc,
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"ApiKeyAuth": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+// oasPath converts gin path parameters (:name) to OpenAPI ({name}).
+func oasPath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		if name, ok := strings.CutPrefix(s, ":"); ok {
+			segs[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+func buildOperation(op Operation, schemas map[string]any) map[string]any {
+	out := map[string]any{"operationId": op.ID, "summary": op.Summary}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+	desc := op.Description
+	if op.Scope != "" {
+		desc = strings.TrimSpace(desc + "\n\nRequires an API key with the `" + op.Scope + "` scope.")
+		out["security"] = []map[string][]string{{"ApiKeyAuth": {}}}
+	} else {
+		out["security"] = []map[string][]string{}
+	}
+	if desc != "" {
+		out["description"] = desc
+	}
+
+	if params := buildParams(op); len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	if op.Body != nil {
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schemaFor(reflect.TypeOf(op.Body), schemas)},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok := map[string]any{"description": http.StatusText(status)}
+	if op.Response != nil {
+		media := "application/json"
+		if op.Stream {
+			media = "text/event-stream"
+		}
+		ok["content"] = map[string]any{
+			media: map[string]any{"schema": schemaFor(reflect.TypeOf(op.Response), schemas)},
+		}
+	}
+	responses := map[string]any{strconv.Itoa(status): ok}
+	for _, status := range op.Errors {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": ref("Error")},
+			},
+		}
+	}
+	out["responses"] = responses
+	return out
+}
+
+func buildParams(op Operation) []map[string]any {
+	declared := map[string]Param{}
+	for _, p := range op.Params {
+		declared[p.In+":"+p.Name] = p
+	}
+
+	var params []Param
+	for _, s := range strings.Split(op.Path, "/") {
+		if name, ok := strings.CutPrefix(s, ":"); ok {
+			p, found := declared["path:"+name]
+			if !found {
+				p = Param{Name: name, In: "path"}
+			}
+			p.Required = true
+			params = append(params, p)
+		}
+	}
+	for _, p := range op.Params {
+		if p.In != "path" {
+			params = append(params, p)
+		}
+	}
+
+	out := make([]map[string]any, 0, len(params))
+	for _, p := range params {
+		typ := p.Type
+		if typ == "" {
+			typ = "string"
+		}
+		schema := map[string]any{"type": typ}
+		if p.Format != "" {
+			schema["format"] = p.Format
+		}
+		m := map[string]any{"name": p.Name, "in": p.In, "required": p.Required, "schema": schema}
+		if p.Description != "" {
+			m["description"] = p.Description
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the JSON schema of t. Named structs are added to
+// schemas and referenced; anonymous structs are inlined.
+func schemaFor(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := schemaFor(t.Elem(), schemas)
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]any{"allOf": []any{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = map[string]any{} // placeholder for recursive types
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return ref(t.Name())
+	default: // interfaces: any JSON value
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = schemaFor(f.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	return s
+}
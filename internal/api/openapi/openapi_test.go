@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name    string            `json:"name"`
+	Amount  int64             `json:"amount"`
+	Note    *string           `json:"note,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	At      time.Time         `json:"at"`
+	Next    *item             `json:"next,omitempty"`
+	private string
+	Skipped string `json:"-"`
+}
+
+type list struct {
+	Items []item `json:"items"`
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build(Info{Title: "t", Version: "1"}, []Operation{
+		{
+			Method: http.MethodGet, Path: "/things/:id", ID: "getThing", Scope: "read",
+			Params:   []Param{{Name: "page", In: "query", Type: "integer"}},
+			Response: list{},
+			Errors:   []int{http.StatusNotFound},
+		},
+		{Method: http.MethodGet, Path: "/health", ID: "health"},
+	})
+
+	paths := doc["paths"].(map[string]any)
+	op := paths["/things/{id}"].(map[string]any)["get"].(map[string]any)
+	assert.Contains(t, op["description"], "`read` scope")
+
+	params := op["parameters"].([]map[string]any)
+	require.Len(t, params, 2)
+	assert.Equal(t, "id", params[0]["name"])
+	assert.Equal(t, true, params[0]["required"])
+	assert.Equal(t, "page", params[1]["name"])
+
+	responses := op["responses"].(map[string]any)
+	assert.Contains(t, responses, "404")
+
+	public := paths["/health"].(map[string]any)["get"].(map[string]any)
+	assert.Empty(t, public["security"])
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	s := schemas["item"].(map[string]any)
+	assert.Equal(t, []string{"amount", "at", "name"}, s["required"])
+	props := s["properties"].(map[string]any)
+	assert.NotContains(t, props, "private")
+	assert.NotContains(t, props, "Skipped")
+	assert.Equal(t, map[string]any{"type": "integer", "format": "int64"}, props["amount"])
+	assert.Equal(t, map[string]any{"type": "string", "nullable": true}, props["note"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["at"])
+	assert.Equal(t, "#/components/schemas/item", props["next"].(map[string]any)["allOf"].([]any)[0].(map[string]any)["$ref"])
+}
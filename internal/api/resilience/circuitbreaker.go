@@ -2,11 +2,14 @@ package resilience
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/aynifx/bank-scraper/internal/api/session"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
 	"github.com/sony/gobreaker"
 )
 
@@ -24,45 +27,100 @@ func DefaultBreakerConfig() BreakerConfig {
 	}
 }
 
-// BreakerRegistry manages per-bank circuit breakers.
+// BreakerRegistry manages per-bank circuit breakers, one set per profile:
+// a profile whose credential the bank rejects doesn't lock the others out.
 type BreakerRegistry struct {
 	mu       sync.Mutex
-	breakers map[bank.Code]*gobreaker.CircuitBreaker
+	breakers map[breakerKey]*Breaker
 	cfg      BreakerConfig
 }
 
+type breakerKey struct {
+	profile string
+	bank    bank.Code
+}
+
 // NewBreakerRegistry creates a registry with the given configuration.
 func NewBreakerRegistry(cfg BreakerConfig) *BreakerRegistry {
 	return &BreakerRegistry{
-		breakers: make(map[bank.Code]*gobreaker.CircuitBreaker),
+		breakers: make(map[breakerKey]*Breaker),
 		cfg:      cfg,
 	}
 }
 
-// Get returns the circuit breaker for the given bank, creating it if needed.
-func (r *BreakerRegistry) Get(bankCode bank.Code) *gobreaker.CircuitBreaker {
+// Get returns the circuit breaker for the given bank in the context's
+// profile (see store.WithProfile), creating it if needed.
+func (r *BreakerRegistry) Get(ctx context.Context, bankCode bank.Code) *Breaker {
+	key := breakerKey{profile: store.ProfileFrom(ctx), bank: bankCode}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if cb, ok := r.breakers[bankCode]; ok {
-		return cb
+	if b, ok := r.breakers[key]; ok {
+		return b
 	}
 
-	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
-		Name:    fmt.Sprintf("bank-%s", bankCode),
+	b := &Breaker{}
+	b.cb = gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
+		Name:    fmt.Sprintf("bank-%s-%s", key.profile, bankCode),
 		Timeout: r.cfg.ResetTimeout,
+		// A rejected credential opens the circuit at once: logging in again
+		// with it only brings the bank user closer to being locked.
 		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			return counts.ConsecutiveFailures >= r.cfg.MaxFailures
+			return b.credentialFailure || counts.ConsecutiveFailures >= r.cfg.MaxFailures
 		},
 	})
-	r.breakers[bankCode] = cb
-	return cb
+	r.breakers[key] = b
+	return b
+}
+
+// Breaker is the circuit breaker of one bank in one profile.
+type Breaker struct {
+	cb *gobreaker.TwoStepCircuitBreaker
+
+	// mu is held while a result is recorded, so ReadyToTrip, which runs
+	// while it is, sees whether that same result was a credential failure.
+	mu                sync.Mutex
+	credentialFailure bool
+}
+
+// Execute runs req if the circuit is closed or half-open and records its
+// result, as gobreaker.CircuitBreaker.Execute does. When the circuit is
+// open it returns gobreaker.ErrOpenState without running req.
+func (b *Breaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	done, err := b.cb.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			b.record(done, errors.New("panic"))
+			panic(e)
+		}
+	}()
+
+	result, err := req()
+	b.record(done, err)
+	return result, err
+}
+
+// record reports err, the result of a request Allow let through.
+func (b *Breaker) record(done func(success bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.credentialFailure = err != nil && bank.Classify(err) == bank.ClassCredential
+	// A read-only caller finding no session says nothing about the bank.
+	done(err == nil || errors.Is(err, session.ErrLoginNotAllowed))
+}
+
+// State returns the current state of the circuit.
+func (b *Breaker) State() gobreaker.State {
+	return b.cb.State()
 }
 
 // ScraperProvider matches the handler.ScraperProvider interface.
 type ScraperProvider interface {
 	GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error)
-	Invalidate(bankCode bank.Code)
+	Invalidate(ctx context.Context, bankCode bank.Code)
 }
 
 // ResilientProvider wraps a ScraperProvider with retry and circuit breaker.
@@ -87,13 +145,13 @@ func NewResilientProvider(inner ScraperProvider, retryCfg Config, breakers *Brea
 // Retries transient errors (ErrBankUnavailable, ErrTimeout) with exponential backoff.
 // On ErrSessionExpired, invalidates the session and retries with a fresh login.
 func (r *ResilientProvider) GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error) {
-	cb := r.breakers.Get(bankCode)
+	cb := r.breakers.Get(ctx, bankCode)
 
 	result, err := cb.Execute(func() (interface{}, error) {
 		scraper, err := Retry(ctx, r.retryCfg, func() (bank.Scraper, error) {
 			s, err := r.inner.GetScraper(ctx, bankCode)
 			if err != nil && IsRetryable(err) {
-				r.inner.Invalidate(bankCode)
+				r.inner.Invalidate(ctx, bankCode)
 			}
 			return s, err
 		})
@@ -106,6 +164,6 @@ func (r *ResilientProvider) GetScraper(ctx context.Context, bankCode bank.Code)
 }
 
 // Invalidate passes through to the inner provider.
-func (r *ResilientProvider) Invalidate(bankCode bank.Code) {
-	r.inner.Invalidate(bankCode)
+func (r *ResilientProvider) Invalidate(ctx context.Context, bankCode bank.Code) {
+	r.inner.Invalidate(ctx, bankCode)
 }
@@ -3,11 +3,13 @@ package resilience
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/sony/gobreaker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,7 +35,7 @@ func (m *mockScraperProvider) GetScraper(ctx context.Context, code bank.Code) (b
 	return m.scraper, nil
 }
 
-func (m *mockScraperProvider) Invalidate(_ bank.Code) {
+func (m *mockScraperProvider) Invalidate(_ context.Context, _ bank.Code) {
 	m.invCalls++
 }
 
@@ -135,9 +137,9 @@ func TestCircuitBreakerRegistry_GetOrCreate(t *testing.T) {
 		ResetTimeout: time.Minute,
 	})
 
-	cb1 := reg.Get(bank.BankBBVA)
-	cb2 := reg.Get(bank.BankBBVA)
-	cb3 := reg.Get(bank.BankInterbank)
+	cb1 := reg.Get(context.Background(), bank.BankBBVA)
+	cb2 := reg.Get(context.Background(), bank.BankBBVA)
+	cb3 := reg.Get(context.Background(), bank.BankInterbank)
 
 	assert.Same(t, cb1, cb2, "same bank should return same breaker")
 	assert.NotSame(t, cb1, cb3, "different banks should have different breakers")
@@ -149,7 +151,7 @@ func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
 		ResetTimeout: time.Minute,
 	})
 
-	cb := reg.Get(bank.BankBBVA)
+	cb := reg.Get(context.Background(), bank.BankBBVA)
 
 	// Fail 3 times to trip the breaker
 	for i := 0; i < 3; i++ {
@@ -168,6 +170,45 @@ func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
 	assert.Contains(t, err.Error(), "circuit breaker is open")
 }
 
+func TestCircuitBreaker_OpensOnCredentialFailure(t *testing.T) {
+	cb := NewBreakerRegistry(BreakerConfig{MaxFailures: 5, ResetTimeout: time.Minute}).Get(context.Background(), bank.BankBBVA)
+
+	_, err := cb.Execute(func() (interface{}, error) {
+		return nil, &bank.ScraperError{Cause: bank.ErrInvalidCredentials}
+	})
+	require.Error(t, err)
+
+	_, err = cb.Execute(func() (interface{}, error) {
+		t.Fatal("should not log in again with a rejected credential")
+		return nil, nil
+	})
+	assert.Contains(t, err.Error(), "circuit breaker is open")
+}
+
+func TestCircuitBreaker_CredentialFailureAmongConcurrentSuccesses(t *testing.T) {
+	for range 50 {
+		cb := NewBreakerRegistry(BreakerConfig{MaxFailures: 5, ResetTimeout: time.Minute}).Get(context.Background(), bank.BankBBVA)
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = cb.Execute(func() (interface{}, error) {
+					<-start
+					if i == 0 {
+						return nil, &bank.ScraperError{Cause: bank.ErrInvalidCredentials}
+					}
+					return nil, nil
+				})
+			}()
+		}
+		close(start)
+		wg.Wait()
+		require.Equal(t, gobreaker.StateOpen, cb.State(), "a success recorded alongside must not hide the rejected credential")
+	}
+}
+
 // --- Integration: ResilientScraperProvider ---
 
 func TestResilientProvider_GetScraper_Success(t *testing.T) {
@@ -227,6 +268,6 @@ func TestResilientProvider_Invalidate_PassesThrough(t *testing.T) {
 	inner := &mockScraperProvider{}
 	rp := NewResilientProvider(inner, DefaultConfig(), NewBreakerRegistry(DefaultBreakerConfig()))
 
-	rp.Invalidate(bank.BankBBVA)
+	rp.Invalidate(context.Background(), bank.BankBBVA)
 	assert.Equal(t, 1, inner.invCalls)
 }
@@ -3,7 +3,6 @@ package resilience
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -26,21 +25,10 @@ func DefaultConfig() Config {
 	}
 }
 
-// retryableErrors are transient — worth retrying.
-var retryableErrors = []error{
-	bank.ErrBankUnavailable,
-	bank.ErrTimeout,
-	bank.ErrSessionExpired,
-}
-
-// IsRetryable returns true if the error is transient and worth retrying.
+// IsRetryable returns true if the error is transient and worth retrying,
+// per bank.Classify.
 func IsRetryable(err error) bool {
-	for _, re := range retryableErrors {
-		if errors.Is(err, re) {
-			return true
-		}
-	}
-	return false
+	return err != nil && bank.Classify(err) == bank.ClassRetryable
 }
 
 // Retry executes fn with exponential backoff. Permanent errors are not retried.
@@ -2,10 +2,14 @@
 package api
 
 import (
-	"github.com/gin-gonic/gin"
+	"net/http"
+
+	"github.com/aynifx/bank-scraper/internal/api/dashboard"
+	"github.com/aynifx/bank-scraper/internal/api/graphql"
 	"github.com/aynifx/bank-scraper/internal/api/handler"
 	"github.com/aynifx/bank-scraper/internal/api/middleware"
 	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/gin-gonic/gin"
 )
 
 // RouterDeps holds the dependencies needed to set up the API router.
@@ -18,6 +22,25 @@ type RouterDeps struct {
 	Creds       handler.CredentialProvider
 	PingDB      handler.DBPinger
 	Sessions    handler.SessionStatusProvider
+	Forecaster  handler.Forecaster
+	Events      handler.EventSubscriber
+	Jobs        handler.ScrapeQueue
+	JobStats    handler.JobStatsProvider
+	Timings     handler.TimingsProvider
+
+	// Audit records API-triggered bank access; nil disables auditing.
+	Audit middleware.AuditLogger
+	// ScrapeLimiter throttles, per API key, the endpoints that log in to a
+	// bank; nil disables throttling.
+	ScrapeLimiter *middleware.RateLimiter
+	// Dashboard serves the read-only web UI at /dashboard/. Its page is
+	// public; the data comes from the API with the key the user enters.
+	Dashboard bool
+	// GraphQL is the schema served at /api/v1/graphql; nil answers 404.
+	GraphQL *graphql.Schema
+	// Webhooks are the external systems allowed to queue scrapes through
+	// /api/v1/hooks/:hook, keyed by name; unknown names answer 404.
+	Webhooks map[string]middleware.Webhook
 }
 
 // SetupRouter creates and configures the Gin router with all API routes.
@@ -29,21 +52,85 @@ func SetupRouter(deps RouterDeps) *gin.Engine {
 	balanceH := handler.NewBalanceHandler(deps.AccountRepo, deps.Scrapers)
 	txH := handler.NewTransactionHandler(deps.AccountRepo, deps.Scrapers)
 	healthH := handler.NewHealthHandler(deps.PingDB, deps.Sessions)
+	if deps.JobStats != nil {
+		healthH.WithJobStats(deps.JobStats)
+	}
+	if deps.Timings != nil {
+		healthH.WithTimings(deps.Timings)
+	}
 	discoveryH := handler.NewDiscoveryHandler(deps.Discovery, deps.Creds, deps.CredRepo)
+	forecastH := handler.NewForecastHandler(deps.Forecaster)
+	eventsH := handler.NewEventsHandler(deps.Events)
+	sessionH := handler.NewSessionHandler(deps.Scrapers, deps.Sessions)
+	scrapeH := handler.NewScrapeHandler(deps.Jobs).WithAccounts(deps.AccountRepo)
+
+	if deps.Dashboard {
+		r.StaticFS("/dashboard", http.FS(dashboard.FS))
+		r.GET("/", func(c *gin.Context) { c.Redirect(http.StatusFound, "/dashboard/") })
+	}
 
 	v1 := r.Group("/api/v1")
 
 	// Health check is outside auth — must respond even when DB is down.
 	v1.GET("/health", healthH.Check)
 
+	spec := OpenAPI()
+	v1.GET("/openapi.json", func(c *gin.Context) { c.JSON(http.StatusOK, spec) })
+
+	limiter := deps.ScrapeLimiter
+	if limiter == nil {
+		limiter, _ = middleware.NewRateLimiter(0, 0) // Disabled, never fails
+	}
+
 	v1auth := v1.Group("")
 	v1auth.Use(middleware.APIKeyAuth(deps.APIKeyRepo))
+
+	// Read-only keys are served from existing bank sessions; only keys with
+	// the scrape scope may cause a login, which counts against the scrape
+	// rate limit.
+	read := v1auth.Group("")
+	read.Use(middleware.RequireScope(store.APIKeyScopeRead), middleware.ScrapeScopeLogin())
 	{
-		v1auth.GET("/accounts", accountH.List)
-		v1auth.GET("/accounts/:account_id/balance", balanceH.Get)
-		v1auth.GET("/accounts/:account_id/transactions", txH.List)
-		v1auth.POST("/admin/discover/:bank_code", discoveryH.Trigger)
+		read.GET("/accounts", accountH.List)
+		read.GET("/accounts/:account_id/balance",
+			middleware.Audit(deps.Audit, middleware.AuditAPIScrape, "account", "account_id"), limiter.LoginGate(), balanceH.Get)
+		read.GET("/accounts/:account_id/transactions",
+			middleware.Audit(deps.Audit, middleware.AuditAPIScrape, "account", "account_id"), limiter.LoginGate(), txH.List)
+		read.GET("/reports/forecast",
+			middleware.Audit(deps.Audit, middleware.AuditAPIScrape, "report", ""), limiter.LoginGate(), forecastH.Get)
+		read.GET("/events", eventsH.Stream)
+		read.GET("/graphql", graphql.Handler(deps.GraphQL))
+		read.POST("/graphql", graphql.Handler(deps.GraphQL))
+		read.GET("/scrape", scrapeH.List)
+		read.GET("/scrape/:job_id", scrapeH.Get)
 	}
 
+	// Endpoints that log in to a bank are audited (including rejections) and
+	// share one per-key rate limit.
+	loginGroup := func(scope, action, targetParam string) *gin.RouterGroup {
+		g := v1auth.Group("")
+		g.Use(
+			middleware.Audit(deps.Audit, action, "bank", targetParam),
+			middleware.RequireScope(scope),
+			limiter.Middleware(),
+		)
+		return g
+	}
+	loginGroup(store.APIKeyScopeScrape, middleware.AuditAPISession, "bank_code").
+		POST("/sessions/:bank_code", sessionH.Open)
+	loginGroup(store.APIKeyScopeScrape, middleware.AuditAPIScrapeJob, "").
+		POST("/scrape", scrapeH.Enqueue)
+	loginGroup(store.APIKeyScopeAdmin, middleware.AuditAPIDiscover, "bank_code").
+		POST("/admin/discover/:bank_code", discoveryH.Trigger)
+
+	// Inbound webhooks sign their requests instead of sending an API key.
+	// Scrapes are audited (including rejected signatures) and share the
+	// scrape rate limit under the webhook's own client ID.
+	webhookAuth := middleware.WebhookAuth(deps.Webhooks)
+	hooks := v1.Group("/hooks/:hook")
+	hooks.POST("/scrape", middleware.Audit(deps.Audit, middleware.AuditWebhookScrape, "bank", ""),
+		webhookAuth, limiter.Middleware(), scrapeH.Webhook)
+	hooks.GET("/scrape/:job_id", webhookAuth, scrapeH.Get)
+
 	return r
 }
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupRouter_Dashboard(t *testing.T) {
+	r := SetupRouter(RouterDeps{Dashboard: true})
+
+	for path, want := range map[string]string{
+		"/dashboard/":       `<script src="app.js">`,
+		"/dashboard/app.js": "/api/v1",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, path)
+		assert.Contains(t, w.Body.String(), want, path)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/dashboard/", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	SetupRouter(RouterDeps{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboard/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "disabled")
+}
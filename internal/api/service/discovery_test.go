@@ -14,9 +14,11 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// --- Mocks (discovery-specific; scraper mock is shared) ---
+// --- Mocks (service-specific; scraper mock is shared) ---
 
 type mockAccountRepo struct {
+	listed           []store.Account
+	listErr          error
 	upsertedAccounts []store.Account
 	upsertedCredID   uuid.UUID
 	upsertErr        error
@@ -27,7 +29,7 @@ func (m *mockAccountRepo) GetByID(_ context.Context, _ uuid.UUID) (*store.Accoun
 	return nil, nil
 }
 func (m *mockAccountRepo) List(_ context.Context, _ store.AccountFilter) ([]store.Account, error) {
-	return nil, nil
+	return m.listed, m.listErr
 }
 func (m *mockAccountRepo) UpdateLastSynced(_ context.Context, _ uuid.UUID) error { return nil }
 
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// pendingLookback is how many recent transactions are scanned per account for
+// movements booked but not yet settled (value date in the future).
+const pendingLookback = 50
+
+// ScraperSource returns a logged-in scraper for a bank. The API's session
+// manager (and its resilient wrapper) satisfies it.
+type ScraperSource interface {
+	GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error)
+}
+
+// ForecastService gathers balances, pending transactions and scheduled
+// operations for every active account and projects the cash position.
+type ForecastService struct {
+	accounts store.AccountRepository
+	scrapers ScraperSource
+	logger   *slog.Logger
+}
+
+// NewForecastService creates a new ForecastService.
+func NewForecastService(accounts store.AccountRepository, scrapers ScraperSource, logger *slog.Logger) *ForecastService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ForecastService{
+		accounts: accounts,
+		scrapers: scrapers,
+		logger:   logger,
+	}
+}
+
+// Forecast projects the cash position per currency for days days from asOf.
+// Any bank that cannot be read fails the whole forecast: a projection that
+// silently omits a bank would overstate or understate the position.
+func (f *ForecastService) Forecast(ctx context.Context, asOf time.Time, days int) (*report.CashForecast, error) {
+	accounts, err := f.accounts.List(ctx, store.AccountFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+
+	byBank := map[bank.Code][]store.Account{}
+	var order []bank.Code
+	for _, a := range accounts {
+		if a.Status != "" && a.Status != store.AccountStatusActive {
+			continue
+		}
+		code := bank.Code(a.BankCode)
+		if _, seen := byBank[code]; !seen {
+			order = append(order, code)
+		}
+		byBank[code] = append(byBank[code], a)
+	}
+
+	until := asOf.AddDate(0, 0, days)
+	var (
+		balances []bank.Balance
+		items    []report.Item
+	)
+	for _, code := range order {
+		b, it, err := f.collectBank(ctx, code, byBank[code], asOf, until)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, b...)
+		items = append(items, it...)
+	}
+
+	return report.Forecast(balances, items, asOf, days), nil
+}
+
+// collectBank reads one bank's balances, pending items and (when supported)
+// scheduled operations, restricted to the known accounts.
+func (f *ForecastService) collectBank(ctx context.Context, code bank.Code, accounts []store.Account, asOf, until time.Time) ([]bank.Balance, []report.Item, error) {
+	scraper, err := f.scrapers.GetScraper(ctx, code)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to %s: %w", code, err)
+	}
+
+	all, err := scraper.GetBalance(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get %s balances: %w", code, err)
+	}
+
+	known := make(map[string]store.Account, len(accounts))
+	for _, a := range accounts {
+		known[a.AccountNumber] = a
+	}
+
+	var (
+		balances []bank.Balance
+		items    []report.Item
+	)
+	for _, b := range all {
+		if _, ok := known[b.AccountID]; !ok {
+			continue
+		}
+		balances = append(balances, b)
+
+		txns, err := scraper.GetTransactions(ctx, b.AccountID, pendingLookback)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get %s transactions: %w", code, err)
+		}
+		items = append(items, report.PendingItems(txns, b.Currency, asOf)...)
+	}
+
+	if sched, ok := scraper.(bank.ScheduledOperationsScraper); ok {
+		ops, err := sched.GetScheduledOperations(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get %s scheduled operations: %w", code, err)
+		}
+		items = append(items, report.ScheduledItems(ops, until)...)
+	} else {
+		f.logger.Info("bank does not expose scheduled operations; forecasting without them",
+			slog.String("bank", string(code)))
+	}
+
+	return balances, items, nil
+}
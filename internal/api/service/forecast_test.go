@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockScraperSource struct {
+	scrapers map[bank.Code]bank.Scraper
+	err      error
+}
+
+func (m *mockScraperSource) GetScraper(_ context.Context, code bank.Code) (bank.Scraper, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	s, ok := m.scrapers[code]
+	if !ok {
+		return nil, errors.New("no scraper")
+	}
+	return s, nil
+}
+
+func TestForecastService_Forecast(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := &mockAccountRepo{listed: []store.Account{
+		{BankCode: "BBVA", AccountNumber: "PEN-1", Currency: "PEN", Status: store.AccountStatusActive},
+		{BankCode: "BBVA", AccountNumber: "OLD-1", Currency: "PEN", Status: store.AccountStatusInactive},
+	}}
+	scraper := &banktest.MockScraper{
+		Balances: []bank.Balance{
+			{AccountID: "PEN-1", Currency: bank.CurrencyPEN, AvailableBalance: 100_000},
+			{AccountID: "OLD-1", Currency: bank.CurrencyPEN, AvailableBalance: 999_999},
+		},
+		Transactions: []bank.Transaction{
+			{Description: "ABONO", Amount: 5_000, Type: bank.TransactionCredit, ValueDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		},
+		Scheduled: []bank.ScheduledOperation{
+			{Description: "Transferencia", Amount: 20_000, Currency: bank.CurrencyPEN, Type: bank.TransactionDebit,
+				ScheduledDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	svc := NewForecastService(repo, &mockScraperSource{scrapers: map[bank.Code]bank.Scraper{bank.BankBBVA: scraper}}, nil)
+
+	fc, err := svc.Forecast(context.Background(), asOf, 5)
+	require.NoError(t, err)
+
+	require.Len(t, fc.Currencies, 1)
+	pen := fc.Currencies[0]
+	assert.Equal(t, int64(100_000), pen.Opening, "inactive accounts are excluded")
+	require.Len(t, pen.Days, 5)
+	assert.Equal(t, int64(105_000), pen.Days[1].Closing)
+	assert.Equal(t, int64(85_000), pen.Days[4].Closing)
+
+	sources := map[string]int{}
+	for _, it := range pen.Items {
+		sources[it.Source]++
+	}
+	assert.Equal(t, map[string]int{report.SourcePending: 1, report.SourceScheduled: 1}, sources)
+}
+
+func TestForecastService_Errors(t *testing.T) {
+	accounts := []store.Account{{BankCode: "BBVA", AccountNumber: "PEN-1", Status: store.AccountStatusActive}}
+
+	tests := []struct {
+		name    string
+		repo    *mockAccountRepo
+		source  *mockScraperSource
+		wantErr string
+	}{
+		{
+			name:    "list accounts fails",
+			repo:    &mockAccountRepo{listErr: errors.New("db down")},
+			source:  &mockScraperSource{},
+			wantErr: "list accounts",
+		},
+		{
+			name:    "bank unavailable",
+			repo:    &mockAccountRepo{listed: accounts},
+			source:  &mockScraperSource{err: bank.ErrBankUnavailable},
+			wantErr: "connect to BBVA",
+		},
+		{
+			name: "scheduled operations fail",
+			repo: &mockAccountRepo{listed: accounts},
+			source: &mockScraperSource{scrapers: map[bank.Code]bank.Scraper{
+				bank.BankBBVA: &banktest.MockScraper{ScheduledErr: bank.ErrTimeout},
+			}},
+			wantErr: "scheduled operations",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewForecastService(tc.repo, tc.source, nil)
+			_, err := svc.Forecast(context.Background(), time.Now(), 7)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// summaryLookback is how many recent transactions are fetched per account to
+// find the last transaction date.
+const summaryLookback = 50
+
+// SummaryService reads the current balance and last transaction date of every
+// active account. Unlike ForecastService it tolerates failing banks: their
+// rows are marked with an error so the report can show how stale they are.
+type SummaryService struct {
+	accounts store.AccountRepository
+	scrapers ScraperSource
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewSummaryService creates a new SummaryService.
+func NewSummaryService(accounts store.AccountRepository, scrapers ScraperSource, logger *slog.Logger) *SummaryService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SummaryService{
+		accounts: accounts,
+		scrapers: scrapers,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Collect returns one row per active account. It only fails when the account
+// list itself cannot be read.
+func (s *SummaryService) Collect(ctx context.Context) ([]report.AccountSummary, error) {
+	accounts, err := s.accounts.List(ctx, store.AccountFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+
+	byBank := map[bank.Code][]store.Account{}
+	var order []bank.Code
+	for _, a := range accounts {
+		if a.Status != "" && a.Status != store.AccountStatusActive {
+			continue
+		}
+		code := bank.Code(a.BankCode)
+		if _, seen := byBank[code]; !seen {
+			order = append(order, code)
+		}
+		byBank[code] = append(byBank[code], a)
+	}
+
+	var rows []report.AccountSummary
+	for _, code := range order {
+		rows = append(rows, s.collectBank(ctx, code, byBank[code])...)
+	}
+	return rows, nil
+}
+
+func (s *SummaryService) collectBank(ctx context.Context, code bank.Code, accounts []store.Account) []report.AccountSummary {
+	rows := make([]report.AccountSummary, len(accounts))
+	index := make(map[string]int, len(accounts))
+	for i, a := range accounts {
+		rows[i] = report.AccountSummary{
+			BankCode:      code,
+			AccountNumber: a.AccountNumber,
+			Alias:         a.Alias,
+			Currency:      bank.Currency(a.Currency),
+			LastSyncedAt:  a.LastSyncedAt,
+		}
+		index[a.AccountNumber] = i
+	}
+	fail := func(err error) []report.AccountSummary {
+		s.logger.Warn("summary: bank unavailable", slog.String("bank", string(code)), slog.Any("error", err))
+		for i := range rows {
+			rows[i].Error, rows[i].Err = err.Error(), err
+		}
+		return rows
+	}
+
+	scraper, err := s.scrapers.GetScraper(ctx, code)
+	if err != nil {
+		return fail(fmt.Errorf("connect: %w", err))
+	}
+	balances, err := scraper.GetBalance(ctx)
+	if err != nil {
+		return fail(fmt.Errorf("get balance: %w", err))
+	}
+
+	seen := map[int]bool{}
+	for _, b := range balances {
+		i, ok := index[b.AccountID]
+		if !ok {
+			continue
+		}
+		seen[i] = true
+		fetched := b.FetchedAt
+		if fetched.IsZero() {
+			fetched = s.now()
+		}
+		rows[i].Currency = b.Currency
+		rows[i].AvailableBalance = b.AvailableBalance
+		rows[i].CurrentBalance = b.CurrentBalance
+		rows[i].FetchedAt = &fetched
+
+		txns, err := scraper.GetTransactions(ctx, b.AccountID, summaryLookback)
+		if err != nil {
+			// The balance is still current; only the last-transaction column is missing.
+			s.logger.Warn("summary: transactions unavailable",
+				slog.String("bank", string(code)), slog.Any("error", err))
+			continue
+		}
+		rows[i].LastTransaction = report.LatestDate(txns)
+	}
+	for i := range rows {
+		if !seen[i] {
+			rows[i].Error = "account not returned by bank"
+		}
+	}
+	return rows
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryService_Collect(t *testing.T) {
+	synced := time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC)
+	repo := &mockAccountRepo{listed: []store.Account{
+		{BankCode: "BBVA", AccountNumber: "PEN-1", Currency: "PEN", Status: store.AccountStatusActive},
+		{BankCode: "BBVA", AccountNumber: "GONE-1", Currency: "USD", Status: store.AccountStatusActive},
+		{BankCode: "BCP", AccountNumber: "BCP-1", Currency: "PEN", Status: store.AccountStatusActive, LastSyncedAt: &synced},
+		{BankCode: "BBVA", AccountNumber: "OLD-1", Currency: "PEN", Status: store.AccountStatusInactive},
+	}}
+	bbva := &banktest.MockScraper{
+		Balances: []bank.Balance{{AccountID: "PEN-1", Currency: bank.CurrencyPEN, AvailableBalance: 10_000, CurrentBalance: 12_000}},
+		Transactions: []bank.Transaction{
+			{Date: time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)},
+			{Date: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	source := &mockScraperSource{scrapers: map[bank.Code]bank.Scraper{bank.BankBBVA: bbva}}
+
+	rows, err := NewSummaryService(repo, source, nil).Collect(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, "PEN-1", rows[0].AccountNumber)
+	assert.True(t, rows[0].Fresh())
+	assert.Equal(t, int64(10_000), rows[0].AvailableBalance)
+	assert.Equal(t, int64(12_000), rows[0].CurrentBalance)
+	require.NotNil(t, rows[0].LastTransaction)
+	assert.Equal(t, 28, rows[0].LastTransaction.Day())
+
+	assert.Equal(t, "GONE-1", rows[1].AccountNumber)
+	assert.Equal(t, "account not returned by bank", rows[1].Error)
+
+	assert.Equal(t, bank.BankBCP, rows[2].BankCode)
+	assert.Contains(t, rows[2].Error, "connect")
+	assert.Error(t, rows[2].Err, "kept for classifying the failure")
+	assert.Equal(t, &synced, rows[2].LastSyncedAt)
+}
+
+func TestSummaryService_Collect_TransactionsFailKeepsBalance(t *testing.T) {
+	repo := &mockAccountRepo{listed: []store.Account{{BankCode: "BBVA", AccountNumber: "PEN-1", Status: store.AccountStatusActive}}}
+	scraper := &banktest.MockScraper{
+		Balances:        []bank.Balance{{AccountID: "PEN-1", Currency: bank.CurrencyPEN, AvailableBalance: 500}},
+		TransactionsErr: bank.ErrTimeout,
+	}
+	source := &mockScraperSource{scrapers: map[bank.Code]bank.Scraper{bank.BankBBVA: scraper}}
+
+	rows, err := NewSummaryService(repo, source, nil).Collect(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.True(t, rows[0].Fresh())
+	assert.Nil(t, rows[0].LastTransaction)
+}
+
+func TestSummaryService_Collect_ListError(t *testing.T) {
+	_, err := NewSummaryService(&mockAccountRepo{listErr: errors.New("db down")}, &mockScraperSource{}, nil).Collect(context.Background())
+	assert.Error(t, err)
+}
@@ -1,43 +1,86 @@
 // Package session provides a lazy singleton session manager for bank scrapers.
-// It maintains one authenticated scraper instance per bank, creating them on
-// first request and re-authenticating when sessions expire.
+// It maintains one authenticated scraper instance per bank and profile,
+// creating them on first request and re-authenticating when sessions expire.
 package session
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
 )
 
-// CredentialProvider retrieves decrypted bank credentials.
+// CredentialProvider retrieves decrypted bank credentials of the context's
+// profile (see store.WithProfile).
 // Satisfied by credmgr/service.CredentialService.GetCredentials.
 type CredentialProvider interface {
 	GetCredentials(ctx context.Context, bankCode string) (map[string]string, error)
 }
 
+// ErrLoginNotAllowed is returned by GetScraper when a bank has no active
+// session and the context forbids opening one (see WithoutLogin).
+var ErrLoginNotAllowed = errors.New("no active bank session and login not allowed")
+
+type noLoginKey struct{}
+
+// WithoutLogin returns a context under which GetScraper only hands out
+// existing, unexpired sessions and never logs in to the bank. Used for
+// callers (e.g. read-only API keys) that may read data but must not cause
+// bank logins.
+func WithoutLogin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noLoginKey{}, true)
+}
+
+// LoginAllowed reports whether ctx permits GetScraper to log in.
+func LoginAllowed(ctx context.Context) bool {
+	noLogin, _ := ctx.Value(noLoginKey{}).(bool)
+	return !noLogin
+}
+
+type loginGateKey struct{}
+
+// WithLoginGate returns a context under which GetScraper calls gate before
+// logging in to a bank and fails with its error, if any, instead. Requests
+// served from an existing session don't call it. The API uses it to
+// rate-limit only the requests that cause a login.
+func WithLoginGate(ctx context.Context, gate func() error) context.Context {
+	return context.WithValue(ctx, loginGateKey{}, gate)
+}
+
 // Info describes the state of a managed scraper session.
 type Info struct {
-	BankCode  bank.Code
-	Active    bool
-	ExpiresAt time.Time
+	Profile      string
+	BankCode     bank.Code
+	Active       bool
+	ExpiresAt    time.Time // When the session lapses unless used again; zero for never
+	LoginVariant string    // See bank.Session.LoginVariant
+}
+
+// sessionKey identifies a session: profiles never share a bank login.
+type sessionKey struct {
+	profile string
+	bank    bank.Code
 }
 
-// managedScraper holds a scraper and its session metadata.
+// managedScraper holds a scraper and its session metadata. session is the
+// manager's own copy of what Login returned, touched under Manager.mu each
+// time the scraper is handed out.
 type managedScraper struct {
 	scraper bank.Scraper
 	session *bank.Session
 }
 
-// Manager manages lazy singleton scraper instances per bank.
-// Uses per-bank locking so Login for one bank doesn't block cached lookups for others.
+// Manager manages lazy singleton scraper instances per bank and profile.
+// Uses per-session locking so Login for one bank doesn't block cached lookups for others.
 type Manager struct {
-	mu       sync.Mutex                    // protects the scrapers map itself
-	scrapers map[bank.Code]*managedScraper // cached scraper instances
-	locks    map[bank.Code]*sync.Mutex     // per-bank lock for Login serialization
+	mu       sync.Mutex                     // protects the scrapers map itself
+	scrapers map[sessionKey]*managedScraper // cached scraper instances
+	locks    map[sessionKey]*sync.Mutex     // per-session lock for Login serialization
 	creds    CredentialProvider
 	factory  bank.ScraperFactory
 	logger   *slog.Logger
@@ -46,52 +89,71 @@ type Manager struct {
 // NewManager creates a new session manager.
 func NewManager(creds CredentialProvider, factory bank.ScraperFactory, logger *slog.Logger) *Manager {
 	return &Manager{
-		scrapers: make(map[bank.Code]*managedScraper),
-		locks:    make(map[bank.Code]*sync.Mutex),
+		scrapers: make(map[sessionKey]*managedScraper),
+		locks:    make(map[sessionKey]*sync.Mutex),
 		creds:    creds,
 		factory:  factory,
 		logger:   logger,
 	}
 }
 
-// bankLock returns (or creates) the per-bank mutex.
-func (m *Manager) bankLock(bankCode bank.Code) *sync.Mutex {
+// sessionLock returns (or creates) the per-session mutex.
+func (m *Manager) sessionLock(key sessionKey) *sync.Mutex {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if _, ok := m.locks[bankCode]; !ok {
-		m.locks[bankCode] = &sync.Mutex{}
+	if _, ok := m.locks[key]; !ok {
+		m.locks[key] = &sync.Mutex{}
 	}
-	return m.locks[bankCode]
+	return m.locks[key]
 }
 
-// GetScraper returns a logged-in scraper for the given bank.
+// GetScraper returns a logged-in scraper for the given bank, with the
+// credentials of the context's profile (see store.WithProfile).
 // On first call, it creates and authenticates a new scraper.
-// On subsequent calls, it returns the cached scraper if the session is still valid.
+// On subsequent calls, it returns the cached scraper if the session is still
+// valid (see bank.Session.Valid), restarting its idle timeout.
 // If the session has expired, it closes the old scraper and creates a fresh one.
+// Under a WithoutLogin context, it returns ErrLoginNotAllowed instead of
+// logging in, and under a WithLoginGate one, the gate's error.
 //
 // Uses per-bank locking so a slow Login for one bank doesn't block cached lookups for others.
 func (m *Manager) GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scraper, error) {
-	bl := m.bankLock(bankCode)
-	bl.Lock()
-	defer bl.Unlock()
+	key := sessionKey{profile: store.ProfileFrom(ctx), bank: bankCode}
+	sl := m.sessionLock(key)
+	sl.Lock()
+	defer sl.Unlock()
 
 	// Check for existing active session (brief map read under global lock)
 	m.mu.Lock()
-	ms, ok := m.scrapers[bankCode]
+	ms, ok := m.scrapers[key]
+	valid := ok && ms.session.Valid()
+	if valid {
+		ms.session.Touch()
+	}
 	m.mu.Unlock()
 
-	if ok && time.Now().Before(ms.session.ExpiresAt) {
+	if valid {
 		return ms.scraper, nil
 	}
 
+	if !LoginAllowed(ctx) {
+		return nil, fmt.Errorf("%s: %w", bankCode, ErrLoginNotAllowed)
+	}
+	if gate, _ := ctx.Value(loginGateKey{}).(func() error); gate != nil {
+		if err := gate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", bankCode, err)
+		}
+	}
+
 	// Session expired or doesn't exist — clean up old one if present
 	if ok {
 		m.logger.Info("session expired, creating new scraper",
+			slog.String("profile", key.profile),
 			slog.String("bank", string(bankCode)),
 			slog.String("session_id", ms.session.ID))
 		_ = ms.scraper.Close()
 		m.mu.Lock()
-		delete(m.scrapers, bankCode)
+		delete(m.scrapers, key)
 		m.mu.Unlock()
 	}
 
@@ -115,33 +177,41 @@ func (m *Manager) GetScraper(ctx context.Context, bankCode bank.Code) (bank.Scra
 		return nil, fmt.Errorf("login to %s: %w", bankCode, err)
 	}
 
+	tracked := *session
+	if tracked.LastActive.IsZero() {
+		tracked.Touch()
+	}
 	m.mu.Lock()
-	m.scrapers[bankCode] = &managedScraper{
+	m.scrapers[key] = &managedScraper{
 		scraper: scraper,
-		session: session,
+		session: &tracked,
 	}
 	m.mu.Unlock()
 
 	m.logger.Info("scraper session created",
+		slog.String("profile", key.profile),
 		slog.String("bank", string(bankCode)),
 		slog.String("session_id", session.ID),
-		slog.Time("expires_at", session.ExpiresAt))
+		slog.Time("expires_at", tracked.Expiry()),
+		slog.String("login_variant", session.LoginVariant))
 
 	return scraper, nil
 }
 
-// Invalidate removes and closes the scraper for a bank.
-// The next GetScraper call will create a fresh instance.
-func (m *Manager) Invalidate(bankCode bank.Code) {
+// Invalidate removes and closes the scraper for a bank in the context's
+// profile. The next GetScraper call will create a fresh instance.
+func (m *Manager) Invalidate(ctx context.Context, bankCode bank.Code) {
+	key := sessionKey{profile: store.ProfileFrom(ctx), bank: bankCode}
 	m.mu.Lock()
-	ms, ok := m.scrapers[bankCode]
+	ms, ok := m.scrapers[key]
 	if ok {
-		delete(m.scrapers, bankCode)
+		delete(m.scrapers, key)
 	}
 	m.mu.Unlock()
 
 	if ok {
 		m.logger.Info("invalidating scraper session",
+			slog.String("profile", key.profile),
 			slog.String("bank", string(bankCode)))
 		_ = ms.scraper.Close()
 	}
@@ -151,29 +221,45 @@ func (m *Manager) Invalidate(bankCode bank.Code) {
 func (m *Manager) Shutdown(ctx context.Context) {
 	m.mu.Lock()
 	scrapers := m.scrapers
-	m.scrapers = make(map[bank.Code]*managedScraper)
+	m.scrapers = make(map[sessionKey]*managedScraper)
 	m.mu.Unlock()
 
-	for code, ms := range scrapers {
+	for key, ms := range scrapers {
 		m.logger.Info("shutting down scraper",
-			slog.String("bank", string(code)))
+			slog.String("profile", key.profile),
+			slog.String("bank", string(key.bank)))
 		_ = ms.scraper.Logout(ctx)
 		_ = ms.scraper.Close()
 	}
 }
 
-// SessionStatus returns the current state of all managed sessions.
+// SessionStatus returns the current state of all managed sessions, of
+// every profile.
 func (m *Manager) SessionStatus() []Info {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	infos := make([]Info, 0, len(m.scrapers))
-	for code, ms := range m.scrapers {
+	for key, ms := range m.scrapers {
 		infos = append(infos, Info{
-			BankCode:  code,
-			Active:    time.Now().Before(ms.session.ExpiresAt),
-			ExpiresAt: ms.session.ExpiresAt,
+			Profile:      key.profile,
+			BankCode:     key.bank,
+			Active:       ms.session.Valid(),
+			ExpiresAt:    ms.session.Expiry(),
+			LoginVariant: ms.session.LoginVariant,
 		})
 	}
 	return infos
 }
+
+// LoginVariant returns the login page variant of the context's profile's
+// session with a bank, empty when there's no session or the bank has only
+// one variant.
+func (m *Manager) LoginVariant(ctx context.Context, bankCode bank.Code) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ms, ok := m.scrapers[sessionKey{profile: store.ProfileFrom(ctx), bank: bankCode}]; ok {
+		return ms.session.LoginVariant
+	}
+	return ""
+}
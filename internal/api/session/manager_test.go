@@ -9,6 +9,7 @@ import (
 
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -113,6 +114,35 @@ func TestManager_GetScraper_RelogsOnExpiredSession(t *testing.T) {
 	assert.Equal(t, 2, callCount, "factory should be called twice")
 }
 
+func TestManager_GetScraper_IdleTimeout(t *testing.T) {
+	logins := 0
+	factory := func(_ bank.Code) (bank.Scraper, error) {
+		logins++
+		return &banktest.MockScraper{LoginSession: &bank.Session{ID: "idle", Code: bank.BankBBVA, IdleTimeout: 10 * time.Minute}}, nil
+	}
+	mgr := NewManager(&mockCredProvider{creds: validCreds()}, factory, testLogger())
+	ctx := context.Background()
+	key := sessionKey{profile: store.ProfileFrom(ctx), bank: bank.BankBBVA}
+
+	s1, err := mgr.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+
+	// Used 9 minutes ago: still valid, and handing it out restarts the clock.
+	mgr.scrapers[key].session.LastActive = time.Now().Add(-9 * time.Minute)
+	s2, err := mgr.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+	assert.Same(t, s1, s2)
+	assert.WithinDuration(t, time.Now(), mgr.scrapers[key].session.LastActive, time.Second)
+
+	// Idle for longer than the timeout: logs in again.
+	mgr.scrapers[key].session.LastActive = time.Now().Add(-11 * time.Minute)
+	assert.False(t, mgr.SessionStatus()[0].Active)
+	s3, err := mgr.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+	assert.NotSame(t, s1, s3)
+	assert.Equal(t, 2, logins)
+}
+
 func TestManager_GetScraper_CredentialError(t *testing.T) {
 	credErr := errors.New("no credential configured")
 	factory := func(_ bank.Code) (bank.Scraper, error) {
@@ -155,6 +185,52 @@ func TestManager_GetScraper_LoginError(t *testing.T) {
 	assert.Equal(t, 1, ms.CloseCalled, "should close scraper on login failure")
 }
 
+func TestManager_GetScraper_WithoutLogin(t *testing.T) {
+	ms := &banktest.MockScraper{LoginSession: validSession()}
+	factoryCalls := 0
+	factory := func(_ bank.Code) (bank.Scraper, error) { factoryCalls++; return ms, nil }
+	mgr := NewManager(&mockCredProvider{creds: validCreds()}, factory, testLogger())
+	noLogin := WithoutLogin(context.Background())
+
+	_, err := mgr.GetScraper(noLogin, bank.BankBBVA)
+	require.ErrorIs(t, err, ErrLoginNotAllowed)
+	assert.Equal(t, 0, factoryCalls, "no browser launched")
+	assert.Equal(t, 0, ms.LoginCalled)
+
+	// Once a session exists, read-only callers share it.
+	_, err = mgr.GetScraper(context.Background(), bank.BankBBVA)
+	require.NoError(t, err)
+	scraper, err := mgr.GetScraper(noLogin, bank.BankBBVA)
+	require.NoError(t, err)
+	assert.Equal(t, ms, scraper)
+	assert.Equal(t, 1, ms.LoginCalled)
+
+	assert.True(t, LoginAllowed(context.Background()))
+	assert.False(t, LoginAllowed(noLogin))
+}
+
+func TestManager_GetScraper_LoginGate(t *testing.T) {
+	ms := &banktest.MockScraper{LoginSession: validSession()}
+	factory := func(_ bank.Code) (bank.Scraper, error) { return ms, nil }
+	mgr := NewManager(&mockCredProvider{creds: validCreds()}, factory, testLogger())
+	refused := errors.New("over the limit")
+	calls := 0
+	gated := WithLoginGate(context.Background(), func() error {
+		calls++
+		return refused
+	})
+
+	_, err := mgr.GetScraper(gated, bank.BankBBVA)
+	require.ErrorIs(t, err, refused)
+	assert.Equal(t, 0, ms.LoginCalled)
+
+	_, err = mgr.GetScraper(context.Background(), bank.BankBBVA)
+	require.NoError(t, err)
+	_, err = mgr.GetScraper(gated, bank.BankBBVA)
+	require.NoError(t, err, "an open session needs no login")
+	assert.Equal(t, 1, calls)
+}
+
 func TestManager_Invalidate(t *testing.T) {
 	callCount := 0
 	factory := func(_ bank.Code) (bank.Scraper, error) {
@@ -169,13 +245,36 @@ func TestManager_Invalidate(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, callCount)
 
-	mgr.Invalidate(bank.BankBBVA)
+	mgr.Invalidate(context.Background(), bank.BankBBVA)
 
 	_, err = mgr.GetScraper(context.Background(), bank.BankBBVA)
 	require.NoError(t, err)
 	assert.Equal(t, 2, callCount, "should create a new scraper after invalidation")
 }
 
+func TestManager_GetScraper_SessionPerProfile(t *testing.T) {
+	callCount := 0
+	factory := func(_ bank.Code) (bank.Scraper, error) {
+		callCount++
+		return &banktest.MockScraper{LoginSession: validSession()}, nil
+	}
+	mgr := NewManager(&mockCredProvider{creds: validCreds()}, factory, testLogger())
+	acme := store.WithProfile(context.Background(), "acme")
+
+	defaultScraper, err := mgr.GetScraper(context.Background(), bank.BankBBVA)
+	require.NoError(t, err)
+	acmeScraper, err := mgr.GetScraper(acme, bank.BankBBVA)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, callCount, "profiles never share a bank login")
+	assert.NotSame(t, defaultScraper, acmeScraper)
+
+	mgr.Invalidate(acme, bank.BankBBVA)
+	again, err := mgr.GetScraper(context.Background(), bank.BankBBVA)
+	require.NoError(t, err)
+	assert.Same(t, defaultScraper, again, "invalidating one profile leaves the other's session")
+}
+
 func TestManager_Shutdown(t *testing.T) {
 	ms := &banktest.MockScraper{LoginSession: validSession()}
 	factory := func(_ bank.Code) (bank.Scraper, error) { return ms, nil }
@@ -226,3 +325,22 @@ func TestManager_SessionStatus_ExpiredSession(t *testing.T) {
 	require.Len(t, statuses, 1)
 	assert.False(t, statuses[0].Active, "expired session should not be active")
 }
+
+func TestManager_LoginVariant(t *testing.T) {
+	session := validSession()
+	session.LoginVariant = "legacy"
+	ms := &banktest.MockScraper{LoginSession: session}
+	factory := func(_ bank.Code) (bank.Scraper, error) { return ms, nil }
+	mgr := NewManager(&mockCredProvider{creds: validCreds()}, factory, testLogger())
+	ctx := context.Background()
+
+	assert.Empty(t, mgr.LoginVariant(ctx, bank.BankBBVA), "no session yet")
+
+	_, err := mgr.GetScraper(ctx, bank.BankBBVA)
+	require.NoError(t, err)
+
+	assert.Equal(t, "legacy", mgr.LoginVariant(ctx, bank.BankBBVA))
+	assert.Empty(t, mgr.LoginVariant(store.WithProfile(ctx, "other"), bank.BankBBVA), "other profiles have their own session")
+	require.Len(t, mgr.SessionStatus(), 1)
+	assert.Equal(t, "legacy", mgr.SessionStatus()[0].LoginVariant)
+}
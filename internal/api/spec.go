@@ -0,0 +1,235 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/api/handler"
+	"github.com/aynifx/bank-scraper/internal/api/middleware"
+	"github.com/aynifx/bank-scraper/internal/api/openapi"
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// accountsResponse mirrors the body of GET /accounts.
+type accountsResponse struct {
+	SchemaVersion string                    `json:"schema_version"`
+	Accounts      []handler.AccountResponse `json:"accounts"`
+}
+
+// discoveryResponse mirrors the body of POST /admin/discover/:bank_code.
+type discoveryResponse struct {
+	Accounts []handler.AccountResponse `json:"accounts"`
+}
+
+// graphQLRequest mirrors the body of POST /graphql.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// graphQLResponse mirrors the body of GraphQL responses.
+type graphQLResponse struct {
+	Data   any `json:"data,omitempty"`
+	Errors []struct {
+		Message string `json:"message"`
+		Path    []any  `json:"path,omitempty"`
+	} `json:"errors,omitempty"`
+}
+
+var accountIDParam = openapi.Param{Name: "account_id", In: "path", Format: "uuid"}
+
+var webhookParams = []openapi.Param{
+	{Name: "hook", In: "path", Description: "Webhook name, as configured in SCRAPE_HOOKS"},
+	{Name: middleware.HeaderWebhookSignature, In: "header", Required: true},
+}
+
+// operations documents every route SetupRouter registers. spec_test.go
+// checks the two stay in sync.
+var operations = []openapi.Operation{
+	{
+		Method: http.MethodGet, Path: "/api/v1/health", ID: "getHealth", Tag: "system",
+		Summary:  "System and per-bank session health",
+		Response: handler.HealthResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/openapi.json", ID: "getOpenAPI", Tag: "system",
+		Summary: "This document",
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/accounts", ID: "listAccounts", Tag: "accounts",
+		Summary: "List configured bank accounts",
+		Scope:   store.APIKeyScopeRead,
+		Params: []openapi.Param{
+			{Name: "bank_code", In: "query", Description: "Filter by bank, e.g. BBVA"},
+			{Name: "currency", In: "query", Description: "Filter by ISO 4217 currency, e.g. PEN"},
+		},
+		Response: accountsResponse{},
+		Errors:   []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/accounts/:account_id/balance", ID: "getBalance", Tag: "accounts",
+		Summary:     "Current balance of an account",
+		Description: "Fetched live from the bank. Keys without the `scrape` scope are served only from an existing bank session.",
+		Scope:       store.APIKeyScopeRead,
+		Params:      []openapi.Param{accountIDParam},
+		Response:    handler.BalanceResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+			http.StatusNotFound, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/accounts/:account_id/transactions", ID: "listTransactions", Tag: "accounts",
+		Summary:     "Transactions of an account within a date range",
+		Description: "Fetched live from the bank. Keys without the `scrape` scope are served only from an existing bank session.",
+		Scope:       store.APIKeyScopeRead,
+		Params: []openapi.Param{
+			accountIDParam,
+			{Name: "from_date", In: "query", Format: "date", Description: "Defaults to 30 days ago"},
+			{Name: "to_date", In: "query", Format: "date", Description: "Defaults to today; at most 90 days after from_date"},
+			{Name: "page", In: "query", Type: "integer", Description: "1-based page number"},
+			{Name: "page_size", In: "query", Type: "integer", Description: "1 to 250, default 50"},
+			{Name: "sort", In: "query", Description: "Comma-separated keys (date, value_date, id, amount), each optionally :asc or :desc; default date:desc,id:desc"},
+		},
+		Response: handler.TransactionsListResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+			http.StatusNotFound, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/reports/forecast", ID: "getForecast", Tag: "reports",
+		Summary:     "Projected cash position per currency",
+		Description: "Built from live balances of every bank. Keys without the `scrape` scope are served only from existing bank sessions.",
+		Scope:       store.APIKeyScopeRead,
+		Params: []openapi.Param{
+			{Name: "days", In: "query", Type: "integer",
+				Description: fmt.Sprintf("Horizon in days, 1 to %d (default %d)", handler.MaxForecastDays, handler.DefaultForecastDays)},
+		},
+		Response: handler.ForecastResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+			http.StatusTooManyRequests, http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/events", ID: "streamEvents", Tag: "events",
+		Summary:     "Server-sent stream of scrape events and new transactions",
+		Description: "Send Last-Event-ID to replay recent events missed while disconnected.",
+		Scope:       store.APIKeyScopeRead,
+		Params: []openapi.Param{
+			{Name: "types", In: "query", Description: "Comma-separated event types or prefixes, e.g. transaction.new,scrape"},
+			{Name: "bank_code", In: "query", Description: "Filter by bank"},
+		},
+		Response: events.Event{},
+		Stream:   true,
+		Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/graphql", ID: "getGraphQL", Tag: "graphql",
+		Summary:     "GraphQL schema, or a query given in the URL",
+		Description: "Without `query` returns the schema in SDL (text/plain). Enabled with API_GRAPHQL.",
+		Scope:       store.APIKeyScopeRead,
+		Params: []openapi.Param{
+			{Name: "query", In: "query"},
+			{Name: "operationName", In: "query"},
+			{Name: "variables", In: "query", Description: "JSON object"},
+		},
+		Response: graphQLResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/graphql", ID: "queryGraphQL", Tag: "graphql",
+		Summary:     "Query stored accounts, balance history and transactions",
+		Description: "Read-only; never contacts a bank. Queries with variables and aliases; no fragments or introspection. Enabled with API_GRAPHQL.",
+		Scope:       store.APIKeyScopeRead,
+		Body:        graphQLRequest{},
+		Response:    graphQLResponse{},
+		Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/sessions/:bank_code", ID: "openSession", Tag: "sessions",
+		Summary:     "Log in to a bank unless a session is already active",
+		Description: "Rate-limited per API key. force=true discards the current session first.",
+		Scope:       store.APIKeyScopeScrape,
+		Params: []openapi.Param{
+			{Name: "force", In: "query", Type: "boolean"},
+		},
+		Response: handler.SessionResponse{},
+		Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests,
+			http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scrape", ID: "enqueueScrape", Tag: "scrape",
+		Summary:     "Queue a scrape of a bank's accounts",
+		Description: "Returns immediately with a job to poll at the Location header. Rate-limited per API key.",
+		Scope:       store.APIKeyScopeScrape,
+		Body:        handler.ScrapeRequest{},
+		Response:    handler.ScrapeJobResponse{},
+		Status:      http.StatusAccepted,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+			http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scrape", ID: "listScrapeJobs", Tag: "scrape",
+		Summary:     "Latest scrape jobs, newest first",
+		Description: "What the last runs fetched and which errors and warnings they hit. Never contacts a bank.",
+		Scope:       store.APIKeyScopeRead,
+		Params: []openapi.Param{
+			{Name: "limit", In: "query", Type: "integer",
+				Description: fmt.Sprintf("1 to %d (default %d)", handler.MaxScrapeJobsLimit, handler.DefaultScrapeJobsLimit)},
+		},
+		Response: handler.ScrapeJobsListResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+			http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scrape/:job_id", ID: "getScrapeJob", Tag: "scrape",
+		Summary:     "Status and, once finished, results of a scrape job",
+		Description: "A `degraded` job failed for some accounts only: those carry an `error` and the rest have their results.",
+		Scope:       store.APIKeyScopeRead,
+		Params:      []openapi.Param{{Name: "job_id", In: "path", Format: "uuid"}},
+		Response:    handler.ScrapeJobResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+			http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/hooks/:hook/scrape", ID: "webhookScrape", Tag: "webhooks",
+		Summary: "Queue a scrape from a signed inbound webhook",
+		Description: "For external systems, e.g. refreshing balances before payroll. Takes the same body as POST /api/v1/scrape " +
+			"and defaults to high priority. Authenticated by the " + middleware.HeaderWebhookSignature +
+			" header instead of an API key: t=<unix seconds>,v1=<hex HMAC-SHA256 of \"<t>.<METHOD>.<path>.<body>\" with the webhook's secret>, " +
+			"where path is the request path without the query, e.g. /api/v1/hooks/payroll/scrape. " +
+			"Poll the job at the Location header. Rate-limited per webhook.",
+		Params:   webhookParams,
+		Body:     handler.ScrapeRequest{},
+		Response: handler.ScrapeJobResponse{},
+		Status:   http.StatusAccepted,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+			http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/hooks/:hook/scrape/:job_id", ID: "webhookGetScrapeJob", Tag: "webhooks",
+		Summary:     "Status and results of a scrape job, for a signed inbound webhook",
+		Description: "Signed like webhookScrape, over GET, this path and an empty body.",
+		Params:      append(webhookParams, openapi.Param{Name: "job_id", In: "path", Format: "uuid"}),
+		Response:    handler.ScrapeJobResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+			http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/admin/discover/:bank_code", ID: "discoverAccounts", Tag: "admin",
+		Summary:     "Discover and store the accounts of a bank's configured credential",
+		Description: "Rate-limited per API key.",
+		Scope:       store.APIKeyScopeAdmin,
+		Response:    discoveryResponse{},
+		Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+}
+
+// OpenAPI returns the OpenAPI 3 document describing the REST API.
+func OpenAPI() map[string]any {
+	return openapi.Build(openapi.Info{
+		Title:       "bank-scraper API",
+		Version:     schema.Version,
+		Description: "Read access to scraped bank accounts, balances and transactions. Authenticate with an X-API-Key header.",
+	}, operations)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aynifx/bank-scraper/internal/api/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI_CoversAllRoutes(t *testing.T) {
+	r := SetupRouter(RouterDeps{})
+
+	documented := map[string]bool{}
+	for _, op := range operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+	registered := map[string]bool{}
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		assert.True(t, documented[key], "route %s missing from the OpenAPI operations", key)
+	}
+	for key := range documented {
+		assert.True(t, registered[key], "documented operation %s has no route", key)
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	r := SetupRouter(RouterDeps{})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Contains(t, doc.Paths["/api/v1/accounts/{account_id}/transactions"], "get")
+	assert.Contains(t, doc.Components.Schemas, "TransactionsListResponse")
+
+	// Every $ref resolves.
+	for _, ref := range strings.Split(w.Body.String(), `"$ref":"#/components/schemas/`)[1:] {
+		name := ref[:strings.Index(ref, `"`)]
+		assert.Contains(t, doc.Components.Schemas, name)
+	}
+}
@@ -0,0 +1,155 @@
+// Package calendar knows which days banks post transactions on, so
+// schedules and staleness checks can tell a quiet holiday from a broken
+// scraper.
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Lima is Peru's time zone. Peru has no daylight saving time, so a fixed
+// offset is exact and needs no tzdata on the host.
+var Lima = time.FixedZone("PET", -5*60*60)
+
+// holiday is a fixed-date holiday, observed from year From on (0 for
+// always).
+type holiday struct {
+	Month time.Month
+	Day   int
+	Name  string
+	From  int
+}
+
+// peruHolidays are Peru's national holidays on a fixed date, on which
+// banks are closed.
+var peruHolidays = []holiday{
+	{time.January, 1, "Año Nuevo", 0},
+	{time.May, 1, "Día del Trabajo", 0},
+	{time.June, 7, "Batalla de Arica y Día de la Bandera", 2022},
+	{time.June, 29, "San Pedro y San Pablo", 0},
+	{time.July, 23, "Día de la Fuerza Aérea", 2024},
+	{time.July, 28, "Fiestas Patrias", 0},
+	{time.July, 29, "Fiestas Patrias", 0},
+	{time.August, 6, "Batalla de Junín", 2024},
+	{time.August, 30, "Santa Rosa de Lima", 0},
+	{time.October, 8, "Combate de Angamos", 0},
+	{time.November, 1, "Todos los Santos", 0},
+	{time.December, 8, "Inmaculada Concepción", 0},
+	{time.December, 9, "Batalla de Ayacucho", 2022},
+	{time.December, 25, "Navidad", 0},
+}
+
+// Calendar is a banking calendar: weekends and holidays are days without
+// postings. Days are taken in the calendar's time zone.
+type Calendar struct {
+	loc   *time.Location
+	fixed []holiday
+	extra map[string]string // "2006-01-02" -> name
+}
+
+// Peru returns the Peruvian banking calendar: weekends, the national
+// holidays, Holy Thursday and Good Friday, plus extra dates given as
+// "2006-01-02" (e.g. a non-working day decreed for the year).
+func Peru(extra ...string) (*Calendar, error) {
+	c := &Calendar{loc: Lima, fixed: peruHolidays, extra: map[string]string{}}
+	for _, d := range extra {
+		if _, err := time.Parse(time.DateOnly, d); err != nil {
+			return nil, fmt.Errorf("holiday %q: want YYYY-MM-DD: %w", d, err)
+		}
+		c.extra[d] = "Feriado adicional"
+	}
+	return c, nil
+}
+
+// ForCountry returns the banking calendar of country ("PE"), with extra
+// holidays as for Peru. An empty country has no calendar: nil, which
+// treats every day as a business day.
+func ForCountry(country string, extra ...string) (*Calendar, error) {
+	switch strings.ToUpper(country) {
+	case "":
+		return nil, nil
+	case "PE":
+		return Peru(extra...)
+	default:
+		return nil, fmt.Errorf("no banking calendar for %q (supported: PE)", country)
+	}
+}
+
+// Holiday returns the name of the holiday t falls on, in the calendar's
+// time zone. Weekends are not holidays.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	t = t.In(c.loc)
+	if name, ok := c.extra[t.Format(time.DateOnly)]; ok {
+		return name, true
+	}
+	y, m, d := t.Date()
+	for _, h := range c.fixed {
+		if h.Month == m && h.Day == d && y >= h.From {
+			return h.Name, true
+		}
+	}
+	easter := Easter(y)
+	switch time.Date(y, m, d, 0, 0, 0, 0, time.UTC) {
+	case easter.AddDate(0, 0, -3):
+		return "Jueves Santo", true
+	case easter.AddDate(0, 0, -2):
+		return "Viernes Santo", true
+	}
+	return "", false
+}
+
+// BusinessDay reports whether banks post transactions on the day of t: a
+// weekday that is not a holiday. A nil Calendar treats every day as one.
+func (c *Calendar) BusinessDay(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	switch t.In(c.loc).Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// Easter returns Easter Sunday of year, at midnight UTC (the anonymous
+// Gregorian algorithm).
+func Easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// BusinessTime returns how much of [from, to) falls on business days, for
+// measuring staleness without counting the weekends and holidays nothing
+// is posted on. A nil Calendar counts all of it.
+func (c *Calendar) BusinessTime(from, to time.Time) time.Duration {
+	if c == nil || !from.Before(to) {
+		return max(0, to.Sub(from))
+	}
+	var total time.Duration
+	for day := from.In(c.loc); day.Before(to); {
+		y, m, d := day.Date()
+		next := time.Date(y, m, d+1, 0, 0, 0, 0, c.loc)
+		if c.BusinessDay(day) {
+			end := next
+			if to.Before(end) {
+				end = to
+			}
+			total += end.Sub(day)
+		}
+		day = next
+	}
+	return total
+}
@@ -0,0 +1,88 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEaster(t *testing.T) {
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), Easter(2024))
+	assert.Equal(t, time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC), Easter(2025))
+	assert.Equal(t, time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC), Easter(2026))
+}
+
+func TestPeru_Holiday(t *testing.T) {
+	cal, err := Peru("2026-06-08")
+	require.NoError(t, err)
+
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, Lima) }
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"fixed", day(2026, time.July, 28), "Fiestas Patrias"},
+		{"good friday", day(2026, time.April, 3), "Viernes Santo"},
+		{"holy thursday", day(2026, time.April, 2), "Jueves Santo"},
+		{"extra", day(2026, time.June, 8), "Feriado adicional"},
+		{"added in 2024", day(2024, time.August, 6), "Batalla de Junín"},
+		{"not yet observed", day(2023, time.August, 6), ""},
+		{"working day", day(2026, time.July, 27), ""},
+		// 04:00 UTC on Jan 1 is still Dec 31 in Lima.
+		{"lima day", time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, ok := cal.Holiday(tt.t)
+			assert.Equal(t, tt.want, name)
+			assert.Equal(t, tt.want != "", ok)
+		})
+	}
+}
+
+func TestPeru_BusinessDay(t *testing.T) {
+	cal, err := Peru()
+	require.NoError(t, err)
+
+	assert.True(t, cal.BusinessDay(time.Date(2026, 10, 15, 9, 0, 0, 0, Lima)), "Thursday")
+	assert.False(t, cal.BusinessDay(time.Date(2026, 10, 17, 9, 0, 0, 0, Lima)), "Saturday")
+	assert.False(t, cal.BusinessDay(time.Date(2026, 10, 8, 9, 0, 0, 0, Lima)), "Combate de Angamos")
+
+	var none *Calendar
+	assert.True(t, none.BusinessDay(time.Date(2026, 10, 17, 9, 0, 0, 0, Lima)))
+}
+
+func TestPeru_InvalidExtra(t *testing.T) {
+	_, err := Peru("08/06/2026")
+	assert.Error(t, err)
+}
+
+func TestForCountry(t *testing.T) {
+	cal, err := ForCountry("")
+	require.NoError(t, err)
+	assert.Nil(t, cal)
+
+	cal, err = ForCountry("pe", "2026-06-08")
+	require.NoError(t, err)
+	assert.False(t, cal.BusinessDay(time.Date(2026, 6, 8, 9, 0, 0, 0, Lima)))
+
+	_, err = ForCountry("CL")
+	assert.Error(t, err)
+}
+
+func TestPeru_BusinessTime(t *testing.T) {
+	cal, err := Peru()
+	require.NoError(t, err)
+
+	fri := time.Date(2026, 10, 16, 18, 0, 0, 0, Lima)
+	assert.Equal(t, 6*time.Hour, cal.BusinessTime(fri, fri.Add(6*time.Hour)), "until midnight")
+	assert.Equal(t, 6*time.Hour, cal.BusinessTime(fri, fri.Add(54*time.Hour)), "Saturday doesn't count")
+	assert.Equal(t, 8*time.Hour, cal.BusinessTime(fri, fri.Add(3*24*time.Hour-16*time.Hour)), "Monday 02:00")
+	assert.Zero(t, cal.BusinessTime(fri, fri.Add(-time.Hour)))
+
+	var none *Calendar
+	assert.Equal(t, 54*time.Hour, none.BusinessTime(fri, fri.Add(54*time.Hour)))
+}
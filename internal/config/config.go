@@ -3,6 +3,8 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -17,6 +19,25 @@ type Config struct {
 	// Encryption
 	EncryptionKey string `envconfig:"ENCRYPTION_KEY"` // 64-char hex string (32 bytes)
 
+	// Encrypt stored banking data (transaction descriptions, references,
+	// balances) with ENCRYPTION_KEY, not just credentials
+	StoreEncryption bool `envconfig:"STORE_ENCRYPTION" default:"false"`
+
+	// Per-profile master keys (64-char hex) for profiles whose data must be
+	// sealed under a key of their own, as PROFILE_ENCRYPTION_KEYS=acme:<hex>.
+	// Other profiles use a key derived from ENCRYPTION_KEY; "default" uses
+	// ENCRYPTION_KEY itself.
+	ProfileEncryptionKeys map[string]string `envconfig:"PROFILE_ENCRYPTION_KEYS"`
+
+	// How identical same-day movements are told apart, per bank, as
+	// DEDUP_STRATEGIES=BBVA:document. Unlisted banks use "sequence".
+	DedupStrategies map[string]string `envconfig:"DEDUP_STRATEGIES"`
+
+	// Friendly account names shown instead of masked numbers, keyed by
+	// account number or its last four or more digits, as
+	// ACCOUNT_ALIASES=4607:Operating PEN,4615:Payroll USD.
+	AccountAliases map[string]string `envconfig:"ACCOUNT_ALIASES"`
+
 	// Credential Manager
 	CredMgrPort int           `envconfig:"CREDMGR_PORT" default:"8081"`
 	SessionTTL  time.Duration `envconfig:"SESSION_TTL" default:"15m"`
@@ -24,12 +45,151 @@ type Config struct {
 	// API Gateway
 	APIPort int `envconfig:"API_PORT" default:"8080"`
 
+	// Per-API-key budget for requests that log in to a bank (opening a
+	// session, discovery). Zero disables the limit.
+	APIScrapeRateLimit  int           `envconfig:"API_SCRAPE_RATE_LIMIT" default:"10"`
+	APIScrapeRateWindow time.Duration `envconfig:"API_SCRAPE_RATE_WINDOW" default:"1h"`
+
+	// Serve the read-only web dashboard at /dashboard/
+	APIDashboard bool `envconfig:"API_DASHBOARD" default:"true"`
+
+	// Serve the read-only GraphQL endpoint over stored data at
+	// /api/v1/graphql
+	APIGraphQL bool `envconfig:"API_GRAPHQL" default:"false"`
+
+	// External systems allowed to queue scrapes through signed requests to
+	// /api/v1/hooks/<name>/scrape, as SCRAPE_HOOKS=payroll=<secret>,...
+	// Each acts in the default profile unless SCRAPE_HOOK_PROFILES sets
+	// another, as SCRAPE_HOOK_PROFILES=payroll:acme.
+	ScrapeHooks        []string          `envconfig:"SCRAPE_HOOKS"`
+	ScrapeHookProfiles map[string]string `envconfig:"SCRAPE_HOOK_PROFILES"`
+
+	// Scrape jobs — "memory" keeps jobs in process, "postgres" persists them
+	// so queued jobs survive a restart. A zero interval disables the
+	// scheduler. SCRAPE_WORKERS caps concurrent jobs overall;
+	// SCRAPE_BANK_CONCURRENCY caps them per bank, with per-bank overrides
+	// as SCRAPE_BANK_LIMITS=BBVA:1,BCP:2. Finished jobs, whose results hold
+	// every scraped transaction, are deleted SCRAPE_JOB_RETENTION after
+	// they finish; zero keeps them.
+	ScrapeJobStore        string         `envconfig:"SCRAPE_JOB_STORE" default:"memory"`
+	ScrapeJobRetention    time.Duration  `envconfig:"SCRAPE_JOB_RETENTION" default:"168h"`
+	ScrapeWorkers         int            `envconfig:"SCRAPE_WORKERS" default:"1"`
+	ScrapeBankConcurrency int            `envconfig:"SCRAPE_BANK_CONCURRENCY" default:"1"`
+	ScrapeBankLimits      map[string]int `envconfig:"SCRAPE_BANK_LIMITS"`
+	ScrapeInterval        time.Duration  `envconfig:"SCRAPE_INTERVAL" default:"0"`
+
+	// Profiles the scheduler scrapes, each with its own credentials,
+	// accounts, API keys and jobs (e.g. one per client company), as
+	// PROFILES=default,acme. PROFILE_SCRAPE_INTERVALS overrides
+	// SCRAPE_INTERVAL per profile, as PROFILE_SCRAPE_INTERVALS=acme:1h.
+	Profiles               []string                 `envconfig:"PROFILES" default:"default"`
+	ProfileScrapeIntervals map[string]time.Duration `envconfig:"PROFILE_SCRAPE_INTERVALS"`
+
+	// Banking calendar the scheduler follows ("PE", or empty for none). On
+	// weekends and holidays, when banks post nothing, it scrapes every
+	// SCRAPE_QUIET_INTERVAL instead, or not at all when that is zero.
+	// SCRAPE_HOLIDAYS adds one-off non-working days, as
+	// SCRAPE_HOLIDAYS=2026-06-08,2026-12-24.
+	ScrapeCalendar      string        `envconfig:"SCRAPE_CALENDAR"`
+	ScrapeHolidays      []string      `envconfig:"SCRAPE_HOLIDAYS"`
+	ScrapeQuietInterval time.Duration `envconfig:"SCRAPE_QUIET_INTERVAL" default:"0"`
+
+	// Exports of stored data, on a schedule independent of scraping:
+	// EXPORT_CSV_DIR gets one CSV per account, EXPORT_PARQUET_DIR a
+	// transactions and a daily balances Parquet table per profile, both
+	// rewritten every EXPORT_INTERVAL. A zero interval disables scheduled
+	// exports.
+	ExportCSVDir     string        `envconfig:"EXPORT_CSV_DIR"`
+	ExportParquetDir string        `envconfig:"EXPORT_PARQUET_DIR"`
+	ExportInterval   time.Duration `envconfig:"EXPORT_INTERVAL" default:"0"`
+
+	// Firefly III instance that exports push transactions to, every
+	// EXPORT_INTERVAL, with a personal access token. FIREFLY_ACCOUNTS maps
+	// bank accounts, keyed as ACCOUNT_ALIASES, to Firefly asset account IDs,
+	// as FIREFLY_ACCOUNTS=4607:12; unmapped accounts aren't pushed.
+	FireflyURL      string            `envconfig:"FIREFLY_URL"`
+	FireflyToken    string            `envconfig:"FIREFLY_TOKEN"`
+	FireflyAccounts map[string]string `envconfig:"FIREFLY_ACCOUNTS"`
+
+	// General-ledger codes for the exports' gl_debit and gl_credit columns:
+	// per bank account, keyed as ACCOUNT_ALIASES, as GL_ACCOUNTS=4607:104101,
+	// and per category (a tax tag, or "other"), as
+	// GL_CATEGORIES=itf:6411,detraccion:40111,other:1039.
+	GLAccounts   map[string]string `envconfig:"GL_ACCOUNTS"`
+	GLCategories map[string]string `envconfig:"GL_CATEGORIES"`
+
+	// YAML file setting the steps each scrape job runs per account (scrape,
+	// backfill, dedupe, categorize, enrich, store, export, notify), per
+	// profile; see package pipeline. Without it jobs only scrape.
+	PipelineConfig string `envconfig:"PIPELINE_CONFIG"`
+
+	// How far (an amount, as 1.50) the balance may move beyond what the
+	// scraped transactions explain before the pipeline's backfill step
+	// flags a gap and fetches deeper. Absorbs rounding and pending holds.
+	BackfillGapThreshold string `envconfig:"BACKFILL_GAP_THRESHOLD" default:"1.00"`
+
+	// Webhooks new transactions are POSTed to, as
+	// NOTIFY_WEBHOOKS=ops=https://...,finance=https://... ("=" because URLs
+	// contain ":"). Each channel gets one message per transaction unless
+	// NOTIFY_DIGEST sets it to a daily or weekly digest, as
+	// NOTIFY_DIGEST=finance:weekly.
+	NotifyWebhooks []string          `envconfig:"NOTIFY_WEBHOOKS"`
+	NotifyDigest   map[string]string `envconfig:"NOTIFY_DIGEST"`
+
+	// Flag unusual transactions (odd amounts, new payees, odd hours) in
+	// notifications
+	NotifyAnomalies bool `envconfig:"NOTIFY_ANOMALIES" default:"true"`
+
+	// How old each account's stored data may get before the notify channels
+	// get a staleness alert, keyed by account number (or its last four or
+	// more digits) or account type, as FRESHNESS_SLOS=4607:2h,savings:24h.
+	// Weekends and holidays of SCRAPE_CALENDAR don't count.
+	FreshnessSLOs map[string]time.Duration `envconfig:"FRESHNESS_SLOS"`
+
+	// Send full account numbers in notifications and export files. By
+	// default they carry only the last four digits.
+	FullAccountIDs bool `envconfig:"FULL_ACCOUNT_IDS" default:"false"`
+
 	// Cookie security (set to false for local dev without HTTPS)
 	SecureCookies bool `envconfig:"CREDMGR_SECURE_COOKIES" default:"false"`
 
 	// Scraper settings
-	ScraperTimeout  time.Duration `envconfig:"SCRAPER_TIMEOUT" default:"30s"`
-	ScraperHeadless bool          `envconfig:"SCRAPER_HEADLESS" default:"true"`
+	ScraperTimeout    time.Duration `envconfig:"SCRAPER_TIMEOUT" default:"30s"`
+	ScraperHeadless   bool          `envconfig:"SCRAPER_HEADLESS" default:"true"`
+	ScraperNetworkLog bool          `envconfig:"SCRAPER_NETWORK_LOG"` // Record browser requests (no bodies) in job results
+	ScraperRawHTML    bool          `envconfig:"SCRAPER_RAW_HTML"`    // Keep the HTML each parser was given in job results
+
+	// Bank session limits overriding each scraper's own, per bank:
+	// BANK_SESSION_LIFETIMES caps a session however much it is used, as
+	// BANK_SESSION_LIFETIMES=BBVA:2h; BANK_SESSION_IDLE expires one left
+	// unused that long, as BANK_SESSION_IDLE=BBVA:5m. Zero lifts a limit.
+	BankSessionLifetimes map[string]time.Duration `envconfig:"BANK_SESSION_LIFETIMES"`
+	BankSessionIdle      map[string]time.Duration `envconfig:"BANK_SESSION_IDLE"`
+
+	// Proxy the scrapers' browsers go through, e.g. Tor's SocksPort as
+	// SCRAPER_PROXY=socks5://127.0.0.1:9050, with per-bank overrides as
+	// SCRAPER_BANK_PROXIES=BBVA=socks5://127.0.0.1:9052 ("=" because URLs
+	// contain ":"). Tor keeps streams on different SocksPorts on separate
+	// circuits, so a port per bank isolates the banks from each other.
+	ScraperProxy       string   `envconfig:"SCRAPER_PROXY"`
+	ScraperBankProxies []string `envconfig:"SCRAPER_BANK_PROXIES"`
+
+	// Experimental scraper behaviors to turn on, as BANK:flag entries, e.g.
+	// FEATURE_FLAGS=BBVA:legacy-login. They ship off; see each bank
+	// package's KnownFlags.
+	FeatureFlags []string `envconfig:"FEATURE_FLAGS"`
+
+	// Check where bank logins would leave from before each one:
+	// GEO_PREFLIGHT_URL returns the caller's IP and country as JSON (e.g.
+	// https://ipinfo.io/json) and is called through the bank's proxy. A
+	// login from outside GEO_PREFLIGHT_COUNTRY is warned about, or refused
+	// with GEO_PREFLIGHT_ENFORCE. Empty URL disables the check.
+	GeoPreflightURL     string `envconfig:"GEO_PREFLIGHT_URL"`
+	GeoPreflightCountry string `envconfig:"GEO_PREFLIGHT_COUNTRY" default:"PE"`
+	GeoPreflightEnforce bool   `envconfig:"GEO_PREFLIGHT_ENFORCE"`
+
+	// Chromium used by the scrapers.
+	Browser BrowserConfig `envconfig:"BROWSER"`
 
 	// Resilience
 	RetryMaxAttempts           uint64        `envconfig:"RETRY_MAX_ATTEMPTS" default:"3"`
@@ -38,6 +198,10 @@ type Config struct {
 	CircuitBreakerMaxFailures  uint32        `envconfig:"CB_MAX_FAILURES" default:"5"`
 	CircuitBreakerResetTimeout time.Duration `envconfig:"CB_RESET_TIMEOUT" default:"5m"`
 
+	// Reporting — soles per dollar for PEN-equivalent totals. Zero leaves USD
+	// out of the total instead of guessing a rate.
+	USDPENRate float64 `envconfig:"FX_USD_PEN"`
+
 	// BBVA — backwards compatible with existing env vars.
 	// Will move to DB-managed credentials in a future milestone.
 	BBVA BBVAConfig `envconfig:"BBVA"`
@@ -51,6 +215,108 @@ type BBVAConfig struct {
 	Password    string `envconfig:"PASSWORD"`
 }
 
+// BrowserConfig says where the scrapers' Chromium comes from.
+// Env vars: BROWSER_POLICY, BROWSER_BIN, BROWSER_CACHE_DIR, BROWSER_REVISION,
+// BROWSER_SHA256, BROWSER_REMOTE_URL, BROWSER_TIMEZONE.
+type BrowserConfig struct {
+	// "auto" (installed browser, else download), "system" (installed only,
+	// fail fast) or "download" (always the pinned revision)
+	Policy   string `envconfig:"POLICY" default:"auto"`
+	Bin      string `envconfig:"BIN"`       // Explicit binary; overrides the policy
+	CacheDir string `envconfig:"CACHE_DIR"` // Downloaded revisions; default user cache dir
+	Revision int    `envconfig:"REVISION"`  // Revision to download; default the release's pinned one
+	SHA256   string `envconfig:"SHA256"`    // Expected checksum of the downloaded binary
+
+	// Containerized Chrome to connect to instead of launching one; the
+	// settings above are ignored when set
+	RemoteURL string `envconfig:"REMOTE_URL"`
+	Timezone  string `envconfig:"TIMEZONE"` // Remote pages' IANA zone; default America/Lima
+}
+
+// Redacted returns a copy of b with the remote browser URL's userinfo and
+// query, where browserless tokens go, masked.
+func (b BrowserConfig) Redacted() BrowserConfig {
+	b.RemoteURL = redactURL(b.RemoteURL)
+	return b
+}
+
+// LoadBrowser reads just the browser settings, for tools that run without
+// the rest of the configuration (e.g. doctor).
+func LoadBrowser() (*BrowserConfig, error) {
+	var cfg BrowserConfig
+	if err := envconfig.Process("BROWSER", &cfg); err != nil {
+		return nil, fmt.Errorf("load browser config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// redacted replaces the secret part of a value Redacted keeps.
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of c without secrets: the encryption keys, BBVA
+// credentials, the Firefly III token, the database password, the scrape
+// hooks' signing secrets and the notify webhooks' paths and queries, where
+// Slack, Discord and Telegram URLs carry their tokens. The proxies, remote
+// browser and geo preflight URLs keep all but their userinfo and queries,
+// where proxy passwords, browserless tokens and API keys go.
+func (c Config) Redacted() Config {
+	c.EncryptionKey = ""
+	c.ProfileEncryptionKeys = nil
+	c.BBVA = BBVAConfig{}
+	c.FireflyToken = ""
+	hooks := make([]string, len(c.ScrapeHooks))
+	for i, hook := range c.ScrapeHooks {
+		name, _, _ := strings.Cut(hook, "=")
+		hooks[i] = name + "=" + redacted
+	}
+	c.ScrapeHooks = hooks
+	webhooks := make([]string, len(c.NotifyWebhooks))
+	for i, webhook := range c.NotifyWebhooks {
+		name, raw, _ := strings.Cut(webhook, "=")
+		webhooks[i] = name + "=" + redacted
+		if u, err := url.Parse(raw); err == nil && u.Scheme != "" && u.Host != "" {
+			webhooks[i] = name + "=" + u.Scheme + "://" + u.Host + "/" + redacted
+		}
+	}
+	c.NotifyWebhooks = webhooks
+	c.ScraperProxy = redactURL(c.ScraperProxy)
+	proxies := make([]string, len(c.ScraperBankProxies))
+	for i, entry := range c.ScraperBankProxies {
+		code, raw, _ := strings.Cut(entry, "=")
+		proxies[i] = code + "=" + redactURL(raw)
+	}
+	c.ScraperBankProxies = proxies
+	c.Browser = c.Browser.Redacted()
+	c.GeoPreflightURL = redactURL(c.GeoPreflightURL)
+	switch u, err := url.Parse(c.DatabaseURL); {
+	case err != nil:
+		c.DatabaseURL = ""
+	case u.User != nil:
+		u.User = url.User(u.User.Username())
+		c.DatabaseURL = u.String()
+	}
+	return c
+}
+
+// redactURL masks the userinfo and query of raw, or all of it if it doesn't
+// parse.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	user, query := u.User != nil, u.RawQuery != ""
+	u.User, u.RawQuery, u.Fragment = nil, "", ""
+	out := u.String()
+	if user {
+		out = strings.Replace(out, "//", "//"+redacted+"@", 1)
+	}
+	if query {
+		out += "?" + redacted
+	}
+	return out
+}
+
 // Load reads configuration from environment variables.
 func Load() (*Config, error) {
 	var cfg Config
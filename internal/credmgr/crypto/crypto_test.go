@@ -252,3 +252,23 @@ func testMasterKeyAlt(t *testing.T) MasterKey {
 	require.NoError(t, err)
 	return mk
 }
+
+func TestFieldCipher(t *testing.T) {
+	var mk MasterKey
+	copy(mk[:], bytes.Repeat([]byte{7}, keySize))
+	c := NewFieldCipher(mk)
+
+	encData, encDEK, err := c.Seal([]byte("PAGO FACTURA"))
+	require.NoError(t, err)
+	got, err := c.Open(encData, encDEK)
+	require.NoError(t, err)
+	assert.Equal(t, "PAGO FACTURA", string(got))
+
+	assert.Equal(t, c.MAC([]byte("x")), NewFieldCipher(mk).MAC([]byte("x")), "deterministic for a key")
+	assert.NotEqual(t, c.MAC([]byte("x")), c.MAC([]byte("y")))
+
+	var other MasterKey
+	assert.NotEqual(t, c.MAC([]byte("x")), NewFieldCipher(other).MAC([]byte("x")))
+	_, err = NewFieldCipher(other).Open(encData, encDEK)
+	assert.Error(t, err)
+}
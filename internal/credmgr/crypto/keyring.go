@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// profileKeyLabel domain-separates derived profile keys from other uses of
+// the root key.
+const profileKeyLabel = "bank-scraper/profile-key/v1/"
+
+// Keyring holds a master key per profile, so one profile's credentials and
+// stored data can't be decrypted with another's key. A profile without a
+// configured key gets one derived from the root key. The root profile — the
+// one that predates profiles — keeps the root key itself, so data encrypted
+// before profiles existed stays readable.
+type Keyring struct {
+	root        MasterKey
+	rootProfile string
+	keys        map[string]MasterKey
+}
+
+// NewKeyring creates a Keyring deriving every profile's key from root, except
+// rootProfile's, which is root. keys overrides the key of the listed
+// profiles, e.g. for a client that supplies its own.
+func NewKeyring(root MasterKey, rootProfile string, keys map[string]MasterKey) *Keyring {
+	return &Keyring{root: root, rootProfile: rootProfile, keys: keys}
+}
+
+// LoadKeyring parses the root key and a profile → key map, both as 64-character
+// hex (see ParseMasterKey), and returns their Keyring.
+func LoadKeyring(rootHex, rootProfile string, profileHex map[string]string) (*Keyring, error) {
+	root, err := ParseMasterKey(rootHex)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]MasterKey, len(profileHex))
+	for profile, hexKey := range profileHex {
+		if keys[profile], err = ParseMasterKey(hexKey); err != nil {
+			return nil, fmt.Errorf("key of profile %s: %w", profile, err)
+		}
+	}
+	return NewKeyring(root, rootProfile, keys), nil
+}
+
+// Key returns profile's master key.
+func (k *Keyring) Key(profile string) MasterKey {
+	if mk, ok := k.keys[profile]; ok {
+		return mk
+	}
+	if profile == k.rootProfile {
+		return k.root
+	}
+	m := hmac.New(sha256.New, k.root[:])
+	m.Write([]byte(profileKeyLabel + profile))
+	var mk MasterKey
+	copy(mk[:], m.Sum(nil))
+	return mk
+}
+
+// FieldCipher returns the FieldCipher of profile's key.
+func (k *Keyring) FieldCipher(profile string) *FieldCipher {
+	return NewFieldCipher(k.Key(profile))
+}
@@ -0,0 +1,44 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRootHex = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestKeyring_Key(t *testing.T) {
+	own := "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+	k, err := LoadKeyring(testRootHex, "default", map[string]string{"globex": own})
+	require.NoError(t, err)
+	root, _ := ParseMasterKey(testRootHex)
+	globex, _ := ParseMasterKey(own)
+
+	assert.Equal(t, root, k.Key("default"), "root profile keeps the root key")
+	assert.Equal(t, globex, k.Key("globex"))
+	assert.NotEqual(t, root, k.Key("acme"))
+	assert.NotEqual(t, k.Key("acme"), k.Key("initech"))
+	assert.Equal(t, k.Key("acme"), k.Key("acme"), "derivation is deterministic")
+}
+
+func TestKeyring_ProfilesCantOpenEachOther(t *testing.T) {
+	k, err := LoadKeyring(testRootHex, "default", nil)
+	require.NoError(t, err)
+
+	encData, encDEK, err := k.FieldCipher("acme").Seal([]byte("PAGO FACTURA"))
+	require.NoError(t, err)
+
+	_, err = k.FieldCipher("default").Open(encData, encDEK)
+	assert.Error(t, err)
+	got, err := k.FieldCipher("acme").Open(encData, encDEK)
+	require.NoError(t, err)
+	assert.Equal(t, "PAGO FACTURA", string(got))
+	assert.NotEqual(t, k.FieldCipher("acme").MAC([]byte("h")), k.FieldCipher("default").MAC([]byte("h")))
+}
+
+func TestLoadKeyring_InvalidProfileKey(t *testing.T) {
+	_, err := LoadKeyring(testRootHex, "default", map[string]string{"acme": "abcd"})
+	assert.ErrorContains(t, err, "profile acme")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -96,6 +97,7 @@ func (h *CredentialHandler) Create(c *gin.Context) {
 			"EditMode":   false,
 			"FormAction": "/credentials",
 			"Error":      "Failed to create credential. Please check your input and try again.",
+			"Profile":    cred.Profile,
 			"BankCode":   cred.BankCode,
 			"Label":      cred.Label,
 		})
@@ -125,6 +127,7 @@ func (h *CredentialHandler) Edit(c *gin.Context) {
 		"Title":      "Edit Credential",
 		"EditMode":   true,
 		"FormAction": "/credentials/" + id.String() + "/update",
+		"Profile":    found.Profile,
 		"BankCode":   found.BankCode,
 		"Label":      found.Label,
 	})
@@ -211,7 +214,8 @@ func (h *CredentialHandler) Accounts(c *gin.Context) {
 	var accounts []store.Account
 	if h.accounts != nil {
 		bankCode := found.BankCode
-		accounts, err = h.accounts.List(c.Request.Context(), store.AccountFilter{BankCode: &bankCode})
+		ctx := store.WithProfile(c.Request.Context(), found.Profile)
+		accounts, err = h.accounts.List(ctx, store.AccountFilter{BankCode: &bankCode})
 		if err != nil {
 			h.log.Error("list accounts failed", slog.Any("error", err))
 			setFlash(c, flashError, "Failed to load accounts")
@@ -249,7 +253,9 @@ func (h *CredentialHandler) Discover(c *gin.Context) {
 		return
 	}
 
-	fields, err := h.creds.GetCredentials(c.Request.Context(), found.BankCode)
+	// Discovered accounts belong to the credential's profile.
+	ctx := store.WithProfile(c.Request.Context(), found.Profile)
+	fields, err := h.creds.GetCredentials(ctx, found.BankCode)
 	if err != nil {
 		h.log.Error("decrypt credentials failed", slog.String("id", id.String()), slog.Any("error", err))
 		setFlash(c, flashError, "Failed to decrypt credentials")
@@ -257,7 +263,7 @@ func (h *CredentialHandler) Discover(c *gin.Context) {
 		return
 	}
 
-	accounts, err := h.discoverer.Discover(c.Request.Context(), found.BankCode, fields, id)
+	accounts, err := h.discoverer.Discover(ctx, found.BankCode, fields, id)
 	if err != nil {
 		h.log.Error("account discovery failed",
 			slog.String("bank", found.BankCode),
@@ -280,6 +286,7 @@ func parsePlaintextCredential(c *gin.Context) service.PlaintextCredential {
 		}
 	}
 	return service.PlaintextCredential{
+		Profile:  strings.ToLower(strings.TrimSpace(c.PostForm("profile"))),
 		BankCode: c.PostForm("bank_code"),
 		Label:    c.PostForm("label"),
 		Fields:   fields,
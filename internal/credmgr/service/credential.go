@@ -1,6 +1,7 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -29,6 +30,7 @@ const (
 
 // PlaintextCredential represents bank credentials before encryption.
 type PlaintextCredential struct {
+	Profile  string // Owning profile; empty means store.DefaultProfile
 	BankCode string
 	Label    string
 	Fields   map[string]string // Bank-specific: company_code, user_code, password, etc.
@@ -37,6 +39,7 @@ type PlaintextCredential struct {
 // CredentialSummary is the safe view returned to the UI (no secrets).
 type CredentialSummary struct {
 	ID        uuid.UUID
+	Profile   string
 	BankCode  string
 	Label     string
 	Version   int
@@ -54,33 +57,40 @@ type CredentialTester interface {
 type CredentialService struct {
 	creds  store.CredentialRepository
 	aw     *AuditWriter
-	mk     crypto.MasterKey
+	keys   *crypto.Keyring
 	tester CredentialTester
 	logger *slog.Logger
 }
 
-// NewCredentialService creates a new CredentialService.
+// NewCredentialService creates a new CredentialService. Each credential is
+// encrypted with its profile's key from keys.
 func NewCredentialService(
 	creds store.CredentialRepository,
 	aw *AuditWriter,
-	mk crypto.MasterKey,
+	keys *crypto.Keyring,
 	tester CredentialTester,
 	logger *slog.Logger,
 ) *CredentialService {
 	return &CredentialService{
 		creds:  creds,
 		aw:     aw,
-		mk:     mk,
+		keys:   keys,
 		tester: tester,
 		logger: logger,
 	}
 }
 
 // Create encrypts and stores a new bank credential. Returns the new credential ID.
-// Returns ErrDuplicateBank if an active credential already exists for the same bank.
+// Returns ErrDuplicateBank if an active credential already exists for the same
+// bank in the credential's profile.
 func (s *CredentialService) Create(ctx context.Context, cred PlaintextCredential, userID uuid.UUID, ip, ua string) (uuid.UUID, error) {
-	// Enforce one credential per bank
-	_, err := s.creds.GetActiveByBankCode(ctx, cred.BankCode)
+	profile := cmp.Or(cred.Profile, store.DefaultProfile)
+	if err := store.ValidateProfile(profile); err != nil {
+		return uuid.Nil, fmt.Errorf("create credential: %w", err)
+	}
+
+	// Enforce one credential per bank and profile
+	_, err := s.creds.GetActiveByBankCode(store.WithProfile(ctx, profile), cred.BankCode)
 	if err == nil {
 		return uuid.Nil, fmt.Errorf("create credential: %w", ErrDuplicateBank)
 	}
@@ -88,12 +98,13 @@ func (s *CredentialService) Create(ctx context.Context, cred PlaintextCredential
 		return uuid.Nil, fmt.Errorf("check existing credential: %w", err)
 	}
 
-	encData, encDEK, err := s.encryptFields(cred.Fields)
+	encData, encDEK, err := s.encryptFields(profile, cred.Fields)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("encrypt credential: %w", err)
 	}
 
 	c := &store.BankCredential{
+		Profile:        profile,
 		BankCode:       cred.BankCode,
 		AccountLabel:   cred.Label,
 		CredentialsEnc: encData,
@@ -110,6 +121,7 @@ func (s *CredentialService) Create(ctx context.Context, cred PlaintextCredential
 
 	s.logger.Info("credential created",
 		slog.String("credential_id", c.ID.String()),
+		slog.String("profile", profile),
 		slog.String("bank_code", cred.BankCode))
 
 	return c.ID, nil
@@ -126,6 +138,7 @@ func (s *CredentialService) List(ctx context.Context, userID uuid.UUID, ip, ua s
 	for i, c := range creds {
 		summaries[i] = CredentialSummary{
 			ID:        c.ID,
+			Profile:   c.Profile,
 			BankCode:  c.BankCode,
 			Label:     c.AccountLabel,
 			Version:   c.Version,
@@ -140,15 +153,21 @@ func (s *CredentialService) List(ctx context.Context, userID uuid.UUID, ip, ua s
 	return summaries, nil
 }
 
-// Update re-encrypts and updates an existing credential.
+// Update re-encrypts and updates an existing credential. It stays in its
+// profile, under that profile's key.
 func (s *CredentialService) Update(ctx context.Context, id uuid.UUID, cred PlaintextCredential, userID uuid.UUID, ip, ua string) error {
-	encData, encDEK, err := s.encryptFields(cred.Fields)
+	stored, err := s.creds.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("update credential: %w", err)
+	}
+	encData, encDEK, err := s.encryptFields(stored.Profile, cred.Fields)
 	if err != nil {
 		return fmt.Errorf("update credential: %w", err)
 	}
 
 	c := &store.BankCredential{
 		ID:             id,
+		Profile:        stored.Profile,
 		BankCode:       cred.BankCode,
 		AccountLabel:   cred.Label,
 		CredentialsEnc: encData,
@@ -207,7 +226,7 @@ func (s *CredentialService) TestByID(ctx context.Context, id uuid.UUID, userID u
 		return fmt.Errorf("get credential: %w", err)
 	}
 
-	fields, err := s.decryptFields(stored.CredentialsEnc, stored.CredentialsDEK)
+	fields, err := s.decryptFields(stored.Profile, stored.CredentialsEnc, stored.CredentialsDEK)
 	if err != nil {
 		return fmt.Errorf("decrypt credential: %w", err)
 	}
@@ -220,7 +239,8 @@ func (s *CredentialService) TestByID(ctx context.Context, id uuid.UUID, userID u
 	return testErr
 }
 
-// GetCredentials fetches and decrypts the active credential for a bank.
+// GetCredentials fetches and decrypts the active credential for a bank in
+// the context's profile (see store.WithProfile).
 // Returns ErrCredentialNotConfigured if no active credential exists for the bank code.
 func (s *CredentialService) GetCredentials(ctx context.Context, bankCode string) (map[string]string, error) {
 	stored, err := s.creds.GetActiveByBankCode(ctx, bankCode)
@@ -231,7 +251,7 @@ func (s *CredentialService) GetCredentials(ctx context.Context, bankCode string)
 		return nil, fmt.Errorf("get credentials for %s: %w", bankCode, err)
 	}
 
-	fields, err := s.decryptFields(stored.CredentialsEnc, stored.CredentialsDEK)
+	fields, err := s.decryptFields(stored.Profile, stored.CredentialsEnc, stored.CredentialsDEK)
 	if err != nil {
 		return nil, fmt.Errorf("decrypt credentials for %s: %w", bankCode, err)
 	}
@@ -239,13 +259,14 @@ func (s *CredentialService) GetCredentials(ctx context.Context, bankCode string)
 	return fields, nil
 }
 
-// encryptFields marshals credential fields to JSON and encrypts with envelope encryption.
-func (s *CredentialService) encryptFields(fields map[string]string) (encData, encDEK []byte, err error) {
+// encryptFields marshals credential fields to JSON and encrypts with envelope
+// encryption under profile's key.
+func (s *CredentialService) encryptFields(profile string, fields map[string]string) (encData, encDEK []byte, err error) {
 	plaintext, err := json.Marshal(fields)
 	if err != nil {
 		return nil, nil, fmt.Errorf("marshal fields: %w", err)
 	}
-	encData, encDEK, err = crypto.Seal(s.mk, plaintext)
+	encData, encDEK, err = crypto.Seal(s.keys.Key(cmp.Or(profile, store.DefaultProfile)), plaintext)
 	if err != nil {
 		return nil, nil, fmt.Errorf("encrypt fields: %w", err)
 	}
@@ -253,8 +274,8 @@ func (s *CredentialService) encryptFields(fields map[string]string) (encData, en
 }
 
 // decryptFields decrypts and unmarshals credential fields from the database.
-func (s *CredentialService) decryptFields(encData, encDEK []byte) (map[string]string, error) {
-	plaintext, err := crypto.Open(s.mk, encData, encDEK)
+func (s *CredentialService) decryptFields(profile string, encData, encDEK []byte) (map[string]string, error) {
+	plaintext, err := crypto.Open(s.keys.Key(cmp.Or(profile, store.DefaultProfile)), encData, encDEK)
 	if err != nil {
 		return nil, fmt.Errorf("decrypt fields: %w", err)
 	}
@@ -92,11 +92,11 @@ func (r *fakeCredentialRepo) SoftDelete(_ context.Context, id, deletedBy uuid.UU
 	return nil
 }
 
-func (r *fakeCredentialRepo) GetActiveByBankCode(_ context.Context, bankCode string) (*store.BankCredential, error) {
+func (r *fakeCredentialRepo) GetActiveByBankCode(ctx context.Context, bankCode string) (*store.BankCredential, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	for _, c := range r.creds {
-		if c.BankCode == bankCode && c.Status == store.CredentialStatusActive {
+		if c.Profile == store.ProfileFrom(ctx) && c.BankCode == bankCode && c.Status == store.CredentialStatusActive {
 			clone := *c
 			return &clone, nil
 		}
@@ -163,7 +163,7 @@ func newTestCredentialService(
 ) *CredentialService {
 	mk, _ := crypto.ParseMasterKey("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
 	aw := NewAuditWriter(auditRepo, slog.Default())
-	return NewCredentialService(credRepo, aw, mk, tester, slog.Default())
+	return NewCredentialService(credRepo, aw, crypto.NewKeyring(mk, store.DefaultProfile, nil), tester, slog.Default())
 }
 
 // --- tests ---
@@ -209,7 +209,7 @@ func TestCredentialService_Create_CanDecrypt(t *testing.T) {
 	auditRepo := newFakeAuditRepo()
 	mk, _ := crypto.ParseMasterKey("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
 	aw := NewAuditWriter(auditRepo, slog.Default())
-	svc := NewCredentialService(credRepo, aw, mk, &fakeTester{}, slog.Default())
+	svc := NewCredentialService(credRepo, aw, crypto.NewKeyring(mk, store.DefaultProfile, nil), &fakeTester{}, slog.Default())
 
 	ctx := context.Background()
 	cred := PlaintextCredential{
@@ -230,6 +230,30 @@ func TestCredentialService_Create_CanDecrypt(t *testing.T) {
 	assert.Equal(t, "mysecret", fields["password"])
 }
 
+func TestCredentialService_Create_ProfileKey(t *testing.T) {
+	credRepo := newFakeCredentialRepo()
+	mk, _ := crypto.ParseMasterKey("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	keys := crypto.NewKeyring(mk, store.DefaultProfile, nil)
+	svc := NewCredentialService(credRepo, NewAuditWriter(newFakeAuditRepo(), slog.Default()), keys, &fakeTester{}, slog.Default())
+
+	ctx := context.Background()
+	cred := PlaintextCredential{Profile: "acme", BankCode: "BBVA", Label: "Acme", Fields: map[string]string{"password": "acme-secret"}}
+	id, err := svc.Create(ctx, cred, uuid.New(), "10.0.0.1", "TestAgent")
+	require.NoError(t, err)
+
+	stored, _ := credRepo.GetByID(ctx, id)
+	_, err = crypto.Open(mk, stored.CredentialsEnc, stored.CredentialsDEK)
+	assert.Error(t, err, "sealed under the profile's key, not the root key")
+	_, err = crypto.Open(keys.Key("acme"), stored.CredentialsEnc, stored.CredentialsDEK)
+	require.NoError(t, err)
+
+	_, err = svc.GetCredentials(ctx, "BBVA")
+	assert.ErrorIs(t, err, ErrCredentialNotConfigured, "not visible from the default profile")
+	fields, err := svc.GetCredentials(store.WithProfile(ctx, "acme"), "BBVA")
+	require.NoError(t, err)
+	assert.Equal(t, "acme-secret", fields["password"])
+}
+
 func TestCredentialService_List(t *testing.T) {
 	credRepo := newFakeCredentialRepo()
 	auditRepo := newFakeAuditRepo()
@@ -0,0 +1,186 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// Code is the synthetic bank's code. No real bank uses it, so demo data
+// can't be mistaken for scraped data.
+const Code bank.Code = "DEMO"
+
+// historyDays is how much history a new Bank has.
+const historyDays = 60
+
+// Bank is a synthetic bank.Scraper: a few accounts with weeks of made-up
+// but plausible activity, always logged in. Advance adds a day of activity,
+// as if time passed between two scrapes. The data only depends on the seed
+// and the day the bank was created.
+type Bank struct {
+	mu       sync.Mutex
+	rng      *rand.Rand
+	day      time.Time // next day Advance fills
+	seq      int       // last document number handed out
+	accounts []*account
+}
+
+type account struct {
+	number   string
+	currency bank.Currency
+	balance  int64
+	txns     []bank.Transaction // oldest first
+}
+
+// NewBank creates a Bank whose history ends the day before today.
+func NewBank(seed uint64, today time.Time) *Bank {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	b := &Bank{
+		rng: rand.New(rand.NewPCG(seed, seed)),
+		day: today.AddDate(0, 0, -historyDays),
+		accounts: []*account{
+			{number: "PE00DEMO0100000001", currency: bank.CurrencyPEN, balance: 4_250_000},
+			{number: "PE00DEMO0100000002", currency: bank.CurrencyPEN, balance: 980_000},
+			{number: "PE00DEMO0200000003", currency: bank.CurrencyUSD, balance: 1_520_000},
+		},
+	}
+	for range historyDays {
+		b.advance(0)
+	}
+	return b
+}
+
+// Advance adds a day of activity, at least one transaction, to every
+// account.
+func (b *Bank) Advance() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(1)
+}
+
+// movement is a kind of synthetic transaction, with its amount range in
+// whole units of the account's currency.
+type movement struct {
+	description string
+	typ         bank.TransactionType
+	min, max    int64
+}
+
+var movements = []movement{
+	{"ABONO CLIENTE COMERCIAL ANDINA SAC", bank.TransactionCredit, 500, 9_000},
+	{"ABONO CLIENTE DISTRIBUIDORA NORTE EIRL", bank.TransactionCredit, 200, 4_000},
+	{"TRANSF. RECIBIDA DE OTRO BANCO", bank.TransactionCredit, 100, 2_500},
+	{"PAGO PROVEEDOR SUMINISTROS LIMA SAC", bank.TransactionDebit, 300, 6_000},
+	{"PAGO PLANILLA HABERES", bank.TransactionDebit, 1_500, 12_000},
+	{"PAGO SUNAT TRIBUTOS", bank.TransactionDebit, 100, 3_000},
+	{"PAGO SERVICIOS LUZ DEL SUR", bank.TransactionDebit, 80, 600},
+	{"COMISION MANTENIMIENTO CUENTA", bank.TransactionDebit, 10, 30},
+}
+
+// itfThreshold is the smallest debit, in cents, charged the ITF tax.
+const itfThreshold = 100_000
+
+// advance adds a day of activity with at least `least` transactions per
+// account.
+func (b *Bank) advance(least int) {
+	for _, a := range b.accounts {
+		for range least + b.rng.IntN(4-least) {
+			m := movements[b.rng.IntN(len(movements))]
+			amount := (m.min + b.rng.Int64N(m.max-m.min+1)) * 100
+			if a.currency == bank.CurrencyUSD {
+				amount /= 4 // keep dollar accounts at dollar-sized amounts
+			}
+			amount += b.rng.Int64N(100)
+			typ := m.typ
+			if typ == bank.TransactionDebit && amount > a.balance {
+				m, typ = movements[0], bank.TransactionCredit
+			}
+			b.post(a, m.description, amount, typ)
+			// ITF: 0.005% of the debit, at least one cent.
+			if typ == bank.TransactionDebit && amount >= itfThreshold {
+				b.post(a, "ITF", max(1, amount*5/100_000), bank.TransactionDebit)
+			}
+		}
+	}
+	b.day = b.day.AddDate(0, 0, 1)
+}
+
+func (b *Bank) post(a *account, description string, amount int64, typ bank.TransactionType) {
+	if typ == bank.TransactionDebit {
+		a.balance -= amount
+	} else {
+		a.balance += amount
+	}
+	b.seq++
+	balance := a.balance
+	a.txns = append(a.txns, bank.Transaction{
+		ID:           fmt.Sprintf("%08d", b.seq),
+		Date:         b.day,
+		ValueDate:    b.day,
+		Description:  description,
+		Amount:       amount,
+		Type:         typ,
+		BalanceAfter: &balance,
+	})
+}
+
+// Accounts returns the numbers of the bank's accounts.
+func (b *Bank) Accounts() []string {
+	out := make([]string, len(b.accounts))
+	for i, a := range b.accounts {
+		out[i] = a.number
+	}
+	return out
+}
+
+// Login implements bank.Scraper. Any credentials are accepted.
+func (b *Bank) Login(_ context.Context, _ map[string]string) (*bank.Session, error) {
+	return &bank.Session{ID: "demo", Code: Code, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+// GetBalance implements bank.Scraper.
+func (b *Bank) GetBalance(_ context.Context) ([]bank.Balance, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]bank.Balance, len(b.accounts))
+	for i, a := range b.accounts {
+		out[i] = bank.Balance{
+			AccountID:        a.number,
+			Currency:         a.currency,
+			AvailableBalance: a.balance,
+			CurrentBalance:   a.balance,
+			FetchedAt:        time.Now(),
+		}
+	}
+	return out, nil
+}
+
+// GetTransactions implements bank.Scraper.
+func (b *Bank) GetTransactions(ctx context.Context, accountID string, count int) ([]bank.Transaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := slices.IndexFunc(b.accounts, func(a *account) bool { return a.number == accountID })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", bank.ErrAccountNotFound, accountID)
+	}
+	txns := slices.Clone(b.accounts[i].txns)
+	slices.Reverse(txns)
+	if len(txns) > count {
+		txns = txns[:count]
+	}
+	bank.ReportProgress(ctx, bank.PageProgress{Operation: "GetTransactions", Pages: 1, Rows: len(txns), Target: count})
+	return txns, nil
+}
+
+// Logout implements bank.Scraper.
+func (b *Bank) Logout(_ context.Context) error { return nil }
+
+// Close implements bank.Scraper.
+func (b *Bank) Close() error { return nil }
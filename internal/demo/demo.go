@@ -0,0 +1,205 @@
+// Package demo runs the whole pipeline (scrape, store, export, notify)
+// against a synthetic bank, so new users can evaluate the CSV exports and
+// webhook integrations without bank credentials or a database.
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/api/notify"
+	"github.com/aynifx/bank-scraper/internal/export"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// transactionCount is how many transactions each scrape asks for, enough
+// for the whole synthetic history.
+const transactionCount = 250
+
+// Options configures Run.
+type Options struct {
+	Dir      string           // CSV export directory (required)
+	Channels []notify.Channel // Webhooks to notify; empty starts a local receiver
+	Seed     uint64           // Varies the synthetic data
+	Now      time.Time        // Day the synthetic history leads up to; zero for today
+	Logger   *slog.Logger
+}
+
+// Result is what a demo run did.
+type Result struct {
+	Bank      bank.Code `json:"bank"`
+	Accounts  int       `json:"accounts"`
+	History   int       `json:"history"`  // Transactions stored by the first scrape
+	New       int       `json:"new"`      // Transactions the second scrape found
+	Notified  int       `json:"notified"` // Messages the local receiver got; 0 with Options.Channels
+	ExportDir string    `json:"export_dir"`
+}
+
+// Run scrapes the synthetic bank twice, a day apart, as the scheduler
+// would. The first scrape stores the accounts and their history; the second
+// stores the day's new transactions, which go out as notifications. Then
+// everything stored is exported to CSV under opts.Dir.
+//
+// Without opts.Channels, notifications go to a receiver on a local port
+// that logs each message, so the payloads can be seen without a webhook
+// endpoint.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("demo: export directory is required")
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	res := &Result{Bank: Code}
+	channels := opts.Channels
+	if len(channels) == 0 {
+		url, stop, err := receive(logger, &res.Notified)
+		if err != nil {
+			return nil, err
+		}
+		defer stop()
+		channels = []notify.Channel{{Name: "local", URL: url, Mode: notify.ModeInstant}}
+	}
+
+	b := NewBank(opts.Seed, now)
+	st := NewStore()
+	dedup := store.DedupConfig{}
+	hub := events.NewHub(events.DefaultHistory)
+	scrapers := events.NewProvider(provider{b}, hub).WithDedup(dedup)
+
+	notifyDone := notify.NewNotifier(hub, channels, logger).WithAnomalyDetection(report.NewAnomalyDetector()).Start(ctx)
+	// Closing the hub ends the notifier once it has sent everything.
+	closeHub := func() {
+		hub.Close()
+		<-notifyDone
+	}
+
+	scraper, err := scrapers.GetScraper(ctx, Code)
+	if err != nil {
+		closeHub()
+		return nil, err
+	}
+	balances, err := scraper.GetBalance(ctx)
+	if err != nil {
+		closeHub()
+		return nil, err
+	}
+	accounts := make([]store.Account, len(balances))
+	for i, bal := range balances {
+		accounts[i] = store.Account{BankCode: string(Code), AccountNumber: bal.AccountID, Currency: string(bal.Currency), AccountType: store.AccountTypeChecking}
+	}
+	if err := st.UpsertBatch(ctx, uuid.Nil, accounts); err != nil {
+		closeHub()
+		return nil, err
+	}
+	res.Accounts = len(accounts)
+
+	scrape := func() (int, error) {
+		stored, err := st.List(ctx, store.AccountFilter{})
+		if err != nil {
+			return 0, err
+		}
+		total := 0
+		for _, a := range stored {
+			txns, err := scraper.GetTransactions(ctx, a.AccountNumber, transactionCount)
+			if err != nil {
+				return total, fmt.Errorf("%s: %w", a.AccountNumber, err)
+			}
+			n, err := st.InsertBatch(ctx, dedup.For(a.BankCode).NewTransactions(a.ID, store.TransactionSourceScrape, txns))
+			if err != nil {
+				return total, err
+			}
+			if err := st.UpdateLastSynced(ctx, a.ID); err != nil {
+				return total, err
+			}
+			total += n
+		}
+		return total, nil
+	}
+	if res.History, err = scrape(); err != nil {
+		closeHub()
+		return nil, fmt.Errorf("first scrape: %w", err)
+	}
+	logger.Info("demo: stored history", slog.Int("accounts", res.Accounts), slog.Int("transactions", res.History))
+
+	b.Advance()
+	res.New, err = scrape()
+	closeHub()
+	if err != nil {
+		return nil, fmt.Errorf("second scrape: %w", err)
+	}
+	logger.Info("demo: stored new transactions", slog.Int("transactions", res.New))
+
+	if err := export.NewExporter(export.CSVDir{Dir: opts.Dir}, st, st, logger).Run(ctx); err != nil {
+		return nil, err
+	}
+	res.ExportDir = filepath.Join(opts.Dir, store.DefaultProfile)
+	return res, nil
+}
+
+// provider hands out the synthetic bank, as the session manager hands out
+// logged-in scrapers.
+type provider struct{ bank *Bank }
+
+func (p provider) GetScraper(_ context.Context, code bank.Code) (bank.Scraper, error) {
+	if code != Code {
+		return nil, fmt.Errorf("demo: unsupported bank: %s", code)
+	}
+	return p.bank, nil
+}
+
+func (p provider) Invalidate(context.Context, bank.Code) {}
+
+// receive starts a webhook receiver on a local port that logs the messages
+// it gets and counts them in n. It returns the receiver's URL and a func
+// stopping it.
+func receive(logger *slog.Logger, n *int) (string, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, fmt.Errorf("demo: start webhook receiver: %w", err)
+	}
+	var count atomic.Int64
+	srv := &http.Server{
+		ReadHeaderTimeout: 5 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var m notify.Message
+			if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			count.Add(1)
+			attrs := []any{slog.String("type", m.Type)}
+			if m.Event != nil {
+				attrs = append(attrs, slog.String("account", m.Event.AccountID), slog.Any("transaction", m.Event.Data))
+			}
+			if len(m.Anomalies) > 0 {
+				attrs = append(attrs, slog.Any("anomalies", m.Anomalies))
+			}
+			logger.Info("demo: webhook received", attrs...)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+	go func() { _ = srv.Serve(ln) }()
+	return "http://" + ln.Addr().String() + "/", func() {
+		_ = srv.Close()
+		*n = int(count.Load())
+	}, nil
+}
@@ -0,0 +1,116 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/notify"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	today = time.Date(2026, 3, 16, 10, 0, 0, 0, time.UTC)
+	quiet = slog.New(slog.DiscardHandler)
+)
+
+func TestRun_LocalReceiver(t *testing.T) {
+	dir := t.TempDir()
+	res, err := Run(context.Background(), Options{Dir: dir, Seed: 1, Now: today, Logger: quiet})
+	require.NoError(t, err)
+
+	assert.Equal(t, Code, res.Bank)
+	assert.Equal(t, 3, res.Accounts)
+	assert.Greater(t, res.History, 60)
+	assert.GreaterOrEqual(t, res.New, 3, "every account moves on the new day")
+	assert.Equal(t, res.New, res.Notified, "one instant message per new transaction")
+	assert.Equal(t, filepath.Join(dir, store.DefaultProfile), res.ExportDir)
+
+	files, err := filepath.Glob(filepath.Join(res.ExportDir, "DEMO_*.csv"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "2026-03-16", "the new day is exported")
+}
+
+func TestRun_Channels(t *testing.T) {
+	var got atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m notify.Message
+		if json.NewDecoder(r.Body).Decode(&m) == nil && m.Type == "digest" {
+			got.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	res, err := Run(context.Background(), Options{
+		Dir:      t.TempDir(),
+		Channels: []notify.Channel{{Name: "erp", URL: srv.URL, Mode: notify.ModeDaily}},
+		Now:      today,
+		Logger:   quiet,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, res.Notified)
+	assert.Equal(t, int64(1), got.Load(), "pending digest sent when the run ends")
+}
+
+func TestBank_Deterministic(t *testing.T) {
+	txns := func(seed uint64) []bank.Transaction {
+		b := NewBank(seed, today)
+		out, err := b.GetTransactions(context.Background(), b.Accounts()[0], 500)
+		require.NoError(t, err)
+		return out
+	}
+	a := txns(7)
+	assert.Equal(t, a, txns(7))
+	assert.NotEqual(t, a, txns(8))
+	assert.True(t, a[0].Date.Before(today.Truncate(24*time.Hour)), "history ends before today")
+	assert.False(t, a[0].Date.Before(a[len(a)-1].Date), "newest first")
+
+	_, err := NewBank(7, today).GetTransactions(context.Background(), "nope", 10)
+	assert.ErrorIs(t, err, bank.ErrAccountNotFound)
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+	require.NoError(t, s.UpsertBatch(ctx, [16]byte{}, []store.Account{{BankCode: "DEMO", AccountNumber: "1", Currency: "PEN"}}))
+	require.NoError(t, s.UpsertBatch(ctx, [16]byte{}, []store.Account{{BankCode: "DEMO", AccountNumber: "1", Currency: "USD"}}))
+	accounts, err := s.List(ctx, store.AccountFilter{})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "USD", accounts[0].Currency)
+	assert.Equal(t, store.AccountStatusActive, accounts[0].Status)
+
+	other, err := s.List(store.WithProfile(ctx, "acme"), store.AccountFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, other, "accounts are scoped to their profile")
+
+	recs := store.NewTransactions(accounts[0].ID, store.TransactionSourceScrape, []bank.Transaction{
+		{Date: today, Description: "ITF", Amount: 5, Type: bank.TransactionDebit},
+		{Date: today.AddDate(0, 0, -1), Description: "ABONO", Amount: 100, Type: bank.TransactionCredit},
+	})
+	n, err := s.InsertBatch(ctx, recs)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = s.InsertBatch(ctx, recs)
+	require.NoError(t, err)
+	assert.Zero(t, n, "already stored")
+
+	stored, err := s.ListByAccount(ctx, accounts[0].ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.True(t, strings.HasPrefix(stored[0].Description, "ABONO"), "oldest first")
+}
@@ -0,0 +1,146 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// Store keeps accounts and transactions in process memory, for runs without
+// a database. Like the Postgres repositories, accounts are scoped to the
+// context's profile and a transaction already stored for its account (same
+// dedup hash) is skipped. Satisfies store.AccountRepository and
+// store.TransactionRepository.
+type Store struct {
+	mu       sync.Mutex
+	accounts []store.Account
+	txns     map[uuid.UUID][]store.Transaction
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{txns: make(map[uuid.UUID][]store.Transaction)}
+}
+
+// Create stores a new account, assigning its ID.
+func (s *Store) Create(ctx context.Context, a *store.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	a.ID, a.Profile, a.SchemaVersion, a.CreatedAt, a.UpdatedAt = uuid.New(), store.ProfileFrom(ctx), schema.Version, now, now
+	if a.Status == "" {
+		a.Status = store.AccountStatusActive
+	}
+	if a.Priority == "" {
+		a.Priority = store.PriorityNormal
+	}
+	s.accounts = append(s.accounts, *a)
+	return nil
+}
+
+// GetByID returns the profile's account with the given ID.
+func (s *Store) GetByID(ctx context.Context, id uuid.UUID) (*store.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.accounts, func(a store.Account) bool { return a.ID == id && a.Profile == store.ProfileFrom(ctx) })
+	if i < 0 {
+		return nil, fmt.Errorf("account %s: %w", id, store.ErrNotFound)
+	}
+	a := s.accounts[i]
+	return &a, nil
+}
+
+// List returns the profile's accounts matching filter.
+func (s *Store) List(ctx context.Context, filter store.AccountFilter) ([]store.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.Account
+	for _, a := range s.accounts {
+		if a.Profile != store.ProfileFrom(ctx) ||
+			filter.BankCode != nil && a.BankCode != *filter.BankCode ||
+			filter.Currency != nil && a.Currency != *filter.Currency {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// UpsertBatch creates the accounts not stored yet and updates the others,
+// matching on bank and account number.
+func (s *Store) UpsertBatch(ctx context.Context, credentialID uuid.UUID, accounts []store.Account) error {
+	for _, a := range accounts {
+		s.mu.Lock()
+		i := slices.IndexFunc(s.accounts, func(b store.Account) bool {
+			return b.Profile == store.ProfileFrom(ctx) && b.BankCode == a.BankCode && b.AccountNumber == a.AccountNumber
+		})
+		if i >= 0 {
+			s.accounts[i].Currency, s.accounts[i].AccountType, s.accounts[i].CredentialID = a.Currency, a.AccountType, credentialID
+			s.accounts[i].UpdatedAt = time.Now()
+		}
+		s.mu.Unlock()
+		if i < 0 {
+			a.CredentialID = credentialID
+			if err := s.Create(ctx, &a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateLastSynced sets the account's last sync time to now.
+func (s *Store) UpdateLastSynced(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.accounts, func(a store.Account) bool { return a.ID == id && a.Profile == store.ProfileFrom(ctx) })
+	if i < 0 {
+		return fmt.Errorf("account %s: %w", id, store.ErrNotFound)
+	}
+	now := time.Now()
+	s.accounts[i].LastSyncedAt, s.accounts[i].UpdatedAt = &now, now
+	return nil
+}
+
+// InsertBatch stores the transactions not stored yet and returns how many
+// that was.
+func (s *Store) InsertBatch(_ context.Context, txns []store.Transaction) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inserted := 0
+	for _, t := range txns {
+		stored := s.txns[t.AccountID]
+		if slices.ContainsFunc(stored, func(o store.Transaction) bool { return bytes.Equal(o.DedupHash, t.DedupHash) }) {
+			continue
+		}
+		t.ID, t.CreatedAt = uuid.New(), time.Now()
+		if t.SchemaVersion == "" {
+			t.SchemaVersion = schema.Version
+		}
+		s.txns[t.AccountID] = append(stored, t)
+		inserted++
+	}
+	return inserted, nil
+}
+
+// ListByAccount returns the account's transactions, oldest first.
+func (s *Store) ListByAccount(_ context.Context, accountID uuid.UUID) ([]store.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := slices.Clone(s.txns[accountID])
+	slices.SortStableFunc(out, func(a, b store.Transaction) int { return a.OperationDate.Compare(b.OperationDate) })
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/money"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// csvHeader is the first row of every exported file.
+var csvHeader = []string{
+	"operation_date", "value_date", "description", "reference", "bank_id",
+	"type", "amount", "currency", "balance_after", "source", "tax_tag",
+}
+
+// CSVDir drops one CSV file per account in a folder, rewriting it on every
+// run: <dir>/<profile>/<bank>_<account number>.csv, the number masked to
+// its last 4 digits unless FullAccountIDs is set. Amounts are signed
+// decimals (debits negative). tax_tag marks detractions, IGV, ITF and other
+// tax payments (see report.TaxTagFor). With a GL mapping, gl_debit and
+// gl_credit columns follow with each movement's ledger accounts. Files are
+// replaced atomically, so a reader never sees a half-written one.
+type CSVDir struct {
+	Dir            string
+	GL             *GLMapping // Optional
+	FullAccountIDs bool       // Name files by the full account number
+}
+
+// Name implements Sink.
+func (CSVDir) Name() string { return "csv" }
+
+// Write implements Sink.
+func (s CSVDir) Write(_ context.Context, profile string, accounts []AccountExport) error {
+	dir := filepath.Join(s.Dir, profile)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	written := map[string]string{}
+	for _, a := range accounts {
+		name := fmt.Sprintf("%s_%s.csv", a.Account.BankCode, accountNumber(a.Account, s.FullAccountIDs))
+		if prev, dup := written[name]; dup {
+			return fmt.Errorf("accounts %s and %s would both export to %s; set FULL_ACCOUNT_IDS", prev, a.Account.ID, name)
+		}
+		written[name] = a.Account.ID.String()
+		if err := writeCSV(filepath.Join(dir, name), a, s.GL); err != nil {
+			return fmt.Errorf("account %s: %w", a.Account.ID, err)
+		}
+	}
+	return nil
+}
+
+// writeCSV writes a's transactions to path, atomically.
+func writeCSV(path string, a AccountExport, gl *GLMapping) error {
+	return writeFile(path, func(f io.Writer) error {
+		w := csv.NewWriter(f)
+		header := csvHeader
+		if gl != nil {
+			header = append(slices.Clip(header), "gl_debit", "gl_credit")
+		}
+		_ = w.Write(header)
+		for _, t := range a.Transactions {
+			record := csvRecord(a.Account.Currency, t)
+			if gl != nil {
+				debit, credit := gl.Entries(a.Account, t)
+				record = append(record, debit, credit)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	})
+}
+
+// writeFile has write fill a temporary file next to path, then renames it
+// over path, so readers never see a half-written file.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".export-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // No-op after the rename
+
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func csvRecord(currency string, t store.Transaction) []string {
+	amount := t.Amount
+	if t.Type == string(bank.TransactionDebit) {
+		amount = -amount
+	}
+	var valueDate, balance string
+	if t.ValueDate != nil {
+		valueDate = t.ValueDate.Format(time.DateOnly)
+	}
+	if t.BalanceAfter != nil {
+		balance = money.FormatCents(*t.BalanceAfter)
+	}
+	return []string{
+		t.OperationDate.Format(time.DateOnly), valueDate, t.Description, t.Reference, t.BankID,
+		strings.ToLower(t.Type), money.FormatCents(amount), currency, balance, t.Source,
+		string(report.TaxTagFor(t.Description)),
+	}
+}
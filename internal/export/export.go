@@ -0,0 +1,108 @@
+// Package export hands stored accounts and transactions to external
+// destinations (a folder of CSV files, an accounting tool) on a schedule of
+// its own. It reads from the store rather than from scrape results, so how
+// often reports go out doesn't depend on how often banks are scraped.
+package export
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// AccountExport is one account and its stored transactions, oldest first.
+type AccountExport struct {
+	Account      store.Account
+	Transactions []store.Transaction
+}
+
+// accountNumber returns how an export shows a's account number: masked to
+// its last 4 digits (bank.MaskAccountID) unless full is set.
+func accountNumber(a store.Account, full bool) string {
+	if full {
+		return a.AccountNumber
+	}
+	return bank.MaskAccountID(a.AccountNumber)
+}
+
+// Sink is an export destination. Write receives every active account of a
+// profile with its full history, so a sink that rewrites its output each
+// run needs no state of its own.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, profile string, accounts []AccountExport) error
+}
+
+// Exporter runs a Sink over the stored data of one profile.
+type Exporter struct {
+	sink     Sink
+	accounts store.AccountRepository
+	txns     store.TransactionRepository
+	profile  string
+	logger   *slog.Logger
+}
+
+// NewExporter creates an Exporter writing the default profile's data to
+// sink.
+func NewExporter(sink Sink, accounts store.AccountRepository, txns store.TransactionRepository, logger *slog.Logger) *Exporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Exporter{sink: sink, accounts: accounts, txns: txns, profile: store.DefaultProfile, logger: logger}
+}
+
+// WithProfile exports the data of profile instead of the default profile's.
+func (e *Exporter) WithProfile(profile string) *Exporter {
+	e.profile = profile
+	return e
+}
+
+// Run exports once.
+func (e *Exporter) Run(ctx context.Context) error {
+	ctx = store.WithProfile(ctx, e.profile)
+	accounts, err := e.accounts.List(ctx, store.AccountFilter{})
+	if err != nil {
+		return fmt.Errorf("list accounts: %w", err)
+	}
+
+	var out []AccountExport
+	for _, a := range accounts {
+		if a.Status != "" && a.Status != store.AccountStatusActive {
+			continue
+		}
+		txns, err := e.txns.ListByAccount(ctx, a.ID)
+		if err != nil {
+			return fmt.Errorf("list transactions of %s: %w", a.ID, err)
+		}
+		out = append(out, AccountExport{Account: a, Transactions: txns})
+	}
+
+	if err := e.sink.Write(ctx, e.profile, out); err != nil {
+		return fmt.Errorf("%s export: %w", e.sink.Name(), err)
+	}
+	return nil
+}
+
+// RunEvery exports on every tick of interval until ctx is cancelled, logging
+// failures. It does nothing if the interval isn't positive.
+func (e *Exporter) RunEvery(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Run(ctx); err != nil {
+				e.logger.Error("export failed", slog.String("sink", e.sink.Name()), slog.String("profile", e.profile), slog.Any("error", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccountRepo struct {
+	accounts []store.Account
+}
+
+func (m *mockAccountRepo) Create(_ context.Context, _ *store.Account) error { return nil }
+func (m *mockAccountRepo) GetByID(_ context.Context, _ uuid.UUID) (*store.Account, error) {
+	return nil, store.ErrNotFound
+}
+func (m *mockAccountRepo) UpsertBatch(_ context.Context, _ uuid.UUID, _ []store.Account) error {
+	return nil
+}
+func (m *mockAccountRepo) UpdateLastSynced(_ context.Context, _ uuid.UUID) error { return nil }
+
+func (m *mockAccountRepo) List(ctx context.Context, _ store.AccountFilter) ([]store.Account, error) {
+	var out []store.Account
+	for _, a := range m.accounts {
+		if a.Profile == store.ProfileFrom(ctx) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+type mockTransactionRepo struct {
+	txns map[uuid.UUID][]store.Transaction
+}
+
+func (m *mockTransactionRepo) InsertBatch(_ context.Context, _ []store.Transaction) (int, error) {
+	return 0, nil
+}
+
+func (m *mockTransactionRepo) ListByAccount(_ context.Context, id uuid.UUID) ([]store.Transaction, error) {
+	return m.txns[id], nil
+}
+
+func TestExporter_CSVDir(t *testing.T) {
+	active := store.Account{ID: uuid.New(), Profile: "acme", BankCode: "BBVA", AccountNumber: "0011", Currency: "PEN", Status: store.AccountStatusActive}
+	closed := store.Account{ID: uuid.New(), Profile: "acme", BankCode: "BBVA", AccountNumber: "0022", Currency: "PEN", Status: store.AccountStatusInactive}
+	other := store.Account{ID: uuid.New(), Profile: store.DefaultProfile, BankCode: "BBVA", AccountNumber: "0033", Currency: "USD"}
+	balance := int64(99_950)
+	txns := &mockTransactionRepo{txns: map[uuid.UUID][]store.Transaction{
+		active.ID: {
+			{OperationDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Description: "ABONO, CLIENTE", Amount: 100_000, Type: "CREDIT", Source: store.TransactionSourceScrape},
+			{OperationDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Description: "ITF", Amount: 50, Type: "DEBIT", BalanceAfter: &balance, Source: store.TransactionSourceScrape},
+		},
+	}}
+	dir := t.TempDir()
+	e := NewExporter(CSVDir{Dir: dir}, &mockAccountRepo{accounts: []store.Account{active, closed, other}}, txns, nil).WithProfile("acme")
+
+	require.NoError(t, e.Run(context.Background()))
+
+	got, err := os.ReadFile(filepath.Join(dir, "acme", "BBVA_0011.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "operation_date,value_date,description,reference,bank_id,type,amount,currency,balance_after,source,tax_tag\n"+
+		"2026-03-02,,\"ABONO, CLIENTE\",,,credit,1000.00,PEN,,scrape,\n"+
+		"2026-03-03,,ITF,,,debit,-0.50,PEN,999.50,scrape,itf\n", string(got))
+	entries, err := os.ReadDir(filepath.Join(dir, "acme"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "inactive accounts, other profiles and temp files left out")
+	assert.NoDirExists(t, filepath.Join(dir, store.DefaultProfile))
+}
+
+func TestCSVDir_GLColumns(t *testing.T) {
+	gl, err := ParseGLMapping(map[string]string{"0011": "104101"}, map[string]string{"itf": "6411"})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	a := AccountExport{
+		Account:      store.Account{BankCode: "BBVA", AccountNumber: "0011", Currency: "PEN"},
+		Transactions: []store.Transaction{{OperationDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Description: "ITF", Amount: 50, Type: "DEBIT"}},
+	}
+
+	require.NoError(t, CSVDir{Dir: dir, GL: gl}.Write(context.Background(), "acme", []AccountExport{a}))
+
+	got, err := os.ReadFile(filepath.Join(dir, "acme", "BBVA_0011.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "operation_date,value_date,description,reference,bank_id,type,amount,currency,balance_after,source,tax_tag,gl_debit,gl_credit\n"+
+		"2026-03-03,,ITF,,,debit,-0.50,PEN,,,itf,6411,104101\n", string(got))
+}
+
+func TestExporter_ParquetDir(t *testing.T) {
+	acc := store.Account{ID: uuid.New(), Profile: "acme", BankCode: "BBVA", AccountNumber: "0011", Currency: "PEN", Alias: "Operating"}
+	dir := t.TempDir()
+	e := NewExporter(ParquetDir{Dir: dir}, &mockAccountRepo{accounts: []store.Account{acc}}, &mockTransactionRepo{txns: map[uuid.UUID][]store.Transaction{
+		acc.ID: {{OperationDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Description: "ITF", Amount: 50, Type: "DEBIT"}},
+	}}, nil).WithProfile("acme")
+
+	require.NoError(t, e.Run(context.Background()))
+
+	for _, name := range []string{"transactions.parquet", "balances.parquet"} {
+		got, err := os.ReadFile(filepath.Join(dir, "acme", name))
+		require.NoError(t, err)
+		assert.Equal(t, "PAR1", string(got[:4]), name)
+	}
+}
+
+func TestBalanceRows(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	balance := func(n int64) *int64 { return &n }
+	rows := balanceRows(AccountExport{
+		Account: store.Account{BankCode: "BBVA", AccountNumber: "0011", Currency: "PEN"},
+		Transactions: []store.Transaction{
+			{OperationDate: day(2), BalanceAfter: balance(1_000)},
+			{OperationDate: day(2), BalanceAfter: balance(1_500)},
+			{OperationDate: day(3)},
+			{OperationDate: day(4), BalanceAfter: balance(900)},
+		},
+	}, true)
+
+	assert.Equal(t, [][]any{
+		{"BBVA", "0011", nil, "PEN", day(2), int64(1_500)},
+		{"BBVA", "0011", nil, "PEN", day(4), int64(900)},
+	}, rows, "the day's last balance; days without one left out")
+}
+
+func TestCSVDir_MasksAccountNumbers(t *testing.T) {
+	dir := t.TempDir()
+	a := AccountExport{Account: store.Account{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "0011-0100-0200004607", Currency: "PEN"}}
+
+	require.NoError(t, CSVDir{Dir: dir}.Write(context.Background(), "acme", []AccountExport{a}))
+	assert.FileExists(t, filepath.Join(dir, "acme", "BBVA_XXXXXXXXXXXXXXXX4607.csv"))
+	require.NoError(t, CSVDir{Dir: dir, FullAccountIDs: true}.Write(context.Background(), "acme", []AccountExport{a}))
+	assert.FileExists(t, filepath.Join(dir, "acme", "BBVA_0011-0100-0200004607.csv"))
+
+	b := AccountExport{Account: store.Account{ID: uuid.New(), BankCode: "BBVA", AccountNumber: "0011-0100-0200014607", Currency: "USD"}}
+	err := CSVDir{Dir: dir}.Write(context.Background(), "acme", []AccountExport{a, b})
+	assert.ErrorContains(t, err, "would both export to BBVA_XXXXXXXXXXXXXXXX4607.csv")
+
+	rows := balanceRows(AccountExport{Account: a.Account, Transactions: []store.Transaction{{BalanceAfter: new(int64)}}}, false)
+	assert.Equal(t, "XXXXXXXXXXXXXXXX4607", rows[0][1])
+}
@@ -0,0 +1,188 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/money"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// ErrInvalidFirefly is returned by NewFirefly for a bad URL, a missing token
+// or a bad account mapping.
+var ErrInvalidFirefly = errors.New("invalid Firefly III configuration")
+
+// fireflyDuplicate is how Firefly III rejects a transaction it already has
+// when asked to check (error_if_duplicate_hash).
+const fireflyDuplicate = "Duplicate of transaction"
+
+// Firefly pushes transactions to a Firefly III instance through its API, as
+// withdrawals from and deposits to the asset accounts bank accounts are
+// mapped to. Accounts without a mapping are left out.
+//
+// Every run offers the whole history: transactions pushed earlier by this
+// process are skipped, and Firefly III rejects the rest it already has by
+// their hash, so a restart pushes nothing twice. Each transaction carries
+// its dedup hash as external_id, to trace it back to the store.
+type Firefly struct {
+	url      string
+	token    string
+	accounts store.AccountAliases // Firefly asset account ID by account number or its last digits
+	client   *http.Client
+
+	mu     sync.Mutex
+	pushed map[string]bool // External IDs Firefly III has
+}
+
+// NewFirefly creates a Firefly sink for the instance at baseURL, e.g. from
+// FIREFLY_URL, authenticating with a personal access token. accounts maps
+// account numbers, or their last 4+ digits, to asset account IDs, e.g. from
+// FIREFLY_ACCOUNTS (4607:12).
+func NewFirefly(baseURL, token string, accounts map[string]string) (*Firefly, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: URL %q: want http(s)://host", ErrInvalidFirefly, baseURL)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("%w: missing token", ErrInvalidFirefly)
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("%w: no account mapped", ErrInvalidFirefly)
+	}
+	mapped, err := store.ParseAccountAliases(accounts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFirefly, err)
+	}
+	return &Firefly{
+		url:      strings.TrimSuffix(u.String(), "/"),
+		token:    token,
+		accounts: mapped,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		pushed:   map[string]bool{},
+	}, nil
+}
+
+// Name implements Sink.
+func (*Firefly) Name() string { return "firefly" }
+
+// Write implements Sink. It stops at the first transaction Firefly III
+// refuses for any reason but a duplicate; the next run retries it.
+func (f *Firefly) Write(ctx context.Context, _ string, accounts []AccountExport) error {
+	for _, a := range accounts {
+		assetID := f.accounts.For(a.Account.AccountNumber)
+		if assetID == "" {
+			continue
+		}
+		for _, t := range a.Transactions {
+			id := hex.EncodeToString(t.DedupHash)
+			if f.isPushed(id) {
+				continue
+			}
+			if err := f.push(ctx, toFireflySplit(assetID, a.Account, t, id)); err != nil {
+				return fmt.Errorf("account %s: %w", a.Account.ID, err)
+			}
+			f.markPushed(id)
+		}
+	}
+	return nil
+}
+
+func (f *Firefly) isPushed(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pushed[id]
+}
+
+func (f *Firefly) markPushed(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pushed[id] = true
+}
+
+// fireflyTransaction is the body of POST /api/v1/transactions.
+type fireflyTransaction struct {
+	ErrorIfDuplicateHash bool           `json:"error_if_duplicate_hash"`
+	ApplyRules           bool           `json:"apply_rules"`
+	Transactions         []fireflySplit `json:"transactions"`
+}
+
+// fireflySplit is one split of a Firefly III transaction; ours have one.
+type fireflySplit struct {
+	Type              string `json:"type"` // "withdrawal", "deposit"
+	Date              string `json:"date"`
+	Amount            string `json:"amount"` // Positive decimal
+	Description       string `json:"description"`
+	CurrencyCode      string `json:"currency_code,omitempty"`
+	SourceID          string `json:"source_id,omitempty"`
+	DestinationID     string `json:"destination_id,omitempty"`
+	ExternalID        string `json:"external_id"`
+	InternalReference string `json:"internal_reference,omitempty"`
+	Notes             string `json:"notes,omitempty"`
+}
+
+// toFireflySplit converts t on account a, mapped to asset account assetID. A
+// debit leaves the asset account; a credit enters it. The other side is left
+// for Firefly III to fill with its cash account.
+func toFireflySplit(assetID string, a store.Account, t store.Transaction, externalID string) fireflySplit {
+	s := fireflySplit{
+		Type:              "deposit",
+		Date:              t.OperationDate.Format(time.DateOnly),
+		Amount:            money.FormatCents(t.Amount),
+		Description:       cmp.Or(t.Description, strings.ToLower(t.Type)),
+		CurrencyCode:      a.Currency,
+		DestinationID:     assetID,
+		ExternalID:        externalID,
+		InternalReference: t.BankID,
+		Notes:             t.Reference,
+	}
+	if t.Type == string(bank.TransactionDebit) {
+		s.Type, s.SourceID, s.DestinationID = "withdrawal", assetID, ""
+	}
+	return s
+}
+
+// push creates one transaction. A duplicate counts as pushed.
+func (f *Firefly) push(ctx context.Context, split fireflySplit) error {
+	body, err := json.Marshal(fireflyTransaction{
+		ErrorIfDuplicateHash: true,
+		ApplyRules:           true,
+		Transactions:         []fireflySplit{split},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url+"/api/v1/transactions", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+f.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.api+json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnprocessableEntity && bytes.Contains(reply, []byte(fireflyDuplicate)):
+		return nil
+	default:
+		return fmt.Errorf("firefly: %s: %s", resp.Status, bytes.TrimSpace(reply))
+	}
+}
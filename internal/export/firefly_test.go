@@ -0,0 +1,119 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fireflyServer is a Firefly III API that knows the transactions in has,
+// by external ID, and fails every request while down is set.
+type fireflyServer struct {
+	mu       sync.Mutex
+	has      map[string]bool
+	down     bool
+	received []fireflySplit
+}
+
+func (s *fireflyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != "/api/v1/transactions" || r.Header.Get("Authorization") != "Bearer tok" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if s.down {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var body fireflyTransaction
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !body.ErrorIfDuplicateHash || len(body.Transactions) != 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	split := body.Transactions[0]
+	s.received = append(s.received, split)
+	if s.has[split.ExternalID] {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message":"Duplicate of transaction #7."}`))
+		return
+	}
+	s.has[split.ExternalID] = true
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *fireflyServer) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func TestNewFirefly_Invalid(t *testing.T) {
+	tests := []struct {
+		name, url, token string
+		accounts         map[string]string
+	}{
+		{name: "scheme", url: "ftp://firefly", token: "tok", accounts: map[string]string{"4607": "12"}},
+		{name: "no token", url: "https://firefly", accounts: map[string]string{"4607": "12"}},
+		{name: "no account", url: "https://firefly", token: "tok"},
+		{name: "short key", url: "https://firefly", token: "tok", accounts: map[string]string{"46": "12"}},
+	}
+	for _, tc := range tests {
+		_, err := NewFirefly(tc.url, tc.token, tc.accounts)
+		assert.ErrorIs(t, err, ErrInvalidFirefly, tc.name)
+	}
+}
+
+func TestFirefly_Write(t *testing.T) {
+	mapped := store.Account{ID: uuid.New(), AccountNumber: "0011-0130-0100014607", Currency: "PEN"}
+	unmapped := store.Account{ID: uuid.New(), AccountNumber: "0011-0130-0100019999", Currency: "USD"}
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	accounts := []AccountExport{
+		{Account: mapped, Transactions: []store.Transaction{
+			{DedupHash: []byte{1}, OperationDate: day, Description: "ABONO CLIENTE", Amount: 100_000, Type: "CREDIT", BankID: "000123"},
+			{DedupHash: []byte{2}, OperationDate: day, Description: "ITF", Amount: 50, Type: "DEBIT"},
+			{DedupHash: []byte{3}, OperationDate: day, Description: "COMISION", Amount: 1_200, Type: "DEBIT"},
+		}},
+		{Account: unmapped, Transactions: []store.Transaction{
+			{DedupHash: []byte{4}, OperationDate: day, Description: "ABONO", Amount: 500, Type: "CREDIT"},
+		}},
+	}
+	api := &fireflyServer{has: map[string]bool{"03": true}} // Pushed before a restart
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	f, err := NewFirefly(srv.URL+"/", "tok", map[string]string{"4607": "12"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, f.Write(ctx, store.DefaultProfile, accounts))
+	require.Len(t, api.received, 3, "unmapped accounts left out")
+	assert.Equal(t, fireflySplit{
+		Type: "deposit", Date: "2026-03-02", Amount: "1000.00", Description: "ABONO CLIENTE", CurrencyCode: "PEN",
+		DestinationID: "12", ExternalID: "01", InternalReference: "000123",
+	}, api.received[0])
+	assert.Equal(t, fireflySplit{
+		Type: "withdrawal", Date: "2026-03-02", Amount: "0.50", Description: "ITF", CurrencyCode: "PEN",
+		SourceID: "12", ExternalID: "02",
+	}, api.received[1])
+
+	require.NoError(t, f.Write(ctx, store.DefaultProfile, accounts))
+	assert.Len(t, api.received, 3, "pushed and duplicate transactions aren't offered again")
+
+	accounts[0].Transactions = append(accounts[0].Transactions,
+		store.Transaction{DedupHash: []byte{5}, OperationDate: day, Description: "ITF", Amount: 10, Type: "DEBIT"})
+	api.setDown(true)
+	assert.ErrorContains(t, f.Write(ctx, store.DefaultProfile, accounts), "500")
+	api.setDown(false)
+	require.NoError(t, f.Write(ctx, store.DefaultProfile, accounts))
+	require.Len(t, api.received, 4, "retried on the next run")
+	assert.Equal(t, "05", api.received[3].ExternalID)
+}
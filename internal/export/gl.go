@@ -0,0 +1,80 @@
+package export
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+)
+
+// ErrInvalidGLMapping is returned by ParseGLMapping for an unknown category,
+// a short account key or an empty code.
+var ErrInvalidGLMapping = errors.New("invalid GL mapping")
+
+// CategoryOther is the category of movements without a tax tag.
+const CategoryOther = "other"
+
+// glCategories are the categories a GLMapping may map: the tax tags, and
+// CategoryOther for the rest.
+var glCategories = []string{
+	string(report.TaxDetraction), string(report.TaxIGV), string(report.TaxITF), string(report.TaxPayment), CategoryOther,
+}
+
+// GLMapping assigns general-ledger account codes to exported transactions,
+// so accounting systems (Concar, Siigo) can import them as journal lines
+// without recoding: each bank account has its GL code (e.g. 104101), and
+// each category the code of the counterpart (ITF to 6411, detractions to
+// 40111, the rest to a suspense account). A credit debits the bank's code
+// and credits the counterpart; a debit the reverse.
+type GLMapping struct {
+	accounts   store.AccountAliases // GL code by account number or its last digits, matched as aliases are
+	categories map[string]string
+}
+
+// ParseGLMapping validates a mapping, e.g. from GL_ACCOUNTS (4607:104101)
+// and GL_CATEGORIES (itf:6411,other:1039). Without either it returns nil,
+// which maps nothing.
+func ParseGLMapping(accounts, categories map[string]string) (*GLMapping, error) {
+	if len(accounts) == 0 && len(categories) == 0 {
+		return nil, nil
+	}
+	m := &GLMapping{accounts: store.AccountAliases{}, categories: map[string]string{}}
+	for key, code := range accounts {
+		key, code = strings.TrimSpace(key), strings.TrimSpace(code)
+		if len(key) < 4 || code == "" {
+			return nil, fmt.Errorf("%w: account %q: want the number or its last 4+ digits, and a code", ErrInvalidGLMapping, key)
+		}
+		m.accounts[key] = code
+	}
+	for key, code := range categories {
+		key, code = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(code)
+		if !slices.Contains(glCategories, key) {
+			return nil, fmt.Errorf("%w: unknown category %q (use %s)", ErrInvalidGLMapping, key, strings.Join(glCategories, ", "))
+		}
+		if code == "" {
+			return nil, fmt.Errorf("%w: empty code for %s", ErrInvalidGLMapping, key)
+		}
+		m.categories[key] = code
+	}
+	return m, nil
+}
+
+// Entries returns the GL codes to debit and to credit for t on account a.
+// A side is empty when its account or category isn't mapped, as with a
+// nil mapping.
+func (m *GLMapping) Entries(a store.Account, t store.Transaction) (debit, credit string) {
+	if m == nil {
+		return "", ""
+	}
+	category := cmp.Or(string(report.TaxTagFor(t.Description)), CategoryOther)
+	bankCode, counterpart := m.accounts.For(a.AccountNumber), m.categories[category]
+	if t.Type == string(bank.TransactionDebit) {
+		return counterpart, bankCode
+	}
+	return bankCode, counterpart
+}
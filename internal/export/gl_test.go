@@ -0,0 +1,41 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGLMapping_Entries(t *testing.T) {
+	gl, err := ParseGLMapping(map[string]string{"4607": "104101"}, map[string]string{"ITF": "6411", "other": "1039"})
+	require.NoError(t, err)
+	acc := store.Account{AccountNumber: "PE0011000100064607"}
+
+	debit, credit := gl.Entries(acc, store.Transaction{Description: "ITF", Type: "DEBIT"})
+	assert.Equal(t, []string{"6411", "104101"}, []string{debit, credit}, "expense debited, bank credited")
+
+	debit, credit = gl.Entries(acc, store.Transaction{Description: "ABONO CLIENTE", Type: "CREDIT"})
+	assert.Equal(t, []string{"104101", "1039"}, []string{debit, credit})
+
+	debit, credit = gl.Entries(store.Account{AccountNumber: "PE0011000100069999"}, store.Transaction{Description: "PAGO SUNAT", Type: "DEBIT"})
+	assert.Equal(t, []string{"", ""}, []string{debit, credit}, "unmapped account and category")
+
+	var none *GLMapping
+	debit, credit = none.Entries(acc, store.Transaction{Type: "DEBIT"})
+	assert.Empty(t, debit+credit)
+}
+
+func TestParseGLMapping(t *testing.T) {
+	gl, err := ParseGLMapping(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, gl)
+
+	_, err = ParseGLMapping(map[string]string{"46": "104101"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidGLMapping)
+	_, err = ParseGLMapping(nil, map[string]string{"payroll": "6211"})
+	assert.ErrorIs(t, err, ErrInvalidGLMapping)
+	_, err = ParseGLMapping(nil, map[string]string{"itf": " "})
+	assert.ErrorIs(t, err, ErrInvalidGLMapping)
+}
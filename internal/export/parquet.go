@@ -0,0 +1,151 @@
+package export
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/parquet"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// transactionColumns are the columns of transactions.parquet.
+var transactionColumns = []parquet.Column{
+	{Name: "bank_code", Type: parquet.String},
+	{Name: "account_number", Type: parquet.String},
+	{Name: "account_alias", Type: parquet.String, Optional: true},
+	{Name: "currency", Type: parquet.String},
+	{Name: "operation_date", Type: parquet.Date},
+	{Name: "value_date", Type: parquet.Date, Optional: true},
+	{Name: "description", Type: parquet.String},
+	{Name: "reference", Type: parquet.String},
+	{Name: "bank_id", Type: parquet.String},
+	{Name: "type", Type: parquet.String},
+	{Name: "amount", Type: parquet.Cents},
+	{Name: "balance_after", Type: parquet.Cents, Optional: true},
+	{Name: "source", Type: parquet.String},
+	{Name: "tax_tag", Type: parquet.String, Optional: true},
+	{Name: "gl_debit", Type: parquet.String, Optional: true},
+	{Name: "gl_credit", Type: parquet.String, Optional: true},
+	{Name: "stored_at", Type: parquet.Timestamp},
+}
+
+// balanceColumns are the columns of balances.parquet.
+var balanceColumns = []parquet.Column{
+	{Name: "bank_code", Type: parquet.String},
+	{Name: "account_number", Type: parquet.String},
+	{Name: "account_alias", Type: parquet.String, Optional: true},
+	{Name: "currency", Type: parquet.String},
+	{Name: "date", Type: parquet.Date},
+	{Name: "balance", Type: parquet.Cents},
+}
+
+// ParquetDir writes a profile's data as two Parquet tables for analytics
+// tools, rewriting them on every run: <dir>/<profile>/transactions.parquet,
+// every transaction of every account, and balances.parquet, each account's
+// closing balance per day. The store keeps no balance snapshots, so the
+// balance history comes from the running balances transactions carry: days
+// without one are left out. Amounts are DECIMAL(18,2), debits negative, as
+// in the CSV export, and gl_debit and gl_credit are null without a GL
+// mapping. Account numbers are masked to their last 4 digits unless
+// FullAccountIDs is set. Files are replaced atomically.
+type ParquetDir struct {
+	Dir            string
+	GL             *GLMapping // Optional
+	FullAccountIDs bool       // Write full account numbers, not just their last 4 digits
+}
+
+// Name implements Sink.
+func (ParquetDir) Name() string { return "parquet" }
+
+// Write implements Sink.
+func (s ParquetDir) Write(_ context.Context, profile string, accounts []AccountExport) error {
+	dir := filepath.Join(s.Dir, profile)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	var txns, balances [][]any
+	for _, a := range accounts {
+		txns = append(txns, transactionRows(a, s.GL, s.FullAccountIDs)...)
+		balances = append(balances, balanceRows(a, s.FullAccountIDs)...)
+	}
+	if err := writeParquet(filepath.Join(dir, "transactions.parquet"), transactionColumns, txns); err != nil {
+		return err
+	}
+	return writeParquet(filepath.Join(dir, "balances.parquet"), balanceColumns, balances)
+}
+
+func writeParquet(path string, columns []parquet.Column, rows [][]any) error {
+	return writeFile(path, func(w io.Writer) error {
+		return parquet.Write(w, columns, rows)
+	})
+}
+
+func transactionRows(a AccountExport, gl *GLMapping, full bool) [][]any {
+	acc := a.Account
+	number := accountNumber(acc, full)
+	rows := make([][]any, len(a.Transactions))
+	for i, t := range a.Transactions {
+		amount := t.Amount
+		if t.Type == string(bank.TransactionDebit) {
+			amount = -amount
+		}
+		debit, credit := gl.Entries(acc, t)
+		rows[i] = []any{
+			acc.BankCode, number, optional(acc.Alias), acc.Currency,
+			t.OperationDate, optionalTime(t.ValueDate), t.Description, t.Reference, t.BankID,
+			strings.ToLower(t.Type), amount, optionalInt(t.BalanceAfter), t.Source,
+			optional(string(report.TaxTagFor(t.Description))), optional(debit), optional(credit), t.CreatedAt,
+		}
+	}
+	return rows
+}
+
+// balanceRows returns a's closing balance per operation date: the running
+// balance of the day's last transaction that has one.
+func balanceRows(a AccountExport, full bool) [][]any {
+	acc := a.Account
+	number := accountNumber(acc, full)
+	var (
+		rows [][]any
+		last string
+	)
+	for _, t := range a.Transactions {
+		if t.BalanceAfter == nil {
+			continue
+		}
+		row := []any{acc.BankCode, number, optional(acc.Alias), acc.Currency, t.OperationDate, *t.BalanceAfter}
+		if day := t.OperationDate.Format(time.DateOnly); day == last {
+			rows[len(rows)-1] = row
+		} else {
+			rows, last = append(rows, row), day
+		}
+	}
+	return rows
+}
+
+// optional returns s, or nil for a null when it's empty.
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func optionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func optionalInt(n *int64) any {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
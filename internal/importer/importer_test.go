@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+const bbvaCSV = "\ufeffMovimientos de la cuenta;;;;;;\n" +
+	"Cuenta:;0011-0339-0100012345;;;;;\n" +
+	";;;;;;\n" +
+	"F. Operación;F. Valor;Código;Nº. Doc.;Concepto;Importe;Oficina\n" +
+	"10-02-2026;10-02-2026;015;000123;*C/ PAGO FACTURA | SUNAT DETRACCIONES;-1,500.00;0437\n" +
+	"11-02-2026;12-02-2026;;000124;ABONO TRANSFERENCIA;25,000.50;\n" +
+	";;;;Total;23,500.50;\n"
+
+func TestReadCSV_BBVA(t *testing.T) {
+	txns, err := ReadCSV(strings.NewReader(bbvaCSV), BBVAMapping)
+	require.NoError(t, err)
+	require.Len(t, txns, 2, "preamble and totals rows are skipped")
+
+	assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), txns[0].Date)
+	assert.Equal(t, bank.TransactionDebit, txns[0].Type)
+	assert.Equal(t, int64(150_000), txns[0].Amount)
+	assert.Equal(t, "000123", txns[0].ID)
+	assert.Equal(t, "PAGO FACTURA | Sunat Detracciones", txns[0].CleanDescription)
+	assert.Equal(t, map[string]string{"Código": "015", "Oficina": "0437"}, txns[0].Extra)
+
+	assert.Equal(t, bank.TransactionCredit, txns[1].Type)
+	assert.Equal(t, int64(2_500_050), txns[1].Amount)
+	assert.Equal(t, time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC), txns[1].ValueDate)
+	assert.Nil(t, txns[1].Extra)
+}
+
+func TestReadCSV_GenericMapping(t *testing.T) {
+	m, err := ParseMapping("date=Fecha,description=Descripción,debit=Cargo,credit=Abono,balance=Saldo,decimal=comma,date_format=02/01/2006")
+	require.NoError(t, err)
+
+	csv := "Fecha,Descripcion,Cargo,Abono,Saldo\n" +
+		`05/01/2024,COMISION MANTENIMIENTO,"12,50","0,00","1.000,00"` + "\n" +
+		`06/01/2024,DEPOSITO,,"1.234,56","2.234,56"` + "\n"
+
+	txns, err := ReadCSV(strings.NewReader(csv), m)
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+
+	assert.Equal(t, bank.TransactionDebit, txns[0].Type)
+	assert.Equal(t, int64(1_250), txns[0].Amount)
+	require.NotNil(t, txns[0].BalanceAfter)
+	assert.Equal(t, int64(100_000), *txns[0].BalanceAfter)
+	assert.Equal(t, bank.TransactionCredit, txns[1].Type)
+	assert.Equal(t, int64(123_456), txns[1].Amount)
+}
+
+func TestReadCSV_Errors(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader("a;b;c\n1;2;3\n"), BBVAMapping)
+	assert.ErrorIs(t, err, ErrNoHeader)
+
+	bad := "F. Operación;Concepto;Importe\n31-02-2026;X;1.00\n"
+	_, err = ReadCSV(strings.NewReader(bad), BBVAMapping)
+	assert.ErrorIs(t, err, ErrInvalidRow)
+	assert.Contains(t, err.Error(), "row 2")
+}
+
+func TestParseMapping_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"missing date", "description=D,amount=A"},
+		{"missing amount", "date=F,description=D"},
+		{"amount and debit", "date=F,description=D,amount=A,debit=C"},
+		{"unknown key", "date=F,description=D,amount=A,currency=M"},
+		{"not a pair", "date=F,description"},
+		{"bad decimal", "date=F,description=D,amount=A,decimal=space"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMapping(tt.spec)
+			assert.ErrorIs(t, err, ErrInvalidMapping)
+		})
+	}
+}
+
+func TestReadXLSX_BBVA(t *testing.T) {
+	data := buildXLSX(t, map[string]string{
+		"xl/sharedStrings.xml": `<sst><si><t>F. Operación</t></si><si><t>Concepto</t></si><si><t>Importe</t></si>` +
+			`<si><r><t>PAGO </t></r><r><t>SERVICIO</t></r></si></sst>`,
+		"xl/styles.xml": `<styleSheet><cellXfs><xf numFmtId="0"/><xf numFmtId="14"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>` +
+			`<row><c r="A2" s="1"><v>46063</v></c><c r="B2" t="s"><v>3</v></c><c r="C2"><v>-80.5</v></c></row>` +
+			`<row><c r="A3" t="inlineStr"><is><t>11/02/2026</t></is></c><c r="B3" t="inlineStr"><is><t>ABONO</t></is></c><c r="D3"><v>x</v></c><c r="C3"><v>10</v></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+
+	txns, err := ReadXLSX(bytes.NewReader(data), int64(len(data)), BBVAMapping)
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+
+	assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), txns[0].Date, "date serial converted")
+	assert.Equal(t, "PAGO SERVICIO", txns[0].Description)
+	assert.Equal(t, bank.TransactionDebit, txns[0].Type)
+	assert.Equal(t, int64(8_050), txns[0].Amount)
+	assert.Equal(t, time.Date(2026, 2, 11, 0, 0, 0, 0, time.UTC), txns[1].Date)
+	assert.Equal(t, int64(1_000), txns[1].Amount)
+}
+
+func TestReadJSON(t *testing.T) {
+	arr := `[{"id":"1","date":"2026-02-10T00:00:00Z","description":"X","amount":100,"type":"DEBIT"}]`
+	txns, err := ReadJSON(strings.NewReader(arr))
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, int64(100), txns[0].Amount)
+
+	obj := `{"account_id":"x","transactions":[{"date":"2026-02-10T00:00:00Z","amount":5,"type":"CREDIT"}]}`
+	txns, err = ReadJSON(strings.NewReader(obj))
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, bank.TransactionCredit, txns[0].Type)
+
+	_, err = ReadJSON(strings.NewReader(`[{"amount":5,"type":"CREDIT"}]`))
+	assert.ErrorIs(t, err, ErrInvalidRow)
+	_, err = ReadJSON(strings.NewReader(`[{"date":"2026-02-10T00:00:00Z","type":"OTHER"}]`))
+	assert.ErrorIs(t, err, ErrInvalidRow)
+}
+
+func TestColumnIndex(t *testing.T) {
+	assert.Equal(t, 0, columnIndex("A1"))
+	assert.Equal(t, 25, columnIndex("Z9"))
+	assert.Equal(t, 27, columnIndex("AB12"))
+}
+
+// buildXLSX zips the given parts into a minimal workbook.
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,324 @@
+// Package importer reads manually downloaded bank statements (CSV, XLSX or
+// JSON) into bank.Transaction values, so historical data can seed the store
+// ahead of what the scrapers can still reach.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
+)
+
+// Sentinel errors for statement import.
+var (
+	ErrInvalidMapping = errors.New("invalid column mapping")
+	ErrNoHeader       = errors.New("header row not found")
+	ErrInvalidRow     = errors.New("invalid statement row")
+)
+
+// Mapping tells the tabular readers which statement columns hold which
+// transaction fields. Column names are matched against the header row
+// case- and accent-insensitively.
+//
+// Amounts come either from one signed Amount column (negative = debit) or
+// from separate Debit and Credit columns.
+type Mapping struct {
+	Date        string // Operation date, required
+	ValueDate   string
+	Description string // Required
+	Amount      string
+	Debit       string
+	Credit      string
+	ID          string // Bank document number
+	Reference   string
+	Balance     string
+	Extra       []string // Columns copied verbatim into Transaction.Extra
+
+	Layout parseutil.Layout // Amount separators and date layouts
+}
+
+// BBVAMapping matches the movements export of BBVA Peru's net cash portal
+// ("Descargar movimientos"), in CSV or XLSX.
+var BBVAMapping = Mapping{
+	Date:        "F. Operación",
+	ValueDate:   "F. Valor",
+	Description: "Concepto",
+	Amount:      "Importe",
+	ID:          "Nº. Doc.",
+	Balance:     "Saldo Contable",
+	Extra:       []string{"Código", "Oficina"},
+	Layout: parseutil.Layout{
+		DecimalSep:   '.',
+		ThousandsSep: ',',
+		DateLayouts:  []string{"02-01-2006", "02/01/2006"},
+	},
+}
+
+// Validate checks that the mapping names the required columns.
+func (m Mapping) Validate() error {
+	switch {
+	case m.Date == "":
+		return fmt.Errorf("%w: date column is required", ErrInvalidMapping)
+	case m.Description == "":
+		return fmt.Errorf("%w: description column is required", ErrInvalidMapping)
+	case m.Amount == "" && m.Debit == "" && m.Credit == "":
+		return fmt.Errorf("%w: amount, or debit/credit, column is required", ErrInvalidMapping)
+	case m.Amount != "" && (m.Debit != "" || m.Credit != ""):
+		return fmt.Errorf("%w: use either amount or debit/credit columns, not both", ErrInvalidMapping)
+	}
+	return nil
+}
+
+// ParseMapping parses a generic CSV mapping from a comma-separated list of
+// key=column pairs, e.g.
+//
+//	date=Fecha,description=Descripción,debit=Cargo,credit=Abono
+//
+// Keys are date, value_date, description, amount, debit, credit, id,
+// reference, balance and extra (repeatable). date_format adds a Go time
+// layout (default: the parseutil default layouts) and decimal=comma switches
+// to "1.234,56" amounts.
+func ParseMapping(spec string) (Mapping, error) {
+	m := Mapping{Layout: parseutil.DefaultLayout}
+	var layouts []string
+	for pair := range strings.SplitSeq(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Mapping{}, fmt.Errorf("%w: %q is not key=column", ErrInvalidMapping, pair)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "date":
+			m.Date = value
+		case "value_date":
+			m.ValueDate = value
+		case "description":
+			m.Description = value
+		case "amount":
+			m.Amount = value
+		case "debit":
+			m.Debit = value
+		case "credit":
+			m.Credit = value
+		case "id":
+			m.ID = value
+		case "reference":
+			m.Reference = value
+		case "balance":
+			m.Balance = value
+		case "extra":
+			m.Extra = append(m.Extra, value)
+		case "date_format":
+			layouts = append(layouts, value)
+		case "decimal":
+			switch strings.ToLower(value) {
+			case "comma":
+				m.Layout.DecimalSep, m.Layout.ThousandsSep = ',', '.'
+			case "dot":
+				m.Layout.DecimalSep, m.Layout.ThousandsSep = '.', ','
+			default:
+				return Mapping{}, fmt.Errorf("%w: decimal must be comma or dot, got %q", ErrInvalidMapping, value)
+			}
+		default:
+			return Mapping{}, fmt.Errorf("%w: unknown key %q", ErrInvalidMapping, key)
+		}
+	}
+	if layouts != nil {
+		m.Layout.DateLayouts = layouts
+	}
+	return m, m.Validate()
+}
+
+// columns resolves the mapping against a header row.
+type columns struct {
+	date, valueDate, description, amount, debit, credit, id, reference, balance int
+	extra                                                                       map[string]int
+}
+
+// resolve finds the mapped columns in header. ok is false when a required
+// column is missing, which the readers use to skip preamble rows.
+func (m Mapping) resolve(header []string) (columns, bool) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		if k := normalizeHeader(h); k != "" {
+			if _, dup := index[k]; !dup {
+				index[k] = i
+			}
+		}
+	}
+	find := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		if i, ok := index[normalizeHeader(name)]; ok {
+			return i
+		}
+		return -1
+	}
+
+	c := columns{
+		date:        find(m.Date),
+		valueDate:   find(m.ValueDate),
+		description: find(m.Description),
+		amount:      find(m.Amount),
+		debit:       find(m.Debit),
+		credit:      find(m.Credit),
+		id:          find(m.ID),
+		reference:   find(m.Reference),
+		balance:     find(m.Balance),
+		extra:       map[string]int{},
+	}
+	for _, name := range m.Extra {
+		if i := find(name); i >= 0 {
+			c.extra[strings.TrimSpace(header[i])] = i
+		}
+	}
+	ok := c.date >= 0 && c.description >= 0 && (c.amount >= 0 || c.debit >= 0 || c.credit >= 0)
+	return c, ok
+}
+
+// transaction builds a transaction from one data row.
+func (m Mapping) transaction(c columns, row []string) (bank.Transaction, error) {
+	cell := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var tx bank.Transaction
+	var err error
+	if tx.Date, err = m.Layout.ParseDate(cell(c.date)); err != nil {
+		return tx, fmt.Errorf("%w: date: %w", ErrInvalidRow, err)
+	}
+	if v := cell(c.valueDate); v != "" {
+		if tx.ValueDate, err = m.Layout.ParseDate(v); err != nil {
+			return tx, fmt.Errorf("%w: value date: %w", ErrInvalidRow, err)
+		}
+	}
+
+	var amount int64
+	if c.amount >= 0 {
+		if amount, err = m.Layout.ParseAmount(cell(c.amount)); err != nil {
+			return tx, fmt.Errorf("%w: amount: %w", ErrInvalidRow, err)
+		}
+	} else {
+		// Exports fill the unused side with blanks or "0.00".
+		var debit, credit int64
+		if v := cell(c.debit); v != "" {
+			if debit, err = m.Layout.ParseAmount(v); err != nil {
+				return tx, fmt.Errorf("%w: debit: %w", ErrInvalidRow, err)
+			}
+		}
+		if v := cell(c.credit); v != "" {
+			if credit, err = m.Layout.ParseAmount(v); err != nil {
+				return tx, fmt.Errorf("%w: credit: %w", ErrInvalidRow, err)
+			}
+		}
+		switch {
+		case debit != 0 && credit != 0:
+			return tx, fmt.Errorf("%w: both debit and credit are set", ErrInvalidRow)
+		case debit != 0:
+			amount = -abs(debit)
+		case credit != 0:
+			amount = abs(credit)
+		default:
+			return tx, fmt.Errorf("%w: no debit or credit amount", ErrInvalidRow)
+		}
+	}
+	tx.Type = bank.TransactionCredit
+	if amount < 0 {
+		tx.Type = bank.TransactionDebit
+	}
+	tx.Amount = abs(amount)
+
+	if v := cell(c.balance); v != "" {
+		balance, err := m.Layout.ParseAmount(v)
+		if err != nil {
+			return tx, fmt.Errorf("%w: balance: %w", ErrInvalidRow, err)
+		}
+		tx.BalanceAfter = &balance
+	}
+
+	tx.ID = cell(c.id)
+	tx.Reference = cell(c.reference)
+	tx.Description = cell(c.description)
+	tx.CleanDescription = parseutil.CleanDescription(tx.Description)
+	for name, i := range c.extra {
+		if v := cell(i); v != "" {
+			if tx.Extra == nil {
+				tx.Extra = map[string]string{}
+			}
+			tx.Extra[name] = v
+		}
+	}
+	return tx, nil
+}
+
+// fromRows converts a table to transactions. Rows before the header (report
+// titles, account info) are skipped, as are blank rows and trailing totals
+// rows without a date.
+func (m Mapping) fromRows(rows [][]string) ([]bank.Transaction, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	start := -1
+	var cols columns
+	for i, row := range rows {
+		if c, ok := m.resolve(row); ok {
+			start, cols = i+1, c
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("%w: no row has columns %q and %q", ErrNoHeader, m.Date, m.Description)
+	}
+
+	var txns []bank.Transaction
+	for i, row := range rows[start:] {
+		if blankRow(row) || cols.date >= len(row) || strings.TrimSpace(row[cols.date]) == "" {
+			continue
+		}
+		tx, err := m.transaction(cols, row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", start+i+1, err)
+		}
+		txns = append(txns, tx)
+	}
+	return txns, nil
+}
+
+// headerReplacer folds the accents and ordinal signs found in Spanish headers.
+var headerReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n", "º", "o", "°", "o",
+	"Á", "a", "É", "e", "Í", "i", "Ó", "o", "Ú", "u", "Ñ", "n",
+)
+
+func normalizeHeader(s string) string {
+	s = strings.TrimPrefix(s, "\ufeff") // UTF-8 BOM on the first cell of Excel CSVs
+	return strings.Join(strings.Fields(strings.ToLower(headerReplacer.Replace(s))), " ")
+}
+
+func blankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
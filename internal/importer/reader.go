@@ -0,0 +1,259 @@
+package importer
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// ReadCSV reads a CSV statement using m. The delimiter (comma, semicolon or
+// tab) is detected from the file; Excel's UTF-8 BOM is ignored.
+func ReadCSV(r io.Reader, m Mapping) ([]bank.Transaction, error) {
+	br := bufio.NewReader(r)
+	peek, _ := br.Peek(4096)
+
+	cr := csv.NewReader(br)
+	cr.Comma = detectDelimiter(peek)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	return m.fromRows(rows)
+}
+
+// detectDelimiter picks the most frequent of ; , and tab in the sample.
+// Spanish-locale Excel writes ';' because ',' is its decimal separator.
+func detectDelimiter(sample []byte) rune {
+	best, bestCount := ',', 0
+	for _, d := range []rune{';', ',', '\t'} {
+		if n := bytes.Count(sample, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// ReadXLSX reads the first worksheet of an XLSX statement using m. Dates
+// stored as Excel serial numbers are converted to "02/01/2006" text, so the
+// mapping's layouts should include that layout.
+func ReadXLSX(r io.ReaderAt, size int64, m Mapping) ([]bank.Transaction, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("read xlsx: %w", err)
+	}
+	rows, err := xlsxRows(zr)
+	if err != nil {
+		return nil, fmt.Errorf("read xlsx: %w", err)
+	}
+	return m.fromRows(rows)
+}
+
+// ReadJSON reads transactions serialized by this module: either a bare
+// array of bank.Transaction or an object with a "transactions" array.
+func ReadJSON(r io.Reader) ([]bank.Transaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read json: %w", err)
+	}
+
+	var txns []bank.Transaction
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var doc struct {
+			Transactions []bank.Transaction `json:"transactions"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+		txns = doc.Transactions
+	} else if err := json.Unmarshal(data, &txns); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	for i, tx := range txns {
+		if tx.Date.IsZero() {
+			return nil, fmt.Errorf("transaction %d: %w: missing date", i, ErrInvalidRow)
+		}
+		if tx.Type != bank.TransactionCredit && tx.Type != bank.TransactionDebit {
+			return nil, fmt.Errorf("transaction %d: %w: type %q", i, ErrInvalidRow, tx.Type)
+		}
+	}
+	return txns, nil
+}
+
+// xlsxRows returns the cell text of the workbook's first sheet. Only what
+// statement exports use is supported: shared and inline strings, numbers and
+// date-formatted serials.
+func xlsxRows(zr *zip.Reader) ([][]string, error) {
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, fmt.Errorf("shared strings: %w", err)
+		}
+		for _, si := range sst.Items {
+			shared = append(shared, si.String())
+		}
+	}
+
+	dateStyles, err := xlsxDateStyles(files["xl/styles.xml"])
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, ok := files["xl/worksheets/sheet1.xml"]
+	if !ok {
+		return nil, errors.New("workbook has no first worksheet")
+	}
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Style  int      `xml:"s,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(sheet, &ws); err != nil {
+		return nil, fmt.Errorf("worksheet: %w", err)
+	}
+
+	rows := make([][]string, 0, len(ws.Rows))
+	for _, r := range ws.Rows {
+		var row []string
+		for _, c := range r.Cells {
+			col := len(row)
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+
+			switch c.Type {
+			case "s":
+				i, err := strconv.Atoi(c.Value)
+				if err != nil || i < 0 || i >= len(shared) {
+					return nil, fmt.Errorf("cell %s: bad shared string index %q", c.Ref, c.Value)
+				}
+				row[col] = shared[i]
+			case "inlineStr":
+				row[col] = c.Inline.String()
+			default:
+				row[col] = c.Value
+				if dateStyles[c.Style] && c.Value != "" {
+					if serial, err := strconv.ParseFloat(c.Value, 64); err == nil {
+						row[col] = excelDate(serial)
+					}
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// xlsxText is a string item: plain text or rich-text runs.
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	for _, r := range t.Runs {
+		sb.WriteString(r.Text)
+	}
+	return sb.String()
+}
+
+// xlsxDateStyles returns which cell style indices format numbers as dates.
+func xlsxDateStyles(f *zip.File) (map[int]bool, error) {
+	out := map[int]bool{}
+	if f == nil {
+		return out, nil
+	}
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decodeZipXML(f, &styles); err != nil {
+		return nil, fmt.Errorf("styles: %w", err)
+	}
+
+	custom := map[int]bool{}
+	for _, nf := range styles.NumFmts {
+		code := strings.ToLower(nf.Code)
+		custom[nf.ID] = strings.Contains(code, "d") && strings.Contains(code, "y")
+	}
+	for i, xf := range styles.CellXfs {
+		id := xf.NumFmtID
+		// 14-22 and 45-47 are Excel's built-in date/time formats.
+		out[i] = (id >= 14 && id <= 22) || (id >= 45 && id <= 47) || custom[id]
+	}
+	return out, nil
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// columnIndex converts the letters of a cell reference ("C12") to a
+// zero-based column index.
+func columnIndex(ref string) int {
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
+
+// excelEpoch is day 0 of Excel's 1900 date system, shifted to absorb its
+// phantom 29 Feb 1900.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// excelDate formats an Excel serial day number (1900 date system) as
+// DD/MM/YYYY.
+func excelDate(serial float64) string {
+	return excelEpoch.AddDate(0, 0, int(serial)).Format("02/01/2006")
+}
@@ -0,0 +1,186 @@
+// Package logging sets up the process-wide slog logger shared by the
+// commands and scripts: its level, text or JSON format, and destination.
+//
+// Every command accepts the same flags, with one or two dashes and anywhere
+// on the command line:
+//
+//	-v, --verbose           debug logging
+//	-q, --quiet             warnings and errors only
+//	--log-level=LEVEL       debug, info, warn or error
+//	--log-json              JSON lines instead of text
+//	--log-file=PATH         append logs to PATH instead of stderr
+//	--log-full-account-ids  log bank account numbers in full
+//
+// LOG_LEVEL, LOG_FORMAT (text or json), LOG_FILE and LOG_FULL_ACCOUNT_IDS
+// set the defaults. Account numbers logged under the account, account_id
+// and account_number keys are masked to their last 4 digits unless full
+// ones are asked for.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidOption is returned for an unknown level or format.
+var ErrInvalidOption = errors.New("invalid logging option")
+
+// Options configures the logger.
+type Options struct {
+	Level slog.Level
+	JSON  bool
+	File  string // empty logs to stderr
+
+	FullAccountIDs bool // Log account numbers unmasked
+}
+
+// accountKeys are the attribute keys whose values are bank account numbers.
+var accountKeys = map[string]bool{"account": true, "account_id": true, "account_number": true}
+
+// ParseArgs returns the options set by the LOG_* variables (read with
+// getenv) and the logging flags in args, and args without those flags so the
+// command's own parsing doesn't see them.
+func ParseArgs(args []string, getenv func(string) string) (Options, []string, error) {
+	var opts Options
+	if v := getenv("LOG_LEVEL"); v != "" {
+		if err := opts.Level.UnmarshalText([]byte(v)); err != nil {
+			return opts, nil, fmt.Errorf("%w: LOG_LEVEL=%q", ErrInvalidOption, v)
+		}
+	}
+	switch f := strings.ToLower(getenv("LOG_FORMAT")); f {
+	case "", "text":
+	case "json":
+		opts.JSON = true
+	default:
+		return opts, nil, fmt.Errorf("%w: LOG_FORMAT=%q, want text or json", ErrInvalidOption, f)
+	}
+	opts.File = getenv("LOG_FILE")
+	if v := getenv("LOG_FULL_ACCOUNT_IDS"); v != "" {
+		full, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, nil, fmt.Errorf("%w: LOG_FULL_ACCOUNT_IDS=%q", ErrInvalidOption, v)
+		}
+		opts.FullAccountIDs = full
+	}
+
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		// takeValue returns the flag's value, from "=value" or the next argument.
+		takeValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%w: %s needs a value", ErrInvalidOption, arg)
+			}
+			i++
+			return args[i], nil
+		}
+		switch name {
+		case "v", "verbose":
+			opts.Level = slog.LevelDebug
+		case "q", "quiet":
+			opts.Level = slog.LevelWarn
+		case "log-json":
+			opts.JSON = true
+		case "log-full-account-ids":
+			opts.FullAccountIDs = true
+		case "log-level":
+			v, err := takeValue()
+			if err != nil {
+				return opts, nil, err
+			}
+			if err := opts.Level.UnmarshalText([]byte(v)); err != nil {
+				return opts, nil, fmt.Errorf("%w: --log-level=%q", ErrInvalidOption, v)
+			}
+		case "log-file":
+			v, err := takeValue()
+			if err != nil {
+				return opts, nil, err
+			}
+			opts.File = v
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return opts, rest, nil
+}
+
+// New returns a logger writing to w with opts' level and format.
+func New(w io.Writer, opts Options) *slog.Logger {
+	ho := &slog.HandlerOptions{Level: opts.Level}
+	if !opts.FullAccountIDs {
+		ho.ReplaceAttr = maskAccounts
+	}
+	if opts.JSON {
+		return slog.New(slog.NewJSONHandler(w, ho))
+	}
+	return slog.New(slog.NewTextHandler(w, ho))
+}
+
+// maskAccounts masks the account numbers logged under accountKeys (see
+// bank.MaskAccountID). Stored account UUIDs, also logged as account_id,
+// aren't bank data and are left alone.
+func maskAccounts(_ []string, a slog.Attr) slog.Attr {
+	if !accountKeys[a.Key] || a.Value.Kind() != slog.KindString {
+		return a
+	}
+	v := a.Value.String()
+	if uuid.Validate(v) == nil {
+		return a
+	}
+	return slog.String(a.Key, bank.MaskAccountID(v))
+}
+
+// Setup makes the logger opts describe the default, for slog and for the
+// standard log package, whose messages (log.Fatalf in the commands) are
+// logged as errors. The returned func closes the log file, if any.
+func Setup(opts Options) (func() error, error) {
+	var w io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+	if opts.File != "" {
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open log file: %w", err)
+		}
+		w, closeFn = f, f.Close
+	}
+	slog.SetDefault(New(w, opts))
+	slog.SetLogLoggerLevel(slog.LevelError)
+	return closeFn, nil
+}
+
+// Init parses the logging options from os.Args and the environment, sets up
+// the default logger, and strips the logging flags from os.Args. It exits
+// with status 2 on an invalid option.
+func Init() func() error {
+	opts, rest, err := ParseArgs(os.Args, os.Getenv)
+	if err == nil {
+		var closeFn func() error
+		if closeFn, err = Setup(opts); err == nil {
+			os.Args = rest
+			return closeFn
+		}
+	}
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+	return nil
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+func TestParseArgs(t *testing.T) {
+	opts, rest, err := ParseArgs([]string{"bank-scraper", "-v", "report", "summary", "--log-json", "--log-file", "/tmp/x.log", "--json"}, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, Options{Level: slog.LevelDebug, JSON: true, File: "/tmp/x.log"}, opts)
+	assert.Equal(t, []string{"bank-scraper", "report", "summary", "--json"}, rest)
+
+	opts, rest, err = ParseArgs([]string{"sanitize", "-quiet", "-bank=bbva", "--", "-v"}, env(map[string]string{"LOG_FORMAT": "JSON", "LOG_FILE": "a.log"}))
+	require.NoError(t, err)
+	assert.Equal(t, Options{Level: slog.LevelWarn, JSON: true, File: "a.log"}, opts)
+	assert.Equal(t, []string{"sanitize", "-bank=bbva", "--", "-v"}, rest, "flags after -- are left alone")
+
+	opts, _, err = ParseArgs([]string{"api", "--log-level=error"}, env(map[string]string{"LOG_LEVEL": "debug"}))
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelError, opts.Level, "flags override LOG_LEVEL")
+
+	for _, bad := range []struct {
+		args []string
+		env  map[string]string
+	}{
+		{[]string{"x", "--log-level=loud"}, nil},
+		{[]string{"x", "--log-file"}, nil},
+		{[]string{"x"}, map[string]string{"LOG_LEVEL": "loud"}},
+		{[]string{"x"}, map[string]string{"LOG_FORMAT": "xml"}},
+	} {
+		_, _, err := ParseArgs(bad.args, env(bad.env))
+		assert.ErrorIs(t, err, ErrInvalidOption, bad)
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, Options{Level: slog.LevelWarn, JSON: true})
+	l.Info("hidden")
+	l.Warn("shown", slog.String("bank", "BBVA"))
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), `"msg":"shown","bank":"BBVA"`)
+}
+
+func TestNew_MasksAccounts(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, Options{})
+	l.Info("fetched", slog.String("account", "0011-0100-0200004607"),
+		slog.Group("op", slog.String("account_id", "0011-0100-0200004607")),
+		slog.String("account_id", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+	assert.Contains(t, buf.String(), "account=XXXXXXXXXXXXXXXX4607 op.account_id=XXXXXXXXXXXXXXXX4607")
+	assert.Contains(t, buf.String(), "account_id=6ba7b810-9dad-11d1-80b4-00c04fd430c8", "UUIDs kept")
+
+	buf.Reset()
+	New(&buf, Options{FullAccountIDs: true}).Info("fetched", slog.String("account", "0011-0100-0200004607"))
+	assert.Contains(t, buf.String(), "account=0011-0100-0200004607")
+
+	opts, _, err := ParseArgs([]string{"api"}, env(map[string]string{"LOG_FULL_ACCOUNT_IDS": "true"}))
+	require.NoError(t, err)
+	assert.True(t, opts.FullAccountIDs)
+	opts, _, err = ParseArgs([]string{"api", "--log-full-account-ids"}, env(nil))
+	require.NoError(t, err)
+	assert.True(t, opts.FullAccountIDs)
+}
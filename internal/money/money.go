@@ -0,0 +1,93 @@
+// Package money formats and rounds amounts the same way wherever they are
+// shown: the CLI, reports, exports and the API. Amounts are int64 cents
+// throughout the module; this is the one place that turns them into text.
+package money
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// Money is an amount in cents of a currency.
+type Money struct {
+	Cents    int64
+	Currency bank.Currency
+}
+
+// Locale is a way of writing amounts.
+type Locale string
+
+// Locales.
+const (
+	// LocalePE writes amounts as Peruvian banks and SUNAT do: currency
+	// symbol, comma thousands separator, decimal point (S/ 8,577.97,
+	// US$ 10,416.79, -S/ 50.00).
+	LocalePE Locale = "es-PE"
+	// LocaleDecimal writes a plain signed decimal without symbol or
+	// grouping (8577.97, -0.50), for CSV, JSON and other machine readers.
+	LocaleDecimal Locale = ""
+)
+
+// symbols are the currency symbols of LocalePE. Other currencies are shown
+// by their code.
+var symbols = map[bank.Currency]string{
+	bank.CurrencyPEN: "S/",
+	bank.CurrencyUSD: "US$",
+}
+
+// FormatMoney writes m for locale.
+func FormatMoney(m Money, locale Locale) string {
+	if locale == LocaleDecimal {
+		return FormatCents(m.Cents)
+	}
+	sign, cents := "", m.Cents
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	symbol := symbols[m.Currency]
+	if symbol == "" {
+		symbol = string(m.Currency)
+	}
+	units := group(strconv.FormatUint(uint64(cents/100), 10))
+	return sign + symbol + " " + units + "." + twoDigits(cents%100)
+}
+
+// FormatCents writes cents as a plain decimal: -50 → "-0.50".
+func FormatCents(cents int64) string {
+	sign := ""
+	u := uint64(cents)
+	if cents < 0 {
+		sign, u = "-", uint64(-cents)
+	}
+	return sign + strconv.FormatUint(u/100, 10) + "." + twoDigits(int64(u%100))
+}
+
+// Scale multiplies cents by factor (an exchange rate, a percentage),
+// rounding half away from zero to whole cents.
+func Scale(cents int64, factor float64) int64 {
+	return int64(math.Round(float64(cents) * factor))
+}
+
+// group inserts a comma every three digits from the right.
+func group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	out := make([]byte, 0, len(digits)+len(digits)/3)
+	for i := range len(digits) {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, digits[i])
+	}
+	return string(out)
+}
+
+func twoDigits(n int64) string {
+	if n < 10 {
+		return "0" + strconv.FormatInt(n, 10)
+	}
+	return strconv.FormatInt(n, 10)
+}
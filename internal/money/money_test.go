@@ -0,0 +1,43 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		m      Money
+		locale Locale
+		want   string
+	}{
+		{Money{857_797, bank.CurrencyPEN}, LocalePE, "S/ 8,577.97"},
+		{Money{1_041_679, bank.CurrencyUSD}, LocalePE, "US$ 10,416.79"},
+		{Money{-5_000, bank.CurrencyPEN}, LocalePE, "-S/ 50.00"},
+		{Money{5, bank.CurrencyPEN}, LocalePE, "S/ 0.05"},
+		{Money{123_456_789_00, "EUR"}, LocalePE, "EUR 123,456,789.00"},
+		{Money{857_797, bank.CurrencyPEN}, LocaleDecimal, "8577.97"},
+		{Money{-50, bank.CurrencyUSD}, LocaleDecimal, "-0.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatMoney(tt.m, tt.locale))
+		})
+	}
+}
+
+func TestFormatCents(t *testing.T) {
+	assert.Equal(t, "0.00", FormatCents(0))
+	assert.Equal(t, "-0.50", FormatCents(-50))
+	assert.Equal(t, "1234.05", FormatCents(123_405))
+	assert.Equal(t, "-92233720368547758.08", FormatCents(math.MinInt64))
+}
+
+func TestScale(t *testing.T) {
+	assert.Equal(t, int64(375), Scale(100, 3.75))
+	assert.Equal(t, int64(38), Scale(10, 3.75), "37.5 rounds up")
+	assert.Equal(t, int64(-38), Scale(-10, 3.75), "away from zero")
+}
@@ -0,0 +1,307 @@
+// Package parquet writes flat tables as Apache Parquet files, for handing
+// stored data to analytics tools (DuckDB, Spark, a data lake) as-is.
+//
+// It covers what the exports need and nothing more: required and optional
+// columns of strings, integers, decimals, dates and timestamps, PLAIN
+// encoded, uncompressed, in a single row group. Any Parquet reader can
+// read the result; the tests read it back with parquet-go.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrInvalidValue is returned by Write for a value that doesn't match its
+// column.
+var ErrInvalidValue = errors.New("invalid parquet value")
+
+// magic starts and ends every Parquet file.
+const magic = "PAR1"
+
+// createdBy is recorded in the footer of the files written.
+const createdBy = "bank-scraper"
+
+// Type is the type of a column's values.
+type Type int
+
+// Column types, with the Go type Write expects for each.
+const (
+	String    Type = iota // string, stored as UTF-8 BYTE_ARRAY
+	Int64                 // int64
+	Cents                 // int64 cents, stored as DECIMAL(18,2)
+	Date                  // time.Time, stored as DATE (its calendar day)
+	Timestamp             // time.Time, stored as TIMESTAMP_MILLIS (UTC)
+)
+
+// Column describes one column of a table.
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool // Allows nil values
+}
+
+// Physical types, encodings and other enums of the Parquet format.
+const (
+	typeInt32     = 1
+	typeInt64     = 2
+	typeByteArray = 6
+
+	convertedUTF8            = 0
+	convertedDecimal         = 5
+	convertedDate            = 6
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+	formatVersion     = 1
+)
+
+// Write writes rows as a Parquet file with the given columns to w. Each row
+// holds one value per column, of the Go type its Type expects, or nil in an
+// optional column.
+func Write(w io.Writer, columns []Column, rows [][]any) error {
+	out := &countingWriter{w: w}
+	if _, err := io.WriteString(out, magic); err != nil {
+		return err
+	}
+
+	chunks := make([]columnChunk, len(columns))
+	for i, col := range columns {
+		page, err := encodePage(col, i, rows)
+		if err != nil {
+			return err
+		}
+		chunks[i] = columnChunk{offset: out.n, size: int64(len(page)), values: int64(len(rows))}
+		if _, err := out.Write(page); err != nil {
+			return err
+		}
+	}
+
+	footer := fileMetaData(columns, chunks, int64(len(rows)))
+	if _, err := out.Write(footer); err != nil {
+		return err
+	}
+	if err := binary.Write(out, binary.LittleEndian, uint32(len(footer))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, magic)
+	return err
+}
+
+// columnChunk is where a column's page landed in the file.
+type columnChunk struct {
+	offset int64 // Of the page header
+	size   int64 // Header and page
+	values int64
+}
+
+// encodePage returns column i of rows as a data page, header included:
+// definition levels for optional columns, then the non-nil values.
+func encodePage(col Column, i int, rows [][]any) ([]byte, error) {
+	var values bytes.Buffer
+	levels := make([]byte, 0, len(rows))
+	for r, row := range rows {
+		if len(row) <= i {
+			return nil, fmt.Errorf("%w: row %d has %d values, want %d", ErrInvalidValue, r, len(row), i+1)
+		}
+		v := row[i]
+		if v == nil {
+			if !col.Optional {
+				return nil, fmt.Errorf("%w: row %d: %s is required", ErrInvalidValue, r, col.Name)
+			}
+			levels = append(levels, 0)
+			continue
+		}
+		levels = append(levels, 1)
+		if err := encodeValue(&values, col.Type, v); err != nil {
+			return nil, fmt.Errorf("row %d: %s: %w", r, col.Name, err)
+		}
+	}
+
+	var body bytes.Buffer
+	if col.Optional {
+		rle := encodeLevels(levels)
+		_ = binary.Write(&body, binary.LittleEndian, uint32(len(rle)))
+		body.Write(rle)
+	}
+	body.Write(values.Bytes())
+
+	t := newThriftWriter()
+	t.i32(1, pageTypeData)
+	t.i32(2, int32(body.Len()))
+	t.i32(3, int32(body.Len()))
+	t.structBegin(5) // DataPageHeader
+	t.i32(1, int32(len(rows)))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.structEnd()
+	return append(t.end(), body.Bytes()...), nil
+}
+
+// encodeValue appends v to buf in PLAIN encoding.
+func encodeValue(buf *bytes.Buffer, typ Type, v any) error {
+	switch typ {
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%w: %T is not a string", ErrInvalidValue, v)
+		}
+		_ = binary.Write(buf, binary.LittleEndian, uint32(len(s)))
+		buf.WriteString(s)
+	case Int64, Cents:
+		n, ok := v.(int64)
+		if !ok {
+			return fmt.Errorf("%w: %T is not an int64", ErrInvalidValue, v)
+		}
+		_ = binary.Write(buf, binary.LittleEndian, n)
+	case Date:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("%w: %T is not a time.Time", ErrInvalidValue, v)
+		}
+		y, m, d := t.Date()
+		days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+		_ = binary.Write(buf, binary.LittleEndian, int32(days))
+	case Timestamp:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("%w: %T is not a time.Time", ErrInvalidValue, v)
+		}
+		_ = binary.Write(buf, binary.LittleEndian, t.UnixMilli())
+	default:
+		return fmt.Errorf("%w: unknown column type %d", ErrInvalidValue, typ)
+	}
+	return nil
+}
+
+// encodeLevels encodes definition levels of bit width 1 as RLE runs of the
+// RLE/bit-packing hybrid encoding.
+func encodeLevels(levels []byte) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		out = append(out, levels[i])
+		i = j
+	}
+	return out
+}
+
+// fileMetaData returns the footer describing the file's schema and its
+// single row group.
+func fileMetaData(columns []Column, chunks []columnChunk, rows int64) []byte {
+	t := newThriftWriter()
+	t.i32(1, formatVersion)
+
+	t.listBegin(2, thriftStruct, len(columns)+1)
+	t.elemBegin() // Root of the schema
+	t.binary(4, []byte("schema"))
+	t.i32(5, int32(len(columns)))
+	t.structEnd()
+	for _, col := range columns {
+		t.elemBegin()
+		t.i32(1, physicalType(col.Type))
+		repetition := int32(repetitionRequired)
+		if col.Optional {
+			repetition = repetitionOptional
+		}
+		t.i32(3, repetition)
+		t.binary(4, []byte(col.Name))
+		switch col.Type {
+		case String:
+			t.i32(6, convertedUTF8)
+		case Cents:
+			t.i32(6, convertedDecimal)
+			t.i32(7, 2)  // scale
+			t.i32(8, 18) // precision
+		case Date:
+			t.i32(6, convertedDate)
+		case Timestamp:
+			t.i32(6, convertedTimestampMillis)
+		}
+		t.structEnd()
+	}
+
+	t.i64(3, rows)
+
+	groups := 0
+	if rows > 0 {
+		groups = 1
+	}
+	t.listBegin(4, thriftStruct, groups)
+	if groups > 0 {
+		t.elemBegin() // RowGroup
+		t.listBegin(1, thriftStruct, len(columns))
+		var total int64
+		for i, col := range columns {
+			c := chunks[i]
+			total += c.size
+			t.elemBegin() // ColumnChunk
+			t.i64(2, c.offset)
+			t.structBegin(3) // ColumnMetaData
+			t.i32(1, physicalType(col.Type))
+			encodings := []int32{encodingPlain}
+			if col.Optional {
+				encodings = append(encodings, encodingRLE)
+			}
+			t.listBegin(2, thriftI32, len(encodings))
+			for _, e := range encodings {
+				t.elemI32(e)
+			}
+			t.listBegin(3, thriftBinary, 1)
+			t.elemBinary([]byte(col.Name))
+			t.i32(4, codecUncompressed)
+			t.i64(5, c.values)
+			t.i64(6, c.size)
+			t.i64(7, c.size)
+			t.i64(9, c.offset)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64(2, total)
+		t.i64(3, rows)
+		t.structEnd()
+	}
+
+	t.binary(6, []byte(createdBy))
+	return t.end()
+}
+
+func physicalType(typ Type) int32 {
+	switch typ {
+	case String:
+		return typeByteArray
+	case Date:
+		return typeInt32
+	default:
+		return typeInt64
+	}
+}
+
+// countingWriter counts the bytes written through it, for the offsets the
+// footer records.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
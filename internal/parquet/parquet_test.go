@@ -0,0 +1,231 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = []Column{
+	{Name: "description", Type: String},
+	{Name: "amount", Type: Cents},
+	{Name: "operation_date", Type: Date},
+	{Name: "balance_after", Type: Cents, Optional: true},
+	{Name: "synced_at", Type: Timestamp, Optional: true},
+}
+
+func TestWrite(t *testing.T) {
+	day := time.Date(2026, 3, 2, 23, 30, 0, 0, time.FixedZone("PET", -5*60*60))
+	rows := [][]any{
+		{"PAGO PLANILLA", int64(-120_000), day, int64(880_000), nil},
+		{"ABONO CLIENTE", int64(45_050), day, nil, time.UnixMilli(1_772_500_000_000)},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testColumns, rows))
+	file := buf.Bytes()
+
+	require.Equal(t, magic, string(file[:4]))
+	require.Equal(t, magic, string(file[len(file)-4:]))
+	size := binary.LittleEndian.Uint32(file[len(file)-8:])
+	meta := readStruct(t, bytes.NewReader(file[len(file)-8-int(size):len(file)-8]))
+
+	assert.Equal(t, int64(2), meta[3], "num_rows")
+	assert.Equal(t, []byte(createdBy), meta[6])
+	schema := meta[2].([]any)
+	require.Len(t, schema, len(testColumns)+1)
+	assert.Equal(t, int64(len(testColumns)), schema[0].(map[int16]any)[5], "root num_children")
+	amount := schema[2].(map[int16]any)
+	assert.Equal(t, []byte("amount"), amount[4])
+	assert.Equal(t, int64(convertedDecimal), amount[6])
+	assert.Equal(t, int64(2), amount[7], "scale")
+
+	groups := meta[4].([]any)
+	require.Len(t, groups, 1)
+	chunks := groups[0].(map[int16]any)[1].([]any)
+	require.Len(t, chunks, len(testColumns))
+	page := func(col int) (map[int16]any, *bytes.Reader) {
+		md := chunks[col].(map[int16]any)[3].(map[int16]any)
+		r := bytes.NewReader(file[md[9].(int64):])
+		return readStruct(t, r), r
+	}
+
+	header, r := page(0)
+	assert.Equal(t, int64(2), header[5].(map[int16]any)[1], "num_values")
+	var n uint32
+	require.NoError(t, binary.Read(r, binary.LittleEndian, &n))
+	s := make([]byte, n)
+	_, _ = r.Read(s)
+	assert.Equal(t, "PAGO PLANILLA", string(s))
+
+	_, r = page(1)
+	var cents int64
+	require.NoError(t, binary.Read(r, binary.LittleEndian, &cents))
+	assert.Equal(t, int64(-120_000), cents)
+
+	_, r = page(2)
+	var days int32
+	require.NoError(t, binary.Read(r, binary.LittleEndian, &days))
+	assert.Equal(t, "2026-03-02", time.Unix(int64(days)*86400, 0).UTC().Format(time.DateOnly), "the value's own calendar day")
+
+	// Optional: RLE definition levels (one present, one null), then the
+	// present value only.
+	_, r = page(3)
+	var levelsLen uint32
+	require.NoError(t, binary.Read(r, binary.LittleEndian, &levelsLen))
+	levels := make([]byte, levelsLen)
+	_, _ = r.Read(levels)
+	assert.Equal(t, []byte{1 << 1, 1, 1 << 1, 0}, levels)
+	require.NoError(t, binary.Read(r, binary.LittleEndian, &cents))
+	assert.Equal(t, int64(880_000), cents)
+}
+
+// TestWrite_ParquetGo reads what Write produced with parquet-go, an
+// independent implementation of the format, so the file isn't only checked
+// against this package's idea of it.
+func TestWrite_ParquetGo(t *testing.T) {
+	day := time.Date(2026, 3, 2, 23, 30, 0, 0, time.FixedZone("PET", -5*60*60))
+	synced := time.UnixMilli(1_772_500_000_000)
+	var rows [][]any
+	for i := range 300 {
+		// Runs of nulls and values of several lengths in the optional
+		// columns, for their definition levels.
+		var balance, at any
+		if i%7 != 0 {
+			balance = int64(i) * 1_000
+		}
+		if i >= 100 && i < 250 {
+			at = synced
+		}
+		rows = append(rows, []any{fmt.Sprintf("PAGO %03d", i), int64(-i * 150), day, balance, at})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testColumns, rows))
+
+	f, err := parquetgo.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(rows)), f.NumRows())
+	assert.Equal(t, `message schema {
+	required binary description (STRING);
+	required int64 amount (DECIMAL(18,2));
+	required int32 operation_date (DATE);
+	optional int64 balance_after (DECIMAL(18,2));
+	optional int64 synced_at (TIMESTAMP(isAdjustedToUTC=true,unit=MILLIS));
+}`, f.Schema().String())
+
+	got := make([]parquetgo.Row, len(rows)+1)
+	n, err := parquetgo.NewReader(bytes.NewReader(buf.Bytes())).ReadRows(got)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, len(rows), n)
+	wantDays := int32(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+	for i, row := range got[:n] {
+		require.Len(t, row, len(testColumns))
+		assert.Equal(t, rows[i][0], string(row[0].ByteArray()))
+		assert.Equal(t, rows[i][1], row[1].Int64())
+		assert.Equal(t, wantDays, row[2].Int32())
+		if rows[i][3] == nil {
+			assert.True(t, row[3].IsNull(), "row %d balance_after", i)
+		} else {
+			assert.Equal(t, rows[i][3], row[3].Int64(), "row %d balance_after", i)
+		}
+		if rows[i][4] == nil {
+			assert.True(t, row[4].IsNull(), "row %d synced_at", i)
+		} else {
+			assert.Equal(t, synced.UnixMilli(), row[4].Int64(), "row %d synced_at", i)
+		}
+	}
+}
+
+func TestWrite_ParquetGoEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testColumns, nil))
+	f, err := parquetgo.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), f.NumRows())
+	assert.Len(t, f.Schema().Fields(), len(testColumns))
+}
+
+func TestWrite_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testColumns, nil))
+	file := buf.Bytes()
+	size := binary.LittleEndian.Uint32(file[len(file)-8:])
+	meta := readStruct(t, bytes.NewReader(file[len(file)-8-int(size):len(file)-8]))
+	assert.Equal(t, int64(0), meta[3])
+	assert.Empty(t, meta[4])
+}
+
+func TestWrite_InvalidValue(t *testing.T) {
+	err := Write(&bytes.Buffer{}, testColumns, [][]any{{"x", 12, time.Now(), nil, nil}})
+	assert.ErrorIs(t, err, ErrInvalidValue, "int, not int64")
+
+	err = Write(&bytes.Buffer{}, testColumns, [][]any{{nil, int64(1), time.Now(), nil, nil}})
+	assert.ErrorIs(t, err, ErrInvalidValue, "required")
+
+	err = Write(&bytes.Buffer{}, testColumns, [][]any{{"x"}})
+	assert.ErrorIs(t, err, ErrInvalidValue, "short row")
+}
+
+// readStruct decodes a Thrift compact struct into field id → value, enough
+// to check what Write produced: integers as int64, binaries as []byte,
+// lists as []any, structs as map[int16]any.
+func readStruct(t *testing.T, r *bytes.Reader) map[int16]any {
+	t.Helper()
+	out := map[int16]any{}
+	var id int16
+	for {
+		b, err := r.ReadByte()
+		require.NoError(t, err)
+		if b == 0 {
+			return out
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(readZigzag(t, r))
+		}
+		out[id] = readValue(t, r, b&0x0f)
+	}
+}
+
+func readValue(t *testing.T, r *bytes.Reader, typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return readZigzag(t, r)
+	case thriftBinary:
+		n, err := binary.ReadUvarint(r)
+		require.NoError(t, err)
+		b := make([]byte, n)
+		_, _ = r.Read(b)
+		return b
+	case thriftList:
+		h, err := r.ReadByte()
+		require.NoError(t, err)
+		n := uint64(h >> 4)
+		if n == 15 {
+			n, err = binary.ReadUvarint(r)
+			require.NoError(t, err)
+		}
+		list := []any{}
+		for range n {
+			list = append(list, readValue(t, r, h&0x0f))
+		}
+		return list
+	case thriftStruct:
+		return readStruct(t, r)
+	}
+	t.Fatalf("unexpected thrift type %d", typ)
+	return nil
+}
+
+func readZigzag(t *testing.T, r *bytes.Reader) int64 {
+	u, err := binary.ReadUvarint(r)
+	require.NoError(t, err)
+	return int64(u>>1) ^ -int64(u&1)
+}
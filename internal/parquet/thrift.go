@@ -0,0 +1,105 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol types used by the Parquet footer and page
+// headers.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes one struct in the Thrift compact protocol, the
+// encoding of Parquet's metadata. Fields must be written in increasing id
+// order within each struct.
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // Last field id written, per open struct
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+// end closes the outermost struct and returns the encoding.
+func (t *thriftWriter) end() []byte {
+	t.buf.WriteByte(0)
+	return t.buf.Bytes()
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	top := len(t.last) - 1
+	if delta := id - t.last[top]; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.last[top] = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, b []byte) {
+	t.field(id, thriftBinary)
+	t.elemBinary(b)
+}
+
+// structBegin opens a struct field; close it with structEnd.
+func (t *thriftWriter) structBegin(id int16) {
+	t.field(id, thriftStruct)
+	t.last = append(t.last, 0)
+}
+
+func (t *thriftWriter) structEnd() {
+	t.buf.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+// listBegin opens a list field of n elements of elemType, written next
+// with the elem methods.
+func (t *thriftWriter) listBegin(id int16, elemType byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.varint(uint64(n))
+	}
+}
+
+// elemBegin opens a struct element of a list; close it with structEnd.
+func (t *thriftWriter) elemBegin() {
+	t.last = append(t.last, 0)
+}
+
+func (t *thriftWriter) elemI32(v int32) {
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) elemBinary(b []byte) {
+	t.varint(uint64(len(b)))
+	t.buf.Write(b)
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
@@ -0,0 +1,213 @@
+// Package pipeline runs the steps each account of a scrape job goes through,
+// in an order set per profile: fetch the transactions, fetch deeper when the
+// balance shows some are missing, drop those already stored, tag and enrich
+// the rest, store them, refresh the exports and notify. The steps, and which
+// profiles skip or reorder them, come from a YAML file, so a deployment can
+// fit its workflow without code changes:
+//
+//	# Every profile, in order. Default: all steps, in this order.
+//	steps: [scrape, backfill, dedupe, categorize, enrich, store, export, notify]
+//	profiles:
+//	  acme:
+//	    steps: [scrape, dedupe, store, notify] # replaces the list
+//	  personal:
+//	    disable: [export]                      # drops from the list
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig is returned for a pipeline file naming an unknown step,
+// listing one twice, or ordering them impossibly.
+var ErrInvalidConfig = errors.New("invalid pipeline config")
+
+// Step names.
+const (
+	StepScrape     = "scrape"     // Fetch the account's transactions
+	StepBackfill   = "backfill"   // Fetch deeper when the balance shows a gap
+	StepDedupe     = "dedupe"     // Drop the transactions already stored
+	StepCategorize = "categorize" // Tag tax movements (ITF, IGV, ...)
+	StepEnrich     = "enrich"     // Run the configured bank.Enrichers
+	StepStore      = "store"      // Insert into the transactions table
+	StepExport     = "export"     // Rewrite the profile's exports
+	StepNotify     = "notify"     // Publish transaction.new events
+)
+
+// DefaultSteps is the pipeline of a profile the file doesn't configure.
+var DefaultSteps = []string{StepScrape, StepBackfill, StepDedupe, StepCategorize, StepEnrich, StepStore, StepExport, StepNotify}
+
+// Batch is one account's trip through the pipeline. The job fills the
+// account, scraper, count and balance; the scrape step fills Fetched and
+// Transactions, and later steps filter and change Transactions. The dedupe
+// step hashes the whole fetched batch and keeps each fresh transaction's
+// hash in Hashes, since hashing the fresh subset again would renumber
+// identical same-day movements.
+type Batch struct {
+	Profile  string
+	BankCode bank.Code
+	Account  store.Account
+	Scraper  bank.Scraper
+	Count    int           // Transactions to fetch
+	Balance  *bank.Balance // The account's balance, if the job got it
+
+	Fetched      []bank.Transaction // As the bank returned them
+	Transactions []bank.Transaction
+	Hashes       [][]byte   // Dedup hashes of Transactions, set by the dedupe step
+	Gap          *store.Gap // Set by the backfill step
+	Inserted     int        // Set by the store step
+}
+
+// Step is one stage of a pipeline.
+type Step interface {
+	Run(ctx context.Context, b *Batch) error
+}
+
+// StepFunc adapts a function to Step.
+type StepFunc func(ctx context.Context, b *Batch) error
+
+// Run calls f(ctx, b).
+func (f StepFunc) Run(ctx context.Context, b *Batch) error {
+	return f(ctx, b)
+}
+
+// Config is a parsed pipeline file.
+type Config struct {
+	Steps    []string                 `yaml:"steps"`
+	Profiles map[string]ProfileConfig `yaml:"profiles"`
+}
+
+// ProfileConfig overrides the steps of one profile: Steps replaces the
+// file's list, Disable removes steps from it.
+type ProfileConfig struct {
+	Steps   []string `yaml:"steps"`
+	Disable []string `yaml:"disable"`
+}
+
+// Load reads and validates the pipeline file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a pipeline file.
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if err := validate("steps", c.Steps, true); err != nil {
+		return nil, err
+	}
+	for profile, p := range c.Profiles {
+		if err := validate(profile+".steps", p.Steps, true); err != nil {
+			return nil, err
+		}
+		if err := validate(profile+".disable", p.Disable, false); err != nil {
+			return nil, err
+		}
+		if slices.Contains(p.Disable, StepScrape) {
+			return nil, fmt.Errorf("%w: %s.disable: %s can't be disabled", ErrInvalidConfig, profile, StepScrape)
+		}
+	}
+	return &c, nil
+}
+
+// validate checks a list of step names: known and unique and, for an
+// ordered pipeline, starting with scrape, backfilling right after it, since
+// a deeper fetch replaces the transactions, and storing before exporting,
+// since exports read the store. An empty list is valid: it means the
+// default.
+func validate(field string, steps []string, ordered bool) error {
+	for i, name := range steps {
+		if !slices.Contains(DefaultSteps, name) {
+			return fmt.Errorf("%w: %s: unknown step %q (use %s)", ErrInvalidConfig, field, name, strings.Join(DefaultSteps, ", "))
+		}
+		if slices.Index(steps, name) != i {
+			return fmt.Errorf("%w: %s: %s listed twice", ErrInvalidConfig, field, name)
+		}
+	}
+	if !ordered || len(steps) == 0 {
+		return nil
+	}
+	if steps[0] != StepScrape {
+		return fmt.Errorf("%w: %s: must start with %s", ErrInvalidConfig, field, StepScrape)
+	}
+	if i := slices.Index(steps, StepBackfill); i > 1 {
+		return fmt.Errorf("%w: %s: %s must come right after %s", ErrInvalidConfig, field, StepBackfill, StepScrape)
+	}
+	if s, e := slices.Index(steps, StepStore), slices.Index(steps, StepExport); s >= 0 && e >= 0 && e < s {
+		return fmt.Errorf("%w: %s: %s must come after %s", ErrInvalidConfig, field, StepExport, StepStore)
+	}
+	return nil
+}
+
+// StepsFor returns the steps of profile, in order. A nil Config runs
+// DefaultSteps.
+func (c *Config) StepsFor(profile string) []string {
+	if c == nil {
+		return DefaultSteps
+	}
+	steps := DefaultSteps
+	if len(c.Steps) > 0 {
+		steps = c.Steps
+	}
+	p := c.Profiles[profile]
+	if len(p.Steps) > 0 {
+		steps = p.Steps
+	}
+	return slices.DeleteFunc(slices.Clone(steps), func(name string) bool {
+		return slices.Contains(p.Disable, name)
+	})
+}
+
+// Pipeline runs each profile's configured steps.
+type Pipeline struct {
+	config *Config
+	steps  map[string]Step
+}
+
+// New creates a Pipeline running config's steps with the given
+// implementations, keyed by step name. Every step config uses must have
+// one.
+func New(config *Config, steps map[string]Step) (*Pipeline, error) {
+	used := slices.Clone(config.StepsFor(store.DefaultProfile))
+	if config != nil {
+		for profile := range config.Profiles {
+			used = append(used, config.StepsFor(profile)...)
+		}
+	}
+	for _, name := range used {
+		if steps[name] == nil {
+			return nil, fmt.Errorf("%w: step %s isn't available", ErrInvalidConfig, name)
+		}
+	}
+	return &Pipeline{config: config, steps: steps}, nil
+}
+
+// Run runs b through the steps of b.Profile, in order, stopping at the
+// first that fails.
+func (p *Pipeline) Run(ctx context.Context, b *Batch) error {
+	for _, name := range p.config.StepsFor(b.Profile) {
+		if err := p.steps[name].Run(ctx, b); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
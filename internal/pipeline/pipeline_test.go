@@ -0,0 +1,220 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`
+steps: [scrape, dedupe, store, export, notify]
+profiles:
+  acme:
+    steps: [scrape, notify, store]
+  personal:
+    disable: [export, notify]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scrape", "dedupe", "store", "export", "notify"}, c.StepsFor("default"))
+	assert.Equal(t, []string{"scrape", "notify", "store"}, c.StepsFor("acme"), "reordered")
+	assert.Equal(t, []string{"scrape", "dedupe", "store"}, c.StepsFor("personal"))
+
+	empty, err := Parse(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSteps, empty.StepsFor("default"))
+	var none *Config
+	assert.Equal(t, DefaultSteps, none.StepsFor("default"))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown step":   "steps: [scrape, archive]",
+		"twice":          "steps: [scrape, store, store]",
+		"scrape first":   "steps: [dedupe, scrape]",
+		"store, export":  "profiles: {acme: {steps: [scrape, export, store]}}",
+		"late backfill":  "steps: [scrape, dedupe, backfill]",
+		"disable scrape": "profiles: {acme: {disable: [scrape]}}",
+		"unknown key":    "stages: [scrape]",
+	} {
+		_, err := Parse([]byte(data))
+		assert.ErrorIs(t, err, ErrInvalidConfig, name)
+	}
+}
+
+func TestNew_MissingStep(t *testing.T) {
+	c, err := Parse([]byte("profiles: {acme: {steps: [scrape, store]}}"))
+	require.NoError(t, err)
+	_, err = New(c, map[string]Step{StepScrape: Scrape()})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+type memoryRepo struct {
+	stored []store.Transaction
+}
+
+// InsertBatch skips rows whose hash is already stored, as the table's
+// unique (account_id, dedup_hash) does.
+func (m *memoryRepo) InsertBatch(_ context.Context, txns []store.Transaction) (int, error) {
+	inserted := 0
+	for _, tx := range txns {
+		if !slices.ContainsFunc(m.stored, func(s store.Transaction) bool { return bytes.Equal(s.DedupHash, tx.DedupHash) }) {
+			m.stored = append(m.stored, tx)
+			inserted++
+		}
+	}
+	return inserted, nil
+}
+
+func (m *memoryRepo) ListByAccount(context.Context, uuid.UUID) ([]store.Transaction, error) {
+	return m.stored, nil
+}
+
+type recorder []events.Event
+
+func (r *recorder) Publish(e events.Event) { *r = append(*r, e) }
+
+func TestPipeline_Run(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	itf := bank.Transaction{Date: day, Description: "ITF", Amount: 5, Type: bank.TransactionDebit}
+	old := bank.Transaction{Date: day.AddDate(0, 0, -1), Description: "ABONO", Amount: 100, Type: bank.TransactionCredit}
+	mock := &banktest.MockScraper{Transactions: []bank.Transaction{itf, old}}
+
+	account := store.Account{ID: uuid.New(), AccountNumber: "0011-0100-0200004607"}
+	repo := &memoryRepo{stored: store.NewTransactions(account.ID, store.TransactionSourceScrape, []bank.Transaction{old})}
+	var published recorder
+	var exported []string
+
+	c, err := Parse([]byte("profiles: {acme: {disable: [notify]}}"))
+	require.NoError(t, err)
+	p, err := New(c, map[string]Step{
+		StepScrape:     Scrape(),
+		StepBackfill:   Backfill(repo, nil, 0),
+		StepDedupe:     Dedupe(repo, nil),
+		StepCategorize: Categorize(),
+		StepEnrich:     Enrich(),
+		StepStore:      Store(repo, nil),
+		StepExport: Export(func(_ context.Context, profile string) error {
+			exported = append(exported, profile)
+			return nil
+		}),
+		StepNotify: Notify(&published, store.AccountAliases{"4607": "Payroll"}),
+	})
+	require.NoError(t, err)
+
+	b := &Batch{Profile: "default", BankCode: bank.BankBBVA, Account: account, Scraper: mock, Count: 50}
+	require.NoError(t, p.Run(context.Background(), b))
+	assert.Len(t, b.Fetched, 2)
+	require.Len(t, b.Transactions, 1, "the stored one dropped")
+	assert.Equal(t, "itf", b.Transactions[0].Extra[CategoryKey])
+	assert.Equal(t, 1, b.Inserted)
+	assert.Equal(t, []string{"default"}, exported)
+	require.Len(t, published, 1)
+	assert.Equal(t, "Payroll", published[0].AccountAlias)
+	assert.Equal(t, "ITF", published[0].Data.(bank.Transaction).Description)
+
+	// Nothing new for acme, which doesn't notify either.
+	b = &Batch{Profile: "acme", BankCode: bank.BankBBVA, Account: account, Scraper: mock, Count: 50}
+	require.NoError(t, p.Run(context.Background(), b))
+	assert.Empty(t, b.Transactions)
+	assert.Len(t, published, 1)
+
+	mock.TransactionsErr = errors.New("portal down")
+	err = p.Run(context.Background(), b)
+	assert.ErrorContains(t, err, "scrape: portal down")
+}
+
+func TestDedupeStore_IdenticalMovements(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	itf := bank.Transaction{Date: day, Description: "ITF", Amount: 5, Type: bank.TransactionDebit}
+	account := store.Account{ID: uuid.New(), AccountNumber: "0011-0100-0200004607"}
+	repo := &memoryRepo{stored: store.NewTransactions(account.ID, store.TransactionSourceScrape, []bank.Transaction{itf})}
+	mock := &banktest.MockScraper{Transactions: []bank.Transaction{itf, itf, itf}}
+	var published recorder
+	p, err := New(&Config{Steps: []string{StepScrape, StepDedupe, StepStore, StepNotify}}, map[string]Step{
+		StepScrape: Scrape(),
+		StepDedupe: Dedupe(repo, nil),
+		StepStore:  Store(repo, nil),
+		StepNotify: Notify(&published, nil),
+	})
+	require.NoError(t, err)
+
+	b := &Batch{BankCode: bank.BankBBVA, Account: account, Scraper: mock, Count: 50}
+	require.NoError(t, p.Run(context.Background(), b))
+	assert.Len(t, b.Transactions, 2, "one of the three already stored")
+	assert.Equal(t, 2, b.Inserted, "none lost to a renumbered hash")
+	assert.Len(t, repo.stored, 3)
+	assert.Len(t, published, 2)
+
+	b = &Batch{BankCode: bank.BankBBVA, Account: account, Scraper: mock, Count: 50}
+	require.NoError(t, p.Run(context.Background(), b))
+	assert.Empty(t, b.Transactions)
+	assert.Len(t, published, 2, "not announced again")
+}
+
+// history returns the count most recent of its transactions, newest first;
+// the embedded nil Scraper panics on anything else.
+type history struct {
+	bank.Scraper
+	txns  []bank.Transaction
+	calls []int
+}
+
+func (h *history) GetTransactions(_ context.Context, _ string, count int) ([]bank.Transaction, error) {
+	h.calls = append(h.calls, count)
+	return h.txns[:min(count, len(h.txns))], nil
+}
+
+func TestBackfill(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	account := store.Account{ID: uuid.New(), AccountNumber: "0011-0100-0200004607"}
+	after := int64(1000)
+	last := bank.Transaction{Date: day, Description: "ABONO", Amount: 1000, Type: bank.TransactionCredit, BalanceAfter: &after}
+	repo := &memoryRepo{stored: store.NewTransactions(account.ID, store.TransactionSourceScrape, []bank.Transaction{last})}
+
+	// Newest first: a debit of 10 on each of the 5 days after the last
+	// stored transaction.
+	h := &history{}
+	for i := 5; i >= 1; i-- {
+		h.txns = append(h.txns, bank.Transaction{Date: day.AddDate(0, 0, i), Description: fmt.Sprint("PAGO ", i), Amount: 10, Type: bank.TransactionDebit})
+	}
+	h.txns = append(h.txns, last)
+	step := Backfill(repo, nil, 5)
+	run := func(count int, balance *bank.Balance) *Batch {
+		b := &Batch{BankCode: bank.BankBBVA, Account: account, Scraper: h, Count: count, Balance: balance}
+		require.NoError(t, Scrape().Run(context.Background(), b))
+		require.NoError(t, step.Run(context.Background(), b))
+		return b
+	}
+
+	b := run(3, &bank.Balance{CurrentBalance: 950})
+	require.NotNil(t, b.Gap, "2 debits unscraped")
+	assert.Equal(t, day, b.Gap.From)
+	assert.Equal(t, day, b.Gap.To, "fetched back to the last stored")
+	assert.Equal(t, int64(-20), b.Gap.Unexplained)
+	assert.True(t, b.Gap.Backfilled)
+	assert.Equal(t, []int{3, 6}, h.calls)
+	assert.Len(t, b.Transactions, 6)
+
+	h.calls = nil
+	assert.Nil(t, run(6, &bank.Balance{CurrentBalance: 950}).Gap, "all explained")
+	assert.Nil(t, run(6, &bank.Balance{CurrentBalance: 954}).Gap, "within the threshold")
+	assert.Nil(t, run(3, nil).Gap, "no balance to check")
+
+	b = run(6, &bank.Balance{CurrentBalance: 900})
+	require.NotNil(t, b.Gap, "a gap within the window")
+	assert.False(t, b.Gap.Backfilled)
+	assert.Equal(t, []int{6, 6, 3, 6}, h.calls, "no deeper fetch")
+}
@@ -0,0 +1,239 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/api/events"
+	"github.com/aynifx/bank-scraper/internal/report"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/google/uuid"
+)
+
+// CategoryKey is the Transaction.Extra key the categorize step writes.
+const CategoryKey = "category"
+
+// Publisher publishes the notify step's events. Satisfied by events.Hub.
+type Publisher interface {
+	Publish(e events.Event)
+}
+
+// Scrape fetches b.Count transactions of the account through b.Scraper.
+func Scrape() Step {
+	return StepFunc(func(ctx context.Context, b *Batch) error {
+		txns, err := b.Scraper.GetTransactions(ctx, b.Account.AccountNumber, b.Count)
+		if err != nil {
+			return err
+		}
+		b.Fetched, b.Transactions = txns, txns
+		return nil
+	})
+}
+
+// MaxBackfillCount is the deepest the backfill step fetches: the most any
+// scraper returns.
+const MaxBackfillCount = 250
+
+// Backfill checks the scraped transactions against the balance: starting
+// from the balance after the last stored transaction, the new ones must add
+// up to b.Balance within threshold cents. When they don't, some went
+// unscraped between the two, so it records the gap in b.Gap and, if the
+// scraped window doesn't reach back to the last stored transaction, fetches
+// again, doubling the count up to MaxBackfillCount until it does. A gap
+// within the window (a pending movement, say) is only recorded. Without a
+// balance, or a stored one to start from, there's nothing to check.
+func Backfill(txns store.TransactionRepository, dedup store.DedupConfig, threshold int64) Step {
+	return StepFunc(func(ctx context.Context, b *Batch) error {
+		if b.Balance == nil || len(b.Fetched) == 0 {
+			return nil
+		}
+		stored, err := txns.ListByAccount(ctx, b.Account.ID)
+		if err != nil {
+			return err
+		}
+		fresh, _ := unstored(dedup.For(string(b.BankCode)), b.Account.ID, stored, b.Fetched)
+		b.Gap = findGap(stored, fresh, b.Balance.CurrentBalance, threshold)
+		if b.Gap == nil {
+			return nil
+		}
+		b.Gap.To = oldest(b.Fetched)
+		if !b.Gap.To.After(b.Gap.From) {
+			return nil // Already scraped back to it: fetching deeper won't help
+		}
+
+		for count := b.Count; b.Gap.To.After(b.Gap.From) && count < MaxBackfillCount; {
+			count = min(count*2, MaxBackfillCount)
+			txns, err := b.Scraper.GetTransactions(ctx, b.Account.AccountNumber, count)
+			if err != nil {
+				return fmt.Errorf("backfill %d transactions: %w", count, err)
+			}
+			b.Fetched, b.Transactions = txns, txns
+			b.Gap.To = oldest(txns)
+			if len(txns) < count {
+				break // The bank shows no more
+			}
+		}
+		b.Gap.Backfilled = !b.Gap.To.After(b.Gap.From)
+		return nil
+	})
+}
+
+// findGap compares balance with the balance after the last stored
+// transaction that has one plus the fresh transactions from that day on,
+// and returns the gap if they differ by more than threshold.
+func findGap(stored []store.Transaction, fresh []bank.Transaction, balance, threshold int64) *store.Gap {
+	var last *store.Transaction
+	for i := len(stored) - 1; i >= 0 && last == nil; i-- {
+		if stored[i].BalanceAfter != nil {
+			last = &stored[i]
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	expected := *last.BalanceAfter
+	for _, tx := range fresh {
+		if tx.Date.Before(last.OperationDate) {
+			continue
+		}
+		if tx.Type == bank.TransactionDebit {
+			expected -= tx.Amount
+		} else {
+			expected += tx.Amount
+		}
+	}
+	if diff := balance - expected; diff > threshold || -diff > threshold {
+		return &store.Gap{From: last.OperationDate, Unexplained: diff}
+	}
+	return nil
+}
+
+// oldest returns the earliest date among txns.
+func oldest(txns []bank.Transaction) time.Time {
+	var first time.Time
+	for _, tx := range txns {
+		if first.IsZero() || tx.Date.Before(first) {
+			first = tx.Date
+		}
+	}
+	return first
+}
+
+// Dedupe drops the transactions already stored for the account, hashing
+// both sides with the bank's strategy from dedup, so what is left is new,
+// and keeps the hashes of what is left in b.Hashes for the store step.
+// Rows are compared by content rather than by stored hash, which an
+// encrypted repository keys.
+func Dedupe(txns store.TransactionRepository, dedup store.DedupConfig) Step {
+	return StepFunc(func(ctx context.Context, b *Batch) error {
+		stored, err := txns.ListByAccount(ctx, b.Account.ID)
+		if err != nil {
+			return err
+		}
+		b.Transactions, b.Hashes = unstored(dedup.For(string(b.BankCode)), b.Account.ID, stored, b.Transactions)
+		return nil
+	})
+}
+
+// unstored returns the transactions of txns not among stored, and their
+// hashes, hashing both sides with strategy.
+func unstored(strategy store.DedupStrategy, accountID uuid.UUID, stored []store.Transaction, txns []bank.Transaction) ([]bank.Transaction, [][]byte) {
+	old := make([]bank.Transaction, len(stored))
+	for i, t := range stored {
+		old[i] = t.BankTransaction()
+	}
+	known := map[string]bool{}
+	for _, rec := range strategy.NewTransactions(accountID, "", old) {
+		known[string(rec.DedupHash)] = true
+	}
+
+	records := strategy.NewTransactions(accountID, "", txns)
+	fresh, hashes := txns[:0:0], [][]byte{}
+	for i, rec := range records {
+		if !known[string(rec.DedupHash)] {
+			fresh = append(fresh, txns[i])
+			hashes = append(hashes, rec.DedupHash)
+		}
+	}
+	return fresh, hashes
+}
+
+// Categorize tags each tax movement (ITF, IGV, detraction, tax payment)
+// with its report.TaxTag under Extra[CategoryKey]. Other movements are left
+// alone.
+func Categorize() Step {
+	return StepFunc(func(_ context.Context, b *Batch) error {
+		for i := range b.Transactions {
+			tx := &b.Transactions[i]
+			tag := report.TaxTagFor(tx.Description)
+			if tag == "" {
+				continue
+			}
+			if tx.Extra == nil {
+				tx.Extra = map[string]string{}
+			}
+			tx.Extra[CategoryKey] = string(tag)
+		}
+		return nil
+	})
+}
+
+// Enrich runs enrichers over each transaction; a failure is a warning on
+// the job, not an error (see bank.Enrichers.Apply).
+func Enrich(enrichers ...bank.Enricher) Step {
+	return StepFunc(func(ctx context.Context, b *Batch) error {
+		bank.Enrichers(enrichers).Apply(ctx, b.BankCode, b.Account.AccountNumber, b.Transactions)
+		return nil
+	})
+}
+
+// Store inserts the transactions into txns and counts the new rows in
+// b.Inserted. They keep the hashes the dedupe step gave them; without one,
+// they are hashed with the bank's strategy from dedup.
+func Store(txns store.TransactionRepository, dedup store.DedupConfig) Step {
+	return StepFunc(func(ctx context.Context, b *Batch) error {
+		records := dedup.For(string(b.BankCode)).NewTransactions(b.Account.ID, store.TransactionSourceScrape, b.Transactions)
+		if len(b.Hashes) == len(records) {
+			for i := range records {
+				records[i].DedupHash = b.Hashes[i]
+			}
+		}
+		inserted, err := txns.InsertBatch(ctx, records)
+		if err != nil {
+			return err
+		}
+		b.Inserted += inserted
+		return nil
+	})
+}
+
+// Export rewrites the exports of b's profile by calling run, e.g. the
+// Run of the profile's export.Exporters.
+func Export(run func(ctx context.Context, profile string) error) Step {
+	return StepFunc(func(ctx context.Context, b *Batch) error {
+		return run(ctx, b.Profile)
+	})
+}
+
+// Notify publishes a transaction.new event per transaction, oldest first,
+// naming the account by its alias in aliases. After dedupe, that is each
+// transaction not stored before.
+func Notify(p Publisher, aliases store.AccountAliases) Step {
+	return StepFunc(func(_ context.Context, b *Batch) error {
+		for i := len(b.Transactions) - 1; i >= 0; i-- {
+			p.Publish(events.Event{
+				Type:         events.TypeTransactionNew,
+				Profile:      b.Profile,
+				BankCode:     string(b.BankCode),
+				AccountID:    b.Account.AccountNumber,
+				AccountAlias: aliases.For(b.Account.AccountNumber),
+				Operation:    "GetTransactions",
+				Data:         b.Transactions[i],
+			})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+// Package progress reports the progress of long-running commands, such as
+// multi-month backfills, with a redrawn bar and ETA on a terminal and plain
+// log-friendly lines when the output is piped or redirected.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// barWidth is the number of cells in the terminal progress bar.
+const barWidth = 24
+
+// Tracker shows progress through Total units of work, such as accounts or
+// statement windows. It is safe for concurrent use.
+type Tracker struct {
+	w     io.Writer
+	total int
+	tty   bool
+	now   func() time.Time
+	start time.Time
+
+	mu         sync.Mutex
+	lastDetail string
+	drawn      bool
+}
+
+// New returns a Tracker writing to w. The bar is redrawn in place only when
+// w is a terminal; otherwise each change is written as its own line.
+func New(w io.Writer, total int) *Tracker {
+	tty := false
+	if f, ok := w.(*os.File); ok {
+		tty = term.IsTerminal(int(f.Fd()))
+	}
+	t := &Tracker{w: w, total: total, tty: tty, now: time.Now}
+	t.start = t.now()
+	return t
+}
+
+// Update reports done units out of the total, where a fraction is the part
+// of the current unit already finished, and detail describes what's
+// happening now (e.g. "PE0011...4607: page 3, 150/250 rows").
+func (t *Tracker) Update(done float64, detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	eta := ETA(t.now().Sub(t.start), done, float64(t.total))
+	if t.tty {
+		fmt.Fprintf(t.w, "\r\x1b[K%s", t.line(done, eta, detail))
+		t.drawn = true
+		return
+	}
+	if detail == t.lastDetail {
+		return
+	}
+	t.lastDetail = detail
+	fmt.Fprintln(t.w, t.line(done, eta, detail))
+}
+
+// Finish ends the progress display with a final line, leaving the terminal
+// on a fresh line for whatever is printed next.
+func (t *Tracker) Finish(detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tty && t.drawn {
+		fmt.Fprint(t.w, "\r\x1b[K")
+	}
+	fmt.Fprintf(t.w, "%s in %s\n", detail, t.now().Sub(t.start).Round(time.Second))
+}
+
+func (t *Tracker) line(done float64, eta time.Duration, detail string) string {
+	var b strings.Builder
+	if t.tty {
+		filled := 0
+		if t.total > 0 {
+			filled = min(barWidth, int(done/float64(t.total)*barWidth))
+		}
+		b.WriteString("[" + strings.Repeat("#", filled) + strings.Repeat(".", barWidth-filled) + "] ")
+	}
+	pct := 0
+	if t.total > 0 {
+		pct = min(100, int(done/float64(t.total)*100))
+	}
+	fmt.Fprintf(&b, "%3d%% %d/%d", pct, int(done), t.total)
+	if eta > 0 {
+		fmt.Fprintf(&b, " ETA %s", eta.Round(time.Second))
+	}
+	if detail != "" {
+		b.WriteString("  " + detail)
+	}
+	return b.String()
+}
+
+// ETA estimates the time left to finish total units of work from the time
+// elapsed finishing done of them, assuming the rest go at the same pace.
+// It returns 0 when there's no basis for an estimate yet.
+func ETA(elapsed time.Duration, done, total float64) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+	return time.Duration(float64(elapsed) / done * (total - done))
+}
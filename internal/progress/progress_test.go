@@ -0,0 +1,55 @@
+package progress
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETA(t *testing.T) {
+	assert.Equal(t, 30*time.Minute, ETA(10*time.Minute, 1, 4))
+	assert.Equal(t, 5*time.Minute, ETA(10*time.Minute, 2, 3))
+	assert.Zero(t, ETA(10*time.Minute, 0, 4), "no estimate before any progress")
+	assert.Zero(t, ETA(10*time.Minute, 4, 4), "nothing left")
+}
+
+func newTestTracker(tty bool, total int) (*Tracker, *bytes.Buffer, *time.Time) {
+	var buf bytes.Buffer
+	clock := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	t := &Tracker{w: &buf, total: total, tty: tty, now: func() time.Time { return clock }}
+	t.start = clock
+	return t, &buf, &clock
+}
+
+func TestTracker_Piped(t *testing.T) {
+	tr, buf, clock := newTestTracker(false, 4)
+
+	tr.Update(0, "acct 1: page 1")
+	*clock = clock.Add(time.Minute)
+	tr.Update(1, "acct 2: page 1")
+	tr.Update(1, "acct 2: page 1") // unchanged, not repeated
+	*clock = clock.Add(time.Minute)
+	tr.Finish("backfilled 4 accounts")
+
+	assert.Equal(t, "  0% 0/4  acct 1: page 1\n"+
+		" 25% 1/4 ETA 3m0s  acct 2: page 1\n"+
+		"backfilled 4 accounts in 2m0s\n", buf.String())
+}
+
+func TestTracker_Terminal(t *testing.T) {
+	tr, buf, clock := newTestTracker(true, 2)
+
+	*clock = clock.Add(time.Minute)
+	tr.Update(1, "acct 2")
+	tr.Update(1, "acct 2")
+	tr.Finish("done")
+
+	want := "\r\x1b[K[############............]  50% 1/2 ETA 1m0s  acct 2"
+	assert.Equal(t, want+want+"\r\x1b[Kdone in 1m0s\n", buf.String())
+}
+
+func TestNew_NotTerminal(t *testing.T) {
+	assert.False(t, New(&bytes.Buffer{}, 1).tty)
+}
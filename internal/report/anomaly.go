@@ -0,0 +1,206 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
+)
+
+// Anomaly kinds.
+const (
+	AnomalyAmount      = "amount"       // Far from the usual amount of similar transactions
+	AnomalyNewPayee    = "new_payee"    // No similar transaction seen before on the account
+	AnomalyUnusualHour = "unusual_hour" // At an hour the account rarely moves
+)
+
+// Detector thresholds. Each check only speaks once it has seen enough of
+// the account to know what usual looks like.
+const (
+	anomalyZScore         = 3.0  // |z| at or above which an amount is unusual
+	anomalyMinClusterSize = 5    // Transactions in a cluster before z-scores count
+	anomalyMinHistory     = 20   // Transactions on an account before new payees and hours count
+	anomalyRareHourShare  = 0.02 // Share of timed transactions below which an hour is unusual
+	anomalyMaxHistory     = 1000 // Latest transactions per account the detector remembers
+)
+
+// Anomaly is one reason a transaction looks out of the ordinary.
+type Anomaly struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// AnomalyDetector flags unusual transactions against what it has seen on
+// the same account before: amounts several standard deviations from their
+// description cluster's mean, descriptions from a payee never seen, and
+// movements at an hour the account rarely has any. It learns from every
+// transaction it checks, remembering the latest anomalyMaxHistory per
+// account, so usual follows the account as it changes and memory stays
+// bounded. Not safe for concurrent use.
+type AnomalyDetector struct {
+	accounts map[string]*accountPattern
+}
+
+// accountPattern is what the detector learned about one account.
+type accountPattern struct {
+	history  []observation // Oldest first, at most anomalyMaxHistory
+	clusters map[string]*runningStats
+	hours    [24]int
+	timed    int // Transactions with a time of day
+}
+
+// observation is what the detector keeps of a transaction, to forget it
+// once it leaves the history.
+type observation struct {
+	cluster string
+	amount  float64
+	hour    int
+	timed   bool
+}
+
+// runningStats is Welford's online mean and variance.
+type runningStats struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (s *runningStats) add(x float64) {
+	s.n++
+	d := x - s.mean
+	s.mean += d / float64(s.n)
+	s.m2 += d * (x - s.mean)
+}
+
+// remove undoes add(x).
+func (s *runningStats) remove(x float64) {
+	s.n--
+	if s.n == 0 {
+		*s = runningStats{}
+		return
+	}
+	d := x - s.mean
+	s.mean -= d / float64(s.n)
+	s.m2 = max(0, s.m2-d*(x-s.mean))
+}
+
+func (s *runningStats) stddev() float64 {
+	if s.n < 2 {
+		return 0
+	}
+	return math.Sqrt(s.m2 / float64(s.n-1))
+}
+
+// NewAnomalyDetector creates a detector with no history. Learn seeds it,
+// e.g. from the store at startup.
+func NewAnomalyDetector() *AnomalyDetector {
+	return &AnomalyDetector{accounts: make(map[string]*accountPattern)}
+}
+
+// Observe returns what is unusual about tx on account (any key identifying
+// it, e.g. "profile/bank/number"), then learns from it.
+func (d *AnomalyDetector) Observe(account string, tx bank.Transaction) []Anomaly {
+	p := d.pattern(account)
+	o := observe(tx)
+
+	var out []Anomaly
+	stats, known := p.clusters[o.cluster]
+	switch {
+	case !known && len(p.history) >= anomalyMinHistory:
+		out = append(out, Anomaly{Kind: AnomalyNewPayee, Detail: fmt.Sprintf("first %q on this account", o.cluster)})
+	case known && stats.n >= anomalyMinClusterSize:
+		if sd := stats.stddev(); sd > 0 {
+			if z := (o.amount - stats.mean) / sd; math.Abs(z) >= anomalyZScore {
+				out = append(out, Anomaly{Kind: AnomalyAmount, Detail: fmt.Sprintf("%.1f standard deviations from the usual %q amount", z, o.cluster)})
+			}
+		}
+	}
+
+	if o.timed && p.timed >= anomalyMinHistory && float64(p.hours[o.hour])/float64(p.timed) < anomalyRareHourShare {
+		out = append(out, Anomaly{Kind: AnomalyUnusualHour, Detail: fmt.Sprintf("at %02d:00, when the account rarely moves", o.hour)})
+	}
+
+	p.learn(o)
+	return out
+}
+
+// Learn adds tx to account's history without checking it, as Observe would
+// after. Transactions are expected oldest first.
+func (d *AnomalyDetector) Learn(account string, tx bank.Transaction) {
+	d.pattern(account).learn(observe(tx))
+}
+
+func (d *AnomalyDetector) pattern(account string) *accountPattern {
+	p, ok := d.accounts[account]
+	if !ok {
+		p = &accountPattern{clusters: make(map[string]*runningStats)}
+		d.accounts[account] = p
+	}
+	return p
+}
+
+func observe(tx bank.Transaction) observation {
+	amount := float64(tx.Amount)
+	if tx.Type == bank.TransactionDebit {
+		amount = -amount
+	}
+	return observation{
+		cluster: DescriptionCluster(tx.Description),
+		amount:  amount,
+		hour:    tx.Date.Hour(),
+		timed:   hasTimeOfDay(tx),
+	}
+}
+
+// learn adds o to the history, forgetting the oldest observation past
+// anomalyMaxHistory.
+func (p *accountPattern) learn(o observation) {
+	p.history = append(p.history, o)
+	p.count(o, 1)
+	if len(p.history) > anomalyMaxHistory {
+		p.count(p.history[0], -1)
+		p.history = slices.Delete(p.history, 0, 1)
+	}
+}
+
+// count adds (delta 1) or removes (delta -1) o from the statistics.
+func (p *accountPattern) count(o observation, delta int) {
+	stats, ok := p.clusters[o.cluster]
+	if !ok {
+		stats = &runningStats{}
+		p.clusters[o.cluster] = stats
+	}
+	if delta > 0 {
+		stats.add(o.amount)
+	} else if stats.remove(o.amount); stats.n == 0 {
+		delete(p.clusters, o.cluster)
+	}
+	if o.timed {
+		p.hours[o.hour] += delta
+		p.timed += delta
+	}
+}
+
+// hasTimeOfDay reports whether the bank gave tx a time, not just a date.
+func hasTimeOfDay(tx bank.Transaction) bool {
+	h, m, s := tx.Date.Clock()
+	return h != 0 || m != 0 || s != 0
+}
+
+// DescriptionCluster groups descriptions that differ only in numbers (dates,
+// invoice and operation numbers) and casing, so recurring payments from one
+// payee fall together.
+func DescriptionCluster(description string) string {
+	s := parseutil.CleanDescription(description)
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return ' '
+		}
+		return unicode.ToUpper(r)
+	}, s)
+	return parseutil.CollapseWhitespace(s)
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+)
+
+func debit(desc string, amount int64, at time.Time) bank.Transaction {
+	return bank.Transaction{Description: desc, Amount: amount, Type: bank.TransactionDebit, Date: at}
+}
+
+func TestDescriptionCluster(t *testing.T) {
+	assert.Equal(t, "PAGO FACTURA | LUZ DEL SUR", DescriptionCluster("*C/PAGO FACTURA 000123 | LUZ DEL SUR"))
+	assert.Equal(t, DescriptionCluster("PAGO FACTURA 000123 | Luz del Sur"), DescriptionCluster("PAGO FACTURA 000456 | LUZ DEL SUR"))
+}
+
+func TestAnomalyDetector(t *testing.T) {
+	d := NewAnomalyDetector()
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	// Weekly utility payments around 150.00, during office hours.
+	amounts := []int64{15_000, 14_800, 15_200, 15_100, 14_900, 15_050, 14_950, 15_000, 15_100, 14_900}
+	for i, a := range amounts {
+		assert.Empty(t, d.Observe("acct", debit(fmt.Sprintf("PAGO LUZ %06d", i), a, day.AddDate(0, 0, 7*i))))
+	}
+	for i := range 10 {
+		assert.Empty(t, d.Observe("acct", debit("TRANSF PROVEEDOR", 50_000, day.AddDate(0, 0, i).Add(time.Hour))))
+	}
+
+	got := d.Observe("acct", debit("PAGO LUZ 000099", 90_000, day.AddDate(0, 3, 0)))
+	assert.Equal(t, []string{AnomalyAmount}, kinds(got))
+
+	got = d.Observe("acct", debit("RETIRO CAJERO", 20_000, day.AddDate(0, 3, 1)))
+	assert.Equal(t, []string{AnomalyNewPayee}, kinds(got))
+
+	got = d.Observe("acct", debit("TRANSF PROVEEDOR", 50_000, time.Date(2026, 6, 3, 3, 12, 0, 0, time.UTC)))
+	assert.Equal(t, []string{AnomalyUnusualHour}, kinds(got))
+
+	assert.Empty(t, d.Observe("other", debit("RETIRO CAJERO", 999_999, day)), "accounts learn separately")
+}
+
+func TestAnomalyDetector_DateOnlyNeverUnusualHour(t *testing.T) {
+	d := NewAnomalyDetector()
+	midnight := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	for i := range 30 {
+		assert.Empty(t, d.Observe("acct", debit("TRANSF PROVEEDOR", 50_000, midnight.AddDate(0, 0, i))))
+	}
+}
+
+func TestAnomalyDetector_Learn(t *testing.T) {
+	d := NewAnomalyDetector()
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	for i := range 20 {
+		d.Learn("acct", debit("TRANSF PROVEEDOR", 50_000+int64(i%3)*100, day.AddDate(0, 0, i)))
+	}
+
+	got := d.Observe("acct", debit("RETIRO CAJERO", 20_000, day.AddDate(0, 1, 0)))
+	assert.Equal(t, []string{AnomalyNewPayee}, kinds(got), "a seeded detector speaks at once")
+}
+
+func TestAnomalyDetector_ForgetsOldHistory(t *testing.T) {
+	d := NewAnomalyDetector()
+	day := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	d.Learn("acct", debit("RETIRO CAJERO", 20_000, day))
+	for i := range anomalyMaxHistory {
+		d.Learn("acct", debit("TRANSF PROVEEDOR", 50_000+int64(i%3)*100, day.AddDate(0, 0, i)))
+	}
+
+	p := d.accounts["acct"]
+	assert.Len(t, p.history, anomalyMaxHistory)
+	assert.NotContains(t, p.clusters, "RETIRO CAJERO")
+	assert.Equal(t, anomalyMaxHistory, p.timed)
+
+	got := d.Observe("acct", debit("RETIRO CAJERO", 20_000, day.AddDate(1, 0, 0)))
+	assert.Equal(t, []string{AnomalyNewPayee}, kinds(got), "forgotten with the oldest transactions")
+}
+
+func TestRunningStats_Remove(t *testing.T) {
+	var s runningStats
+	for _, x := range []float64{10, 20, 30, 1_000} {
+		s.add(x)
+	}
+	s.remove(10)
+	assert.InDelta(t, 350.0, s.mean, 1e-9)
+
+	var want runningStats
+	for _, x := range []float64{20, 30, 1_000} {
+		want.add(x)
+	}
+	assert.InDelta(t, want.stddev(), s.stddev(), 1e-9)
+}
+
+func kinds(as []Anomaly) []string {
+	var out []string
+	for _, a := range as {
+		out = append(out, a.Kind)
+	}
+	return out
+}
@@ -0,0 +1,230 @@
+// Package report builds derived views (projections, summaries) from scraped
+// bank data. It does no I/O: callers gather balances and transactions and
+// pass them in.
+package report
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// Item sources.
+const (
+	SourcePending   = "pending"
+	SourceScheduled = "scheduled"
+)
+
+// Item is a dated cash movement expected to hit an account on or after the
+// forecast start. Amount is signed, in cents: positive adds to the position.
+type Item struct {
+	Date        time.Time     `json:"date"`
+	Currency    bank.Currency `json:"currency"`
+	Amount      int64         `json:"amount"`
+	Source      string        `json:"source"` // SourcePending or SourceScheduled
+	Description string        `json:"description"`
+}
+
+// DayPosition is the projected position of one currency at the end of a day.
+type DayPosition struct {
+	Date     time.Time `json:"date"`
+	Inflows  int64     `json:"inflows"`  // Sum of positive items that day
+	Outflows int64     `json:"outflows"` // Sum of negative items that day, as a positive number
+	Closing  int64     `json:"closing"`  // Position after the day's items
+}
+
+// CurrencyForecast is the day-by-day projection for one currency.
+type CurrencyForecast struct {
+	Currency bank.Currency `json:"currency"`
+	Opening  int64         `json:"opening"` // Sum of available balances at AsOf
+	Days     []DayPosition `json:"days"`
+	Items    []Item        `json:"items"` // Items included in the projection, by date
+}
+
+// CashForecast is an N-day cash position projection per currency.
+type CashForecast struct {
+	AsOf       time.Time          `json:"as_of"`
+	Days       int                `json:"days"`
+	Currencies []CurrencyForecast `json:"currencies"`
+}
+
+// Forecast projects the cash position per currency for days days starting at
+// asOf (day 0 is asOf's date). The opening position is the sum of available
+// balances; items before asOf's date are folded into day 0 and items after the
+// horizon are ignored.
+func Forecast(balances []bank.Balance, items []Item, asOf time.Time, days int) *CashForecast {
+	if days < 1 {
+		days = 1
+	}
+	start := startOfDay(asOf)
+
+	opening := map[bank.Currency]int64{}
+	for _, b := range balances {
+		opening[b.Currency] += b.AvailableBalance
+	}
+
+	byCurrency := map[bank.Currency][]Item{}
+	for _, it := range items {
+		if dayIndex(start, it.Date) >= days {
+			continue
+		}
+		byCurrency[it.Currency] = append(byCurrency[it.Currency], it)
+		if _, ok := opening[it.Currency]; !ok {
+			opening[it.Currency] = 0
+		}
+	}
+
+	currencies := make([]bank.Currency, 0, len(opening))
+	for c := range opening {
+		currencies = append(currencies, c)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	fc := &CashForecast{AsOf: asOf, Days: days}
+	for _, c := range currencies {
+		cItems := byCurrency[c]
+		sort.SliceStable(cItems, func(i, j int) bool { return cItems[i].Date.Before(cItems[j].Date) })
+
+		positions := make([]DayPosition, days)
+		for d := range positions {
+			positions[d].Date = start.AddDate(0, 0, d)
+		}
+		for _, it := range cItems {
+			d := max(dayIndex(start, it.Date), 0)
+			if it.Amount >= 0 {
+				positions[d].Inflows += it.Amount
+			} else {
+				positions[d].Outflows -= it.Amount
+			}
+		}
+		running := opening[c]
+		for d := range positions {
+			running += positions[d].Inflows - positions[d].Outflows
+			positions[d].Closing = running
+		}
+
+		if cItems == nil {
+			cItems = []Item{}
+		}
+		fc.Currencies = append(fc.Currencies, CurrencyForecast{
+			Currency: c,
+			Opening:  opening[c],
+			Days:     positions,
+			Items:    cItems,
+		})
+	}
+	return fc
+}
+
+// PendingItems returns the transactions in txns that were booked but not yet
+// settled at asOf (value date after asOf's date), as forecast items. Their
+// effect on the available balance lands on the value date.
+func PendingItems(txns []bank.Transaction, currency bank.Currency, asOf time.Time) []Item {
+	start := startOfDay(asOf)
+	var items []Item
+	for _, tx := range txns {
+		if tx.ValueDate.IsZero() || !startOfDay(tx.ValueDate).After(start) {
+			continue
+		}
+		amount := tx.Amount
+		if tx.Type == bank.TransactionDebit {
+			amount = -amount
+		}
+		items = append(items, Item{
+			Date:        tx.ValueDate,
+			Currency:    currency,
+			Amount:      amount,
+			Source:      SourcePending,
+			Description: firstNonEmpty(tx.CleanDescription, tx.Description),
+		})
+	}
+	return items
+}
+
+// ScheduledItems expands scheduled operations into forecast items up to (but
+// excluding) until. Recurring operations repeat according to their
+// Recurrence label; unrecognized labels are treated as one-off.
+func ScheduledItems(ops []bank.ScheduledOperation, until time.Time) []Item {
+	var items []Item
+	for _, op := range ops {
+		amount := op.Amount
+		if op.Type != bank.TransactionCredit {
+			amount = -amount
+		}
+		desc := op.Description
+		if op.Counterparty != "" {
+			desc += " | " + op.Counterparty
+		}
+		step := recurrenceStep(op.Recurrence)
+		for date, n := op.ScheduledDate, 0; date.Before(until); n++ {
+			items = append(items, Item{
+				Date:        date,
+				Currency:    op.Currency,
+				Amount:      amount,
+				Source:      SourceScheduled,
+				Description: desc,
+			})
+			if step == nil {
+				break
+			}
+			date = step(op.ScheduledDate, n+1)
+		}
+	}
+	return items
+}
+
+// recurrenceStep returns a function giving the n-th occurrence after first,
+// or nil for one-off operations. Labels are BBVA/Interbank Spanish wording.
+func recurrenceStep(label string) func(first time.Time, n int) time.Time {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "diario", "diaria":
+		return func(first time.Time, n int) time.Time { return first.AddDate(0, 0, n) }
+	case "semanal":
+		return func(first time.Time, n int) time.Time { return first.AddDate(0, 0, 7*n) }
+	case "quincenal":
+		return func(first time.Time, n int) time.Time { return first.AddDate(0, 0, 14*n) }
+	case "mensual":
+		return func(first time.Time, n int) time.Time { return addMonths(first, n) }
+	case "bimestral":
+		return func(first time.Time, n int) time.Time { return addMonths(first, 2*n) }
+	case "trimestral":
+		return func(first time.Time, n int) time.Time { return addMonths(first, 3*n) }
+	case "semestral":
+		return func(first time.Time, n int) time.Time { return addMonths(first, 6*n) }
+	case "anual":
+		return func(first time.Time, n int) time.Time { return addMonths(first, 12*n) }
+	default:
+		return nil
+	}
+}
+
+// addMonths returns t moved n months, on t's day of the month or the last
+// day of a shorter month: a payment on January 31 falls on February 28, not
+// March 3 as with AddDate.
+func addMonths(t time.Time, n int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return firstOfMonth.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dayIndex returns how many calendar days t is after start (negative if before).
+func dayIndex(start, t time.Time) int {
+	t = startOfDay(t.In(start.Location()))
+	return int(math.Round(t.Sub(start).Hours() / 24))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestForecast(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	balances := []bank.Balance{
+		{AccountID: "a1", Currency: bank.CurrencyPEN, AvailableBalance: 100_000},
+		{AccountID: "a2", Currency: bank.CurrencyPEN, AvailableBalance: 50_000},
+		{AccountID: "a3", Currency: bank.CurrencyUSD, AvailableBalance: 20_000},
+	}
+	items := []Item{
+		{Date: date(2026, 3, 2), Currency: bank.CurrencyPEN, Amount: -30_000, Source: SourceScheduled},
+		{Date: date(2026, 3, 2), Currency: bank.CurrencyPEN, Amount: 5_000, Source: SourcePending},
+		{Date: date(2026, 2, 27), Currency: bank.CurrencyPEN, Amount: -1_000, Source: SourcePending},    // folded into day 0
+		{Date: date(2026, 3, 10), Currency: bank.CurrencyPEN, Amount: -99_999, Source: SourceScheduled}, // beyond horizon
+		{Date: date(2026, 3, 3), Currency: bank.CurrencyUSD, Amount: -25_000, Source: SourceScheduled},
+	}
+
+	fc := Forecast(balances, items, asOf, 3)
+
+	assert.Equal(t, 3, fc.Days)
+	require.Len(t, fc.Currencies, 2)
+
+	pen := fc.Currencies[0]
+	assert.Equal(t, bank.CurrencyPEN, pen.Currency)
+	assert.Equal(t, int64(150_000), pen.Opening)
+	require.Len(t, pen.Days, 3)
+	assert.Equal(t, date(2026, 3, 1), pen.Days[0].Date)
+	assert.Equal(t, int64(1_000), pen.Days[0].Outflows)
+	assert.Equal(t, int64(149_000), pen.Days[0].Closing)
+	assert.Equal(t, int64(5_000), pen.Days[1].Inflows)
+	assert.Equal(t, int64(30_000), pen.Days[1].Outflows)
+	assert.Equal(t, int64(124_000), pen.Days[1].Closing)
+	assert.Equal(t, int64(124_000), pen.Days[2].Closing)
+	assert.Len(t, pen.Items, 3)
+
+	usd := fc.Currencies[1]
+	assert.Equal(t, bank.CurrencyUSD, usd.Currency)
+	assert.Equal(t, int64(-5_000), usd.Days[2].Closing, "projection may go negative")
+}
+
+func TestForecast_CurrencyOnlyInItems(t *testing.T) {
+	fc := Forecast(nil, []Item{{Date: date(2026, 3, 1), Currency: bank.CurrencyUSD, Amount: 100}}, date(2026, 3, 1), 1)
+
+	require.Len(t, fc.Currencies, 1)
+	assert.Equal(t, int64(0), fc.Currencies[0].Opening)
+	assert.Equal(t, int64(100), fc.Currencies[0].Days[0].Closing)
+}
+
+func TestPendingItems(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	txns := []bank.Transaction{
+		{Description: "settled", Amount: 100, Type: bank.TransactionCredit, ValueDate: date(2026, 3, 1)},
+		{Description: "PAGO  RAW", CleanDescription: "PAGO", Amount: 500, Type: bank.TransactionDebit, ValueDate: date(2026, 3, 3)},
+		{Description: "ABONO", Amount: 200, Type: bank.TransactionCredit, ValueDate: date(2026, 3, 2)},
+		{Description: "no value date", Amount: 300, Type: bank.TransactionCredit},
+	}
+
+	items := PendingItems(txns, bank.CurrencyPEN, asOf)
+
+	require.Len(t, items, 2)
+	assert.Equal(t, int64(-500), items[0].Amount)
+	assert.Equal(t, "PAGO", items[0].Description)
+	assert.Equal(t, SourcePending, items[0].Source)
+	assert.Equal(t, bank.CurrencyPEN, items[0].Currency)
+	assert.Equal(t, int64(200), items[1].Amount)
+}
+
+func TestScheduledItems(t *testing.T) {
+	ops := []bank.ScheduledOperation{
+		{Description: "Transferencia", Counterparty: "PROVEEDOR", Amount: 1_000, Currency: bank.CurrencyPEN,
+			Type: bank.TransactionDebit, ScheduledDate: date(2026, 3, 5), Recurrence: "Semanal"},
+		{Description: "Pago unico", Amount: 2_000, Currency: bank.CurrencyUSD,
+			Type: bank.TransactionDebit, ScheduledDate: date(2026, 3, 6)},
+		{Description: "Mensual", Amount: 3_000, Currency: bank.CurrencyPEN,
+			Type: bank.TransactionDebit, ScheduledDate: date(2026, 3, 31), Recurrence: "Mensual"},
+	}
+
+	items := ScheduledItems(ops, date(2026, 3, 20))
+
+	require.Len(t, items, 4, "weekly op repeats, monthly op starts after the horizon")
+	assert.Equal(t, date(2026, 3, 5), items[0].Date)
+	assert.Equal(t, date(2026, 3, 12), items[1].Date)
+	assert.Equal(t, date(2026, 3, 19), items[2].Date)
+	assert.Equal(t, "Transferencia | PROVEEDOR", items[0].Description)
+	assert.Equal(t, int64(-1_000), items[0].Amount)
+	assert.Equal(t, date(2026, 3, 6), items[3].Date)
+	assert.Equal(t, bank.CurrencyUSD, items[3].Currency)
+	assert.Equal(t, SourceScheduled, items[3].Source)
+}
+
+func TestRecurrenceStep(t *testing.T) {
+	first := date(2026, 1, 15)
+	tests := []struct {
+		label string
+		want  time.Time
+	}{
+		{"Diario", date(2026, 1, 16)},
+		{"semanal", date(2026, 1, 22)},
+		{"Quincenal", date(2026, 1, 29)},
+		{"MENSUAL", date(2026, 2, 15)},
+		{"Trimestral", date(2026, 4, 15)},
+		{"Anual", date(2027, 1, 15)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.label, func(t *testing.T) {
+			step := recurrenceStep(tc.label)
+			require.NotNil(t, step)
+			assert.Equal(t, tc.want, step(first, 1))
+		})
+	}
+	assert.Nil(t, recurrenceStep(""))
+	assert.Nil(t, recurrenceStep("Única vez"))
+}
+
+func TestRecurrenceStep_MonthEnd(t *testing.T) {
+	monthly := recurrenceStep("Mensual")
+	first := date(2026, 1, 31)
+	assert.Equal(t, date(2026, 2, 28), monthly(first, 1), "clamped to February's last day")
+	assert.Equal(t, date(2026, 3, 31), monthly(first, 2), "back on the 31st")
+	assert.Equal(t, date(2026, 4, 30), monthly(first, 3))
+
+	assert.Equal(t, date(2026, 2, 28), recurrenceStep("Bimestral")(date(2025, 12, 31), 1))
+	assert.Equal(t, date(2027, 2, 28), recurrenceStep("Anual")(date(2024, 2, 29), 3))
+	assert.Equal(t, date(2028, 2, 29), recurrenceStep("Anual")(date(2024, 2, 29), 4))
+}
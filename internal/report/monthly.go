@@ -0,0 +1,222 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// StatementAccount is one account's stored history, the input of
+// NewMonthlyStatement. Transactions may span any period; the statement picks
+// its month out of them and uses the rest to work out balances.
+type StatementAccount struct {
+	BankCode      bank.Code
+	AccountNumber string
+	Alias         string
+	Currency      bank.Currency
+	Transactions  []bank.Transaction
+}
+
+// StatementLine is one transaction of a monthly statement. Amount is signed
+// (debits negative).
+type StatementLine struct {
+	Date         time.Time `json:"date"`
+	ValueDate    time.Time `json:"value_date,omitzero"`
+	Description  string    `json:"description"`
+	Reference    string    `json:"reference,omitempty"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter *int64    `json:"balance_after,omitempty"`
+	TaxTag       TaxTag    `json:"tax_tag,omitempty"`
+}
+
+// AccountStatement is one account's month. Opening and Closing are nil when
+// no stored transaction carries a running balance to derive them from.
+type AccountStatement struct {
+	BankCode      bank.Code       `json:"bank_code"`
+	AccountNumber string          `json:"account_number"`
+	Alias         string          `json:"alias,omitempty"`
+	Currency      bank.Currency   `json:"currency"`
+	Opening       *int64          `json:"opening_balance,omitempty"`
+	Closing       *int64          `json:"closing_balance,omitempty"`
+	Credits       int64           `json:"credits"`
+	Debits        int64           `json:"debits"`
+	ITF           int64           `json:"itf"` // ITF charged, net of reversals
+	Lines         []StatementLine `json:"transactions"`
+}
+
+// CurrencyTotals adds up the accounts of one currency. Balances only count
+// accounts whose balances are known.
+type CurrencyTotals struct {
+	Currency bank.Currency `json:"currency"`
+	Opening  int64         `json:"opening_balance"`
+	Closing  int64         `json:"closing_balance"`
+	Credits  int64         `json:"credits"`
+	Debits   int64         `json:"debits"`
+	ITF      int64         `json:"itf"`
+}
+
+// FXSummary converts the per-currency totals to PEN at one rate.
+type FXSummary struct {
+	USDPENRate float64          `json:"usd_pen_rate,omitempty"`
+	Totals     []CurrencyTotals `json:"totals"`
+	OpeningPEN int64            `json:"opening_pen"`
+	ClosingPEN int64            `json:"closing_pen"`
+	Excluded   []bank.Currency  `json:"excluded,omitempty"` // Currencies left out of the PEN figures (no rate)
+}
+
+// MonthlyStatement is the closing report of one calendar month across
+// accounts.
+type MonthlyStatement struct {
+	Month       string             `json:"month"` // YYYY-MM
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"` // Last day of the month
+	GeneratedAt time.Time          `json:"generated_at"`
+	Accounts    []AccountStatement `json:"accounts"`
+	FX          FXSummary          `json:"fx"`
+	Incomplete  int                `json:"incomplete"` // Accounts without a known balance, left out of the balance totals
+}
+
+// NewMonthlyStatement builds the statement for the calendar month of month.
+// usdPENRate is soles per dollar, as in Summarize. Accounts are sorted by
+// bank, then account number.
+func NewMonthlyStatement(month time.Time, accounts []StatementAccount, usdPENRate float64, now time.Time) *MonthlyStatement {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	end := from.AddDate(0, 1, 0)
+	s := &MonthlyStatement{
+		Month:       from.Format("2006-01"),
+		From:        from,
+		To:          end.AddDate(0, 0, -1),
+		GeneratedAt: now,
+		FX:          FXSummary{USDPENRate: usdPENRate},
+	}
+
+	totals := map[bank.Currency]*CurrencyTotals{}
+	for _, a := range accounts {
+		st := accountStatement(a, from, end)
+		s.Accounts = append(s.Accounts, st)
+
+		t, ok := totals[a.Currency]
+		if !ok {
+			t = &CurrencyTotals{Currency: a.Currency}
+			totals[a.Currency] = t
+		}
+		t.Credits += st.Credits
+		t.Debits += st.Debits
+		t.ITF += st.ITF
+		if st.Opening == nil {
+			s.Incomplete++
+			continue
+		}
+		t.Opening += *st.Opening
+		t.Closing += *st.Closing
+	}
+	sort.SliceStable(s.Accounts, func(i, j int) bool {
+		if s.Accounts[i].BankCode != s.Accounts[j].BankCode {
+			return s.Accounts[i].BankCode < s.Accounts[j].BankCode
+		}
+		return s.Accounts[i].AccountNumber < s.Accounts[j].AccountNumber
+	})
+
+	currencies := make([]bank.Currency, 0, len(totals))
+	for c := range totals {
+		currencies = append(currencies, c)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+	for _, c := range currencies {
+		t := *totals[c]
+		s.FX.Totals = append(s.FX.Totals, t)
+		opening, ok := ToPEN(t.Opening, c, usdPENRate)
+		if !ok {
+			s.FX.Excluded = append(s.FX.Excluded, c)
+			continue
+		}
+		closing, _ := ToPEN(t.Closing, c, usdPENRate)
+		s.FX.OpeningPEN += opening
+		s.FX.ClosingPEN += closing
+	}
+	return s
+}
+
+// accountStatement picks a's transactions in [from, end) and carries its
+// running balance up to them.
+func accountStatement(a StatementAccount, from, end time.Time) AccountStatement {
+	st := AccountStatement{
+		BankCode:      a.BankCode,
+		AccountNumber: a.AccountNumber,
+		Alias:         a.Alias,
+		Currency:      a.Currency,
+		Lines:         []StatementLine{},
+	}
+
+	txns := append([]bank.Transaction(nil), a.Transactions...)
+	sort.SliceStable(txns, func(i, j int) bool { return txns[i].Date.Before(txns[j].Date) })
+
+	balance, known := startingBalance(txns)
+	for _, tx := range txns {
+		if !tx.Date.Before(end) {
+			break
+		}
+		amount := signedAmount(tx)
+		if !tx.Date.Before(from) && st.Opening == nil && known {
+			opening := balance
+			st.Opening = &opening
+		}
+		if tx.BalanceAfter != nil {
+			balance = *tx.BalanceAfter
+		} else {
+			balance += amount
+		}
+		if tx.Date.Before(from) {
+			continue
+		}
+
+		tag := TaxTagFor(tx.Description)
+		if amount < 0 {
+			st.Debits -= amount
+		} else {
+			st.Credits += amount
+		}
+		if tag == TaxITF {
+			st.ITF -= amount
+		}
+		st.Lines = append(st.Lines, StatementLine{
+			Date:         tx.Date,
+			ValueDate:    tx.ValueDate,
+			Description:  tx.Description,
+			Reference:    tx.Reference,
+			Amount:       amount,
+			BalanceAfter: tx.BalanceAfter,
+			TaxTag:       tag,
+		})
+	}
+	if known {
+		if st.Opening == nil { // No movements this month
+			opening := balance
+			st.Opening = &opening
+		}
+		closing := balance
+		st.Closing = &closing
+	}
+	return st
+}
+
+// startingBalance works out the balance before the first of txns (sorted)
+// from the first running balance the bank reported. ok is false if none did.
+func startingBalance(txns []bank.Transaction) (balance int64, ok bool) {
+	var net int64
+	for _, tx := range txns {
+		net += signedAmount(tx)
+		if tx.BalanceAfter != nil {
+			return *tx.BalanceAfter - net, true
+		}
+	}
+	return 0, false
+}
+
+func signedAmount(tx bank.Transaction) int64 {
+	if tx.Type == bank.TransactionDebit {
+		return -tx.Amount
+	}
+	return tx.Amount
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/csv"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/money"
+)
+
+// statementCSVHeader is the first row of WriteCSV's output.
+var statementCSVHeader = []string{
+	"bank_code", "account_number", "currency", "row", "date", "value_date",
+	"description", "reference", "amount", "balance", "tax_tag",
+}
+
+// WriteCSV writes s as one CSV table: per account an "opening" row, its
+// transactions and a "closing" row, with signed decimal amounts. Balances
+// are empty where unknown.
+func (s *MonthlyStatement) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(statementCSVHeader)
+	for _, a := range s.Accounts {
+		row := func(kind string, date, valueDate time.Time, desc, ref, amount string, balance *int64, tag TaxTag) {
+			_ = cw.Write([]string{
+				string(a.BankCode), a.AccountNumber, string(a.Currency), kind,
+				formatDate(date), formatDate(valueDate), desc, ref, amount, formatBalance(balance), string(tag),
+			})
+		}
+		row("opening", s.From, time.Time{}, "", "", "", a.Opening, "")
+		for _, l := range a.Lines {
+			row("transaction", l.Date, l.ValueDate, l.Description, l.Reference, money.FormatCents(l.Amount), l.BalanceAfter, l.TaxTag)
+		}
+		row("closing", s.To, time.Time{}, "", "", "", a.Closing, "")
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteHTML writes s as a printable HTML page, which the CLI prints to PDF.
+func (s *MonthlyStatement) WriteHTML(w io.Writer) error {
+	return statementTemplate.Execute(w, s)
+}
+
+var statementTemplate = template.Must(template.New("statement").Funcs(template.FuncMap{
+	"cents":   money.FormatCents,
+	"balance": formatBalance,
+	"date":    formatDate,
+}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Estado de cuenta {{.Month}}</title>
+<style>
+body { font: 10pt sans-serif; margin: 1.5cm; }
+h1 { font-size: 14pt; } h2 { font-size: 12pt; margin-top: 1.5em; page-break-after: avoid; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border-bottom: 1px solid #ccc; padding: 2px 4px; text-align: left; }
+td.num, th.num { text-align: right; white-space: nowrap; }
+tr.total td { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Estado de cuenta mensual {{.Month}}</h1>
+<p>{{date .From}} al {{date .To}} &middot; generado {{.GeneratedAt.Format "2006-01-02 15:04"}}</p>
+{{range .Accounts}}
+<h2>{{.BankCode}} {{.AccountNumber}}{{with .Alias}} ({{.}}){{end}} &middot; {{.Currency}}</h2>
+<table>
+<tr><th>Fecha</th><th>Valor</th><th>Descripción</th><th>Referencia</th><th class="num">Importe</th><th class="num">Saldo</th><th>Tributo</th></tr>
+<tr class="total"><td colspan="5">Saldo inicial</td><td class="num">{{balance .Opening}}</td><td></td></tr>
+{{range .Lines}}<tr><td>{{date .Date}}</td><td>{{date .ValueDate}}</td><td>{{.Description}}</td><td>{{.Reference}}</td><td class="num">{{cents .Amount}}</td><td class="num">{{balance .BalanceAfter}}</td><td>{{.TaxTag}}</td></tr>
+{{end}}<tr class="total"><td colspan="5">Saldo final</td><td class="num">{{balance .Closing}}</td><td></td></tr>
+</table>
+<p>Abonos {{cents .Credits}} &middot; Cargos {{cents .Debits}} &middot; ITF {{cents .ITF}}</p>
+{{end}}
+<h2>Resumen por moneda</h2>
+<table>
+<tr><th>Moneda</th><th class="num">Saldo inicial</th><th class="num">Abonos</th><th class="num">Cargos</th><th class="num">ITF</th><th class="num">Saldo final</th></tr>
+{{range .FX.Totals}}<tr><td>{{.Currency}}</td><td class="num">{{cents .Opening}}</td><td class="num">{{cents .Credits}}</td><td class="num">{{cents .Debits}}</td><td class="num">{{cents .ITF}}</td><td class="num">{{cents .Closing}}</td></tr>
+{{end}}<tr class="total"><td>PEN equivalente{{if .FX.USDPENRate}} (USD/PEN {{printf "%.4f" .FX.USDPENRate}}){{end}}</td><td class="num">{{cents .FX.OpeningPEN}}</td><td colspan="3"></td><td class="num">{{cents .FX.ClosingPEN}}</td></tr>
+</table>
+{{with .FX.Excluded}}<p>Sin tipo de cambio, fuera del equivalente en PEN: {{range .}}{{.}} {{end}}</p>{{end}}
+{{with .Incomplete}}<p>{{.}} cuenta(s) sin saldo conocido, fuera de los saldos totales.</p>{{end}}
+</body>
+</html>
+`))
+
+func formatBalance(cents *int64) string {
+	if cents == nil {
+		return ""
+	}
+	return money.FormatCents(*cents)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
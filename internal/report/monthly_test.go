@@ -0,0 +1,92 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(v int64) *int64 { return &v }
+
+func TestNewMonthlyStatement(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	pen := StatementAccount{
+		BankCode: "BBVA", AccountNumber: "0011", Currency: bank.CurrencyPEN,
+		Transactions: []bank.Transaction{
+			{Date: day(2, 27), Description: "ABONO CLIENTE", Amount: 100_000, Type: bank.TransactionCredit},
+			{Date: day(3, 2), Description: "PAGO PROVEEDOR", Amount: 20_000, Type: bank.TransactionDebit},
+			{Date: day(3, 2), Description: "ITF", Amount: 1, Type: bank.TransactionDebit, BalanceAfter: ptr(179_999)},
+			{Date: day(3, 20), Description: "ABONO CLIENTE", Amount: 5_000, Type: bank.TransactionCredit},
+			{Date: day(4, 1), Description: "PAGO PROVEEDOR", Amount: 9_999, Type: bank.TransactionDebit},
+		},
+	}
+	usd := StatementAccount{
+		BankCode: "BBVA", AccountNumber: "0002", Currency: bank.CurrencyUSD,
+		Transactions: []bank.Transaction{
+			{Date: day(1, 15), Description: "ABONO", Amount: 10_000, Type: bank.TransactionCredit, BalanceAfter: ptr(10_000)},
+		},
+	}
+	unknown := StatementAccount{
+		BankCode: "BCP", AccountNumber: "0099", Currency: bank.CurrencyPEN,
+		Transactions: []bank.Transaction{
+			{Date: day(3, 10), Description: "ABONO", Amount: 700, Type: bank.TransactionCredit},
+		},
+	}
+
+	s := NewMonthlyStatement(day(3, 15), []StatementAccount{unknown, pen, usd}, 3.75, day(4, 2))
+
+	assert.Equal(t, "2026-03", s.Month)
+	assert.Equal(t, day(3, 1), s.From)
+	assert.Equal(t, day(3, 31), s.To)
+	require.Len(t, s.Accounts, 3)
+	assert.Equal(t, []string{"0002", "0011", "0099"}, []string{s.Accounts[0].AccountNumber, s.Accounts[1].AccountNumber, s.Accounts[2].AccountNumber})
+
+	a := s.Accounts[1]
+	assert.Equal(t, ptr(200_000), a.Opening, "derived back from the first reported balance")
+	assert.Equal(t, ptr(184_999), a.Closing)
+	assert.Equal(t, int64(5_000), a.Credits)
+	assert.Equal(t, int64(20_001), a.Debits)
+	assert.Equal(t, int64(1), a.ITF)
+	require.Len(t, a.Lines, 3, "other months left out")
+	assert.Equal(t, int64(-20_000), a.Lines[0].Amount)
+	assert.Equal(t, TaxITF, a.Lines[1].TaxTag)
+
+	assert.Equal(t, ptr(10_000), s.Accounts[0].Opening, "balance carried into a month without movements")
+	assert.Equal(t, ptr(10_000), s.Accounts[0].Closing)
+	assert.Empty(t, s.Accounts[0].Lines)
+
+	assert.Nil(t, s.Accounts[2].Opening)
+	assert.Nil(t, s.Accounts[2].Closing)
+	assert.Equal(t, 1, s.Incomplete)
+
+	require.Len(t, s.FX.Totals, 2)
+	assert.Equal(t, CurrencyTotals{Currency: bank.CurrencyPEN, Opening: 200_000, Closing: 184_999, Credits: 5_700, Debits: 20_001, ITF: 1}, s.FX.Totals[0])
+	assert.Equal(t, int64(200_000+37_500), s.FX.OpeningPEN)
+	assert.Equal(t, int64(184_999+37_500), s.FX.ClosingPEN)
+	assert.Empty(t, s.FX.Excluded)
+}
+
+func TestMonthlyStatement_WriteCSV(t *testing.T) {
+	s := NewMonthlyStatement(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), []StatementAccount{{
+		BankCode: "BBVA", AccountNumber: "0011", Currency: bank.CurrencyPEN,
+		Transactions: []bank.Transaction{
+			{Date: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Description: "ITF", Amount: 50, Type: bank.TransactionDebit, BalanceAfter: ptr(99_950)},
+		},
+	}}, 0, time.Now())
+
+	var b strings.Builder
+	require.NoError(t, s.WriteCSV(&b))
+	assert.Equal(t, "bank_code,account_number,currency,row,date,value_date,description,reference,amount,balance,tax_tag\n"+
+		"BBVA,0011,PEN,opening,2026-03-01,,,,,1000.00,\n"+
+		"BBVA,0011,PEN,transaction,2026-03-03,,ITF,,-0.50,999.50,itf\n"+
+		"BBVA,0011,PEN,closing,2026-03-31,,,,,999.50,\n", b.String())
+
+	b.Reset()
+	require.NoError(t, s.WriteHTML(&b))
+	assert.Contains(t, b.String(), "Estado de cuenta mensual 2026-03")
+	assert.Contains(t, b.String(), "999.50")
+}
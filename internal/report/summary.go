@@ -0,0 +1,125 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/money"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// AccountSummary is one row of the multi-account summary. When the bank could
+// not be read, Error is set, the balances are zero and LastSyncedAt tells how
+// old the last good data is.
+type AccountSummary struct {
+	BankCode         bank.Code     `json:"bank_code"`
+	AccountNumber    string        `json:"account_number"`
+	Alias            string        `json:"alias,omitempty"` // From ACCOUNT_ALIASES
+	Currency         bank.Currency `json:"currency"`
+	AvailableBalance int64         `json:"available_balance"`
+	CurrentBalance   int64         `json:"current_balance"`
+	LastTransaction  *time.Time    `json:"last_transaction,omitempty"` // Most recent operation date seen
+	FetchedAt        *time.Time    `json:"fetched_at,omitempty"`       // When this run read the balance
+	LastSyncedAt     *time.Time    `json:"last_synced_at,omitempty"`   // Previous successful sync, from the store
+	Error            string        `json:"error,omitempty"`
+	Err              error         `json:"-"` // Error as returned, for classifying it
+}
+
+// Fresh reports whether the row holds data read in this run.
+func (a AccountSummary) Fresh() bool {
+	return a.Error == "" && a.FetchedAt != nil
+}
+
+// Age returns how old the row's data is at now: since FetchedAt for fresh
+// rows, since LastSyncedAt otherwise. ok is false when neither is known.
+func (a AccountSummary) Age(now time.Time) (age time.Duration, ok bool) {
+	switch {
+	case a.Fresh():
+		return now.Sub(*a.FetchedAt), true
+	case a.LastSyncedAt != nil:
+		return now.Sub(*a.LastSyncedAt), true
+	default:
+		return 0, false
+	}
+}
+
+// Summary consolidates balances across banks and accounts.
+type Summary struct {
+	GeneratedAt   time.Time               `json:"generated_at"`
+	Accounts      []AccountSummary        `json:"accounts"`
+	Totals        map[bank.Currency]int64 `json:"totals"` // Available balance per currency, fresh rows only
+	PENEquivalent int64                   `json:"pen_equivalent"`
+	USDPENRate    float64                 `json:"usd_pen_rate,omitempty"`
+	Excluded      []bank.Currency         `json:"excluded,omitempty"` // Currencies left out of PENEquivalent (no rate)
+	Stale         int                     `json:"stale"`              // Rows that could not be refreshed
+}
+
+// Summarize totals the fresh rows per currency and converts them to PEN.
+// usdPENRate is soles per dollar; with a zero rate USD is listed in Excluded
+// instead of being converted. Rows are sorted by bank, then account number.
+func Summarize(accounts []AccountSummary, usdPENRate float64, now time.Time) *Summary {
+	rows := append([]AccountSummary(nil), accounts...)
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].BankCode != rows[j].BankCode {
+			return rows[i].BankCode < rows[j].BankCode
+		}
+		return rows[i].AccountNumber < rows[j].AccountNumber
+	})
+
+	s := &Summary{
+		GeneratedAt: now,
+		Accounts:    rows,
+		Totals:      map[bank.Currency]int64{},
+		USDPENRate:  usdPENRate,
+	}
+	for _, a := range rows {
+		if !a.Fresh() {
+			s.Stale++
+			continue
+		}
+		s.Totals[a.Currency] += a.AvailableBalance
+	}
+
+	currencies := make([]bank.Currency, 0, len(s.Totals))
+	for c := range s.Totals {
+		currencies = append(currencies, c)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	for _, c := range currencies {
+		pen, ok := ToPEN(s.Totals[c], c, usdPENRate)
+		if !ok {
+			s.Excluded = append(s.Excluded, c)
+			continue
+		}
+		s.PENEquivalent += pen
+	}
+	return s
+}
+
+// ToPEN converts cents in currency to PEN cents, rounding half away from zero.
+// ok is false when the currency has no rate.
+func ToPEN(cents int64, currency bank.Currency, usdPENRate float64) (int64, bool) {
+	switch currency {
+	case bank.CurrencyPEN:
+		return cents, true
+	case bank.CurrencyUSD:
+		if usdPENRate <= 0 {
+			return 0, false
+		}
+		return money.Scale(cents, usdPENRate), true
+	default:
+		return 0, false
+	}
+}
+
+// LatestDate returns the most recent operation date in txns, or nil.
+func LatestDate(txns []bank.Transaction) *time.Time {
+	var latest *time.Time
+	for i := range txns {
+		if d := txns[i].Date; !d.IsZero() && (latest == nil || d.After(*latest)) {
+			latest = &d
+		}
+	}
+	return latest
+}
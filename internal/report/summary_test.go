@@ -0,0 +1,93 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fetched := now.Add(-2 * time.Minute)
+	synced := now.Add(-26 * time.Hour)
+
+	rows := []AccountSummary{
+		{BankCode: bank.BankBBVA, AccountNumber: "B", Currency: bank.CurrencyUSD, AvailableBalance: 10_000, FetchedAt: &fetched},
+		{BankCode: bank.BankBBVA, AccountNumber: "A", Currency: bank.CurrencyPEN, AvailableBalance: 50_000, FetchedAt: &fetched},
+		{BankCode: bank.BankBCP, AccountNumber: "C", Currency: bank.CurrencyPEN, LastSyncedAt: &synced, Error: "bank unavailable"},
+	}
+
+	s := Summarize(rows, 3.75, now)
+
+	require.Len(t, s.Accounts, 3)
+	assert.Equal(t, "A", s.Accounts[0].AccountNumber, "sorted by bank then account")
+	assert.Equal(t, bank.BankBCP, s.Accounts[2].BankCode)
+	assert.Equal(t, map[bank.Currency]int64{bank.CurrencyPEN: 50_000, bank.CurrencyUSD: 10_000}, s.Totals)
+	assert.Equal(t, int64(50_000+37_500), s.PENEquivalent)
+	assert.Empty(t, s.Excluded)
+	assert.Equal(t, 1, s.Stale)
+	assert.Equal(t, "B", rows[0].AccountNumber, "input is not reordered")
+}
+
+func TestSummarize_NoRate(t *testing.T) {
+	fetched := time.Now()
+	rows := []AccountSummary{
+		{Currency: bank.CurrencyUSD, AvailableBalance: 10_000, FetchedAt: &fetched},
+		{Currency: bank.CurrencyPEN, AvailableBalance: 1_000, FetchedAt: &fetched},
+	}
+
+	s := Summarize(rows, 0, time.Now())
+
+	assert.Equal(t, int64(1_000), s.PENEquivalent)
+	assert.Equal(t, []bank.Currency{bank.CurrencyUSD}, s.Excluded)
+}
+
+func TestAccountSummary_Age(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fetched := now.Add(-time.Minute)
+	synced := now.Add(-time.Hour)
+
+	age, ok := AccountSummary{FetchedAt: &fetched, LastSyncedAt: &synced}.Age(now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, age)
+
+	age, ok = AccountSummary{FetchedAt: &fetched, LastSyncedAt: &synced, Error: "x"}.Age(now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, age, "failed rows fall back to last sync")
+
+	_, ok = AccountSummary{Error: "x"}.Age(now)
+	assert.False(t, ok)
+}
+
+func TestToPEN(t *testing.T) {
+	got, ok := ToPEN(12_345, bank.CurrencyUSD, 3.7512)
+	assert.True(t, ok)
+	assert.Equal(t, int64(46_309), got) // 46308.564 rounds up
+
+	got, ok = ToPEN(-100, bank.CurrencyPEN, 0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(-100), got)
+
+	_, ok = ToPEN(100, bank.CurrencyUSD, 0)
+	assert.False(t, ok)
+	_, ok = ToPEN(100, bank.Currency("EUR"), 3.75)
+	assert.False(t, ok)
+}
+
+func TestLatestDate(t *testing.T) {
+	assert.Nil(t, LatestDate(nil))
+
+	txns := []bank.Transaction{
+		{Date: date(2026, 2, 1)},
+		{Date: date(2026, 2, 20)},
+		{},
+		{Date: date(2026, 2, 10)},
+	}
+	got := LatestDate(txns)
+	require.NotNil(t, got)
+	assert.Equal(t, date(2026, 2, 20), *got)
+}
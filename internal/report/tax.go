@@ -0,0 +1,48 @@
+package report
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TaxTag classifies a transaction as a Peruvian tax movement, so accountants
+// don't have to pick detractions, IGV and tax payments out of the statement
+// by hand.
+type TaxTag string
+
+// Tax tags. The zero value means no tax movement was recognized.
+const (
+	TaxDetraction TaxTag = "detraccion" // SPOT deposit to/from a Banco de la Nación detraction account
+	TaxIGV        TaxTag = "igv"        // Transfer or payment of IGV (VAT)
+	TaxITF        TaxTag = "itf"        // Financial transactions tax charged by the bank
+	TaxPayment    TaxTag = "tributo"    // Other payment to SUNAT (renta, essalud, fraccionamiento, ...)
+)
+
+// taxRules are tried in order: detraction payments often mention SUNAT and
+// IGV too, so the most specific rules come first.
+var taxRules = []struct {
+	tag TaxTag
+	re  *regexp.Regexp
+}{
+	{TaxDetraction, regexp.MustCompile(`\bDETRAC|\bSPOT\b|\bCTA\.? ?DETR|\bB(?:CO|ANCO)\.? ?(?:DE LA )?NACION\b.*\bDETR`)},
+	{TaxIGV, regexp.MustCompile(`\bI\.?G\.?V\.?\b`)},
+	{TaxITF, regexp.MustCompile(`\bI\.?T\.?F\.?\b|IMP\.? ?TRANS\.? ?FINANC`)},
+	{TaxPayment, regexp.MustCompile(`\bSUNAT\b|\bTRIBUT|\bIMPUESTO|\bIMP\.? ?(?:A LA )?RENTA\b|\bRENTA (?:DE )?` + rentaCategory + `|\bNPS\b|\bPAGO ?FACIL\b|\bESSALUD\b`)},
+}
+
+// rentaCategory is an income tax category (3RA, QUINTA, ...). "RENTA" alone
+// is as often rent ("PAGO RENTA DEPTO"), so it only counts as a tax with
+// SUNAT's wording around it.
+const rentaCategory = `(?:[1-5] ?(?:RA|DA|TA)\b|PRIMERA|SEGUNDA|TERCERA|CUARTA|QUINTA)`
+
+// TaxTagFor returns the tax tag of a transaction description, or "" if it
+// doesn't look like a tax movement.
+func TaxTagFor(description string) TaxTag {
+	s := strings.ToUpper(description)
+	for _, r := range taxRules {
+		if r.re.MatchString(s) {
+			return r.tag
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaxTagFor(t *testing.T) {
+	tests := []struct {
+		description string
+		want        TaxTag
+	}{
+		{"*C/PAGO DETRACCION 00-123-456789 | SUNAT", TaxDetraction},
+		{"DEP. DETRACCIONES BCO NACION", TaxDetraction},
+		{"ABONO SPOT 20601234567", TaxDetraction},
+		{"TRANSF IGV FACTURA F001-123", TaxIGV},
+		{"pago i.g.v. marzo", TaxIGV},
+		{"ITF", TaxITF},
+		{"IMP.TRANS.FINANC.", TaxITF},
+		{"PAGO SUNAT NPS 123456789", TaxPayment},
+		{"PAGO DE TRIBUTOS RENTA 3RA CAT", TaxPayment},
+		{"PAGO A CTA RENTA 3RA CATEGORIA", TaxPayment},
+		{"IMP RENTA QUINTA", TaxPayment},
+		{"IMPUESTO A LA RENTA 2025", TaxPayment},
+		{"PAGO ESSALUD", TaxPayment},
+		{"PAGO FACTURA LUZ DEL SUR", ""},
+		{"RENTAL EQUIPOS SAC", ""},
+		{"PAGO RENTA DEPTO", ""},
+		{"RENTA LOCAL MARZO", ""},
+		{"COMPRA SPOTIFY", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, TaxTagFor(tt.description), tt.description)
+	}
+}
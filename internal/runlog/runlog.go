@@ -0,0 +1,135 @@
+// Package runlog writes a machine-readable summary of each CLI run, run.json,
+// into a timestamped folder of its own, so operators can grep run history
+// without a database and a support request can include one file.
+package runlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/schema"
+)
+
+// FileName is the name of the summary in its run folder.
+const FileName = "run.json"
+
+// Bank outcome statuses.
+const (
+	StatusOK      = "ok"      // Every account was read
+	StatusPartial = "partial" // Some accounts failed
+	StatusFailed  = "failed"  // Every account failed
+)
+
+// Summary is what a run did. Its shape follows schema.Version.
+type Summary struct {
+	SchemaVersion string         `json:"schema_version"`
+	Command       string         `json:"command"`
+	StartedAt     time.Time      `json:"started_at"`
+	FinishedAt    time.Time      `json:"finished_at"`
+	OK            bool           `json:"ok"`
+	ExitCode      int            `json:"exit_code"`
+	Error         string         `json:"error,omitempty"`
+	ConfigHash    string         `json:"config_hash,omitempty"` // See Hash
+	Banks         []BankOutcome  `json:"banks,omitempty"`
+	Counts        map[string]int `json:"counts,omitempty"`
+	Warnings      []string       `json:"warnings,omitempty"`
+	Artifacts     []string       `json:"artifacts,omitempty"` // Files and directories the run wrote
+}
+
+// BankOutcome is how a run went for one bank.
+type BankOutcome struct {
+	Bank     string `json:"bank"`
+	Status   string `json:"status"`
+	Accounts int    `json:"accounts"`
+	Failed   int    `json:"failed,omitempty"`
+}
+
+// AddAccount records one account's outcome under its bank; errMsg is empty
+// when the account was read, and is kept as a warning when it wasn't.
+func (s *Summary) AddAccount(bank, account, errMsg string) {
+	i := 0
+	for i < len(s.Banks) && s.Banks[i].Bank != bank {
+		i++
+	}
+	if i == len(s.Banks) {
+		s.Banks = append(s.Banks, BankOutcome{Bank: bank})
+	}
+	b := &s.Banks[i]
+	b.Accounts++
+	if errMsg != "" {
+		b.Failed++
+		s.Warnings = append(s.Warnings, fmt.Sprintf("%s %s: %s", bank, account, errMsg))
+	}
+	switch b.Failed {
+	case 0:
+		b.Status = StatusOK
+	case b.Accounts:
+		b.Status = StatusFailed
+	default:
+		b.Status = StatusPartial
+	}
+}
+
+// Count adds n to the named count.
+func (s *Summary) Count(name string, n int) {
+	if s.Counts == nil {
+		s.Counts = make(map[string]int)
+	}
+	s.Counts[name] += n
+}
+
+// Write writes s as run.json into a new folder under dir, named after the
+// run's start time and command (e.g. 20260301T090000Z-backfill), and returns
+// the file's path. SchemaVersion is filled in when empty.
+func Write(dir string, s *Summary) (string, error) {
+	if s.SchemaVersion == "" {
+		s.SchemaVersion = schema.Version
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode run summary: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create run directory: %w", err)
+	}
+
+	name := s.StartedAt.UTC().Format("20060102T150405Z") + "-" + strings.Join(strings.Fields(s.Command), "-")
+	runDir := filepath.Join(dir, name)
+	// Runs started in the same second get numbered folders.
+	for n := 2; ; n++ {
+		err := os.Mkdir(runDir, 0o755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("create run directory: %w", err)
+		}
+		runDir = filepath.Join(dir, fmt.Sprintf("%s-%d", name, n))
+	}
+
+	path := filepath.Join(runDir, FileName)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("write run summary: %w", err)
+	}
+	return path, nil
+}
+
+// Hash returns a short fingerprint of v's JSON, for telling apart runs with
+// different configuration without recording the configuration itself.
+// Leave secrets out of v: a fingerprint of a short secret can be guessed.
+func Hash(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
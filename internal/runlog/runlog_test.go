@@ -0,0 +1,59 @@
+package runlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary_AddAccount(t *testing.T) {
+	var s Summary
+	s.AddAccount("BBVA", "0011-0001", "")
+	s.AddAccount("BBVA", "0011-0002", "session expired")
+	s.AddAccount("BCP", "1910001", "")
+	s.AddAccount("IBK", "200300", "timeout")
+
+	assert.Equal(t, []BankOutcome{
+		{Bank: "BBVA", Status: StatusPartial, Accounts: 2, Failed: 1},
+		{Bank: "BCP", Status: StatusOK, Accounts: 1},
+		{Bank: "IBK", Status: StatusFailed, Accounts: 1, Failed: 1},
+	}, s.Banks)
+	assert.Equal(t, []string{"BBVA 0011-0002: session expired", "IBK 200300: timeout"}, s.Warnings)
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := &Summary{Command: "report summary", StartedAt: start, FinishedAt: start.Add(time.Minute), OK: true}
+	s.Count("accounts", 3)
+
+	path, err := Write(dir, s)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20260301T090000Z-report-summary", FileName), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got Summary
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, schema.Version, got.SchemaVersion)
+	assert.Equal(t, map[string]int{"accounts": 3}, got.Counts)
+	assert.True(t, got.StartedAt.Equal(start))
+
+	// A second run in the same second gets a folder of its own.
+	path2, err := Write(dir, s)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20260301T090000Z-report-summary-2", FileName), path2)
+}
+
+func TestHash(t *testing.T) {
+	type cfg struct{ Workers int }
+	assert.Equal(t, Hash(cfg{1}), Hash(cfg{1}))
+	assert.NotEqual(t, Hash(cfg{1}), Hash(cfg{2}))
+	assert.Len(t, Hash(cfg{1}), 16)
+}
@@ -0,0 +1,158 @@
+// Package schema versions the serialized shape of scraped data (balances,
+// transactions, account records) and migrates older documents forward.
+//
+// The version follows semantic versioning: a MAJOR bump breaks readers, a
+// MINOR bump adds fields, a PATCH bump changes nothing structural. Every
+// serialized document carries its version under the "schema_version" key.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the current data schema version written by this build.
+const Version = "1.0.0"
+
+// VersionKey is the JSON key holding the schema version in serialized documents.
+const VersionKey = "schema_version"
+
+// Sentinel errors for version handling.
+var (
+	ErrInvalidVersion = errors.New("invalid schema version")
+	ErrMissingVersion = errors.New("missing schema version")
+	ErrNoMigration    = errors.New("no migration path")
+)
+
+// Semver is a parsed MAJOR.MINOR.PATCH version.
+type Semver struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// Parse parses a "MAJOR.MINOR.PATCH" string. A leading "v" is accepted.
+func Parse(v string) (Semver, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	if len(parts) != 3 {
+		return Semver{}, fmt.Errorf("%w: %q", ErrInvalidVersion, v)
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Semver{}, fmt.Errorf("%w: %q", ErrInvalidVersion, v)
+		}
+		nums[i] = n
+	}
+	return Semver{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+// String formats the version as "MAJOR.MINOR.PATCH".
+func (s Semver) String() string {
+	return fmt.Sprintf("%d.%d.%d", s.Major, s.Minor, s.Patch)
+}
+
+// Compare returns -1, 0 or 1 when s is lower than, equal to, or greater than o.
+func (s Semver) Compare(o Semver) int {
+	for _, d := range []int{s.Major - o.Major, s.Minor - o.Minor, s.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Compatible reports whether a document at version v can be read by this
+// build without migration: same major version, not newer than Version.
+func Compatible(v string) bool {
+	got, err := Parse(v)
+	if err != nil {
+		return false
+	}
+	cur, _ := Parse(Version)
+	return got.Major == cur.Major && got.Compare(cur) <= 0
+}
+
+// Migration upgrades a decoded JSON document from one version to the next.
+// Apply mutates doc in place; the Migrator updates the version key.
+type Migration struct {
+	From  string
+	To    string
+	Apply func(doc map[string]any) error
+}
+
+// Migrator walks a document through registered migrations until it reaches
+// the target version.
+type Migrator struct {
+	byFrom map[string]Migration
+}
+
+// NewMigrator creates a Migrator from a set of single-step migrations.
+func NewMigrator(migrations ...Migration) *Migrator {
+	m := &Migrator{byFrom: make(map[string]Migration, len(migrations))}
+	for _, mig := range migrations {
+		m.byFrom[mig.From] = mig
+	}
+	return m
+}
+
+// Migrations lists the built-in upgrade steps, oldest first. Append a step
+// here whenever Version is bumped in a way that changes the serialized shape.
+var Migrations = []Migration{}
+
+// Default is the Migrator built from Migrations.
+var Default = NewMigrator(Migrations...)
+
+// Migrate upgrades doc in place to the target version. Documents already at
+// a compatible version (same major, not newer) are left untouched apart from
+// the version key.
+func (m *Migrator) Migrate(doc map[string]any, target string) error {
+	raw, ok := doc[VersionKey].(string)
+	if !ok || raw == "" {
+		return ErrMissingVersion
+	}
+	cur, err := Parse(raw)
+	if err != nil {
+		return err
+	}
+	want, err := Parse(target)
+	if err != nil {
+		return err
+	}
+
+	// Each step must move forward, so the loop is bounded by the number of
+	// registered migrations.
+	for steps := 0; cur.Compare(want) < 0; steps++ {
+		mig, ok := m.byFrom[cur.String()]
+		if !ok {
+			if cur.Major == want.Major {
+				// Minor/patch bumps without a registered step are additive.
+				break
+			}
+			return fmt.Errorf("%w: %s -> %s", ErrNoMigration, cur, want)
+		}
+		next, err := Parse(mig.To)
+		if err != nil {
+			return err
+		}
+		if next.Compare(cur) <= 0 || steps > len(m.byFrom) {
+			return fmt.Errorf("%w: migration %s -> %s does not move forward", ErrNoMigration, mig.From, mig.To)
+		}
+		if err := mig.Apply(doc); err != nil {
+			return fmt.Errorf("migrate %s -> %s: %w", mig.From, mig.To, err)
+		}
+		cur = next
+	}
+
+	if cur.Compare(want) > 0 {
+		return fmt.Errorf("%w: document version %s is newer than %s", ErrNoMigration, cur, want)
+	}
+	doc[VersionKey] = want.String()
+	return nil
+}
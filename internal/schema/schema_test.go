@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Semver
+		wantErr bool
+	}{
+		{"plain", "1.2.3", Semver{1, 2, 3}, false},
+		{"v prefix", "v2.0.0", Semver{2, 0, 0}, false},
+		{"two parts", "1.2", Semver{}, true},
+		{"non-numeric", "1.x.0", Semver{}, true},
+		{"negative", "1.-1.0", Semver{}, true},
+		{"empty", "", Semver{}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.input)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSemver_Compare(t *testing.T) {
+	assert.Equal(t, 0, Semver{1, 2, 3}.Compare(Semver{1, 2, 3}))
+	assert.Equal(t, -1, Semver{1, 2, 3}.Compare(Semver{1, 3, 0}))
+	assert.Equal(t, 1, Semver{2, 0, 0}.Compare(Semver{1, 9, 9}))
+}
+
+func TestCompatible(t *testing.T) {
+	assert.True(t, Compatible(Version))
+	assert.False(t, Compatible("99.0.0"))
+	assert.False(t, Compatible("garbage"))
+}
+
+func TestMigrator_WalksChain(t *testing.T) {
+	m := NewMigrator(
+		Migration{From: "1.0.0", To: "2.0.0", Apply: func(doc map[string]any) error {
+			doc["amount_cents"] = doc["amount"]
+			delete(doc, "amount")
+			return nil
+		}},
+		Migration{From: "2.0.0", To: "3.0.0", Apply: func(doc map[string]any) error {
+			doc["currency"] = "PEN"
+			return nil
+		}},
+	)
+
+	doc := map[string]any{VersionKey: "1.0.0", "amount": 100}
+	require.NoError(t, m.Migrate(doc, "3.0.0"))
+
+	assert.Equal(t, "3.0.0", doc[VersionKey])
+	assert.Equal(t, 100, doc["amount_cents"])
+	assert.NotContains(t, doc, "amount")
+	assert.Equal(t, "PEN", doc["currency"])
+}
+
+func TestMigrator_AdditiveMinorBumpNeedsNoStep(t *testing.T) {
+	doc := map[string]any{VersionKey: "1.0.0"}
+	require.NoError(t, NewMigrator().Migrate(doc, "1.2.0"))
+	assert.Equal(t, "1.2.0", doc[VersionKey])
+}
+
+func TestMigrator_Errors(t *testing.T) {
+	m := NewMigrator()
+
+	err := m.Migrate(map[string]any{}, Version)
+	assert.ErrorIs(t, err, ErrMissingVersion)
+
+	err = m.Migrate(map[string]any{VersionKey: "1.0.0"}, "2.0.0")
+	assert.ErrorIs(t, err, ErrNoMigration, "major bump without a registered step")
+
+	err = m.Migrate(map[string]any{VersionKey: "3.0.0"}, "2.0.0")
+	assert.ErrorIs(t, err, ErrNoMigration, "downgrades are not supported")
+
+	failing := NewMigrator(Migration{From: "1.0.0", To: "2.0.0", Apply: func(map[string]any) error {
+		return errors.New("boom")
+	}})
+	err = failing.Migrate(map[string]any{VersionKey: "1.0.0"}, "2.0.0")
+	assert.ErrorContains(t, err, "boom")
+}
@@ -0,0 +1,84 @@
+package banktest
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Live runs log in to real banks with real credentials. Besides
+// SCRAPER_TEST_MODE=live they need the operator's explicit acknowledgement,
+// so a CI job or a stray environment variable can't start one.
+const (
+	LiveAckEnv = "SCRAPER_LIVE_ACK"
+	LiveAck    = "I_UNDERSTAND"
+
+	// LiveIntervalEnv overrides DefaultLiveInterval, e.g. "1m".
+	LiveIntervalEnv = "SCRAPER_LIVE_INTERVAL"
+)
+
+// DefaultLiveInterval is the least time between two live logins in one test
+// run. Back-to-back logins from one IP are what bank bot detection flags.
+const DefaultLiveInterval = 30 * time.Second
+
+var live struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// RequireLive gates a test that hits a real bank. It skips t unless
+// SCRAPER_LIVE_ACK=I_UNDERSTAND and every credential environment variable in
+// fields (credential field → variable name) is set, then waits out the
+// interval since the previous live test so the run doesn't hammer the bank.
+// It returns the credentials keyed by field, ready for Scraper.Login.
+//
+// Callers must also construct their scraper in read-only mode (e.g.
+// bbva.WithReadOnly(true)).
+func RequireLive(t testing.TB, fields map[string]string) map[string]string {
+	t.Helper()
+	if os.Getenv(LiveAckEnv) != LiveAck {
+		t.Skipf("Skipping: live tests hit real banks; set %s=%s to run them", LiveAckEnv, LiveAck)
+	}
+
+	creds := make(map[string]string, len(fields))
+	var missing []string
+	for field, env := range fields {
+		v := os.Getenv(env)
+		if v == "" {
+			missing = append(missing, env)
+		}
+		creds[field] = v
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		t.Skipf("Skipping: requires %s env vars", strings.Join(missing, ", "))
+	}
+
+	waitLiveSlot(t)
+	return creds
+}
+
+// waitLiveSlot blocks until the live interval has passed since the previous
+// call.
+func waitLiveSlot(t testing.TB) {
+	t.Helper()
+	interval := DefaultLiveInterval
+	if v := os.Getenv(LiveIntervalEnv); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			t.Fatalf("%s: %v", LiveIntervalEnv, err)
+		}
+		interval = d
+	}
+
+	live.mu.Lock()
+	defer live.mu.Unlock()
+	if wait := time.Until(live.last.Add(interval)); wait > 0 {
+		t.Logf("Rate limit: waiting %s before the next live login", wait.Round(time.Second))
+		time.Sleep(wait)
+	}
+	live.last = time.Now()
+}
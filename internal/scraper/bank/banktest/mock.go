@@ -17,6 +17,8 @@ type MockScraper struct {
 	BalanceErr      error
 	Transactions    []bank.Transaction
 	TransactionsErr error
+	Scheduled       []bank.ScheduledOperation
+	ScheduledErr    error
 
 	// Call counters
 	LoginCalled  int
@@ -49,6 +51,14 @@ func (m *MockScraper) GetTransactions(_ context.Context, _ string, _ int) ([]ban
 	return m.Transactions, nil
 }
 
+// GetScheduledOperations implements bank.ScheduledOperationsScraper.
+func (m *MockScraper) GetScheduledOperations(_ context.Context) ([]bank.ScheduledOperation, error) {
+	if m.ScheduledErr != nil {
+		return nil, m.ScheduledErr
+	}
+	return m.Scheduled, nil
+}
+
 // Logout implements bank.Scraper.
 func (m *MockScraper) Logout(_ context.Context) error {
 	m.LogoutCalled++
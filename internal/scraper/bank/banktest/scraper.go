@@ -0,0 +1,120 @@
+package banktest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/testutil"
+)
+
+// TestModeEnv selects what scraper tests run against.
+const TestModeEnv = "SCRAPER_TEST_MODE"
+
+// TestMode is a value of SCRAPER_TEST_MODE.
+type TestMode string
+
+const (
+	TestModeMock   TestMode = "mock"   // Use static fixtures (default)
+	TestModeReplay TestMode = "replay" // Replay recorded sessions
+	TestModeLive   TestMode = "live"   // Hit real bank (dangerous!)
+)
+
+// Mode returns the current test mode.
+func Mode() TestMode {
+	mode := os.Getenv(TestModeEnv)
+	if mode == "" {
+		return TestModeMock
+	}
+	return TestMode(mode)
+}
+
+// ScraperOptions is what NewScraper wires for the current mode. A bank's
+// Build func maps it onto the bank's own options.
+type ScraperOptions struct {
+	Hijacker func(*rod.Hijack) // Serves the recording in replay mode; nil (real network) in live mode
+	Scripts  []browser.Script  // JS shims to inject; set in replay mode only
+	ReadOnly bool              // Always true in live mode
+}
+
+// ScraperSetup tells NewScraper how to build one bank's scraper for a test.
+// Leaving Scenario (or Recording) or LiveCredentials empty means the test
+// doesn't run in that mode.
+type ScraperSetup[S bank.Scraper] struct {
+	Build func(ScraperOptions) (S, error)
+
+	Recordings        string            // Directory of the bank's recordings and their testutil.Manifest
+	Scenario          string            // Recording served in replay mode, by its name in the manifest
+	Recording         string            // HAR file served in replay mode, by path, for recordings outside the manifest
+	StaticDir         string            // Assets the recording lacks, by URL path (see testutil.WithStaticDir)
+	ReplayScripts     []browser.Script  // JS shims replay needs (see browser.InjectScripts)
+	ReplayCredentials map[string]string // Placeholder Login credentials for replay mode
+	Verbose           bool              // Log each replayed request
+
+	LiveCredentials map[string]string // Credential field → env var, for live mode
+}
+
+// loadRecording loads the replay recording of scenario from dir, or the one
+// at path, skipping t when there is none and failing it when the recording
+// isn't sanitized.
+func loadRecording(t testing.TB, dir, scenario, path string) *testutil.HARLog {
+	t.Helper()
+	if scenario != "" {
+		path = testutil.ScenarioPath(dir, scenario)
+	}
+	if path == "" {
+		t.Skipf("Skipping: no recording for this test; requires %s=%s", TestModeEnv, TestModeLive)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		t.Skipf("Skipping: recording not found: %s", path)
+	}
+	if scenario != "" {
+		har, sc := testutil.MustLoadScenario(t, dir, scenario, testutil.WithSanitizeCheck(true))
+		t.Logf("Replaying scenario %s: %s (recorded %s, %d entries)", sc.Name, sc.Description, sc.Created.Format(time.DateOnly), sc.Entries)
+		return har
+	}
+	har := testutil.MustLoadHAR(t, path, testutil.WithSanitizeCheck(true))
+	t.Logf("Replaying %s (%d entries)", path, len(har.Entries))
+	return har
+}
+
+// NewScraper builds a scraper for the current SCRAPER_TEST_MODE, closes it
+// when t ends, and returns it with the credentials to log in with. It skips
+// t with the reason when the mode needs no browser (mock), when setup doesn't
+// cover the mode, when the recording is missing, or when live prerequisites
+// aren't met (see RequireLive).
+func NewScraper[S bank.Scraper](t testing.TB, setup ScraperSetup[S]) (S, map[string]string) {
+	t.Helper()
+
+	var opts ScraperOptions
+	var creds map[string]string
+	switch mode := Mode(); mode {
+	case TestModeReplay:
+		har := loadRecording(t, setup.Recordings, setup.Scenario, setup.Recording)
+		replayOpts := []testutil.ReplayerOption{testutil.WithVerbose(setup.Verbose)}
+		if setup.StaticDir != "" {
+			replayOpts = append(replayOpts, testutil.WithStaticDir(setup.StaticDir))
+		}
+		opts.Hijacker = testutil.NewReplayer(har, replayOpts...).Middleware()
+		opts.Scripts = setup.ReplayScripts
+		creds = setup.ReplayCredentials
+	case TestModeLive:
+		if len(setup.LiveCredentials) == 0 {
+			t.Skipf("Skipping: replay-only test; requires %s=%s", TestModeEnv, TestModeReplay)
+		}
+		creds = RequireLive(t, setup.LiveCredentials)
+		opts.ReadOnly = true
+	default:
+		t.Skipf("Skipping: needs a browser; requires %s=%s or %s (current: %s)", TestModeEnv, TestModeReplay, TestModeLive, mode)
+	}
+
+	s, err := setup.Build(opts)
+	if err != nil {
+		t.Fatalf("build scraper: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s, creds
+}
@@ -0,0 +1,102 @@
+package bbva
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/debug"
+)
+
+// Ensure Scraper supports page capture.
+var _ bank.PageCapturer = (*Scraper)(nil)
+
+// capturePageURLs are the pages CapturePage navigates to. bank.PageAccounts
+// goes through navigateToAccountsPage for its retries instead.
+var capturePageURLs = map[string]string{
+	bank.PageScheduled: scheduledURL,
+}
+
+// CapturePage navigates to pageName and returns its flattened HTML and a
+// full-page screenshot. The flattening is read-only, so the session stays
+// usable for GetBalance and GetTransactions afterwards.
+func (s *Scraper) CapturePage(ctx context.Context, session *bank.Session, pageName string) (html string, screenshot []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := debug.StartOp(s.logger, "CapturePage", slog.String("page", pageName))
+
+	page, err := s.activePage("CapturePage")
+	if err != nil {
+		return "", nil, err
+	}
+	if session == nil || session.ID != s.session.ID {
+		return "", nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "CapturePage",
+			Cause:     bank.ErrSessionExpired,
+			Details:   "no active session matching the given one — call Login first",
+		}
+	}
+
+	switch url, ok := capturePageURLs[pageName]; {
+	case pageName == bank.PageCurrent:
+	case pageName == bank.PageAccounts:
+		err = navigateToAccountsPage(ctx, page, accountsNavStepTimeout, s.timings, s.logger)
+	case ok:
+		navCtx, navCancel := context.WithTimeout(ctx, s.timeout)
+		err = navigateTo(navCtx, page, url)
+		navCancel()
+	default:
+		return "", nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "CapturePage",
+			Cause:     bank.ErrUnsupportedPage,
+			Details:   fmt.Sprintf("no %q page on BBVA", pageName),
+		}
+	}
+	if err != nil {
+		op.Error("page not reachable", err)
+		return "", nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "CapturePage",
+			Cause:     bank.ErrUnknown,
+			Details:   fmt.Sprintf("navigate to %s page: %v", pageName, err),
+		}
+	}
+
+	captureCtx, captureCancel := context.WithTimeout(ctx, s.timeout)
+	defer captureCancel()
+	if summary, err := browser.WaitForIFrames(captureCtx, page); err != nil {
+		// Capture what is there: a page that never settles is itself worth
+		// archiving.
+		op.Warn("frames did not settle", slog.String("frames", summary.String()), slog.Any("error", err))
+		captureCtx, captureCancel = context.WithTimeout(ctx, s.timeout)
+		defer captureCancel()
+	}
+	p := page.Context(captureCtx)
+
+	screenshot, err = p.Screenshot(true, nil)
+	if err != nil {
+		return "", nil, captureError(op, "screenshot", err)
+	}
+	html, shadowCount, iframeCount, err := browser.FlattenShadowDOMReadOnly(p)
+	if err != nil {
+		return "", nil, captureError(op, "flatten", err)
+	}
+
+	op.Success(slog.Int("shadow_roots", shadowCount), slog.Int("iframes", iframeCount), slog.Int("html_bytes", len(html)))
+	return html, screenshot, nil
+}
+
+func captureError(op *debug.OpLogger, step string, err error) error {
+	op.Error(step+" failed", err)
+	return &bank.ScraperError{
+		Code:      bank.BankBBVA,
+		Operation: "CapturePage",
+		Cause:     bank.ErrUnknown,
+		Details:   fmt.Sprintf("%s: %v", step, err),
+	}
+}
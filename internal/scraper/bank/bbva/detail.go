@@ -0,0 +1,173 @@
+package bbva
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/debug"
+)
+
+// maxDetailWorkers caps WithTransactionDetails: each worker is a browser tab
+// replaying the full accounts → account detail → pagination flow.
+const maxDetailWorkers = 4
+
+// WithTransactionDetails enables per-transaction detail fetching in
+// GetTransactions, populating Reference and Extra from each row's detail
+// drawer. workers bounds how many tabs fetch details concurrently (clamped to
+// 1..4). Off by default: every row costs a click and a drawer render, so
+// expect GetTransactions to take considerably longer.
+func WithTransactionDetails(workers int) Option {
+	return func(s *Scraper) {
+		s.detailWorkers = min(max(workers, 1), maxDetailWorkers)
+	}
+}
+
+// fetchTransactionDetails opens up to s.detailWorkers extra tabs on the same
+// account and splits the rows between them round-robin. It is best-effort:
+// rows whose detail cannot be fetched or parsed keep their table data, and
+// failures are logged rather than returned.
+func (s *Scraper) fetchTransactionDetails(ctx context.Context, accountID string, txns []bank.Transaction, op *debug.OpLogger) {
+	if len(txns) == 0 {
+		return
+	}
+	workers := min(s.detailWorkers, len(txns))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		fetched int
+	)
+	for w := 0; w < workers; w++ {
+		var indices []int
+		for i := w; i < len(txns); i += workers {
+			indices = append(indices, i)
+		}
+
+		wg.Add(1)
+		go func(worker int, indices []int) {
+			defer wg.Done()
+			n, err := s.detailWorker(ctx, accountID, indices, len(txns), txns, op)
+			if err != nil {
+				op.Warn("detail worker failed",
+					slog.Int("worker", worker), slog.String("error", err.Error()))
+			}
+			mu.Lock()
+			fetched += n
+			mu.Unlock()
+		}(w, indices)
+	}
+	wg.Wait()
+
+	op.Info("transaction details fetched",
+		slog.Int("fetched", fetched), slog.Int("total", len(txns)), slog.Int("workers", workers))
+}
+
+// detailWorker navigates its own tab to the account's movements, loads rowCount
+// rows and applies the detail of each row in indices to txns. Workers own
+// disjoint indices, so writes to txns do not race. Returns how many rows were
+// enriched.
+func (s *Scraper) detailWorker(ctx context.Context, accountID string, indices []int, rowCount int, txns []bank.Transaction, op *debug.OpLogger) (int, error) {
+	page, err := s.newPage("")
+	if err != nil {
+		return 0, fmt.Errorf("open tab: %w", err)
+	}
+	defer func() { _ = page.Close() }()
+
+	router := page.HijackRequests()
+	router.MustAdd("*", s.requestHandler(nil))
+	go router.Run()
+	defer func() { _ = router.Stop() }()
+
+	if err := navigateToAccountsPage(ctx, page, accountsNavStepTimeout, s.timings, s.logger); err != nil {
+		return 0, fmt.Errorf("accounts page: %w", err)
+	}
+	if !waitAndClickAccountDetail(ctx, page, accountID, s.timeout) {
+		return 0, fmt.Errorf("%w: account card %s", bank.ErrAccountNotFound, accountID)
+	}
+	if !waitForTransactionsReady(ctx, page, s.timeout) {
+		return 0, fmt.Errorf("timed out waiting for transactions table")
+	}
+
+	loadCtx, loadCancel := context.WithTimeout(ctx, s.timeout)
+	err = loadTransactionRows(loadCtx, page, rowCount, s.timings, op)
+	loadCancel()
+	if err != nil {
+		return 0, err
+	}
+
+	enriched := 0
+	for _, idx := range indices {
+		if ctx.Err() != nil {
+			return enriched, ctx.Err()
+		}
+		html, err := openTransactionDetail(ctx, page, idx, s.timeout)
+		if err != nil {
+			s.warn(ctx, op, "GetTransactions", accountID, fmt.Sprintf("row %d: transaction detail not available: %v", idx, err))
+			continue
+		}
+		detail, err := ParseTransactionDetail(html)
+		if err != nil {
+			s.debug.HTMLString(html, "GetTransactions", fmt.Sprintf("detail-parse-error-%d", idx))
+			s.warn(ctx, op, "GetTransactions", accountID, fmt.Sprintf("row %d: transaction detail parse failed: %v", idx, err))
+			continue
+		}
+		for _, w := range detail.Warnings {
+			s.warn(ctx, op, "GetTransactions", accountID, fmt.Sprintf("row %d: %s", idx, w))
+		}
+		detail.Apply(&txns[idx])
+		enriched++
+	}
+	return enriched, nil
+}
+
+// openTransactionDetail clicks the row at index idx, waits for the detail
+// drawer, captures its flattened HTML and closes it again.
+func openTransactionDetail(ctx context.Context, page *rod.Page, idx int, timeout time.Duration) (string, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	p := page.Context(waitCtx)
+
+	clicked, err := p.Eval(fmt.Sprintf(`() => {
+		%s
+		const rows = deepQueryAll(document, '%s');
+		if (%d >= rows.length) return false;
+		rows[%d].click();
+		return true;
+	}`, browser.DeepQueryAllJS, SelectorTransactionRow, idx, idx))
+	if err != nil {
+		return "", fmt.Errorf("click row: %w", err)
+	}
+	if !clicked.Value.Bool() {
+		return "", fmt.Errorf("row %d not rendered", idx)
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for !browser.DeepQueryExists(p, SelectorTxDetailField) {
+		select {
+		case <-waitCtx.Done():
+			return "", fmt.Errorf("detail drawer did not open: %w", waitCtx.Err())
+		case <-ticker.C:
+		}
+	}
+
+	html, err := browser.DeepQueryOuterHTML(p, SelectorTxDetailPanel)
+	if err != nil {
+		return "", err
+	}
+
+	// Close the drawer so the next row click lands on the table.
+	if !browser.DeepQueryClick(p, SelectorTxDetailClose) {
+		_ = p.Keyboard.Type(input.Escape)
+	}
+	_ = p.WaitDOMStable(300*time.Millisecond, 0)
+
+	return html, nil
+}
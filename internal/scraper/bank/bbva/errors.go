@@ -0,0 +1,55 @@
+package bbva
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// ErrorTable classifies what the BBVA portal shows when an operation fails.
+var ErrorTable = bank.ErrorTable{
+	// Senda API (grantingTicket) error codes.
+	{ErrorCode: "160", Err: bank.ErrInvalidCredentials},
+	{ErrorCode: "162", Err: bank.ErrInvalidCredentials},
+	// Senda API gateway statuses.
+	{Status: 502, Err: bank.ErrBankUnavailable},
+	{Status: 503, Err: bank.ErrBankUnavailable},
+	{Status: 504, Err: bank.ErrTimeout},
+
+	// Senda UI messages in span#error-message.
+	{Text: "corrijas los datos", Err: bank.ErrInvalidCredentials},
+	{Text: "bloqueado", Err: bank.ErrInvalidCredentials},
+	{Text: "límite de intentos", Err: bank.ErrInvalidCredentials},
+	{Text: "no disponible", Err: bank.ErrBankUnavailable},
+
+	// The login form not rendering means the portal is down or mid-deploy,
+	// whichever form the legacy-login flag expects.
+	{Selector: SelectorLoginButton, Err: bank.ErrBankUnavailable},
+	{Selector: SelectorLegacyLoginButton, Err: bank.ErrBankUnavailable},
+}
+
+// classifySendaError maps Senda error message text to typed errors.
+// Handles both UI text (from span#error-message) and API probe format
+// ("senda API error-code NNN" or "senda API STATUS: ...").
+func classifySendaError(errorText string) error {
+	// Probe format: "senda API error-code 160"
+	if code, ok := strings.CutPrefix(errorText, "senda API error-code "); ok {
+		return classifySendaErrorCode(code)
+	}
+	// Probe format: "senda API 403: ..." or "senda API probe eval: ..."
+	if rest, ok := strings.CutPrefix(errorText, "senda API "); ok {
+		status, _, _ := strings.Cut(rest, ":")
+		n, err := strconv.Atoi(status)
+		if err != nil {
+			return bank.ErrUnknown
+		}
+		return ErrorTable.Match(bank.ErrorSignature{Status: n})
+	}
+	return ErrorTable.Match(bank.ErrorSignature{Text: errorText})
+}
+
+// classifySendaErrorCode maps Senda API error codes to typed errors.
+func classifySendaErrorCode(code string) error {
+	return ErrorTable.Match(bank.ErrorSignature{ErrorCode: code})
+}
@@ -2,14 +2,13 @@ package bbva
 
 import (
 	"fmt"
-	"math"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
 )
 
 // Currency symbols and labels for BBVA account parsing.
@@ -32,20 +31,13 @@ const (
 	bbvaDateLayout2026 = "02 Jan 2006"
 )
 
-var spanishMonths = map[string]string{
-	"Ene": "Jan",
-	"Feb": "Feb",
-	"Mar": "Mar",
-	"Abr": "Apr",
-	"May": "May",
-	"Jun": "Jun",
-	"Jul": "Jul",
-	"Ago": "Aug",
-	"Sep": "Sep",
-	"Oct": "Oct",
-	"Nov": "Nov",
-	"Dic": "Dec",
-}
+// layout is BBVA's amount/date format, registered so bank-agnostic code can
+// look it up with parseutil.LayoutFor(bank.BankBBVA).
+var layout = parseutil.Register(bank.BankBBVA, parseutil.Layout{
+	DecimalSep:   '.',
+	ThousandsSep: ',',
+	DateLayouts:  []string{bbvaDateLayout, bbvaDateLayout2026},
+})
 
 // LoginErrorInfo holds error details from a failed BBVA login.
 type LoginErrorInfo struct {
@@ -96,15 +88,64 @@ func (r *Row) ToTransaction() *bank.Transaction {
 	}
 
 	return &bank.Transaction{
-		ID:           r.NumeroMovimiento,
-		Reference:    "",
-		Date:         r.FOperacion,
-		ValueDate:    r.FValor,
-		Description:  r.Concepto,
-		Amount:       absAmount,
-		Type:         txnType,
-		BalanceAfter: &r.BalanceAfter,
-		Extra:        map[string]string{"Beneficiary": r.Beneficiary, "Codigo": r.Codigo},
+		ID:               r.NumeroMovimiento,
+		Reference:        "",
+		Date:             r.FOperacion,
+		ValueDate:        r.FValor,
+		Description:      r.Concepto,
+		CleanDescription: parseutil.CleanDescription(r.Concepto),
+		Amount:           absAmount,
+		Type:             txnType,
+		BalanceAfter:     &r.BalanceAfter,
+		Extra:            map[string]string{bank.BBVAExtraBeneficiary: r.Beneficiary, bank.BBVAExtraCodigo: r.Codigo},
+	}
+}
+
+// TransactionDetail holds the fields shown in a transaction's detail drawer
+// that the movements table omits.
+type TransactionDetail struct {
+	OperationNumber string
+	Channel         string
+	Timestamp       time.Time         // Zero if the drawer shows no parseable date/time
+	Fields          map[string]string // Every label/value pair, as shown
+	Warnings        []string          // Fields that were shown but couldn't be used
+}
+
+// Detail drawer labels (lowercased, accents removed by normalizeLabel).
+const (
+	detailLabelOperation = "numero de operacion"
+	detailLabelChannel   = "canal"
+	detailLabelDateTime  = "fecha y hora"
+)
+
+// detailTimestampLayouts are the date-time formats seen in the detail drawer.
+var detailTimestampLayouts = []string{
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02-01-2006 15:04:05",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+}
+
+// Apply copies the detail into tx: the operation number becomes Reference,
+// and channel, timestamp and the remaining fields are added to Extra.
+func (d *TransactionDetail) Apply(tx *bank.Transaction) {
+	if tx.Extra == nil {
+		tx.Extra = map[string]string{}
+	}
+	if d.OperationNumber != "" {
+		tx.Reference = d.OperationNumber
+	}
+	if d.Channel != "" {
+		tx.Extra[bank.BBVAExtraChannel] = d.Channel
+	}
+	if !d.Timestamp.IsZero() {
+		tx.Extra[bank.BBVAExtraTimestamp] = d.Timestamp.Format(time.RFC3339)
+	}
+	for label, value := range d.Fields {
+		if _, exists := tx.Extra[label]; !exists {
+			tx.Extra[label] = value
+		}
 	}
 }
 
@@ -113,14 +154,22 @@ func (r *Row) ToTransaction() *bank.Transaction {
 // ParseAccountBalances parses the 2026 redesigned accounts page.
 // Auto-detects view mode: list view (both balances) or tile view (available only).
 func ParseAccountBalances(html string) ([]bank.Balance, error) {
+	balances, _, err := ParseAccountBalancesWithWarnings(html)
+	return balances, err
+}
+
+// ParseAccountBalancesWithWarnings is ParseAccountBalances, also returning
+// a message for each account element it skipped.
+func ParseAccountBalancesWithWarnings(html string) ([]bank.Balance, []string, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", bank.ErrParsingFailed, err)
+		return nil, nil, fmt.Errorf("%w: %v", bank.ErrParsingFailed, err)
 	}
 
 	// List view has more data (both available and accounted balance).
 	if tables := doc.Find(SelectorAccountTable); tables.Length() > 0 {
-		return parseAccountsListView(doc)
+		balances, err := parseAccountsListView(doc)
+		return balances, nil, err
 	}
 
 	// Tile view only has available balance.
@@ -128,7 +177,7 @@ func ParseAccountBalances(html string) ([]bank.Balance, error) {
 		return parseAccountsTileView(doc)
 	}
 
-	return nil, fmt.Errorf("%w: no account elements found", bank.ErrParsingFailed)
+	return nil, nil, fmt.Errorf("%w: no account elements found", bank.ErrParsingFailed)
 }
 
 // ParseTransactions parses transaction rows from flattened BBVA HTML.
@@ -205,6 +254,30 @@ func DetectAnnouncementModal(html string) bool {
 	return doc.Find(SelectorAnnouncementModal).Length() > 0
 }
 
+// Login page variants, as reported in bank.Session.LoginVariant.
+const (
+	LoginVariantSenda   = "senda"   // #enviarSenda and the Senda micro-frontend; what Login drives
+	LoginVariantLegacy  = "legacy"  // Only the DFServlet form's #aceptar
+	LoginVariantUnknown = "unknown" // Neither login button
+)
+
+// DetectLoginVariant tells which login page BBVA served. The Senda page
+// keeps the legacy button hidden as a fallback, so it counts as Senda.
+func DetectLoginVariant(html string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return LoginVariantUnknown
+	}
+	switch {
+	case doc.Find(SelectorLoginButton).Length() > 0:
+		return LoginVariantSenda
+	case doc.Find(SelectorLegacyLoginButton).Length() > 0:
+		return LoginVariantLegacy
+	default:
+		return LoginVariantUnknown
+	}
+}
+
 // DetectLoginError checks login response HTML for error indicators.
 func DetectLoginError(html string, statusCode int) error {
 	// Handle HTTP errors first
@@ -318,6 +391,160 @@ func parseTransactionRow(s *goquery.Selection) (*Row, error) {
 	}, nil
 }
 
+// ParseTransactionDetail parses the flattened HTML of a transaction detail drawer.
+func ParseTransactionDetail(html string) (*TransactionDetail, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", bank.ErrParsingFailed, err)
+	}
+
+	fields := doc.Find(SelectorTxDetailField)
+	if fields.Length() == 0 {
+		return nil, fmt.Errorf("%w: no detail fields found with selector: %s", bank.ErrParsingFailed, SelectorTxDetailField)
+	}
+
+	detail := &TransactionDetail{Fields: make(map[string]string, fields.Length())}
+	fields.Each(func(_ int, f *goquery.Selection) {
+		label := strings.TrimSpace(f.AttrOr("label", ""))
+		value := strings.TrimSpace(f.AttrOr("description", f.AttrOr("text", "")))
+		if value == "" {
+			return
+		}
+		if label == "" {
+			detail.Warnings = append(detail.Warnings, fmt.Sprintf("unlabeled detail field %q skipped", value))
+			return
+		}
+		switch normalizeLabel(label) {
+		case detailLabelOperation:
+			detail.OperationNumber = value
+		case detailLabelChannel:
+			detail.Channel = value
+		case detailLabelDateTime:
+			if t, err := parseutil.ParseDate(value, detailTimestampLayouts...); err == nil {
+				detail.Timestamp = t
+			} else {
+				detail.Warnings = append(detail.Warnings, fmt.Sprintf("unrecognized detail timestamp %q", value))
+			}
+		}
+		detail.Fields[label] = value
+	})
+
+	return detail, nil
+}
+
+// normalizeLabel lowercases a detail label and strips accents, "N°"/"Nº"
+// abbreviations and a trailing colon so label variants compare equal.
+func normalizeLabel(label string) string {
+	l := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(label), ":"))
+	l = strings.NewReplacer(
+		"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u",
+		"n°", "numero", "nº", "numero", "nro.", "numero",
+	).Replace(l)
+	return strings.Join(strings.Fields(l), " ")
+}
+
+// ParseScheduledOperations parses the scheduled operations table. An empty
+// or "noresults" table yields an empty slice.
+func ParseScheduledOperations(html string) ([]bank.ScheduledOperation, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", bank.ErrParsingFailed, err)
+	}
+
+	table := doc.Find(SelectorScheduledTable)
+	if table.Length() == 0 {
+		return nil, fmt.Errorf("%w: table not found with selector: %s", bank.ErrParsingFailed, SelectorScheduledTable)
+	}
+	switch table.AttrOr("state", "") {
+	case "noresults":
+		return []bank.ScheduledOperation{}, nil
+	case "error":
+		return nil, fmt.Errorf("%w: scheduled operations page returned error state", bank.ErrBankUnavailable)
+	}
+
+	rows := table.Find(SelectorScheduledRow)
+	ops := make([]bank.ScheduledOperation, 0, rows.Length())
+
+	var parseErr error
+	rows.EachWithBreak(func(i int, row *goquery.Selection) bool {
+		op, err := parseScheduledRow(row)
+		if err != nil {
+			parseErr = fmt.Errorf("failed to parse scheduled row: %d: %w", i, err)
+			return false
+		}
+		ops = append(ops, *op)
+		return true
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	return ops, nil
+}
+
+func parseScheduledRow(row *goquery.Selection) (*bank.ScheduledOperation, error) {
+	dateElem := row.Find(SelectorScheduledDate)
+	dateStr, exists := dateElem.Attr("date")
+	if !exists {
+		return nil, fmt.Errorf("%w: missing scheduled date", bank.ErrParsingFailed)
+	}
+	date, err := parseBankDate2026(dateStr, dateElem.AttrOr("year", ""))
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse scheduled date: %v", bank.ErrParsingFailed, err)
+	}
+
+	amountElem := row.Find(SelectorScheduledAmount)
+	amountStr, exists := amountElem.Attr("amount")
+	if !exists {
+		return nil, fmt.Errorf("%w: missing amount", bank.ErrParsingFailed)
+	}
+	amount, err := ParseSpanishAmount(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse amount: %v", bank.ErrParsingFailed, err)
+	}
+	currency, err := currencyFromCode(amountElem.AttrOr("currency-code", ""))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", bank.ErrParsingFailed, err)
+	}
+
+	// The portal only lists operations ordered by the company, so every
+	// scheduled operation debits the origin account; some rows show a sign.
+	if amount < 0 {
+		amount = -amount
+	}
+
+	typeStr := strings.TrimSpace(row.Find(SelectorScheduledType).AttrOr("text", ""))
+
+	return &bank.ScheduledOperation{
+		ID:            strings.TrimSpace(row.Find(SelectorScheduledID).AttrOr("text", "")),
+		AccountID:     strings.TrimSpace(row.Find(SelectorScheduledAccount).AttrOr("text", "")),
+		Kind:          scheduledKind(typeStr),
+		Type:          bank.TransactionDebit,
+		Description:   typeStr,
+		Counterparty:  strings.TrimSpace(row.Find(SelectorScheduledCounterparty).AttrOr("text", "")),
+		Amount:        amount,
+		Currency:      currency,
+		ScheduledDate: date,
+		Recurrence:    strings.TrimSpace(row.Find(SelectorScheduledFrequency).AttrOr("text", "")),
+		Status:        strings.TrimSpace(row.Find(SelectorScheduledStatus).AttrOr("text", "")),
+	}, nil
+}
+
+// scheduledKind maps BBVA's operation type label to a ScheduledKind.
+func scheduledKind(label string) bank.ScheduledKind {
+	l := strings.ToLower(label)
+	switch {
+	case strings.Contains(l, "transferencia"):
+		return bank.ScheduledTransfer
+	case strings.Contains(l, "débito") || strings.Contains(l, "debito") || strings.Contains(l, "cargo"):
+		return bank.ScheduledDirectDebit
+	case strings.Contains(l, "pago"):
+		return bank.ScheduledPayment
+	default:
+		return bank.ScheduledOther
+	}
+}
+
 // hasNoMovements checks if the transaction page has a "No Movements" error.
 func hasNoMovements(doc *goquery.Document) bool {
 	table := doc.Find(SelectorTransactionsTable)
@@ -422,8 +649,9 @@ func parseListViewRow(row *goquery.Selection, currency bank.Currency) (*bank.Bal
 	}, nil
 }
 
-func parseAccountsTileView(doc *goquery.Document) ([]bank.Balance, error) {
+func parseAccountsTileView(doc *goquery.Document) ([]bank.Balance, []string, error) {
 	var balances []bank.Balance
+	var warnings []string
 	var parseErr error
 
 	doc.Find(SelectorAccountCard).EachWithBreak(func(i int, card *goquery.Selection) bool {
@@ -433,17 +661,21 @@ func parseAccountsTileView(doc *goquery.Document) ([]bank.Balance, error) {
 			return false
 		}
 		if bal == nil {
-			return true // Skip cards without amount data
+			// Skip cards without amount data; only the overview card is expected.
+			if id := card.AttrOr("id", ""); id != AccountCardOverviewID {
+				warnings = append(warnings, fmt.Sprintf("account card %d (%q) has no amount; skipped", i, id))
+			}
+			return true
 		}
 		balances = append(balances, *bal)
 		return true
 	})
 
 	if parseErr != nil {
-		return nil, parseErr
+		return nil, nil, parseErr
 	}
 
-	return balances, nil
+	return balances, warnings, nil
 }
 
 func parseTileViewCard(card *goquery.Selection) (*bank.Balance, error) {
@@ -501,44 +733,19 @@ func currencyFromCode(code string) (bank.Currency, error) {
 // ParseSpanishAmount transforms a string representation of a number to an int64
 // representation with two decimals.
 func ParseSpanishAmount(s string) (int64, error) {
-	cleanStr := strings.NewReplacer(",", "", " ", "").Replace(s)
-
-	// Parse into float
-	floatVal, err := strconv.ParseFloat(cleanStr, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	// Round
-	return int64(math.Round(floatVal * 100)), nil
+	return layout.ParseAmount(s)
 }
 
 // parseBankDate2026 parses the 2026 date format: date attr ("10 Feb") + year attr ("2026").
-// Spanish month abbreviations (Ene, Feb, Mar, ...) are translated to English before parsing.
+// Spanish month abbreviations (Ene, Feb, Mar, ...) are translated by parseutil.
 func parseBankDate2026(date, year string) (time.Time, error) {
-	cleanStr := strings.Fields(date)
-	if len(cleanStr) != 2 {
+	if len(strings.Fields(date)) != 2 {
 		return time.Time{}, fmt.Errorf("invalid date format: %s", date)
 	}
-
-	engMonth := spanishMonths[cleanStr[1]]
-
-	parsedDate, err := time.Parse(bbvaDateLayout2026, strings.Join([]string{cleanStr[0], engMonth, year}, " "))
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	return parsedDate, nil
+	return parseutil.ParseDate(date+" "+year, bbvaDateLayout2026)
 }
 
 // ParseBankDate parses a date string in DD-MM-YYYY format.
 func ParseBankDate(s string) (time.Time, error) {
-	// 1. Clean up the string
-	cleanStr := strings.TrimSpace(s)
-	// 2. Parse using reference layout
-	t, err := time.Parse(bbvaDateLayout, cleanStr)
-	if err != nil {
-		return time.Time{}, err
-	}
-	return t, nil
+	return parseutil.ParseDate(s, bbvaDateLayout)
 }
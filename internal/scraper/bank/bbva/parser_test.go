@@ -1,6 +1,7 @@
 package bbva
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -198,6 +199,24 @@ func TestParseAccountBalances_TileViewUnknownCurrencySymbol(t *testing.T) {
 	assert.ErrorIs(t, err, bank.ErrParsingFailed)
 }
 
+func TestParseAccountBalancesWithWarnings_TileView(t *testing.T) {
+	_, warnings, err := ParseAccountBalancesWithWarnings(testutil.LoadFixture(t, "bbva", "accounts_tile"))
+	require.NoError(t, err)
+	assert.Empty(t, warnings, "the overview card is expected to have no amount")
+
+	html := `<html><body>
+		<bbva-btge-card-product-select id="allContracts" header-text="Todas las cuentas"></bbva-btge-card-product-select>
+		<bbva-btge-card-product-select id="PE001" product-amount="500.00" product-amount-currency="S/"></bbva-btge-card-product-select>
+		<bbva-btge-card-product-select id="PE002" product-name="Cuenta Corriente"></bbva-btge-card-product-select>
+	</body></html>`
+
+	balances, warnings, err := ParseAccountBalancesWithWarnings(html)
+	require.NoError(t, err)
+	assert.Len(t, balances, 1)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `"PE002"`)
+}
+
 func int64Ptr(v int64) *int64 { return &v }
 
 func TestParseTransactions(t *testing.T) {
@@ -215,6 +234,7 @@ func TestParseTransactions(t *testing.T) {
 	assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), row0.Date)
 	assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), row0.ValueDate)
 	assert.Equal(t, "PAGO FACTURA | SUNAT DETRACCIONES", row0.Description)
+	assert.Equal(t, "PAGO FACTURA | Sunat Detracciones", row0.CleanDescription)
 	assert.Equal(t, int64(350), row0.Amount)
 	assert.Equal(t, bank.TransactionDebit, row0.Type)
 	require.NotNil(t, row0.BalanceAfter)
@@ -311,6 +331,12 @@ func TestParseTransactions_InvalidRow(t *testing.T) {
 	assert.ErrorIs(t, err, bank.ErrParsingFailed)
 }
 
+func TestDetectLoginVariant(t *testing.T) {
+	assert.Equal(t, LoginVariantSenda, DetectLoginVariant(testutil.LoadFixture(t, "bbva", "login_page")))
+	assert.Equal(t, LoginVariantLegacy, DetectLoginVariant(`<form><input id="empresa"><button id="aceptar">Ingresar</button></form>`))
+	assert.Equal(t, LoginVariantUnknown, DetectLoginVariant(testutil.LoadFixture(t, "bbva", "login_error_404")))
+}
+
 func TestDetectLoginError_404(t *testing.T) {
 	html := testutil.LoadFixture(t, "bbva", "login_error_404")
 
@@ -796,3 +822,215 @@ func Test_hasNoMovements(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTransactionDetail(t *testing.T) {
+	html := `<bbva-btge-accounts-solution-movement-detail>
+		<bbva-list-description-item label="N° de operación" description="00123456"></bbva-list-description-item>
+		<bbva-list-description-item label="Canal" description="Banca por Internet"></bbva-list-description-item>
+		<bbva-list-description-item label="Fecha y hora" description="10/02/2026 14:35:12"></bbva-list-description-item>
+		<bbva-list-description-item label="Oficina" text="0437"></bbva-list-description-item>
+		<bbva-list-description-item label="Vacío" description=""></bbva-list-description-item>
+	</bbva-btge-accounts-solution-movement-detail>`
+
+	detail, err := ParseTransactionDetail(html)
+	require.NoError(t, err)
+
+	assert.Equal(t, "00123456", detail.OperationNumber)
+	assert.Equal(t, "Banca por Internet", detail.Channel)
+	assert.Equal(t, time.Date(2026, 2, 10, 14, 35, 12, 0, time.UTC), detail.Timestamp)
+	assert.Equal(t, "0437", detail.Fields["Oficina"])
+	assert.NotContains(t, detail.Fields, "Vacío")
+	assert.Empty(t, detail.Warnings)
+}
+
+func TestParseTransactionDetail_Warnings(t *testing.T) {
+	html := `<bbva-btge-accounts-solution-movement-detail>
+		<bbva-list-description-item label="Fecha y hora" description="mañana"></bbva-list-description-item>
+		<bbva-list-description-item label=" " description="sin etiqueta"></bbva-list-description-item>
+	</bbva-btge-accounts-solution-movement-detail>`
+
+	detail, err := ParseTransactionDetail(html)
+	require.NoError(t, err)
+
+	assert.True(t, detail.Timestamp.IsZero())
+	assert.Equal(t, []string{
+		`unrecognized detail timestamp "mañana"`,
+		`unlabeled detail field "sin etiqueta" skipped`,
+	}, detail.Warnings)
+}
+
+func TestParseTransactionDetail_NoFields(t *testing.T) {
+	_, err := ParseTransactionDetail(`<bbva-btge-accounts-solution-movement-detail></bbva-btge-accounts-solution-movement-detail>`)
+	assert.ErrorIs(t, err, bank.ErrParsingFailed)
+}
+
+func TestTransactionDetail_Apply(t *testing.T) {
+	tx := bank.Transaction{ID: "mv-1", Extra: map[string]string{"Codigo": "015"}}
+	detail := &TransactionDetail{
+		OperationNumber: "00123456",
+		Channel:         "Banca por Internet",
+		Timestamp:       time.Date(2026, 2, 10, 14, 35, 12, 0, time.UTC),
+		Fields:          map[string]string{"Oficina": "0437", "Codigo": "999"},
+	}
+
+	detail.Apply(&tx)
+
+	assert.Equal(t, "00123456", tx.Reference)
+	assert.Equal(t, "Banca por Internet", tx.Extra["Channel"])
+	assert.Equal(t, "2026-02-10T14:35:12Z", tx.Extra["Timestamp"])
+	assert.Equal(t, "0437", tx.Extra["Oficina"])
+	assert.Equal(t, "015", tx.Extra["Codigo"], "table data must not be overwritten")
+}
+
+func TestNormalizeLabel(t *testing.T) {
+	tests := map[string]string{
+		"N° de operación":     detailLabelOperation,
+		"Número de operación": detailLabelOperation,
+		"Nº de Operación:":    detailLabelOperation,
+		"Canal":               detailLabelChannel,
+		" Fecha y  hora ":     detailLabelDateTime,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, want, normalizeLabel(input))
+		})
+	}
+}
+
+func TestParseScheduledOperations(t *testing.T) {
+	html := `<bbva-btge-accounts-solution-table id="scheduled-operations-table"><table><tbody>
+		<tr class="row">
+			<td><bbva-table-body-date class="scheduledDate" date="15 Mar" year="2026"></bbva-table-body-date></td>
+			<td><bbva-table-body-text class="operationNumber" text="PRG-001"></bbva-table-body-text></td>
+			<td><bbva-table-body-text class="operationType" text="Transferencia a terceros "></bbva-table-body-text></td>
+			<td><bbva-table-body-text class="originAccount" text="0011-0123-0200123456"></bbva-table-body-text></td>
+			<td><bbva-table-body-text class="beneficiary" text="PROVEEDOR SAC"></bbva-table-body-text></td>
+			<td><bbva-table-body-amount class="operationAmount" amount="-12,500.00" currency-code="PEN"></bbva-table-body-amount></td>
+			<td><bbva-table-body-text class="frequency" text="Mensual"></bbva-table-body-text></td>
+			<td><bbva-table-body-text class="status" text="Pendiente"></bbva-table-body-text></td>
+		</tr>
+		<tr class="row">
+			<td><bbva-table-body-date class="scheduledDate" date="01 Abr" year="2026"></bbva-table-body-date></td>
+			<td><bbva-table-body-text class="operationNumber" text="PRG-002"></bbva-table-body-text></td>
+			<td><bbva-table-body-text class="operationType" text="Débito automático"></bbva-table-body-text></td>
+			<td><bbva-table-body-amount class="operationAmount" amount="350.10" currency-code="USD"></bbva-table-body-amount></td>
+		</tr>
+	</tbody></table></bbva-btge-accounts-solution-table>`
+
+	ops, err := ParseScheduledOperations(html)
+	require.NoError(t, err)
+	require.Len(t, ops, 2)
+
+	assert.Equal(t, "PRG-001", ops[0].ID)
+	assert.Equal(t, bank.ScheduledTransfer, ops[0].Kind)
+	assert.Equal(t, bank.TransactionDebit, ops[0].Type)
+	assert.Equal(t, "Transferencia a terceros", ops[0].Description)
+	assert.Equal(t, "0011-0123-0200123456", ops[0].AccountID)
+	assert.Equal(t, "PROVEEDOR SAC", ops[0].Counterparty)
+	assert.Equal(t, int64(1_250_000), ops[0].Amount)
+	assert.Equal(t, bank.CurrencyPEN, ops[0].Currency)
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), ops[0].ScheduledDate)
+	assert.Equal(t, "Mensual", ops[0].Recurrence)
+	assert.Equal(t, "Pendiente", ops[0].Status)
+
+	assert.Equal(t, bank.ScheduledDirectDebit, ops[1].Kind)
+	assert.Equal(t, int64(35010), ops[1].Amount)
+	assert.Equal(t, bank.CurrencyUSD, ops[1].Currency)
+	assert.Empty(t, ops[1].Recurrence)
+}
+
+func TestParseScheduledOperations_States(t *testing.T) {
+	ops, err := ParseScheduledOperations(`<bbva-btge-accounts-solution-table id="scheduled-operations-table" state="noresults"></bbva-btge-accounts-solution-table>`)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	_, err = ParseScheduledOperations(`<bbva-btge-accounts-solution-table id="scheduled-operations-table" state="error"></bbva-btge-accounts-solution-table>`)
+	assert.ErrorIs(t, err, bank.ErrBankUnavailable)
+
+	_, err = ParseScheduledOperations(`<div></div>`)
+	assert.ErrorIs(t, err, bank.ErrParsingFailed)
+}
+
+func TestParseScheduledOperations_InvalidRow(t *testing.T) {
+	html := `<bbva-btge-accounts-solution-table id="scheduled-operations-table"><table><tbody>
+		<tr class="row">
+			<td><bbva-table-body-date class="scheduledDate" date="15 Mar" year="2026"></bbva-table-body-date></td>
+			<td><bbva-table-body-amount class="operationAmount" amount="10.00" currency-code="EUR"></bbva-table-body-amount></td>
+		</tr>
+	</tbody></table></bbva-btge-accounts-solution-table>`
+
+	_, err := ParseScheduledOperations(html)
+	assert.ErrorIs(t, err, bank.ErrParsingFailed)
+}
+
+func TestScheduledKind(t *testing.T) {
+	tests := map[string]bank.ScheduledKind{
+		"Transferencia a terceros": bank.ScheduledTransfer,
+		"Débito automático":        bank.ScheduledDirectDebit,
+		"Cargo recurrente":         bank.ScheduledDirectDebit,
+		"Pago de servicios":        bank.ScheduledPayment,
+		"Otro":                     bank.ScheduledOther,
+	}
+	for label, want := range tests {
+		t.Run(label, func(t *testing.T) {
+			assert.Equal(t, want, scheduledKind(label))
+		})
+	}
+}
+
+func TestParseTransactions_Synthetic(t *testing.T) {
+	page := testutil.SyntheticBBVATransactions(testutil.SyntheticOptions{Rows: 2000, Seed: 1, EdgeCaseRate: 0.3})
+
+	got, err := ParseTransactions(page.HTML)
+
+	require.NoError(t, err)
+	assert.Equal(t, page.Want, got)
+}
+
+func TestParseTransactions_SyntheticMalformed(t *testing.T) {
+	for seed := range uint64(20) {
+		page := testutil.SyntheticBBVATransactions(testutil.SyntheticOptions{Rows: 200, Seed: seed, EdgeCaseRate: 0.2, MalformedRate: 0.02})
+		if len(page.Malformed) == 0 {
+			continue
+		}
+
+		got, err := ParseTransactions(page.HTML)
+
+		assert.Nil(t, got, "seed %d", seed)
+		assert.ErrorIs(t, err, bank.ErrParsingFailed, "seed %d", seed)
+		assert.ErrorContains(t, err, fmt.Sprintf("row: %d:", page.Malformed[0]), "seed %d", seed)
+	}
+}
+
+func BenchmarkParseTransactions(b *testing.B) {
+	for _, rows := range []int{50, 500, 5000} {
+		page := testutil.SyntheticBBVATransactions(testutil.SyntheticOptions{Rows: rows, Seed: 1, EdgeCaseRate: 0.1})
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			b.SetBytes(int64(len(page.HTML)))
+			for b.Loop() {
+				if _, err := ParseTransactions(page.HTML); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func FuzzParseTransactions(f *testing.F) {
+	f.Add(testutil.LoadFixture(f, "bbva", "transactions_empty"))
+	for seed := range uint64(8) {
+		f.Add(testutil.SyntheticBBVATransactions(testutil.SyntheticOptions{Rows: 5, Seed: seed, EdgeCaseRate: 0.5, MalformedRate: 0.2}).HTML)
+	}
+	f.Fuzz(func(t *testing.T, html string) {
+		got, err := ParseTransactions(html)
+		if err != nil {
+			assert.Nil(t, got)
+			return
+		}
+		for _, txn := range got {
+			assert.False(t, txn.Date.IsZero())
+			assert.GreaterOrEqual(t, txn.Amount, int64(0))
+			assert.NotNil(t, txn.BalanceAfter)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package bbva
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/debug"
+)
+
+// Ensure Scraper supports listing scheduled operations.
+var _ bank.ScheduledOperationsScraper = (*Scraper)(nil)
+
+// GetScheduledOperations lists the scheduled transfers, direct debits and
+// payments shown under "Operaciones programadas". Read-only: nothing is
+// clicked beyond navigation.
+func (s *Scraper) GetScheduledOperations(ctx context.Context) ([]bank.ScheduledOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := debug.StartOp(s.logger, "GetScheduledOperations")
+
+	page, err := s.activePage("GetScheduledOperations")
+	if err != nil {
+		return nil, err
+	}
+
+	navCtx, navCancel := context.WithTimeout(ctx, s.timeout)
+	defer navCancel()
+	if err := navigateTo(navCtx, page, scheduledURL); err != nil {
+		pageURL, dir := s.debug.Snapshot(page, "GetScheduledOperations", "navigate-error")
+		op.Error("scheduled operations page not reachable", err,
+			slog.String("url", pageURL), slog.String("debug_dir", dir))
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetScheduledOperations",
+			Cause:     bank.ErrUnknown,
+			Details:   fmt.Sprintf("navigate to scheduled operations: %v (url=%s, debug=%s)", err, pageURL, dir),
+		}
+	}
+
+	if !waitForScheduledReady(ctx, page, s.timeout) {
+		pageURL, dir := s.debug.Snapshot(page, "GetScheduledOperations", "table-timeout")
+		op.Error("timed out waiting for scheduled operations table", bank.ErrUnknown,
+			slog.String("url", pageURL), slog.String("debug_dir", dir))
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetScheduledOperations",
+			Cause:     bank.ErrUnknown,
+			Details:   fmt.Sprintf("timed out waiting for scheduled operations table (url=%s, debug=%s)", pageURL, dir),
+		}
+	}
+
+	extractCtx, extractCancel := context.WithTimeout(ctx, s.timeout)
+	defer extractCancel()
+	html, err := browser.DeepQueryOuterHTML(page.Context(extractCtx), SelectorScheduledTable)
+	if err != nil {
+		s.debug.Screenshot(page, "GetScheduledOperations", "extract-error")
+		op.Error("extract scheduled operations HTML failed", err)
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetScheduledOperations",
+			Cause:     bank.ErrUnknown,
+			Details:   fmt.Sprintf("extract scheduled operations HTML: %v", err),
+		}
+	}
+
+	bank.RecordHTML(ctx, "GetScheduledOperations", "", html)
+	ops, err := ParseScheduledOperations(html)
+	if err != nil {
+		s.debug.HTMLString(html, "GetScheduledOperations", "parse-error")
+		op.Error("parse scheduled operations failed", err, slog.String("debug_dir", s.debug.Dir()))
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetScheduledOperations",
+			Cause:     err,
+			Details:   fmt.Sprintf("parse scheduled operations failed (debug HTML dumped to %s)", s.debug.Dir()),
+		}
+	}
+
+	op.Success(slog.Int("scheduled_count", len(ops)))
+	return ops, nil
+}
+
+// waitForScheduledReady polls until the scheduled operations table has rows
+// or a terminal state attribute ("noresults", "error").
+func waitForScheduledReady(ctx context.Context, page *rod.Page, timeout time.Duration) bool {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	p := page.Context(waitCtx)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if browser.DeepQueryAttr(p, SelectorScheduledTable, "state") != "" {
+			return true
+		}
+		if browser.DeepQueryAttr(p, SelectorScheduledDate, "date") != "" {
+			return true
+		}
+		select {
+		case <-waitCtx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,37 +5,48 @@ package bbva
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/scraper/browser"
 	"github.com/aynifx/bank-scraper/internal/scraper/debug"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
+	"github.com/aynifx/bank-scraper/internal/scraper/preflight"
 )
 
 const (
-	baseURL     = "https://www.bbvanetcash.pe"
-	loginURL    = baseURL + "/DFAUTH85/mult/KDPOSolicitarCredenciales_es.html"
-	portalURL   = baseURL + "/nextgenempresas/portal/index.html"
-	accountsURL = baseURL + "/nextgenempresas/portal/index.html#!/bbva-btge-accounts-solution"
+	baseURL      = "https://www.bbvanetcash.pe"
+	loginURL     = baseURL + "/DFAUTH85/mult/KDPOSolicitarCredenciales_es.html"
+	portalURL    = baseURL + "/nextgenempresas/portal/index.html"
+	accountsURL  = baseURL + "/nextgenempresas/portal/index.html#!/bbva-btge-accounts-solution"
+	scheduledURL = baseURL + "/nextgenempresas/portal/index.html#!/bbva-btge-scheduled-operations-solution"
 
 	maxPaginationClicks    = 10 // Safety limit for "Ver más" pagination loop
 	maxAccountsNavAttempts = 3  // Total attempts for navigate+wait on accounts page
 
 	defaultTimeout         = 30 * time.Second
 	accountsNavStepTimeout = 15 * time.Second // Timeout per step (navigate or wait) when retrying
+	minAccountsRenderWait  = 5 * time.Second  // Shortest learned wait for the accounts to render
 
-	bbvaSessionTimeout = 10 * time.Minute
+	// BBVA Net Cash signs sessions out after 10 minutes without use; no
+	// hard cap on an active session has been observed.
+	defaultSessionIdle = 10 * time.Minute
+
+	// maxClockSkew is how far the local clock may drift from the portal's
+	// before operations warn: more and date windows shift by a day near
+	// midnight.
+	maxClockSkew = 2 * time.Minute
 
 	minTransactionCount = 50
 	maxTransactionCount = 250
@@ -43,17 +54,51 @@ const (
 
 const debugBaseDir = "bbva-debug"
 
+// portalHost is the host whose responses' Date headers give the portal's
+// clock.
+const portalHost = "bbvanetcash.pe"
+
 // Scraper implements browser automation for the BBVA Net Cash portal.
+//
+// A Scraper is safe for concurrent use, but runs one operation at a time:
+// all of them drive the single authenticated tab, so concurrent calls
+// queue up behind the one in progress. Close waits for it too.
 type Scraper struct {
+	mu sync.Mutex // Serializes operations on the shared tab and session
+
 	browser  *rod.Browser
 	page     *rod.Page         // Authenticated page, kept alive between operations
 	router   *rod.HijackRouter // Request hijacker, kept alive with the page
 	session  *bank.Session
-	debug    *debug.Collector // Session-scoped artifact capture; nil before Login
+	debug    *debug.Collector  // Session-scoped artifact capture; nil before Login
+	audit    *debug.AuditTrail // Per-step screenshots; nil unless WithAuditScreenshots
+	auditDir string            // WithAuditScreenshots base dir
 	timeout  time.Duration
-	headless bool              // Whether to launch browser in headless mode
-	hijacker func(*rod.Hijack) // Optional hijacker for replay testing
+	lifetime time.Duration        // Hard cap on a session's life; zero for none (WithSessionLifetime)
+	idle     time.Duration        // Idle timeout of a session (WithIdleTimeout)
+	headless bool                 // Whether to launch browser in headless mode
+	bin      string               // Browser binary; empty lets rod pick one
+	remote   *browser.Remote      // Containerized browser to connect to instead of launching one
+	proxy    string               // Proxy the launched browser goes through (WithProxy)
+	client   *http.Client         // Fetches the hijacked requests, through proxy
+	geo      *preflight.GeoCheck  // Egress country check before Login (WithGeoPreflight)
+	hijacker func(*rod.Hijack)    // Optional hijacker for replay testing
+	wrap     []browser.Middleware // Wrap the hijacker or the network, outermost first (WithMiddleware)
+	human    *browser.Human       // Human-like typing and pauses; nil skips them (replay, unless WithHuman)
+	timings  *bank.Timings        // Learned step latencies sizing the waits (WithTimings)
+	scripts  []browser.Script     // JS shims injected into served documents (WithInjectedScripts)
+	readOnly bool                 // Block requests that could move money (WithReadOnly)
+	flags    bank.Flags           // Experimental behaviors enabled (WithFlags)
 	logger   *slog.Logger
+	describe *parseutil.DescriptionNormalizer // Optional override for CleanDescription
+
+	detailWorkers int  // >0 enables per-transaction detail fetching (WithTransactionDetails)
+	screencast    bool // Record operations, keeping failed ones (WithScreencast)
+
+	console    *browser.ConsoleLog  // JS errors of the authenticated page, reported as warnings
+	network    *browser.NetworkLog  // Requests of the authenticated page; nil unless networkLog
+	clock      *browser.ServerClock // Portal's clock, from live responses; nil under replay
+	networkLog bool                 // Record requests into bank.WithRequestLog (WithNetworkLog)
 }
 
 // credentials holds BBVA login fields (internal, mapped from generic map).
@@ -101,6 +146,23 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithSessionLifetime caps how long a session is used after Login, however
+// active. Default is no cap.
+func WithSessionLifetime(d time.Duration) Option {
+	return func(s *Scraper) {
+		s.lifetime = d
+	}
+}
+
+// WithIdleTimeout sets how long a session is kept without use before it
+// counts as expired. Default is 10 minutes, the portal's own; zero never
+// idles a session out.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *Scraper) {
+		s.idle = d
+	}
+}
+
 // WithHeadless controls whether the browser launches in headless mode.
 // Default is true. Set to false for visual debugging of live sessions.
 func WithHeadless(headless bool) Option {
@@ -109,6 +171,44 @@ func WithHeadless(headless bool) Option {
 	}
 }
 
+// WithBrowserBin launches the Chromium binary at path, typically one picked
+// by browser.Resolver. Default is rod's own lookup, which downloads a browser
+// silently when none is installed.
+func WithBrowserBin(path string) Option {
+	return func(s *Scraper) {
+		s.bin = path
+	}
+}
+
+// WithRemoteBrowser connects to a containerized Chrome (browserless,
+// alpine-chrome) instead of launching one; see browser.Remote. Headless and
+// WithBrowserBin don't apply.
+func WithRemoteBrowser(r browser.Remote) Option {
+	return func(s *Scraper) {
+		s.remote = &r
+	}
+}
+
+// WithProxy sends the launched browser's traffic through proxy, an HTTP or
+// SOCKS URL browser.ParseProxy accepted (e.g. Tor's socks5://127.0.0.1:9050).
+// The portal requests the scraper hijacks and fetches itself go through it
+// too. The browser flag doesn't apply to a remote browser, whose container
+// sets its own.
+func WithProxy(proxy string) Option {
+	return func(s *Scraper) {
+		s.proxy = proxy
+	}
+}
+
+// WithGeoPreflight checks the egress IP's country before each Login. A login
+// from elsewhere is reported as a bank.Warning, or refused when
+// c.Enforce is set. c.Client should go through the browser's proxy.
+func WithGeoPreflight(c *preflight.GeoCheck) Option {
+	return func(s *Scraper) {
+		s.geo = c
+	}
+}
+
 // WithHijacker sets a custom hijacker middleware for request interception.
 // This is used for replay testing to serve recorded responses instead of
 // making real network requests.
@@ -118,6 +218,102 @@ func WithHijacker(middleware func(*rod.Hijack)) Option {
 	}
 }
 
+// WithMiddleware wraps the requests of the portal's pages, whether the
+// hijacker or the network serves them, in mws: the first sees each request
+// first. Combine e.g. browser.BlockResources with a replay or recording
+// hijacker. The read-only guard (WithReadOnly) stays outside them all.
+func WithMiddleware(mws ...browser.Middleware) Option {
+	return func(s *Scraper) {
+		s.wrap = append(s.wrap, mws...)
+	}
+}
+
+// WithHuman times the human-like typing and pauses of Login with h, e.g.
+// a browser.NewHuman with a fixed seed for reproducible runs. Under replay
+// (WithHijacker), which otherwise types fast without pauses, it also turns
+// them on, so replay tests can cover them.
+func WithHuman(h *browser.Human) Option {
+	return func(s *Scraper) {
+		s.human = h
+	}
+}
+
+// WithTimings records how long the portal's steps take into t, and sizes
+// the waits that retry or poll from what it has learned; see bank.Timings.
+func WithTimings(t *bank.Timings) Option {
+	return func(s *Scraper) {
+		s.timings = t
+	}
+}
+
+// WithInjectedScripts injects JS into the HTML documents of the login page
+// and everything it opens, iframes included, ahead of their own scripts. For
+// shims replay needs, such as stubbing the Senda iframe's postMessage
+// handshake. See browser.InjectScripts.
+func WithInjectedScripts(scripts ...browser.Script) Option {
+	return func(s *Scraper) {
+		s.scripts = append(s.scripts, scripts...)
+	}
+}
+
+// WithReadOnly blocks every request that looks like it could move money or
+// change the company's setup: a write (not GET, HEAD or OPTIONS) whose URL
+// matches MutatingPathTerms. Login and the reads the scraper does are never
+// blocked. Live tests force it on as a guardrail.
+func WithReadOnly(enabled bool) Option {
+	return func(s *Scraper) {
+		s.readOnly = enabled
+	}
+}
+
+// WithAuditScreenshots keeps a timestamped screenshot of each major step
+// (post-login, accounts, transactions) under dir/{session ID}/, listed in
+// audit.jsonl, as an evidence trail of what the automation saw. Steps name
+// accounts by their masked number (bank.MaskAccountID).
+func WithAuditScreenshots(dir string) Option {
+	return func(s *Scraper) {
+		s.auditDir = dir
+	}
+}
+
+// WithScreencast records each Login, GetBalance and GetTransactions as a
+// CDP screencast and saves it to the debug directory as a WebM when the
+// operation fails, for debugging intermittent failures. Encoding needs
+// ffmpeg on PATH; without it the frames are kept instead.
+func WithScreencast(enabled bool) Option {
+	return func(s *Scraper) {
+		s.screencast = enabled
+	}
+}
+
+// WithNetworkLog records every request the browser makes (method, URL
+// without query, status, timing; no bodies) into the request log attached to
+// each operation's context with bank.WithRequestLog. Cheaper than a HAR for
+// telling which call returned 403.
+func WithNetworkLog(enabled bool) Option {
+	return func(s *Scraper) {
+		s.networkLog = enabled
+	}
+}
+
+// Feature flags of the BBVA scraper (see bank.Flag).
+const (
+	// FlagLegacyLogin logs in through the legacy DFServlet form's #aceptar
+	// when BBVA serves that page instead of the Senda one, rather than
+	// failing on the missing #enviarSenda.
+	FlagLegacyLogin bank.Flag = "legacy-login"
+)
+
+// KnownFlags lists the BBVA scraper's feature flags, for bank.ParseFlags.
+var KnownFlags = []bank.Flag{FlagLegacyLogin}
+
+// WithFlags enables experimental behaviors; see KnownFlags.
+func WithFlags(flags bank.Flags) Option {
+	return func(s *Scraper) {
+		s.flags = flags
+	}
+}
+
 // WithLogger sets a custom logger.
 func WithLogger(l *slog.Logger) Option {
 	return func(s *Scraper) {
@@ -125,10 +321,19 @@ func WithLogger(l *slog.Logger) Option {
 	}
 }
 
+// WithDescriptionNormalizer overrides the pipeline that builds each
+// transaction's CleanDescription. Default is parseutil.DefaultDescriptionNormalizer.
+func WithDescriptionNormalizer(n *parseutil.DescriptionNormalizer) Option {
+	return func(s *Scraper) {
+		s.describe = n
+	}
+}
+
 // NewScraper creates a new BBVA scraper with the given options.
 func NewScraper(opts ...Option) (*Scraper, error) {
 	s := &Scraper{
 		timeout:  defaultTimeout,
+		idle:     defaultSessionIdle,
 		headless: true,
 		logger:   slog.Default(),
 	}
@@ -136,12 +341,29 @@ func NewScraper(opts ...Option) (*Scraper, error) {
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.human == nil && s.hijacker == nil {
+		s.human = browser.RandomHuman()
+	}
+	client, err := portalClient(s.proxy)
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
 
-	// Launch with stealth flags to avoid bot detection
-	url, err := launcher.New().
-		Set("disable-blink-features", "AutomationControlled").
-		Headless(s.headless).
-		Launch()
+	if s.remote != nil {
+		bro, err := s.remote.Connect()
+		if err != nil {
+			return nil, err
+		}
+		s.browser = bro
+		return s, nil
+	}
+
+	l := browser.NewLauncher(s.bin, s.headless)
+	if s.proxy != "" {
+		l = browser.WithProxy(l, s.proxy)
+	}
+	url, err := l.Launch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to launch browser: %w", err)
 	}
@@ -155,9 +377,36 @@ func NewScraper(opts ...Option) (*Scraper, error) {
 	return s, nil
 }
 
+// newPage opens a tab on url (blank when empty). On a remote browser the tab
+// gets its viewport and timezone before it navigates.
+func (s *Scraper) newPage(url string) (*rod.Page, error) {
+	if s.remote == nil {
+		return s.browser.Page(proto.TargetCreateTarget{URL: url})
+	}
+
+	page, err := s.browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.remote.Prepare(page); err != nil {
+		_ = page.Close()
+		return nil, err
+	}
+	if url != "" {
+		if err := page.Navigate(url); err != nil {
+			_ = page.Close()
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
 // Login authenticates with BBVA and returns a session.
 // Expected credential fields: "company_code", "user_code", "password".
 func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	op := debug.StartOp(s.logger, "Login")
 
 	creds, err := credentialsFromMap(fields)
@@ -170,16 +419,29 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 		}
 	}
 
-	// Close previous page if re-logging in
-	if s.page != nil {
-		s.stopHijacker()
-		_ = s.page.Close()
-		s.page = nil
-		s.session = nil
-		s.debug = nil
+	if s.geo != nil {
+		egress, err := s.geo.Run(ctx)
+		switch {
+		case err != nil && s.geo.Enforce:
+			op.Error("geo preflight refused login", err)
+			return nil, &bank.ScraperError{
+				Code:      bank.BankBBVA,
+				Operation: "Login",
+				Cause:     err,
+				Details:   "egress check failed; not logging in",
+			}
+		case err != nil:
+			op.Warn("geo preflight failed", slog.Any("error", err))
+			bank.Warn(ctx, bank.Warning{Code: bank.BankBBVA, Operation: "Login", Message: err.Error()})
+		default:
+			op.Info("geo preflight passed", slog.String("country", egress.Country))
+		}
 	}
 
-	page, err := s.browser.Page(proto.TargetCreateTarget{URL: loginURL})
+	// Close previous page if re-logging in
+	s.releasePage()
+
+	page, err := s.newPage(loginURL)
 	if err != nil {
 		op.Error("page creation failed", err)
 		return nil, &bank.ScraperError{
@@ -199,17 +461,36 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 		}
 	}()
 
+	rec := s.startScreencast(debug.New(DebugDir(), fmt.Sprintf("login-%d", time.Now().UnixNano()), s.logger), page, "Login")
+	defer func() { rec.Stop(!success) }()
+
+	console := browser.CaptureConsole(page)
+	var network *browser.NetworkLog
+	if s.networkLog {
+		network = browser.CaptureNetwork(page)
+	}
+	defer func() {
+		s.reportConsole(ctx, op, "Login", "", console)
+		reportNetwork(ctx, "Login", network)
+		if !success {
+			console.Stop()
+			network.Stop()
+		}
+	}()
+
 	// Set up request hijacking on the base page (no timeout context).
 	// The router's event context derives from the page's context at creation time.
 	router := page.HijackRequests()
 
-	if s.hijacker != nil {
-		router.MustAdd("*", s.hijacker)
-	} else {
-		router.MustAdd("*", func(h *rod.Hijack) {
-			_ = h.LoadResponse(http.DefaultClient, true)
-		})
+	// Replayed responses carry the recording's Date, so the portal's clock
+	// is only read live.
+	var clock *browser.ServerClock
+	if s.hijacker == nil {
+		clock = browser.NewServerClock(portalHost)
 	}
+	defer reportClock(ctx, "Login", clock)
+	handler := s.requestHandler(clock)
+	router.MustAdd("*", handler)
 
 	go router.Run()
 	s.router = router
@@ -225,11 +506,27 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 		return nil, fmt.Errorf("login page load failed: %w", err)
 	}
 
+	// Note which login page was served, so a shift of traffic to the legacy
+	// form shows up before the click below starts failing.
+	variant := LoginVariantUnknown
+	if html, err := p.HTML(); err == nil {
+		variant = DetectLoginVariant(html)
+	}
+	if variant != LoginVariantSenda {
+		op.Warn("unexpected login page variant", slog.String("variant", variant))
+	}
+	loginButton := SelectorLoginButton
+	legacy := variant == LoginVariantLegacy && s.flags.Enabled(FlagLegacyLogin)
+	if legacy {
+		op.Info("logging in through the legacy form", slog.String("flag", string(FlagLegacyLogin)))
+		loginButton = SelectorLegacyLoginButton
+	}
+
 	// 1. Fill credentials (form is on main page, not in iframe)
 	// Use human-like typing in live mode to avoid bot detection
 	typeFn := browser.TypeFast
-	if s.hijacker == nil {
-		typeFn = browser.TypeHuman
+	if s.human != nil {
+		typeFn = s.human.Type
 	}
 	if err := fillLoginForm(p, creds, typeFn); err != nil {
 		op.Error("fill form failed", err)
@@ -237,22 +534,35 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 	}
 
 	// Small random delay before clicking to appear more human-like
-	if s.hijacker == nil {
-		time.Sleep(time.Duration(200+rand.Intn(300)) * time.Millisecond)
+	if s.human != nil {
+		s.human.Pause(200*time.Millisecond, 500*time.Millisecond)
+	}
+
+	// In replay mode, serve the Senda iframe's own requests too, so the
+	// page gets its login outcome the way it does live. Without them the
+	// outcome comes from probeSendaAPI instead.
+	var senda *sendaReplay
+	if s.hijacker != nil {
+		if senda, err = s.startSendaReplay(handler); err != nil {
+			op.Info("senda iframe not hijacked, probing the API instead", slog.String("reason", err.Error()))
+		}
+		defer senda.stop()
 	}
 
 	// 2. Click login (#enviarSenda → Senda flow via postMessage to iframe)
-	loginBtn, err := p.Element(SelectorLoginButton)
-	if err != nil {
+	err = browser.WithElement(p, loginButton, func(el *rod.Element) error {
+		return el.Click(proto.InputMouseButtonLeft, 1)
+	})
+	if errors.Is(err, browser.ErrElementNotFound) {
 		op.Error("login button not found", err)
 		return nil, &bank.ScraperError{
 			Code:      bank.BankBBVA,
 			Operation: "Login",
-			Cause:     bank.ErrBankUnavailable,
+			Cause:     ErrorTable.Match(bank.ErrorSignature{Selector: loginButton}),
 			Details:   fmt.Sprintf("login button not found: %v", err),
 		}
 	}
-	if err := loginBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+	if err != nil {
 		op.Error("click login failed", err)
 		return nil, &bank.ScraperError{
 			Code:      bank.BankBBVA,
@@ -265,14 +575,16 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 
 	// 3. Wait for Senda outcome: portal redirect (success) or error span (failure)
 	// Each wait function derives its own context from ctx.
-	result := s.waitForLoginOutcome(ctx, page)
+	clickedAt := time.Now()
+	result := s.waitForLoginOutcome(ctx, page, senda, legacy)
 	switch result.outcome {
 	case loginSuccess:
+		s.timings.Observe(bank.BankBBVA, bank.StepLogin, time.Since(clickedAt))
 		if s.hijacker == nil {
 			// Live mode: wait for the SPA to set the dashboard route hash
 			if !s.waitForDashboard(ctx, page) {
 				// Pre-session failure: use temporary collector
-				preDebug := debug.New(debugDir(), fmt.Sprintf("pre-login-%d", time.Now().UnixNano()), s.logger)
+				preDebug := debug.New(DebugDir(), fmt.Sprintf("pre-login-%d", time.Now().UnixNano()), s.logger)
 				pageURL, dir := preDebug.Snapshot(page, "Login", "dashboard-not-loaded")
 				op.Error("dashboard did not load", bank.ErrUnknown,
 					slog.String("url", pageURL), slog.String("debug_dir", dir))
@@ -298,7 +610,7 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 
 	case loginTimeout:
 		// Pre-session failure: use temporary collector
-		preDebug := debug.New(debugDir(), fmt.Sprintf("pre-login-%d", time.Now().UnixNano()), s.logger)
+		preDebug := debug.New(DebugDir(), fmt.Sprintf("pre-login-%d", time.Now().UnixNano()), s.logger)
 		pageURL, dir := preDebug.Snapshot(page, "Login", "timeout")
 		op.Error("timed out waiting for redirect or error", bank.ErrUnknown,
 			slog.String("url", pageURL), slog.String("debug_dir", dir))
@@ -312,15 +624,26 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 
 	// Store session and page for subsequent operations
 	session := &bank.Session{
-		ID:        generateSessionID(),
-		Code:      bank.BankBBVA,
-		ExpiresAt: time.Now().Add(bbvaSessionTimeout),
+		ID:           generateSessionID(),
+		Code:         bank.BankBBVA,
+		IdleTimeout:  s.idle,
+		LastActive:   time.Now(),
+		LoginVariant: variant,
+	}
+	if s.lifetime > 0 {
+		session.ExpiresAt = session.LastActive.Add(s.lifetime)
 	}
 	s.session = session
 	s.page = page
-	s.debug = debug.New(debugDir(), session.ID, s.logger)
+	s.console, s.network = console, network
+	s.clock = clock
+	s.debug = debug.New(DebugDir(), session.ID, s.logger)
+	if s.auditDir != "" {
+		s.audit = debug.NewAuditTrail(s.auditDir, session.ID, s.logger)
+	}
 	s.logger = s.logger.With(slog.String("session_id", session.ID))
 	success = true
+	s.audit.Capture(page, "post-login")
 
 	op.Success()
 	return session, nil
@@ -328,19 +651,91 @@ func (s *Scraper) Login(ctx context.Context, fields map[string]string) (*bank.Se
 
 // Close shuts down the browser and releases resources.
 func (s *Scraper) Close() error {
-	s.stopHijacker()
-	if s.page != nil {
-		_ = s.page.Close()
-		s.page = nil
-	}
-	s.session = nil
-	s.debug = nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.releasePage()
 	if s.browser != nil {
 		return s.browser.Close()
 	}
 	return nil
 }
 
+// startScreencast records page over operation into c's directory when
+// WithScreencast is on; otherwise it returns nil, which Stop ignores.
+func (s *Scraper) startScreencast(c *debug.Collector, page *rod.Page, operation string) *debug.Screencast {
+	if !s.screencast {
+		return nil
+	}
+	return c.StartScreencast(page, operation)
+}
+
+// requestHandler returns the hijack handler serving the portal's pages: the
+// hijacker, or the network with clock reading its responses, wrapped in the
+// read-only guard, the WithMiddleware middlewares and the script injection,
+// in that order.
+func (s *Scraper) requestHandler(clock *browser.ServerClock) func(*rod.Hijack) {
+	handler := s.hijacker
+	if handler == nil {
+		handler = clock.Track(func(h *rod.Hijack) {
+			_ = h.LoadResponse(s.client, true)
+		})
+	}
+	var guard browser.Middleware
+	if s.readOnly {
+		guard = s.guardReadOnly
+	}
+	inject := func(next func(*rod.Hijack)) func(*rod.Hijack) {
+		return browser.InjectScripts(next, s.scripts)
+	}
+	return browser.Chain(handler, append(append([]browser.Middleware{guard}, s.wrap...), inject)...)
+}
+
+// portalClient returns the client hijacked requests are fetched with: every
+// portal request is, so without a proxy-aware client they would leave from
+// this host's IP whatever the browser's proxy.
+func portalClient(proxy string) (*http.Client, error) {
+	if proxy == "" {
+		return http.DefaultClient, nil
+	}
+	client, err := preflight.ProxyClient(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+	return client, nil
+}
+
+// guardReadOnly wraps next so mutating requests fail in the browser instead
+// of reaching the bank.
+func (s *Scraper) guardReadOnly(next func(*rod.Hijack)) func(*rod.Hijack) {
+	return func(h *rod.Hijack) {
+		if isMutatingRequest(h.Request.Method(), h.Request.URL().Path) {
+			s.logger.Warn("read-only mode: blocked request",
+				slog.String("method", h.Request.Method()),
+				slog.String("url", h.Request.URL().String()))
+			h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+		next(h)
+	}
+}
+
+// isMutatingRequest reports whether a request could change state at the
+// bank: a write to a path naming a transfer, payment or signature.
+func isMutatingRequest(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	path = strings.ToLower(path)
+	for _, term := range MutatingPathTerms {
+		if strings.Contains(path, term) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Scraper) stopHijacker() {
 	if s.router != nil {
 		_ = s.router.Stop()
@@ -348,26 +743,89 @@ func (s *Scraper) stopHijacker() {
 	}
 }
 
+// stopCapture stops collecting the authenticated page's JS errors and
+// requests.
+func (s *Scraper) stopCapture() {
+	s.console.Stop()
+	s.network.Stop()
+	s.console, s.network = nil, nil
+}
+
+// reportClock reports the portal's clock, as of its latest response, to
+// ctx, warning when the local clock is off by more than maxClockSkew.
+func reportClock(ctx context.Context, operation string, c *browser.ServerClock) {
+	server, local, ok := c.Reading()
+	if !ok {
+		return
+	}
+	skew := bank.NewClockSkew(bank.BankBBVA, server, local)
+	bank.ReportClockSkew(ctx, skew)
+	if d := skew.Skew(); d > maxClockSkew || d < -maxClockSkew {
+		bank.Warn(ctx, bank.Warning{Code: bank.BankBBVA, Operation: operation,
+			Message: fmt.Sprintf("local clock is %s off the portal's; date windows may shift", d.Round(time.Second))})
+	}
+}
+
+// reportNetwork records the requests that finished during operation in
+// ctx's request log.
+func reportNetwork(ctx context.Context, operation string, l *browser.NetworkLog) {
+	entries, dropped := l.Drain()
+	if dropped > 0 {
+		bank.Warn(ctx, bank.Warning{Code: bank.BankBBVA, Operation: operation,
+			Message: fmt.Sprintf("network log: %d more requests not recorded", dropped)})
+	}
+	reqs := make([]bank.Request, len(entries))
+	for i, e := range entries {
+		reqs[i] = bank.Request{
+			Operation: operation,
+			Method:    e.Method,
+			URL:       e.URL,
+			Status:    e.Status,
+			Error:     e.Error,
+			StartedAt: e.StartedAt,
+			Duration:  e.Duration,
+		}
+	}
+	bank.LogRequests(ctx, reqs...)
+}
+
+// reportConsole records the JS errors and exceptions the page logged during
+// operation as warnings: a portal script failing is often why a selector
+// never appears.
+func (s *Scraper) reportConsole(ctx context.Context, op *debug.OpLogger, operation, accountID string, l *browser.ConsoleLog) {
+	entries, dropped := l.Drain()
+	msgs := make([]string, 0, len(entries)+1)
+	for _, e := range entries {
+		msgs = append(msgs, "browser "+e.String())
+	}
+	if dropped > 0 {
+		msgs = append(msgs, fmt.Sprintf("browser: %d more console errors not recorded", dropped))
+	}
+	for _, msg := range msgs {
+		op.Warn("browser console", slog.String("entry", msg))
+		bank.Warn(ctx, bank.Warning{Code: bank.BankBBVA, Operation: operation, AccountID: accountID, Message: msg})
+	}
+}
+
 // Logout performs a clean logout from the BBVA portal by clicking the
 // sidebar "Salir" button, confirming the logout modal, and waiting for
 // the page to redirect away from the portal. After a successful logout,
 // the session and page are cleared; subsequent GetBalance/GetTransactions
 // calls will return ErrSessionExpired.
 func (s *Scraper) Logout(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	op := debug.StartOp(s.logger, "Logout")
 
-	if s.page == nil {
-		return &bank.ScraperError{
-			Code:      bank.BankBBVA,
-			Operation: "Logout",
-			Cause:     bank.ErrSessionExpired,
-			Details:   "no active session — call Login first",
-		}
+	active, err := s.activePage("Logout")
+	if err != nil {
+		return err
 	}
 
 	logoutCtx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
-	page := s.page.Context(logoutCtx)
+	page := active.Context(logoutCtx)
 
 	// Step 0: Dismiss the announcement modal if present
 	dismissAnnouncementModal(logoutCtx, page)
@@ -452,11 +910,11 @@ func (s *Scraper) Logout(ctx context.Context) error {
 	// The DELETE completes in <1s; give the Cells redirect 3s to fire.
 	redirectCtx, redirectCancel := context.WithTimeout(logoutCtx, 3*time.Second)
 	defer redirectCancel()
-	if !waitForLogoutRedirect(redirectCtx, s.page) {
+	if !waitForLogoutRedirect(redirectCtx, active) {
 		// Headless Chrome doesn't complete the Cells redirect — navigate ourselves.
 		// The server-side session is already invalidated by the DELETE above.
 		op.Info("completing redirect to login page")
-		if navErr := s.page.Navigate(loginURL); navErr != nil {
+		if navErr := active.Navigate(loginURL); navErr != nil {
 			op.Error("navigate to login page failed", navErr)
 			return &bank.ScraperError{
 				Code:      bank.BankBBVA,
@@ -469,11 +927,7 @@ func (s *Scraper) Logout(ctx context.Context) error {
 	op.Info("redirect complete")
 
 	// Step 5: Clean up — stop hijacker, close page, clear session
-	s.stopHijacker()
-	_ = s.page.Close()
-	s.page = nil
-	s.session = nil
-	s.debug = nil
+	s.releasePage()
 
 	op.Success()
 	return nil
@@ -501,7 +955,7 @@ func waitForLogoutRedirect(ctx context.Context, page *rod.Page) bool {
 	defer ticker.Stop()
 	for {
 		info, err := page.Info()
-		if err == nil && !strings.Contains(info.URL, PortalPath) {
+		if err == nil && !isPortalURL(info.URL) {
 			return true
 		}
 		select {
@@ -513,23 +967,28 @@ func waitForLogoutRedirect(ctx context.Context, page *rod.Page) bool {
 }
 
 // GetBalance fetches balances for all accounts.
-func (s *Scraper) GetBalance(ctx context.Context) ([]bank.Balance, error) {
+func (s *Scraper) GetBalance(ctx context.Context) (_ []bank.Balance, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	op := debug.StartOp(s.logger, "GetBalance")
 
-	if s.page == nil {
-		return nil, &bank.ScraperError{
-			Code:      bank.BankBBVA,
-			Operation: "GetBalance",
-			Cause:     bank.ErrSessionExpired,
-			Details:   "no active session — call Login first",
-		}
+	page, err := s.activePage("GetBalance")
+	if err != nil {
+		return nil, err
 	}
 
+	rec := s.startScreencast(s.debug, page, "GetBalance")
+	defer func() { rec.Stop(err != nil) }()
+	defer s.reportConsole(ctx, op, "GetBalance", "", s.console)
+	defer reportNetwork(ctx, "GetBalance", s.network)
+	defer reportClock(ctx, "GetBalance", s.clock)
+
 	// Navigate to accounts page with retry (SPA intermittently fails to render).
-	if err := navigateToAccountsPage(ctx, s.page, accountsNavStepTimeout, s.logger); err != nil {
+	if err := navigateToAccountsPage(ctx, page, accountsNavStepTimeout, s.timings, s.logger); err != nil {
 		debugCtx, debugCancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer debugCancel()
-		dp := s.page.Context(debugCtx)
+		dp := page.Context(debugCtx)
 
 		pageURL, dir := s.debug.Snapshot(dp, "GetBalance", "accounts-timeout")
 		diagJSON := s.debug.RunAccountsDiagnostic(dp, "GetBalance", "accounts-timeout-diag", browser.DeepQueryJS, debug.AccountDiagSelectors{
@@ -550,10 +1009,10 @@ func (s *Scraper) GetBalance(ctx context.Context) ([]bank.Balance, error) {
 	// Flatten + parse phase
 	flattenCtx, flattenCancel := context.WithTimeout(ctx, s.timeout)
 	defer flattenCancel()
-	html, _, _, err := browser.FlattenShadowDOM(s.page.Context(flattenCtx))
+	html, _, _, err := browser.FlattenShadowDOM(page.Context(flattenCtx))
 	if err != nil {
 		op.Error("flatten shadow DOM failed", err)
-		s.debug.Screenshot(s.page, "GetBalance", "flatten-error")
+		s.debug.Screenshot(page, "GetBalance", "flatten-error")
 		return nil, &bank.ScraperError{
 			Code:      bank.BankBBVA,
 			Operation: "GetBalance",
@@ -562,7 +1021,8 @@ func (s *Scraper) GetBalance(ctx context.Context) ([]bank.Balance, error) {
 		}
 	}
 
-	balances, err := ParseAccountBalances(html)
+	bank.RecordHTML(ctx, "GetBalance", "", html)
+	balances, warnings, err := ParseAccountBalancesWithWarnings(html)
 	if err != nil {
 		s.debug.HTMLString(html, "GetBalance", "parse-error")
 		op.Error("parse account balances failed", err, slog.String("debug_dir", s.debug.Dir()))
@@ -574,12 +1034,27 @@ func (s *Scraper) GetBalance(ctx context.Context) ([]bank.Balance, error) {
 		}
 	}
 
+	for _, w := range warnings {
+		s.warn(ctx, op, "GetBalance", "", w)
+	}
+	s.audit.Capture(page, "accounts")
+
 	op.Success(slog.Int("account_count", len(balances)))
 	return balances, nil
 }
 
+// warn logs a parse anomaly and records it with the caller's warning
+// collector (see bank.WithWarnings).
+func (s *Scraper) warn(ctx context.Context, op *debug.OpLogger, operation, accountID, msg string) {
+	op.Warn("parse anomaly", slog.String("warning", msg))
+	bank.Warn(ctx, bank.Warning{Code: bank.BankBBVA, Operation: operation, AccountID: accountID, Message: msg})
+}
+
 // GetTransactions fetches transactions for the given account.
-func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count int) ([]bank.Transaction, error) {
+func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count int) (_ []bank.Transaction, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	op := debug.StartOp(s.logger, "GetTransactions", slog.String("account_id", accountID))
 
 	if count < minTransactionCount {
@@ -589,23 +1064,25 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 		count = maxTransactionCount
 	}
 
-	if s.page == nil {
-		return nil, &bank.ScraperError{
-			Code:      bank.BankBBVA,
-			Operation: "GetTransactions",
-			Cause:     bank.ErrSessionExpired,
-			Details:   "no active session — call Login first",
-		}
+	page, err := s.activePage("GetTransactions")
+	if err != nil {
+		return nil, err
 	}
 
+	rec := s.startScreencast(s.debug, page, "GetTransactions")
+	defer func() { rec.Stop(err != nil) }()
+	defer s.reportConsole(ctx, op, "GetTransactions", accountID, s.console)
+	defer reportNetwork(ctx, "GetTransactions", s.network)
+	defer reportClock(ctx, "GetTransactions", s.clock)
+
 	// Navigation phase — mimic real user flow:
 	// Direct URL navigation leaves the SPA's selectedAccount store empty → "undefined".
 	// Instead: accounts page → click "Ir al detalle de cuenta" on the target card.
 	// Each step gets its own context to avoid deadline exhaustion across steps.
 
 	// Step 1: Navigate to accounts page with retry (SPA intermittently fails to render)
-	if err := navigateToAccountsPage(ctx, s.page, accountsNavStepTimeout, s.logger); err != nil {
-		pageURL, dir := s.debug.Snapshot(s.page, "GetTransactions", "accounts-timeout")
+	if err := navigateToAccountsPage(ctx, page, accountsNavStepTimeout, s.timings, s.logger); err != nil {
+		pageURL, dir := s.debug.Snapshot(page, "GetTransactions", "accounts-timeout")
 		op.Error("accounts page not reachable after retries", bank.ErrUnknown,
 			slog.String("url", pageURL), slog.String("debug_dir", dir))
 		return nil, &bank.ScraperError{
@@ -620,8 +1097,8 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 	// Each card has a footer link that navigates directly to the account detail page,
 	// independent of the SPA's selectedAccount state. This avoids the bug where
 	// "Ver todos los movimientos" redirects based on stale SPA state.
-	if !waitAndClickAccountDetail(ctx, s.page, accountID, s.timeout) {
-		s.debug.Screenshot(s.page, "GetTransactions", "account-not-found")
+	if !waitAndClickAccountDetail(ctx, page, accountID, s.timeout) {
+		s.debug.Screenshot(page, "GetTransactions", "account-not-found")
 		op.Error("account card not found", bank.ErrAccountNotFound,
 			slog.String("account_id", accountID))
 		return nil, &bank.ScraperError{
@@ -633,11 +1110,12 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 	}
 
 	// Step 4: Wait for SPA hash navigation to account detail page
+	openedAt := time.Now()
 	stableCtx, stableCancel := context.WithTimeout(ctx, s.timeout)
-	err := s.page.Context(stableCtx).WaitDOMStable(time.Second, 0)
+	err = page.Context(stableCtx).WaitDOMStable(time.Second, 0)
 	stableCancel()
 	if err != nil {
-		s.debug.Screenshot(s.page, "GetTransactions", "dom-unstable")
+		s.debug.Screenshot(page, "GetTransactions", "dom-unstable")
 		op.Error("DOM unstable after account detail click", err)
 		return nil, &bank.ScraperError{
 			Code:      bank.BankBBVA,
@@ -648,8 +1126,8 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 	}
 
 	// Wait for Web Components to finish rendering transaction rows.
-	if !waitForTransactionsReady(ctx, s.page, s.timeout) {
-		pageURL, dir := s.debug.Snapshot(s.page, "GetTransactions", "table-timeout")
+	if !waitForTransactionsReady(ctx, page, s.timeout) {
+		pageURL, dir := s.debug.Snapshot(page, "GetTransactions", "table-timeout")
 		op.Error("timed out waiting for transactions table", bank.ErrUnknown,
 			slog.String("url", pageURL), slog.String("debug_dir", dir))
 		return nil, &bank.ScraperError{
@@ -659,20 +1137,89 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 			Details:   fmt.Sprintf("timed out waiting for transactions table to render (url=%s, debug=%s)", pageURL, dir),
 		}
 	}
+	s.timings.Observe(bank.BankBBVA, bank.StepTransactionsRender, time.Since(openedAt))
 
 	// Pagination loop, then extract
 	loopCtx, loopCancel := context.WithTimeout(ctx, s.timeout)
 	defer loopCancel()
-	page := s.page.Context(loopCtx)
-	var allTxns []bank.Transaction
+	if err := loadTransactionRows(loopCtx, page, count, s.timings, op); err != nil {
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetTransactions",
+			Cause:     bank.ErrUnknown,
+			Details:   err.Error(),
+		}
+	}
+	loopCancel()
+
+	// Extract the transactions table HTML via deepQuery — clones the subtree
+	// and flattens shadow DOM on the clone, leaving the live DOM intact so the
+	// SPA framework can still navigate to other routes afterward.
+	extractCtx, extractCancel := context.WithTimeout(ctx, s.timeout)
+	defer extractCancel()
+	html, err := browser.DeepQueryOuterHTML(page.Context(extractCtx), SelectorTransactionsTable)
+	if err != nil {
+		s.debug.Screenshot(page, "GetTransactions", "extract-error")
+		op.Error("extract transactions table HTML failed", err)
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetTransactions",
+			Cause:     bank.ErrUnknown,
+			Details:   fmt.Sprintf("extract transactions table HTML: %v", err),
+		}
+	}
+	if html == "" {
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetTransactions",
+			Cause:     bank.ErrParsingFailed,
+			Details:   "transactions table not found via deepQuery",
+		}
+	}
+
+	bank.RecordHTML(ctx, "GetTransactions", accountID, html)
+	allTxns, err := ParseTransactions(html)
+	if err != nil {
+		s.debug.HTMLString(html, "GetTransactions", "parse-error")
+		op.Error("parse transactions failed", err, slog.String("debug_dir", s.debug.Dir()))
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: "GetTransactions",
+			Cause:     err,
+			Details:   fmt.Sprintf("parse transactions failed (debug HTML dumped to %s)", s.debug.Dir()),
+		}
+	}
+
+	if s.describe != nil {
+		for i := range allTxns {
+			allTxns[i].CleanDescription = s.describe.Normalize(allTxns[i].Description)
+		}
+	}
+
+	if s.detailWorkers > 0 {
+		s.fetchTransactionDetails(ctx, accountID, allTxns, op)
+	}
+	// Details are matched to rows by page position, so sort only afterwards.
+	bank.SortTransactions(allTxns)
+	s.audit.Capture(page, "transactions-"+bank.MaskAccountID(accountID))
+
+	op.Success(slog.Int("transaction_count", len(allTxns)))
+	return allTxns, nil
+}
+
+// --- PRIVATE DOMAIN LOGIC ---
+
+// loadTransactionRows clicks "Ver más" until at least count rows are rendered,
+// the button disappears, or a click loads nothing new. ctx bounds the whole
+// loop; cancellation is reported as an error.
+//
+// Each click's new rows are polled for about as long as t has seen them take,
+// falling back to 10 checks 500ms apart.
+func loadTransactionRows(ctx context.Context, p *rod.Page, count int, t *bank.Timings, op *debug.OpLogger) error {
+	page := p.Context(ctx)
 	for i := 0; i < maxPaginationClicks; i++ {
-		if loopCtx.Err() != nil {
-			return nil, &bank.ScraperError{
-				Code:      bank.BankBBVA,
-				Operation: "GetTransactions",
-				Cause:     bank.ErrUnknown,
-				Details:   "context cancelled during pagination",
-			}
+		if ctx.Err() != nil {
+			return fmt.Errorf("context cancelled during pagination")
 		}
 
 		// Lightweight check - count row elements without flattening
@@ -681,6 +1228,7 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 			slog.Int("iteration", i),
 			slog.Int("rowCount", rowCount),
 			slog.Int("target", count))
+		bank.ReportProgress(ctx, bank.PageProgress{Operation: "GetTransactions", Pages: i + 1, Rows: rowCount, Target: count})
 		if rowCount >= count {
 			op.Info("pagination: target reached, stopping")
 			break
@@ -697,6 +1245,7 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 		// shadow root. Clicking the outer custom element does nothing — we must
 		// deepQuery into its shadow tree for the real button, same pattern as
 		// dismissAnnouncementModal.
+		clickedAt := time.Now()
 		clicked, _ := page.Eval(fmt.Sprintf(`() => {
 			%s
 			const footer = deepQuery(document, '%s');
@@ -720,9 +1269,12 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 
 		// Poll for row count to increase — confirms new rows actually loaded
 		rowsLoaded := false
-		for j := 0; j < 10; j++ {
+		budget := t.Budget(bank.BankBBVA, bank.StepPagination, 2*time.Second, 5*time.Second)
+		interval := t.Poll(bank.BankBBVA, bank.StepPagination, 100*time.Millisecond, 500*time.Millisecond)
+		for deadline := clickedAt.Add(budget); ; {
 			newCount := browser.DeepQueryCountAll(page, SelectorTransactionRow)
 			if newCount > prevCount {
+				t.Observe(bank.BankBBVA, bank.StepPagination, time.Since(clickedAt))
 				op.Info("pagination: new rows loaded",
 					slog.Int("iteration", i),
 					slog.Int("prevCount", prevCount),
@@ -730,7 +1282,10 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 				rowsLoaded = true
 				break
 			}
-			time.Sleep(500 * time.Millisecond)
+			if time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(interval)
 		}
 		if !rowsLoaded {
 			op.Info("pagination: no new rows after click, stopping",
@@ -739,51 +1294,9 @@ func (s *Scraper) GetTransactions(ctx context.Context, accountID string, count i
 			break
 		}
 	}
-	loopCancel()
-
-	// Extract the transactions table HTML via deepQuery — clones the subtree
-	// and flattens shadow DOM on the clone, leaving the live DOM intact so the
-	// SPA framework can still navigate to other routes afterward.
-	extractCtx, extractCancel := context.WithTimeout(ctx, s.timeout)
-	defer extractCancel()
-	html, err := browser.DeepQueryOuterHTML(s.page.Context(extractCtx), SelectorTransactionsTable)
-	if err != nil {
-		s.debug.Screenshot(s.page, "GetTransactions", "extract-error")
-		op.Error("extract transactions table HTML failed", err)
-		return nil, &bank.ScraperError{
-			Code:      bank.BankBBVA,
-			Operation: "GetTransactions",
-			Cause:     bank.ErrUnknown,
-			Details:   fmt.Sprintf("extract transactions table HTML: %v", err),
-		}
-	}
-	if html == "" {
-		return nil, &bank.ScraperError{
-			Code:      bank.BankBBVA,
-			Operation: "GetTransactions",
-			Cause:     bank.ErrParsingFailed,
-			Details:   "transactions table not found via deepQuery",
-		}
-	}
-
-	allTxns, err = ParseTransactions(html)
-	if err != nil {
-		s.debug.HTMLString(html, "GetTransactions", "parse-error")
-		op.Error("parse transactions failed", err, slog.String("debug_dir", s.debug.Dir()))
-		return nil, &bank.ScraperError{
-			Code:      bank.BankBBVA,
-			Operation: "GetTransactions",
-			Cause:     err,
-			Details:   fmt.Sprintf("parse transactions failed (debug HTML dumped to %s)", s.debug.Dir()),
-		}
-	}
-
-	op.Success(slog.Int("transaction_count", len(allTxns)))
-	return allTxns, nil
+	return nil
 }
 
-// --- PRIVATE DOMAIN LOGIC ---
-
 type loginOutcome int
 
 const (
@@ -801,11 +1314,20 @@ type loginResult struct {
 // - URL changes to PortalPath → success (Senda redirected to portal)
 // - span#error-message becomes visible with text → failure
 //
-// In replay mode (s.hijacker != nil), uses a direct Senda API probe
-// to bypass the broken iframe postMessage chain.
-func (s *Scraper) waitForLoginOutcome(ctx context.Context, page *rod.Page) loginResult {
-	// In replay mode, the iframe postMessage chain is broken — use direct API probe
+// After the legacy form (FlagLegacyLogin), DFServlet's error page is a
+// failure too.
+//
+// In replay mode (s.hijacker != nil), the outcome is the grantingTicket
+// responses the hijacked Senda iframe got, falling back to a direct Senda
+// API probe when the iframe couldn't be hijacked or never called the API.
+func (s *Scraper) waitForLoginOutcome(ctx context.Context, page *rod.Page, senda *sendaReplay, legacy bool) loginResult {
 	if s.hijacker != nil {
+		if senda != nil {
+			if result, ok := senda.wait(ctx, s.timeout); ok {
+				return result
+			}
+			s.logger.Info("senda iframe made no grantingTicket calls, probing the API instead")
+		}
 		return s.probeSendaAPI(ctx, page)
 	}
 
@@ -818,7 +1340,7 @@ func (s *Scraper) waitForLoginOutcome(ctx context.Context, page *rod.Page) login
 	for {
 		// Success: URL changed to portal
 		info, err := p.Info()
-		if err == nil && strings.Contains(info.URL, PortalPath) {
+		if err == nil && isPortalURL(info.URL) {
 			return loginResult{outcome: loginSuccess}
 		}
 
@@ -834,6 +1356,17 @@ func (s *Scraper) waitForLoginOutcome(ctx context.Context, page *rod.Page) login
 			}
 		}
 
+		// Error: the legacy form posts to DFServlet, which renders an error
+		// page instead
+		if legacy {
+			if html, err := p.HTML(); err == nil {
+				var le *LoginErrorInfo
+				if errors.As(DetectLoginError(html, 0), &le) {
+					return loginResult{outcome: loginError, errorText: strings.TrimSpace(le.Code + " " + le.Message)}
+				}
+			}
+		}
+
 		select {
 		case <-waitCtx.Done():
 			return loginResult{outcome: loginTimeout}
@@ -856,7 +1389,7 @@ func (s *Scraper) probeSendaAPI(ctx context.Context, _ *rod.Page) loginResult {
 	probeCtx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	probePage, err := s.browser.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	probePage, err := s.newPage("about:blank")
 	if err != nil {
 		return loginResult{outcome: loginTimeout}
 	}
@@ -912,44 +1445,6 @@ func classifyProbeResponse(resp probeResponse) loginResult {
 	}
 }
 
-// classifySendaError maps Senda error message text to typed errors.
-// Handles both UI text (from span#error-message) and API probe format
-// ("senda API error-code NNN" or "senda API STATUS: ...").
-func classifySendaError(errorText string) error {
-	// Probe format: "senda API error-code 160"
-	if strings.HasPrefix(errorText, "senda API error-code ") {
-		code := strings.TrimPrefix(errorText, "senda API error-code ")
-		return classifySendaErrorCode(code)
-	}
-	// Probe format: "senda API 403: ..." or "senda API probe eval: ..."
-	if strings.HasPrefix(errorText, "senda API ") {
-		return bank.ErrUnknown
-	}
-	// UI text matching
-	switch {
-	case strings.Contains(errorText, "corrijas los datos"):
-		return bank.ErrInvalidCredentials
-	case strings.Contains(errorText, "bloqueado"):
-		return bank.ErrInvalidCredentials
-	case strings.Contains(errorText, "límite de intentos"):
-		return bank.ErrInvalidCredentials
-	case strings.Contains(errorText, "no disponible"):
-		return bank.ErrBankUnavailable
-	default:
-		return bank.ErrUnknown
-	}
-}
-
-// classifySendaErrorCode maps Senda API error codes to typed errors.
-func classifySendaErrorCode(code string) error {
-	switch code {
-	case "160", "162":
-		return bank.ErrInvalidCredentials
-	default:
-		return bank.ErrUnknown
-	}
-}
-
 // waitForDashboard polls the page URL for the dashboard route hash.
 // The 2026 portal SPA sets this after the "Validando tus credenciales"
 // splash transitions to the dashboard.
@@ -1001,9 +1496,13 @@ func navigateTo(ctx context.Context, page *rod.Page, url string) error {
 // Components to render account data. Retries up to maxAccountsNavAttempts
 // times because the SPA framework intermittently fails to render route content.
 // Each retry triggers a fresh page load via cache-busted URL.
-func navigateToAccountsPage(ctx context.Context, page *rod.Page, timeout time.Duration, logger *slog.Logger) error {
+//
+// The wait for the render is sized by t from earlier renders, so a render
+// that failed is retried sooner than timeout on a portal known to be quick.
+func navigateToAccountsPage(ctx context.Context, page *rod.Page, timeout time.Duration, t *bank.Timings, logger *slog.Logger) error {
 	var lastErr error
 	for attempt := 1; attempt <= maxAccountsNavAttempts; attempt++ {
+		started := time.Now()
 		navCtx, navCancel := context.WithTimeout(ctx, timeout)
 		err := navigateTo(navCtx, page, accountsURL)
 		navCancel()
@@ -1018,7 +1517,9 @@ func navigateToAccountsPage(ctx context.Context, page *rod.Page, timeout time.Du
 			continue
 		}
 
-		if waitForAccountsReady(ctx, page, timeout) {
+		wait := t.Budget(bank.BankBBVA, bank.StepAccountsRender, minAccountsRenderWait, timeout)
+		if waitForAccountsReady(ctx, page, wait) {
+			t.Observe(bank.BankBBVA, bank.StepAccountsRender, time.Since(started))
 			if attempt > 1 {
 				logger.Info("accounts page loaded on retry",
 					slog.Int("attempt", attempt))
@@ -1026,7 +1527,7 @@ func navigateToAccountsPage(ctx context.Context, page *rod.Page, timeout time.Du
 			return nil
 		}
 
-		lastErr = fmt.Errorf("attempt %d: accounts did not render within %s", attempt, timeout)
+		lastErr = fmt.Errorf("attempt %d: accounts did not render within %s", attempt, wait)
 		if attempt < maxAccountsNavAttempts {
 			logger.Warn("accounts page did not render, retrying",
 				slog.Int("attempt", attempt),
@@ -1200,7 +1701,7 @@ func generateSessionID() string {
 	return fmt.Sprintf("bbva-%d", time.Now().UnixNano())
 }
 
-// debugDir returns the base directory for debug artifacts.
-func debugDir() string {
+// DebugDir returns the base directory for debug artifacts.
+func DebugDir() string {
 	return filepath.Join(os.TempDir(), debugBaseDir)
 }
@@ -2,124 +2,110 @@ package bbva
 
 import (
 	"context"
-	"os"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
-	"github.com/aynifx/bank-scraper/internal/scraper/testutil"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// TestMode
-type TestMode string
-
-const (
-	TestModeMock   TestMode = "mock"   // Use static fixtures
-	TestModeReplay TestMode = "replay" // Replay recorded sessions
-	TestModeLive   TestMode = "live"   // Hit real bank (dangerous!)
-)
-
-func getTestMode() TestMode {
-	mode := os.Getenv("SCRAPER_TEST_MODE")
-	if mode == "" {
-		return TestModeMock
+// skipUnlessMode skips test if not in specified mode
+func skipUnlessMode(t *testing.T, required banktest.TestMode) {
+	if banktest.Mode() != required {
+		t.Skipf("Skipping: requires %s=%s", banktest.TestModeEnv, required)
 	}
-	return TestMode(mode)
 }
 
-// skipUnlessMode skips test if not in specified mode
-func skipUnlessMode(t *testing.T, required TestMode) {
-	if getTestMode() != required {
-		t.Skipf("Skipping: requires SCRAPER_TEST_MODE=%s", required)
-	}
+// liveCredentials maps BBVA credential fields to the env vars live tests
+// read them from.
+var liveCredentials = map[string]string{
+	fieldCompanyCode: "BBVA_COMPANY_CODE",
+	fieldUserCode:    "BBVA_USER_CODE",
+	fieldPassword:    "BBVA_PASSWORD",
+}
+
+// replayCredentials are placeholders: replayed responses don't check them.
+var replayCredentials = map[string]string{
+	fieldCompanyCode: "test-company",
+	fieldUserCode:    "test-user",
+	fieldPassword:    "test-password",
 }
 
-// requireLiveCreds reads BBVA credentials from environment variables.
-// Skips the test if any are missing.
-func requireLiveCreds(t *testing.T) map[string]string {
+// newTestScraper creates a scraper for the current test mode (see
+// banktest.NewScraper) and returns it with the credentials to log in with.
+// recording names the scenario under testdata/recordings that replay mode
+// serves (see testutil.Manifest), empty for live-only tests; live reports whether the test may run against
+// the real bank. The scraper is closed when the test ends.
+func newTestScraper(t *testing.T, recording string, live bool, opts ...Option) (*Scraper, map[string]string) {
 	t.Helper()
-	company := os.Getenv("BBVA_COMPANY_CODE")
-	user := os.Getenv("BBVA_USER_CODE")
-	pass := os.Getenv("BBVA_PASSWORD")
-	if company == "" || user == "" || pass == "" {
-		t.Skip("Skipping: requires BBVA_COMPANY_CODE, BBVA_USER_CODE, BBVA_PASSWORD env vars")
+	setup := banktest.ScraperSetup[*Scraper]{
+		Build: func(o banktest.ScraperOptions) (*Scraper, error) {
+			if o.Hijacker != nil {
+				opts = append(opts, WithHijacker(o.Hijacker))
+			}
+			if len(o.Scripts) > 0 {
+				opts = append(opts, WithInjectedScripts(o.Scripts...))
+			}
+			return NewScraper(append(opts, WithReadOnly(o.ReadOnly))...)
+		},
+		Recordings:        filepath.Join("testdata", "recordings"),
+		Scenario:          recording,
+		ReplayCredentials: replayCredentials,
 	}
-	return map[string]string{
-		"company_code": company,
-		"user_code":    user,
-		"password":     pass,
+	if live {
+		setup.LiveCredentials = liveCredentials
 	}
+	return banktest.NewScraper(t, setup)
 }
 
 // Integration test - runs only in replay/live mode
 func TestScraper_Login_ReplaySuccess_Integration(t *testing.T) {
-	skipUnlessMode(t, TestModeReplay)
+	scraper, creds := newTestScraper(t, "login-success", false, WithTimeout(5*time.Second))
 
-	// Load recorded session
-	harPath := filepath.Join("testdata", "recordings", "login-success.har.json")
-	if _, err := os.Stat(harPath); os.IsNotExist(err) {
-		t.Skipf("Recording not found: %s\n", harPath)
-	}
-
-	har := testutil.MustLoadHAR(t, harPath)
-	replayer := testutil.NewReplayer(har, testutil.WithVerbose(true))
-
-	t.Logf("Loaded HAR with %d entries", len(har.Entries))
-	stats := replayer.Stats()
-	t.Logf("Replayer stats: exact=%d, path=%d", stats["exact_matches"], stats["path_matches"])
-
-	// Create scraper with replay hijacker
-	scraper, err := NewScraper(
-		WithHijacker(replayer.Middleware()),
-		WithTimeout(5*time.Second),
-	)
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
-
-	// Test login (credentials don't matter in replay mode)
 	ctx := context.Background()
-	session, err := scraper.Login(ctx, map[string]string{
-		"company_code": "test-company",
-		"user_code":    "test-user",
-		"password":     "test-password",
-	})
+	session, err := scraper.Login(ctx, creds)
 
 	require.NoError(t, err, "Login should succeed with recorded session")
 	assert.NotEmpty(t, session.ID, "Session ID should be set")
 	assert.Equal(t, bank.BankBBVA, session.Code, "Code should be BBVA")
-	assert.False(t, session.ExpiresAt.IsZero(), "Session expiry should be set")
+	assert.True(t, session.Valid(), "Session should be valid right after login")
 
 	// Page lifecycle: page and session should persist after successful login
 	assert.NotNil(t, scraper.page, "Page should be kept alive after successful login")
 	assert.NotNil(t, scraper.session, "Session should be stored on scraper")
-	assert.WithinDuration(t, time.Now().Add(bbvaSessionTimeout), session.ExpiresAt, 5*time.Second,
+	assert.Equal(t, defaultSessionIdle, session.IdleTimeout, "Session should idle out like the portal")
+	assert.WithinDuration(t, time.Now().Add(defaultSessionIdle), session.Expiry(), 5*time.Second,
 		"Session expiry should be ~10 minutes from now")
 }
 
-func TestScraper_Login_ReplayError403BotDetection_Integration(t *testing.T) {
-	t.Skip("TODO: re-record with #enviarSenda for Senda-based bot detection")
-
-	skipUnlessMode(t, TestModeReplay)
-
-	// Load recorded error session
-	harPath := filepath.Join("testdata", "recordings", "login-bot-detection.har.json")
-	if _, err := os.Stat(harPath); os.IsNotExist(err) {
-		t.Skipf("Recording not found: %s\n", harPath)
+func TestScraper_Login_ReplayHuman_Integration(t *testing.T) {
+	// Two logins with the same seed type and pause alike.
+	var runs [2][]time.Duration
+	for i := range runs {
+		human := browser.NewHuman(42)
+		human.Sleep = func(d time.Duration) { runs[i] = append(runs[i], d) }
+		scraper, creds := newTestScraper(t, "login-success", false, WithTimeout(5*time.Second), WithHuman(human))
+		_, err := scraper.Login(context.Background(), creds)
+		require.NoError(t, err)
 	}
 
-	har := testutil.MustLoadHAR(t, harPath)
-	replayer := testutil.NewReplayer(har)
+	keystrokes := len(replayCredentials[fieldCompanyCode]) + len(replayCredentials[fieldUserCode]) + len(replayCredentials[fieldPassword])
+	assert.Len(t, runs[0], keystrokes+1, "a pause per keystroke, then one before the click")
+	assert.Equal(t, runs[0], runs[1])
+}
 
-	// Create scraper with replay hijacker
-	scraper, err := NewScraper(
-		WithHijacker(replayer.Middleware()),
-		WithTimeout(10*time.Second),
-	)
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
+func TestScraper_Login_ReplayError403BotDetection_Integration(t *testing.T) {
+	t.Skip("TODO: re-record with #enviarSenda for Senda-based bot detection")
+
+	scraper, _ := newTestScraper(t, "login-bot-detection", false, WithTimeout(10*time.Second))
 
 	// Test login with (simulated) invalid credentials
 	ctx := context.Background()
@@ -146,24 +132,7 @@ func TestScraper_Login_ReplayError403BotDetection_Integration(t *testing.T) {
 }
 
 func TestScraper_Login_ReplayErrorInvalidCredentials_Integration(t *testing.T) {
-	skipUnlessMode(t, TestModeReplay)
-
-	// Load recorded error session (Senda flow: iframe gets 403 → LOGIN_ERROR → span shows error)
-	harPath := filepath.Join("testdata", "recordings", "login-invalid-credentials-legacy.har.json")
-	if _, err := os.Stat(harPath); os.IsNotExist(err) {
-		t.Skipf("Recording not found: %s\n", harPath)
-	}
-
-	har := testutil.MustLoadHAR(t, harPath)
-	replayer := testutil.NewReplayer(har)
-
-	// Create scraper with replay hijacker
-	scraper, err := NewScraper(
-		WithHijacker(replayer.Middleware()),
-		WithTimeout(10*time.Second),
-	)
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
+	scraper, _ := newTestScraper(t, "login-invalid-credentials-legacy", false, WithTimeout(10*time.Second))
 
 	ctx := context.Background()
 	session, err := scraper.Login(ctx, map[string]string{
@@ -192,29 +161,9 @@ func TestScraper_Login_ReplayErrorInvalidCredentials_Integration(t *testing.T) {
 }
 
 func TestScraper_Login_ReplayRelogin_Integration(t *testing.T) {
-	skipUnlessMode(t, TestModeReplay)
-
-	harPath := filepath.Join("testdata", "recordings", "login-success.har.json")
-	if _, err := os.Stat(harPath); os.IsNotExist(err) {
-		t.Skipf("Recording not found: %s\n", harPath)
-	}
-
-	har := testutil.MustLoadHAR(t, harPath)
-	replayer := testutil.NewReplayer(har)
-
-	scraper, err := NewScraper(
-		WithHijacker(replayer.Middleware()),
-		WithTimeout(5*time.Second),
-	)
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
+	scraper, creds := newTestScraper(t, "login-success", false, WithTimeout(5*time.Second))
 
 	ctx := context.Background()
-	creds := map[string]string{
-		"company_code": "test-company",
-		"user_code":    "test-user",
-		"password":     "test-password",
-	}
 
 	// First login
 	session1, err := scraper.Login(ctx, creds)
@@ -236,32 +185,12 @@ func TestScraper_GetBalance_Replay_Integration(t *testing.T) {
 		"Requires re-architecting replay to inject Cells session state or using a " +
 		"direct API probe approach for GetBalance.")
 
-	skipUnlessMode(t, TestModeReplay)
-
-	// HAR must contain login + accounts page navigation traffic
-	harPath := filepath.Join("testdata", "recordings", "get-balance.har.json")
-	if _, err := os.Stat(harPath); os.IsNotExist(err) {
-		t.Skipf("Recording not found: %s\n", harPath)
-	}
-
-	har := testutil.MustLoadHAR(t, harPath)
-	replayer := testutil.NewReplayer(har)
-
-	scraper, err := NewScraper(
-		WithHijacker(replayer.Middleware()),
-		WithTimeout(45*time.Second),
-	)
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
+	scraper, creds := newTestScraper(t, "get-balance", false, WithTimeout(45*time.Second))
 
 	ctx := context.Background()
 
 	// Login first — hijacker stays alive for GetBalance navigation
-	_, err = scraper.Login(ctx, map[string]string{
-		"company_code": "test-company",
-		"user_code":    "test-user",
-		"password":     "test-password",
-	})
+	_, err := scraper.Login(ctx, creds)
 	require.NoError(t, err, "Login should succeed")
 
 	// Act
@@ -289,32 +218,12 @@ func TestScraper_GetTransactions_Replay_Integration(t *testing.T) {
 		"Requires re-architecting replay to inject Cells session state or using a " +
 		"direct API probe approach for GetTransactions.")
 
-	skipUnlessMode(t, TestModeReplay)
-
-	// HAR must contain login + navigate to transactions page traffic
-	harPath := filepath.Join("testdata", "recordings", "get-transactions.har.json")
-	if _, err := os.Stat(harPath); os.IsNotExist(err) {
-		t.Skipf("Recording not found: %s\n", harPath)
-	}
-
-	har := testutil.MustLoadHAR(t, harPath)
-	replayer := testutil.NewReplayer(har)
-
-	scraper, err := NewScraper(
-		WithHijacker(replayer.Middleware()),
-		WithTimeout(45*time.Second),
-	)
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
+	scraper, creds := newTestScraper(t, "get-transactions", false, WithTimeout(45*time.Second))
 
 	ctx := context.Background()
 
 	// Login first
-	session, err := scraper.Login(ctx, map[string]string{
-		"company_code": "test-company",
-		"user_code":    "test-user",
-		"password":     "test-pass",
-	})
+	session, err := scraper.Login(ctx, creds)
 	require.NoError(t, err)
 	require.NotNil(t, session)
 
@@ -387,6 +296,42 @@ func TestScraper_GetTransactions_NoSession(t *testing.T) {
 	require.ErrorIs(t, err, bank.ErrSessionExpired)
 }
 
+func TestScraper_GetScheduledOperations_NoSession(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires browser")
+	}
+
+	scraper, err := NewScraper(WithTimeout(5 * time.Second))
+	require.NoError(t, err)
+	defer func() { _ = scraper.Close() }()
+
+	_, err = scraper.GetScheduledOperations(context.Background())
+
+	require.Error(t, err)
+	var scraperErr *bank.ScraperError
+	require.ErrorAs(t, err, &scraperErr)
+	assert.Equal(t, "GetScheduledOperations", scraperErr.Operation)
+	require.ErrorIs(t, err, bank.ErrSessionExpired)
+}
+
+func TestScraper_CapturePage_NoSession(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires browser")
+	}
+
+	scraper, err := NewScraper(WithTimeout(5 * time.Second))
+	require.NoError(t, err)
+	defer func() { _ = scraper.Close() }()
+
+	_, _, err = scraper.CapturePage(context.Background(), &bank.Session{ID: "stale"}, bank.PageAccounts)
+
+	require.Error(t, err)
+	var scraperErr *bank.ScraperError
+	require.ErrorAs(t, err, &scraperErr)
+	assert.Equal(t, "CapturePage", scraperErr.Operation)
+	require.ErrorIs(t, err, bank.ErrSessionExpired)
+}
+
 func TestClassifySendaError(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -469,15 +414,98 @@ func TestClassifySendaErrorCode(t *testing.T) {
 	}
 }
 
+func TestErrorTable_MissingLoginButton(t *testing.T) {
+	for _, selector := range []string{SelectorLoginButton, SelectorLegacyLoginButton} {
+		got := ErrorTable.Match(bank.ErrorSignature{Selector: selector})
+		assert.ErrorIs(t, got, bank.ErrBankUnavailable, selector)
+	}
+}
+
+// forwardProxy is an HTTP proxy answering every request itself, recording
+// the hosts asked for.
+type forwardProxy struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (p *forwardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.hosts = append(p.hosts, r.URL.Host)
+	p.mu.Unlock()
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write([]byte("<html><body>proxied</body></html>"))
+}
+
+func (p *forwardProxy) seen() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.hosts)
+}
+
+func TestPortalClient(t *testing.T) {
+	proxy := &forwardProxy{}
+	srv := httptest.NewServer(proxy)
+	defer srv.Close()
+
+	client, err := portalClient(srv.URL)
+	require.NoError(t, err)
+	resp, err := client.Get("http://portal.bbva.invalid/login")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, []string{"portal.bbva.invalid"}, proxy.seen())
+
+	client, err = portalClient("")
+	require.NoError(t, err)
+	assert.Same(t, http.DefaultClient, client)
+}
+
+func TestScraper_RequestHandler_Proxy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires browser")
+	}
+	proxy := &forwardProxy{}
+	srv := httptest.NewServer(proxy)
+	defer srv.Close()
+
+	scraper, err := NewScraper(WithProxy(srv.URL), WithTimeout(5*time.Second))
+	require.NoError(t, err)
+	defer func() { _ = scraper.Close() }()
+
+	page := scraper.browser.MustPage("")
+	router := page.HijackRequests()
+	router.MustAdd("*", scraper.requestHandler(nil))
+	go router.Run()
+	defer router.MustStop()
+
+	page.MustNavigate("http://portal.bbva.invalid/").MustWaitLoad()
+	assert.Contains(t, page.MustElement("body").MustText(), "proxied")
+	assert.Contains(t, proxy.seen(), "portal.bbva.invalid", "hijacked, then fetched through the proxy")
+}
+
+func TestIsMutatingRequest(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"POST", "/TechArchitecture/pe/grantingTicket/V02", false},
+		{"GET", "/nextgenempresas/portal/index.html", false},
+		{"GET", "/api/transfers/v1/list", false},
+		{"POST", "/api/transfers/v1/transfers", true},
+		{"PUT", "/api/Pagos/masivos", true},
+		{"DELETE", "/api/beneficiaries/42", true},
+		{"OPTIONS", "/api/payments", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isMutatingRequest(tt.method, tt.path), "%s %s", tt.method, tt.path)
+	}
+}
+
 // loginAndGetAccounts creates a new scraper, logs in, and fetches balances.
 // Returns the scraper (still logged in) and the list of balances.
-// The caller is responsible for closing the scraper.
+// The scraper is closed when the test ends.
 func loginAndGetAccounts(t *testing.T, ctx context.Context) (*Scraper, []bank.Balance) {
 	t.Helper()
-	creds := requireLiveCreds(t)
-
-	scraper, err := NewScraper(WithTimeout(60 * time.Second))
-	require.NoError(t, err)
+	scraper, creds := newTestScraper(t, "", true, WithTimeout(60*time.Second))
 
 	session, err := scraper.Login(ctx, creds)
 	require.NoError(t, err, "Login failed")
@@ -519,15 +547,12 @@ func assertTransactions(t *testing.T, txns []bank.Transaction) {
 }
 
 func TestScraper_Live_Login(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
-	creds := requireLiveCreds(t)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	scraper, err := NewScraper(WithTimeout(60 * time.Second))
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
+	scraper, creds := newTestScraper(t, "", true, WithTimeout(60*time.Second))
 
 	session, err := scraper.Login(ctx, creds)
 	require.NoError(t, err, "Login failed")
@@ -538,13 +563,12 @@ func TestScraper_Live_Login(t *testing.T) {
 }
 
 func TestScraper_Live_GetBalance(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
 	defer cancel()
 
-	scraper, balances := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
+	_, balances := loginAndGetAccounts(t, ctx)
 
 	require.GreaterOrEqual(t, len(balances), 2, "Expected at least 2 accounts")
 	for i, b := range balances {
@@ -559,13 +583,12 @@ func TestScraper_Live_GetBalance(t *testing.T) {
 }
 
 func TestScraper_Live_GetTransactions_FirstAccount(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
 
 	scraper, balances := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
 
 	accountID := balances[0].AccountID
 	t.Logf("Fetching transactions for first account %s (%s)...", accountID, balances[0].Currency)
@@ -576,15 +599,12 @@ func TestScraper_Live_GetTransactions_FirstAccount(t *testing.T) {
 }
 
 func TestScraper_Live_Logout(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
-	creds := requireLiveCreds(t)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
 	defer cancel()
 
-	scraper, err := NewScraper(WithTimeout(60 * time.Second))
-	require.NoError(t, err)
-	defer func() { _ = scraper.Close() }()
+	scraper, creds := newTestScraper(t, "", true, WithTimeout(60*time.Second))
 
 	// Login
 	session, err := scraper.Login(ctx, creds)
@@ -609,13 +629,12 @@ func TestScraper_Live_Logout(t *testing.T) {
 }
 
 func TestScraper_Live_GetTransactions_SecondAccount(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
 
 	scraper, balances := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
 
 	accountID := balances[1].AccountID
 	t.Logf("Fetching transactions for second account %s (%s)...", accountID, balances[1].Currency)
@@ -631,13 +650,12 @@ func TestScraper_Live_GetTransactions_SecondAccount(t *testing.T) {
 // must fully reset the page state between operations.
 
 func TestScraper_Live_SessionReuse_DoubleGetBalance(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
 
 	scraper, balances1 := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
 
 	t.Log("Calling GetBalance a second time on the same session...")
 	balances2, err := scraper.GetBalance(ctx)
@@ -658,13 +676,12 @@ func TestScraper_Live_SessionReuse_DoubleGetBalance(t *testing.T) {
 }
 
 func TestScraper_Live_SessionReuse_BalanceAfterTransactions(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	scraper, balances1 := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
 
 	// GetTransactions for first account
 	accountID := balances1[0].AccountID
@@ -688,13 +705,12 @@ func TestScraper_Live_SessionReuse_BalanceAfterTransactions(t *testing.T) {
 }
 
 func TestScraper_Live_SessionReuse_TransactionsDifferentAccounts(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	scraper, balances := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
 	require.GreaterOrEqual(t, len(balances), 2, "Need at least 2 accounts")
 
 	// GetTransactions for first account
@@ -721,13 +737,12 @@ func TestScraper_Live_SessionReuse_TransactionsDifferentAccounts(t *testing.T) {
 }
 
 func TestScraper_Live_SessionReuse_TransactionsSameAccountTwice(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	scraper, balances := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
 
 	accountID := balances[0].AccountID
 	t.Logf("GetTransactions (call 1) for %s...", accountID)
@@ -757,13 +772,12 @@ func TestScraper_Live_SessionReuse_TransactionsSameAccountTwice(t *testing.T) {
 }
 
 func TestScraper_Live_SessionReuse_FullWorkflow(t *testing.T) {
-	skipUnlessMode(t, TestModeLive)
+	skipUnlessMode(t, banktest.TestModeLive)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
 	scraper, balances1 := loginAndGetAccounts(t, ctx)
-	defer func() { _ = scraper.Close() }()
 	require.GreaterOrEqual(t, len(balances1), 2, "Need at least 2 accounts")
 
 	// Step 1: GetTransactions for first account
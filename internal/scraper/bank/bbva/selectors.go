@@ -9,13 +9,16 @@ const (
 	// Senda flow: clicks #enviarSenda which sends credentials via postMessage
 	// to iframe#microfrontend, which calls the Senda API (grantingTicket/V02).
 	SelectorLoginButton = "button#enviarSenda"
+	SelectorSendaFrame  = "iframe#microfrontend"
+	// Legacy DFServlet flow's button, hidden while the Senda flow is served.
+	SelectorLegacyLoginButton = "button#aceptar"
 
 	// Senda login error display element
 	SelectorLoginErrorSpan = `span#error-message`
 
 	// SendaAPIURL is the grantingTicket endpoint used by the Senda login flow.
-	// In replay mode, the scraper probes this URL directly via fetch() to bypass
-	// the broken iframe postMessage chain.
+	// In replay mode, when the Senda iframe can't be hijacked, the scraper
+	// probes this URL directly to learn the login outcome.
 	SendaAPIURL = "https://asosenda.bbva.pe/TechArchitecture/pe/grantingTicket/V02"
 
 	// Login error page (legacy DFServlet flow — kept for reference)
@@ -50,6 +53,9 @@ const (
 
 	// Tile view
 	SelectorAccountCard = `bbva-btge-card-product-select`
+	// AccountCardOverviewID is the id of the "Todas las cuentas" tile, which
+	// summarizes the others and has no amount of its own.
+	AccountCardOverviewID = "allContracts"
 
 	// DashboardRoute is the SPA hash fragment set after successful login.
 	// The 2026 portal updates the URL to include this after the
@@ -66,6 +72,24 @@ const (
 	SelectorTxConcept         = `bbva-table-body-text.concept`
 	SelectorTxAmount          = `bbva-table-body-amount.transactionAmount`
 
+	// Transaction detail drawer, opened by clicking a data-actionable row.
+	// Each field is a label/value pair rendered as attributes.
+	SelectorTxDetailPanel = `bbva-btge-accounts-solution-movement-detail`
+	SelectorTxDetailField = `bbva-list-description-item[label]`
+	SelectorTxDetailClose = `bbva-btge-accounts-solution-movement-detail bbva-button-default.close`
+
+	// Scheduled operations page ("Operaciones programadas")
+	SelectorScheduledTable        = `bbva-btge-accounts-solution-table#scheduled-operations-table`
+	SelectorScheduledRow          = `tr.row`
+	SelectorScheduledDate         = `bbva-table-body-date.scheduledDate`
+	SelectorScheduledType         = `bbva-table-body-text.operationType`
+	SelectorScheduledID           = `bbva-table-body-text.operationNumber`
+	SelectorScheduledAccount      = `bbva-table-body-text.originAccount`
+	SelectorScheduledCounterparty = `bbva-table-body-text.beneficiary`
+	SelectorScheduledAmount       = `bbva-table-body-amount.operationAmount`
+	SelectorScheduledFrequency    = `bbva-table-body-text.frequency`
+	SelectorScheduledStatus       = `bbva-table-body-text.status`
+
 	// Accounts page — "Ir al detalle de cuenta" footer link inside each card
 	SelectorCardFooterLink = `.c-card-product-select__footer bbva-type-link[role="link"]`
 
@@ -76,3 +100,17 @@ const (
 	SelectorLogoutButton = `bbva-web-navigation-menu-item-action.exit`              // "Salir" sidebar button
 	SelectorLogoutModal  = `bbva-web-template-modal#template-modal-logout[visible]` // Confirmation modal (visible state)
 )
+
+// MutatingPathTerms are URL path fragments of portal endpoints that move money
+// or change the company's setup (transfers, payments, payroll, signing,
+// beneficiaries). WithReadOnly blocks writes to them. Lowercase.
+var MutatingPathTerms = []string{
+	"transfer",
+	"payment",
+	"pago",
+	"payroll",
+	"planilla",
+	"signature",
+	"firma",
+	"beneficiar",
+}
@@ -0,0 +1,106 @@
+package bbva
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// sendaHost serves iframe#microfrontend and the grantingTicket API it calls.
+const sendaHost = "asosenda.bbva.pe"
+
+// targetTypeIframe is the CDP target type of an out-of-process iframe;
+// proto has no constant for it.
+const targetTypeIframe proto.TargetTargetInfoType = "iframe"
+
+// errNoSendaFrame is returned when the Senda iframe has no target of its own
+// to hijack.
+var errNoSendaFrame = errors.New("senda iframe target not found")
+
+// sendaReplay serves the Senda iframe's requests in replay mode. The iframe
+// is cross-site, so Chrome runs it out of process as its own target and its
+// grantingTicket calls never reach the login page's router. Hijacking the
+// iframe target too lets #enviarSenda run as it does live: the iframe gets
+// the recorded API responses and posts the real outcome back to the page.
+type sendaReplay struct {
+	router    *rod.HijackRouter
+	responses chan sendaResponse
+}
+
+// sendaResponse is a grantingTicket response the iframe received.
+type sendaResponse struct {
+	method string
+	probeResponse
+}
+
+// startSendaReplay hijacks the Senda iframe's target with handler, recording
+// the grantingTicket responses it serves. Call before clicking the login
+// button, and stop when the login outcome is known.
+func (s *Scraper) startSendaReplay(handler func(*rod.Hijack)) (*sendaReplay, error) {
+	targets, err := proto.TargetGetTargets{}.Call(s.browser)
+	if err != nil {
+		return nil, err
+	}
+	var frame *rod.Page
+	for _, t := range targets.TargetInfos {
+		if t.Type == targetTypeIframe && strings.Contains(t.URL, sendaHost) {
+			if frame, err = s.browser.PageFromTarget(t.TargetID); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if frame == nil {
+		return nil, errNoSendaFrame
+	}
+
+	r := &sendaReplay{router: frame.HijackRequests(), responses: make(chan sendaResponse, 8)}
+	r.router.MustAdd("*", func(h *rod.Hijack) {
+		handler(h)
+		if !strings.Contains(h.Request.URL().Path, "grantingTicket") {
+			return
+		}
+		payload := h.Response.Payload()
+		select {
+		case r.responses <- sendaResponse{method: h.Request.Method(), probeResponse: probeResponse{status: payload.ResponseCode, body: string(payload.Body)}}:
+		default:
+		}
+	})
+	go r.router.Run()
+	return r, nil
+}
+
+// wait returns the login outcome from the grantingTicket calls the iframe
+// makes: a pre-auth POST, a DELETE clearing it, then the authenticating
+// POST. A failed call ends the flow early. ok is false when the flow doesn't
+// finish within timeout.
+func (r *sendaReplay) wait(ctx context.Context, timeout time.Duration) (result loginResult, ok bool) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cleared := false
+	for {
+		select {
+		case resp := <-r.responses:
+			switch {
+			case resp.method == http.MethodDelete:
+				cleared = true
+			case resp.status != http.StatusOK, cleared:
+				return classifyProbeResponse(resp.probeResponse), true
+			}
+		case <-waitCtx.Done():
+			return loginResult{}, false
+		}
+	}
+}
+
+func (r *sendaReplay) stop() {
+	if r != nil {
+		_ = r.router.Stop()
+	}
+}
@@ -0,0 +1,64 @@
+package bbva
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendaReplay_Wait(t *testing.T) {
+	ok200 := probeResponse{status: http.StatusOK, body: `{}`}
+	tests := []struct {
+		name      string
+		responses []sendaResponse
+		want      loginResult
+		wantOK    bool
+	}{
+		{
+			name: "success after pre-auth is cleared",
+			responses: []sendaResponse{
+				{http.MethodPost, ok200},
+				{http.MethodDelete, ok200},
+				{http.MethodPost, ok200},
+			},
+			want:   loginResult{outcome: loginSuccess},
+			wantOK: true,
+		},
+		{
+			name: "invalid credentials",
+			responses: []sendaResponse{
+				{http.MethodPost, ok200},
+				{http.MethodDelete, ok200},
+				{http.MethodPost, probeResponse{status: http.StatusForbidden, body: `{"error-code":"160"}`}},
+			},
+			want:   loginResult{outcome: loginError, errorText: "senda API error-code 160"},
+			wantOK: true,
+		},
+		{
+			name:      "pre-auth rejected",
+			responses: []sendaResponse{{http.MethodPost, probeResponse{status: http.StatusServiceUnavailable, body: "down"}}},
+			want:      loginResult{outcome: loginError, errorText: "senda API 503: down"},
+			wantOK:    true,
+		},
+		{
+			name:      "flow never finishes",
+			responses: []sendaResponse{{http.MethodPost, ok200}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &sendaReplay{responses: make(chan sendaResponse, len(tt.responses))}
+			for _, resp := range tt.responses {
+				r.responses <- resp
+			}
+
+			got, ok := r.wait(context.Background(), 50*time.Millisecond)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package bbva
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// Session returns a copy of the session from the last Login, or nil when
+// there is none (before Login, after Logout or Close).
+func (s *Scraper) Session() *bank.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session == nil {
+		return nil
+	}
+	session := *s.session
+	return &session
+}
+
+// activePage returns the authenticated tab kept from Login, so operation
+// reuses it instead of logging in again. It fails early with
+// ErrSessionExpired, rather than deep inside a navigation, when there is no
+// session, when the tab itself is gone (closed or crashed), and when the
+// portal signed the session out: BBVA then sends the tab back to the login
+// page, off PortalPath. The dead session is dropped so the next Login
+// starts clean. Callers hold s.mu.
+func (s *Scraper) activePage(operation string) (*rod.Page, error) {
+	if s.page == nil || s.session == nil {
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: operation,
+			Cause:     bank.ErrSessionExpired,
+			Details:   "no active session — call Login first",
+		}
+	}
+	info, err := s.page.Info()
+	if err != nil {
+		s.releasePage()
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: operation,
+			Cause:     bank.ErrSessionExpired,
+			Details:   "logged-in tab is gone: " + err.Error(),
+		}
+	}
+	if !isPortalURL(info.URL) {
+		s.releasePage()
+		return nil, &bank.ScraperError{
+			Code:      bank.BankBBVA,
+			Operation: operation,
+			Cause:     bank.ErrSessionExpired,
+			Details:   "signed out by the portal (tab at " + info.URL + ")",
+		}
+	}
+	s.session.Touch()
+	return s.page, nil
+}
+
+// releasePage closes the authenticated tab and forgets the session along
+// with everything scoped to it. Callers hold s.mu.
+func (s *Scraper) releasePage() {
+	s.stopHijacker()
+	s.stopCapture()
+	if s.page != nil {
+		_ = s.page.Close()
+		s.page = nil
+	}
+	s.session = nil
+	s.clock = nil
+	s.debug = nil
+	s.audit = nil
+}
+
+// isPortalURL reports whether rawURL is a page of the authenticated portal.
+// The portal only serves PortalPath to a live session.
+func isPortalURL(rawURL string) bool {
+	return strings.Contains(rawURL, PortalPath)
+}
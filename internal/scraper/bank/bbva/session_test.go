@@ -0,0 +1,79 @@
+package bbva
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+func TestScraper_ActivePage_NoPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *bank.Session
+	}{
+		{"before login", nil},
+		{"session without page", &bank.Session{ID: "s1", Code: bank.BankBBVA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Scraper{session: tt.session}
+
+			page, err := s.activePage("GetBalance")
+
+			assert.Nil(t, page)
+			var scraperErr *bank.ScraperError
+			require.ErrorAs(t, err, &scraperErr)
+			assert.Equal(t, "GetBalance", scraperErr.Operation)
+			require.ErrorIs(t, err, bank.ErrSessionExpired)
+		})
+	}
+}
+
+func TestScraper_Session(t *testing.T) {
+	s := &Scraper{}
+	assert.Nil(t, s.Session())
+
+	s.session = &bank.Session{ID: "s1", Code: bank.BankBBVA, ExpiresAt: time.Now().Add(time.Minute)}
+	got := s.Session()
+	require.NotNil(t, got)
+	assert.Equal(t, *s.session, *got)
+
+	got.ID = "changed"
+	assert.Equal(t, "s1", s.session.ID, "Session returns a copy")
+}
+
+func TestIsPortalURL(t *testing.T) {
+	assert.True(t, isPortalURL(accountsURL))
+	assert.True(t, isPortalURL(portalURL))
+	assert.False(t, isPortalURL(loginURL))
+	assert.False(t, isPortalURL("about:blank"))
+}
+
+// TestScraper_ConcurrentCalls exercises the Scraper's locking; run with
+// -race. Close clears the session while the other calls read it.
+func TestScraper_ConcurrentCalls(t *testing.T) {
+	s := &Scraper{session: &bank.Session{ID: "s1", Code: bank.BankBBVA}, logger: slog.New(slog.DiscardHandler)}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(5)
+		go func() { defer wg.Done(); _ = s.Session() }()
+		go func() { defer wg.Done(); _, _ = s.GetBalance(ctx) }()
+		go func() { defer wg.Done(); _, _ = s.GetTransactions(ctx, "any-account", 50) }()
+		go func() { defer wg.Done(); _, _ = s.GetScheduledOperations(ctx) }()
+		go func() { defer wg.Done(); _ = s.Close() }()
+	}
+	wg.Wait()
+
+	assert.Nil(t, s.Session())
+	_, err := s.GetBalance(ctx)
+	require.ErrorIs(t, err, bank.ErrSessionExpired)
+}
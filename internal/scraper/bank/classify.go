@@ -0,0 +1,109 @@
+package bank
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorClass says what a caller should do about a failed operation.
+type ErrorClass string
+
+// Error classes.
+const (
+	// ClassRetryable failures are transient: the bank is slow or down, or the
+	// session lapsed. Trying again later may succeed.
+	ClassRetryable ErrorClass = "retryable"
+	// ClassFatal failures won't go away by retrying: the page changed, the
+	// account doesn't exist, or the bank flagged the session as a bot.
+	ClassFatal ErrorClass = "fatal"
+	// ClassCredential failures mean the stored credential is wrong, expired or
+	// locked. Retrying risks locking the bank user, so it must be fixed first.
+	ClassCredential ErrorClass = "credential"
+)
+
+// sentinelClasses is the class of each sentinel error. Errors matching none
+// of them are fatal.
+var sentinelClasses = []struct {
+	err   error
+	class ErrorClass
+}{
+	{ErrInvalidCredentials, ClassCredential},
+	{ErrBankUnavailable, ClassRetryable},
+	{ErrTimeout, ClassRetryable},
+	{ErrSessionExpired, ClassRetryable},
+}
+
+// classifiedError is a sentinel whose class a bank's ErrorTable overrides.
+type classifiedError struct {
+	err   error
+	class ErrorClass
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+func (e *classifiedError) Unwrap() error { return e.err }
+
+// Classify returns the class of err: the one an ErrorTable rule assigned, or
+// else the class of the sentinel it wraps.
+func Classify(err error) ErrorClass {
+	var ce *classifiedError
+	if errors.As(err, &ce) {
+		return ce.class
+	}
+	for _, s := range sentinelClasses {
+		if errors.Is(err, s.err) {
+			return s.class
+		}
+	}
+	return ClassFatal
+}
+
+// ErrorSignature is what a scraper observed when an operation failed. Only
+// the fields it knows are set.
+type ErrorSignature struct {
+	Status    int    // HTTP status of a bank API response
+	ErrorCode string // error code in a bank API response body
+	Text      string // error message shown on the page
+	Selector  string // selector the scraper expected but didn't find
+}
+
+// ErrorRule maps an error signature to a sentinel error. A rule matches when
+// every field it sets matches the signature; Text matches as a
+// case-insensitive substring.
+type ErrorRule struct {
+	Status    int
+	ErrorCode string
+	Text      string
+	Selector  string
+
+	Err   error      // sentinel the signature means
+	Class ErrorClass // overrides the sentinel's class when set
+}
+
+func (r ErrorRule) matches(sig ErrorSignature) bool {
+	if r.Status == 0 && r.ErrorCode == "" && r.Text == "" && r.Selector == "" {
+		return false
+	}
+	return (r.Status == 0 || r.Status == sig.Status) &&
+		(r.ErrorCode == "" || r.ErrorCode == sig.ErrorCode) &&
+		(r.Text == "" || strings.Contains(strings.ToLower(sig.Text), strings.ToLower(r.Text))) &&
+		(r.Selector == "" || r.Selector == sig.Selector)
+}
+
+// ErrorTable is a bank's classification of the failures its portal shows.
+// Rules are checked in order; the first match wins.
+type ErrorTable []ErrorRule
+
+// Match returns the error sig means, or ErrUnknown if no rule matches.
+// Classify reports the result's class.
+func (t ErrorTable) Match(sig ErrorSignature) error {
+	for _, r := range t {
+		if !r.matches(sig) {
+			continue
+		}
+		if r.Class != "" {
+			return &classifiedError{err: r.Err, class: r.Class}
+		}
+		return r.Err
+	}
+	return ErrUnknown
+}
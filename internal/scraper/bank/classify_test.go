@@ -0,0 +1,38 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, ClassRetryable, Classify(fmt.Errorf("login: %w", ErrBankUnavailable)))
+	assert.Equal(t, ClassCredential, Classify(&ScraperError{Cause: ErrInvalidCredentials}))
+	assert.Equal(t, ClassFatal, Classify(ErrParsingFailed))
+	assert.Equal(t, ClassFatal, Classify(errors.New("something else")))
+}
+
+func TestErrorTable_Match(t *testing.T) {
+	table := ErrorTable{
+		{ErrorCode: "160", Err: ErrInvalidCredentials},
+		{Status: 503, Err: ErrBankUnavailable},
+		{Text: "Bloqueado", Err: ErrInvalidCredentials},
+		{Text: "mantenimiento", Err: ErrBankUnavailable, Class: ClassFatal},
+		{Selector: "#login", Err: ErrBankUnavailable},
+		{}, // matches nothing
+	}
+
+	assert.ErrorIs(t, table.Match(ErrorSignature{ErrorCode: "160"}), ErrInvalidCredentials)
+	assert.ErrorIs(t, table.Match(ErrorSignature{Status: 503}), ErrBankUnavailable)
+	assert.ErrorIs(t, table.Match(ErrorSignature{Text: "Usuario BLOQUEADO."}), ErrInvalidCredentials, "case-insensitive")
+	assert.ErrorIs(t, table.Match(ErrorSignature{Selector: "#login"}), ErrBankUnavailable)
+	assert.ErrorIs(t, table.Match(ErrorSignature{Status: 500}), ErrUnknown)
+	assert.ErrorIs(t, table.Match(ErrorSignature{}), ErrUnknown)
+
+	overridden := table.Match(ErrorSignature{Text: "Sistema en mantenimiento"})
+	assert.ErrorIs(t, overridden, ErrBankUnavailable)
+	assert.Equal(t, ClassFatal, Classify(fmt.Errorf("login: %w", overridden)))
+}
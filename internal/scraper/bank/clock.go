@@ -0,0 +1,43 @@
+package bank
+
+import (
+	"context"
+	"time"
+)
+
+// ClockSkew compares a bank's server clock with the local one. Date windows
+// ("last 7 days") are computed from the local clock, so a skewed host
+// silently asks the bank for the wrong days.
+type ClockSkew struct {
+	Code       Code      `json:"bank_code"`
+	ServerTime time.Time `json:"server_time"`  // Bank's time, e.g. from a response's Date header
+	LocalTime  time.Time `json:"local_time"`   // Local time when ServerTime was read
+	Seconds    float64   `json:"skew_seconds"` // ServerTime - LocalTime; positive when the local clock is behind
+}
+
+// NewClockSkew returns the skew between server and local.
+func NewClockSkew(code Code, server, local time.Time) ClockSkew {
+	return ClockSkew{Code: code, ServerTime: server, LocalTime: local, Seconds: server.Sub(local).Seconds()}
+}
+
+// Skew returns Seconds as a duration.
+func (c ClockSkew) Skew() time.Duration {
+	return time.Duration(c.Seconds * float64(time.Second))
+}
+
+type clockSkewKey struct{}
+
+// WithClockSkew returns a context whose scraper operations call fn with the
+// bank's clock whenever they read it. Scrapers only read it when talking to
+// the live bank; replayed responses carry the recording's time.
+func WithClockSkew(ctx context.Context, fn func(ClockSkew)) context.Context {
+	return context.WithValue(ctx, clockSkewKey{}, fn)
+}
+
+// ReportClockSkew calls the function attached to ctx by WithClockSkew.
+// Without one it does nothing.
+func ReportClockSkew(ctx context.Context, c ClockSkew) {
+	if fn, ok := ctx.Value(clockSkewKey{}).(func(ClockSkew)); ok {
+		fn(c)
+	}
+}
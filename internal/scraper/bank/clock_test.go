@@ -0,0 +1,22 @@
+package bank
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportClockSkew(t *testing.T) {
+	local := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	ReportClockSkew(context.Background(), NewClockSkew(BankBBVA, local, local)) // no listener: no-op
+
+	var got []ClockSkew
+	ctx := WithClockSkew(context.Background(), func(c ClockSkew) { got = append(got, c) })
+	ReportClockSkew(ctx, NewClockSkew(BankBBVA, local.Add(-90*time.Second), local))
+
+	assert.Len(t, got, 1)
+	assert.Equal(t, -90.0, got[0].Seconds)
+	assert.Equal(t, -90*time.Second, got[0].Skew())
+}
@@ -0,0 +1,15 @@
+package bank
+
+import "io/fs"
+
+// Corpus returns the sanitized pages the parsers are tested against and
+// their golden output, for an installed binary to check its own parsers
+// (bank-scraper selftest) or to run without a bank (demo mode). The layout
+// matches this directory: <code>/testdata/fixtures/<name>.html and
+// <code>/testdata/golden/<name>.json.
+//
+// The pages are megabytes, so they are only built in with -tags fixtures;
+// otherwise Corpus returns nil.
+func Corpus() fs.FS {
+	return corpus
+}
@@ -0,0 +1,13 @@
+//go:build fixtures
+
+package bank
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed */testdata/fixtures/*.html */testdata/golden/*.json
+var embeddedCorpus embed.FS
+
+var corpus fs.FS = embeddedCorpus
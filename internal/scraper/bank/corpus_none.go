@@ -0,0 +1,7 @@
+//go:build !fixtures
+
+package bank
+
+import "io/fs"
+
+var corpus fs.FS
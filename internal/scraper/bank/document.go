@@ -0,0 +1,55 @@
+package bank
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aynifx/bank-scraper/internal/schema"
+)
+
+// Document is the versioned envelope for serialized scrape output.
+// Always write it through NewDocument so SchemaVersion is set, and read it
+// through DecodeDocument so older versions are migrated first.
+type Document struct {
+	SchemaVersion string        `json:"schema_version"`
+	Code          Code          `json:"bank_code,omitempty"`
+	Balances      []Balance     `json:"balances,omitempty"`
+	Transactions  []Transaction `json:"transactions,omitempty"`
+}
+
+// NewDocument wraps balances and transactions in an envelope stamped with
+// the current schema version.
+func NewDocument(code Code, balances []Balance, transactions []Transaction) *Document {
+	return &Document{
+		SchemaVersion: schema.Version,
+		Code:          code,
+		Balances:      balances,
+		Transactions:  transactions,
+	}
+}
+
+// DecodeDocument parses a serialized Document, migrating it to the current
+// schema version with schema.Default when needed.
+func DecodeDocument(data []byte) (*Document, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode document: %w", err)
+	}
+
+	if v, _ := raw[schema.VersionKey].(string); v != schema.Version {
+		if err := schema.Default.Migrate(raw, schema.Version); err != nil {
+			return nil, fmt.Errorf("migrate document: %w", err)
+		}
+		migrated, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("re-encode migrated document: %w", err)
+		}
+		data = migrated
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode document: %w", err)
+	}
+	return &doc, nil
+}
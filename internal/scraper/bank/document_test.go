@@ -0,0 +1,44 @@
+package bank
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_RoundTrip(t *testing.T) {
+	after := int64(5000)
+	date := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	doc := NewDocument(BankBBVA,
+		[]Balance{{AccountID: "acc1", Currency: CurrencyPEN, AvailableBalance: 100}},
+		[]Transaction{{ID: "1", Date: date, Amount: 250, Type: TransactionDebit, BalanceAfter: &after}},
+	)
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"schema_version":"`+schema.Version+`"`)
+	assert.Contains(t, string(data), `"available_balance":100`)
+
+	got, err := DecodeDocument(data)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Version, got.SchemaVersion)
+	assert.Equal(t, BankBBVA, got.Code)
+	require.Len(t, got.Transactions, 1)
+	assert.Equal(t, int64(250), got.Transactions[0].Amount)
+	assert.Equal(t, int64(5000), *got.Transactions[0].BalanceAfter)
+}
+
+func TestDecodeDocument_Errors(t *testing.T) {
+	_, err := DecodeDocument([]byte(`{"balances":[]}`))
+	assert.ErrorIs(t, err, schema.ErrMissingVersion)
+
+	_, err = DecodeDocument([]byte(`{"schema_version":"99.0.0"}`))
+	assert.ErrorIs(t, err, schema.ErrNoMigration)
+
+	_, err = DecodeDocument([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package bank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Enricher post-processes a transaction after the scraper parsed it and
+// before anything stores or exports it: looking up the merchant, tagging a
+// project, filling Extra. It may change any field. A failing enricher
+// doesn't drop the transaction: it is kept as enriched so far.
+type Enricher interface {
+	Enrich(*Transaction) error
+}
+
+// EnricherFunc adapts a function to Enricher.
+type EnricherFunc func(*Transaction) error
+
+// Enrich calls f(tx).
+func (f EnricherFunc) Enrich(tx *Transaction) error {
+	return f(tx)
+}
+
+// Enrichers runs enrichers in order.
+type Enrichers []Enricher
+
+// Enrich runs each enricher on tx, in order. An error doesn't stop the
+// others: enrichers are independent lookups, and one failing is no reason
+// to skip the rest. The errors are returned together.
+func (es Enrichers) Enrich(tx *Transaction) error {
+	var errs []error
+	for _, e := range es {
+		if err := e.Enrich(tx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply enriches every transaction of txns in place. A transaction an
+// enricher fails on is kept and a Warning recorded on ctx (see Warn), so a
+// broken lookup degrades the data rather than losing it.
+func (es Enrichers) Apply(ctx context.Context, code Code, accountID string, txns []Transaction) {
+	if len(es) == 0 {
+		return
+	}
+	for i := range txns {
+		if err := es.Enrich(&txns[i]); err != nil {
+			Warn(ctx, Warning{
+				Code:      code,
+				Operation: "GetTransactions",
+				AccountID: accountID,
+				Message:   fmt.Sprintf("enrich transaction %s: %v", txns[i].ID, err),
+			})
+		}
+	}
+}
+
+// Enrich wraps s so the transactions GetTransactions returns went through
+// enrichers first. Scrapers implementing ScheduledOperationsScraper or
+// PageCapturer keep doing so. Without enrichers s is returned as is.
+func Enrich(code Code, s Scraper, enrichers ...Enricher) Scraper {
+	if len(enrichers) == 0 {
+		return s
+	}
+	e := &enriched{Scraper: s, code: code, enrichers: enrichers}
+	sched, isSched := s.(ScheduledOperationsScraper)
+	capturer, isCapturer := s.(PageCapturer)
+	switch {
+	case isSched && isCapturer:
+		return struct {
+			*enriched
+			ScheduledOperationsScraper
+			PageCapturer
+		}{e, sched, capturer}
+	case isSched:
+		return struct {
+			*enriched
+			ScheduledOperationsScraper
+		}{e, sched}
+	case isCapturer:
+		return struct {
+			*enriched
+			PageCapturer
+		}{e, capturer}
+	}
+	return e
+}
+
+// enriched runs enrichers over the transactions of the Scraper it wraps.
+type enriched struct {
+	Scraper
+	code      Code
+	enrichers Enrichers
+}
+
+// GetTransactions returns the inner scraper's transactions, enriched.
+func (e *enriched) GetTransactions(ctx context.Context, accountID string, count int) ([]Transaction, error) {
+	txns, err := e.Scraper.GetTransactions(ctx, accountID, count)
+	if err != nil {
+		return txns, err
+	}
+	e.enrichers.Apply(ctx, e.code, accountID, txns)
+	return txns, nil
+}
@@ -0,0 +1,72 @@
+package bank
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubScraper returns fixed transactions; the embedded nil Scraper panics
+// on anything else.
+type stubScraper struct {
+	Scraper
+	txns []Transaction
+}
+
+func (s *stubScraper) GetTransactions(context.Context, string, int) ([]Transaction, error) {
+	return s.txns, nil
+}
+
+type stubScheduled struct {
+	*stubScraper
+}
+
+func (stubScheduled) GetScheduledOperations(context.Context) ([]ScheduledOperation, error) {
+	return nil, nil
+}
+
+func TestEnrich(t *testing.T) {
+	inner := &stubScraper{txns: []Transaction{
+		{ID: "1", Description: "PAGO NETFLIX"},
+		{ID: "2", Description: "ABONO CLIENTE"},
+	}}
+	merchant := EnricherFunc(func(tx *Transaction) error {
+		if tx.ID == "2" {
+			return errors.New("lookup timed out")
+		}
+		tx.CleanDescription = "Netflix"
+		return nil
+	})
+	project := EnricherFunc(func(tx *Transaction) error {
+		tx.Extra = map[string]string{"project": "ops"}
+		return nil
+	})
+	s := Enrich(BankBBVA, inner, merchant, project)
+
+	ws := &Warnings{}
+	txns, err := s.GetTransactions(WithWarnings(context.Background(), ws), "001", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "Netflix", txns[0].CleanDescription)
+	assert.Equal(t, "ops", txns[0].Extra["project"])
+	assert.Empty(t, txns[1].CleanDescription)
+	assert.Equal(t, "ops", txns[1].Extra["project"], "a failed enricher doesn't stop the next")
+
+	warnings := ws.List()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "001", warnings[0].AccountID)
+	assert.Contains(t, warnings[0].Message, "enrich transaction 2: lookup timed out")
+}
+
+func TestEnrich_KeepsCapabilities(t *testing.T) {
+	inner := &stubScraper{}
+	assert.Same(t, Scraper(inner), Enrich(BankBBVA, inner), "no enrichers: unwrapped")
+
+	noop := EnricherFunc(func(*Transaction) error { return nil })
+	_, ok := Enrich(BankBBVA, inner, noop).(ScheduledOperationsScraper)
+	assert.False(t, ok)
+	_, ok = Enrich(BankBBVA, stubScheduled{inner}, noop).(ScheduledOperationsScraper)
+	assert.True(t, ok)
+}
@@ -16,6 +16,8 @@ var (
 
 	ErrParsingFailed = errors.New("failed to parse bank response")
 	ErrTimeout       = errors.New("operation timed out")
+
+	ErrUnsupportedPage = errors.New("unsupported page")
 )
 
 // ScraperError provides detailed error context
@@ -0,0 +1,46 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownFlag is returned by ParseFlags for a flag no bank declares.
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Flag names an experimental behavior of a bank's scraper, off unless
+// enabled, so a risky path can ship dark and be turned on per deployment
+// without a release of its own. Each bank package declares its flags.
+type Flag string
+
+// Flags is the set of a bank's enabled flags. The zero value enables none.
+type Flags map[Flag]bool
+
+// Enabled reports whether flag is on.
+func (f Flags) Enabled(flag Flag) bool {
+	return f[flag]
+}
+
+// ParseFlags parses flag entries of the form BANK:flag (e.g.
+// BBVA:legacy-login) into each bank's enabled set, rejecting the flags
+// known doesn't list for their bank.
+func ParseFlags(entries []string, known map[Code][]Flag) (map[Code]Flags, error) {
+	flags := map[Code]Flags{}
+	for _, e := range entries {
+		c, name, ok := strings.Cut(strings.TrimSpace(e), ":")
+		if !ok || c == "" || name == "" {
+			return nil, fmt.Errorf("%w: %q, want BANK:flag", ErrUnknownFlag, e)
+		}
+		code, flag := Code(strings.ToUpper(c)), Flag(strings.ToLower(name))
+		if !slices.Contains(known[code], flag) {
+			return nil, fmt.Errorf("%w: %s has no flag %q", ErrUnknownFlag, code, flag)
+		}
+		if flags[code] == nil {
+			flags[code] = Flags{}
+		}
+		flags[code][flag] = true
+	}
+	return flags, nil
+}
@@ -0,0 +1,26 @@
+package bank
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlags(t *testing.T) {
+	known := map[Code][]Flag{BankBBVA: {"legacy-login", "xhr"}}
+
+	flags, err := ParseFlags([]string{"BBVA:legacy-login", " bbva:XHR"}, known)
+	require.NoError(t, err)
+	assert.True(t, flags[BankBBVA].Enabled("legacy-login"))
+	assert.True(t, flags[BankBBVA].Enabled("xhr"))
+
+	none, err := ParseFlags(nil, known)
+	require.NoError(t, err)
+	assert.False(t, none[BankBBVA].Enabled("legacy-login"), "nil set enables nothing")
+
+	for _, entry := range []string{"legacy-login", "BBVA:", "BBVA:turbo", "BCP:legacy-login"} {
+		_, err := ParseFlags([]string{entry}, known)
+		assert.ErrorIs(t, err, ErrUnknownFlag, entry)
+	}
+}
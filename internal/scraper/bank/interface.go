@@ -18,6 +18,7 @@ type Scraper interface {
 
 	// GetTransactions fetches transaction history for a specific account.
 	// count is the desired number of transactions; the scraper may clamp to bank-specific limits.
+	// Transactions are returned in DefaultSortOrder (newest first).
 	// Must be called after a successful Login.
 	GetTransactions(ctx context.Context, accountID string, count int) ([]Transaction, error)
 
@@ -28,6 +29,34 @@ type Scraper interface {
 	Close() error
 }
 
+// ScheduledOperationsScraper is implemented by scrapers that can list
+// future-dated operations ("operaciones programadas"). It is optional:
+// callers type-assert a Scraper to check for support.
+type ScheduledOperationsScraper interface {
+	// GetScheduledOperations lists upcoming scheduled transfers, direct debits
+	// and payments for all accounts. Read-only. Must be called after a successful Login.
+	GetScheduledOperations(ctx context.Context) ([]ScheduledOperation, error)
+}
+
+// Pages a PageCapturer can capture. Each bank maps them to its own portal.
+const (
+	PageCurrent   = "current"   // Whatever the session is showing, without navigating
+	PageAccounts  = "accounts"  // Account list with balances
+	PageScheduled = "scheduled" // Scheduled operations
+)
+
+// PageCapturer is implemented by scrapers that can hand out the raw state of
+// a portal page, for callers building their own parsers or archiving what
+// the bank showed. It is optional: callers type-assert a Scraper to check
+// for support.
+type PageCapturer interface {
+	// CapturePage navigates the authenticated session to pageName (one of
+	// the Page constants) and returns its HTML, with shadow DOM and iframes
+	// inlined, and a full-page PNG screenshot. The page is read, never
+	// modified. Returns ErrUnsupportedPage for a page the bank lacks.
+	CapturePage(ctx context.Context, session *Session, pageName string) (html string, screenshot []byte, err error)
+}
+
 // Code identifies a supported bank.
 type Code string
 
@@ -0,0 +1,14 @@
+package bank
+
+import "strings"
+
+// MaskAccountID masks all but the last 4 characters of a bank account
+// number, for output that leaves the store: logs, notifications and
+// exports show it this way unless full identifiers are explicitly enabled.
+// e.g., "PE001101190100064607" → "XXXXXXXXXXXXXXXX4607"
+func MaskAccountID(id string) string {
+	if len(id) <= 4 {
+		return id
+	}
+	return strings.Repeat("X", len(id)-4) + id[len(id)-4:]
+}
@@ -0,0 +1,18 @@
+package bank
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskAccountID(t *testing.T) {
+	for id, want := range map[string]string{
+		"PE001101190100064607": "XXXXXXXXXXXXXXXX4607",
+		"0011-0100-0200004607": "XXXXXXXXXXXXXXXX4607",
+		"4607":                 "4607",
+		"":                     "",
+	} {
+		assert.Equal(t, want, MaskAccountID(id), id)
+	}
+}
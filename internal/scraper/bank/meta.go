@@ -0,0 +1,82 @@
+package bank
+
+import (
+	"fmt"
+	"time"
+)
+
+// Transaction.Extra keys written by the BBVA scraper. The detail drawer adds
+// every other label it shows verbatim (e.g. "Oficina").
+const (
+	BBVAExtraCodigo      = "Codigo"
+	BBVAExtraBeneficiary = "Beneficiary"
+	BBVAExtraChannel     = "Channel"
+	BBVAExtraTimestamp   = "Timestamp" // RFC 3339
+	BBVAExtraOffice      = "Oficina"
+)
+
+// bbvaExtraAliases are keys other sources use for the same data: statement
+// imports copy column headers, which carry accents.
+var bbvaExtraAliases = map[string][]string{
+	BBVAExtraCodigo: {"Código"},
+}
+
+// BBVAMeta is the typed view of a BBVA transaction's Extra metadata.
+type BBVAMeta struct {
+	Codigo      string    // Movement code, e.g. "015"
+	Office      string    // Branch where the movement originated, e.g. "0437"
+	Beneficiary string    // Counterparty shown under the concept
+	Channel     string    // e.g. "Banca por Internet"; detail drawer only
+	Timestamp   time.Time // Exact date and time; detail drawer only, zero otherwise
+}
+
+// BBVAMeta reads t's Extra into a BBVAMeta. Missing keys leave fields empty;
+// a present but malformed value is an error. Extra itself is unchanged and
+// may hold keys BBVAMeta doesn't know.
+func (t Transaction) BBVAMeta() (BBVAMeta, error) {
+	m := BBVAMeta{
+		Codigo:      bbvaExtra(t.Extra, BBVAExtraCodigo),
+		Office:      bbvaExtra(t.Extra, BBVAExtraOffice),
+		Beneficiary: bbvaExtra(t.Extra, BBVAExtraBeneficiary),
+		Channel:     bbvaExtra(t.Extra, BBVAExtraChannel),
+	}
+	if v := bbvaExtra(t.Extra, BBVAExtraTimestamp); v != "" {
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return m, fmt.Errorf("extra %s: %w", BBVAExtraTimestamp, err)
+		}
+		m.Timestamp = ts
+	}
+	return m, nil
+}
+
+// Extra returns m as Extra entries, omitting empty fields.
+func (m BBVAMeta) Extra() map[string]string {
+	extra := map[string]string{}
+	for key, v := range map[string]string{
+		BBVAExtraCodigo:      m.Codigo,
+		BBVAExtraOffice:      m.Office,
+		BBVAExtraBeneficiary: m.Beneficiary,
+		BBVAExtraChannel:     m.Channel,
+	} {
+		if v != "" {
+			extra[key] = v
+		}
+	}
+	if !m.Timestamp.IsZero() {
+		extra[BBVAExtraTimestamp] = m.Timestamp.Format(time.RFC3339)
+	}
+	return extra
+}
+
+func bbvaExtra(extra map[string]string, key string) string {
+	if v, ok := extra[key]; ok {
+		return v
+	}
+	for _, alias := range bbvaExtraAliases[key] {
+		if v, ok := extra[alias]; ok {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package bank
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_BBVAMeta(t *testing.T) {
+	ts := time.Date(2026, 2, 10, 14, 35, 12, 0, time.UTC)
+	tx := Transaction{Extra: map[string]string{
+		"Codigo":    "015",
+		"Oficina":   "0437",
+		"Channel":   "Banca por Internet",
+		"Timestamp": "2026-02-10T14:35:12Z",
+		"Tipo":      "Abono", // unknown keys are kept, just not typed
+	}}
+
+	meta, err := tx.BBVAMeta()
+	require.NoError(t, err)
+	assert.Equal(t, BBVAMeta{Codigo: "015", Office: "0437", Channel: "Banca por Internet", Timestamp: ts}, meta)
+
+	back := meta.Extra()
+	delete(tx.Extra, "Tipo")
+	assert.Equal(t, tx.Extra, back, "round-trips")
+}
+
+func TestTransaction_BBVAMeta_ImportedKeys(t *testing.T) {
+	meta, err := Transaction{Extra: map[string]string{"Código": "015"}}.BBVAMeta()
+	require.NoError(t, err)
+	assert.Equal(t, "015", meta.Codigo)
+
+	meta, err = Transaction{}.BBVAMeta()
+	require.NoError(t, err)
+	assert.Zero(t, meta)
+}
+
+func TestTransaction_BBVAMeta_Malformed(t *testing.T) {
+	_, err := Transaction{Extra: map[string]string{"Timestamp": "10/02/2026"}}.BBVAMeta()
+	assert.ErrorContains(t, err, "extra Timestamp")
+}
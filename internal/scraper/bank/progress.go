@@ -0,0 +1,28 @@
+package bank
+
+import "context"
+
+// PageProgress reports how far a paginated fetch has got: the pages loaded
+// so far and the rows they hold, against the rows asked for.
+type PageProgress struct {
+	Operation string // Scraper method, e.g. "GetTransactions"
+	Pages     int
+	Rows      int
+	Target    int
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context whose paginated scraper operations call fn
+// after each page they load, for showing progress of long fetches.
+func WithProgress(ctx context.Context, fn func(PageProgress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress calls the function attached to ctx by WithProgress.
+// Without one it does nothing, so scrapers can report unconditionally.
+func ReportProgress(ctx context.Context, p PageProgress) {
+	if fn, ok := ctx.Value(progressKey{}).(func(PageProgress)); ok {
+		fn(p)
+	}
+}
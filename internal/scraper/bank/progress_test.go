@@ -0,0 +1,20 @@
+package bank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportProgress(t *testing.T) {
+	ReportProgress(context.Background(), PageProgress{Pages: 1}) // no listener: no-op
+
+	var got []PageProgress
+	ctx := WithProgress(context.Background(), func(p PageProgress) { got = append(got, p) })
+	ReportProgress(ctx, PageProgress{Operation: "GetTransactions", Pages: 1, Rows: 50, Target: 250})
+	ReportProgress(ctx, PageProgress{Operation: "GetTransactions", Pages: 2, Rows: 100, Target: 250})
+
+	assert.Len(t, got, 2)
+	assert.Equal(t, 100, got[1].Rows)
+}
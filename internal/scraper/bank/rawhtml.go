@@ -0,0 +1,54 @@
+package bank
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RawHTML is the exact input a scraper handed its parser. Kept alongside the
+// parsed result, a reported discrepancy can be replayed through the parser
+// offline instead of waiting for the portal to show it again.
+type RawHTML struct {
+	Operation  string    `json:"operation"`            // Scraper method, e.g. "GetBalance"
+	AccountID  string    `json:"account_id,omitempty"` // For per-account operations
+	HTML       string    `json:"html"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// RawHTMLLog collects the parser inputs of one or more operations. Safe for
+// concurrent use.
+type RawHTMLLog struct {
+	mu   sync.Mutex
+	list []RawHTML
+}
+
+// Add records pages.
+func (l *RawHTMLLog) Add(pages ...RawHTML) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.list = append(l.list, pages...)
+}
+
+// List returns the pages recorded so far, in order.
+func (l *RawHTMLLog) List() []RawHTML {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]RawHTML(nil), l.list...)
+}
+
+type rawHTMLKey struct{}
+
+// WithRawHTML returns a context whose scraper operations record the HTML
+// they parse in l. Pages run to megabytes: attach a log only when asked to.
+func WithRawHTML(ctx context.Context, l *RawHTMLLog) context.Context {
+	return context.WithValue(ctx, rawHTMLKey{}, l)
+}
+
+// RecordHTML records html as the parser input of operation in the log
+// attached to ctx by WithRawHTML. Without one it does nothing.
+func RecordHTML(ctx context.Context, operation, accountID, html string) {
+	if l, ok := ctx.Value(rawHTMLKey{}).(*RawHTMLLog); ok {
+		l.Add(RawHTML{Operation: operation, AccountID: accountID, HTML: html, CapturedAt: time.Now()})
+	}
+}
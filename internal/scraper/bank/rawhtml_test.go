@@ -0,0 +1,23 @@
+package bank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordHTML(t *testing.T) {
+	RecordHTML(context.Background(), "GetBalance", "", "<html></html>") // no log: no-op
+
+	l := &RawHTMLLog{}
+	ctx := WithRawHTML(context.Background(), l)
+	RecordHTML(ctx, "GetBalance", "", "<table>balances</table>")
+	RecordHTML(ctx, "GetTransactions", "001", "<table>movements</table>")
+
+	got := l.List()
+	assert.Len(t, got, 2)
+	assert.Equal(t, "<table>balances</table>", got[0].HTML)
+	assert.Equal(t, "001", got[1].AccountID)
+	assert.False(t, got[1].CapturedAt.IsZero())
+}
@@ -0,0 +1,57 @@
+package bank
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Request is one network request a scraper's browser made: enough to tell
+// which call returned 403 or hung, without the bodies a HAR would carry.
+type Request struct {
+	Operation string        `json:"operation"` // Scraper method, e.g. "GetBalance"
+	Method    string        `json:"method"`
+	URL       string        `json:"url"`              // Without query string, which may carry tokens
+	Status    int           `json:"status,omitempty"` // 0 when no response arrived
+	Error     string        `json:"error,omitempty"`  // Network failure, e.g. "net::ERR_TIMED_OUT"
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+}
+
+// RequestLog collects the requests made during one or more operations.
+// Safe for concurrent use.
+type RequestLog struct {
+	mu   sync.Mutex
+	list []Request
+}
+
+// Add records reqs.
+func (l *RequestLog) Add(reqs ...Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.list = append(l.list, reqs...)
+}
+
+// List returns the requests recorded so far, in order.
+func (l *RequestLog) List() []Request {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Request(nil), l.list...)
+}
+
+type requestLogKey struct{}
+
+// WithRequestLog returns a context whose scraper operations record their
+// browser's requests in l. Scrapers only capture requests when built to
+// (e.g. bbva.WithNetworkLog).
+func WithRequestLog(ctx context.Context, l *RequestLog) context.Context {
+	return context.WithValue(ctx, requestLogKey{}, l)
+}
+
+// LogRequests records reqs in the log attached to ctx by WithRequestLog.
+// Without one it does nothing.
+func LogRequests(ctx context.Context, reqs ...Request) {
+	if l, ok := ctx.Value(requestLogKey{}).(*RequestLog); ok && len(reqs) > 0 {
+		l.Add(reqs...)
+	}
+}
@@ -0,0 +1,117 @@
+package bank
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidSort is returned by ParseSortOrder for an unknown key or direction.
+var ErrInvalidSort = errors.New("invalid sort order")
+
+// SortKey is a Transaction field transactions can be ordered by.
+type SortKey string
+
+// Sort keys.
+const (
+	SortByDate      SortKey = "date"       // Operation date
+	SortByValueDate SortKey = "value_date" // Value date
+	SortByID        SortKey = "id"         // Document number; numeric when both are digits
+	SortByAmount    SortKey = "amount"
+)
+
+// SortField orders by one key, ascending unless Desc.
+type SortField struct {
+	Key  SortKey
+	Desc bool
+}
+
+// DefaultSortOrder is the order every Scraper returns transactions in:
+// newest operation date first, then highest document number first. Banks
+// list movements in different orders per view; scrapers sort before
+// returning so callers can rely on this one.
+var DefaultSortOrder = []SortField{
+	{Key: SortByDate, Desc: true},
+	{Key: SortByID, Desc: true},
+}
+
+// SortTransactions sorts txns in place by order, or by DefaultSortOrder if
+// order is empty. The sort is stable: transactions equal on every key keep
+// their relative order, which keeps same-day duplicates in page order.
+func SortTransactions(txns []Transaction, order ...SortField) {
+	if len(order) == 0 {
+		order = DefaultSortOrder
+	}
+	slices.SortStableFunc(txns, func(a, b Transaction) int {
+		for _, f := range order {
+			c := compareBy(f.Key, a, b)
+			if f.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// ParseSortOrder parses a comma-separated list of keys, each optionally
+// suffixed with ":asc" or ":desc", e.g. "date:desc,id". An empty string
+// yields DefaultSortOrder.
+func ParseSortOrder(s string) ([]SortField, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultSortOrder, nil
+	}
+	var order []SortField
+	for part := range strings.SplitSeq(s, ",") {
+		key, dir, _ := strings.Cut(strings.TrimSpace(part), ":")
+		f := SortField{Key: SortKey(strings.ToLower(key))}
+		switch f.Key {
+		case SortByDate, SortByValueDate, SortByID, SortByAmount:
+		default:
+			return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidSort, key)
+		}
+		switch strings.ToLower(dir) {
+		case "", "asc":
+		case "desc":
+			f.Desc = true
+		default:
+			return nil, fmt.Errorf("%w: unknown direction %q", ErrInvalidSort, dir)
+		}
+		order = append(order, f)
+	}
+	return order, nil
+}
+
+func compareBy(key SortKey, a, b Transaction) int {
+	switch key {
+	case SortByDate:
+		return a.Date.Compare(b.Date)
+	case SortByValueDate:
+		return a.ValueDate.Compare(b.ValueDate)
+	case SortByID:
+		return compareDocNumbers(a.ID, b.ID)
+	case SortByAmount:
+		return cmp.Compare(a.Amount, b.Amount)
+	}
+	return 0
+}
+
+// compareDocNumbers compares document numbers numerically when both are
+// digits (so "99" sorts before "100"), lexically otherwise.
+func compareDocNumbers(a, b string) int {
+	if isDigits(a) && isDigits(b) {
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
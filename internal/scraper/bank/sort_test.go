@@ -0,0 +1,60 @@
+package bank
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ids(txns []Transaction) []string {
+	out := make([]string, len(txns))
+	for i, tx := range txns {
+		out[i] = tx.ID
+	}
+	return out
+}
+
+func TestSortTransactions_Default(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	txns := []Transaction{
+		{ID: "99", Date: day(1)},
+		{ID: "100", Date: day(1)},
+		{ID: "7", Date: day(2)},
+		{ID: "", Date: day(1), Description: "first"},
+		{ID: "", Date: day(1), Description: "second"},
+	}
+
+	SortTransactions(txns)
+
+	assert.Equal(t, []string{"7", "100", "99", "", ""}, ids(txns), "newest first, then numeric document number")
+	assert.Equal(t, "first", txns[3].Description, "stable for equal keys")
+}
+
+func TestSortTransactions_Custom(t *testing.T) {
+	txns := []Transaction{
+		{ID: "a", Amount: 300},
+		{ID: "b", Amount: 100},
+		{ID: "c", Amount: 300},
+	}
+
+	SortTransactions(txns, SortField{Key: SortByAmount, Desc: true}, SortField{Key: SortByID, Desc: true})
+
+	assert.Equal(t, []string{"c", "a", "b"}, ids(txns))
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder("amount:desc, ID")
+	require.NoError(t, err)
+	assert.Equal(t, []SortField{{Key: SortByAmount, Desc: true}, {Key: SortByID}}, order)
+
+	order, err = ParseSortOrder("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSortOrder, order)
+
+	_, err = ParseSortOrder("color")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = ParseSortOrder("date:sideways")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
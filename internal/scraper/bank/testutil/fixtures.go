@@ -1,24 +1,23 @@
-// Package testutil provides test helpers for loading HTML fixtures.
+// Package testutil provides test helpers for loading and generating HTML fixtures.
 package testutil
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"testing"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 )
 
 // LoadFixture reads an HTML fixture file for the given bank
-func LoadFixture(t *testing.T, bankCode, name string) string {
+func LoadFixture(t testing.TB, bankCode, name string) string {
 	t.Helper()
 
-	// Get path relative to thjis file
-	_, filename, _, _ := runtime.Caller(0)
-	baseDir := filepath.Dir(filepath.Dir(filename)) // up to bank/
-
-	path := filepath.Join(baseDir, bankCode, "testdata", "fixtures", name+".html")
-
-	data, err := os.ReadFile(path)
+	data, err := readFixture(bankCode, name)
 	if err != nil {
 		t.Fatalf("Failed to load fixture %s/%s: %v", bankCode, name, err)
 	}
@@ -27,19 +26,28 @@ func LoadFixture(t *testing.T, bankCode, name string) string {
 }
 
 // MustLoadFixture is like LoadFixture but panics on error (for non-test use)
-func MustLoadFixture(t *testing.T, bankCode, name string) string {
+func MustLoadFixture(t testing.TB, bankCode, name string) string {
 	t.Helper()
 
-	// Get path relative to thjis file
-	_, filename, _, _ := runtime.Caller(0)
-	baseDir := filepath.Dir(filepath.Dir(filename)) // up to bank/
-
-	path := filepath.Join(baseDir, bankCode, "testdata", "fixtures", name+".html")
-
-	data, err := os.ReadFile(path)
+	data, err := readFixture(bankCode, name)
 	if err != nil {
 		panic(err)
 	}
 
 	return string(data)
 }
+
+// readFixture reads a fixture from the source tree, falling back to the
+// fixtures built into the binary (bank.Corpus) when the file is absent, as
+// for a test binary run away from its checkout.
+func readFixture(bankCode, name string) ([]byte, error) {
+	// Get path relative to this file
+	_, filename, _, _ := runtime.Caller(0)
+	baseDir := filepath.Dir(filepath.Dir(filename)) // up to bank/
+
+	data, err := os.ReadFile(filepath.Join(baseDir, bankCode, "testdata", "fixtures", name+".html"))
+	if errors.Is(err, fs.ErrNotExist) && bank.Corpus() != nil {
+		return fs.ReadFile(bank.Corpus(), path.Join(bankCode, "testdata", "fixtures", name+".html"))
+	}
+	return data, err
+}
@@ -0,0 +1,276 @@
+package testutil
+
+import (
+	"fmt"
+	"html"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
+)
+
+// SyntheticOptions configures SyntheticBBVATransactions.
+type SyntheticOptions struct {
+	Rows          int       // Rows in the table
+	Seed          uint64    // The same options and seed give the same page
+	EdgeCaseRate  float64   // Fraction of rows (0-1) with an unusual but valid amount, date or text
+	MalformedRate float64   // Fraction of rows (0-1) broken in a way the parser must reject
+	Newest        time.Time // Operation date of the first row; zero means 2026-02-10
+}
+
+// SyntheticPage is a generated BBVA transactions page.
+type SyntheticPage struct {
+	HTML string
+	// Want is what the well-formed rows parse to, in page order: the whole
+	// result when there are no malformed rows, or what a parser skipping
+	// them would return.
+	Want      []bank.Transaction
+	Malformed []int // Indexes of the malformed rows, ascending
+}
+
+// SyntheticBBVATransactions generates a BBVA movements table shaped like the
+// live page (newest first, running balance, Spanish month abbreviations),
+// with as many rows as asked, for benchmarks, fuzz seeds and parser stress
+// tests. It keeps only the markup the parser reads, not the shadow DOM.
+func SyntheticBBVATransactions(opts SyntheticOptions) SyntheticPage {
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	day := opts.Newest
+	if day.IsZero() {
+		day = time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	balance := 500_000 + rng.Int64N(5_000_000)
+
+	var page SyntheticPage
+	var b strings.Builder
+	fmt.Fprintf(&b, `<html><body>
+<bbva-btge-accounts-solution-table id="moviments-table" state="" total-items="%d">
+<table><tbody>
+`, opts.Rows)
+	for i := range opts.Rows {
+		r := synthRow{
+			opDate:  day,
+			valDate: day,
+			code:    fmt.Sprintf("%03d", rng.IntN(1000)),
+			number:  strconv.Itoa(100_000 + opts.Rows - i),
+			balance: balance,
+		}
+		c := synthConcepts[rng.IntN(len(synthConcepts))]
+		r.concept, r.beneficiary = c.concept, c.beneficiary
+		r.amount = (c.min + rng.Int64N(c.max-c.min+1)) * 100
+		r.amount += rng.Int64N(100)
+		if !c.credit {
+			r.amount = -r.amount
+		}
+		r.amountAttr, r.balanceAttr = formatCents(r.amount), formatCents(r.balance)
+		r.opAttr, r.valAttr = bbvaDate(r.opDate), bbvaDate(r.valDate)
+		r.opYear = synthAttr{v: strconv.Itoa(r.opDate.Year())}
+		r.valYear = r.opYear
+
+		if rng.Float64() < opts.EdgeCaseRate {
+			synthEdgeCases[rng.IntN(len(synthEdgeCases))](rng, &r)
+		}
+		malformed := rng.Float64() < opts.MalformedRate
+		if malformed {
+			synthMalformations[rng.IntN(len(synthMalformations))](&r)
+			page.Malformed = append(page.Malformed, i)
+		} else {
+			page.Want = append(page.Want, r.transaction())
+		}
+		r.write(&b)
+
+		// Going down the table is going back in time: the balance before
+		// this movement is the one after the next (older) row.
+		balance -= r.amount
+		day = day.AddDate(0, 0, -rng.IntN(3))
+	}
+	b.WriteString("</tbody></table>\n</bbva-btge-accounts-solution-table>\n</body></html>\n")
+	page.HTML = b.String()
+	return page
+}
+
+// synthRow is one generated row: the values it stands for and the attribute
+// text written for them, which edge cases and malformations alter.
+type synthRow struct {
+	opDate, valDate      time.Time
+	code, number         string
+	concept, beneficiary string
+	amount, balance      int64 // cents
+
+	opAttr, opYear, valAttr, valYear synthAttr
+	amountAttr, balanceAttr          synthAttr
+}
+
+// synthAttr is an attribute's text, or its absence.
+type synthAttr struct {
+	v    string
+	drop bool
+}
+
+func (a synthAttr) write(b *strings.Builder, name string) {
+	if !a.drop {
+		fmt.Fprintf(b, ` %s="%s"`, name, html.EscapeString(a.v))
+	}
+}
+
+func (r *synthRow) transaction() bank.Transaction {
+	typ, amount := bank.TransactionCredit, r.amount
+	if amount <= 0 {
+		typ, amount = bank.TransactionDebit, -amount
+	}
+	concept := strings.TrimSpace(r.concept)
+	balance := r.balance
+	return bank.Transaction{
+		ID:               r.number,
+		Date:             r.opDate,
+		ValueDate:        r.valDate,
+		Description:      concept,
+		CleanDescription: parseutil.CleanDescription(concept),
+		Amount:           amount,
+		Type:             typ,
+		BalanceAfter:     &balance,
+		Extra: map[string]string{
+			bank.BBVAExtraBeneficiary: strings.TrimSpace(r.beneficiary),
+			bank.BBVAExtraCodigo:      r.code,
+		},
+	}
+}
+
+func (r *synthRow) write(b *strings.Builder) {
+	b.WriteString(`<tr class="row" data-actionable="">` + "\n" + `<td class="cellDate"><bbva-table-body-date class="operationDate"`)
+	r.opAttr.write(b, "date")
+	r.opYear.write(b, "year")
+	b.WriteString("></bbva-table-body-date></td>\n" + `<td class="cellDate"><bbva-table-body-date class="valueDate"`)
+	r.valAttr.write(b, "date")
+	r.valYear.write(b, "year")
+	fmt.Fprintf(b, `></bbva-table-body-date></td>
+<td class="cellText"><bbva-table-body-text class="code" text="%s"></bbva-table-body-text></td>
+<td class="cellText"><bbva-table-body-text class="numberMovement" text="%s"></bbva-table-body-text></td>
+<td class="cellText"><bbva-table-body-text class="concept" text="%s" description="%s"></bbva-table-body-text></td>
+<td class="cellAmount"><bbva-table-body-amount class="transactionAmount" currency="S/"`,
+		html.EscapeString(r.code), html.EscapeString(r.number),
+		html.EscapeString(r.concept), html.EscapeString(r.beneficiary))
+	r.amountAttr.write(b, "amount")
+	r.balanceAttr.write(b, "secondary-amount")
+	b.WriteString("></bbva-table-body-amount></td>\n</tr>\n")
+}
+
+// synthMonths are the month abbreviations the portal shows.
+var synthMonths = [...]string{"Ene", "Feb", "Mar", "Abr", "May", "Jun", "Jul", "Ago", "Set", "Oct", "Nov", "Dic"}
+
+// bbvaDate returns the date attribute the portal writes for t ("10 Feb").
+func bbvaDate(t time.Time) synthAttr {
+	return synthAttr{v: fmt.Sprintf("%02d %s", t.Day(), synthMonths[t.Month()-1])}
+}
+
+// formatCents writes cents the way the amount attributes do: a plain
+// decimal with a minus sign, no thousands separators and no trailing zeros
+// ("-3.5", "8577.97", "100").
+func formatCents(c int64) synthAttr {
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	s := sign + strconv.FormatInt(c/100, 10)
+	if frac := strings.TrimRight(fmt.Sprintf("%02d", c%100), "0"); frac != "" {
+		s += "." + frac
+	}
+	return synthAttr{v: s}
+}
+
+// synthConcept is a kind of movement, with its amount range in whole soles.
+type synthConcept struct {
+	concept, beneficiary string
+	credit               bool
+	min, max             int64
+}
+
+var synthConcepts = []synthConcept{
+	{"ABONO POR TRASPASO", "Entrega A Rendir                       @", true, 500, 20_000},
+	{"TRANSFERENCIA RECIBIDA", "Comercial Andina Sac", true, 100, 9_000},
+	{"DEPOSITO EN EFECTIVO", "", true, 50, 3_000},
+	{"PAGO FACTURA | SUNAT DETRACCIONES", "*Mp: 20607818054S Com Sunat Detraccione@", false, 1, 2_000},
+	{"PAGO A PROVEEDORES", "Suministros Lima Sac", false, 200, 8_000},
+	{"COMISION DE MANTENIMIENTO", "Comision De Mantenimiento", false, 10, 30},
+	{"NOTA DE CARGO", "*C/Ph4Ob", false, 100, 2_000},
+	{"IMPUESTO ITF", "", false, 0, 1},
+}
+
+// synthEdgeCases alter a row in ways that are unusual but still valid.
+var synthEdgeCases = []func(*rand.Rand, *synthRow){
+	// Smallest amounts.
+	func(_ *rand.Rand, r *synthRow) { r.setAmount(-1) },
+	func(_ *rand.Rand, r *synthRow) { r.setAmount(1) },
+	// Zero, which the parser reads as a debit.
+	func(_ *rand.Rand, r *synthRow) { r.setAmount(0) },
+	// Whole amounts and amounts past a billion.
+	func(rng *rand.Rand, r *synthRow) { r.setAmount(rng.Int64N(10_000) * 100) },
+	func(rng *rand.Rand, r *synthRow) { r.setAmount(-(100_000_000_000 + rng.Int64N(1_000_000))) },
+	// Thousands separators, explicit plus sign and a currency prefix.
+	func(_ *rand.Rand, r *synthRow) {
+		r.amountAttr.v = groupThousands(r.amountAttr.v)
+		r.balanceAttr.v = groupThousands(r.balanceAttr.v)
+	},
+	func(_ *rand.Rand, r *synthRow) {
+		if r.amount > 0 {
+			r.amountAttr.v = "+" + r.amountAttr.v
+		}
+	},
+	func(_ *rand.Rand, r *synthRow) { r.amountAttr.v = "S/ " + r.amountAttr.v },
+	// Negative running balance (overdraft).
+	func(_ *rand.Rand, r *synthRow) { r.balance = -r.balance; r.balanceAttr = formatCents(r.balance) },
+	// Value date in the next month or year.
+	func(_ *rand.Rand, r *synthRow) {
+		r.valDate = time.Date(r.opDate.Year(), r.opDate.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		r.valAttr, r.valYear = bbvaDate(r.valDate), synthAttr{v: strconv.Itoa(r.valDate.Year())}
+	},
+	// Other spellings of the month.
+	func(_ *rand.Rand, r *synthRow) { r.opAttr.v = strings.ToUpper(r.opAttr.v) },
+	func(_ *rand.Rand, r *synthRow) { r.opAttr.v += "." },
+	func(_ *rand.Rand, r *synthRow) { r.opAttr.v = strings.Replace(r.opAttr.v, "Set", "Sep", 1) }, // September only
+	// Text needing escaping, accents, padding, and a very long concept.
+	func(_ *rand.Rand, r *synthRow) { r.concept, r.beneficiary = `PAGO "A&B" <SAC>`, `A&B's Ñandú` },
+	func(_ *rand.Rand, r *synthRow) { r.concept = "  ABONO CAFÉ ÑUÑOA  " },
+	func(_ *rand.Rand, r *synthRow) { r.concept = strings.Repeat("TRANSFERENCIA INTERBANCARIA ", 12) },
+	func(_ *rand.Rand, r *synthRow) { r.code, r.beneficiary = "", "" },
+}
+
+func (r *synthRow) setAmount(c int64) {
+	r.amount, r.amountAttr = c, formatCents(c)
+}
+
+// groupThousands adds comma thousands separators to a formatCents string.
+func groupThousands(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + "," + intPart[i:]
+	}
+	if hasFrac {
+		intPart += "." + frac
+	}
+	return sign + intPart
+}
+
+// synthMalformations break a row so the parser must reject it.
+var synthMalformations = []func(*synthRow){
+	func(r *synthRow) { r.opAttr.drop = true },
+	func(r *synthRow) { r.valAttr.v = "" },
+	func(r *synthRow) { r.opYear.drop = true },
+	func(r *synthRow) { r.valYear.v = "20X6" },
+	func(r *synthRow) { r.opAttr.v += " " + r.opYear.v },
+	func(r *synthRow) { r.opAttr.v = "31 Feb" },
+	func(r *synthRow) { r.valAttr.v = "10 Foo" },
+	func(r *synthRow) { r.amountAttr.drop = true },
+	func(r *synthRow) { r.amountAttr.v = "" },
+	func(r *synthRow) { r.amountAttr.v = "1.234.56" },
+	func(r *synthRow) { r.amountAttr.v = "N/A" },
+	func(r *synthRow) { r.balanceAttr.drop = true },
+	func(r *synthRow) { r.balanceAttr.v = "12,34x" },
+}
@@ -0,0 +1,139 @@
+package bank
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// Navigation steps whose latency scrapers record in Timings.
+const (
+	StepLogin              = "login"               // Login click to the portal, or the bank's answer
+	StepAccountsRender     = "accounts_render"     // Accounts page navigation to rendered balances
+	StepTransactionsRender = "transactions_render" // Account detail to rendered transactions
+	StepPagination         = "pagination"          // "Load more" click to the new rows
+)
+
+// MinTimingSamples is how many observations a step needs before Timings
+// trusts them over a wait's fixed default.
+const MinTimingSamples = 5
+
+// timingWeight is how much a new observation moves a step's mean.
+const timingWeight = 0.2
+
+// StepTiming is the observed latency of one navigation step.
+type StepTiming struct {
+	Samples int           `json:"samples"`
+	Mean    time.Duration `json:"mean_ns"` // Exponentially weighted, favoring recent runs
+	Max     time.Duration `json:"max_ns"`
+}
+
+// TimingProfile is a bank's step timings, keyed by step name.
+type TimingProfile map[string]StepTiming
+
+// Timings learns how long each bank's navigation steps take, from the
+// latencies scrapers observe, and sizes their waits from it instead of
+// fixed sleeps and timeouts: a step the bank renders in 2 seconds needn't
+// be given the scraper's full timeout before retrying. Safe for concurrent
+// use; a nil *Timings records nothing and leaves every wait at its default.
+type Timings struct {
+	mu       sync.Mutex
+	profiles map[Code]TimingProfile
+	dirty    map[Code]bool
+}
+
+// NewTimings creates Timings starting from profiles, e.g. as persisted by
+// an earlier run.
+func NewTimings(profiles map[Code]TimingProfile) *Timings {
+	t := &Timings{profiles: map[Code]TimingProfile{}, dirty: map[Code]bool{}}
+	for code, p := range profiles {
+		t.profiles[code] = maps.Clone(p)
+	}
+	return t
+}
+
+// Observe records that step took d at code's portal.
+func (t *Timings) Observe(code Code, step string, d time.Duration) {
+	if t == nil || d <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.profiles[code]
+	if p == nil {
+		p = TimingProfile{}
+		t.profiles[code] = p
+	}
+	s := p[step]
+	if s.Samples == 0 {
+		s.Mean = d
+	} else {
+		s.Mean += time.Duration(timingWeight * float64(d-s.Mean))
+	}
+	s.Samples++
+	s.Max = max(s.Max, d)
+	p[step] = s
+	t.dirty[code] = true
+}
+
+// Budget returns how long to wait for step at code's portal: three times
+// its mean latency, within [lo, hi]. Until the step has MinTimingSamples
+// observations it is hi, the wait's fixed default.
+func (t *Timings) Budget(code Code, step string, lo, hi time.Duration) time.Duration {
+	s, ok := t.step(code, step)
+	if !ok {
+		return hi
+	}
+	return min(max(3*s.Mean, lo), hi)
+}
+
+// Poll returns how often to check for step's outcome at code's portal: a
+// quarter of its mean latency, within [lo, hi]. Until the step has
+// MinTimingSamples observations it is hi.
+func (t *Timings) Poll(code Code, step string, lo, hi time.Duration) time.Duration {
+	s, ok := t.step(code, step)
+	if !ok {
+		return hi
+	}
+	return min(max(s.Mean/4, lo), hi)
+}
+
+func (t *Timings) step(code Code, step string) (StepTiming, bool) {
+	if t == nil {
+		return StepTiming{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.profiles[code][step]
+	return s, s.Samples >= MinTimingSamples
+}
+
+// Profiles returns a copy of every bank's profile.
+func (t *Timings) Profiles() map[Code]TimingProfile {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[Code]TimingProfile, len(t.profiles))
+	for code, p := range t.profiles {
+		out[code] = maps.Clone(p)
+	}
+	return out
+}
+
+// Changed returns a copy of the profiles observed since the last call, for
+// persisting them.
+func (t *Timings) Changed() map[Code]TimingProfile {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[Code]TimingProfile, len(t.dirty))
+	for code := range t.dirty {
+		out[code] = maps.Clone(t.profiles[code])
+	}
+	clear(t.dirty)
+	return out
+}
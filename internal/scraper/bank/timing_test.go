@@ -0,0 +1,46 @@
+package bank
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimings(t *testing.T) {
+	timings := NewTimings(nil)
+	lo, hi := time.Second, 30*time.Second
+
+	for range MinTimingSamples - 1 {
+		timings.Observe(BankBBVA, StepAccountsRender, 2*time.Second)
+	}
+	assert.Equal(t, hi, timings.Budget(BankBBVA, StepAccountsRender, lo, hi), "too few samples")
+
+	timings.Observe(BankBBVA, StepAccountsRender, 2*time.Second)
+	assert.Equal(t, 6*time.Second, timings.Budget(BankBBVA, StepAccountsRender, lo, hi))
+	assert.Equal(t, 500*time.Millisecond, timings.Poll(BankBBVA, StepAccountsRender, 100*time.Millisecond, time.Second))
+
+	// A slow run moves the mean a fifth of the way.
+	timings.Observe(BankBBVA, StepAccountsRender, 12*time.Second)
+	p := timings.Profiles()[BankBBVA][StepAccountsRender]
+	assert.Equal(t, 6, p.Samples)
+	assert.Equal(t, 4*time.Second, p.Mean)
+	assert.Equal(t, 12*time.Second, p.Max)
+	assert.Equal(t, 12*time.Second, timings.Budget(BankBBVA, StepAccountsRender, lo, hi))
+	assert.Equal(t, 10*time.Second, timings.Budget(BankBBVA, StepAccountsRender, lo, 10*time.Second), "capped")
+
+	assert.Len(t, timings.Changed(), 1)
+	assert.Empty(t, timings.Changed(), "reset")
+}
+
+func TestTimings_Loaded(t *testing.T) {
+	timings := NewTimings(map[Code]TimingProfile{
+		BankBBVA: {StepLogin: {Samples: 10, Mean: 100 * time.Millisecond}},
+	})
+	assert.Equal(t, time.Second, timings.Budget(BankBBVA, StepLogin, time.Second, time.Minute), "floored")
+
+	var none *Timings
+	none.Observe(BankBBVA, StepLogin, time.Second)
+	assert.Equal(t, time.Minute, none.Budget(BankBBVA, StepLogin, time.Second, time.Minute))
+	assert.Nil(t, none.Profiles())
+}
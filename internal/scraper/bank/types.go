@@ -4,44 +4,114 @@ import "time"
 
 // Session represents an authenticated bank session.
 type Session struct {
-	ID        string
-	Code      Code
+	ID   string
+	Code Code
+	// ExpiresAt is the session's hard deadline, however much it is used;
+	// zero when the bank only expires sessions on idle.
 	ExpiresAt time.Time
+	// IdleTimeout is how long the bank keeps the session once it stops
+	// being used, counted from LastActive; zero when it never idles out.
+	IdleTimeout time.Duration
+	LastActive  time.Time
+	// LoginVariant names the login page the bank served when it has more
+	// than one (e.g. BBVA's "senda" and "legacy"); empty when it doesn't.
+	LoginVariant string
+}
+
+// Valid reports whether the session is still usable: before its hard
+// deadline and not idle for longer than IdleTimeout.
+func (s *Session) Valid() bool {
+	if s == nil {
+		return false
+	}
+	expiry := s.Expiry()
+	return expiry.IsZero() || time.Now().Before(expiry)
+}
+
+// Expiry returns when the session lapses if it isn't used again: the
+// earlier of ExpiresAt and IdleTimeout after LastActive. It is zero when
+// the session expires neither way.
+func (s *Session) Expiry() time.Time {
+	expiry := s.ExpiresAt
+	if s.IdleTimeout > 0 {
+		idle := s.LastActive.Add(s.IdleTimeout)
+		if expiry.IsZero() || idle.Before(expiry) {
+			expiry = idle
+		}
+	}
+	return expiry
+}
+
+// Touch records that the session was just used, restarting its idle
+// timeout.
+func (s *Session) Touch() {
+	s.LastActive = time.Now()
 }
 
 // Balance represents the balance of an account for a certain currency
 // it uses int64 and assumes a 2 point precision.
 type Balance struct {
-	AccountID        string
-	Currency         Currency
-	AvailableBalance int64
-	CurrentBalance   int64
-	FetchedAt        time.Time
+	AccountID        string    `json:"account_id"`
+	Currency         Currency  `json:"currency"`
+	AvailableBalance int64     `json:"available_balance"`
+	CurrentBalance   int64     `json:"current_balance"`
+	FetchedAt        time.Time `json:"fetched_at"`
 }
 
 // Transaction represents a transaction for a bank account.
 // it uses int64 for the amount and assumes a 2 point precision.
 type Transaction struct {
 	// Identity
-	ID        string // Bank's document/reference number (e.g., BBVA's N. Doc)
-	Reference string // 	Secondary reference if available (e.g., transfer reference)
+	ID        string `json:"id"`                  // Bank's document/reference number (e.g., BBVA's N. Doc)
+	Reference string `json:"reference,omitempty"` // 	Secondary reference if available (e.g., transfer reference)
 
 	// Dates
-	Date      time.Time // F. Operacion
-	ValueDate time.Time // F. Valor
+	Date      time.Time `json:"date"`       // F. Operacion
+	ValueDate time.Time `json:"value_date"` // F. Valor
 
 	// Transaction details
-	Description string
-	Amount      int64           // Always positive, in cents (2 decimal places)
-	Type        TransactionType // CREDIT (money in) or DEBIT (money out)
+	Description      string          `json:"description"`
+	CleanDescription string          `json:"clean_description,omitempty"` // Normalized Description (codes and account fragments stripped)
+	Amount           int64           `json:"amount"`                      // Always positive, in cents (2 decimal places)
+	Type             TransactionType `json:"type"`                        // CREDIT (money in) or DEBIT (money out)
 
 	// Balance (optional - only populated if bank provides it)
-	BalanceAfter *int64
+	BalanceAfter *int64 `json:"balance_after,omitempty"`
 
-	// Bank-specific metadata (optional)
-	Extra map[string]string // Extra metadata. e.g., Store "Codigo": "015", "Office": "0437"
+	// Bank-specific metadata (optional). Read it through the bank's typed
+	// getter (e.g. BBVAMeta) rather than by key.
+	Extra map[string]string `json:"extra,omitempty"` // Extra metadata. e.g., Store "Codigo": "015", "Oficina": "0437"
 }
 
+// ScheduledOperation is a future-dated operation registered in the portal:
+// a scheduled transfer, a direct debit or a programmed payment. It has not
+// moved money yet, so it is reported separately from Transaction.
+type ScheduledOperation struct {
+	ID            string            `json:"id"`
+	AccountID     string            `json:"account_id"` // Origin (debit) or destination (credit) account
+	Kind          ScheduledKind     `json:"kind"`
+	Type          TransactionType   `json:"type"` // Direction once executed
+	Description   string            `json:"description"`
+	Counterparty  string            `json:"counterparty,omitempty"` // Beneficiary or biller
+	Amount        int64             `json:"amount"`                 // Always positive, in cents
+	Currency      Currency          `json:"currency"`
+	ScheduledDate time.Time         `json:"scheduled_date"`       // Next execution date
+	Recurrence    string            `json:"recurrence,omitempty"` // As shown by the bank, e.g. "Mensual"; empty for one-off
+	Status        string            `json:"status,omitempty"`     // As shown by the bank, e.g. "Pendiente"
+	Extra         map[string]string `json:"extra,omitempty"`
+}
+
+// ScheduledKind classifies a ScheduledOperation.
+type ScheduledKind string
+
+// Scheduled operation kinds.
+const (
+	ScheduledTransfer    ScheduledKind = "TRANSFER"
+	ScheduledDirectDebit ScheduledKind = "DIRECT_DEBIT"
+	ScheduledPayment     ScheduledKind = "PAYMENT"
+	ScheduledOther       ScheduledKind = "OTHER"
+)
+
 // Currency represents a monetary currency code.
 type Currency string
 
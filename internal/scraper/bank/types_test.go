@@ -0,0 +1,50 @@
+package bank
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_Valid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		session *Session
+		want    bool
+	}{
+		{"nil", nil, false},
+		{"no expiry", &Session{}, true},
+		{"before deadline", &Session{ExpiresAt: now.Add(time.Minute)}, true},
+		{"past deadline", &Session{ExpiresAt: now.Add(-time.Minute)}, false},
+		{"recently used", &Session{IdleTimeout: 10 * time.Minute, LastActive: now.Add(-time.Minute)}, true},
+		{"idle too long", &Session{IdleTimeout: 10 * time.Minute, LastActive: now.Add(-11 * time.Minute)}, false},
+		{"used but past deadline", &Session{ExpiresAt: now.Add(-time.Second), IdleTimeout: 10 * time.Minute, LastActive: now}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.session.Valid())
+		})
+	}
+}
+
+func TestSession_Expiry(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(time.Hour)
+
+	assert.True(t, (&Session{}).Expiry().IsZero())
+	assert.Equal(t, deadline, (&Session{ExpiresAt: deadline}).Expiry())
+	assert.Equal(t, now.Add(10*time.Minute), (&Session{IdleTimeout: 10 * time.Minute, LastActive: now}).Expiry())
+	assert.Equal(t, deadline, (&Session{ExpiresAt: deadline, IdleTimeout: 2 * time.Hour, LastActive: now}).Expiry(),
+		"the deadline caps a long idle timeout")
+}
+
+func TestSession_Touch(t *testing.T) {
+	s := &Session{IdleTimeout: 10 * time.Minute, LastActive: time.Now().Add(-11 * time.Minute)}
+	assert.False(t, s.Valid())
+
+	s.Touch()
+
+	assert.True(t, s.Valid())
+}
@@ -0,0 +1,53 @@
+package bank
+
+import (
+	"context"
+	"sync"
+)
+
+// Warning reports data a scraper skipped or could not interpret without
+// failing the operation, e.g. an account card with no amount. A steady
+// trickle of new warnings is usually the first sign the portal changed.
+type Warning struct {
+	Code      Code   `json:"bank_code"`
+	Operation string `json:"operation"`            // Scraper method, e.g. "GetBalance"
+	AccountID string `json:"account_id,omitempty"` // Bank account number, if the warning concerns one
+	Message   string `json:"message"`
+}
+
+// Warnings collects the warnings raised during one or more operations.
+// Safe for concurrent use.
+type Warnings struct {
+	mu   sync.Mutex
+	list []Warning
+}
+
+// Add records w.
+func (ws *Warnings) Add(w Warning) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.list = append(ws.list, w)
+}
+
+// List returns the warnings recorded so far, in order.
+func (ws *Warnings) List() []Warning {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return append([]Warning(nil), ws.list...)
+}
+
+type warningsKey struct{}
+
+// WithWarnings returns a context whose scraper operations record their
+// warnings in ws.
+func WithWarnings(ctx context.Context, ws *Warnings) context.Context {
+	return context.WithValue(ctx, warningsKey{}, ws)
+}
+
+// Warn records w in the collector attached to ctx by WithWarnings. Without
+// one it does nothing, so scrapers can warn unconditionally.
+func Warn(ctx context.Context, w Warning) {
+	if ws, ok := ctx.Value(warningsKey{}).(*Warnings); ok {
+		ws.Add(w)
+	}
+}
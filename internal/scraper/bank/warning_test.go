@@ -0,0 +1,22 @@
+package bank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarn(t *testing.T) {
+	Warn(context.Background(), Warning{Message: "dropped"}) // no collector: no-op
+
+	ws := &Warnings{}
+	ctx := WithWarnings(context.Background(), ws)
+	Warn(ctx, Warning{Code: BankBBVA, Operation: "GetBalance", Message: "card skipped"})
+	Warn(ctx, Warning{Code: BankBBVA, Operation: "GetTransactions", AccountID: "001", Message: "detail missing"})
+
+	got := ws.List()
+	assert.Len(t, got, 2)
+	assert.Equal(t, "card skipped", got[0].Message)
+	assert.Equal(t, "001", got[1].AccountID)
+}
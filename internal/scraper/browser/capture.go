@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// clipboardHookJS records what the page copies, into window.__bankScraperClipboard.
+// Headless Chrome has no system clipboard to read back, so both ways a copy
+// button can write are intercepted: navigator.clipboard.writeText and the
+// copy event behind document.execCommand('copy').
+const clipboardHookJS = `() => {
+	if (window.__bankScraperClipboardHooked) return;
+	window.__bankScraperClipboardHooked = true;
+	window.__bankScraperClipboard = '';
+
+	if (navigator.clipboard && navigator.clipboard.writeText) {
+		const writeText = navigator.clipboard.writeText.bind(navigator.clipboard);
+		navigator.clipboard.writeText = (text) => {
+			window.__bankScraperClipboard = String(text);
+			return writeText(text).catch(() => {});
+		};
+	}
+
+	document.addEventListener('copy', (e) => {
+		const data = e.clipboardData && e.clipboardData.getData('text/plain');
+		window.__bankScraperClipboard = data || String(document.getSelection() || '');
+	}, true);
+}`
+
+// CaptureClipboard starts recording what page copies to the clipboard, on
+// the current document and on every one it navigates to. Call it before
+// clicking a copy button, then read the text with ReadClipboard.
+func CaptureClipboard(page *rod.Page) error {
+	if _, err := page.EvalOnNewDocument("(" + clipboardHookJS + ")()"); err != nil {
+		return fmt.Errorf("install clipboard hook: %w", err)
+	}
+	if _, err := page.Eval(clipboardHookJS); err != nil {
+		return fmt.Errorf("install clipboard hook: %w", err)
+	}
+	return nil
+}
+
+// ReadClipboard returns the text page last copied since CaptureClipboard, or
+// "" if it copied nothing.
+func ReadClipboard(page *rod.Page) (string, error) {
+	result, err := page.Eval(`() => window.__bankScraperClipboard || ''`)
+	if err != nil {
+		return "", fmt.Errorf("read clipboard: %w", err)
+	}
+	return result.Value.Str(), nil
+}
+
+// PrintPDF renders page as the browser would print it, backgrounds included,
+// for archiving printable views such as statements and vouchers.
+func PrintPDF(page *rod.Page) ([]byte, error) {
+	r, err := page.PDF(&proto.PagePrintToPDF{PrintBackground: true})
+	if err != nil {
+		return nil, fmt.Errorf("print to PDF: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read PDF: %w", err)
+	}
+	return data, nil
+}
+
+// RenderPDF prints an HTML document with a headless browser at bin (rod's
+// lookup when empty), for reports generated outside the bank portals.
+func RenderPDF(bin, html string) ([]byte, error) {
+	url, err := NewLauncher(bin, true).Launch()
+	if err != nil {
+		return nil, fmt.Errorf("launch browser: %w", err)
+	}
+	bro := rod.New().ControlURL(url)
+	if err := bro.Connect(); err != nil {
+		return nil, fmt.Errorf("connect to browser: %w", err)
+	}
+	defer func() { _ = bro.Close() }()
+
+	page, err := bro.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, err
+	}
+	if err := page.SetDocumentContent(html); err != nil {
+		return nil, fmt.Errorf("load document: %w", err)
+	}
+	return PrintPDF(page)
+}
@@ -0,0 +1,37 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureClipboard(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => {
+		document.body.innerHTML = '<span id="acct">0011-0123-0100012345</span>' +
+			'<button id="copy" onclick="navigator.clipboard.writeText(document.getElementById(\'acct\').textContent)">Copiar</button>';
+	}`)
+
+	require.NoError(t, CaptureClipboard(page))
+	text, err := ReadClipboard(page)
+	require.NoError(t, err)
+	assert.Empty(t, text)
+
+	page.MustElement("#copy").MustClick()
+	text, err = ReadClipboard(page)
+	require.NoError(t, err)
+	assert.Equal(t, "0011-0123-0100012345", text)
+}
+
+func TestPrintPDF(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => { document.body.innerHTML = '<h1>Estado de cuenta</h1>'; }`)
+
+	data, err := PrintPDF(page)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF", string(data[:4]))
+}
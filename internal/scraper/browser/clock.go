@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ServerClock tracks a site's clock from the Date header of its responses,
+// so a wrong host clock can be told apart from a bank that shows the wrong
+// days. Safe for concurrent use.
+type ServerClock struct {
+	host string
+	now  func() time.Time
+
+	mu     sync.Mutex
+	server time.Time // Date of the latest response
+	local  time.Time // When it arrived
+}
+
+// NewServerClock returns a ServerClock reading the responses of host and its
+// subdomains.
+func NewServerClock(host string) *ServerClock {
+	return &ServerClock{host: host, now: time.Now}
+}
+
+// Track wraps next so the Date header of each response it loads from the
+// clock's host is recorded. A nil clock returns next unchanged.
+func (c *ServerClock) Track(next func(*rod.Hijack)) func(*rod.Hijack) {
+	if c == nil {
+		return next
+	}
+	return func(h *rod.Hijack) {
+		next(h)
+		host := h.Request.URL().Hostname()
+		if host != c.host && !strings.HasSuffix(host, "."+c.host) {
+			return
+		}
+		if date := headerValue(h.Response.Payload().ResponseHeaders, "Date"); date != "" {
+			c.observe(date, c.now())
+		}
+	}
+}
+
+// observe records a Date header value received at local. Unparseable values
+// are ignored.
+func (c *ServerClock) observe(date string, local time.Time) {
+	server, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.server, c.local = server, local
+}
+
+// Reading returns the server time of the latest response and the local time
+// it arrived at; ok is false before any response. Date headers have
+// one-second resolution, so skews under a second are noise.
+func (c *ServerClock) Reading() (server, local time.Time, ok bool) {
+	if c == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.server, c.local, !c.server.IsZero()
+}
+
+func headerValue(headers []*proto.FetchHeaderEntry, name string) string {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package browser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerClock_Observe(t *testing.T) {
+	c := NewServerClock("bank.example")
+	_, _, ok := c.Reading()
+	assert.False(t, ok)
+
+	local := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	c.observe("Mon, 02 Mar 2026 15:04:30 GMT", local)
+	c.observe("not a date", local.Add(time.Minute))
+
+	server, gotLocal, ok := c.Reading()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 3, 2, 15, 4, 30, 0, time.UTC), server)
+	assert.Equal(t, local, gotLocal)
+}
+
+func TestServerClock_NilReading(t *testing.T) {
+	var c *ServerClock
+	_, _, ok := c.Reading()
+	assert.False(t, ok)
+}
+
+func TestHeaderValue(t *testing.T) {
+	headers := []*proto.FetchHeaderEntry{{Name: "content-type", Value: "text/html"}, {Name: "date", Value: "x"}}
+	assert.Equal(t, "x", headerValue(headers, "Date"))
+	assert.Empty(t, headerValue(headers, "Server"))
+}
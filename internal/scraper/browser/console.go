@@ -0,0 +1,156 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxConsoleEntries bounds a ConsoleLog between drains. A broken portal
+// script can log the same error on every animation frame.
+const maxConsoleEntries = 100
+
+// Console entry levels.
+const (
+	ConsoleError     = "error"     // console.error (and console.assert failures)
+	ConsoleException = "exception" // uncaught JS exception
+)
+
+// ConsoleEntry is one error a page logged or threw.
+type ConsoleEntry struct {
+	Level   string
+	Message string
+	URL     string // Script that logged or threw, when known
+	Count   int    // Identical entries folded into this one
+}
+
+func (e ConsoleEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", e.Level, e.Message)
+	if e.URL != "" {
+		fmt.Fprintf(&b, " (%s)", e.URL)
+	}
+	if e.Count > 1 {
+		fmt.Fprintf(&b, " ×%d", e.Count)
+	}
+	return b.String()
+}
+
+// ConsoleLog collects the console errors and uncaught exceptions of a page,
+// iframes in the same process included. Portal-side JS failures often
+// explain why a selector never appears. Safe for concurrent use.
+type ConsoleLog struct {
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	entries []ConsoleEntry
+	dropped int
+}
+
+// CaptureConsole starts collecting page's errors until Stop.
+func CaptureConsole(page *rod.Page) *ConsoleLog {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &ConsoleLog{cancel: cancel}
+	wait := page.Context(ctx).EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+		if e.Type == proto.RuntimeConsoleAPICalledTypeError || e.Type == proto.RuntimeConsoleAPICalledTypeAssert {
+			l.add(consoleEntry(e))
+		}
+	}, func(e *proto.RuntimeExceptionThrown) {
+		l.add(exceptionEntry(e))
+	})
+	go wait()
+	return l
+}
+
+// Stop ends collection. Entries not yet drained are kept.
+func (l *ConsoleLog) Stop() {
+	if l != nil {
+		l.cancel()
+	}
+}
+
+// Drain returns the entries collected since the previous Drain, identical
+// ones folded together, and how many were dropped over the cap.
+func (l *ConsoleLog) Drain() (entries []ConsoleEntry, dropped int) {
+	if l == nil {
+		return nil, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries, dropped = l.entries, l.dropped
+	l.entries, l.dropped = nil, 0
+	return entries, dropped
+}
+
+func (l *ConsoleLog) add(e ConsoleEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.entries {
+		if prev := &l.entries[i]; prev.Level == e.Level && prev.Message == e.Message && prev.URL == e.URL {
+			prev.Count++
+			return
+		}
+	}
+	if len(l.entries) == maxConsoleEntries {
+		l.dropped++
+		return
+	}
+	e.Count = 1
+	l.entries = append(l.entries, e)
+}
+
+func consoleEntry(e *proto.RuntimeConsoleAPICalled) ConsoleEntry {
+	parts := make([]string, 0, len(e.Args))
+	for _, arg := range e.Args {
+		parts = append(parts, remoteObjectString(arg))
+	}
+	return ConsoleEntry{
+		Level:   ConsoleError,
+		Message: strings.Join(parts, " "),
+		URL:     stackURL(e.StackTrace),
+	}
+}
+
+func exceptionEntry(e *proto.RuntimeExceptionThrown) ConsoleEntry {
+	d := e.ExceptionDetails
+	if d == nil {
+		return ConsoleEntry{Level: ConsoleException, Message: "unknown exception"}
+	}
+	msg := d.Text
+	// The description carries the error's own message ("TypeError: ...")
+	// followed by the stack; the text is just "Uncaught".
+	if d.Exception != nil && d.Exception.Description != "" {
+		desc, _, _ := strings.Cut(d.Exception.Description, "\n")
+		msg = strings.TrimSpace(msg + " " + desc)
+	}
+	url := d.URL
+	if url == "" {
+		url = stackURL(d.StackTrace)
+	}
+	return ConsoleEntry{Level: ConsoleException, Message: msg, URL: url}
+}
+
+func remoteObjectString(o *proto.RuntimeRemoteObject) string {
+	if o == nil {
+		return ""
+	}
+	if o.Type == proto.RuntimeRemoteObjectTypeString {
+		return o.Value.Str()
+	}
+	if o.Description != "" {
+		desc, _, _ := strings.Cut(o.Description, "\n")
+		return desc
+	}
+	return o.Value.JSON("", "")
+}
+
+func stackURL(st *proto.RuntimeStackTrace) string {
+	if st == nil || len(st.CallFrames) == 0 {
+		return ""
+	}
+	return st.CallFrames[0].URL
+}
@@ -0,0 +1,55 @@
+package browser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleEntry(t *testing.T) {
+	var evt proto.RuntimeConsoleAPICalled
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "error",
+		"args": [
+			{"type": "string", "value": "grantingTicket failed:"},
+			{"type": "number", "value": 403, "description": "403"}
+		],
+		"executionContextId": 1,
+		"timestamp": 0,
+		"stackTrace": {"callFrames": [{"functionName": "", "scriptId": "1", "url": "https://bbvanetcash.pe/app.js", "lineNumber": 0, "columnNumber": 0}]}
+	}`), &evt))
+
+	e := consoleEntry(&evt)
+	assert.Equal(t, ConsoleEntry{Level: ConsoleError, Message: "grantingTicket failed: 403", URL: "https://bbvanetcash.pe/app.js"}, e)
+}
+
+func TestExceptionEntry(t *testing.T) {
+	e := exceptionEntry(&proto.RuntimeExceptionThrown{ExceptionDetails: &proto.RuntimeExceptionDetails{
+		Text:      "Uncaught",
+		URL:       "https://bbvanetcash.pe/cells.js",
+		Exception: &proto.RuntimeRemoteObject{Description: "TypeError: Cannot read properties of undefined (reading 'accounts')\n    at render (cells.js:1:2)"},
+	}})
+	assert.Equal(t, "Uncaught TypeError: Cannot read properties of undefined (reading 'accounts')", e.Message)
+	assert.Equal(t, "https://bbvanetcash.pe/cells.js", e.URL)
+}
+
+func TestConsoleLog_FoldsAndCaps(t *testing.T) {
+	l := &ConsoleLog{}
+	l.add(ConsoleEntry{Level: ConsoleError, Message: "boom"})
+	l.add(ConsoleEntry{Level: ConsoleError, Message: "boom"})
+	for i := range maxConsoleEntries + 2 {
+		l.add(ConsoleEntry{Level: ConsoleException, Message: string(rune('a' + i%26)), URL: string(rune(i))})
+	}
+
+	entries, dropped := l.Drain()
+	assert.Len(t, entries, maxConsoleEntries)
+	assert.Equal(t, 3, dropped)
+	assert.Equal(t, "error: boom ×2", entries[0].String())
+
+	entries, dropped = l.Drain()
+	assert.Empty(t, entries)
+	assert.Zero(t, dropped)
+}
@@ -0,0 +1,148 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Download errors. Match them with errors.Is.
+var (
+	ErrDownloadTimeout = errors.New("download did not finish in time")
+	ErrDownloadFailed  = errors.New("download canceled or failed")
+)
+
+// DefaultDownloadTimeout bounds one Downloader.Download unless WithTimeout
+// says otherwise. Statement exports are generated server-side and can take a
+// while.
+const DefaultDownloadTimeout = 2 * time.Minute
+
+// DownloadedFile is a file the browser downloaded.
+type DownloadedFile struct {
+	Name string // Filename the server suggested, e.g. "movimientos.xlsx"
+	URL  string
+	Data []byte
+}
+
+// Downloader captures files a page downloads, such as statement and Excel
+// exports. It points the browser's downloads at its own directory and reads
+// each file back once Chrome reports it complete.
+//
+// With a Remote browser, dir must be the container's view of a volume shared
+// with the host.
+type Downloader struct {
+	browser *rod.Browser
+	dir     string
+	owned   bool // dir was created by NewDownloader and is removed by Close
+	timeout time.Duration
+}
+
+// NewDownloader routes b's downloads into dir, or into a fresh temporary
+// directory when dir is empty. Close restores the browser's default.
+func NewDownloader(b *rod.Browser, dir string) (*Downloader, error) {
+	d := &Downloader{browser: b, dir: dir, timeout: DefaultDownloadTimeout}
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "bank-scraper-download-")
+		if err != nil {
+			return nil, fmt.Errorf("create download dir: %w", err)
+		}
+		d.dir, d.owned = tmp, true
+	} else if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create download dir: %w", err)
+	}
+
+	// AllowAndName saves each file under its GUID, so concurrent or repeated
+	// exports with the same suggested name don't collide.
+	err := proto.BrowserSetDownloadBehavior{
+		Behavior:      proto.BrowserSetDownloadBehaviorBehaviorAllowAndName,
+		DownloadPath:  d.dir,
+		EventsEnabled: true,
+	}.Call(b)
+	if err != nil {
+		_ = d.removeDir()
+		return nil, fmt.Errorf("set download behavior: %w", err)
+	}
+	return d, nil
+}
+
+// WithTimeout sets how long Download waits for a file. Default is
+// DefaultDownloadTimeout.
+func (d *Downloader) WithTimeout(timeout time.Duration) *Downloader {
+	d.timeout = timeout
+	return d
+}
+
+// Dir is where downloads are saved.
+func (d *Downloader) Dir() string {
+	return d.dir
+}
+
+// Download runs trigger (typically a click on an export button) and waits
+// for the download it starts to complete. The file is read into memory and
+// removed from disk.
+func (d *Downloader) Download(ctx context.Context, trigger func() error) (*DownloadedFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	var (
+		begin *proto.BrowserDownloadWillBegin
+		state proto.BrowserDownloadProgressState
+	)
+	wait := d.browser.Context(ctx).EachEvent(func(e *proto.BrowserDownloadWillBegin) {
+		if begin == nil {
+			begin = e
+		}
+	}, func(e *proto.BrowserDownloadProgress) bool {
+		if begin == nil || e.GUID != begin.GUID {
+			return false
+		}
+		state = e.State
+		return state != proto.BrowserDownloadProgressStateInProgress
+	})
+
+	if err := trigger(); err != nil {
+		return nil, fmt.Errorf("start download: %w", err)
+	}
+	wait()
+
+	switch {
+	case begin == nil:
+		return nil, fmt.Errorf("%w: no download started within %s", ErrDownloadTimeout, d.timeout)
+	case state == proto.BrowserDownloadProgressStateCanceled:
+		return nil, fmt.Errorf("%w: %s", ErrDownloadFailed, begin.SuggestedFilename)
+	case state != proto.BrowserDownloadProgressStateCompleted:
+		_ = os.Remove(filepath.Join(d.dir, begin.GUID))
+		return nil, fmt.Errorf("%w: %s still in progress after %s", ErrDownloadTimeout, begin.SuggestedFilename, d.timeout)
+	}
+
+	path := filepath.Join(d.dir, begin.GUID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read download: %w", err)
+	}
+	_ = os.Remove(path)
+
+	return &DownloadedFile{Name: begin.SuggestedFilename, URL: begin.URL, Data: data}, nil
+}
+
+// Close restores the browser's default download behavior and removes the
+// temporary directory, if NewDownloader created one.
+func (d *Downloader) Close() error {
+	err := proto.BrowserSetDownloadBehavior{
+		Behavior: proto.BrowserSetDownloadBehaviorBehaviorDefault,
+	}.Call(d.browser)
+	return errors.Join(err, d.removeDir())
+}
+
+func (d *Downloader) removeDir() error {
+	if !d.owned {
+		return nil
+	}
+	return os.RemoveAll(d.dir)
+}
@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloader_Download(t *testing.T) {
+	page := setupPage(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="movimientos.csv"`)
+		_, _ = w.Write([]byte("fecha,monto\n2024-01-15,100.00\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	d, err := NewDownloader(page.Browser(), "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = d.Close() })
+
+	file, err := d.Download(t.Context(), func() error {
+		return page.Navigate(srv.URL + "/export")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "movimientos.csv", file.Name)
+	assert.Equal(t, "fecha,monto\n2024-01-15,100.00\n", string(file.Data))
+}
+
+func TestDownloader_Timeout(t *testing.T) {
+	page := setupPage(t)
+
+	d, err := NewDownloader(page.Browser(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = d.Close() })
+
+	_, err = d.WithTimeout(200*time.Millisecond).Download(t.Context(), func() error { return nil })
+	assert.ErrorIs(t, err, ErrDownloadTimeout)
+
+	_, err = d.Download(t.Context(), func() error { return errors.New("button not found") })
+	assert.ErrorContains(t, err, "start download: button not found")
+}
@@ -0,0 +1,65 @@
+package browser
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+)
+
+// Human is the randomness behind human-like input: the delays between
+// keystrokes and the pauses before clicks. A Human made with a fixed seed
+// produces the same delays every run, so replay tests of these code paths
+// are reproducible. Safe for concurrent use.
+type Human struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	// Sleep waits out each delay; time.Sleep when nil. Tests set it to
+	// record the delays instead of waiting.
+	Sleep func(time.Duration)
+}
+
+// NewHuman returns a Human whose delays follow seed.
+func NewHuman(seed uint64) *Human {
+	return &Human{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// RandomHuman returns a Human with a random seed, for live runs.
+func RandomHuman() *Human {
+	return NewHuman(rand.Uint64())
+}
+
+// Duration returns a random duration in [lo, hi).
+func (h *Human) Duration(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return lo + time.Duration(h.rng.Int64N(int64(hi-lo)))
+}
+
+// Pause sleeps for a random duration in [lo, hi).
+func (h *Human) Pause(lo, hi time.Duration) {
+	d := h.Duration(lo, hi)
+	if h.Sleep != nil {
+		h.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// Type types text into el one key at a time, pausing 50-150ms between
+// keystrokes. Element.Type triggers proper keyboard events (keydown/keyup).
+func (h *Human) Type(el *rod.Element, text string) error {
+	for _, char := range text {
+		if err := el.Type(input.Key(char)); err != nil {
+			return err
+		}
+		h.Pause(50*time.Millisecond, 150*time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package browser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHuman_Seeded(t *testing.T) {
+	record := func(h *Human) []time.Duration {
+		var slept []time.Duration
+		h.Sleep = func(d time.Duration) { slept = append(slept, d) }
+		for range 20 {
+			h.Pause(50*time.Millisecond, 150*time.Millisecond)
+		}
+		return slept
+	}
+	a, b := record(NewHuman(7)), record(NewHuman(7))
+	assert.Equal(t, a, b, "same seed, same delays")
+	assert.NotEqual(t, a, record(NewHuman(8)))
+	for _, d := range a {
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.Less(t, d, 150*time.Millisecond)
+	}
+
+	assert.Equal(t, time.Second, NewHuman(1).Duration(time.Second, time.Second), "empty range")
+}
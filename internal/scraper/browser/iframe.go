@@ -2,55 +2,150 @@
 package browser
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-rod/rod"
 )
 
-// WaitForIFrames recursively waits for DOM stability on all visible iframes.
-// This ensures that iframe content is fully loaded before interaction.
-func WaitForIFrames(page *rod.Page) {
-	page.MustWaitDOMStable()
+// MaxIFrameDepth is how many levels of nested iframes WaitForIFrames
+// descends into. BBVA's portal nests three.
+const MaxIFrameDepth = 5
 
-	iframes, err := page.Elements("iframe")
+// IFrameWaitSummary counts what WaitForIFrames waited on and skipped.
+type IFrameWaitSummary struct {
+	Settled     int // Frames whose DOM went stable, the page included
+	Hidden      int // Invisible iframes, skipped
+	Unreachable int // Iframes whose document could not be read, as cross-origin ones
+	TooDeep     int // Iframes below MaxIFrameDepth, skipped
+}
+
+func (s IFrameWaitSummary) String() string {
+	return fmt.Sprintf("%d settled, %d hidden, %d unreachable, %d too deep",
+		s.Settled, s.Hidden, s.Unreachable, s.TooDeep)
+}
+
+// WaitForIFrames waits for DOM stability on page and, recursively, on its
+// visible iframes up to MaxIFrameDepth levels down, so that iframe content
+// is loaded before interaction. Iframes that cannot be entered are skipped
+// and counted. ctx bounds the whole wait: a frame that never stops mutating
+// ends it with ctx's error, returned alongside the summary so far.
+func WaitForIFrames(ctx context.Context, page *rod.Page) (IFrameWaitSummary, error) {
+	var s IFrameWaitSummary
+	err := waitForIFrames(ctx, page, 0, &s)
+	return s, err
+}
+
+func waitForIFrames(ctx context.Context, frame *rod.Page, depth int, s *IFrameWaitSummary) error {
+	frame = frame.Context(ctx)
+	if err := frame.WaitDOMStable(time.Second, 0); err != nil {
+		return fmt.Errorf("wait for DOM stability at depth %d: %w", depth, err)
+	}
+	s.Settled++
+
+	iframes, err := frame.Elements("iframe")
 	if err != nil {
-		return
+		return fmt.Errorf("list iframes at depth %d: %w", depth, err)
 	}
 
 	for _, iframe := range iframes {
-		visible, _ := iframe.Visible()
-		if !visible {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if visible, _ := iframe.Visible(); !visible {
+			s.Hidden++
+			continue
+		}
+		if depth+1 > MaxIFrameDepth {
+			s.TooDeep++
 			continue
 		}
 
-		frame, err := iframe.Frame()
+		child, err := iframe.Frame()
 		if err != nil {
+			s.Unreachable++
 			continue
 		}
-
-		WaitForIFrames(frame)
+		if err := waitForIFrames(ctx, child, depth+1, s); err != nil {
+			// Only running out of time ends the wait; a frame that navigated
+			// away or is out of process is skipped like any unreachable one.
+			if ctx.Err() != nil {
+				return err
+			}
+			s.Unreachable++
+		}
 	}
+	return nil
+}
+
+// FrameInfo describes a visible iframe found by VisibleLeafFrames.
+type FrameInfo struct {
+	Frame  *rod.Page // The iframe's document, for element interaction
+	Src    string
+	Name   string
+	Width  float64 // CSS pixels
+	Height float64
+	Depth  int // 1 for an iframe of the page itself
 }
 
-// GetDeepestVisibleFrame recursively navigates into the deepest visible iframe.
-// Returns the innermost page/frame context for element interaction.
-// If no visible iframe is found, returns the original page.
-func GetDeepestVisibleFrame(page *rod.Page) *rod.Page {
-	page.MustWaitDOMStable()
+// VisibleLeafFrames returns every visible iframe, at any depth up to
+// MaxIFrameDepth, that contains no visible iframe of its own, in document
+// order. Callers pick one by Src, Name or size instead of relying on which
+// sibling happens to come first. Iframes whose document cannot be read are
+// left out. Call WaitForIFrames first for frames still loading.
+func VisibleLeafFrames(page *rod.Page) ([]FrameInfo, error) {
+	var leaves []FrameInfo
+	if _, err := visibleLeafFrames(page, 1, &leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
 
-	iframes, err := page.Elements("iframe")
+// visibleLeafFrames appends the visible leaf iframes under frame to leaves
+// and reports whether frame had any visible iframe at all.
+func visibleLeafFrames(frame *rod.Page, depth int, leaves *[]FrameInfo) (bool, error) {
+	iframes, err := frame.Elements("iframe")
 	if err != nil {
-		return page
+		return false, fmt.Errorf("list iframes at depth %d: %w", depth-1, err)
 	}
 
+	found := false
 	for _, iframe := range iframes {
-		if visible, _ := iframe.Visible(); visible {
-			child := iframe.MustFrame()
-			return GetDeepestVisibleFrame(child)
+		if visible, _ := iframe.Visible(); !visible {
+			continue
+		}
+		found = true
+
+		child, err := iframe.Frame()
+		if err != nil {
+			continue
+		}
+		if depth < MaxIFrameDepth {
+			// A frame whose iframes can't be listed is treated as a leaf.
+			if nested, err := visibleLeafFrames(child, depth+1, leaves); err == nil && nested {
+				continue
+			}
 		}
+		*leaves = append(*leaves, frameInfo(iframe, child, depth))
 	}
+	return found, nil
+}
 
-	return page
+func frameInfo(iframe *rod.Element, frame *rod.Page, depth int) FrameInfo {
+	info := FrameInfo{Frame: frame, Depth: depth}
+	if v, err := iframe.Attribute("src"); err == nil && v != nil {
+		info.Src = *v
+	}
+	if v, err := iframe.Attribute("name"); err == nil && v != nil {
+		info.Name = *v
+	}
+	if shape, err := iframe.Shape(); err == nil {
+		if box := shape.Box(); box != nil {
+			info.Width, info.Height = box.Width, box.Height
+		}
+	}
+	return info
 }
 
 // GetIFrameBySelector returns the frame context for a specific iframe selector.
@@ -0,0 +1,71 @@
+package browser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForIFrames_Summary(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => {
+		document.body.innerHTML =
+			'<iframe id="a" srcdoc="<iframe srcdoc=\'<p>inner</p>\'></iframe>"></iframe>' +
+			'<iframe id="hidden" style="display:none" srcdoc="<p>hidden</p>"></iframe>';
+	}`)
+	page.MustElement("#a").MustFrame().MustElement("iframe")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	summary, err := WaitForIFrames(ctx, page)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Settled)
+	assert.Equal(t, 1, summary.Hidden)
+	assert.Zero(t, summary.Unreachable)
+}
+
+func TestWaitForIFrames_MutatingFrameTimesOut(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => {
+		setInterval(() => { document.body.textContent = String(Math.random()); }, 100);
+	}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := WaitForIFrames(ctx, page)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestVisibleLeafFrames_Siblings(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => {
+		document.body.innerHTML =
+			'<iframe name="outer" srcdoc="<iframe name=\'inner\' width=\'200\' height=\'100\' srcdoc=\'<p>a</p>\'></iframe>"></iframe>' +
+			'<iframe name="sibling" width="300" height="150" srcdoc="<p>b</p>"></iframe>' +
+			'<iframe name="hidden" style="display:none" srcdoc="<p>c</p>"></iframe>';
+	}`)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := WaitForIFrames(ctx, page)
+	require.NoError(t, err)
+
+	leaves, err := VisibleLeafFrames(page)
+
+	require.NoError(t, err)
+	require.Len(t, leaves, 2)
+	assert.Equal(t, "inner", leaves[0].Name)
+	assert.Equal(t, 2, leaves[0].Depth)
+	assert.Equal(t, 200.0, leaves[0].Width)
+	assert.Equal(t, "sibling", leaves[1].Name)
+	assert.Equal(t, 1, leaves[1].Depth)
+	assert.Equal(t, 150.0, leaves[1].Height)
+	assert.Contains(t, leaves[1].Frame.MustElement("p").MustText(), "b")
+}
@@ -0,0 +1,90 @@
+package browser
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Script is JavaScript to run in matching HTML documents before any of
+// their own scripts, for shims a flow needs under replay (e.g. stubbing a
+// postMessage handshake the recording can't reproduce).
+type Script struct {
+	Name   string // Shown in the injected tag, for telling shims apart in DevTools
+	Match  string // Substring of the document URL; empty matches every document
+	Source string
+}
+
+// InjectScripts wraps next so the HTML documents it serves, iframes
+// included, get scripts inserted at the top of <head>. Unlike
+// Page.EvalOnNewDocument this reaches cross-origin iframes, since every
+// document passes through the hijacker. Other responses are left alone.
+func InjectScripts(next func(*rod.Hijack), scripts []Script) func(*rod.Hijack) {
+	if len(scripts) == 0 {
+		return next
+	}
+	return func(h *rod.Hijack) {
+		next(h)
+		if h.Request.Type() != proto.NetworkResourceTypeDocument {
+			return
+		}
+		payload := h.Response.Payload()
+		if payload.ResponseCode < 200 || payload.ResponseCode >= 300 || !isHTML(payload.ResponseHeaders) {
+			return
+		}
+		url := h.Request.URL().String()
+		var matched []Script
+		for _, sc := range scripts {
+			if strings.Contains(url, sc.Match) {
+				matched = append(matched, sc)
+			}
+		}
+		if len(matched) > 0 {
+			payload.Body = InjectHTML(payload.Body, matched)
+		}
+	}
+}
+
+func isHTML(headers []*proto.FetchHeaderEntry) bool {
+	return strings.Contains(strings.ToLower(headerValue(headers, "Content-Type")), "text/html")
+}
+
+// InjectHTML returns doc with a <script> tag per script right after the
+// opening <head> tag, or <html> when there's no head, or at the very start.
+func InjectHTML(doc []byte, scripts []Script) []byte {
+	var tags bytes.Buffer
+	for _, sc := range scripts {
+		tags.WriteString(`<script data-injected="` + strings.ReplaceAll(sc.Name, `"`, "") + `">`)
+		// A literal "</script" would end the tag early.
+		tags.WriteString(strings.ReplaceAll(sc.Source, "</script", `<\/script`))
+		tags.WriteString("</script>")
+	}
+
+	at := 0
+	lower := bytes.ToLower(doc)
+	for _, tag := range []string{"<head", "<html"} {
+		i := bytes.Index(lower, []byte(tag))
+		if i < 0 {
+			continue
+		}
+		// The tag name must end there: <header> is not <head>.
+		if next := i + len(tag); next < len(lower) && !isTagNameEnd(lower[next]) {
+			continue
+		}
+		if end := bytes.IndexByte(lower[i:], '>'); end >= 0 {
+			at = i + end + 1
+			break
+		}
+	}
+
+	out := make([]byte, 0, len(doc)+tags.Len())
+	out = append(out, doc[:at]...)
+	out = append(out, tags.Bytes()...)
+	return append(out, doc[at:]...)
+}
+
+func isTagNameEnd(b byte) bool {
+	return b == '>' || b == '/' || b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
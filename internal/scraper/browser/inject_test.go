@@ -0,0 +1,45 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectHTML(t *testing.T) {
+	shim := []Script{{Name: "shim", Source: "window.x = 1;"}}
+	const tag = `<script data-injected="shim">window.x = 1;</script>`
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"head", `<html><head lang="es"><title>t</title></head></html>`, `<html><head lang="es">` + tag + `<title>t</title></head></html>`},
+		{"uppercase", `<HTML><HEAD><script src="app.js"></script></HEAD>`, `<HTML><HEAD>` + tag + `<script src="app.js"></script></HEAD>`},
+		{"no head", `<!doctype html><html><body></body></html>`, `<!doctype html><html>` + tag + `<body></body></html>`},
+		{"header is not head", `<html><body><header></header></body></html>`, `<html>` + tag + `<body><header></header></body></html>`},
+		{"fragment", `<div>hi</div>`, tag + `<div>hi</div>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(InjectHTML([]byte(tt.doc), shim)))
+		})
+	}
+}
+
+func TestInjectHTML_EscapesClosingTag(t *testing.T) {
+	got := InjectHTML([]byte("<head></head>"), []Script{
+		{Name: `a"b`, Source: `document.write("</script>")`},
+		{Name: "second", Source: "2"},
+	})
+
+	assert.Equal(t, `<head><script data-injected="ab">document.write("<\/script>")</script><script data-injected="second">2</script></head>`, string(got))
+}
+
+func TestIsHTML(t *testing.T) {
+	assert.True(t, isHTML([]*proto.FetchHeaderEntry{{Name: "content-type", Value: "Text/HTML; charset=utf-8"}}))
+	assert.False(t, isHTML([]*proto.FetchHeaderEntry{{Name: "Content-Type", Value: "application/json"}}))
+	assert.False(t, isHTML(nil))
+}
@@ -0,0 +1,173 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ErrVirtualKeyNotFound is returned when a virtual keyboard has no key for a
+// character of the input. The character itself is never included: the input
+// is usually a password.
+var ErrVirtualKeyNotFound = errors.New("virtual keyboard key not found")
+
+// Point is a position in CSS pixels relative to the keyboard element's
+// top-left corner.
+type Point struct {
+	X, Y float64
+}
+
+// VirtualKeyboard types on an on-screen keyboard, as Interbank and BCP use
+// for passwords, by clicking its keys. Keys are found, in order of
+// preference, by:
+//
+//   - Keys: a fixed selector per character;
+//   - KeySelector: every key element, labeled by LabelAttr or its text (or
+//     an <img> key's alt), for layouts that shuffle on each load;
+//   - Locate: positions matched on a screenshot of Container, for keys that
+//     are bare images.
+type VirtualKeyboard struct {
+	Keys map[rune]string
+
+	KeySelector string
+	LabelAttr   string // Attribute holding a key's character; empty reads the text, then alt
+	Reshuffles  bool   // The layout shuffles after every click; find keys again each time
+
+	Container string                                          // Keyboard element, for Locate's screenshot
+	Locate    func(screenshot []byte) (map[rune]Point, error) // Matches key images to positions
+
+	Delay time.Duration // Pause between clicks
+}
+
+// Type clicks the key of each character of text in turn.
+func (k VirtualKeyboard) Type(page *rod.Page, text string) error {
+	var keys map[rune]*rod.Element
+	var points map[rune]Point
+	var origin *proto.Point
+
+	for i, ch := range []rune(text) {
+		if i > 0 && k.Delay > 0 {
+			time.Sleep(k.Delay)
+		}
+
+		if sel, ok := k.Keys[ch]; ok {
+			el, err := page.Element(sel)
+			if err != nil {
+				return fmt.Errorf("character %d: %w", i+1, err)
+			}
+			if err := el.Click(proto.InputMouseButtonLeft, 1); err != nil {
+				return fmt.Errorf("click key for character %d: %w", i+1, err)
+			}
+			continue
+		}
+
+		if k.KeySelector != "" {
+			if keys == nil || k.Reshuffles {
+				var err error
+				if keys, err = k.scanKeys(page); err != nil {
+					return err
+				}
+			}
+			if el, ok := keys[ch]; ok {
+				if err := el.Click(proto.InputMouseButtonLeft, 1); err != nil {
+					return fmt.Errorf("click key for character %d: %w", i+1, err)
+				}
+				continue
+			}
+		}
+
+		if k.Locate != nil {
+			if points == nil || k.Reshuffles {
+				var err error
+				if points, origin, err = k.locateKeys(page); err != nil {
+					return err
+				}
+			}
+			if pt, ok := points[ch]; ok {
+				if err := clickAt(page, proto.Point{X: origin.X + pt.X, Y: origin.Y + pt.Y}); err != nil {
+					return fmt.Errorf("click key for character %d: %w", i+1, err)
+				}
+				continue
+			}
+		}
+
+		return fmt.Errorf("%w: character %d of %d", ErrVirtualKeyNotFound, i+1, utf8.RuneCountInString(text))
+	}
+	return nil
+}
+
+// scanKeys reads the label of every KeySelector element.
+func (k VirtualKeyboard) scanKeys(page *rod.Page) (map[rune]*rod.Element, error) {
+	els, err := page.Elements(k.KeySelector)
+	if err != nil {
+		return nil, fmt.Errorf("find keys %s: %w", k.KeySelector, err)
+	}
+	keys := make(map[rune]*rod.Element, len(els))
+	for _, el := range els {
+		var label string
+		if k.LabelAttr != "" {
+			if v, err := el.Attribute(k.LabelAttr); err == nil && v != nil {
+				label = *v
+			}
+		} else {
+			label, _ = el.Text()
+			if strings.TrimSpace(label) == "" {
+				if v, err := el.Attribute("alt"); err == nil && v != nil {
+					label = *v
+				}
+			}
+		}
+		if ch, ok := keyLabel(label); ok {
+			keys[ch] = el
+		}
+	}
+	return keys, nil
+}
+
+// locateKeys screenshots Container and asks Locate where each key is.
+func (k VirtualKeyboard) locateKeys(page *rod.Page) (map[rune]Point, *proto.Point, error) {
+	el, err := page.Element(k.Container)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find keyboard %s: %w", k.Container, err)
+	}
+	shot, err := el.Screenshot(proto.PageCaptureScreenshotFormatPng, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("screenshot keyboard: %w", err)
+	}
+	shape, err := el.Shape()
+	if err != nil {
+		return nil, nil, fmt.Errorf("keyboard position: %w", err)
+	}
+	box := shape.Box()
+	if box == nil {
+		return nil, nil, fmt.Errorf("keyboard %s is not visible", k.Container)
+	}
+	points, err := k.Locate(shot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("locate keys: %w", err)
+	}
+	return points, &proto.Point{X: box.X, Y: box.Y}, nil
+}
+
+func clickAt(page *rod.Page, p proto.Point) error {
+	if err := page.Mouse.MoveTo(p); err != nil {
+		return err
+	}
+	return page.Mouse.Click(proto.InputMouseButtonLeft, 1)
+}
+
+// keyLabel returns the character a key is labeled with, if its label is a
+// single character.
+func keyLabel(label string) (rune, bool) {
+	label = strings.TrimSpace(label)
+	ch, size := utf8.DecodeRuneInString(label)
+	if size == 0 || size != len(label) {
+		return 0, false
+	}
+	return ch, true
+}
@@ -0,0 +1,44 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyLabel(t *testing.T) {
+	ch, ok := keyLabel(" 7\n")
+	assert.True(t, ok)
+	assert.Equal(t, '7', ch)
+
+	ch, ok = keyLabel("ñ")
+	assert.True(t, ok)
+	assert.Equal(t, 'ñ', ch)
+
+	_, ok = keyLabel("Borrar")
+	assert.False(t, ok)
+	_, ok = keyLabel("")
+	assert.False(t, ok)
+}
+
+func TestVirtualKeyboard_KeySelector(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => {
+		const digits = ['3', '9', '0', '5', '1', '7', '2', '8', '4', '6'];
+		document.body.innerHTML = '<div id="typed"></div>' +
+			digits.map(d => '<button class="key" data-key="' + d + '">' + d + '</button>').join('');
+		document.querySelectorAll('.key').forEach(b => b.onclick = () => {
+			document.getElementById('typed').textContent += b.dataset.key;
+		});
+	}`)
+
+	kb := VirtualKeyboard{KeySelector: ".key", LabelAttr: "data-key"}
+	require.NoError(t, kb.Type(page, "2580"))
+	assert.Equal(t, "2580", page.MustElement("#typed").MustText())
+
+	err := kb.Type(page, "12a")
+	assert.ErrorIs(t, err, ErrVirtualKeyNotFound)
+	assert.Contains(t, err.Error(), "character 3 of 3")
+}
@@ -0,0 +1,55 @@
+package browser
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// NewLauncher returns a launcher for bin (rod's lookup when empty) with the
+// stealth flags the scrapers run with and the adjustments the host OS needs.
+// Callers add their own flags before Launch.
+func NewLauncher(bin string, headless bool) *launcher.Launcher {
+	// Launch with stealth flags to avoid bot detection
+	l := launcher.New().
+		Set("disable-blink-features", "AutomationControlled").
+		Headless(headless)
+	if bin != "" {
+		l = l.Bin(bin)
+	}
+	return platformFlags(l, runtime.GOOS, os.Geteuid() == 0)
+}
+
+// NewVisibleLauncher returns a launcher for a supervised, visible window, as
+// the capture and discovery tools use: a desktop-sized window without first
+// run prompts or the automation infobar.
+func NewVisibleLauncher(bin string) *launcher.Launcher {
+	return NewLauncher(bin, false).
+		Set("exclude-switches", "enable-automation").
+		Set("no-default-browser-check").
+		Set("window-size", "1920,1080").
+		Devtools(false)
+}
+
+// platformFlags adapts l to goos. rod already finds the browser per OS (see
+// launcher.LookPath); what differs is how Chrome and rod's helpers behave.
+func platformFlags(l *launcher.Launcher, goos string, root bool) *launcher.Launcher {
+	switch goos {
+	case "linux":
+		// Chrome refuses to start as root with the sandbox on; rod only
+		// turns it off inside containers.
+		if root {
+			l = l.NoSandbox(true)
+		}
+		// Don't block on the desktop keyring for a throwaway profile.
+		l = l.Set("password-store", "basic")
+	case "windows":
+		// Antivirus tools quarantine rod's leakless helper executable, which
+		// makes Launch fail. The scrapers close the browser themselves.
+		l = l.Leakless(false)
+	case "darwin":
+		// Mock keychain is already a rod default; nothing else differs.
+	}
+	return l
+}
@@ -0,0 +1,30 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlatformFlags(t *testing.T) {
+	l := platformFlags(launcher.New(), "linux", true)
+	assert.True(t, l.Has(flags.NoSandbox), "root on Linux needs --no-sandbox")
+	assert.Equal(t, "basic", l.Get("password-store"))
+
+	l = platformFlags(launcher.New(), "windows", false)
+	assert.False(t, l.Has(flags.Leakless))
+	assert.False(t, l.Has("password-store"))
+
+	l = platformFlags(launcher.New(), "darwin", false)
+	assert.True(t, l.Has(flags.Leakless))
+}
+
+func TestNewVisibleLauncher(t *testing.T) {
+	l := NewVisibleLauncher("/opt/chrome")
+	assert.Equal(t, "/opt/chrome", l.Get(flags.Bin))
+	assert.False(t, l.Has(flags.Headless))
+	assert.Equal(t, "AutomationControlled", l.Get("disable-blink-features"))
+	assert.Equal(t, "1920,1080", l.Get("window-size"))
+}
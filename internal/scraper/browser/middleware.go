@@ -0,0 +1,39 @@
+package browser
+
+import (
+	"slices"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Middleware wraps a hijack handler, e.g. to block some requests or to
+// inspect the responses next served. It may answer a request itself
+// without calling next.
+type Middleware func(next func(*rod.Hijack)) func(*rod.Hijack)
+
+// Chain returns handler wrapped in mws, the first outermost: it sees each
+// request first and each response last. Nil middlewares are skipped.
+func Chain(handler func(*rod.Hijack), mws ...Middleware) func(*rod.Hijack) {
+	for _, mw := range slices.Backward(mws) {
+		if mw != nil {
+			handler = mw(handler)
+		}
+	}
+	return handler
+}
+
+// BlockResources fails requests for the given resource types (e.g. images
+// and fonts) in the browser, without loading them, to save bandwidth on
+// pages whose data doesn't depend on them.
+func BlockResources(types ...proto.NetworkResourceType) Middleware {
+	return func(next func(*rod.Hijack)) func(*rod.Hijack) {
+		return func(h *rod.Hijack) {
+			if slices.Contains(types, h.Request.Type()) {
+				h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+				return
+			}
+			next(h)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next func(*rod.Hijack)) func(*rod.Hijack) {
+			return func(h *rod.Hijack) {
+				calls = append(calls, name+" in")
+				next(h)
+				calls = append(calls, name+" out")
+			}
+		}
+	}
+	handler := Chain(func(*rod.Hijack) { calls = append(calls, "handler") }, trace("guard"), nil, trace("block"))
+
+	handler(nil)
+	assert.Equal(t, []string{"guard in", "block in", "handler", "block out", "guard out"}, calls)
+}
+
+func TestChain_ShortCircuit(t *testing.T) {
+	served := false
+	answer := func(func(*rod.Hijack)) func(*rod.Hijack) {
+		return func(*rod.Hijack) {}
+	}
+	Chain(func(*rod.Hijack) { served = true }, answer)(nil)
+	assert.False(t, served, "a middleware answering itself skips the handler")
+}
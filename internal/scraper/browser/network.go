@@ -0,0 +1,149 @@
+package browser
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxNetworkEntries bounds a NetworkLog between drains.
+const maxNetworkEntries = 500
+
+// NetworkEntry is one finished request: no headers or bodies.
+type NetworkEntry struct {
+	Method    string
+	URL       string // Without query string, which may carry tokens
+	Status    int    // 0 when no response arrived
+	Error     string // Why it failed, if it did
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+type pendingRequest struct {
+	entry NetworkEntry
+	start proto.MonotonicTime
+}
+
+// NetworkLog records the requests a page makes, iframes in the same process
+// included. Safe for concurrent use.
+type NetworkLog struct {
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[proto.NetworkRequestID]*pendingRequest
+	done    []NetworkEntry
+	dropped int
+}
+
+// CaptureNetwork starts recording page's requests until Stop.
+func CaptureNetwork(page *rod.Page) *NetworkLog {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := newNetworkLog()
+	l.cancel = cancel
+	wait := page.Context(ctx).EachEvent(
+		l.onRequest,
+		l.onResponse,
+		l.onFinished,
+		l.onFailed,
+	)
+	go wait()
+	return l
+}
+
+func newNetworkLog() *NetworkLog {
+	return &NetworkLog{pending: map[proto.NetworkRequestID]*pendingRequest{}}
+}
+
+// Stop ends recording. Entries not yet drained are kept.
+func (l *NetworkLog) Stop() {
+	if l != nil && l.cancel != nil {
+		l.cancel()
+	}
+}
+
+// Drain returns the requests that finished since the previous Drain, in
+// the order they finished, and how many were dropped over the cap.
+func (l *NetworkLog) Drain() (entries []NetworkEntry, dropped int) {
+	if l == nil {
+		return nil, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries, dropped = l.done, l.dropped
+	l.done, l.dropped = nil, 0
+	return entries, dropped
+}
+
+func (l *NetworkLog) onRequest(e *proto.NetworkRequestWillBeSent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// A redirect reuses the request ID: the hop so far is finished.
+	if e.RedirectResponse != nil {
+		if p, ok := l.pending[e.RequestID]; ok {
+			p.entry.Status = e.RedirectResponse.Status
+			l.finish(e.RequestID, e.Timestamp)
+		}
+	}
+	l.pending[e.RequestID] = &pendingRequest{
+		entry: NetworkEntry{
+			Method:    e.Request.Method,
+			URL:       stripQuery(e.Request.URL),
+			StartedAt: e.WallTime.Time(),
+		},
+		start: e.Timestamp,
+	}
+}
+
+func (l *NetworkLog) onResponse(e *proto.NetworkResponseReceived) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if p, ok := l.pending[e.RequestID]; ok {
+		p.entry.Status = e.Response.Status
+	}
+}
+
+func (l *NetworkLog) onFinished(e *proto.NetworkLoadingFinished) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.finish(e.RequestID, e.Timestamp)
+}
+
+func (l *NetworkLog) onFailed(e *proto.NetworkLoadingFailed) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if p, ok := l.pending[e.RequestID]; ok {
+		p.entry.Error = e.ErrorText
+		if e.Canceled {
+			p.entry.Error = "canceled"
+		}
+		l.finish(e.RequestID, e.Timestamp)
+	}
+}
+
+// finish moves a pending request to done. Callers hold l.mu.
+func (l *NetworkLog) finish(id proto.NetworkRequestID, at proto.MonotonicTime) {
+	p, ok := l.pending[id]
+	if !ok {
+		return
+	}
+	delete(l.pending, id)
+	if len(l.done) == maxNetworkEntries {
+		l.dropped++
+		return
+	}
+	p.entry.Duration = at.Duration() - p.start.Duration()
+	l.done = append(l.done, p.entry)
+}
+
+func stripQuery(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.RawQuery, u.Fragment = "", ""
+	return u.String()
+}
@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkLog(t *testing.T) {
+	l := newNetworkLog()
+	wall := proto.TimeSinceEpoch(1705314600) // 2024-01-15 10:30:00 UTC
+
+	l.onRequest(&proto.NetworkRequestWillBeSent{
+		RequestID: "1", Timestamp: 10, WallTime: wall,
+		Request: &proto.NetworkRequest{Method: "POST", URL: "https://bbvanetcash.pe/TechArchitecture/grantingTicket?ticket=secret"},
+	})
+	l.onRequest(&proto.NetworkRequestWillBeSent{
+		RequestID: "2", Timestamp: 10.5, WallTime: wall,
+		Request: &proto.NetworkRequest{Method: "GET", URL: "https://bbvanetcash.pe/accounts"},
+	})
+	l.onResponse(&proto.NetworkResponseReceived{RequestID: "1", Response: &proto.NetworkResponse{Status: 403}})
+	l.onFinished(&proto.NetworkLoadingFinished{RequestID: "1", Timestamp: 10.25})
+	l.onFailed(&proto.NetworkLoadingFailed{RequestID: "2", Timestamp: 40.5, ErrorText: "net::ERR_TIMED_OUT"})
+
+	entries, dropped := l.Drain()
+	require.Len(t, entries, 2)
+	assert.Zero(t, dropped)
+	assert.Equal(t, NetworkEntry{
+		Method:    "POST",
+		URL:       "https://bbvanetcash.pe/TechArchitecture/grantingTicket",
+		Status:    403,
+		StartedAt: wall.Time(),
+		Duration:  250 * time.Millisecond,
+	}, entries[0])
+	assert.Equal(t, "net::ERR_TIMED_OUT", entries[1].Error)
+	assert.Equal(t, 30*time.Second, entries[1].Duration)
+
+	entries, _ = l.Drain()
+	assert.Empty(t, entries)
+}
+
+func TestNetworkLog_Redirect(t *testing.T) {
+	l := newNetworkLog()
+	l.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "1", Timestamp: 1,
+		Request: &proto.NetworkRequest{Method: "GET", URL: "https://bbvanetcash.pe/"}})
+	l.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "1", Timestamp: 2,
+		Request:          &proto.NetworkRequest{Method: "GET", URL: "https://bbvanetcash.pe/login"},
+		RedirectResponse: &proto.NetworkResponse{Status: 302}})
+	l.onResponse(&proto.NetworkResponseReceived{RequestID: "1", Response: &proto.NetworkResponse{Status: 200}})
+	l.onFinished(&proto.NetworkLoadingFinished{RequestID: "1", Timestamp: 3})
+
+	entries, _ := l.Drain()
+	require.Len(t, entries, 2)
+	assert.Equal(t, 302, entries[0].Status)
+	assert.Equal(t, "https://bbvanetcash.pe/login", entries[1].URL)
+	assert.Equal(t, 200, entries[1].Status)
+}
@@ -0,0 +1,48 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// ErrInvalidProxy is returned for a proxy URL Chrome can't use.
+var ErrInvalidProxy = errors.New("invalid proxy")
+
+// ParseProxy checks raw is a proxy URL Chrome can use, such as Tor's
+// socks5://127.0.0.1:9050, and returns it as scheme://host:port. Chrome
+// takes no credentials with --proxy-server and can't authenticate to SOCKS
+// proxies at all, so a URL with credentials is rejected rather than silently
+// sent without them; the proxy would answer every request with a 407 (use
+// an IP allowlist, or a local forwarder that adds them). SOCKS4 is rejected too:
+// it can't resolve host names, which WithProxy leaves to the proxy, and Go's
+// HTTP client, which the geo preflight and hijacked requests use, doesn't
+// speak it.
+func ParseProxy(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Port() == "" {
+		return "", fmt.Errorf("%w: %q is not scheme://host:port", ErrInvalidProxy, raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return "", fmt.Errorf("%w: %q: scheme must be http, https or socks5", ErrInvalidProxy, u.Redacted())
+	}
+	if u.User != nil {
+		return "", fmt.Errorf("%w: %s://%s: Chrome doesn't support proxy credentials", ErrInvalidProxy, u.Scheme, u.Host)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// WithProxy sends all of l's browser traffic through proxy, a URL ParseProxy
+// accepted. Through a SOCKS5 proxy, host names are resolved by the proxy
+// too, so DNS lookups don't leak around it (e.g. around Tor).
+func WithProxy(l *launcher.Launcher, proxy string) *launcher.Launcher {
+	l = l.Proxy(proxy)
+	if u, err := url.Parse(proxy); err == nil && u.Scheme == "socks5" {
+		l = l.Set("host-resolver-rules", "MAP * ~NOTFOUND , EXCLUDE "+u.Hostname())
+	}
+	return l
+}
@@ -0,0 +1,90 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// DefaultTimezone is the zone remote pages run in unless Remote.Timezone
+// says otherwise. Bank portals render "today" in the browser's zone, and a
+// container defaults to UTC, five hours ahead of Lima.
+const DefaultTimezone = "America/Lima"
+
+// Remote is a containerized Chrome the scrapers connect to instead of
+// launching one, e.g.
+//
+//	docker run -p 3000:3000 ghcr.io/browserless/chromium        # URL: ws://localhost:3000
+//	docker run -p 9222:9222 zenika/alpine-chrome --remote-debugging-address=0.0.0.0 --remote-debugging-port=9222
+//	                                                            # URL: localhost:9222
+//
+// A scraper closes its browser when it closes. browserless starts a browser
+// per connection; with alpine-chrome, run one container per scraper.
+type Remote struct {
+	URL           string // ws:// endpoint, or http:// / host:port to look one up
+	Width, Height int    // Viewport; default 1920x1080
+	Timezone      string // IANA zone; default DefaultTimezone
+	DownloadDir   string // Where downloads land, inside the container; empty keeps Chrome's default
+}
+
+// Connect opens a connection to the remote browser and applies its download
+// behavior.
+func (r Remote) Connect() (*rod.Browser, error) {
+	u := r.URL
+	if !strings.HasPrefix(u, "ws://") && !strings.HasPrefix(u, "wss://") {
+		resolved, err := launcher.ResolveURL(u)
+		if err != nil {
+			return nil, fmt.Errorf("resolve remote browser %s: %w", u, err)
+		}
+		u = resolved
+	}
+
+	// Prepare sets the viewport; rod's default device would override the
+	// user agent too.
+	bro := rod.New().ControlURL(u).NoDefaultDevice()
+	if err := bro.Connect(); err != nil {
+		return nil, fmt.Errorf("connect to remote browser %s: %w", r.URL, err)
+	}
+
+	if r.DownloadDir != "" {
+		err := proto.BrowserSetDownloadBehavior{
+			Behavior:      proto.BrowserSetDownloadBehaviorBehaviorAllow,
+			DownloadPath:  r.DownloadDir,
+			EventsEnabled: true,
+		}.Call(bro)
+		if err != nil {
+			_ = bro.Close()
+			return nil, fmt.Errorf("set download behavior: %w", err)
+		}
+	}
+	return bro, nil
+}
+
+// Prepare applies the viewport and timezone to a page opened on the remote
+// browser. Call it before the page navigates.
+func (r Remote) Prepare(p *rod.Page) error {
+	width, height := r.Width, r.Height
+	if width == 0 || height == 0 {
+		width, height = 1920, 1080
+	}
+	err := p.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             width,
+		Height:            height,
+		DeviceScaleFactor: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("set viewport: %w", err)
+	}
+
+	tz := r.Timezone
+	if tz == "" {
+		tz = DefaultTimezone
+	}
+	if err := (proto.EmulationSetTimezoneOverride{TimezoneID: tz}).Call(p); err != nil {
+		return fmt.Errorf("set timezone %s: %w", tz, err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package browser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// ErrBrowserNotFound is returned when no Chromium binary is available under
+// the configured Policy.
+var ErrBrowserNotFound = errors.New("no Chromium browser found")
+
+// ErrBrowserChecksum is returned when a downloaded browser doesn't match its
+// expected checksum. The cached revision is left in place for inspection;
+// delete its directory to download it again.
+var ErrBrowserChecksum = errors.New("browser checksum mismatch")
+
+// ErrBrowserUnpinned is returned for a browser revision to download with no
+// known checksum: neither Resolver.SHA256 nor PinnedSHA256 has one for this
+// platform. Nothing is downloaded, since nothing could verify it. Its message
+// says how to fix it.
+var ErrBrowserUnpinned = errors.New("no known browser checksum")
+
+// Policy says where scrapers get their Chromium binary from.
+type Policy string
+
+// Browser policies.
+const (
+	// PolicyAuto uses an installed Chrome or Chromium, or else downloads the
+	// pinned revision into the cache dir, which needs its checksum (see
+	// PinnedSHA256).
+	PolicyAuto Policy = "auto"
+	// PolicySystem uses an installed Chrome or Chromium and fails fast
+	// without one. Suits locked-down hosts that must not download binaries.
+	PolicySystem Policy = "system"
+	// PolicyDownload always uses the pinned revision, downloading it into
+	// the cache dir on first use.
+	PolicyDownload Policy = "download"
+)
+
+// ParsePolicy validates a policy name; empty means PolicyAuto.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case "":
+		return PolicyAuto, nil
+	case PolicyAuto, PolicySystem, PolicyDownload:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown browser policy %q (use auto, system or download)", s)
+}
+
+// PinnedRevision is the Chromium revision this release of the scrapers is
+// tested against. Headless mode and shadow DOM serialization change between
+// Chrome versions, so PolicyDownload and PolicyAuto (without an installed
+// browser) use this rather than whatever rod defaults to. Bump it together
+// with PinnedSHA256 and re-run the replay tests.
+const PinnedRevision = 1321438
+
+// PinnedSHA256 is the checksum of PinnedRevision's binary per GOOS/GOARCH,
+// taken from a download verified out of band (`bank-scraper doctor` prints
+// the checksum of the resolved browser). A platform missing here can't
+// download the browser unless BROWSER_SHA256 supplies its checksum. None is
+// pinned yet: until one is, hosts without an installed browser must install
+// one, or set BROWSER_SHA256.
+var PinnedSHA256 = map[string]string{}
+
+// Browser sources, as reported in Resolution.Source.
+const (
+	SourceExplicit   = "explicit"   // Resolver.Bin
+	SourceSystem     = "system"     // installed browser
+	SourceCache      = "cache"      // pinned revision, already downloaded
+	SourceDownloaded = "downloaded" // pinned revision, downloaded just now
+)
+
+// Resolution is the browser a Resolver picked.
+type Resolution struct {
+	Path     string
+	Source   string
+	Revision int    // pinned revision; zero for explicit and system browsers
+	SHA256   string // hex checksum of a pinned revision's binary, as verified
+}
+
+// Resolver finds the Chromium binary to launch. The zero value resolves like
+// rod's launcher would (PolicyAuto) but downloads PinnedRevision, verified,
+// under the user cache dir instead of rod's default.
+type Resolver struct {
+	Policy   Policy
+	Bin      string // Explicit binary; overrides Policy when set
+	CacheDir string // Where downloaded revisions live; default DefaultCacheDir
+	Revision int    // Revision to download; default PinnedRevision
+	SHA256   string // Expected hex checksum of the downloaded binary; default PinnedSHA256
+}
+
+// DefaultCacheDir is where downloaded browsers are kept unless
+// Resolver.CacheDir says otherwise.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "bank-scraper", "browser")
+}
+
+// Resolve returns the browser to launch, downloading the pinned revision if
+// the policy allows and nothing else is available.
+func (r Resolver) Resolve(ctx context.Context) (Resolution, error) {
+	res, err := r.Check()
+	if err == nil || r.Policy == PolicySystem || r.Bin != "" || errors.Is(err, ErrBrowserChecksum) || errors.Is(err, ErrBrowserUnpinned) {
+		return res, err
+	}
+
+	dl := r.downloader(ctx)
+	path, err := dl.Get()
+	if err != nil {
+		return Resolution{}, fmt.Errorf("%w: download revision %d: %w", ErrBrowserNotFound, dl.Revision, err)
+	}
+	sum, err := r.verify(dl)
+	if err != nil {
+		return Resolution{}, err
+	}
+	return Resolution{Path: path, Source: SourceDownloaded, Revision: dl.Revision, SHA256: sum}, nil
+}
+
+// Check reports the browser Resolve would use without downloading anything.
+// Its ErrBrowserNotFound error says Resolve would download the browser; its
+// ErrBrowserUnpinned error that it couldn't.
+func (r Resolver) Check() (Resolution, error) {
+	if r.Bin != "" {
+		if _, err := os.Stat(r.Bin); err != nil {
+			return Resolution{}, fmt.Errorf("%w: %w", ErrBrowserNotFound, err)
+		}
+		return Resolution{Path: r.Bin, Source: SourceExplicit}, nil
+	}
+
+	if r.Policy != PolicyDownload {
+		if path, ok := launcher.LookPath(); ok {
+			return Resolution{Path: path, Source: SourceSystem}, nil
+		}
+		if r.Policy == PolicySystem {
+			return Resolution{}, fmt.Errorf("%w: no installed Chrome or Chromium (policy %s never downloads)", ErrBrowserNotFound, PolicySystem)
+		}
+	}
+
+	dl := r.downloader(context.Background())
+	if _, err := r.expectedSHA256(dl); err != nil {
+		return Resolution{}, err
+	}
+	if _, err := os.Stat(dl.BinPath()); err != nil {
+		return Resolution{}, fmt.Errorf("%w: revision %d not yet downloaded to %s", ErrBrowserNotFound, dl.Revision, dl.Dir())
+	}
+	sum, err := r.verify(dl)
+	if err != nil {
+		return Resolution{}, err
+	}
+	return Resolution{Path: dl.BinPath(), Source: SourceCache, Revision: dl.Revision, SHA256: sum}, nil
+}
+
+// expectedSHA256 returns the checksum dl's binary must have: r.SHA256, else
+// the pinned one for this platform.
+func (r Resolver) expectedSHA256(dl *launcher.Browser) (string, error) {
+	if r.SHA256 != "" {
+		return r.SHA256, nil
+	}
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	if sum := PinnedSHA256[platform]; sum != "" && dl.Revision == PinnedRevision {
+		return sum, nil
+	}
+	return "", fmt.Errorf("%w: revision %d on %s; to fix, install Chrome or Chromium (e.g. apt-get install chromium) "+
+		"with BROWSER_POLICY=auto or BROWSER_BIN, or set BROWSER_SHA256 to the sha256sum of %s from a download verified out of band",
+		ErrBrowserUnpinned, dl.Revision, platform, dl.BinPath())
+}
+
+// verify checks dl's binary against its expected checksum and returns the
+// binary's checksum.
+func (r Resolver) verify(dl *launcher.Browser) (string, error) {
+	want, err := r.expectedSHA256(dl)
+	if err != nil {
+		return "", err
+	}
+	sum, err := fileSHA256(dl.BinPath())
+	if err != nil {
+		return "", fmt.Errorf("checksum %s: %w", dl.BinPath(), err)
+	}
+	if !strings.EqualFold(want, sum) {
+		return "", fmt.Errorf("%w: %s is %s, want %s (delete %s to download it again)", ErrBrowserChecksum, dl.BinPath(), sum, want, dl.Dir())
+	}
+	return sum, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (r Resolver) downloader(ctx context.Context) *launcher.Browser {
+	dl := launcher.NewBrowser()
+	dl.Context = ctx
+	dl.RootDir = r.CacheDir
+	if dl.RootDir == "" {
+		dl.RootDir = DefaultCacheDir()
+	}
+	dl.Revision = PinnedRevision
+	if r.Revision != 0 {
+		dl.Revision = r.Revision
+	}
+	return dl
+}
@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyAuto, p)
+
+	p, err = ParsePolicy("system")
+	require.NoError(t, err)
+	assert.Equal(t, PolicySystem, p)
+
+	_, err = ParsePolicy("chrome")
+	assert.Error(t, err)
+}
+
+func TestResolver_Check_Explicit(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "chrome")
+	require.NoError(t, os.WriteFile(bin, nil, 0o755))
+
+	res, err := Resolver{Policy: PolicyDownload, Bin: bin}.Check()
+	require.NoError(t, err)
+	assert.Equal(t, Resolution{Path: bin, Source: SourceExplicit}, res, "Bin overrides the policy")
+
+	_, err = Resolver{Bin: bin + "-missing"}.Check()
+	assert.ErrorIs(t, err, ErrBrowserNotFound)
+}
+
+func TestResolver_Check_Cache(t *testing.T) {
+	r := Resolver{Policy: PolicyDownload, CacheDir: t.TempDir(), Revision: 42, SHA256: emptySHA256}
+
+	_, err := r.Check()
+	require.ErrorIs(t, err, ErrBrowserNotFound)
+	assert.Contains(t, err.Error(), "revision 42 not yet downloaded")
+
+	bin := r.downloader(t.Context()).BinPath()
+	require.NoError(t, os.MkdirAll(filepath.Dir(bin), 0o755))
+	require.NoError(t, os.WriteFile(bin, nil, 0o755))
+
+	res, err := r.Check()
+	require.NoError(t, err)
+	assert.Equal(t, Resolution{Path: bin, Source: SourceCache, Revision: 42, SHA256: emptySHA256}, res)
+}
+
+// emptySHA256 is the checksum of an empty file.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func writeCachedBin(t *testing.T, r Resolver, content string) string {
+	t.Helper()
+	bin := r.downloader(t.Context()).BinPath()
+	require.NoError(t, os.MkdirAll(filepath.Dir(bin), 0o755))
+	require.NoError(t, os.WriteFile(bin, []byte(content), 0o755))
+	return bin
+}
+
+func TestResolver_Check_Checksum(t *testing.T) {
+	r := Resolver{Policy: PolicyDownload, CacheDir: t.TempDir(), Revision: 42, SHA256: emptySHA256}
+	writeCachedBin(t, r, "")
+
+	_, err := r.Check()
+	require.NoError(t, err)
+
+	writeCachedBin(t, r, "tampered")
+	_, err = r.Check()
+	assert.ErrorIs(t, err, ErrBrowserChecksum)
+
+	_, err = r.Resolve(t.Context())
+	assert.ErrorIs(t, err, ErrBrowserChecksum, "a mismatch is not fixed by downloading again")
+}
+
+func TestResolver_Unpinned(t *testing.T) {
+	r := Resolver{Policy: PolicyDownload, CacheDir: t.TempDir(), Revision: 42}
+	writeCachedBin(t, r, "")
+
+	_, err := r.Check()
+	assert.ErrorIs(t, err, ErrBrowserUnpinned, "not trusted on first use")
+
+	r.CacheDir = t.TempDir()
+	_, err = r.Check()
+	require.ErrorIs(t, err, ErrBrowserUnpinned, "reported before the first download")
+	assert.Contains(t, err.Error(), "BROWSER_SHA256 to the sha256sum of "+r.downloader(t.Context()).BinPath())
+
+	_, err = r.Resolve(t.Context())
+	assert.ErrorIs(t, err, ErrBrowserUnpinned, "refused before downloading")
+	assert.NoDirExists(t, r.downloader(t.Context()).Dir())
+}
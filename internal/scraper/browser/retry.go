@@ -0,0 +1,81 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+)
+
+// ErrElementNotFound is returned by WithElement when selector matches
+// nothing before the frame's context ends.
+var ErrElementNotFound = errors.New("element not found")
+
+// StaleRetries is how many times WithElement finds an element again after
+// its handle went stale.
+const StaleRetries = 3
+
+// staleMessages are CDP errors for a node that was removed or replaced
+// after it was resolved; rod has no sentinel for them.
+var staleMessages = []string{
+	"Could not find node with given id",
+	"Node with given id does not belong to the document",
+	"Node is detached from document",
+}
+
+// IsStaleElement reports whether err means an element handle outlived its
+// DOM node, as happens when BBVA's web components re-render mid-interaction.
+// Finding the element again usually fixes it.
+func IsStaleElement(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, cdp.ErrObjNotFound) || errors.Is(err, cdp.ErrCtxNotFound) ||
+		errors.Is(err, cdp.ErrCtxDestroyed) || errors.Is(err, &rod.ObjectNotFoundError{}) {
+		return true
+	}
+	var cdpErr *cdp.Error
+	if errors.As(err, &cdpErr) {
+		for _, msg := range staleMessages {
+			if strings.Contains(cdpErr.Message, msg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithElement finds selector in frame (a page or an iframe's page) and runs
+// act on it. If the handle goes stale while finding or acting, the element
+// is found again and act retried, up to StaleRetries times. act must be safe
+// to repeat: a click is, typing is not. frame's context bounds the whole
+// call.
+func WithElement(frame *rod.Page, selector string, act func(*rod.Element) error) error {
+	ctx := frame.GetContext()
+	var err error
+	for attempt := 0; attempt <= StaleRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s: %w (after stale element: %w)", selector, ctx.Err(), err)
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			}
+		}
+
+		var el *rod.Element
+		el, err = frame.Element(selector)
+		if err != nil {
+			if IsStaleElement(err) {
+				continue
+			}
+			return fmt.Errorf("%w: %s: %w", ErrElementNotFound, selector, err)
+		}
+		if err = act(el); !IsStaleElement(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: still stale after %d retries: %w", selector, StaleRetries, err)
+}
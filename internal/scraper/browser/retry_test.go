@@ -0,0 +1,45 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsStaleElement(t *testing.T) {
+	assert.True(t, IsStaleElement(cdp.ErrObjNotFound))
+	assert.True(t, IsStaleElement(fmt.Errorf("click: %w", &cdp.Error{Code: -32000, Message: "Node is detached from document"})))
+	assert.True(t, IsStaleElement(&rod.ObjectNotFoundError{}))
+	assert.False(t, IsStaleElement(nil))
+	assert.False(t, IsStaleElement(errors.New("element covered")))
+	assert.False(t, IsStaleElement(&cdp.Error{Code: -32000, Message: "Cannot navigate to invalid URL"}))
+}
+
+func TestWithElement_RetriesStale(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => { document.body.innerHTML = '<button id="go">Ingresar</button>'; }`)
+
+	calls := 0
+	err := WithElement(page, "#go", func(el *rod.Element) error {
+		calls++
+		if calls < 3 {
+			return cdp.ErrObjNotFound
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	err = WithElement(page, "#go", func(*rod.Element) error { return cdp.ErrObjNotFound })
+	assert.ErrorContains(t, err, "still stale after 3 retries")
+
+	act := errors.New("not interactable")
+	err = WithElement(page, "#go", func(*rod.Element) error { return act })
+	assert.ErrorIs(t, err, act, "other errors are not retried")
+}
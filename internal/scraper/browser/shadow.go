@@ -226,3 +226,119 @@ func FlattenShadowDOM(page *rod.Page) (html string, shadowCount int, iframeCount
 
 	return result.HTML, result.ShadowCount, result.IframeCount, nil
 }
+
+// serializeFlattenedJS builds the same flattened HTML as flattenShadowDOMJS
+// without touching the live DOM: it serializes each node itself, emitting a
+// shadow root's container after the host's light children and an iframe's
+// container in place of the iframe, as the mutating version leaves them.
+const serializeFlattenedJS = `() => {
+	const MAX_DEPTH = 100;
+	const VOID = new Set(['AREA', 'BASE', 'BR', 'COL', 'EMBED', 'HR', 'IMG', 'INPUT',
+		'LINK', 'META', 'SOURCE', 'TRACK', 'WBR']);
+	const RAW = new Set(['SCRIPT', 'STYLE']);
+	let shadowCount = 0;
+	let iframeCount = 0;
+
+	function escapeText(s) {
+		return s.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+	}
+
+	function escapeAttr(s) {
+		return s.replace(/&/g, '&amp;').replace(/"/g, '&quot;');
+	}
+
+	function attrs(pairs) {
+		return pairs.map(function(p) { return ' ' + p[0] + '="' + escapeAttr(p[1]) + '"'; }).join('');
+	}
+
+	function children(nodes, depth, skipStyles) {
+		let out = '';
+		for (const child of Array.from(nodes)) {
+			if (skipStyles && child.nodeType === Node.ELEMENT_NODE && child.tagName === 'STYLE') {
+				continue;
+			}
+			out += serialize(child, depth);
+		}
+		return out;
+	}
+
+	function styles(root, marker) {
+		let out = '';
+		root.querySelectorAll('style').forEach(function(style) {
+			out += '<style ' + marker + '="true">' + style.textContent + '</style>';
+		});
+		return out;
+	}
+
+	function serialize(node, depth) {
+		if (node.nodeType === Node.TEXT_NODE) {
+			const parent = node.parentNode;
+			return parent && RAW.has(parent.tagName) ? node.textContent : escapeText(node.textContent);
+		}
+		if (node.nodeType !== Node.ELEMENT_NODE || depth > MAX_DEPTH) {
+			return '';
+		}
+		if (node.tagName === 'IFRAME') {
+			return serializeIframe(node, depth);
+		}
+
+		const tag = node.tagName.toLowerCase();
+		let out = '<' + tag + attrs(Array.from(node.attributes).map(function(a) { return [a.name, a.value]; })) + '>';
+		if (VOID.has(node.tagName)) {
+			return out;
+		}
+		out += children(node.childNodes, depth + 1, false);
+
+		const shadow = node.shadowRoot;
+		if (shadow) {
+			shadowCount++;
+			out += '<div' + attrs([['data-shadow-root', 'true'], ['data-shadow-host', tag]]) + '>' +
+				styles(shadow, 'data-from-shadow') +
+				children(shadow.childNodes, depth + 1, true) +
+				'</div>';
+		}
+		return out + '</' + tag + '>';
+	}
+
+	function serializeIframe(iframe, depth) {
+		let doc = null;
+		let error = 'no contentDocument available';
+		try {
+			doc = iframe.contentDocument || (iframe.contentWindow && iframe.contentWindow.document);
+		} catch (e) {
+			error = e.message;
+		}
+		if (!doc || !doc.documentElement) {
+			return '<div' + attrs([['data-captured-iframe', 'true'], ['data-iframe-error', error],
+				['data-iframe-src', iframe.src || '']]) + '>' +
+				escapeText('[iframe not accessible: ' + error + ']') + '</div>';
+		}
+
+		iframeCount++;
+		return '<div' + attrs([['data-captured-iframe', 'true'], ['data-iframe-src', iframe.src || ''],
+			['data-iframe-id', iframe.id || ''], ['data-iframe-name', iframe.name || '']]) + '>' +
+			(doc.head ? styles(doc.head, 'data-from-iframe') : '') +
+			(doc.body ? children(doc.body.childNodes, depth + 1, false) : '') +
+			'</div>';
+	}
+
+	const html = serialize(document.documentElement, 0);
+	return JSON.stringify({html: html, shadowCount: shadowCount, iframeCount: iframeCount});
+}`
+
+// FlattenShadowDOMReadOnly returns the same flattened HTML as
+// FlattenShadowDOM, shadow roots and same-origin iframes inlined, but builds
+// it without modifying the page, so it is safe on a page the scraper keeps
+// using. Unlike FlattenShadowDOM it does not fall back to page.HTML(): a
+// caller archiving page state should know the capture is incomplete.
+func FlattenShadowDOMReadOnly(page *rod.Page) (html string, shadowCount int, iframeCount int, err error) {
+	res, err := page.Eval(serializeFlattenedJS)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("flatten shadow DOM: %w", err)
+	}
+	var result flattenResult
+	if err := json.Unmarshal([]byte(res.Value.Str()), &result); err != nil {
+		return "", 0, 0, fmt.Errorf("flatten shadow DOM: decode result: %w", err)
+	}
+	return result.HTML, result.ShadowCount, result.IframeCount, nil
+}
@@ -140,3 +140,34 @@ func TestFlattenShadowDOM_ShadowStyles(t *testing.T) {
 	assert.Contains(t, html, "color: red")
 	assert.Contains(t, html, "Styled")
 }
+
+func TestFlattenShadowDOMReadOnly_LeavesPageIntact(t *testing.T) {
+	page := setupPage(t)
+	page.MustNavigate("about:blank").MustWaitLoad()
+	page.MustEval(`() => {
+		document.body.innerHTML =
+			'<outer-host><inner-host>Light &amp; text</inner-host></outer-host>' +
+			'<iframe id="frame" name="data" srcdoc="<style>td{color:red}</style><table><tr><td>row</td></tr></table>"></iframe>';
+		document.querySelector('outer-host').attachShadow({mode: 'open'}).innerHTML =
+			'<style>.x{}</style><div class="outer-layout"><slot></slot></div>';
+		document.querySelector('inner-host').attachShadow({mode: 'open'}).innerHTML =
+			'<span title="a&quot;b">secret-data</span>';
+	}`)
+	page.MustElement("#frame").MustFrame().MustElement("td")
+	before := page.MustHTML()
+
+	html, shadowCount, iframeCount, err := FlattenShadowDOMReadOnly(page)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, shadowCount)
+	assert.Equal(t, 1, iframeCount)
+	assert.Contains(t, html, `data-shadow-host="inner-host"`)
+	assert.Contains(t, html, `<span title="a&quot;b">secret-data</span>`)
+	assert.Contains(t, html, "Light &amp; text")
+	assert.Contains(t, html, `data-iframe-name="data"`)
+	assert.Contains(t, html, `<style data-from-iframe="true">td{color:red}</style>`)
+	assert.Contains(t, html, "<td>row</td>")
+	assert.NotContains(t, html, "<iframe")
+
+	assert.Equal(t, before, page.MustHTML(), "the live DOM must not change")
+}
@@ -2,25 +2,19 @@
 package browser
 
 import (
-	"math/rand"
-	"time"
-
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
 )
 
+// defaultHuman times TypeHuman.
+var defaultHuman = RandomHuman()
+
 // TypeHuman types text into an element with human-like timing.
 // It uses Element.Type() which properly triggers keyboard events (keydown/keyup).
 // Small random delays (50-150ms) between keystrokes simulate human typing.
+// Use a seeded Human's Type for reproducible delays.
 func TypeHuman(el *rod.Element, text string) error {
-	for _, char := range text {
-		if err := el.Type(input.Key(char)); err != nil {
-			return err
-		}
-		// Small random delay to simulate human typing
-		time.Sleep(time.Duration(50+rand.Intn(100)) * time.Millisecond)
-	}
-	return nil
+	return defaultHuman.Type(el, text)
 }
 
 // TypeFast types text quickly without delays.
@@ -0,0 +1,104 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// auditManifest lists a trail's screenshots in capture order, one JSON
+// object per line.
+const auditManifest = "audit.jsonl"
+
+// AuditEntry is one line of a trail's audit.jsonl.
+type AuditEntry struct {
+	Step       string    `json:"step"`
+	URL        string    `json:"url"`
+	File       string    `json:"file"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// AuditTrail keeps a screenshot of each major step of a run, as evidence of
+// what the automation saw. Unlike Collector it captures on success too. Like
+// Collector, all methods are nil-receiver safe and never fail the operation;
+// a screenshot that can't be kept is logged as a warning, since the trail is
+// then incomplete.
+type AuditTrail struct {
+	dir    string
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewAuditTrail creates a trail that writes under baseDir/sessionID/.
+func NewAuditTrail(baseDir, sessionID string, logger *slog.Logger) *AuditTrail {
+	return &AuditTrail{
+		dir:    filepath.Join(baseDir, sessionID),
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Dir returns the trail's directory.
+func (a *AuditTrail) Dir() string {
+	if a == nil {
+		return ""
+	}
+	return a.dir
+}
+
+// Capture screenshots page's viewport as step, e.g. "post-login".
+func (a *AuditTrail) Capture(page *rod.Page, step string) {
+	if a == nil || page == nil {
+		return
+	}
+	data, err := page.Screenshot(false, nil)
+	if err != nil {
+		a.logger.Warn("audit screenshot failed", slog.String("step", step), slog.Any("error", err))
+		return
+	}
+	a.record(step, PageURL(page), data)
+}
+
+// record writes png as the next screenshot and appends it to the manifest.
+func (a *AuditTrail) record(step, url string, png []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		a.logger.Warn("failed to create audit dir", slog.String("dir", a.dir), slog.Any("error", err))
+		return
+	}
+
+	a.seq++
+	at := a.now().UTC()
+	entry := AuditEntry{
+		Step:       step,
+		URL:        url,
+		File:       fmt.Sprintf("%02d-%s-%s.png", a.seq, at.Format("20060102T150405.000Z"), step),
+		CapturedAt: at,
+	}
+	if err := os.WriteFile(filepath.Join(a.dir, entry.File), png, 0o644); err != nil {
+		a.logger.Warn("failed to write audit screenshot", slog.String("file", entry.File), slog.Any("error", err))
+		return
+	}
+
+	line, _ := json.Marshal(entry)
+	f, err := os.OpenFile(filepath.Join(a.dir, auditManifest), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		a.logger.Warn("failed to open audit manifest", slog.Any("error", err))
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		a.logger.Warn("failed to write audit manifest", slog.Any("error", err))
+	}
+}
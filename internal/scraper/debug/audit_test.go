@@ -0,0 +1,65 @@
+package debug
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTrail_Record(t *testing.T) {
+	baseDir := t.TempDir()
+	a := NewAuditTrail(baseDir, "session-123", slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	at := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	a.now = func() time.Time { return at }
+
+	a.record("post-login", "https://bbvanetcash.pe/dashboard", []byte("png-1"))
+	a.record("accounts", "https://bbvanetcash.pe/accounts", []byte("png-2"))
+
+	dir := filepath.Join(baseDir, "session-123")
+	data, err := os.ReadFile(filepath.Join(dir, "01-20240115T103000.000Z-post-login.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "png-1", string(data))
+	assert.FileExists(t, filepath.Join(dir, "02-20240115T103000.000Z-accounts.png"))
+
+	f, err := os.Open(filepath.Join(dir, auditManifest))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var entries []AuditEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e AuditEntry
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.Len(t, entries, 2)
+	assert.Equal(t, AuditEntry{
+		Step:       "accounts",
+		URL:        "https://bbvanetcash.pe/accounts",
+		File:       "02-20240115T103000.000Z-accounts.png",
+		CapturedAt: at,
+	}, entries[1])
+}
+
+func TestAuditTrail_WriteFailureWarns(t *testing.T) {
+	notADir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(notADir, nil, 0o600))
+	var logs bytes.Buffer
+	a := NewAuditTrail(notADir, "session-123", slog.New(slog.NewTextHandler(&logs, nil)))
+
+	a.record("post-login", "https://bbvanetcash.pe/dashboard", []byte("png-1"))
+	assert.Contains(t, logs.String(), "level=WARN")
+}
+
+func TestAuditTrail_NilSafety(t *testing.T) {
+	var a *AuditTrail
+	a.Capture(nil, "post-login")
+	assert.Empty(t, a.Dir())
+}
@@ -0,0 +1,149 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxScreencastFrames bounds a recording's memory. At the ~5 fps Chrome
+// sends for a mostly static portal that is a few minutes of video; older
+// frames are dropped first.
+const maxScreencastFrames = 1000
+
+type screencastFrame struct {
+	data []byte
+	at   time.Time
+}
+
+// Screencast records a page's frames over one operation and keeps them only
+// if the operation fails. Nil-receiver safe, like Collector.
+type Screencast struct {
+	collector *Collector
+	operation string
+	page      *rod.Page
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu     sync.Mutex
+	frames []screencastFrame
+}
+
+// StartScreencast starts recording page for operation. Call Stop when the
+// operation ends.
+func (c *Collector) StartScreencast(page *rod.Page, operation string) *Screencast {
+	if c == nil || page == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sc := &Screencast{collector: c, operation: operation, page: page, cancel: cancel, done: make(chan struct{})}
+	p := page.Context(ctx)
+	wait := p.EachEvent(func(e *proto.PageScreencastFrame) {
+		sc.add(e.Data, time.Now())
+		_ = proto.PageScreencastFrameAck{SessionID: e.SessionID}.Call(p)
+	})
+
+	quality := 60
+	err := proto.PageStartScreencast{Format: proto.PageStartScreencastFormatJpeg, Quality: &quality}.Call(page)
+	if err != nil {
+		cancel()
+		c.logger.Debug("screencast start failed", slog.String("operation", operation), slog.Any("error", err))
+		return nil
+	}
+	go func() {
+		defer close(sc.done)
+		wait()
+	}()
+	return sc
+}
+
+func (sc *Screencast) add(data []byte, at time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if len(sc.frames) == maxScreencastFrames {
+		sc.frames = sc.frames[1:]
+	}
+	sc.frames = append(sc.frames, screencastFrame{data: data, at: at})
+}
+
+// Stop ends the recording. If failed, the frames are written under the
+// collector's directory and assembled into {operation}-screencast.webm with
+// ffmpeg; without ffmpeg on PATH the frames and their concat list are kept
+// for assembling by hand.
+func (sc *Screencast) Stop(failed bool) {
+	if sc == nil {
+		return
+	}
+	_ = proto.PageStopScreencast{}.Call(sc.page)
+	sc.cancel()
+	<-sc.done
+
+	sc.mu.Lock()
+	frames := sc.frames
+	sc.frames = nil
+	sc.mu.Unlock()
+	if !failed || len(frames) == 0 {
+		return
+	}
+	sc.save(frames, time.Now())
+}
+
+// save writes frames as JPEGs plus an ffmpeg concat list timing each frame
+// until the next one (the last until end), then encodes them.
+func (sc *Screencast) save(frames []screencastFrame, end time.Time) {
+	c := sc.collector
+	framesDir := filepath.Join(c.dir, sc.operation+"-screencast")
+	if err := os.MkdirAll(framesDir, 0o755); err != nil {
+		c.logger.Debug("failed to create screencast dir", slog.String("dir", framesDir), slog.Any("error", err))
+		return
+	}
+
+	var list strings.Builder
+	for i, f := range frames {
+		name := fmt.Sprintf("frame-%04d.jpg", i)
+		if err := os.WriteFile(filepath.Join(framesDir, name), f.data, 0o644); err != nil {
+			c.logger.Debug("failed to write screencast frame", slog.Any("error", err))
+			return
+		}
+		next := end
+		if i+1 < len(frames) {
+			next = frames[i+1].at
+		}
+		fmt.Fprintf(&list, "file '%s'\nduration %.3f\n", name, next.Sub(f.at).Seconds())
+	}
+	// The concat demuxer ignores the last duration unless the file repeats.
+	fmt.Fprintf(&list, "file 'frame-%04d.jpg'\n", len(frames)-1)
+	listPath := filepath.Join(framesDir, "frames.txt")
+	if err := os.WriteFile(listPath, []byte(list.String()), 0o644); err != nil {
+		c.logger.Debug("failed to write screencast frame list", slog.Any("error", err))
+		return
+	}
+
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		c.logger.Info("screencast frames kept; install ffmpeg to get a WebM", slog.String("dir", framesDir))
+		return
+	}
+	out := filepath.Join(c.dir, sc.operation+"-screencast.webm")
+	cmd := exec.Command(ffmpeg, "-y", "-loglevel", "error",
+		"-f", "concat", "-safe", "0", "-i", listPath,
+		"-c:v", "libvpx-vp9", "-pix_fmt", "yuv420p", "-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		c.logger.Debug("screencast encoding failed; frames kept",
+			slog.String("dir", framesDir), slog.String("output", string(output)), slog.Any("error", err))
+		return
+	}
+	_ = os.RemoveAll(framesDir)
+	c.logger.Info("screencast saved", slog.String("path", out))
+}
@@ -0,0 +1,52 @@
+package debug
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreencast_SaveWithoutFFmpeg(t *testing.T) {
+	t.Setenv("PATH", "")
+	baseDir := t.TempDir()
+	c := New(baseDir, "session-123", slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	sc := &Screencast{collector: c, operation: "GetBalance"}
+
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	sc.save([]screencastFrame{
+		{data: []byte("jpeg-0"), at: start},
+		{data: []byte("jpeg-1"), at: start.Add(250 * time.Millisecond)},
+	}, start.Add(time.Second))
+
+	dir := filepath.Join(baseDir, "session-123", "GetBalance-screencast")
+	data, err := os.ReadFile(filepath.Join(dir, "frame-0001.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg-1", string(data))
+
+	list, err := os.ReadFile(filepath.Join(dir, "frames.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "file 'frame-0000.jpg'\nduration 0.250\n"+
+		"file 'frame-0001.jpg'\nduration 0.750\n"+
+		"file 'frame-0001.jpg'\n", string(list))
+}
+
+func TestScreencast_DropsOldestFrames(t *testing.T) {
+	sc := &Screencast{}
+	for i := range maxScreencastFrames + 5 {
+		sc.add([]byte{byte(i)}, time.Time{})
+	}
+	require.Len(t, sc.frames, maxScreencastFrames)
+	assert.Equal(t, []byte{5}, sc.frames[0].data)
+}
+
+func TestScreencast_NilSafety(t *testing.T) {
+	var c *Collector
+	sc := c.StartScreencast(nil, "Login")
+	assert.Nil(t, sc)
+	sc.Stop(true)
+}
@@ -2,25 +2,287 @@
 package factory
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aynifx/bank-scraper/internal/config"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank"
 	"github.com/aynifx/bank-scraper/internal/scraper/bank/bbva"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/preflight"
 )
 
+// Option configures the scrapers a factory builds.
+type Option func(*options)
+
+type options struct {
+	resolver   *browser.Resolver
+	remote     *browser.Remote
+	networkLog bool
+	lifetimes  map[string]time.Duration
+	idle       map[string]time.Duration
+	proxy      string
+	proxies    map[string]string
+	geo        *preflight.GeoCheck
+	enrichers  []bank.Enricher
+	flags      map[bank.Code]bank.Flags
+	timings    *bank.Timings
+}
+
+// KnownFlags are the feature flags of each bank's scraper.
+var KnownFlags = map[bank.Code][]bank.Flag{
+	bank.BankBBVA: bbva.KnownFlags,
+}
+
+// WithBrowser launches the browser r resolves. It is resolved (and
+// downloaded, if the policy allows) when the first scraper is built, so a
+// missing browser fails that call with browser.ErrBrowserNotFound instead
+// of an opaque launch error. Without it, rod picks the browser.
+func WithBrowser(r browser.Resolver) Option {
+	return func(o *options) {
+		o.resolver = &r
+	}
+}
+
+// WithRemoteBrowser has every scraper connect to its own session on a
+// containerized Chrome instead of launching one. It takes precedence over
+// WithBrowser.
+func WithRemoteBrowser(r browser.Remote) Option {
+	return func(o *options) {
+		o.remote = &r
+	}
+}
+
+// WithNetworkLog has scrapers record their browser's requests into the
+// operation context's bank.RequestLog.
+func WithNetworkLog(enabled bool) Option {
+	return func(o *options) {
+		o.networkLog = enabled
+	}
+}
+
+// WithSessionLimits overrides the session lifetime and idle timeout of the
+// banks listed, keyed by bank code. Unlisted banks keep their scraper's
+// defaults.
+func WithSessionLimits(lifetimes, idle map[string]time.Duration) Option {
+	return func(o *options) {
+		o.lifetimes, o.idle = lifetimes, idle
+	}
+}
+
+// WithProxies sends the launched browsers' traffic through proxy, or
+// through perBank's proxy for the banks it lists, keyed by bank code.
+// Proxies must be URLs browser.ParseProxy accepts. Remote browsers
+// (WithRemoteBrowser) are left alone: their container sets the proxy.
+func WithProxies(proxy string, perBank map[string]string) Option {
+	return func(o *options) {
+		o.proxy, o.proxies = proxy, perBank
+	}
+}
+
+// WithGeoPreflight has scrapers check the egress IP's country before each
+// login, as c describes. Each bank's check goes through its proxy
+// (WithProxies); c.Client is replaced.
+func WithGeoPreflight(c preflight.GeoCheck) Option {
+	return func(o *options) {
+		o.geo = &c
+	}
+}
+
+// WithEnrichers runs enrichers over every transaction the scrapers return,
+// in order, before callers see them (see bank.Enrich).
+func WithEnrichers(enrichers ...bank.Enricher) Option {
+	return func(o *options) {
+		o.enrichers = append(o.enrichers, enrichers...)
+	}
+}
+
+// WithFlags enables experimental scraper behaviors, per bank; see
+// KnownFlags.
+func WithFlags(flags map[bank.Code]bank.Flags) Option {
+	return func(o *options) {
+		o.flags = flags
+	}
+}
+
+// WithTimings has scrapers record their step latencies into t and size
+// their waits from it; see bank.Timings.
+func WithTimings(t *bank.Timings) Option {
+	return func(o *options) {
+		o.timings = t
+	}
+}
+
 // New creates a ScraperFactory that builds bank-specific scrapers.
 // Currently supports BBVA only.
-func New(timeout time.Duration, headless bool) bank.ScraperFactory {
-	return func(bankCode bank.Code) (bank.Scraper, error) {
+func New(timeout time.Duration, headless bool, opts ...Option) bank.ScraperFactory {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var (
+		mu  sync.Mutex
+		bin string
+	)
+	resolveBin := func() (string, error) {
+		if o.resolver == nil {
+			return "", nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if bin == "" {
+			res, err := o.resolver.Resolve(context.Background())
+			if err != nil {
+				return "", err
+			}
+			bin = res.Path
+		}
+		return bin, nil
+	}
+
+	build := func(bankCode bank.Code) (bank.Scraper, error) {
 		switch bankCode {
 		case bank.BankBBVA:
-			return bbva.NewScraper(
+			opts := []bbva.Option{
 				bbva.WithTimeout(timeout),
+				bbva.WithNetworkLog(o.networkLog),
+				bbva.WithFlags(o.flags[bankCode]),
+				bbva.WithTimings(o.timings),
+			}
+			if d, ok := o.lifetimes[string(bankCode)]; ok {
+				opts = append(opts, bbva.WithSessionLifetime(d))
+			}
+			if d, ok := o.idle[string(bankCode)]; ok {
+				opts = append(opts, bbva.WithIdleTimeout(d))
+			}
+			proxy := cmp.Or(o.proxies[string(bankCode)], o.proxy)
+			if o.geo != nil {
+				geo, err := o.geoCheck(proxy)
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, bbva.WithGeoPreflight(geo))
+			}
+			if o.remote != nil {
+				return bbva.NewScraper(append(opts, bbva.WithRemoteBrowser(*o.remote))...)
+			}
+			bin, err := resolveBin()
+			if err != nil {
+				return nil, err
+			}
+			if proxy != "" {
+				opts = append(opts, bbva.WithProxy(proxy))
+			}
+			return bbva.NewScraper(append(opts,
 				bbva.WithHeadless(headless),
-			)
+				bbva.WithBrowserBin(bin),
+			)...)
 		default:
 			return nil, fmt.Errorf("unsupported bank: %s", bankCode)
 		}
 	}
+
+	return func(bankCode bank.Code) (bank.Scraper, error) {
+		s, err := build(bankCode)
+		if err != nil {
+			return nil, err
+		}
+		return bank.Enrich(bankCode, s, o.enrichers...), nil
+	}
+}
+
+// geoCheck returns the geo preflight for a bank whose browser goes through
+// proxy.
+func (o *options) geoCheck(proxy string) (*preflight.GeoCheck, error) {
+	client, err := preflight.ProxyClient(proxy)
+	if err != nil {
+		return nil, err
+	}
+	geo := *o.geo
+	geo.Client = client
+	return &geo, nil
+}
+
+// NewFromConfig creates a ScraperFactory with cfg's scraper and browser
+// settings. extra options are applied after the ones cfg implies.
+func NewFromConfig(cfg *config.Config, extra ...Option) (bank.ScraperFactory, error) {
+	proxy, proxies, err := parseProxies(cfg.ScraperProxy, cfg.ScraperBankProxies)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := bank.ParseFlags(cfg.FeatureFlags, KnownFlags)
+	if err != nil {
+		return nil, fmt.Errorf("FEATURE_FLAGS: %w", err)
+	}
+	opts := []Option{
+		WithNetworkLog(cfg.ScraperNetworkLog),
+		WithSessionLimits(cfg.BankSessionLifetimes, cfg.BankSessionIdle),
+		WithFlags(flags),
+	}
+	if cfg.GeoPreflightURL != "" {
+		opts = append(opts, WithGeoPreflight(preflight.GeoCheck{
+			Endpoint: cfg.GeoPreflightURL,
+			Country:  cfg.GeoPreflightCountry,
+			Enforce:  cfg.GeoPreflightEnforce,
+		}))
+	}
+
+	if cfg.Browser.RemoteURL != "" {
+		if proxy != "" || len(proxies) > 0 {
+			return nil, errors.New("SCRAPER_PROXY, SCRAPER_BANK_PROXIES: not applied to BROWSER_REMOTE_URL; set the proxy in the browser container instead")
+		}
+		remote := browser.Remote{URL: cfg.Browser.RemoteURL, Timezone: cfg.Browser.Timezone}
+		return New(cfg.ScraperTimeout, cfg.ScraperHeadless, append(append(opts, WithRemoteBrowser(remote)), extra...)...), nil
+	}
+	r, err := Resolver(cfg.Browser)
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg.ScraperTimeout, cfg.ScraperHeadless, append(append(opts, WithBrowser(r), WithProxies(proxy, proxies)), extra...)...), nil
+}
+
+// parseProxies validates the default proxy and the per-bank ones, given as
+// BANK=URL entries, and returns them normalized by browser.ParseProxy.
+func parseProxies(proxy string, perBank []string) (string, map[string]string, error) {
+	if proxy != "" {
+		p, err := browser.ParseProxy(proxy)
+		if err != nil {
+			return "", nil, fmt.Errorf("SCRAPER_PROXY: %w", err)
+		}
+		proxy = p
+	}
+	proxies := make(map[string]string, len(perBank))
+	for _, entry := range perBank {
+		code, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || code == "" {
+			return "", nil, fmt.Errorf("SCRAPER_BANK_PROXIES: %q is not BANK=URL", entry)
+		}
+		p, err := browser.ParseProxy(raw)
+		if err != nil {
+			return "", nil, fmt.Errorf("SCRAPER_BANK_PROXIES: %s: %w", code, err)
+		}
+		proxies[strings.ToUpper(code)] = p
+	}
+	return proxy, proxies, nil
+}
+
+// Resolver returns the browser resolver cfg describes.
+func Resolver(cfg config.BrowserConfig) (browser.Resolver, error) {
+	policy, err := browser.ParsePolicy(cfg.Policy)
+	if err != nil {
+		return browser.Resolver{}, fmt.Errorf("BROWSER_POLICY: %w", err)
+	}
+	return browser.Resolver{
+		Policy:   policy,
+		Bin:      cfg.Bin,
+		CacheDir: cfg.CacheDir,
+		Revision: cfg.Revision,
+		SHA256:   cfg.SHA256,
+	}, nil
 }
@@ -0,0 +1,46 @@
+package factory
+
+import (
+	"testing"
+
+	"github.com/aynifx/bank-scraper/internal/config"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProxies(t *testing.T) {
+	proxy, proxies, err := parseProxies("socks5://127.0.0.1:9050", []string{"bbva=socks5://127.0.0.1:9052", " BCP=http://proxy.local:3128 "})
+	require.NoError(t, err)
+	assert.Equal(t, "socks5://127.0.0.1:9050", proxy)
+	assert.Equal(t, map[string]string{"BBVA": "socks5://127.0.0.1:9052", "BCP": "http://proxy.local:3128"}, proxies)
+
+	_, _, err = parseProxies("tor:9050", nil)
+	assert.ErrorIs(t, err, browser.ErrInvalidProxy)
+	_, _, err = parseProxies("", []string{"BBVA"})
+	assert.ErrorContains(t, err, "not BANK=URL")
+	_, _, err = parseProxies("", []string{"BBVA=socks5://u:p@127.0.0.1:9050"})
+	assert.ErrorIs(t, err, browser.ErrInvalidProxy)
+}
+
+func TestNewFromConfig_RemoteBrowserProxy(t *testing.T) {
+	cfg := &config.Config{ScraperProxy: "socks5://127.0.0.1:9050"}
+	cfg.Browser.RemoteURL = "ws://localhost:3000"
+
+	_, err := NewFromConfig(cfg)
+	assert.ErrorContains(t, err, "BROWSER_REMOTE_URL")
+}
+
+func TestNewFromConfig_FeatureFlags(t *testing.T) {
+	cfg := &config.Config{FeatureFlags: []string{"BBVA:turbo"}}
+	cfg.Browser.RemoteURL = "ws://localhost:3000"
+
+	_, err := NewFromConfig(cfg)
+	assert.ErrorIs(t, err, bank.ErrUnknownFlag)
+	assert.ErrorContains(t, err, "FEATURE_FLAGS")
+
+	cfg.FeatureFlags = []string{"BBVA:legacy-login"}
+	_, err = NewFromConfig(cfg)
+	assert.NoError(t, err)
+}
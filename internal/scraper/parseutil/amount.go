@@ -0,0 +1,133 @@
+// Package parseutil provides the number and date parsing shared by bank
+// parsers: Spanish month names, currency-prefixed amounts ("US$ 1,234.56",
+// "S/ 1,234.56") and per-bank format layouts.
+package parseutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// ErrInvalidAmount is returned when a string cannot be parsed as an amount.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// currencyPrefixes maps the symbols and codes Peruvian portals print before
+// an amount. Longer prefixes come first so "US$" wins over "$" and "S/." over "S/".
+var currencyPrefixes = []struct {
+	prefix   string
+	currency bank.Currency
+}{
+	{"US$", bank.CurrencyUSD},
+	{"USD", bank.CurrencyUSD},
+	{"$", bank.CurrencyUSD},
+	{"S/.", bank.CurrencyPEN},
+	{"S/", bank.CurrencyPEN},
+	{"PEN", bank.CurrencyPEN},
+}
+
+// ParseAmount parses an amount in the default layout ("1,234.56") into cents.
+// A leading currency symbol or code is ignored. See Layout.ParseAmount.
+func ParseAmount(s string) (int64, error) {
+	return DefaultLayout.ParseAmount(s)
+}
+
+// ParseCurrencyAmount parses a currency-prefixed amount such as "US$ 1,234.56"
+// or "S/ -45.00" and returns the currency together with the amount in cents.
+func ParseCurrencyAmount(s string) (bank.Currency, int64, error) {
+	cur, rest, ok := SplitCurrency(s)
+	if !ok {
+		return "", 0, fmt.Errorf("%w: no currency prefix in %q", ErrInvalidAmount, s)
+	}
+	cents, err := ParseAmount(rest)
+	if err != nil {
+		return "", 0, err
+	}
+	return cur, cents, nil
+}
+
+// SplitCurrency strips a leading currency symbol or code and returns the
+// currency and the remaining text. A sign before the symbol ("-S/ 10") is kept
+// in front of the remainder. ok is false when no known prefix is present.
+func SplitCurrency(s string) (cur bank.Currency, rest string, ok bool) {
+	s = strings.TrimSpace(s)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", strings.TrimSpace(s[1:])
+	}
+	upper := strings.ToUpper(s)
+	for _, cp := range currencyPrefixes {
+		if strings.HasPrefix(upper, cp.prefix) {
+			return cp.currency, sign + strings.TrimSpace(s[len(cp.prefix):]), true
+		}
+	}
+	return "", sign + s, false
+}
+
+// parseAmount converts a decimal string to cents using the given separators.
+// Parsing is done on the digits directly (no float64) so large amounts keep
+// their exact value; a third decimal digit rounds half away from zero.
+func parseAmount(s string, decimalSep, thousandsSep rune) (int64, error) {
+	orig := s
+	_, s, _ = SplitCurrency(s)
+
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"):
+		// Accounting notation: (1,234.56) is negative.
+		neg, s = true, s[1:len(s)-1]
+	}
+
+	var intPart, fracPart strings.Builder
+	seenDecimal := false
+	for _, r := range s {
+		switch {
+		case r == thousandsSep || unicode.IsSpace(r):
+			continue
+		case r == decimalSep:
+			if seenDecimal {
+				return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, orig)
+			}
+			seenDecimal = true
+		case r >= '0' && r <= '9':
+			if seenDecimal {
+				fracPart.WriteRune(r)
+			} else {
+				intPart.WriteRune(r)
+			}
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, orig)
+		}
+	}
+	if intPart.Len() == 0 && fracPart.Len() == 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, orig)
+	}
+
+	var cents int64
+	for _, r := range intPart.String() {
+		cents = cents*10 + int64(r-'0')
+		if cents > maxUnits {
+			return 0, fmt.Errorf("%w: %q overflows", ErrInvalidAmount, orig)
+		}
+	}
+	frac := fracPart.String() + "000"
+	cents = cents*100 + int64(frac[0]-'0')*10 + int64(frac[1]-'0')
+	if frac[2] >= '5' {
+		cents++
+	}
+
+	if neg {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+// maxUnits keeps cents*100 within int64.
+const maxUnits = (1<<63 - 1) / 1000
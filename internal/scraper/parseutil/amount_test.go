@@ -0,0 +1,86 @@
+package parseutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "thousands and decimals", input: "12,345.67", want: 12_345_67},
+		{name: "integer", input: "45", want: 4500},
+		{name: "negative", input: "-45", want: -4500},
+		{name: "explicit plus", input: "+1.5", want: 150},
+		{name: "one decimal", input: "123.1", want: 12310},
+		{name: "rounds third decimal", input: "0.005", want: 1},
+		{name: "accounting negative", input: "(1,234.56)", want: -123456},
+		{name: "usd prefix", input: "US$ 1,234.56", want: 123456},
+		{name: "pen prefix", input: "S/ 99.90", want: 9990},
+		{name: "old pen prefix", input: "S/.10", want: 1000},
+		{name: "sign before symbol", input: "-S/ 10.00", want: -1000},
+		{name: "large amount stays exact", input: "98,765,432,109.99", want: 9_876_543_210_999},
+		{name: "empty", input: "", wantErr: true},
+		{name: "only separators", input: ".,  .", wantErr: true},
+		{name: "two decimal points", input: "1.2.3", wantErr: true},
+		{name: "letters", input: "12a", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseAmount(tc.input)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidAmount)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseCurrencyAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantCur bank.Currency
+		wantAmt int64
+		wantErr bool
+	}{
+		{name: "interbank usd", input: "US$ 1,234.56", wantCur: bank.CurrencyUSD, wantAmt: 123456},
+		{name: "dollar sign", input: "$ 10", wantCur: bank.CurrencyUSD, wantAmt: 1000},
+		{name: "soles", input: "S/ -45.00", wantCur: bank.CurrencyPEN, wantAmt: -4500},
+		{name: "iso code", input: "PEN 1,000.00", wantCur: bank.CurrencyPEN, wantAmt: 100000},
+		{name: "no prefix", input: "1,000.00", wantErr: true},
+		{name: "bad amount", input: "US$ abc", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cur, amt, err := ParseCurrencyAmount(tc.input)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidAmount)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCur, cur)
+			assert.Equal(t, tc.wantAmt, amt)
+		})
+	}
+}
+
+func TestLayoutParseAmount_EuropeanSeparators(t *testing.T) {
+	l := Layout{DecimalSep: ',', ThousandsSep: '.'}
+
+	got, err := l.ParseAmount("1.234,56")
+	require.NoError(t, err)
+	assert.Equal(t, int64(123456), got)
+}
@@ -0,0 +1,91 @@
+package parseutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidDate is returned when a string matches none of the tried layouts.
+var ErrInvalidDate = errors.New("invalid date")
+
+// spanishMonths maps lowercase Spanish month names and abbreviations to months.
+// Peruvian portals write September as "Set"/"Setiembre" as well as "Sep".
+var spanishMonths = map[string]time.Month{
+	"ene": time.January, "enero": time.January,
+	"feb": time.February, "febrero": time.February,
+	"mar": time.March, "marzo": time.March,
+	"abr": time.April, "abril": time.April,
+	"may": time.May, "mayo": time.May,
+	"jun": time.June, "junio": time.June,
+	"jul": time.July, "julio": time.July,
+	"ago": time.August, "agosto": time.August,
+	"sep": time.September, "set": time.September, "sept": time.September,
+	"septiembre": time.September, "setiembre": time.September,
+	"oct": time.October, "octubre": time.October,
+	"nov": time.November, "noviembre": time.November,
+	"dic": time.December, "diciembre": time.December,
+}
+
+// SpanishMonth returns the month for a Spanish month name or abbreviation.
+// Matching is case-insensitive and ignores a trailing period ("Ene.").
+func SpanishMonth(name string) (time.Month, bool) {
+	m, ok := spanishMonths[strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")]
+	return m, ok
+}
+
+// TranslateMonths rewrites Spanish month words in s to Go's English
+// abbreviations ("Jan", "Feb", ...) and drops the "de"/"del" connectors, so
+// "12 de Enero de 2026" becomes "12 Jan 2026". Other text is left as-is.
+func TranslateMonths(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return unicode.IsSpace(r) })
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		lw := strings.ToLower(w)
+		if lw == "de" || lw == "del" {
+			continue
+		}
+		out = append(out, translateWord(w))
+	}
+	return strings.Join(out, " ")
+}
+
+// translateWord translates the letter runs inside a single word, so
+// separators are preserved: "12-Ene-2026" -> "12-Jan-2026".
+func translateWord(w string) string {
+	var b strings.Builder
+	runes := []rune(w)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || runes[j] == '.') {
+			j++
+		}
+		token := string(runes[i:j])
+		if m, ok := SpanishMonth(token); ok {
+			b.WriteString(m.String()[:3])
+		} else {
+			b.WriteString(token)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// ParseDate translates Spanish month names and tries each Go time layout in
+// order, returning the first successful parse (in UTC).
+func ParseDate(s string, layouts ...string) (time.Time, error) {
+	clean := TranslateMonths(strings.TrimSpace(s))
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, clean); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+}
@@ -0,0 +1,95 @@
+package parseutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+func TestSpanishMonth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Month
+		ok    bool
+	}{
+		{"Ene", time.January, true},
+		{"enero", time.January, true},
+		{"ABR", time.April, true},
+		{"Ago.", time.August, true},
+		{"Set", time.September, true},
+		{"setiembre", time.September, true},
+		{"Dic", time.December, true},
+		{"Jan", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, ok := SpanishMonth(tc.input)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTranslateMonths(t *testing.T) {
+	assert.Equal(t, "12 Jan 2026", TranslateMonths("12 Ene 2026"))
+	assert.Equal(t, "12 Jan 2026", TranslateMonths("12 de Enero de 2026"))
+	assert.Equal(t, "12-Aug-2026", TranslateMonths("12-Ago-2026"))
+	assert.Equal(t, "Pago Plin", TranslateMonths("Pago  Plin"))
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "abbreviated month", input: "12 Ene 2026", want: time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{name: "full month with connectors", input: "3 de setiembre de 2026", want: time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC)},
+		{name: "dashed", input: "30-Dic-2025", want: time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{name: "numeric slash", input: "05/02/2026", want: time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "numeric dash", input: "05-02-2026", want: time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "surrounding spaces", input: "  1 Abr 2026 ", want: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "unknown month", input: "12 Foo 2026", wantErr: true},
+		{name: "missing year", input: "12 Ene", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DefaultLayout.ParseDate(tc.input)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const code bank.Code = "TEST_PARSEUTIL"
+	custom := Layout{DecimalSep: ',', ThousandsSep: '.', DateLayouts: []string{"2006-01-02"}}
+
+	assert.Equal(t, DefaultLayout.DecimalSep, LayoutFor(code).DecimalSep, "unregistered bank falls back to default")
+
+	got := Register(code, custom)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, code)
+		registryMu.Unlock()
+	})
+	assert.Equal(t, custom, got)
+	assert.Equal(t, custom, LayoutFor(code))
+
+	d, err := LayoutFor(code).ParseDate("2026-03-04")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), d)
+}
@@ -0,0 +1,110 @@
+package parseutil
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DescriptionStep is one stage of a description normalization pipeline.
+type DescriptionStep func(string) string
+
+// DescriptionNormalizer runs raw transaction descriptions through a sequence
+// of steps to produce a human-friendly CleanDescription. The zero value (and a
+// nil pointer) leaves descriptions unchanged apart from trimming.
+type DescriptionNormalizer struct {
+	steps []DescriptionStep
+}
+
+// NewDescriptionNormalizer builds a normalizer that applies steps in order.
+func NewDescriptionNormalizer(steps ...DescriptionStep) *DescriptionNormalizer {
+	return &DescriptionNormalizer{steps: steps}
+}
+
+// DefaultDescriptionSteps is the pipeline used by CleanDescription.
+var DefaultDescriptionSteps = []DescriptionStep{
+	StripCodes,
+	StripAccountFragments,
+	CollapseWhitespace,
+	TitleCasePayee,
+}
+
+// DefaultDescriptionNormalizer applies DefaultDescriptionSteps.
+var DefaultDescriptionNormalizer = NewDescriptionNormalizer(DefaultDescriptionSteps...)
+
+// Normalize runs s through every step and trims the result.
+func (n *DescriptionNormalizer) Normalize(s string) string {
+	if n != nil {
+		for _, step := range n.steps {
+			s = step(s)
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// CleanDescription normalizes s with DefaultDescriptionNormalizer.
+func CleanDescription(s string) string {
+	return DefaultDescriptionNormalizer.Normalize(s)
+}
+
+// codePrefix matches the operation codes portals prepend to descriptions:
+// "*C/", "*/", "C/", "*".
+var codePrefix = regexp.MustCompile(`^\s*(?:\*\s*[A-Z]{0,3}\s*/|[A-Z]{1,2}/|\*+)\s*`)
+
+// StripCodes removes leading operation codes such as "*C/" or "*/".
+func StripCodes(s string) string {
+	for {
+		stripped := codePrefix.ReplaceAllString(s, "")
+		if stripped == s {
+			return s
+		}
+		s = stripped
+	}
+}
+
+// accountFragment matches padded or masked account numbers: runs of 8+
+// digits (optionally dash-separated, e.g. "0011-0123-0200123456") and masked
+// tails like "****1234" or "XXXX1234".
+var accountFragment = regexp.MustCompile(`(?:\*{2,}|[Xx]{3,})\d{3,}|\b\d{4,}(?:-\d{2,})+\b|\b\d{8,}\b`)
+
+// StripAccountFragments removes account numbers and masked account tails.
+func StripAccountFragments(s string) string {
+	return accountFragment.ReplaceAllString(s, "")
+}
+
+// CollapseWhitespace replaces runs of whitespace with a single space and
+// trims separators left dangling at either end (" | -", trailing "-" or "/").
+func CollapseWhitespace(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '|' || r == '-' || r == '/'
+	})
+}
+
+// TitleCasePayee title-cases the payee segment after the last " | " and
+// drops the separator when the payee is empty or a placeholder ("-"). The
+// concept before the separator keeps the bank's casing.
+func TitleCasePayee(s string) string {
+	i := strings.LastIndex(s, "|")
+	if i < 0 {
+		return s
+	}
+	concept, payee := strings.TrimSpace(s[:i]), s[i+1:]
+	payee = strings.Trim(strings.TrimSpace(payee), "-")
+	payee = strings.TrimSpace(payee)
+	if payee == "" {
+		return concept
+	}
+	return concept + " | " + titleCase(payee)
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the rest.
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		runes := []rune(strings.ToLower(w))
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
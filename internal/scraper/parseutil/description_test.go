@@ -0,0 +1,45 @@
+package parseutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanDescription(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain", input: "ITF", want: "ITF"},
+		{name: "trailing padding", input: "COMISION DE MANTENIMIENTO   ", want: "COMISION DE MANTENIMIENTO"},
+		{name: "star code prefix", input: "*C/ ABONO POR TRASPASO", want: "ABONO POR TRASPASO"},
+		{name: "bare star slash", input: "*/PAGO FACTURA", want: "PAGO FACTURA"},
+		{name: "payee title cased", input: "PAGO DE SERVICIOS | MARIA TERESA QUINTANA CASTRO ", want: "PAGO DE SERVICIOS | Maria Teresa Quintana Castro"},
+		{name: "payee after last separator", input: "PAGO | REF AB-12 | JUAN PEREZ", want: "PAGO | REF AB-12 | Juan Perez"},
+		{name: "placeholder payee dropped", input: "NOTA DE CARGO | - ", want: "NOTA DE CARGO"},
+		{name: "padded account fragment", input: "TRANSF. A 0011-0123-0200123456   PROVEEDOR", want: "TRANSF. A PROVEEDOR"},
+		{name: "masked account", input: "ABONO DE ****4321", want: "ABONO DE"},
+		{name: "long digit run", input: "DEPOSITO 000001234567 EN EFECTIVO", want: "DEPOSITO EN EFECTIVO"},
+		{name: "short numbers kept", input: "CUOTA 12 DE 24", want: "CUOTA 12 DE 24"},
+		{name: "empty", input: "", want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, CleanDescription(tc.input))
+		})
+	}
+}
+
+func TestDescriptionNormalizer_CustomSteps(t *testing.T) {
+	n := NewDescriptionNormalizer(CollapseWhitespace, strings.ToLower)
+	assert.Equal(t, "pago factura", n.Normalize("PAGO   FACTURA"))
+}
+
+func TestDescriptionNormalizer_Nil(t *testing.T) {
+	var n *DescriptionNormalizer
+	assert.Equal(t, "*C/ RAW", n.Normalize("  *C/ RAW "))
+}
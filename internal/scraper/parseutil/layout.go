@@ -0,0 +1,66 @@
+package parseutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// Layout describes how a bank portal formats amounts and dates.
+type Layout struct {
+	DecimalSep   rune     // '.' on Peruvian portals
+	ThousandsSep rune     // ','
+	DateLayouts  []string // Go time layouts, tried in order after month translation
+}
+
+// DefaultLayout covers the formats common to Peruvian bank portals:
+// "1,234.56" amounts and numeric or Spanish month-name dates.
+var DefaultLayout = Layout{
+	DecimalSep:   '.',
+	ThousandsSep: ',',
+	DateLayouts: []string{
+		"02/01/2006",
+		"02-01-2006",
+		"2 Jan 2006",
+		"2-Jan-2006",
+		"2/Jan/2006",
+	},
+}
+
+// ParseAmount parses s into cents using the layout's separators. A leading
+// currency symbol or code, a sign, or accounting parentheses are accepted.
+func (l Layout) ParseAmount(s string) (int64, error) {
+	return parseAmount(s, l.DecimalSep, l.ThousandsSep)
+}
+
+// ParseDate parses s with the layout's date layouts.
+func (l Layout) ParseDate(s string) (time.Time, error) {
+	return ParseDate(s, l.DateLayouts...)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[bank.Code]Layout{}
+)
+
+// Register records the layout for a bank and returns it, so a bank package
+// can declare and register its layout in one statement:
+//
+//	var layout = parseutil.Register(bank.BankBBVA, parseutil.Layout{...})
+func Register(code bank.Code, l Layout) Layout {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[code] = l
+	return l
+}
+
+// LayoutFor returns the layout registered for a bank, or DefaultLayout.
+func LayoutFor(code bank.Code) Layout {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if l, ok := registry[code]; ok {
+		return l
+	}
+	return DefaultLayout
+}
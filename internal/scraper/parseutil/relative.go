@@ -0,0 +1,64 @@
+package parseutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// relativeDays maps the relative day words portals show for recent movements
+// to their offset from the reference date.
+var relativeDays = map[string]int{
+	"hoy":      0,
+	"ayer":     -1,
+	"anteayer": -2,
+}
+
+// yearlessLayouts are tried for dates shown without a year ("30 Ene").
+var yearlessLayouts = []string{
+	"2 Jan",
+	"2-Jan",
+	"2/Jan",
+	"02/01",
+}
+
+// ParseRelativeDate parses dates as portals show them for recent activity:
+// "Hoy", "Ayer", "Anteayer", a day and month without year ("30 Ene"), or any
+// full date accepted by the layout. The result is midnight in ref's location.
+//
+// ref is the date the page was rendered, normally time.Now(); it is injected
+// so parsing is deterministic. A year-less date is placed in ref's year, or the
+// previous year if that would put it in the future (a "30 Dic" seen on
+// 2 January belongs to last year).
+func (l Layout) ParseRelativeDate(s string, ref time.Time) (time.Time, error) {
+	clean := strings.ToLower(strings.TrimSpace(s))
+	refDay := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, ref.Location())
+
+	if offset, ok := relativeDays[clean]; ok {
+		return refDay.AddDate(0, 0, offset), nil
+	}
+
+	if t, err := l.ParseDate(s); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, ref.Location()), nil
+	}
+
+	t, err := ParseDate(s, yearlessLayouts...)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+	}
+	year := ref.Year()
+	if t.Month() > ref.Month() || (t.Month() == ref.Month() && t.Day() > ref.Day()) {
+		year--
+	}
+	d := time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, ref.Location())
+	if d.Month() != t.Month() || d.Day() != t.Day() {
+		// 29 Feb outside a leap year normalised into March.
+		return time.Time{}, fmt.Errorf("%w: %q in %d", ErrInvalidDate, s, year)
+	}
+	return d, nil
+}
+
+// ParseRelativeDate parses s with DefaultLayout. See Layout.ParseRelativeDate.
+func ParseRelativeDate(s string, ref time.Time) (time.Time, error) {
+	return DefaultLayout.ParseRelativeDate(s, ref)
+}
@@ -0,0 +1,53 @@
+package parseutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRelativeDate(t *testing.T) {
+	lima := time.FixedZone("PET", -5*60*60)
+	ref := time.Date(2026, 1, 2, 15, 30, 0, 0, lima)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, lima) }
+
+	tests := []struct {
+		name    string
+		input   string
+		ref     time.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "hoy", input: "Hoy", ref: ref, want: day(2026, 1, 2)},
+		{name: "hoy uppercase with spaces", input: " HOY ", ref: ref, want: day(2026, 1, 2)},
+		{name: "ayer", input: "Ayer", ref: ref, want: day(2026, 1, 1)},
+		{name: "ayer crosses year", input: "ayer", ref: day(2026, 1, 1), want: day(2025, 12, 31)},
+		{name: "anteayer", input: "Anteayer", ref: ref, want: day(2025, 12, 31)},
+		{name: "yearless in current year", input: "1 Ene", ref: ref, want: day(2026, 1, 1)},
+		{name: "yearless same day", input: "02 Ene", ref: ref, want: day(2026, 1, 2)},
+		{name: "yearless rolls back a year", input: "30 Dic", ref: ref, want: day(2025, 12, 30)},
+		{name: "yearless full month name", input: "15 de marzo", ref: day(2026, 6, 1), want: day(2026, 3, 15)},
+		{name: "yearless numeric", input: "30/01", ref: day(2026, 2, 10), want: day(2026, 1, 30)},
+		{name: "full date passes through", input: "12 Ene 2024", ref: ref, want: day(2024, 1, 12)},
+		{name: "leap day outside leap year", input: "29 Feb", ref: day(2026, 3, 1), wantErr: true},
+		{name: "leap day in leap year", input: "29 Feb", ref: day(2024, 3, 1), want: day(2024, 2, 29)},
+		{name: "leap day rolled back outside leap year", input: "29 Feb", ref: day(2024, 1, 5), wantErr: true},
+		{name: "unknown word", input: "Mañana", ref: ref, wantErr: true},
+		{name: "empty", input: "", ref: ref, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseRelativeDate(tc.input, tc.ref)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDate)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tc.want.Equal(got), "want %v, got %v", tc.want, got)
+			assert.Equal(t, lima, got.Location())
+		})
+	}
+}
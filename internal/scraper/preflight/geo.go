@@ -0,0 +1,135 @@
+// Package preflight checks the conditions a bank login runs under before it
+// is attempted, so a login bound to be blocked isn't tried at all.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrForeignEgress is returned when logins would leave from outside the
+// country the banks expect. Peruvian banks block, or flag as bots, logins
+// from foreign IPs far more often.
+var ErrForeignEgress = errors.New("egress IP outside the expected country")
+
+// DefaultCountry is the country the supported banks expect logins from.
+const DefaultCountry = "PE"
+
+// lookupTimeout bounds the geo lookup, so a slow endpoint doesn't hold up
+// the login.
+const lookupTimeout = 10 * time.Second
+
+// Egress is where an endpoint saw a request come from.
+type Egress struct {
+	IP      string
+	Country string // ISO 3166-1 alpha-2, upper case
+}
+
+// GeoCheck looks up the egress IP's country before a login. The endpoint
+// returns JSON describing the caller's IP, as ipinfo.io/json, ipapi.co/json
+// and ip-api.com/json do.
+type GeoCheck struct {
+	Endpoint string
+	Country  string       // Expected country; DefaultCountry when empty
+	Enforce  bool         // Refuse the login instead of only warning
+	Client   *http.Client // Should go through the browser's proxy; default http.DefaultClient
+}
+
+// ProxyClient returns an HTTP client going through proxy (http, https or
+// socks5), so a GeoCheck sees the same egress as a browser using it. An
+// empty proxy means a direct connection.
+func ProxyClient(proxy string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// Run looks up the egress and compares its country with the expected one.
+// It returns the egress and, when it's elsewhere or can't be looked up, an
+// error: a refusal to log in when Enforce is set, otherwise a warning for
+// the caller to report.
+func (c *GeoCheck) Run(ctx context.Context) (Egress, error) {
+	want := strings.ToUpper(c.Country)
+	if want == "" {
+		want = DefaultCountry
+	}
+	egress, err := c.lookup(ctx)
+	if err != nil {
+		return egress, fmt.Errorf("geo preflight: %w", err)
+	}
+	if egress.Country != want {
+		return egress, fmt.Errorf("geo preflight: %s is in %s, not %s: %w", egress.IP, egress.Country, want, ErrForeignEgress)
+	}
+	return egress, nil
+}
+
+func (c *GeoCheck) lookup(ctx context.Context) (Egress, error) {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Endpoint, nil)
+	if err != nil {
+		return Egress{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Egress{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Egress{}, fmt.Errorf("%s: status %d", c.Endpoint, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return Egress{}, err
+	}
+	return parseEgress(body)
+}
+
+// parseEgress reads the IP and country from a geo endpoint's JSON, under
+// the field names the common services use.
+func parseEgress(body []byte) (Egress, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return Egress{}, fmt.Errorf("decode geo response: %w", err)
+	}
+	str := func(keys ...string) string {
+		for _, k := range keys {
+			if v, ok := fields[k].(string); ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	egress := Egress{IP: str("ip", "query")}
+	// "country" is a code for ipinfo.io but a name for ip-api.com and
+	// ipapi.co, which put the code in countryCode / country_code.
+	for _, v := range []string{str("country_code", "countryCode"), str("country")} {
+		if len(v) == 2 {
+			egress.Country = strings.ToUpper(v)
+			break
+		}
+	}
+	if egress.Country == "" {
+		return egress, errors.New("geo response has no country code")
+	}
+	return egress, nil
+}
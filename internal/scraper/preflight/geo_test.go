@@ -0,0 +1,86 @@
+package preflight
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEgress(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Egress
+	}{
+		{"ipinfo.io", `{"ip":"190.235.1.2","city":"Lima","country":"PE"}`, Egress{IP: "190.235.1.2", Country: "PE"}},
+		{"ipapi.co", `{"ip":"190.235.1.2","country":"PE","country_code":"PE","country_name":"Peru"}`, Egress{IP: "190.235.1.2", Country: "PE"}},
+		{"ip-api.com", `{"query":"52.1.2.3","country":"United States","countryCode":"US"}`, Egress{IP: "52.1.2.3", Country: "US"}},
+		{"lower case", `{"ip":"1.2.3.4","country":"pe"}`, Egress{IP: "1.2.3.4", Country: "PE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEgress([]byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := parseEgress([]byte(`{"ip":"1.2.3.4","country":"Peru"}`))
+	assert.ErrorContains(t, err, "no country code")
+	_, err = parseEgress([]byte(`<html>`))
+	assert.Error(t, err)
+}
+
+func geoServer(t *testing.T, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGeoCheck_Run(t *testing.T) {
+	ctx := context.Background()
+
+	peru := geoServer(t, `{"ip":"190.235.1.2","country":"PE"}`)
+	egress, err := (&GeoCheck{Endpoint: peru.URL}).Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "PE", egress.Country)
+
+	abroad := geoServer(t, `{"ip":"52.1.2.3","country":"US"}`)
+	egress, err = (&GeoCheck{Endpoint: abroad.URL}).Run(ctx)
+	require.ErrorIs(t, err, ErrForeignEgress)
+	assert.Equal(t, "52.1.2.3", egress.IP)
+
+	_, err = (&GeoCheck{Endpoint: abroad.URL, Country: "us"}).Run(ctx)
+	assert.NoError(t, err, "expected country is configurable")
+}
+
+func TestGeoCheck_Run_LookupFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := (&GeoCheck{Endpoint: srv.URL}).Run(context.Background())
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrForeignEgress)
+	assert.Contains(t, err.Error(), "status 429")
+}
+
+func TestProxyClient(t *testing.T) {
+	c, err := ProxyClient("socks5://127.0.0.1:9050")
+	require.NoError(t, err)
+	proxy, err := c.Transport.(*http.Transport).Proxy(httptest.NewRequest(http.MethodGet, "https://ipinfo.io/json", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "socks5://127.0.0.1:9050", proxy.String())
+
+	c, err = ProxyClient("")
+	require.NoError(t, err)
+	assert.Nil(t, c.Transport.(*http.Transport).Proxy, "direct, not from the environment")
+}
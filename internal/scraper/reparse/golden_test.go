@@ -0,0 +1,57 @@
+package reparse
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files from the current parsers")
+
+// TestGolden checks every bank's parsers against its golden corpus:
+// bank/<code>/testdata/golden/<name>.json is the expected Result for
+// bank/<code>/testdata/fixtures/<name>.html. See the corpus README.
+func TestGolden(t *testing.T) {
+	goldens, err := filepath.Glob(filepath.Join("..", "bank", "*", "testdata", "golden", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, goldens, "no golden files found")
+
+	for _, path := range goldens {
+		bankDir := filepath.Dir(filepath.Dir(filepath.Dir(path)))
+		code := bank.Code(strings.ToUpper(filepath.Base(bankDir)))
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+
+		t.Run(string(code)+"/"+name, func(t *testing.T) {
+			want, err := os.ReadFile(path)
+			require.NoError(t, err)
+			var expected Result
+			require.NoError(t, json.Unmarshal(want, &expected))
+
+			html, err := os.ReadFile(filepath.Join(bankDir, "testdata", "fixtures", name+".html"))
+			require.NoError(t, err)
+
+			got, err := Parse(code, expected.Page, string(html))
+			require.NoError(t, err)
+			// Stamped at parse time, not read from the page.
+			for i := range got.Balances {
+				got.Balances[i].FetchedAt = time.Time{}
+			}
+			out, err := json.MarshalIndent(got, "", "  ")
+			require.NoError(t, err)
+
+			if *update {
+				require.NoError(t, os.WriteFile(path, append(out, '\n'), 0o644))
+				return
+			}
+			assert.JSONEq(t, string(want), string(out), "parser output changed; if intended, run go test ./internal/scraper/reparse -run TestGolden -update")
+		})
+	}
+}
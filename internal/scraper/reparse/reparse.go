@@ -0,0 +1,72 @@
+// Package reparse runs the bank parsers over saved HTML: archived pages for
+// the reparse command, and the golden corpus the tests check parser output
+// against.
+package reparse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/bbva"
+)
+
+// ErrUnrecognized is returned by Auto when no parser accepts the page.
+var ErrUnrecognized = errors.New("no parser recognized the page")
+
+// Pages are the page kinds Parse accepts, in the order Auto tries them.
+var Pages = []string{"accounts", "transactions", "scheduled", "detail"}
+
+// Result is what the parsers extracted from one page.
+type Result struct {
+	Bank         bank.Code                 `json:"bank"`
+	Page         string                    `json:"page"`
+	Balances     []bank.Balance            `json:"balances,omitempty"`
+	Transactions []bank.Transaction        `json:"transactions,omitempty"`
+	Scheduled    []bank.ScheduledOperation `json:"scheduled_operations,omitempty"`
+	Detail       *bbva.TransactionDetail   `json:"detail,omitempty"`
+	HasMore      bool                      `json:"has_more,omitempty"` // Transactions page offers "Ver más"
+	Warnings     []string                  `json:"warnings,omitempty"`
+}
+
+// Parse runs code's parser for page over html.
+func Parse(code bank.Code, page, html string) (*Result, error) {
+	if code != bank.BankBBVA {
+		return nil, fmt.Errorf("no parsers for bank %s", code)
+	}
+
+	res := &Result{Bank: code, Page: page}
+	var err error
+	switch page {
+	case "accounts":
+		res.Balances, res.Warnings, err = bbva.ParseAccountBalancesWithWarnings(html)
+	case "transactions":
+		res.Transactions, err = bbva.ParseTransactions(html)
+		res.HasMore = bbva.HasMoreTransactions(html)
+	case "scheduled":
+		res.Scheduled, err = bbva.ParseScheduledOperations(html)
+	case "detail":
+		res.Detail, err = bbva.ParseTransactionDetail(html)
+	default:
+		return nil, fmt.Errorf("unknown page %q (want %s)", page, strings.Join(Pages, "|"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Auto tries each of Pages in turn and returns the first result. The error
+// lists why each parser rejected the page.
+func Auto(code bank.Code, html string) (*Result, error) {
+	errs := []error{ErrUnrecognized}
+	for _, page := range Pages {
+		res, err := Parse(code, page, html)
+		if err == nil {
+			return res, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", page, err))
+	}
+	return nil, errors.Join(errs...)
+}
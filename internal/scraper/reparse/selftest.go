@@ -0,0 +1,97 @@
+package reparse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// ErrNoCorpus is returned by SelfTest when there is no corpus or it has no
+// golden files.
+var ErrNoCorpus = errors.New("no golden files in corpus")
+
+// Check is the outcome of parsing one corpus page.
+type Check struct {
+	Bank  bank.Code `json:"bank"`
+	Name  string    `json:"name"`
+	Page  string    `json:"page"`
+	OK    bool      `json:"ok"`
+	Error string    `json:"error,omitempty"`
+}
+
+// SelfTest runs every bank's parsers over the golden corpus in corpus, laid
+// out as bank.Corpus is, and reports for each page whether the parsers
+// still produce its golden output. It is TestGolden for installed binaries.
+func SelfTest(corpus fs.FS) ([]Check, error) {
+	if corpus == nil {
+		return nil, ErrNoCorpus
+	}
+	goldens, err := fs.Glob(corpus, "*/testdata/golden/*.json")
+	if err != nil {
+		return nil, err
+	}
+	if len(goldens) == 0 {
+		return nil, ErrNoCorpus
+	}
+
+	checks := make([]Check, 0, len(goldens))
+	for _, p := range goldens {
+		dir := path.Dir(path.Dir(path.Dir(p)))
+		c := Check{Bank: bank.Code(strings.ToUpper(dir)), Name: strings.TrimSuffix(path.Base(p), ".json")}
+		if err := checkGolden(corpus, p, path.Join(dir, "testdata", "fixtures", c.Name+".html"), &c); err != nil {
+			c.Error = err.Error()
+		} else {
+			c.OK = true
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+func checkGolden(corpus fs.FS, goldenPath, htmlPath string, c *Check) error {
+	data, err := fs.ReadFile(corpus, goldenPath)
+	if err != nil {
+		return err
+	}
+	var want any
+	var expected Result
+	if err := json.Unmarshal(data, &want); err != nil {
+		return fmt.Errorf("golden file: %w", err)
+	}
+	if err := json.Unmarshal(data, &expected); err != nil {
+		return fmt.Errorf("golden file: %w", err)
+	}
+	c.Page = expected.Page
+
+	html, err := fs.ReadFile(corpus, htmlPath)
+	if err != nil {
+		return err
+	}
+	res, err := Parse(c.Bank, expected.Page, string(html))
+	if err != nil {
+		return err
+	}
+	// Stamped at parse time, not read from the page.
+	for i := range res.Balances {
+		res.Balances[i].FetchedAt = time.Time{}
+	}
+	out, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	var got any
+	if err := json.Unmarshal(out, &got); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(got, want) {
+		return errors.New("parser output differs from the golden file")
+	}
+	return nil
+}
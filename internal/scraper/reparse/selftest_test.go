@@ -0,0 +1,52 @@
+package reparse
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest_Corpus(t *testing.T) {
+	corpora := map[string]fs.FS{"source tree": os.DirFS("../bank")}
+	if bank.Corpus() != nil { // -tags fixtures
+		corpora["embedded"] = bank.Corpus()
+	}
+	for name, corpus := range corpora {
+		checks, err := SelfTest(corpus)
+		require.NoError(t, err, name)
+		require.NotEmpty(t, checks, name)
+		for _, c := range checks {
+			assert.True(t, c.OK, "%s: %s/%s: %s", name, c.Bank, c.Name, c.Error)
+			assert.NotEmpty(t, c.Page)
+		}
+	}
+}
+
+func TestSelfTest_Mismatch(t *testing.T) {
+	html, err := os.ReadFile("../bank/bbva/testdata/fixtures/transactions_empty.html")
+	require.NoError(t, err)
+	corpus := fstest.MapFS{
+		"bbva/testdata/fixtures/empty.html":   {Data: html},
+		"bbva/testdata/golden/empty.json":     {Data: []byte(`{"bank":"BBVA","page":"transactions","has_more":true}`)},
+		"bbva/testdata/golden/missing.json":   {Data: []byte(`{"page":"accounts"}`)},
+		"bbva/testdata/golden/malformed.json": {Data: []byte(`{`)},
+	}
+
+	checks, err := SelfTest(corpus)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+	assert.Equal(t, Check{Bank: bank.BankBBVA, Name: "empty", Page: "transactions", Error: "parser output differs from the golden file"}, checks[0])
+	assert.Contains(t, checks[1].Error, "golden file")
+	assert.Equal(t, "accounts", checks[2].Page)
+	assert.False(t, checks[2].OK)
+
+	_, err = SelfTest(fstest.MapFS{})
+	assert.ErrorIs(t, err, ErrNoCorpus)
+	_, err = SelfTest(nil)
+	assert.ErrorIs(t, err, ErrNoCorpus)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -167,14 +168,48 @@ func SaveHAR(path string, har *HARLog) error {
 	return nil
 }
 
+// LoadOption configures MustLoadHAR and MustLoadScenario.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	sanitizeCheck bool
+}
+
+// WithSanitizeCheck fails the test when the recording still holds values
+// SanitizeHAR would redact (see ScanHAR), so a raw recording committed by
+// mistake isn't used silently.
+func WithSanitizeCheck(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.sanitizeCheck = enabled
+	}
+}
+
 // MustLoadHAR loads a HAR file and fails the test if it cannot be loaded.
-func MustLoadHAR(t *testing.T, path string) *HARLog {
+func MustLoadHAR(t testing.TB, path string, opts ...LoadOption) *HARLog {
 	t.Helper()
 
 	har, err := LoadHAR(path)
 	if err != nil {
 		t.Fatalf("failed to load HAR file %s: %v", path, err)
 	}
+	checkLoaded(t, path, har, opts)
 
 	return har
 }
+
+// checkLoaded runs the checks opts ask for on har, loaded from path.
+func checkLoaded(t testing.TB, path string, har *HARLog, opts []LoadOption) {
+	t.Helper()
+
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.sanitizeCheck {
+		return
+	}
+	if findings := ScanHAR(har); len(findings) > 0 {
+		t.Fatalf("HAR file %s is not sanitized (%s); run: go run ./scripts/sanitize-har -input=%s",
+			path, strings.Join(findings, "; "), path)
+	}
+}
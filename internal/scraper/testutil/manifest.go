@@ -0,0 +1,150 @@
+package testutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ManifestName is the file, in a bank's recordings directory, that lists
+// its scenarios.
+const ManifestName = "manifest.json"
+
+// MaxScenarioAge is how old a recording gets before tests loading it
+// warn that it may no longer match the portal.
+const MaxScenarioAge = 180 * 24 * time.Hour
+
+// ErrUnknownScenario is returned for a scenario the manifest doesn't list.
+var ErrUnknownScenario = errors.New("unknown scenario")
+
+// Scenario describes one recording: the flow it captured and when, so a
+// recording made against an older portal can be told apart and refreshed.
+type Scenario struct {
+	Name          string    `json:"name"` // Recording is {name}.har.json next to the manifest
+	Description   string    `json:"description"`
+	PortalVersion string    `json:"portal_version,omitempty"` // As shown by the portal, if it shows one
+	Created       time.Time `json:"created"`
+	Entries       int       `json:"entries"` // Requests in the recording, to catch hand edits
+}
+
+// Manifest is a bank's registry of recorded scenarios, kept as
+// recordings/manifest.json.
+type Manifest struct {
+	Scenarios []Scenario `json:"scenarios"`
+}
+
+// LoadManifest reads the manifest of the recordings in dir. A directory
+// without one has an empty manifest.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// SaveManifest writes m as the manifest of the recordings in dir, its
+// scenarios sorted by name.
+func SaveManifest(dir string, m *Manifest) error {
+	slices.SortFunc(m.Scenarios, func(a, b Scenario) int { return strings.Compare(a.Name, b.Name) })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
+
+// Scenario returns the scenario called name.
+func (m *Manifest) Scenario(name string) (Scenario, error) {
+	for _, sc := range m.Scenarios {
+		if sc.Name == name {
+			return sc, nil
+		}
+	}
+	return Scenario{}, fmt.Errorf("%w: %q is not in %s", ErrUnknownScenario, name, ManifestName)
+}
+
+// Put adds sc, replacing the scenario of the same name.
+func (m *Manifest) Put(sc Scenario) {
+	for i := range m.Scenarios {
+		if m.Scenarios[i].Name == sc.Name {
+			m.Scenarios[i] = sc
+			return
+		}
+	}
+	m.Scenarios = append(m.Scenarios, sc)
+}
+
+// Stale returns the scenarios recorded more than maxAge before now.
+func (m *Manifest) Stale(maxAge time.Duration, now time.Time) []Scenario {
+	var stale []Scenario
+	for _, sc := range m.Scenarios {
+		if now.Sub(sc.Created) > maxAge {
+			stale = append(stale, sc)
+		}
+	}
+	return stale
+}
+
+// LoadScenario loads the recording of the scenario called name from dir,
+// checking it against the manifest: the scenario must be listed and the
+// recording hold the entries the manifest says.
+func LoadScenario(dir, name string) (*HARLog, Scenario, error) {
+	m, err := LoadManifest(dir)
+	if err != nil {
+		return nil, Scenario{}, err
+	}
+	sc, err := m.Scenario(name)
+	if err != nil {
+		return nil, Scenario{}, err
+	}
+	har, err := LoadHAR(ScenarioPath(dir, name))
+	if err != nil {
+		return nil, sc, err
+	}
+	if len(har.Entries) != sc.Entries {
+		return nil, sc, fmt.Errorf("scenario %q: recording has %d entries, manifest says %d; re-record it or update %s",
+			name, len(har.Entries), sc.Entries, ManifestName)
+	}
+	return har, sc, nil
+}
+
+// ScenarioPath returns where the recording of the scenario called name is
+// kept in dir.
+func ScenarioPath(dir, name string) string {
+	return filepath.Join(dir, name+".har.json")
+}
+
+// MustLoadScenario loads a scenario like LoadScenario and fails the test if
+// it cannot be loaded or fails the checks opts ask for. A scenario older
+// than MaxScenarioAge is logged as stale.
+func MustLoadScenario(t testing.TB, dir, name string, opts ...LoadOption) (*HARLog, Scenario) {
+	t.Helper()
+
+	har, sc, err := LoadScenario(dir, name)
+	if err != nil {
+		t.Fatalf("load scenario %s: %v", name, err)
+	}
+	checkLoaded(t, ScenarioPath(dir, name), har, opts)
+	if age := time.Since(sc.Created); age > MaxScenarioAge {
+		t.Logf("scenario %s is stale: recorded %s (portal %s), %d days ago; refresh it with scripts/record-scenario",
+			name, sc.Created.Format(time.DateOnly), sc.PortalVersion, int(age.Hours()/24))
+	}
+	return har, sc
+}
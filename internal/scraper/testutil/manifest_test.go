@@ -0,0 +1,51 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	m, err := LoadManifest(dir)
+	require.NoError(t, err, "no manifest yet")
+	assert.Empty(t, m.Scenarios)
+
+	created := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	m.Put(Scenario{Name: "logout", Created: created, Entries: 1})
+	m.Put(Scenario{Name: "login-success", Description: "Senda login", Created: created, Entries: 2})
+	m.Put(Scenario{Name: "logout", Created: created.AddDate(0, 6, 0), Entries: 1})
+	require.NoError(t, SaveManifest(dir, m))
+
+	loaded, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"login-success", "logout"}, []string{loaded.Scenarios[0].Name, loaded.Scenarios[1].Name})
+	stale := loaded.Stale(MaxScenarioAge, created.AddDate(0, 7, 0))
+	require.Len(t, stale, 1)
+	assert.Equal(t, "login-success", stale[0].Name)
+
+	_, err = loaded.Scenario("transactions-all")
+	assert.ErrorIs(t, err, ErrUnknownScenario)
+}
+
+func TestLoadScenario(t *testing.T) {
+	dir := t.TempDir()
+	har := &HARLog{Entries: []HAREntry{{Request: HARRequest{Method: "GET", URL: "https://example.com/"}}}}
+	require.NoError(t, SaveHAR(ScenarioPath(dir, "logout"), har))
+	require.NoError(t, SaveManifest(dir, &Manifest{Scenarios: []Scenario{{Name: "logout", Entries: 1}}}))
+
+	loaded, sc, err := LoadScenario(dir, "logout")
+	require.NoError(t, err)
+	assert.Len(t, loaded.Entries, 1)
+	assert.Equal(t, "logout", sc.Name)
+
+	require.NoError(t, SaveManifest(dir, &Manifest{Scenarios: []Scenario{{Name: "logout", Entries: 3}}}))
+	_, _, err = LoadScenario(dir, "logout")
+	assert.ErrorContains(t, err, "recording has 1 entries, manifest says 3")
+
+	_, _, err = LoadScenario(dir, "login-success")
+	assert.ErrorIs(t, err, ErrUnknownScenario)
+}
@@ -0,0 +1,122 @@
+package testutil
+
+import (
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Recorder captures the traffic of a live session as HAR entries, the
+// counterpart of Replayer. Pass its Middleware as a scraper's hijacker, run
+// a flow, then Take the entries recorded for it.
+type Recorder struct {
+	client *http.Client
+
+	mu      sync.Mutex
+	entries []HAREntry
+
+	verbose bool
+}
+
+// NewRecorder creates a recorder that forwards requests with
+// http.DefaultClient.
+func NewRecorder() *Recorder {
+	return &Recorder{client: http.DefaultClient}
+}
+
+// SetVerbose enables logging of recorded requests.
+func (r *Recorder) SetVerbose(enabled bool) {
+	r.verbose = enabled
+}
+
+// Middleware returns a Rod hijack handler that sends each request to the
+// network and records the request/response pair. Requests that fail are
+// failed in the browser too and not recorded.
+func (r *Recorder) Middleware() func(*rod.Hijack) {
+	return func(h *rod.Hijack) {
+		if err := h.LoadResponse(r.client, true); err != nil {
+			if r.verbose {
+				slog.Warn("recorder: request failed", slog.String("method", h.Request.Method()),
+					slog.String("url", h.Request.URL().String()), slog.Any("error", err))
+			}
+			h.Response.Fail(proto.NetworkErrorReasonConnectionFailed)
+			return
+		}
+
+		entry := HAREntry{
+			Request: HARRequest{
+				Method:  h.Request.Method(),
+				URL:     h.Request.URL().String(),
+				Headers: harHeaders(h.Request.Req().Header),
+				Body:    h.Request.Body(),
+			},
+			Response: harResponse(h.Response),
+		}
+		if r.verbose {
+			slog.Debug("recorder: recorded", slog.String("method", entry.Request.Method),
+				slog.String("url", entry.Request.URL), slog.Int("status", entry.Response.Status))
+		}
+
+		r.mu.Lock()
+		r.entries = append(r.entries, entry)
+		r.mu.Unlock()
+	}
+}
+
+// Take returns the entries recorded since the last call, in request order,
+// and starts a new recording. The result is unsanitized: pass it through
+// SanitizeHAR before writing it anywhere.
+func (r *Recorder) Take() *HARLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	har := &HARLog{Entries: r.entries}
+	r.entries = nil
+	return har
+}
+
+func harResponse(resp *rod.HijackResponse) HARResponse {
+	payload := resp.Payload()
+	headers := make([]HARHeader, 0, len(payload.ResponseHeaders))
+	for _, h := range payload.ResponseHeaders {
+		headers = append(headers, HARHeader{Name: h.Name, Value: h.Value})
+	}
+
+	content := HARContent{
+		MimeType: resp.Headers().Get("Content-Type"),
+		Size:     len(payload.Body),
+	}
+	if utf8.Valid(payload.Body) {
+		content.Text = string(payload.Body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(payload.Body)
+		content.Encoding = "base64"
+	}
+
+	return HARResponse{
+		Status:  payload.ResponseCode,
+		Headers: headers,
+		Content: content,
+	}
+}
+
+// harHeaders converts h to HAR headers, sorted by name so recordings diff
+// cleanly between refreshes.
+func harHeaders(h http.Header) []HARHeader {
+	var out []HARHeader
+	for name, values := range h {
+		for _, v := range values {
+			out = append(out, HARHeader{Name: name, Value: v})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out
+}
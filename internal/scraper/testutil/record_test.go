@@ -0,0 +1,61 @@
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Take(t *testing.T) {
+	r := NewRecorder()
+	r.entries = []HAREntry{{Request: HARRequest{Method: "GET", URL: "https://bank.example/"}}}
+
+	har := r.Take()
+	assert.Len(t, har.Entries, 1)
+	assert.Empty(t, r.Take().Entries, "starts a new recording")
+}
+
+func TestHARHeaders_Sorted(t *testing.T) {
+	h := http.Header{"X-B": {"2"}, "accept": {"*/*"}, "Cookie": {"a=1", "b=2"}}
+
+	assert.Equal(t, []HARHeader{
+		{Name: "accept", Value: "*/*"},
+		{Name: "Cookie", Value: "a=1"},
+		{Name: "Cookie", Value: "b=2"},
+		{Name: "X-B", Value: "2"},
+	}, harHeaders(h))
+}
+
+func TestRecorder_Middleware(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires browser")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>cuentas</body></html>"))
+	}))
+	defer srv.Close()
+
+	browser := rod.New().MustConnect()
+	defer browser.MustClose()
+	page := browser.MustPage("")
+	rec := NewRecorder()
+	router := page.HijackRequests()
+	router.MustAdd("*", rec.Middleware())
+	go router.Run()
+	defer router.MustStop()
+
+	page.MustNavigate(srv.URL + "/cuentas").MustWaitLoad()
+
+	har := rec.Take()
+	require.NotEmpty(t, har.Entries)
+	entry := har.Entries[0]
+	assert.Equal(t, srv.URL+"/cuentas", entry.Request.URL)
+	assert.Equal(t, 200, entry.Response.Status)
+	assert.Equal(t, "text/html", entry.Response.Content.MimeType)
+	assert.Contains(t, entry.Response.Content.Text, "cuentas")
+}
@@ -0,0 +1,173 @@
+package testutil
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aynifx/bank-scraper/internal/money"
+)
+
+// Redactor replaces personal data in recordings (account numbers, names,
+// balances) with stand-ins of the same shape, so the scraper still parses
+// the redacted pages. SanitizeHAR only removes credentials and tokens; a
+// Redactor removes what the recorded pages show. Each value gets one
+// stand-in wherever it appears, so an expected result redacted with the
+// same Redactor still matches the redacted recording.
+type Redactor struct {
+	text    map[string]string // Real string → stand-in
+	amounts map[int64]int64   // Real amount in cents → stand-in
+	names   int
+}
+
+// NewRedactor creates a Redactor with nothing registered.
+func NewRedactor() *Redactor {
+	return &Redactor{text: map[string]string{}, amounts: map[int64]int64{}}
+}
+
+// AddAccount registers an account number. Its digits after the first four
+// (the bank's entity code) are replaced, keeping separators, and so is its
+// digits-only form, which some pages show.
+func (r *Redactor) AddAccount(number string) {
+	if number == "" || r.text[number] != "" {
+		return
+	}
+	seed := sha256.Sum256([]byte(number))
+	digit := 0
+	standIn := []byte(number)
+	for i, c := range standIn {
+		if c < '0' || c > '9' {
+			continue
+		}
+		if digit >= 4 {
+			standIn[i] = '0' + seed[digit%len(seed)]%10
+		}
+		digit++
+	}
+	r.text[number] = string(standIn)
+	if compact := digitsOnly(number); compact != number && len(compact) > 4 {
+		r.text[compact] = digitsOnly(string(standIn))
+	}
+}
+
+// AddName registers a name (the company's, a signatory's), replaced by
+// "TITULAR n".
+func (r *Redactor) AddName(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" || r.text[name] != "" {
+		return
+	}
+	r.names++
+	r.text[name] = fmt.Sprintf("TITULAR %d", r.names)
+}
+
+// AddAmount registers an amount in cents, such as a balance. Amount returns
+// its stand-in, which has as many digits, and Text replaces it in its
+// formatted forms (12,345.67 and 12345.67).
+func (r *Redactor) AddAmount(cents int64) {
+	cents = max(cents, -cents)
+	if cents < 100 {
+		return // A few cents say nothing, and would match too much
+	}
+	if _, ok := r.amounts[cents]; ok {
+		return
+	}
+	seed := sha256.Sum256(fmt.Appendf(nil, "%d", cents))
+	digits := []byte(fmt.Sprint(cents))
+	for i := range digits {
+		digits[i] = '0' + seed[i%len(seed)]%10
+	}
+	if digits[0] == '0' {
+		digits[0] = '1'
+	}
+	var standIn int64
+	_, _ = fmt.Sscan(string(digits), &standIn)
+	r.amounts[cents] = standIn
+
+	plain, grouped := formatAmount(cents)
+	r.text[plain], r.text[grouped] = formatAmount(standIn)
+}
+
+// Amount returns the stand-in of a registered amount, keeping its sign, or
+// cents unchanged.
+func (r *Redactor) Amount(cents int64) int64 {
+	if cents < 0 {
+		return -r.Amount(-cents)
+	}
+	if standIn, ok := r.amounts[cents]; ok {
+		return standIn
+	}
+	return cents
+}
+
+// Text returns s with every registered value replaced.
+func (r *Redactor) Text(s string) string {
+	if len(r.text) == 0 {
+		return s
+	}
+	// Longest first, so a value containing another is replaced whole.
+	olds := make([]string, 0, len(r.text))
+	for old := range r.text {
+		olds = append(olds, old)
+	}
+	slices.SortFunc(olds, func(a, b string) int {
+		return cmp.Or(len(b)-len(a), strings.Compare(a, b))
+	})
+	pairs := make([]string, 0, 2*len(olds))
+	for _, old := range olds {
+		pairs = append(pairs, old, r.text[old])
+	}
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+// HAR returns a copy of har with every registered value replaced in URLs,
+// headers and text bodies. Base64 bodies are left as they are.
+func (r *Redactor) HAR(har *HARLog) *HARLog {
+	out := &HARLog{Entries: make([]HAREntry, len(har.Entries))}
+	for i, e := range har.Entries {
+		e.Request.URL = r.Text(e.Request.URL)
+		e.Request.Headers = r.headers(e.Request.Headers)
+		e.Request.Body = r.Text(e.Request.Body)
+		e.Response.Headers = r.headers(e.Response.Headers)
+		if e.Response.Content.Encoding == "" {
+			e.Response.Content.Text = r.Text(e.Response.Content.Text)
+			e.Response.Content.Size = len(e.Response.Content.Text)
+		}
+		out.Entries[i] = e
+	}
+	return out
+}
+
+func (r *Redactor) headers(headers []HARHeader) []HARHeader {
+	if headers == nil {
+		return nil
+	}
+	out := make([]HARHeader, len(headers))
+	for i, h := range headers {
+		out[i] = HARHeader{Name: h.Name, Value: r.Text(h.Value)}
+	}
+	return out
+}
+
+// formatAmount writes positive cents as the portals do: plain (12345.67)
+// and with thousands separators (12,345.67).
+func formatAmount(cents int64) (plain, grouped string) {
+	plain = money.FormatCents(cents)
+	units, decimals, _ := strings.Cut(plain, ".")
+	for len(units) > 3 {
+		grouped = "," + units[len(units)-3:] + grouped
+		units = units[:len(units)-3]
+	}
+	return plain, units + grouped + "." + decimals
+}
+
+func digitsOnly(s string) string {
+	return strings.Map(func(c rune) rune {
+		if c < '0' || c > '9' {
+			return -1
+		}
+		return c
+	}, s)
+}
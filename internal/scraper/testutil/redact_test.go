@@ -0,0 +1,70 @@
+package testutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor()
+	r.AddAccount("0011-0130-0100012345")
+	r.AddName("ACME SAC")
+	r.AddAmount(1234567)
+	r.AddAmount(-50) // too small to redact
+
+	har := &HARLog{Entries: []HAREntry{{
+		Request: HARRequest{Method: "GET", URL: "https://bank.example/cuentas?nro=00110130010001234"},
+		Response: HARResponse{
+			Status:  200,
+			Headers: []HARHeader{{Name: "X-Account", Value: "0011-0130-0100012345"}},
+			Content: HARContent{Text: "<td>ACME SAC</td><td>0011-0130-0100012345</td><td>S/ 12,345.67</td><td>0.50</td>"},
+		},
+	}, {
+		Response: HARResponse{Content: HARContent{Text: "QUNNRSBTQUM=", Encoding: "base64"}},
+	}}}
+	out := r.HAR(har)
+
+	account := r.Text("0011-0130-0100012345")
+	assert.NotEqual(t, "0011-0130-0100012345", account)
+	assert.Regexp(t, `^0011-\d{4}-\d{10}$`, account, "same shape, entity code kept")
+
+	text := out.Entries[0].Response.Content.Text
+	for _, real := range []string{"ACME SAC", "0011-0130-0100012345", "12,345.67"} {
+		assert.NotContains(t, text, real)
+	}
+	assert.Contains(t, text, "TITULAR 1")
+	assert.Contains(t, text, account)
+	assert.Contains(t, text, "0.50")
+	assert.Len(t, text, out.Entries[0].Response.Content.Size)
+	assert.Equal(t, account, out.Entries[0].Response.Headers[0].Value)
+	assert.NotContains(t, out.Entries[0].Request.URL, "0100012345", "digits-only form too")
+	assert.Equal(t, "QUNNRSBTQUM=", out.Entries[1].Response.Content.Text, "base64 left alone")
+	assert.Contains(t, har.Entries[0].Response.Content.Text, "ACME SAC", "input untouched")
+
+	standIn := r.Amount(1234567)
+	require.NotEqual(t, int64(1234567), standIn)
+	assert.Len(t, strings.TrimLeft(formatCents(standIn), "-"), len("12345.67"))
+	assert.Equal(t, -standIn, r.Amount(-1234567))
+	assert.Equal(t, int64(-50), r.Amount(-50))
+	_, grouped := formatAmount(standIn)
+	assert.Contains(t, text, grouped, "text and Amount agree")
+}
+
+func formatCents(cents int64) string {
+	plain, _ := formatAmount(cents)
+	return plain
+}
+
+func TestFormatAmount(t *testing.T) {
+	for cents, want := range map[int64][2]string{
+		50:         {"0.50", "0.50"},
+		123456:     {"1234.56", "1,234.56"},
+		1234567890: {"12345678.90", "12,345,678.90"},
+	} {
+		plain, grouped := formatAmount(cents)
+		assert.Equal(t, want, [2]string{plain, grouped})
+	}
+}
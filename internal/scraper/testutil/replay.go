@@ -2,8 +2,13 @@ package testutil
 
 import (
 	"encoding/base64"
+	"io/fs"
 	"log"
+	"mime"
+	"net/http"
 	"net/url"
+	"os"
+	"path"
 	"strings"
 
 	"github.com/go-rod/rod"
@@ -26,9 +31,15 @@ type Replayer struct {
 	// methodPath maps "METHOD|path" to entries (first wins per method+path)
 	methodPath map[string]*HAREntry
 
+	// static serves GET requests the HAR doesn't cover, by URL path (nil: none)
+	static fs.FS
+
 	// passthrough allows unmatched requests to go to the network
 	passthrough bool
 
+	// fallback serves unmatched requests instead of passthrough or a 404
+	fallback func(*rod.Hijack)
+
 	// verbose enables logging of matched/unmatched requests
 	verbose bool
 }
@@ -44,6 +55,27 @@ func WithPassthrough(enabled bool) ReplayerOption {
 	}
 }
 
+// WithFallback hands requests the HAR and static directory don't cover to
+// next, e.g. a Recorder's Middleware to replay what was recorded and record
+// what wasn't: the first handler to match a request serves it. It takes
+// precedence over WithPassthrough.
+func WithFallback(next func(*rod.Hijack)) ReplayerOption {
+	return func(r *Replayer) {
+		r.fallback = next
+	}
+}
+
+// WithStaticDir serves GET requests the HAR has no entry for from files under
+// dir, keyed by URL path: https://host/assets/app.js is served from
+// dir/assets/app.js whatever the host. Use it for recordings that left out
+// CSS and JS assets the replayed pages need to run. Requests with no file
+// there fall through to passthrough or a 404 as usual.
+func WithStaticDir(dir string) ReplayerOption {
+	return func(r *Replayer) {
+		r.static = os.DirFS(dir)
+	}
+}
+
 // WithVerbose enables verbose logging of request matching.
 func WithVerbose(enabled bool) ReplayerOption {
 	return func(r *Replayer) {
@@ -132,10 +164,27 @@ func (r *Replayer) Middleware() func(*rod.Hijack) {
 		}
 
 		if !found {
+			// 5. Static asset directory
+			if body, contentType, ok := r.staticAsset(method, reqURL); ok {
+				if r.verbose {
+					log.Printf("[replayer] static: %s %s", method, reqURL)
+				}
+				payload := ctx.Response.Payload()
+				payload.ResponseCode = http.StatusOK
+				payload.ResponseHeaders = []*proto.FetchHeaderEntry{{Name: "Content-Type", Value: contentType}}
+				payload.Body = body
+				return
+			}
+
 			if r.verbose {
 				log.Printf("[replayer] no match for: %s %s", method, reqURL)
 			}
 
+			if r.fallback != nil {
+				r.fallback(ctx)
+				return
+			}
+
 			if r.passthrough {
 				// Let it go to the real network
 				_ = ctx.LoadResponse(nil, true)
@@ -210,6 +259,32 @@ func (r *Replayer) serveRecordedResponse(ctx *rod.Hijack, entry *HAREntry) {
 	payload.Body = body
 }
 
+// staticAsset returns the file serving a GET or HEAD request from the static
+// directory, and its content type from the extension or, failing that, the
+// content. Paths escaping the directory are never served.
+func (r *Replayer) staticAsset(method, reqURL string) ([]byte, string, bool) {
+	if r.static == nil || (method != http.MethodGet && method != http.MethodHead) {
+		return nil, "", false
+	}
+	parsed, err := url.Parse(reqURL)
+	if err != nil {
+		return nil, "", false
+	}
+	name := strings.TrimPrefix(path.Clean("/"+parsed.Path), "/")
+	if !fs.ValidPath(name) || name == "." {
+		return nil, "", false
+	}
+	body, err := fs.ReadFile(r.static, name)
+	if err != nil {
+		return nil, "", false
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	return body, contentType, true
+}
+
 // followRedirects follows a redirect chain and returns the final entry.
 // If the entry is not a redirect or the target is not in the HAR, returns the original entry.
 func (r *Replayer) followRedirects(entry *HAREntry) *HAREntry {
@@ -0,0 +1,42 @@
+package testutil
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayer_StaticAsset(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "app.js"), []byte("console.log(1)"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "blob"), []byte("<html></html>"), 0o644))
+	r := NewReplayer(&HARLog{}, WithStaticDir(dir))
+
+	body, contentType, ok := r.staticAsset(http.MethodGet, "https://cdn.example.com/assets/app.js?v=3")
+	require.True(t, ok)
+	assert.Equal(t, "console.log(1)", string(body))
+	assert.Contains(t, contentType, "javascript")
+
+	_, contentType, ok = r.staticAsset(http.MethodHead, "https://other.example.com/assets/blob")
+	require.True(t, ok)
+	assert.Equal(t, "text/html; charset=utf-8", contentType)
+
+	for _, tc := range []struct{ method, url string }{
+		{http.MethodPost, "https://cdn.example.com/assets/app.js"},
+		{http.MethodGet, "https://cdn.example.com/assets/missing.css"},
+		{http.MethodGet, "https://cdn.example.com/assets/"},
+		{http.MethodGet, "https://cdn.example.com/"},
+		{http.MethodGet, "https://cdn.example.com/../../etc/passwd"},
+	} {
+		_, _, ok := r.staticAsset(tc.method, tc.url)
+		assert.False(t, ok, "%s %s", tc.method, tc.url)
+	}
+
+	_, _, ok = NewReplayer(&HARLog{}).staticAsset(http.MethodGet, "https://cdn.example.com/assets/app.js")
+	assert.False(t, ok, "no static dir")
+}
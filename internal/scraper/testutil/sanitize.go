@@ -1,8 +1,10 @@
 package testutil
 
 import (
+	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -49,6 +51,9 @@ var SensitiveHeaders = map[string]bool{
 	"proxy-authorization": true,
 }
 
+// jsonField matches a JSON field with a scalar value, quoted or not.
+var jsonField = regexp.MustCompile(`"([^"]+)"\s*:\s*("[^"]*"|[^",{}[\]\s]+)`)
+
 // SanitizeHAR redacts sensitive data from a HAR log.
 // Returns a new HARLog with sensitive data replaced by [REDACTED].
 func SanitizeHAR(har *HARLog) *HARLog {
@@ -169,20 +174,17 @@ func sanitizeFormBody(body string) string {
 }
 
 func sanitizeJSONBody(body string) string {
-	result := body
-
-	// Use regex to find and redact sensitive JSON fields
-	// Pattern: "sensitive_key": "value" or "sensitive_key":"value"
-	for _, pattern := range SensitivePatterns {
-		re := regexp.MustCompile(`("` + pattern + `")\s*:\s*"[^"]*"`)
-		result = re.ReplaceAllString(result, `$1: "`+redacted+`"`)
-
-		// Also handle non-string values
-		re = regexp.MustCompile(`("` + pattern + `")\s*:\s*([^",}\]]+)`)
-		result = re.ReplaceAllString(result, `$1: "`+redacted+`"`)
-	}
-
-	return result
+	// Redact the scalar value of every field with a sensitive name,
+	// "sensitive_key": "value" or "sensitive_key": 123, the way isSensitiveKey
+	// matches query parameters and headers. Already redacted values are kept,
+	// so sanitizing twice changes nothing.
+	return jsonField.ReplaceAllStringFunc(body, func(field string) string {
+		m := jsonField.FindStringSubmatch(field)
+		if !isSensitiveKey(m[1]) {
+			return field
+		}
+		return `"` + m[1] + `": "` + redacted + `"`
+	})
 }
 
 func isSensitiveKey(key string) bool {
@@ -197,3 +199,84 @@ func isSensitiveKey(key string) bool {
 
 	return false
 }
+
+// ScanHAR reports where har still holds a value SanitizeHAR would redact:
+// a sensitive query parameter, header, form field or JSON field. A
+// sanitized recording has no findings. Each finding names the entry and
+// the field, never the value.
+func ScanHAR(har *HARLog) []string {
+	var findings []string
+	add := func(i int, format string, args ...any) {
+		findings = append(findings, fmt.Sprintf("entry %d: ", i+1)+fmt.Sprintf(format, args...))
+	}
+	for i, e := range har.Entries {
+		if parsed, err := url.Parse(e.Request.URL); err == nil {
+			for key, values := range parsed.Query() {
+				if isSensitiveKey(key) && !allRedacted(values) {
+					add(i, "query parameter %s", key)
+				}
+			}
+		}
+		for _, h := range e.Request.Headers {
+			if isSensitiveHeader(h) {
+				add(i, "request header %s", h.Name)
+			}
+		}
+		for _, h := range e.Response.Headers {
+			if isSensitiveHeader(h) {
+				add(i, "response header %s", h.Name)
+			}
+		}
+		for _, key := range sensitiveBodyFields(e.Request.Body) {
+			add(i, "request body field %s", key)
+		}
+		for _, key := range sensitiveBodyFields(e.Response.Content.Text) {
+			add(i, "response body field %s", key)
+		}
+	}
+	return findings
+}
+
+func isSensitiveHeader(h HARHeader) bool {
+	if h.Value == "" || h.Value == redacted {
+		return false
+	}
+	return SensitiveHeaders[strings.ToLower(h.Name)] || isSensitiveKey(h.Name)
+}
+
+// sensitiveBodyFields returns the sensitive fields of a form or JSON body
+// that aren't redacted.
+func sensitiveBodyFields(body string) []string {
+	var keys []string
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case trimmed == "":
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		for _, m := range jsonField.FindAllStringSubmatch(body, -1) {
+			if isSensitiveKey(m[1]) && m[2] != `"`+redacted+`"` && m[2] != `""` && m[2] != "null" {
+				keys = append(keys, m[1])
+			}
+		}
+	case strings.Contains(body, "="):
+		values, err := url.ParseQuery(body)
+		if err != nil {
+			return nil
+		}
+		for key, vs := range values {
+			if isSensitiveKey(key) && !allRedacted(vs) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func allRedacted(values []string) bool {
+	for _, v := range values {
+		if v != "" && v != redacted {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanHAR(t *testing.T) {
+	raw := &HARLog{Entries: []HAREntry{
+		{
+			Request: HARRequest{
+				Method:  "POST",
+				URL:     "https://example.com/login?token=abc&page=2",
+				Headers: []HARHeader{{Name: "Cookie", Value: "JSESSIONID=1"}, {Name: "Accept", Value: "*/*"}},
+				Body:    "usuario=ana&clave=hunter2",
+			},
+			Response: HARResponse{
+				Status:  200,
+				Headers: []HARHeader{{Name: "Set-Cookie", Value: ""}},
+				Content: HARContent{Text: `{"authenticationData": {"sessionId": "s1"}, "status": "ok"}`},
+			},
+		},
+		{Request: HARRequest{Method: "GET", URL: "https://example.com/app.js"}},
+	}}
+
+	assert.Equal(t, []string{
+		"entry 1: query parameter token",
+		"entry 1: request header Cookie",
+		"entry 1: request body field clave",
+		"entry 1: response body field sessionId",
+	}, ScanHAR(raw))
+	assert.Empty(t, ScanHAR(SanitizeHAR(raw)))
+}
+
+func TestSanitizeJSONBody(t *testing.T) {
+	body := `{"password":"x","sessionId": 42,"authenticationData": {"token": "t"},"n":1}`
+	want := `{"password": "[REDACTED]","sessionId": "[REDACTED]","authenticationData": {"token": "[REDACTED]"},"n":1}`
+	assert.Equal(t, want, sanitizeJSONBody(body))
+	assert.Equal(t, want, sanitizeJSONBody(want), "idempotent")
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aynifx/bank-scraper/internal/schema"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,18 +25,34 @@ const (
 	AccountTypeSavings  = "savings"
 )
 
+// Scrape priority constants, for accounts and scrape jobs.
+const (
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// ValidPriority reports whether p is a known scrape priority.
+func ValidPriority(p string) bool {
+	return p == PriorityNormal || p == PriorityHigh
+}
+
 // Account represents a discovered bank account.
 type Account struct {
 	ID            uuid.UUID
+	Profile       string // Owning profile; see WithProfile
 	BankCode      string
 	AccountNumber string
 	Currency      string
 	AccountType   string // "checking", "savings"
 	Status        string // "active", "inactive"
+	Priority      string // "normal", "high"
 	CredentialID  uuid.UUID
 	LastSyncedAt  *time.Time
+	SchemaVersion string // data schema version the record was written with
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+
+	Alias string // Friendly name from ACCOUNT_ALIASES (see WithAccountAliases); not stored
 }
 
 // AccountFilter holds optional filters for listing accounts.
@@ -44,7 +61,8 @@ type AccountFilter struct {
 	Currency *string
 }
 
-// AccountRepository defines operations on the accounts table.
+// AccountRepository defines operations on the accounts table. Every
+// operation is scoped to the profile of its context (see WithProfile).
 type AccountRepository interface {
 	Create(ctx context.Context, a *Account) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Account, error)
@@ -63,26 +81,27 @@ func NewAccountRepo(pool *pgxpool.Pool) *AccountRepo {
 	return &AccountRepo{pool: pool}
 }
 
-const accountColumns = `id, bank_code, account_number, currency, account_type,
-	status, credential_id, last_synced_at, created_at, updated_at`
+const accountColumns = `id, profile, bank_code, account_number, currency, account_type,
+	status, priority, credential_id, last_synced_at, schema_version, created_at, updated_at`
 
 func scanAccountInto(row pgx.Row, a *Account) error {
 	return row.Scan(
-		&a.ID, &a.BankCode, &a.AccountNumber, &a.Currency, &a.AccountType,
-		&a.Status, &a.CredentialID, &a.LastSyncedAt, &a.CreatedAt, &a.UpdatedAt,
+		&a.ID, &a.Profile, &a.BankCode, &a.AccountNumber, &a.Currency, &a.AccountType,
+		&a.Status, &a.Priority, &a.CredentialID, &a.LastSyncedAt, &a.SchemaVersion, &a.CreatedAt, &a.UpdatedAt,
 	)
 }
 
-// Create inserts a new account.
+// Create inserts a new account into the context's profile.
 func (r *AccountRepo) Create(ctx context.Context, a *Account) error {
+	a.Profile = ProfileFrom(ctx)
 	query := `
-		INSERT INTO accounts (bank_code, account_number, currency, account_type, credential_id)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, status, last_synced_at, created_at, updated_at`
+		INSERT INTO accounts (profile, bank_code, account_number, currency, account_type, credential_id, schema_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, priority, last_synced_at, schema_version, created_at, updated_at`
 
 	err := r.pool.QueryRow(ctx, query,
-		a.BankCode, a.AccountNumber, a.Currency, a.AccountType, a.CredentialID,
-	).Scan(&a.ID, &a.Status, &a.LastSyncedAt, &a.CreatedAt, &a.UpdatedAt)
+		a.Profile, a.BankCode, a.AccountNumber, a.Currency, a.AccountType, a.CredentialID, schema.Version,
+	).Scan(&a.ID, &a.Status, &a.Priority, &a.LastSyncedAt, &a.SchemaVersion, &a.CreatedAt, &a.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("create account: %w", err)
 	}
@@ -91,10 +110,10 @@ func (r *AccountRepo) Create(ctx context.Context, a *Account) error {
 
 // GetByID returns a single account by ID, or ErrNotFound.
 func (r *AccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*Account, error) {
-	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = $1`
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = $1 AND profile = $2`
 
 	var a Account
-	err := scanAccountInto(r.pool.QueryRow(ctx, query, id), &a)
+	err := scanAccountInto(r.pool.QueryRow(ctx, query, id, ProfileFrom(ctx)), &a)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("account %s: %w", id, ErrNotFound)
 	}
@@ -107,8 +126,8 @@ func (r *AccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*Account, erro
 // List returns accounts matching the optional filter criteria.
 func (r *AccountRepo) List(ctx context.Context, filter AccountFilter) ([]Account, error) {
 	var (
-		conditions []string
-		args       []any
+		conditions = []string{"profile = $1"}
+		args       = []any{ProfileFrom(ctx)}
 	)
 
 	if filter.BankCode != nil {
@@ -120,11 +139,8 @@ func (r *AccountRepo) List(ctx context.Context, filter AccountFilter) ([]Account
 		conditions = append(conditions, fmt.Sprintf("currency = $%d", len(args)))
 	}
 
-	query := `SELECT ` + accountColumns + ` FROM accounts`
-	if len(conditions) > 0 {
-		query += ` WHERE ` + strings.Join(conditions, " AND ")
-	}
-	query += ` ORDER BY bank_code, account_number`
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY bank_code, account_number`
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -146,7 +162,8 @@ func (r *AccountRepo) List(ctx context.Context, filter AccountFilter) ([]Account
 	return accounts, nil
 }
 
-// UpsertBatch inserts or updates accounts in a single transaction.
+// UpsertBatch inserts or updates accounts of the context's profile in a
+// single transaction.
 func (r *AccountRepo) UpsertBatch(ctx context.Context, credentialID uuid.UUID, accounts []Account) error {
 	if len(accounts) == 0 {
 		return nil
@@ -159,17 +176,19 @@ func (r *AccountRepo) UpsertBatch(ctx context.Context, credentialID uuid.UUID, a
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	query := `
-		INSERT INTO accounts (bank_code, account_number, currency, account_type, credential_id)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (bank_code, account_number) DO UPDATE SET
+		INSERT INTO accounts (profile, bank_code, account_number, currency, account_type, credential_id, schema_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (profile, bank_code, account_number) DO UPDATE SET
 			currency = EXCLUDED.currency,
 			account_type = EXCLUDED.account_type,
 			credential_id = EXCLUDED.credential_id,
+			schema_version = EXCLUDED.schema_version,
 			updated_at = now()`
 
+	profile := ProfileFrom(ctx)
 	for i, a := range accounts {
 		_, err := tx.Exec(ctx, query,
-			a.BankCode, a.AccountNumber, a.Currency, a.AccountType, credentialID,
+			profile, a.BankCode, a.AccountNumber, a.Currency, a.AccountType, credentialID, schema.Version,
 		)
 		if err != nil {
 			return fmt.Errorf("upsert account %d (%s/%s): %w", i, a.BankCode, a.AccountNumber, err)
@@ -184,9 +203,9 @@ func (r *AccountRepo) UpsertBatch(ctx context.Context, credentialID uuid.UUID, a
 
 // UpdateLastSynced sets the last_synced_at timestamp to now.
 func (r *AccountRepo) UpdateLastSynced(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE accounts SET last_synced_at = now(), updated_at = now() WHERE id = $1`
+	query := `UPDATE accounts SET last_synced_at = now(), updated_at = now() WHERE id = $1 AND profile = $2`
 
-	tag, err := r.pool.Exec(ctx, query, id)
+	tag, err := r.pool.Exec(ctx, query, id, ProfileFrom(ctx))
 	if err != nil {
 		return fmt.Errorf("update last synced: %w", err)
 	}
@@ -195,3 +214,20 @@ func (r *AccountRepo) UpdateLastSynced(ctx context.Context, id uuid.UUID) error
 	}
 	return nil
 }
+
+// SetPriority sets an account's scrape priority.
+func (r *AccountRepo) SetPriority(ctx context.Context, id uuid.UUID, priority string) error {
+	if !ValidPriority(priority) {
+		return fmt.Errorf("invalid priority %q (use %s or %s)", priority, PriorityNormal, PriorityHigh)
+	}
+	query := `UPDATE accounts SET priority = $2, updated_at = now() WHERE id = $1 AND profile = $3`
+
+	tag, err := r.pool.Exec(ctx, query, id, priority, ProfileFrom(ctx))
+	if err != nil {
+		return fmt.Errorf("set priority: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("account %s: %w", id, ErrNotFound)
+	}
+	return nil
+}
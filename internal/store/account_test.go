@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aynifx/bank-scraper/internal/schema"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -35,6 +36,7 @@ func TestAccountRepo_Create(t *testing.T) {
 	assert.NotEqual(t, uuid.Nil, a.ID)
 	assert.Equal(t, AccountStatusActive, a.Status)
 	assert.Nil(t, a.LastSyncedAt)
+	assert.Equal(t, schema.Version, a.SchemaVersion)
 	assert.False(t, a.CreatedAt.IsZero())
 	assert.False(t, a.UpdatedAt.IsZero())
 }
@@ -234,6 +236,25 @@ func TestAccountRepo_UpdateLastSynced_NotFound(t *testing.T) {
 	assert.ErrorIs(t, err, ErrNotFound)
 }
 
+func TestAccountRepo_SetPriority(t *testing.T) {
+	pool := testPool(t)
+	truncateTables(t, pool)
+	u := createTestUser(t, NewUserRepo(pool))
+	cred := createTestCredential(t, NewCredentialRepo(pool), u.ID)
+	accountRepo := NewAccountRepo(pool)
+	a := createTestAccount(t, accountRepo, cred.ID, "BBVA", "001-11111111-0-01", "PEN")
+	assert.Equal(t, PriorityNormal, a.Priority)
+
+	ctx := context.Background()
+	require.NoError(t, accountRepo.SetPriority(ctx, a.ID, PriorityHigh))
+	fetched, err := accountRepo.GetByID(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, PriorityHigh, fetched.Priority)
+
+	assert.Error(t, accountRepo.SetPriority(ctx, a.ID, "urgent"))
+	assert.ErrorIs(t, accountRepo.SetPriority(ctx, uuid.New(), PriorityHigh), ErrNotFound)
+}
+
 // createTestAccount inserts a minimal account for tests.
 func createTestAccount(t *testing.T, repo *AccountRepo, credentialID uuid.UUID, bankCode, accountNumber, currency string) *Account {
 	t.Helper()
@@ -253,3 +274,33 @@ func createTestAccount(t *testing.T, repo *AccountRepo, credentialID uuid.UUID,
 	}
 	return a
 }
+
+func TestAccountRepo_ProfileIsolation(t *testing.T) {
+	pool := testPool(t)
+	truncateTables(t, pool)
+	userRepo := NewUserRepo(pool)
+	credRepo := NewCredentialRepo(pool)
+	accountRepo := NewAccountRepo(pool)
+
+	u := createTestUser(t, userRepo)
+	cred := createTestCredential(t, credRepo, u.ID)
+
+	ctx := context.Background()
+	acme := WithProfile(ctx, "acme")
+	a := &Account{BankCode: "BBVA", AccountNumber: "001-12345678-0-01", Currency: "PEN", CredentialID: cred.ID}
+	require.NoError(t, accountRepo.Create(acme, a))
+	assert.Equal(t, "acme", a.Profile)
+
+	// The same account may exist in another profile.
+	require.NoError(t, accountRepo.Create(ctx, &Account{
+		BankCode: "BBVA", AccountNumber: "001-12345678-0-01", Currency: "PEN", CredentialID: cred.ID,
+	}))
+
+	_, err := accountRepo.GetByID(ctx, a.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "other profiles' accounts are invisible")
+
+	accounts, err := accountRepo.List(acme, AccountFilter{})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, a.ID, accounts[0].ID)
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAccountAlias is returned by ParseAccountAliases for an empty
+// alias or a key too short to identify an account.
+var ErrInvalidAccountAlias = errors.New("invalid account alias")
+
+// minAliasKey is the shortest account number suffix an alias may be keyed
+// by: the digits a masked number leaves visible.
+const minAliasKey = 4
+
+// AccountAliases are friendly names for accounts ("Operating PEN"), keyed by
+// account number or by its last digits, shown wherever a masked number
+// would be.
+type AccountAliases map[string]string
+
+// ParseAccountAliases validates an account number → alias map, e.g. from
+// the ACCOUNT_ALIASES setting.
+func ParseAccountAliases(m map[string]string) (AccountAliases, error) {
+	a := make(AccountAliases, len(m))
+	for key, alias := range m {
+		key, alias = strings.TrimSpace(key), strings.TrimSpace(alias)
+		if len(key) < minAliasKey {
+			return nil, fmt.Errorf("%w: key %q is shorter than %d characters", ErrInvalidAccountAlias, key, minAliasKey)
+		}
+		if alias == "" {
+			return nil, fmt.Errorf("%w: empty alias for %s", ErrInvalidAccountAlias, key)
+		}
+		a[key] = alias
+	}
+	return a, nil
+}
+
+// For returns accountNumber's alias, or "" if it has none. A key equal to
+// the number wins; otherwise the longest key the number ends with.
+func (a AccountAliases) For(accountNumber string) string {
+	if alias, ok := a[accountNumber]; ok {
+		return alias
+	}
+	var best, alias string
+	for key, v := range a {
+		if len(key) > len(best) && strings.HasSuffix(accountNumber, key) {
+			best, alias = key, v
+		}
+	}
+	return alias
+}
+
+// aliasedAccounts sets Account.Alias on the accounts an AccountRepository
+// reads or writes.
+type aliasedAccounts struct {
+	AccountRepository
+	aliases AccountAliases
+}
+
+// WithAccountAliases returns repo with Alias set on every account it
+// returns, and on the accounts passed to UpsertBatch.
+func WithAccountAliases(repo AccountRepository, aliases AccountAliases) AccountRepository {
+	if len(aliases) == 0 {
+		return repo
+	}
+	return &aliasedAccounts{AccountRepository: repo, aliases: aliases}
+}
+
+func (r *aliasedAccounts) GetByID(ctx context.Context, id uuid.UUID) (*Account, error) {
+	a, err := r.AccountRepository.GetByID(ctx, id)
+	if a != nil {
+		a.Alias = r.aliases.For(a.AccountNumber)
+	}
+	return a, err
+}
+
+func (r *aliasedAccounts) List(ctx context.Context, filter AccountFilter) ([]Account, error) {
+	accounts, err := r.AccountRepository.List(ctx, filter)
+	r.apply(accounts)
+	return accounts, err
+}
+
+func (r *aliasedAccounts) UpsertBatch(ctx context.Context, credentialID uuid.UUID, accounts []Account) error {
+	r.apply(accounts)
+	return r.AccountRepository.UpsertBatch(ctx, credentialID, accounts)
+}
+
+func (r *aliasedAccounts) apply(accounts []Account) {
+	for i := range accounts {
+		accounts[i].Alias = r.aliases.For(accounts[i].AccountNumber)
+	}
+}
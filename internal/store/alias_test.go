@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAliases_For(t *testing.T) {
+	a, err := ParseAccountAliases(map[string]string{
+		"4607":                 " Operating PEN ",
+		"0100064607":           "Operating PEN (main)",
+		"PE001101190100064615": "Payroll USD",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Payroll USD", a.For("PE001101190100064615"), "exact key")
+	assert.Equal(t, "Operating PEN (main)", a.For("PE001101190100064607"), "longest suffix wins")
+	assert.Equal(t, "Operating PEN", a.For("•4607"), "masked tile-view ID")
+	assert.Empty(t, a.For("PE001101190100069999"))
+	assert.Empty(t, AccountAliases(nil).For("4607"))
+
+	_, err = ParseAccountAliases(map[string]string{"607": "Too short"})
+	assert.ErrorIs(t, err, ErrInvalidAccountAlias)
+	_, err = ParseAccountAliases(map[string]string{"4607": " "})
+	assert.ErrorIs(t, err, ErrInvalidAccountAlias)
+}
+
+// listAccounts is an AccountRepository serving a fixed account list.
+type listAccounts struct {
+	AccountRepository
+	accounts []Account
+}
+
+func (r *listAccounts) List(context.Context, AccountFilter) ([]Account, error) {
+	return append([]Account(nil), r.accounts...), nil
+}
+
+func TestWithAccountAliases(t *testing.T) {
+	inner := &listAccounts{accounts: []Account{{AccountNumber: "PE001101190100064607"}, {AccountNumber: "PE001101190100064615"}}}
+	assert.Same(t, inner, WithAccountAliases(inner, nil), "no aliases: no wrapper")
+
+	repo := WithAccountAliases(inner, AccountAliases{"4607": "Operating PEN"})
+	accounts, err := repo.List(context.Background(), AccountFilter{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Operating PEN", accounts[0].Alias)
+	assert.Empty(t, accounts[1].Alias)
+}
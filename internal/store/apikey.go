@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +13,44 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// API key scopes. Admin implies every other scope.
+const (
+	APIKeyScopeRead   = "read"   // Read data through existing bank sessions
+	APIKeyScopeScrape = "scrape" // Open bank sessions (log in) to serve or refresh data
+	APIKeyScopeAdmin  = "admin"  // Administrative endpoints (account discovery)
+)
+
+// APIKeyScopes lists the valid scopes.
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopeScrape, APIKeyScopeAdmin}
+
+// ParseAPIKeyScopes parses a comma-separated scope list, rejecting unknown
+// scopes and dropping duplicates.
+func ParseAPIKeyScopes(s string) ([]string, error) {
+	var scopes []string
+	for scope := range strings.SplitSeq(s, ",") {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || slices.Contains(scopes, scope) {
+			continue
+		}
+		if !slices.Contains(APIKeyScopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q (valid: %s)", scope, strings.Join(APIKeyScopes, ", "))
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required (valid: %s)", strings.Join(APIKeyScopes, ", "))
+	}
+	return scopes, nil
+}
+
 // APIKey represents an API key record.
 type APIKey struct {
 	ID          uuid.UUID
 	KeyHash     []byte
 	ClientID    string
+	Profile     string // The only profile the key can see; see WithProfile
 	Description *string
+	Scopes      []string // See APIKeyScope*
 	CreatedAt   time.Time
 	RevokedAt   *time.Time
 	LastUsedAt  *time.Time
@@ -41,25 +75,38 @@ func NewAPIKeyRepo(pool *pgxpool.Pool) *APIKeyRepo {
 	return &APIKeyRepo{pool: pool}
 }
 
-const apiKeyColumns = `id, key_hash, client_id, description, created_at, revoked_at, last_used_at`
+// HasScope reports whether the key grants scope. Admin keys grant all scopes.
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, APIKeyScopeAdmin)
+}
+
+const apiKeyColumns = `id, key_hash, client_id, profile, description, scopes, created_at, revoked_at, last_used_at`
 
 func scanAPIKey(row pgx.Row) (*APIKey, error) {
 	k := &APIKey{}
 	err := row.Scan(
-		&k.ID, &k.KeyHash, &k.ClientID, &k.Description, &k.CreatedAt, &k.RevokedAt, &k.LastUsedAt,
+		&k.ID, &k.KeyHash, &k.ClientID, &k.Profile, &k.Description, &k.Scopes, &k.CreatedAt, &k.RevokedAt, &k.LastUsedAt,
 	)
 	return k, err
 }
 
-// Create inserts a new API key.
+// Create inserts a new API key. A key without scopes gets read-only access;
+// one without a profile belongs to DefaultProfile.
 func (r *APIKeyRepo) Create(ctx context.Context, k *APIKey) error {
+	if len(k.Scopes) == 0 {
+		k.Scopes = []string{APIKeyScopeRead}
+	}
+	if k.Profile == "" {
+		k.Profile = DefaultProfile
+	}
+
 	query := `
-		INSERT INTO api_keys (key_hash, client_id, description)
-		VALUES ($1, $2, $3)
+		INSERT INTO api_keys (key_hash, client_id, profile, description, scopes)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at, revoked_at, last_used_at`
 
 	err := r.pool.QueryRow(ctx, query,
-		k.KeyHash, k.ClientID, k.Description,
+		k.KeyHash, k.ClientID, k.Profile, k.Description, k.Scopes,
 	).Scan(&k.ID, &k.CreatedAt, &k.RevokedAt, &k.LastUsedAt)
 	if err != nil {
 		return fmt.Errorf("create api key: %w", err)
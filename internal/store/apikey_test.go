@@ -49,6 +49,7 @@ func TestAPIKeyRepo_GetByKeyHash(t *testing.T) {
 	assert.Equal(t, k.ID, fetched.ID)
 	assert.Equal(t, "aynifx", fetched.ClientID)
 	assert.Equal(t, hash[:], fetched.KeyHash)
+	assert.Equal(t, []string{APIKeyScopeRead}, fetched.Scopes, "read-only by default")
 }
 
 func TestAPIKeyRepo_GetByKeyHash_NotFound(t *testing.T) {
@@ -149,3 +150,23 @@ func createTestAPIKey(t *testing.T, repo *APIKeyRepo, keyHash []byte, clientID s
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestParseAPIKeyScopes(t *testing.T) {
+	scopes, err := ParseAPIKeyScopes(" Read,scrape,read ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{APIKeyScopeRead, APIKeyScopeScrape}, scopes)
+
+	_, err = ParseAPIKeyScopes("read,write")
+	assert.ErrorContains(t, err, `unknown scope "write"`)
+	_, err = ParseAPIKeyScopes(" , ")
+	assert.ErrorContains(t, err, "at least one scope")
+}
+
+func TestAPIKey_HasScope(t *testing.T) {
+	reader := &APIKey{Scopes: []string{APIKeyScopeRead}}
+	assert.True(t, reader.HasScope(APIKeyScopeRead))
+	assert.False(t, reader.HasScope(APIKeyScopeScrape))
+
+	admin := &APIKey{Scopes: []string{APIKeyScopeAdmin}}
+	assert.True(t, admin.HasScope(APIKeyScopeScrape), "admin implies every scope")
+}
@@ -0,0 +1,270 @@
+package store
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrInvalidBackup is returned by Restore for a file that isn't a backup, or
+// one sealed with a different key.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// backupMagic starts every backup file.
+const backupMagic = "BANKSCRAPER-BACKUP-1\n"
+
+// backupTables are the tables a backup holds, in foreign key order: each
+// table only references tables before it.
+var backupTables = []string{
+	"users", "sessions", "bank_credentials", "audit_logs",
+	"accounts", "api_keys", "transactions", "scrape_jobs",
+	"timing_profiles",
+}
+
+// BackupManifest describes a backup.
+type BackupManifest struct {
+	CreatedAt        time.Time      `json:"created_at"`
+	MigrationVersion uint           `json:"migration_version"`
+	Rows             map[string]int `json:"rows"` // Per table
+}
+
+// Backup writes every table of the store, plus files (e.g. the host's
+// configuration), to w as one archive sealed with cipher. Tables are read in
+// a single snapshot. The archive is built in memory: stores too large for
+// that are better served by pg_dump.
+//
+// Credentials and encrypted transactions stay sealed under their profile
+// keys inside the archive, so restoring needs the same ENCRYPTION_KEY and
+// PROFILE_ENCRYPTION_KEYS.
+func Backup(ctx context.Context, pool *pgxpool.Pool, w io.Writer, cipher FieldCipher, files map[string][]byte) (*BackupManifest, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("begin backup: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	m := &BackupManifest{CreatedAt: time.Now().UTC(), Rows: make(map[string]int, len(backupTables))}
+	if m.MigrationVersion, err = migrationVersion(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string][]byte, len(backupTables))
+	for _, table := range backupTables {
+		var buf bytes.Buffer
+		tag, err := tx.Conn().PgConn().CopyTo(ctx, &buf, "COPY "+table+" TO STDOUT")
+		if err != nil {
+			return nil, fmt.Errorf("copy %s: %w", table, err)
+		}
+		tables[table] = buf.Bytes()
+		m.Rows[table] = int(tag.RowsAffected())
+	}
+
+	archive, err := packBackup(m, tables, files)
+	if err != nil {
+		return nil, err
+	}
+	if err := sealBackup(w, cipher, archive); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Restore loads a backup written by Backup into an empty store migrated to
+// the backup's version, in one transaction. It returns the backup's manifest
+// and the extra files it carried.
+func Restore(ctx context.Context, pool *pgxpool.Pool, r io.Reader, cipher FieldCipher) (*BackupManifest, map[string][]byte, error) {
+	archive, err := openBackup(r, cipher)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, tables, files, err := unpackBackup(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin restore: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	v, err := migrationVersion(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if v != m.MigrationVersion {
+		return nil, nil, fmt.Errorf("backup is at migration %d but the store is at %d: migrate to the same version first", m.MigrationVersion, v)
+	}
+
+	for _, table := range backupTables {
+		var n int
+		if err := tx.QueryRow(ctx, "SELECT count(*) FROM "+table).Scan(&n); err != nil {
+			return nil, nil, fmt.Errorf("count %s: %w", table, err)
+		}
+		if n > 0 {
+			return nil, nil, fmt.Errorf("restore needs an empty store: %s has %d rows", table, n)
+		}
+		if _, err := tx.Conn().PgConn().CopyFrom(ctx, bytes.NewReader(tables[table]), "COPY "+table+" FROM STDIN"); err != nil {
+			return nil, nil, fmt.Errorf("restore %s: %w", table, err)
+		}
+	}
+	// Continue audit log IDs after the restored ones.
+	if _, err := tx.Exec(ctx, `SELECT setval(pg_get_serial_sequence('audit_logs', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM audit_logs`); err != nil {
+		return nil, nil, fmt.Errorf("reset audit log sequence: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit restore: %w", err)
+	}
+	return m, files, nil
+}
+
+// migrationVersion reads the version golang-migrate recorded.
+func migrationVersion(ctx context.Context, tx pgx.Tx) (uint, error) {
+	var (
+		v     int64
+		dirty bool
+	)
+	if err := tx.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations`).Scan(&v, &dirty); err != nil {
+		return 0, fmt.Errorf("read migration version: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("migration %d is dirty", v)
+	}
+	return uint(v), nil
+}
+
+// packBackup writes the manifest, tables and extra files as a gzipped tar:
+// manifest.json, tables/<name>.copy and files/<name>.
+func packBackup(m *BackupManifest, tables, files map[string][]byte) ([]byte, error) {
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: m.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := add("manifest.json", manifest); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	for _, table := range backupTables {
+		if err := add("tables/"+table+".copy", tables[table]); err != nil {
+			return nil, fmt.Errorf("write %s: %w", table, err)
+		}
+	}
+	for name, data := range files {
+		if err := add("files/"+name, data); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("close archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// unpackBackup reads an archive written by packBackup.
+func unpackBackup(archive []byte) (m *BackupManifest, tables, files map[string][]byte, err error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	tables, files = make(map[string][]byte), make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: read %s: %v", ErrInvalidBackup, hdr.Name, err)
+		}
+
+		if hdr.Name == "manifest.json" {
+			m = &BackupManifest{}
+			if err := json.Unmarshal(data, m); err != nil {
+				return nil, nil, nil, fmt.Errorf("%w: manifest: %v", ErrInvalidBackup, err)
+			}
+		} else if name, ok := strings.CutPrefix(hdr.Name, "tables/"); ok {
+			tables[strings.TrimSuffix(name, ".copy")] = data
+		} else if name, ok := strings.CutPrefix(hdr.Name, "files/"); ok {
+			files[name] = data
+		}
+	}
+
+	if m == nil {
+		return nil, nil, nil, fmt.Errorf("%w: no manifest", ErrInvalidBackup)
+	}
+	for _, table := range backupTables {
+		if _, ok := tables[table]; !ok {
+			return nil, nil, nil, fmt.Errorf("%w: no %s table", ErrInvalidBackup, table)
+		}
+	}
+	return m, tables, files, nil
+}
+
+// sealBackup writes archive encrypted with cipher: the magic, the length of
+// the sealed DEK as a big-endian uint32, the DEK and the sealed archive.
+func sealBackup(w io.Writer, cipher FieldCipher, archive []byte) error {
+	encData, encDEK, err := cipher.Seal(archive)
+	if err != nil {
+		return fmt.Errorf("seal backup: %w", err)
+	}
+	header := binary.BigEndian.AppendUint32([]byte(backupMagic), uint32(len(encDEK)))
+	for _, b := range [][]byte{header, encDEK, encData} {
+		if _, err := w.Write(b); err != nil {
+			return fmt.Errorf("write backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// openBackup reads and decrypts what sealBackup wrote.
+func openBackup(r io.Reader, cipher FieldCipher) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+	if len(data) < len(backupMagic)+4 || string(data[:len(backupMagic)]) != backupMagic {
+		return nil, fmt.Errorf("%w: not a backup file", ErrInvalidBackup)
+	}
+	data = data[len(backupMagic):]
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(n) > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: truncated", ErrInvalidBackup)
+	}
+	archive, err := cipher.Open(data[n:], data[:n])
+	if err != nil {
+		return nil, fmt.Errorf("%w: decrypt (wrong ENCRYPTION_KEY?): %v", ErrInvalidBackup, err)
+	}
+	return archive, nil
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup_SealOpenRoundTrip(t *testing.T) {
+	m := &BackupManifest{MigrationVersion: 14, Rows: map[string]int{"users": 1}}
+	tables := make(map[string][]byte)
+	for _, table := range backupTables {
+		tables[table] = []byte(table + " rows\n")
+	}
+	archive, err := packBackup(m, tables, map[string][]byte{"config.json": []byte(`{"APIPort":8080}`)})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, sealBackup(&buf, xorCipher{key: 0x5a}, archive))
+	assert.NotContains(t, buf.String(), "users rows", "archive is sealed")
+
+	opened, err := openBackup(bytes.NewReader(buf.Bytes()), xorCipher{key: 0x5a})
+	require.NoError(t, err)
+	gotM, gotTables, gotFiles, err := unpackBackup(opened)
+	require.NoError(t, err)
+	assert.Equal(t, uint(14), gotM.MigrationVersion)
+	assert.Equal(t, tables, gotTables)
+	assert.Equal(t, `{"APIPort":8080}`, string(gotFiles["config.json"]))
+
+	opened, err = openBackup(bytes.NewReader(buf.Bytes()), xorCipher{key: 0x33})
+	require.NoError(t, err)
+	_, _, _, err = unpackBackup(opened)
+	assert.ErrorIs(t, err, ErrInvalidBackup, "wrong key")
+}
+
+func TestBackup_NotABackup(t *testing.T) {
+	_, err := openBackup(bytes.NewReader([]byte("PK\x03\x04 some zip")), xorCipher{})
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+}
+
+func TestBackup_Restore(t *testing.T) {
+	pool := testPool(t)
+	truncateTables(t, pool)
+	u := createTestUser(t, NewUserRepo(pool))
+	cred := createTestCredential(t, NewCredentialRepo(pool), u.ID)
+	createTestAccount(t, NewAccountRepo(pool), cred.ID, "BBVA", "001-12345678-0-01", "PEN")
+	ctx := context.Background()
+	profile := bank.TimingProfile{bank.StepLogin: {Samples: 3, Mean: 2 * time.Second, Max: 4 * time.Second}}
+	require.NoError(t, NewTimingProfileRepo(pool).Save(ctx, bank.BankBBVA, profile))
+
+	var buf bytes.Buffer
+	m, err := Backup(ctx, pool, &buf, xorCipher{key: 0x5a}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.Rows["accounts"])
+	assert.Equal(t, 1, m.Rows["timing_profiles"])
+
+	_, _, err = Restore(ctx, pool, bytes.NewReader(buf.Bytes()), xorCipher{key: 0x5a})
+	assert.ErrorContains(t, err, "empty store")
+
+	truncateTables(t, pool)
+	_, _, err = Restore(ctx, pool, bytes.NewReader(buf.Bytes()), xorCipher{key: 0x5a})
+	require.NoError(t, err)
+	accounts, err := NewAccountRepo(pool).List(ctx, AccountFilter{})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "001-12345678-0-01", accounts[0].AccountNumber)
+	profiles, err := NewTimingProfileRepo(pool).Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[bank.Code]bank.TimingProfile{bank.BankBBVA: profile}, profiles)
+}
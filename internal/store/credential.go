@@ -20,6 +20,7 @@ const (
 // BankCredential represents an encrypted bank credential record.
 type BankCredential struct {
 	ID             uuid.UUID
+	Profile        string // Owning profile; see WithProfile
 	BankCode       string
 	AccountLabel   string
 	CredentialsEnc []byte
@@ -34,6 +35,8 @@ type BankCredential struct {
 }
 
 // CredentialRepository defines operations on the bank_credentials table.
+// GetActiveByBankCode is scoped to the profile of its context (see
+// WithProfile); the rest serve the credential manager across profiles.
 type CredentialRepository interface {
 	Create(ctx context.Context, c *BankCredential) error
 	GetByID(ctx context.Context, id uuid.UUID) (*BankCredential, error)
@@ -55,14 +58,18 @@ func NewCredentialRepo(pool *pgxpool.Pool) *CredentialRepo {
 }
 
 // Create inserts a new bank credential and populates its generated fields.
+// A credential without a profile goes to the context's profile.
 func (r *CredentialRepo) Create(ctx context.Context, c *BankCredential) error {
+	if c.Profile == "" {
+		c.Profile = ProfileFrom(ctx)
+	}
 	query := `
-		INSERT INTO bank_credentials (bank_code, account_label, credentials_enc, credentials_dek, created_by, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO bank_credentials (profile, bank_code, account_label, credentials_enc, credentials_dek, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, version, status, deleted_at, created_at, updated_at`
 
 	err := r.pool.QueryRow(ctx, query,
-		c.BankCode, c.AccountLabel, c.CredentialsEnc, c.CredentialsDEK, c.CreatedBy, c.UpdatedBy,
+		c.Profile, c.BankCode, c.AccountLabel, c.CredentialsEnc, c.CredentialsDEK, c.CreatedBy, c.UpdatedBy,
 	).Scan(&c.ID, &c.Version, &c.Status, &c.DeletedAt, &c.CreatedAt, &c.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("create credential: %w", err)
@@ -70,13 +77,13 @@ func (r *CredentialRepo) Create(ctx context.Context, c *BankCredential) error {
 	return nil
 }
 
-const credentialColumns = `id, bank_code, account_label, credentials_enc, credentials_dek,
+const credentialColumns = `id, profile, bank_code, account_label, credentials_enc, credentials_dek,
 	version, status, deleted_at, created_by, updated_by, created_at, updated_at`
 
 func scanCredential(row pgx.Row) (*BankCredential, error) {
 	c := &BankCredential{}
 	err := row.Scan(
-		&c.ID, &c.BankCode, &c.AccountLabel, &c.CredentialsEnc, &c.CredentialsDEK,
+		&c.ID, &c.Profile, &c.BankCode, &c.AccountLabel, &c.CredentialsEnc, &c.CredentialsDEK,
 		&c.Version, &c.Status, &c.DeletedAt, &c.CreatedBy, &c.UpdatedBy, &c.CreatedAt, &c.UpdatedAt,
 	)
 	return c, err
@@ -96,11 +103,12 @@ func (r *CredentialRepo) GetByID(ctx context.Context, id uuid.UUID) (*BankCreden
 	return c, nil
 }
 
-// GetActiveByBankCode retrieves the active credential for a given bank code.
+// GetActiveByBankCode retrieves the active credential for a given bank code
+// in the context's profile.
 func (r *CredentialRepo) GetActiveByBankCode(ctx context.Context, bankCode string) (*BankCredential, error) {
-	query := `SELECT ` + credentialColumns + ` FROM bank_credentials WHERE bank_code = $1 AND status = $2`
+	query := `SELECT ` + credentialColumns + ` FROM bank_credentials WHERE bank_code = $1 AND status = $2 AND profile = $3`
 
-	c, err := scanCredential(r.pool.QueryRow(ctx, query, bankCode, CredentialStatusActive))
+	c, err := scanCredential(r.pool.QueryRow(ctx, query, bankCode, CredentialStatusActive, ProfileFrom(ctx)))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("credential for %s: %w", bankCode, ErrNotFound)
 	}
@@ -110,7 +118,8 @@ func (r *CredentialRepo) GetActiveByBankCode(ctx context.Context, bankCode strin
 	return c, nil
 }
 
-// List returns all active bank credentials ordered by creation date descending.
+// List returns all active bank credentials of every profile ordered by
+// creation date descending.
 func (r *CredentialRepo) List(ctx context.Context) ([]BankCredential, error) {
 	query := `SELECT ` + credentialColumns + ` FROM bank_credentials WHERE status = $1 ORDER BY created_at DESC`
 
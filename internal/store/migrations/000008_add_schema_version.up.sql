@@ -0,0 +1,2 @@
+-- Data schema version the record was written with (semver, see internal/schema)
+ALTER TABLE accounts ADD COLUMN schema_version VARCHAR(20) NOT NULL DEFAULT '1.0.0';
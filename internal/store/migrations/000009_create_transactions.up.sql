@@ -0,0 +1,20 @@
+CREATE TABLE transactions (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id     UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    dedup_hash     BYTEA NOT NULL,
+    bank_id        VARCHAR(100) NOT NULL DEFAULT '',
+    reference      VARCHAR(100) NOT NULL DEFAULT '',
+    operation_date DATE NOT NULL,
+    value_date     DATE,
+    description    TEXT NOT NULL,
+    amount         BIGINT NOT NULL,
+    type           VARCHAR(10) NOT NULL,
+    balance_after  BIGINT,
+    extra          JSONB,
+    source         VARCHAR(20) NOT NULL DEFAULT 'scrape',
+    schema_version VARCHAR(20) NOT NULL DEFAULT '1.0.0',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(account_id, dedup_hash)
+);
+
+CREATE INDEX idx_transactions_account_date ON transactions(account_id, operation_date);
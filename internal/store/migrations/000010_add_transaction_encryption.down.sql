@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS enc_dek;
+ALTER TABLE transactions DROP COLUMN IF EXISTS enc_data;
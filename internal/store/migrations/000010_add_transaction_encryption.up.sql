@@ -0,0 +1,4 @@
+-- Envelope-encrypted sensitive fields (description, references, balance, extra)
+-- when store encryption is enabled; the plaintext columns are left empty.
+ALTER TABLE transactions ADD COLUMN enc_data BYTEA;
+ALTER TABLE transactions ADD COLUMN enc_dek BYTEA;
@@ -0,0 +1,2 @@
+-- Existing keys keep full access; new keys are created with explicit scopes.
+ALTER TABLE api_keys ADD COLUMN scopes TEXT[] NOT NULL DEFAULT '{read,scrape,admin}';
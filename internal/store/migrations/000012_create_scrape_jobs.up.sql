@@ -0,0 +1,16 @@
+CREATE TABLE scrape_jobs (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bank_code         VARCHAR(20) NOT NULL,
+    account_ids       UUID[] NOT NULL DEFAULT '{}',
+    transaction_count INT NOT NULL DEFAULT 0,
+    source            VARCHAR(20) NOT NULL,
+    client_id         VARCHAR(50) NOT NULL DEFAULT '',
+    status            VARCHAR(20) NOT NULL DEFAULT 'queued',
+    result            JSONB,
+    error             TEXT NOT NULL DEFAULT '',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at        TIMESTAMPTZ,
+    finished_at       TIMESTAMPTZ
+);
+
+CREATE INDEX idx_scrape_jobs_status ON scrape_jobs(status, created_at);
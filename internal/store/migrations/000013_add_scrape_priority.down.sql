@@ -0,0 +1,2 @@
+ALTER TABLE scrape_jobs DROP COLUMN IF EXISTS priority;
+ALTER TABLE accounts DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,3 @@
+-- High-priority accounts are scraped first (see internal/api/jobs)
+ALTER TABLE accounts ADD COLUMN priority VARCHAR(10) NOT NULL DEFAULT 'normal';
+ALTER TABLE scrape_jobs ADD COLUMN priority VARCHAR(10) NOT NULL DEFAULT 'normal';
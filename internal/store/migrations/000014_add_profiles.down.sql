@@ -0,0 +1,10 @@
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_profile_bank_code_account_number_key;
+ALTER TABLE accounts ADD CONSTRAINT accounts_bank_code_account_number_key UNIQUE (bank_code, account_number);
+
+DROP INDEX IF EXISTS idx_bank_credentials_active_bank;
+CREATE UNIQUE INDEX idx_bank_credentials_active_bank ON bank_credentials (bank_code) WHERE status = 'active';
+
+ALTER TABLE scrape_jobs DROP COLUMN IF EXISTS profile;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS profile;
+ALTER TABLE accounts DROP COLUMN IF EXISTS profile;
+ALTER TABLE bank_credentials DROP COLUMN IF EXISTS profile;
@@ -0,0 +1,14 @@
+-- Profiles isolate the credentials, accounts, API keys and scrape jobs of
+-- clients sharing one daemon (see store.WithProfile)
+ALTER TABLE bank_credentials ADD COLUMN profile VARCHAR(32) NOT NULL DEFAULT 'default';
+ALTER TABLE accounts ADD COLUMN profile VARCHAR(32) NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN profile VARCHAR(32) NOT NULL DEFAULT 'default';
+ALTER TABLE scrape_jobs ADD COLUMN profile VARCHAR(32) NOT NULL DEFAULT 'default';
+
+-- Only one active credential per bank within a profile
+DROP INDEX IF EXISTS idx_bank_credentials_active_bank;
+CREATE UNIQUE INDEX idx_bank_credentials_active_bank ON bank_credentials (profile, bank_code) WHERE status = 'active';
+
+-- Two profiles may see the same account (e.g. an accountant and the client)
+ALTER TABLE accounts DROP CONSTRAINT accounts_bank_code_account_number_key;
+ALTER TABLE accounts ADD CONSTRAINT accounts_profile_bank_code_account_number_key UNIQUE (profile, bank_code, account_number);
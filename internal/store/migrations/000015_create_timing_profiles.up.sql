@@ -0,0 +1,7 @@
+-- Step latencies learned per bank, sizing the scrapers' waits across
+-- restarts (see bank.Timings)
+CREATE TABLE timing_profiles (
+    bank_code  VARCHAR(20) PRIMARY KEY,
+    steps      JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
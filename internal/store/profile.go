@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// DefaultProfile owns the data of single-tenant deployments, and of any
+// context that names no profile.
+const DefaultProfile = "default"
+
+// ErrInvalidProfile is returned for a malformed profile name.
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// profileName is what a profile may be called: it shows up in URLs, flags
+// and env settings.
+var profileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ValidateProfile checks that name is a usable profile name: lowercase
+// letters, digits, '-' and '_', at most 32 characters.
+func ValidateProfile(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("%w: %q (use up to 32 lowercase letters, digits, - and _)", ErrInvalidProfile, name)
+	}
+	return nil
+}
+
+// ParseProfiles validates the profiles a daemon serves, e.g. from the
+// PROFILES setting, dropping duplicates. No profiles means DefaultProfile.
+func ParseProfiles(names []string) ([]string, error) {
+	var profiles []string
+	for _, name := range names {
+		if err := ValidateProfile(name); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(profiles, name) {
+			profiles = append(profiles, name)
+		}
+	}
+	if len(profiles) == 0 {
+		profiles = []string{DefaultProfile}
+	}
+	return profiles, nil
+}
+
+type profileKey struct{}
+
+// WithProfile returns a context scoped to profile. The repositories read and
+// write only that profile's credentials, accounts, API keys and scrape jobs
+// under it; the session manager keeps a separate bank session per profile.
+func WithProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, profileKey{}, profile)
+}
+
+// ProfileFrom returns the profile ctx is scoped to, or DefaultProfile.
+func ProfileFrom(ctx context.Context) string {
+	if p, ok := ctx.Value(profileKey{}).(string); ok && p != "" {
+		return p
+	}
+	return DefaultProfile
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProfile(t *testing.T) {
+	for _, name := range []string{"default", "acme", "client-42", "a_b"} {
+		assert.NoError(t, ValidateProfile(name), name)
+	}
+	for _, name := range []string{"", "Acme", "-acme", "acme corp", "a/b", "abcdefghijklmnopqrstuvwxyz0123456"} {
+		assert.ErrorIs(t, ValidateProfile(name), ErrInvalidProfile, name)
+	}
+}
+
+func TestParseProfiles(t *testing.T) {
+	profiles, err := ParseProfiles([]string{"default", "acme", "default"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "acme"}, profiles)
+
+	profiles, err = ParseProfiles(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfile}, profiles)
+
+	_, err = ParseProfiles([]string{"acme", "Globex"})
+	assert.ErrorIs(t, err, ErrInvalidProfile)
+}
+
+func TestProfileFrom(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, DefaultProfile, ProfileFrom(ctx))
+	assert.Equal(t, "acme", ProfileFrom(WithProfile(ctx, "acme")))
+	assert.Equal(t, DefaultProfile, ProfileFrom(WithProfile(ctx, "")))
+}
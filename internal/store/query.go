@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// queryTimeout bounds an ad-hoc query, so a runaway join can't hold the
+// database.
+const queryTimeout = 30 * time.Second
+
+// QueryResult is the outcome of an ad-hoc query.
+type QueryResult struct {
+	Columns   []string `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Truncated bool     `json:"truncated,omitempty"` // More rows matched than the limit
+}
+
+// Query runs one SQL statement over the store for ad-hoc analysis, in a
+// read-only transaction with a statement timeout, so it can't change data
+// however it is written. It returns at most limit rows (zero for all).
+// Values come back ready for printing and JSON: UUIDs and numerics as
+// strings, bytea as \x-prefixed hex. The statement sees every profile, and
+// the encrypted fields of STORE_ENCRYPTION as stored.
+func (db *DB) Query(ctx context.Context, sql string, limit int) (*QueryResult, error) {
+	tx, err := db.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", queryTimeout.Milliseconds())); err != nil {
+		return nil, fmt.Errorf("set timeout: %w", err)
+	}
+	rows, err := tx.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := &QueryResult{Rows: [][]any{}}
+	for _, f := range rows.FieldDescriptions() {
+		res.Columns = append(res.Columns, f.Name)
+	}
+	for rows.Next() {
+		if limit > 0 && len(res.Rows) == limit {
+			res.Truncated = true
+			break
+		}
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			values[i] = queryValue(v)
+		}
+		res.Rows = append(res.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// queryValue converts the pgx representations that don't print or encode
+// as JSON readably.
+func queryValue(v any) any {
+	switch v := v.(type) {
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case []byte:
+		return `\x` + hex.EncodeToString(v)
+	case pgtype.Numeric:
+		if !v.Valid {
+			return nil
+		}
+		if d, err := v.Value(); err == nil {
+			return d
+		}
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryValue(t *testing.T) {
+	id := uuid.New()
+	assert.Equal(t, id.String(), queryValue([16]byte(id)))
+	assert.Equal(t, `\x00ff`, queryValue([]byte{0, 255}))
+	assert.Equal(t, "1234.50", queryValue(pgtype.Numeric{Int: big.NewInt(123450), Exp: -2, Valid: true}))
+	assert.Nil(t, queryValue(pgtype.Numeric{}))
+	assert.Equal(t, int64(7), queryValue(int64(7)))
+}
+
+func TestDB_Query(t *testing.T) {
+	pool := testPool(t)
+	db := &DB{pool: pool}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := db.Query(ctx, "SELECT n, n * 2 AS twice FROM generate_series(1, 5) AS n", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"n", "twice"}, res.Columns)
+	assert.Len(t, res.Rows, 3)
+	assert.True(t, res.Truncated)
+
+	_, err = db.Query(ctx, "DELETE FROM accounts", 0)
+	assert.ErrorContains(t, err, "read-only")
+}
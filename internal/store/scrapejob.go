@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Scrape job status constants.
+const (
+	ScrapeJobQueued    = "queued"
+	ScrapeJobRunning   = "running"
+	ScrapeJobSucceeded = "succeeded"
+	ScrapeJobDegraded  = "degraded" // some accounts failed; the rest have results
+	ScrapeJobFailed    = "failed"
+)
+
+// Scrape job source constants.
+const (
+	ScrapeJobSourceAPI       = "api"
+	ScrapeJobSourceScheduler = "scheduler"
+	ScrapeJobSourceWebhook   = "webhook"
+)
+
+// ScrapeJob is a request to fetch balances and transactions from one bank,
+// tracked from enqueue to completion.
+type ScrapeJob struct {
+	ID               uuid.UUID
+	Profile          string // Profile whose accounts are scraped; see WithProfile
+	BankCode         string
+	AccountIDs       []uuid.UUID // empty means every active account of the bank
+	TransactionCount int         // transactions to fetch per account; 0 uses the queue default
+	Priority         string      // "normal", "high"; empty lets the queue decide
+	Source           string      // "api", "scheduler"
+	ClientID         string      // API client that enqueued the job, if any
+	Status           string      // "queued", "running", "succeeded", "degraded", "failed"
+	Result           *ScrapeResult
+	Error            string
+	CreatedAt        time.Time
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (j *ScrapeJob) Done() bool {
+	return j.Status == ScrapeJobSucceeded || j.Status == ScrapeJobDegraded || j.Status == ScrapeJobFailed
+}
+
+// ScrapeResult is what a finished scrape job fetched.
+type ScrapeResult struct {
+	Accounts     []ScrapeAccountResult `json:"accounts"`
+	Warnings     []bank.Warning        `json:"warnings,omitempty"`      // data the scraper skipped without failing
+	Requests     []bank.Request        `json:"requests,omitempty"`      // browser requests, when the scraper logs them
+	RawHTML      []bank.RawHTML        `json:"raw_html,omitempty"`      // parser inputs, when the queue keeps them
+	LoginVariant string                `json:"login_variant,omitempty"` // login page the bank session got (bank.Session.LoginVariant)
+	ClockSkew    *bank.ClockSkew       `json:"clock_skew,omitempty"`    // bank's clock vs ours, when the scraper read it
+}
+
+// Failed returns the results of accounts that could not be fully scraped.
+func (r *ScrapeResult) Failed() []ScrapeAccountResult {
+	if r == nil {
+		return nil
+	}
+	var out []ScrapeAccountResult
+	for _, a := range r.Accounts {
+		if a.Error != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// ScrapeAccountResult is the data fetched for one account. Error is set when
+// part of it could not be fetched; whatever did succeed is still included.
+type ScrapeAccountResult struct {
+	AccountID    uuid.UUID          `json:"account_id"`
+	Balance      *bank.Balance      `json:"balance,omitempty"`
+	Transactions []bank.Transaction `json:"transactions"`
+	Gap          *Gap               `json:"gap,omitempty"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// Gap is a stretch of an account's history missing from the store, found
+// because the balance moved more than the scraped transactions explain.
+type Gap struct {
+	From        time.Time `json:"from"`        // Day of the last stored transaction with a balance
+	To          time.Time `json:"to"`          // Day of the oldest transaction scraped
+	Unexplained int64     `json:"unexplained"` // Cents the balance moved beyond the scraped transactions
+	Backfilled  bool      `json:"backfilled"`  // Whether a deeper fetch reached back to From
+}
+
+// ScrapeJobRepository defines operations on the scrape_jobs table.
+type ScrapeJobRepository interface {
+	Create(ctx context.Context, j *ScrapeJob) error
+	Get(ctx context.Context, id uuid.UUID) (*ScrapeJob, error)
+	Update(ctx context.Context, j *ScrapeJob) error
+	ListByStatus(ctx context.Context, status string) ([]ScrapeJob, error)
+	ListRecent(ctx context.Context, limit int) ([]ScrapeJob, error)
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ScrapeJobRepo implements ScrapeJobRepository using pgx.
+type ScrapeJobRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewScrapeJobRepo creates a new ScrapeJobRepo.
+func NewScrapeJobRepo(pool *pgxpool.Pool) *ScrapeJobRepo {
+	return &ScrapeJobRepo{pool: pool}
+}
+
+const scrapeJobColumns = `id, profile, bank_code, account_ids, transaction_count, priority, source, client_id,
+	status, result, error, created_at, started_at, finished_at`
+
+func scanScrapeJob(row pgx.Row) (ScrapeJob, error) {
+	var j ScrapeJob
+	err := row.Scan(
+		&j.ID, &j.Profile, &j.BankCode, &j.AccountIDs, &j.TransactionCount, &j.Priority, &j.Source, &j.ClientID,
+		&j.Status, &j.Result, &j.Error, &j.CreatedAt, &j.StartedAt, &j.FinishedAt,
+	)
+	return j, err
+}
+
+// Create inserts a new job and populates its generated fields.
+func (r *ScrapeJobRepo) Create(ctx context.Context, j *ScrapeJob) error {
+	if j.Status == "" {
+		j.Status = ScrapeJobQueued
+	}
+	if j.Priority == "" {
+		j.Priority = PriorityNormal
+	}
+	if j.AccountIDs == nil {
+		j.AccountIDs = []uuid.UUID{}
+	}
+	if j.Profile == "" {
+		j.Profile = ProfileFrom(ctx)
+	}
+	query := `
+		INSERT INTO scrape_jobs (profile, bank_code, account_ids, transaction_count, priority, source, client_id, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		j.Profile, j.BankCode, j.AccountIDs, j.TransactionCount, j.Priority, j.Source, j.ClientID, j.Status,
+	).Scan(&j.ID, &j.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create scrape job: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a job by ID. Returns ErrNotFound if it doesn't exist.
+func (r *ScrapeJobRepo) Get(ctx context.Context, id uuid.UUID) (*ScrapeJob, error) {
+	query := `SELECT ` + scrapeJobColumns + ` FROM scrape_jobs WHERE id = $1`
+
+	j, err := scanScrapeJob(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("scrape job %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get scrape job: %w", err)
+	}
+	return &j, nil
+}
+
+// Update saves a job's progress: status, result, error and timestamps.
+func (r *ScrapeJobRepo) Update(ctx context.Context, j *ScrapeJob) error {
+	query := `
+		UPDATE scrape_jobs SET status = $2, result = $3, error = $4, started_at = $5, finished_at = $6
+		WHERE id = $1`
+
+	tag, err := r.pool.Exec(ctx, query, j.ID, j.Status, j.Result, j.Error, j.StartedAt, j.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("update scrape job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("scrape job %s: %w", j.ID, ErrNotFound)
+	}
+	return nil
+}
+
+// ListByStatus returns jobs with the given status, oldest first.
+func (r *ScrapeJobRepo) ListByStatus(ctx context.Context, status string) ([]ScrapeJob, error) {
+	query := `SELECT ` + scrapeJobColumns + ` FROM scrape_jobs WHERE status = $1 ORDER BY created_at`
+
+	rows, err := r.pool.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("list scrape jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ScrapeJob, error) {
+		return scanScrapeJob(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan scrape jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ListRecent returns the latest limit jobs of the context's profile (see
+// WithProfile), newest first.
+func (r *ScrapeJobRepo) ListRecent(ctx context.Context, limit int) ([]ScrapeJob, error) {
+	query := `SELECT ` + scrapeJobColumns + ` FROM scrape_jobs WHERE profile = $1 ORDER BY created_at DESC LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, ProfileFrom(ctx), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list recent scrape jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ScrapeJob, error) {
+		return scanScrapeJob(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan scrape jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// DeleteFinishedBefore removes jobs of every profile, with their results,
+// that finished before before. Queued and running jobs are kept. Returns
+// how many were removed.
+func (r *ScrapeJobRepo) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM scrape_jobs WHERE finished_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete finished scrape jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
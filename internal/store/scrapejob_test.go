@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeJobRepo_Lifecycle(t *testing.T) {
+	pool := testPool(t)
+	truncateTables(t, pool)
+	repo := NewScrapeJobRepo(pool)
+	ctx := context.Background()
+
+	accountID := uuid.New()
+	j := &ScrapeJob{BankCode: "BBVA", AccountIDs: []uuid.UUID{accountID}, Source: ScrapeJobSourceAPI, ClientID: "erp"}
+	require.NoError(t, repo.Create(ctx, j))
+	assert.NotEqual(t, uuid.Nil, j.ID)
+	assert.Equal(t, ScrapeJobQueued, j.Status)
+
+	queued, err := repo.ListByStatus(ctx, ScrapeJobQueued)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, []uuid.UUID{accountID}, queued[0].AccountIDs)
+
+	now := time.Now()
+	j.Status = ScrapeJobSucceeded
+	j.StartedAt, j.FinishedAt = &now, &now
+	j.Result = &ScrapeResult{Accounts: []ScrapeAccountResult{{
+		AccountID:    accountID,
+		Balance:      &bank.Balance{AccountID: "001", Currency: bank.CurrencyPEN, AvailableBalance: 100},
+		Transactions: []bank.Transaction{{ID: "1", Amount: 50, Type: bank.TransactionCredit}},
+	}}}
+	require.NoError(t, repo.Update(ctx, j))
+
+	got, err := repo.Get(ctx, j.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Done())
+	require.NotNil(t, got.Result)
+	assert.Equal(t, int64(100), got.Result.Accounts[0].Balance.AvailableBalance)
+	assert.Equal(t, "1", got.Result.Accounts[0].Transactions[0].ID)
+
+	_, err = repo.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	later := &ScrapeJob{BankCode: "BBVA", Source: ScrapeJobSourceScheduler}
+	require.NoError(t, repo.Create(ctx, later))
+	require.NoError(t, repo.Create(WithProfile(ctx, "acme"), &ScrapeJob{BankCode: "BBVA", Source: ScrapeJobSourceScheduler}))
+	recent, err := repo.ListRecent(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, recent, 2, "other profiles left out")
+	assert.Equal(t, later.ID, recent[0].ID)
+
+	n, err := repo.DeleteFinishedBefore(ctx, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "queued jobs kept")
+	_, err = repo.Get(ctx, j.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.Get(ctx, later.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TimingProfileRepository defines operations on the timing_profiles table.
+type TimingProfileRepository interface {
+	Load(ctx context.Context) (map[bank.Code]bank.TimingProfile, error)
+	Save(ctx context.Context, code bank.Code, profile bank.TimingProfile) error
+}
+
+// TimingProfileRepo implements TimingProfileRepository using pgx.
+type TimingProfileRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewTimingProfileRepo creates a new TimingProfileRepo.
+func NewTimingProfileRepo(pool *pgxpool.Pool) *TimingProfileRepo {
+	return &TimingProfileRepo{pool: pool}
+}
+
+// Load returns every bank's saved timing profile.
+func (r *TimingProfileRepo) Load(ctx context.Context) (map[bank.Code]bank.TimingProfile, error) {
+	rows, err := r.pool.Query(ctx, `SELECT bank_code, steps FROM timing_profiles`)
+	if err != nil {
+		return nil, fmt.Errorf("load timing profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := map[bank.Code]bank.TimingProfile{}
+	for rows.Next() {
+		var (
+			code  string
+			steps []byte
+		)
+		if err := rows.Scan(&code, &steps); err != nil {
+			return nil, fmt.Errorf("scan timing profile: %w", err)
+		}
+		var p bank.TimingProfile
+		if err := json.Unmarshal(steps, &p); err != nil {
+			return nil, fmt.Errorf("decode %s timing profile: %w", code, err)
+		}
+		profiles[bank.Code(code)] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load timing profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// Save replaces code's timing profile.
+func (r *TimingProfileRepo) Save(ctx context.Context, code bank.Code, profile bank.TimingProfile) error {
+	steps, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("encode %s timing profile: %w", code, err)
+	}
+	query := `
+		INSERT INTO timing_profiles (bank_code, steps, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (bank_code) DO UPDATE SET steps = EXCLUDED.steps, updated_at = EXCLUDED.updated_at`
+
+	if _, err := r.pool.Exec(ctx, query, string(code), steps, time.Now()); err != nil {
+		return fmt.Errorf("save %s timing profile: %w", code, err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingProfileRepo_SaveLoad(t *testing.T) {
+	pool := testPool(t)
+	truncateTables(t, pool)
+	repo := NewTimingProfileRepo(pool)
+	ctx := context.Background()
+
+	profile := bank.TimingProfile{bank.StepLogin: {Samples: 3, Mean: 2 * time.Second, Max: 4 * time.Second}}
+	require.NoError(t, repo.Save(ctx, bank.BankBBVA, profile))
+
+	profile[bank.StepLogin] = bank.StepTiming{Samples: 4, Mean: 3 * time.Second, Max: 6 * time.Second}
+	require.NoError(t, repo.Save(ctx, bank.BankBBVA, profile), "upserts")
+
+	profiles, err := repo.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[bank.Code]bank.TimingProfile{bank.BankBBVA: profile}, profiles)
+}
@@ -0,0 +1,392 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/schema"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/parseutil"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Transaction source constants.
+const (
+	TransactionSourceScrape = "scrape"
+	TransactionSourceImport = "import"
+)
+
+// Transaction is a stored account movement. DedupHash identifies the
+// movement independently of where it came from, so a scraped row and the
+// same row loaded from a downloaded statement collapse into one record.
+type Transaction struct {
+	ID            uuid.UUID
+	AccountID     uuid.UUID
+	DedupHash     []byte
+	BankID        string // Bank's document/reference number, empty if unknown
+	Reference     string
+	OperationDate time.Time
+	ValueDate     *time.Time
+	Description   string
+	Amount        int64 // Always positive, in cents
+	Type          string
+	BalanceAfter  *int64
+	Extra         map[string]string
+	Source        string // "scrape", "import"
+	SchemaVersion string
+	CreatedAt     time.Time
+}
+
+// BankTransaction converts t back to the bank.Transaction it was stored
+// from, e.g. to hash it again with a DedupStrategy.
+func (t Transaction) BankTransaction() bank.Transaction {
+	tx := bank.Transaction{
+		ID:           t.BankID,
+		Reference:    t.Reference,
+		Date:         t.OperationDate,
+		Description:  t.Description,
+		Amount:       t.Amount,
+		Type:         bank.TransactionType(t.Type),
+		BalanceAfter: t.BalanceAfter,
+		Extra:        t.Extra,
+	}
+	if t.ValueDate != nil {
+		tx.ValueDate = *t.ValueDate
+	}
+	return tx
+}
+
+// TransactionRepository defines operations on the transactions table.
+type TransactionRepository interface {
+	InsertBatch(ctx context.Context, txns []Transaction) (int, error)
+	ListByAccount(ctx context.Context, accountID uuid.UUID) ([]Transaction, error)
+}
+
+// FieldCipher encrypts sensitive fields at rest.
+// Satisfied by credmgr/crypto.FieldCipher.
+type FieldCipher interface {
+	Seal(plaintext []byte) (encData, encDEK []byte, err error)
+	Open(encData, encDEK []byte) ([]byte, error)
+	MAC(data []byte) []byte
+}
+
+// FieldCiphers returns a profile's FieldCipher, so each profile's rows are
+// sealed under its own key (see credmgr/crypto.Keyring).
+type FieldCiphers func(profile string) FieldCipher
+
+// TransactionRepo implements TransactionRepository using pgx.
+type TransactionRepo struct {
+	pool    *pgxpool.Pool
+	ciphers FieldCiphers // nil stores plaintext
+}
+
+// NewTransactionRepo creates a new TransactionRepo that stores plaintext.
+func NewTransactionRepo(pool *pgxpool.Pool) *TransactionRepo {
+	return &TransactionRepo{pool: pool}
+}
+
+// NewEncryptedTransactionRepo creates a TransactionRepo that encrypts the
+// description, document numbers, running balance and extra metadata of new
+// rows. Dates, amount and type stay in plaintext so rows can be filtered and
+// summed. Dedup hashes are keyed (HMAC) so they can't be brute-forced back
+// into descriptions; as a consequence rows written before encryption was
+// enabled don't dedup against new ones. Plaintext rows remain readable.
+// Rows are sealed with the cipher of the context's profile (see WithProfile),
+// and only open under it.
+func NewEncryptedTransactionRepo(pool *pgxpool.Pool, ciphers FieldCiphers) *TransactionRepo {
+	return &TransactionRepo{pool: pool, ciphers: ciphers}
+}
+
+// sealedFields is the encrypted payload of a transaction row.
+type sealedFields struct {
+	BankID       string            `json:"bank_id,omitempty"`
+	Reference    string            `json:"reference,omitempty"`
+	Description  string            `json:"description"`
+	BalanceAfter *int64            `json:"balance_after,omitempty"`
+	Extra        map[string]string `json:"extra,omitempty"`
+}
+
+// seal moves t's sensitive fields into an encrypted payload and keys its
+// dedup hash. It returns the row to write; t is not modified.
+func (r *TransactionRepo) seal(ctx context.Context, t Transaction) (row Transaction, encData, encDEK []byte, err error) {
+	if r.ciphers == nil {
+		return t, nil, nil, nil
+	}
+	cipher := r.ciphers(ProfileFrom(ctx))
+	plaintext, err := json.Marshal(sealedFields{
+		BankID:       t.BankID,
+		Reference:    t.Reference,
+		Description:  t.Description,
+		BalanceAfter: t.BalanceAfter,
+		Extra:        t.Extra,
+	})
+	if err != nil {
+		return t, nil, nil, fmt.Errorf("marshal sealed fields: %w", err)
+	}
+	encData, encDEK, err = cipher.Seal(plaintext)
+	if err != nil {
+		return t, nil, nil, fmt.Errorf("seal transaction: %w", err)
+	}
+
+	row = t
+	row.DedupHash = cipher.MAC(t.DedupHash)
+	row.BankID, row.Reference, row.Description = "", "", ""
+	row.BalanceAfter, row.Extra = nil, nil
+	return row, encData, encDEK, nil
+}
+
+// open restores the sensitive fields of an encrypted row.
+func (r *TransactionRepo) open(ctx context.Context, t *Transaction, encData, encDEK []byte) error {
+	if encData == nil {
+		return nil
+	}
+	if r.ciphers == nil {
+		return fmt.Errorf("transaction %s is encrypted but no cipher is configured", t.ID)
+	}
+	plaintext, err := r.ciphers(ProfileFrom(ctx)).Open(encData, encDEK)
+	if err != nil {
+		return fmt.Errorf("open transaction %s: %w", t.ID, err)
+	}
+	var f sealedFields
+	if err := json.Unmarshal(plaintext, &f); err != nil {
+		return fmt.Errorf("unmarshal sealed fields: %w", err)
+	}
+	t.BankID, t.Reference, t.Description = f.BankID, f.Reference, f.Description
+	t.BalanceAfter, t.Extra = f.BalanceAfter, f.Extra
+	return nil
+}
+
+// TransactionHash returns the dedup hash of tx. It covers only what every
+// source agrees on — operation date, type, amount and the normalized
+// description — so a statement export (which has no document numbers and
+// prints descriptions with different spacing) matches the scraped row.
+// occurrence distinguishes identical movements on the same day: the first is
+// 0, the next 1, and so on.
+func TransactionHash(tx bank.Transaction, occurrence int) []byte {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		tx.Date.Format(time.DateOnly),
+		string(tx.Type),
+		strconv.FormatInt(tx.Amount, 10),
+		strings.ToUpper(parseutil.CleanDescription(tx.Description)),
+		strconv.Itoa(occurrence),
+	}, "\x1f")))
+	return sum[:]
+}
+
+// DocumentHash returns the dedup hash of a transaction that has a document
+// number: the same fields as TransactionHash, with the document number in
+// place of the occurrence.
+func DocumentHash(tx bank.Transaction) []byte {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		tx.Date.Format(time.DateOnly),
+		string(tx.Type),
+		strconv.FormatInt(tx.Amount, 10),
+		strings.ToUpper(parseutil.CleanDescription(tx.Description)),
+		"doc:" + strings.TrimLeft(strings.TrimSpace(tx.ID), "0"),
+	}, "\x1f")))
+	return sum[:]
+}
+
+// ErrInvalidDedupStrategy is returned for an unknown strategy name.
+var ErrInvalidDedupStrategy = errors.New("invalid dedup strategy")
+
+// DedupStrategy decides how identical movements on the same day (several
+// ITF charges, say) are told apart.
+type DedupStrategy string
+
+// Dedup strategies.
+const (
+	// DedupSequence numbers identical movements in the order the bank lists
+	// them. It works for every source, including statements without
+	// document numbers.
+	DedupSequence DedupStrategy = "sequence"
+	// DedupDocument keys movements by document number, falling back to
+	// DedupSequence for rows without one. Suits banks whose scrapes and
+	// statement exports both carry document numbers; rows stored under
+	// another strategy won't dedup against it.
+	DedupDocument DedupStrategy = "document"
+)
+
+// NewTransactions converts scraped or imported transactions into records for
+// accountID with DedupSequence. See DedupStrategy.NewTransactions.
+func NewTransactions(accountID uuid.UUID, source string, txns []bank.Transaction) []Transaction {
+	return DedupSequence.NewTransactions(accountID, source, txns)
+}
+
+// NewTransactions converts scraped or imported transactions into records for
+// accountID, computing their dedup hashes with s. Identical movements hashed
+// by sequence get increasing occurrence numbers in slice order. Since
+// identical movements are interchangeable, a bank reordering same-day rows
+// between fetches yields the same set of hashes.
+func (s DedupStrategy) NewTransactions(accountID uuid.UUID, source string, txns []bank.Transaction) []Transaction {
+	seen := map[string]int{}
+	out := make([]Transaction, 0, len(txns))
+	for _, tx := range txns {
+		var hash []byte
+		if s == DedupDocument && strings.TrimSpace(tx.ID) != "" {
+			hash = DocumentHash(tx)
+		} else {
+			key := string(TransactionHash(tx, 0))
+			hash = TransactionHash(tx, seen[key])
+			seen[key]++
+		}
+
+		rec := Transaction{
+			AccountID:     accountID,
+			DedupHash:     hash,
+			BankID:        tx.ID,
+			Reference:     tx.Reference,
+			OperationDate: tx.Date,
+			Description:   tx.Description,
+			Amount:        tx.Amount,
+			Type:          string(tx.Type),
+			BalanceAfter:  tx.BalanceAfter,
+			Extra:         tx.Extra,
+			Source:        source,
+		}
+		if !tx.ValueDate.IsZero() {
+			vd := tx.ValueDate
+			rec.ValueDate = &vd
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// DedupConfig is the dedup strategy of each bank, by bank code. Banks not
+// listed use DedupSequence.
+type DedupConfig map[string]DedupStrategy
+
+// ParseDedupConfig validates a bank code → strategy name map, e.g. from the
+// DEDUP_STRATEGIES setting.
+func ParseDedupConfig(m map[string]string) (DedupConfig, error) {
+	c := make(DedupConfig, len(m))
+	for code, name := range m {
+		s := DedupStrategy(strings.ToLower(strings.TrimSpace(name)))
+		if s != DedupSequence && s != DedupDocument {
+			return nil, fmt.Errorf("%w: %q for %s (use sequence or document)", ErrInvalidDedupStrategy, name, code)
+		}
+		c[strings.ToUpper(code)] = s
+	}
+	return c, nil
+}
+
+// For returns bankCode's strategy.
+func (c DedupConfig) For(bankCode string) DedupStrategy {
+	if s, ok := c[strings.ToUpper(bankCode)]; ok {
+		return s
+	}
+	return DedupSequence
+}
+
+// CoveredFrom returns the first day a fetch of count transactions covers
+// completely. Scrapers return the newest count movements, so a full fetch
+// (len(txns) >= count) may have cut its oldest day short: rows of that day
+// missing from it may still exist. Overlapping fetches should only treat a
+// row as new if it is on or after this day. Returns the zero time for an
+// empty fetch.
+func CoveredFrom(txns []bank.Transaction, count int) time.Time {
+	var oldest time.Time
+	for _, tx := range txns {
+		if oldest.IsZero() || tx.Date.Before(oldest) {
+			oldest = tx.Date
+		}
+	}
+	if oldest.IsZero() {
+		return oldest
+	}
+	day := time.Date(oldest.Year(), oldest.Month(), oldest.Day(), 0, 0, 0, 0, oldest.Location())
+	if count > 0 && len(txns) >= count {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+const transactionColumns = `id, account_id, dedup_hash, bank_id, reference, operation_date,
+	value_date, description, amount, type, balance_after, extra, source, schema_version, created_at,
+	enc_data, enc_dek`
+
+// InsertBatch inserts txns in a single transaction, skipping rows whose dedup
+// hash already exists for the account. Returns how many rows were inserted.
+// With a cipher configured, rows are sealed before writing.
+func (r *TransactionRepo) InsertBatch(ctx context.Context, txns []Transaction) (int, error) {
+	if len(txns) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin insert transactions: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		INSERT INTO transactions (account_id, dedup_hash, bank_id, reference, operation_date,
+			value_date, description, amount, type, balance_after, extra, source, schema_version,
+			enc_data, enc_dek)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (account_id, dedup_hash) DO NOTHING`
+
+	inserted := 0
+	for i, t := range txns {
+		t, encData, encDEK, err := r.seal(ctx, t)
+		if err != nil {
+			return 0, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		tag, err := tx.Exec(ctx, query,
+			t.AccountID, t.DedupHash, t.BankID, t.Reference, t.OperationDate,
+			t.ValueDate, t.Description, t.Amount, t.Type, t.BalanceAfter, t.Extra, t.Source, schema.Version,
+			encData, encDEK,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert transaction %d (%s): %w", i, t.OperationDate.Format(time.DateOnly), err)
+		}
+		inserted += int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit insert transactions: %w", err)
+	}
+	return inserted, nil
+}
+
+// ListByAccount returns an account's transactions, oldest first, decrypting
+// sealed rows.
+func (r *TransactionRepo) ListByAccount(ctx context.Context, accountID uuid.UUID) ([]Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions
+		WHERE account_id = $1 ORDER BY operation_date, created_at`
+
+	rows, err := r.pool.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("list transactions: %w", err)
+	}
+	defer rows.Close()
+
+	txns, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Transaction, error) {
+		var (
+			t               Transaction
+			encData, encDEK []byte
+		)
+		err := row.Scan(
+			&t.ID, &t.AccountID, &t.DedupHash, &t.BankID, &t.Reference, &t.OperationDate,
+			&t.ValueDate, &t.Description, &t.Amount, &t.Type, &t.BalanceAfter, &t.Extra, &t.Source,
+			&t.SchemaVersion, &t.CreatedAt, &encData, &encDEK,
+		)
+		if err != nil {
+			return t, err
+		}
+		return t, r.open(ctx, &t, encData, encDEK)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan transactions: %w", err)
+	}
+	return txns, nil
+}
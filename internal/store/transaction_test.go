@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionHash(t *testing.T) {
+	day := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	scraped := bank.Transaction{
+		ID:          "000123",
+		Date:        day,
+		Description: "*C/ PAGO FACTURA | SUNAT DETRACCIONES",
+		Amount:      15_000,
+		Type:        bank.TransactionDebit,
+	}
+	imported := bank.Transaction{
+		Date:        day,
+		Description: "  PAGO   FACTURA | Sunat Detracciones ",
+		Amount:      15_000,
+		Type:        bank.TransactionDebit,
+	}
+
+	assert.Equal(t, TransactionHash(scraped, 0), TransactionHash(imported, 0),
+		"document number, codes, spacing and case do not affect the hash")
+	assert.NotEqual(t, TransactionHash(scraped, 0), TransactionHash(scraped, 1))
+
+	other := scraped
+	other.Type = bank.TransactionCredit
+	assert.NotEqual(t, TransactionHash(scraped, 0), TransactionHash(other, 0))
+}
+
+func TestNewTransactions_Occurrences(t *testing.T) {
+	day := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	fee := bank.Transaction{Date: day, Description: "COMISION", Amount: 500, Type: bank.TransactionDebit}
+	accountID := uuid.New()
+
+	recs := NewTransactions(accountID, TransactionSourceImport, []bank.Transaction{fee, fee})
+
+	require.Len(t, recs, 2)
+	assert.Equal(t, TransactionHash(fee, 0), recs[0].DedupHash)
+	assert.Equal(t, TransactionHash(fee, 1), recs[1].DedupHash)
+	assert.Equal(t, accountID, recs[1].AccountID)
+	assert.Equal(t, TransactionSourceImport, recs[1].Source)
+	assert.Nil(t, recs[0].ValueDate)
+}
+
+func TestDedupDocument(t *testing.T) {
+	day := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	itf := bank.Transaction{Date: day, Description: "ITF", Amount: 5, Type: bank.TransactionDebit}
+	a, b, noDoc := itf, itf, itf
+	a.ID, b.ID = "000123", "000124"
+
+	recs := DedupDocument.NewTransactions(uuid.New(), TransactionSourceScrape, []bank.Transaction{b, noDoc, a, noDoc})
+	require.Len(t, recs, 4)
+	assert.Equal(t, DocumentHash(b), recs[0].DedupHash)
+	assert.Equal(t, DocumentHash(a), recs[2].DedupHash)
+	assert.NotEqual(t, recs[0].DedupHash, recs[2].DedupHash, "same day, amount and text; different documents")
+	assert.Equal(t, TransactionHash(itf, 0), recs[1].DedupHash, "rows without a document fall back to sequence")
+	assert.Equal(t, TransactionHash(itf, 1), recs[3].DedupHash)
+
+	padded := a
+	padded.ID = "123"
+	assert.Equal(t, DocumentHash(a), DocumentHash(padded), "leading zeros ignored")
+
+	seq := DedupSequence.NewTransactions(uuid.New(), TransactionSourceScrape, []bank.Transaction{a, b})
+	assert.Equal(t, TransactionHash(itf, 1), seq[1].DedupHash, "sequence ignores documents")
+}
+
+func TestParseDedupConfig(t *testing.T) {
+	c, err := ParseDedupConfig(map[string]string{"bbva": " Document "})
+	require.NoError(t, err)
+	assert.Equal(t, DedupDocument, c.For("BBVA"))
+	assert.Equal(t, DedupSequence, c.For("INTERBANK"))
+	assert.Equal(t, DedupSequence, DedupConfig(nil).For("BBVA"))
+
+	_, err = ParseDedupConfig(map[string]string{"BBVA": "hash"})
+	assert.ErrorIs(t, err, ErrInvalidDedupStrategy)
+}
+
+func TestCoveredFrom(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 2, d, 0, 0, 0, 0, time.UTC) }
+	txns := []bank.Transaction{{Date: day(12)}, {Date: day(11).Add(15 * time.Hour)}, {Date: day(10)}}
+
+	assert.Equal(t, day(10), CoveredFrom(txns, 50), "fewer than asked for: complete")
+	assert.Equal(t, day(11), CoveredFrom(txns, 3), "full fetch: oldest day may be cut short")
+	assert.Equal(t, day(11), CoveredFrom(txns[:2], 50), "truncated to the day")
+	assert.True(t, CoveredFrom(nil, 50).IsZero())
+}
+
+func TestTransactionRepo_InsertBatch_SkipsDuplicates(t *testing.T) {
+	pool := testPool(t)
+	truncateTables(t, pool)
+	u := createTestUser(t, NewUserRepo(pool))
+	cred := createTestCredential(t, NewCredentialRepo(pool), u.ID)
+	a := createTestAccount(t, NewAccountRepo(pool), cred.ID, "BBVA", "001-12345678-0-01", "PEN")
+	repo := NewTransactionRepo(pool)
+
+	ctx := context.Background()
+	day := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	txns := []bank.Transaction{
+		{Date: day, ValueDate: day, Description: "ABONO", Amount: 10_000, Type: bank.TransactionCredit},
+		{Date: day, Description: "COMISION", Amount: 500, Type: bank.TransactionDebit, Extra: map[string]string{"Codigo": "015"}},
+	}
+
+	n, err := repo.InsertBatch(ctx, NewTransactions(a.ID, TransactionSourceImport, txns))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = repo.InsertBatch(ctx, NewTransactions(a.ID, TransactionSourceScrape, txns[1:]))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "already imported")
+
+	stored, err := repo.ListByAccount(ctx, a.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, TransactionSourceImport, stored[1].Source)
+	assert.Equal(t, map[string]string{"Codigo": "015"}, stored[1].Extra)
+	require.NotNil(t, stored[0].ValueDate)
+}
+
+// xorCipher is a reversible stand-in for crypto.FieldCipher.
+type xorCipher struct{ key byte }
+
+func (c xorCipher) Seal(p []byte) ([]byte, []byte, error) {
+	out := make([]byte, len(p))
+	for i := range p {
+		out[i] = p[i] ^ c.key
+	}
+	return out, []byte("dek"), nil
+}
+
+func (c xorCipher) Open(d, _ []byte) ([]byte, error) {
+	out, _, err := c.Seal(d)
+	return out, err
+}
+
+func (xorCipher) MAC(d []byte) []byte { return append([]byte("mac:"), d...) }
+
+// xorCiphers gives each profile its own xorCipher key.
+func xorCiphers(profile string) FieldCipher {
+	return xorCipher{key: 0x5a ^ byte(len(profile))}
+}
+
+func TestTransactionRepo_SealOpen(t *testing.T) {
+	balance := int64(42)
+	in := Transaction{
+		ID:           uuid.New(),
+		DedupHash:    []byte("h"),
+		BankID:       "000123",
+		Description:  "PAGO FACTURA",
+		Amount:       100,
+		BalanceAfter: &balance,
+		Extra:        map[string]string{"Codigo": "015"},
+	}
+	repo := &TransactionRepo{ciphers: xorCiphers}
+	ctx := context.Background()
+
+	row, encData, encDEK, err := repo.seal(ctx, in)
+	require.NoError(t, err)
+	assert.Empty(t, row.Description)
+	assert.Empty(t, row.BankID)
+	assert.Nil(t, row.BalanceAfter)
+	assert.Nil(t, row.Extra)
+	assert.Equal(t, int64(100), row.Amount, "amount stays queryable")
+	assert.Equal(t, []byte("mac:h"), row.DedupHash)
+	assert.NotContains(t, string(encData), "PAGO")
+	assert.Equal(t, "PAGO FACTURA", in.Description, "input not modified")
+
+	err = repo.open(WithProfile(ctx, "acme"), &row, encData, encDEK)
+	assert.Error(t, err, "another profile's key doesn't open the row")
+
+	require.NoError(t, repo.open(ctx, &row, encData, encDEK))
+	assert.Equal(t, "PAGO FACTURA", row.Description)
+	assert.Equal(t, "000123", row.BankID)
+	assert.Equal(t, &balance, row.BalanceAfter)
+	assert.Equal(t, in.Extra, row.Extra)
+
+	err = (&TransactionRepo{}).open(ctx, &row, encData, encDEK)
+	assert.ErrorContains(t, err, "no cipher")
+}
+
+func TestTransactionRepo_Encrypted(t *testing.T) {
+	pool := testPool(t)
+	truncateTables(t, pool)
+	u := createTestUser(t, NewUserRepo(pool))
+	cred := createTestCredential(t, NewCredentialRepo(pool), u.ID)
+	a := createTestAccount(t, NewAccountRepo(pool), cred.ID, "BBVA", "001-12345678-0-01", "PEN")
+	repo := NewEncryptedTransactionRepo(pool, xorCiphers)
+
+	ctx := context.Background()
+	txns := []bank.Transaction{{Date: time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), Description: "ABONO", Amount: 10_000, Type: bank.TransactionCredit}}
+
+	n, err := repo.InsertBatch(ctx, NewTransactions(a.ID, TransactionSourceImport, txns))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = repo.InsertBatch(ctx, NewTransactions(a.ID, TransactionSourceScrape, txns))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "keyed hashes still dedup")
+
+	var stored string
+	require.NoError(t, pool.QueryRow(ctx, `SELECT description FROM transactions`).Scan(&stored))
+	assert.Empty(t, stored)
+
+	got, err := repo.ListByAccount(ctx, a.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "ABONO", got[0].Description)
+}
@@ -0,0 +1,326 @@
+// Package support builds the archive a user attaches when reporting a
+// broken scraper: versions, the configuration without secrets, the tail of
+// the log, recent run summaries, the parsers' self-test and, if asked for,
+// the debug artifacts of failed sessions. Everything text goes through
+// Redact on the way in; screenshots and recordings, which can't be
+// redacted, are left out.
+package support
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/runlog"
+	"github.com/aynifx/bank-scraper/internal/schema"
+)
+
+// Limits on what a bundle takes, so it stays small enough to attach.
+const (
+	MaxLogBytes      = 1 << 20  // Tail of the log file
+	MaxRuns          = 20       // Most recent run summaries
+	MaxArtifactBytes = 16 << 20 // Debug artifacts, newest first
+)
+
+// ManifestName is the name of the manifest inside the archive.
+const ManifestName = "manifest.json"
+
+// Options says what goes into a bundle.
+type Options struct {
+	// Documents are written as NAME.json, e.g. the redacted configuration
+	// and the self-test checks.
+	Documents map[string]any
+	LogFile   string // Log file whose tail goes under logs/; empty for none
+	RunDir    string // Directory of runlog run folders; empty for none
+	Artifacts string // Debug artifact directory; empty leaves artifacts out
+}
+
+// Versions identifies the build that made the bundle.
+type Versions struct {
+	Module    string `json:"module"`
+	Revision  string `json:"revision,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a dirty tree
+	Go        string `json:"go"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	Schema    string `json:"schema"`
+	BuildTags string `json:"build_tags,omitempty"`
+}
+
+// Manifest lists a bundle's contents. It is the archive's first file and
+// what Build returns.
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Versions  Versions  `json:"versions"`
+	Files     []string  `json:"files"`
+	Skipped   []string  `json:"skipped,omitempty"` // Left out, and why
+	Path      string    `json:"path,omitempty"`    // Where the archive was written; set by the caller
+}
+
+// CurrentVersions returns the versions of the running binary.
+func CurrentVersions() Versions {
+	v := Versions{Go: runtime.Version(), OS: runtime.GOOS, Arch: runtime.GOARCH, Schema: schema.Version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	v.Module = info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			v.Revision = s.Value
+		case "vcs.modified":
+			v.Modified = s.Value == "true"
+		case "-tags":
+			v.BuildTags = s.Value
+		}
+	}
+	return v
+}
+
+// Build writes a gzipped tar bundle to w. Sources that are missing or
+// unreadable are noted in the manifest's Skipped rather than failing the
+// bundle: a broken setup is what it is for.
+func Build(w io.Writer, opts Options) (*Manifest, error) {
+	m := &Manifest{CreatedAt: time.Now().UTC(), Versions: CurrentVersions()}
+	files := map[string][]byte{}
+	add := func(name string, data []byte) {
+		files[name] = data
+		m.Files = append(m.Files, name)
+	}
+	skip := func(format string, args ...any) {
+		m.Skipped = append(m.Skipped, fmt.Sprintf(format, args...))
+	}
+
+	names := make([]string, 0, len(opts.Documents))
+	for name := range opts.Documents {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		data, err := json.MarshalIndent(opts.Documents[name], "", "  ")
+		if err != nil {
+			skip("%s.json: %v", name, err)
+			continue
+		}
+		add(name+".json", []byte(Redact(string(data))+"\n"))
+	}
+
+	if opts.LogFile != "" {
+		if data, err := tail(opts.LogFile, MaxLogBytes); err != nil {
+			skip("log %s: %v", opts.LogFile, err)
+		} else {
+			add("logs/"+filepath.Base(opts.LogFile), []byte(Redact(string(data))))
+		}
+	} else {
+		skip("logs: no log file (set LOG_FILE or pass --logs)")
+	}
+
+	if opts.RunDir != "" {
+		addRuns(opts.RunDir, add, skip)
+	} else {
+		skip("runs: no run directory (set RUN_DIR or pass --run-dir)")
+	}
+
+	if opts.Artifacts != "" {
+		addArtifacts(opts.Artifacts, add, skip)
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, f := range append([]string{ManifestName}, m.Files...) {
+		data := files[f]
+		if f == ManifestName {
+			data = append(manifest, '\n')
+		}
+		hdr := &tar.Header{Name: f, Mode: 0o644, Size: int64(len(data)), ModTime: m.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("write %s: %w", f, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, fmt.Errorf("write %s: %w", f, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("write bundle: %w", err)
+	}
+	return m, nil
+}
+
+// tail returns up to the last n bytes of the file at path, starting at a
+// line.
+func tail(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= n {
+		return io.ReadAll(f)
+	}
+	if _, err := f.Seek(-n, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+// addRuns adds the run.json of the MaxRuns latest run folders under dir as
+// runs/FOLDER.json. Folder names start with the run's start time, so they
+// sort by age.
+func addRuns(dir string, add func(string, []byte), skip func(string, ...any)) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		skip("runs %s: %v", dir, err)
+		return
+	}
+	var runs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			runs = append(runs, e.Name())
+		}
+	}
+	slices.Sort(runs)
+	if len(runs) > MaxRuns {
+		skip("runs: %d older runs", len(runs)-MaxRuns)
+		runs = runs[len(runs)-MaxRuns:]
+	}
+	for _, run := range runs {
+		data, err := os.ReadFile(filepath.Join(dir, run, runlog.FileName))
+		if err != nil {
+			skip("run %s: %v", run, err)
+			continue
+		}
+		add("runs/"+run+".json", []byte(Redact(string(data))))
+	}
+}
+
+// artifactExts are the artifact files Redact can clean; the rest
+// (screenshots, screencasts) are skipped.
+var artifactExts = []string{".html", ".json", ".txt", ".log", ".har"}
+
+// addArtifacts adds the text artifacts under dir as artifacts/..., newest
+// first until MaxArtifactBytes.
+func addArtifacts(dir string, add func(string, []byte), skip func(string, ...any)) {
+	type artifact struct {
+		rel  string
+		size int64
+		mod  time.Time
+	}
+	var found []artifact
+	images := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !slices.Contains(artifactExts, strings.ToLower(filepath.Ext(path))) {
+			images++
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		found = append(found, artifact{rel: filepath.ToSlash(rel), size: info.Size(), mod: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		skip("artifacts %s: %v", dir, err)
+		return
+	}
+	if images > 0 {
+		skip("artifacts: %d screenshots and recordings, which can't be redacted", images)
+	}
+
+	slices.SortFunc(found, func(a, b artifact) int { return b.mod.Compare(a.mod) })
+	var total int64
+	for i, a := range found {
+		if total+a.size > MaxArtifactBytes {
+			skip("artifacts: %d older files over the %d MiB limit", len(found)-i, MaxArtifactBytes>>20)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join(dir, a.rel))
+		if err != nil {
+			skip("artifact %s: %v", a.rel, err)
+			continue
+		}
+		total += a.size
+		add("artifacts/"+a.rel, []byte(Redact(string(data))))
+	}
+}
+
+const redacted = "[REDACTED]"
+
+var (
+	// secretValue matches a value given to a sensitive key, as key=value,
+	// key: value, "key": "value" or "key": [list]. Hook keys (ScrapeHooks'
+	// signing secrets, NotifyWebhooks' URLs) count as sensitive.
+	secretValue = regexp.MustCompile(`(?i)("?[\w.-]*(?:password|passwd|clave|contrase|secret|token|session|cookie|authorization|api_?key|credential|private_key|hook)[\w.-]*"?\s*[:=]\s*)("[^"]*"|\[[^\]]*\]|(?:(?:bearer|basic)\s+)?[^\s,;&{}\[\]]+)`)
+	// webhookURL matches the URL of a chat webhook, whose path carries its
+	// token.
+	webhookURL = regexp.MustCompile(`(?i)(https?://(?:hooks\.slack\.com|(?:[\w-]+\.)?discord(?:app)?\.com|api\.telegram\.org))/[^\s"'<>]*`)
+	bearer     = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[\w.~+/=-]+`)
+	email      = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	// digitRun matches account, card and document numbers, which may be
+	// split by dashes.
+	digitRun = regexp.MustCompile(`\b\d[\d-]{6,}\d\b`)
+	isoDate  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// Redact masks what a bundle mustn't leak: the values of password, token,
+// session, hook and similar keys, bearer credentials, chat webhook paths and
+// email addresses are replaced by [REDACTED], and runs of 8 or more digits
+// (account, card and document numbers) keep only their last 4. Dates are
+// left alone.
+func Redact(s string) string {
+	s = secretValue.ReplaceAllStringFunc(s, func(m string) string {
+		sub := secretValue.FindStringSubmatch(m)
+		switch {
+		case strings.HasPrefix(sub[2], `"`):
+			return sub[1] + `"` + redacted + `"`
+		case strings.HasPrefix(sub[2], "["):
+			return sub[1] + `["` + redacted + `"]`
+		}
+		return sub[1] + redacted
+	})
+	s = webhookURL.ReplaceAllString(s, "$1/"+redacted)
+	s = bearer.ReplaceAllString(s, "$1 "+redacted)
+	s = email.ReplaceAllString(s, redacted)
+	return digitRun.ReplaceAllStringFunc(s, func(m string) string {
+		digits := strings.ReplaceAll(m, "-", "")
+		if len(digits) < 8 || isoDate.MatchString(m) {
+			return m
+		}
+		return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+	})
+}
@@ -0,0 +1,128 @@
+package support
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aynifx/bank-scraper/internal/config"
+	"github.com/aynifx/bank-scraper/internal/runlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	for in, want := range map[string]string{
+		`level=INFO msg=login password=hunter2 user=ana`:         `level=INFO msg=login password=[REDACTED] user=ana`,
+		`{"session_id": "abc123", "ok": true}`:                   `{"session_id": "[REDACTED]", "ok": true}`,
+		`Authorization: Bearer eyJhbGciOi.x.y`:                   `Authorization: [REDACTED]`,
+		`account 0011-0100-0200004607 failed`:                    `account **************4607 failed`,
+		`DNI 12345678`:                                           `DNI ****5678`,
+		`mail ana.perez@example.com`:                             `mail [REDACTED]`,
+		`"started_at": "2026-03-01T09:00:00Z", "exit_code": 5`:   `"started_at": "2026-03-01T09:00:00Z", "exit_code": 5`,
+		`operation 1234567 is short`:                             `operation 1234567 is short`,
+		`GET /transactions?token=abc&page=2 took 20260301T0900Z`: `GET /transactions?token=[REDACTED]&page=2 took 20260301T0900Z`,
+		`POST https://hooks.slack.com/services/T0/B0/xyz 200`:    `POST https://hooks.slack.com/[REDACTED] 200`,
+		`"ScrapeHooks": ["payroll=s3cret"], "Profiles": ["a"]`:   `"ScrapeHooks": ["[REDACTED]"], "Profiles": ["a"]`,
+		`"ScrapeHookProfiles": {"payroll": "acme"}`:              `"ScrapeHookProfiles": {"payroll": "acme"}`,
+	} {
+		assert.Equal(t, want, Redact(in), in)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "scraper.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("level=WARN msg=\"get transactions\" account=0011-0100-0200004607\n"), 0o600))
+
+	runs := filepath.Join(dir, "runs")
+	_, err := runlog.Write(runs, &runlog.Summary{Command: "backfill", Warnings: []string{"BBVA 0011-0100-0200004607: timeout"}})
+	require.NoError(t, err)
+
+	artifacts := filepath.Join(dir, "debug", "login-1")
+	require.NoError(t, os.MkdirAll(artifacts, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(artifacts, "Login-failed.html"), []byte(`<input name="clave" value="x"> 0011-0100-0200004607`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(artifacts, "Login-failed.png"), []byte("png"), 0o600))
+
+	var buf bytes.Buffer
+	m, err := Build(&buf, Options{
+		Documents: map[string]any{"config": map[string]string{"BANKS": "BBVA"}},
+		LogFile:   logFile,
+		RunDir:    runs,
+		Artifacts: filepath.Join(dir, "debug"),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, m.Versions.Go)
+	assert.Len(t, m.Files, 4)
+	assert.Contains(t, m.Skipped, "artifacts: 1 screenshots and recordings, which can't be redacted")
+
+	files := untar(t, &buf)
+	assert.Contains(t, files, ManifestName)
+	assert.Contains(t, files["config.json"], `"BANKS": "BBVA"`)
+	assert.Contains(t, files["logs/scraper.log"], "account=**************4607")
+	for name, content := range files {
+		assert.NotContains(t, content, "0200004607", name)
+	}
+	assert.Contains(t, files["artifacts/login-1/Login-failed.html"], "****4607")
+	assert.NotContains(t, files, "artifacts/login-1/Login-failed.png")
+}
+
+func TestBuild_NoSecrets(t *testing.T) {
+	cfg := config.Config{
+		DatabaseURL:    "postgres://scraper:db-pass@db/bank",
+		EncryptionKey:  "0123abcd",
+		BBVA:           config.BBVAConfig{Password: "bbva-pass"},
+		ScrapeHooks:    []string{"payroll=hook-secret"},
+		NotifyWebhooks: []string{"ops=https://hooks.slack.com/services/T0/B0/slack-token", "erp=https://erp.example/notify?key=erp-key"},
+	}
+	logFile := filepath.Join(t.TempDir(), "api.log")
+	require.NoError(t, os.WriteFile(logFile, []byte(
+		"level=WARN msg=\"notify failed\" channel=ops url=https://hooks.slack.com/services/T0/B0/slack-token\n"+
+			"level=INFO msg=\"hook verified\" hook_secret=hook-secret\n"), 0o600))
+
+	var buf bytes.Buffer
+	_, err := Build(&buf, Options{
+		Documents: map[string]any{"config": cfg.Redacted()},
+		LogFile:   logFile,
+	})
+	require.NoError(t, err)
+
+	files := untar(t, &buf)
+	require.Contains(t, files, "config.json")
+	for name, content := range files {
+		for _, secret := range []string{"db-pass", "0123abcd", "bbva-pass", "hook-secret", "slack-token", "erp-key"} {
+			assert.NotContains(t, content, secret, name)
+		}
+	}
+}
+
+func TestBuild_MissingSources(t *testing.T) {
+	var buf bytes.Buffer
+	m, err := Build(&buf, Options{LogFile: filepath.Join(t.TempDir(), "missing.log")})
+	require.NoError(t, err, "a broken setup still gets a bundle")
+	assert.Empty(t, m.Files)
+	assert.Len(t, m.Skipped, 2)
+	assert.Contains(t, untar(t, &buf), ManifestName)
+}
+
+func untar(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(data)
+	}
+}
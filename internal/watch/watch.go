@@ -0,0 +1,133 @@
+// Package watch waits for an expected incoming transfer: it polls an
+// account at a fixed interval until a credit matching the amount and
+// counterparty shows up or the deadline passes, for the "has the client
+// paid yet?" question that otherwise means refreshing the portal by hand.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+)
+
+// MinInterval is the shortest polling interval Wait accepts: each poll is
+// a full trip through the bank's portal, and polling faster gets sessions
+// flagged.
+const MinInterval = time.Minute
+
+// DefaultCount is how many recent transactions each poll reads.
+const DefaultCount = 50
+
+// Sentinel errors.
+var (
+	// ErrNotArrived is returned by Wait when the deadline passes first.
+	ErrNotArrived = errors.New("expected transfer did not arrive")
+	// ErrInvalidExpectation is returned for an expectation matching
+	// anything, or a polling interval under MinInterval.
+	ErrInvalidExpectation = errors.New("invalid expected transfer")
+)
+
+// Expectation describes the credit being waited for.
+type Expectation struct {
+	AccountNumber string
+	Amount        int64     // In cents; zero matches any amount
+	Counterparty  string    // Matched case-insensitively within the description; empty matches anyone
+	Since         time.Time // Credits on earlier days don't count
+}
+
+// Validate checks that e names an account and something to match.
+func (e Expectation) Validate() error {
+	if e.AccountNumber == "" {
+		return fmt.Errorf("%w: no account", ErrInvalidExpectation)
+	}
+	if e.Amount < 0 {
+		return fmt.Errorf("%w: negative amount", ErrInvalidExpectation)
+	}
+	if e.Amount == 0 && strings.TrimSpace(e.Counterparty) == "" {
+		return fmt.Errorf("%w: give an amount, a counterparty or both", ErrInvalidExpectation)
+	}
+	return nil
+}
+
+// Matches reports whether tx is the expected credit.
+func (e Expectation) Matches(tx bank.Transaction) bool {
+	if tx.Type != bank.TransactionCredit {
+		return false
+	}
+	if e.Amount != 0 && tx.Amount != e.Amount {
+		return false
+	}
+	if !e.Since.IsZero() {
+		y, m, d := e.Since.Date()
+		if tx.Date.Before(time.Date(y, m, d, 0, 0, 0, 0, tx.Date.Location())) {
+			return false
+		}
+	}
+	who := strings.ToUpper(strings.TrimSpace(e.Counterparty))
+	return who == "" ||
+		strings.Contains(strings.ToUpper(tx.Description), who) ||
+		strings.Contains(strings.ToUpper(tx.CleanDescription), who)
+}
+
+// Result is how a wait ended.
+type Result struct {
+	Transaction *bank.Transaction `json:"transaction,omitempty"` // The matching credit; nil if it didn't arrive
+	Polls       int               `json:"polls"`
+	Waited      time.Duration     `json:"waited_ns"`
+}
+
+// Wait polls e's account through s every interval until a transaction
+// matches e, and returns it. It gives up with ErrNotArrived when ctx's
+// deadline passes. A poll failing on a transient error (bank down, session
+// lapsed) is logged and retried at the next tick; any other error ends the
+// wait, since retrying a rejected credential could lock the bank user.
+func Wait(ctx context.Context, s bank.Scraper, e Expectation, interval time.Duration) (*Result, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	if interval < MinInterval {
+		return nil, fmt.Errorf("%w: interval %s is under %s", ErrInvalidExpectation, interval, MinInterval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	return poll(ctx, s, e, ticker.C)
+}
+
+// poll runs Wait's loop, polling again on every tick.
+func poll(ctx context.Context, s bank.Scraper, e Expectation, tick <-chan time.Time) (*Result, error) {
+	started := time.Now()
+	res := &Result{}
+	for {
+		res.Polls++
+		txns, err := s.GetTransactions(ctx, e.AccountNumber, DefaultCount)
+		switch {
+		case ctx.Err() != nil:
+			// Cut short by the deadline; given up on below
+		case err != nil && bank.Classify(err) != bank.ClassRetryable:
+			res.Waited = time.Since(started)
+			return res, err
+		case err != nil:
+			slog.Warn("watch: poll failed, retrying", slog.Int("poll", res.Polls), slog.Any("error", err))
+		default:
+			for _, tx := range txns {
+				if e.Matches(tx) {
+					res.Transaction, res.Waited = &tx, time.Since(started)
+					return res, nil
+				}
+			}
+			slog.Debug("watch: not yet", slog.Int("poll", res.Polls), slog.Int("transactions", len(txns)))
+		}
+
+		select {
+		case <-ctx.Done():
+			res.Waited = time.Since(started)
+			return res, fmt.Errorf("%w after %d polls: %w", ErrNotArrived, res.Polls, ctx.Err())
+		case <-tick:
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/banktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectation_Matches(t *testing.T) {
+	today := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	e := Expectation{AccountNumber: "001", Amount: 150_000, Counterparty: "acme", Since: today.Add(15 * time.Hour)}
+	paid := bank.Transaction{Date: today, Description: "ABONO TRANSF ACME SAC", Amount: 150_000, Type: bank.TransactionCredit}
+	assert.True(t, e.Matches(paid), "same day as Since, case-insensitive")
+
+	for name, tx := range map[string]bank.Transaction{
+		"debit":        {Date: today, Description: paid.Description, Amount: paid.Amount, Type: bank.TransactionDebit},
+		"other amount": {Date: today, Description: paid.Description, Amount: 15_000, Type: bank.TransactionCredit},
+		"other payer":  {Date: today, Description: "ABONO BETA SRL", Amount: paid.Amount, Type: bank.TransactionCredit},
+		"before Since": {Date: today.AddDate(0, 0, -1), Description: paid.Description, Amount: paid.Amount, Type: bank.TransactionCredit},
+	} {
+		assert.False(t, e.Matches(tx), name)
+	}
+
+	anyAmount := Expectation{AccountNumber: "001", Counterparty: "ACME"}
+	assert.True(t, anyAmount.Matches(bank.Transaction{Description: "ACME", Amount: 1, Type: bank.TransactionCredit}))
+	assert.ErrorIs(t, Expectation{AccountNumber: "001"}.Validate(), ErrInvalidExpectation, "matches any credit")
+}
+
+func TestWait_Validation(t *testing.T) {
+	e := Expectation{AccountNumber: "001", Amount: 100}
+	_, err := Wait(context.Background(), &banktest.MockScraper{}, e, time.Second)
+	assert.ErrorIs(t, err, ErrInvalidExpectation, "interval under MinInterval")
+}
+
+// pollScraper returns its transactions only from the given poll on, and
+// fails the polls listed in errs.
+type pollScraper struct {
+	*banktest.MockScraper
+	arrivesAt int
+	errs      map[int]error
+	polls     int
+}
+
+func (s *pollScraper) GetTransactions(ctx context.Context, accountID string, count int) ([]bank.Transaction, error) {
+	s.polls++
+	if err := s.errs[s.polls]; err != nil {
+		return nil, err
+	}
+	if s.polls < s.arrivesAt {
+		return nil, nil
+	}
+	return s.MockScraper.GetTransactions(ctx, accountID, count)
+}
+
+func TestPoll(t *testing.T) {
+	paid := bank.Transaction{Description: "ABONO ACME", Amount: 500, Type: bank.TransactionCredit}
+	s := &pollScraper{
+		MockScraper: &banktest.MockScraper{Transactions: []bank.Transaction{paid}},
+		arrivesAt:   3,
+		errs:        map[int]error{2: bank.ErrBankUnavailable},
+	}
+	tick := make(chan time.Time, 10)
+	for range 10 {
+		tick <- time.Time{}
+	}
+
+	res, err := poll(context.Background(), s, Expectation{AccountNumber: "001", Amount: 500}, tick)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Polls, "retried past the outage")
+	assert.Equal(t, "ABONO ACME", res.Transaction.Description)
+}
+
+func TestPoll_Deadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err := poll(ctx, &banktest.MockScraper{}, Expectation{AccountNumber: "001", Amount: 500}, nil)
+	require.ErrorIs(t, err, ErrNotArrived)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, res.Transaction)
+	assert.Equal(t, 1, res.Polls)
+}
+
+func TestPoll_StopsOnCredentialError(t *testing.T) {
+	s := &banktest.MockScraper{TransactionsErr: bank.ErrInvalidCredentials}
+	_, err := poll(context.Background(), s, Expectation{AccountNumber: "001", Amount: 500}, nil)
+	assert.ErrorIs(t, err, bank.ErrInvalidCredentials)
+}
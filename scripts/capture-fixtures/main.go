@@ -3,17 +3,21 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/stealth"
+	"github.com/aynifx/bank-scraper/internal/config"
+	"github.com/aynifx/bank-scraper/internal/logging"
 	browserutil "github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/factory"
 )
 
 // Pages to capture for each bank, ordered by natural portal flow.
@@ -43,12 +47,13 @@ type PageCapture struct {
 }
 
 func main() {
+	defer logging.Init()()
 	bankCode := flag.String("bank", "", "Bank code: bbva, interbank, bcp")
 	outputDir := flag.String("output", "", "Output directory (default: internal/scraper/bank/{bank}/testdata/fixtures)")
 	flag.Parse()
 
 	if *bankCode == "" {
-		fmt.Println("Usage: go run main.go -bank=bbva")
+		fmt.Fprintln(os.Stderr, "Usage: go run ./scripts/capture-fixtures -bank=bbva [-output=DIR] [-v|-q] [-log-json] [-log-file=PATH]")
 		os.Exit(1)
 	}
 
@@ -60,33 +65,19 @@ func main() {
 
 	// Create output directory
 	if err := os.MkdirAll(outDir, 0o755); err != nil {
-		fmt.Printf("Error creating directory: %v\n", err)
+		slog.Error("create output directory", slog.String("dir", outDir), slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║           BANK FIXTURE CAPTURE TOOL                            ║")
-	fmt.Println("╠════════════════════════════════════════════════════════════════╣")
-	fmt.Printf("║  Bank: %-54s  ║\n", strings.ToUpper(*bankCode))
-	fmt.Printf("║  Output: %-52s  ║\n", outDir)
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
+	slog.Info("capturing fixtures", slog.String("bank", strings.ToUpper(*bankCode)), slog.String("output", outDir))
 
 	// Launch visible browser
-	url := launcher.New().
-		Bin("/usr/bin/google-chrome").
-		Headless(false).
-		// CRITICAL: Disable the "Automation" internal flags
-		Set("disable-blink-features", "AutomationControlled").
-		Set("exclude-switches", "enable-automation").
-
-		// Standard "Human" Args
-		Set("no-first-run").
-		Set("no-default-browser-check").
-		Set("window-size", "1920,1080").
-		// Set("user-agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/60.0.3112.50 Safari/537.36").
-		Devtools(false).
-		MustLaunch()
+	bin, err := resolveBrowser()
+	if err != nil {
+		slog.Error("find browser", slog.Any("error", err))
+		os.Exit(1)
+	}
+	url := browserutil.NewVisibleLauncher(bin).MustLaunch()
 
 	browser := rod.New().
 		ControlURL(url).
@@ -97,89 +88,47 @@ func main() {
 	// Create initial page
 	page := stealth.MustPage(browser)
 
-	// page := browser.MustPage("")
-
 	reader := bufio.NewReader(os.Stdin)
 
-	fmt.Println("📋 Instructions:")
-	fmt.Println("   - A browser window has opened")
-	fmt.Println("   - Follow the prompts below")
-	fmt.Println("   - Press ENTER after completing each step")
-	fmt.Println("   - Type 'skip' to skip a page")
-	fmt.Println("   - Type 'quit' to exit")
+	fmt.Println("Instructions:")
+	fmt.Println("  - A browser window has opened")
+	fmt.Println("  - Follow the prompts below")
+	fmt.Println("  - Press ENTER after completing each step")
+	fmt.Println("  - Type 'skip' to skip a page")
+	fmt.Println("  - Type 'quit' to exit")
 	fmt.Println()
 
 	for _, capture := range capturePages {
-		fmt.Println("────────────────────────────────────────────────────────────────")
-		fmt.Printf("📄 Capturing: %s.html\n", capture.Name)
-		fmt.Printf("📝 Instructions: %s\n", capture.Instructions)
-		fmt.Print("   Press ENTER when ready (or 'skip'/'quit'): ")
+		fmt.Println("----------------------------------------------------------------")
+		fmt.Printf("Capturing: %s.html\n", capture.Name)
+		fmt.Printf("  %s\n", capture.Instructions)
+		fmt.Print("  Press ENTER when ready (or 'skip'/'quit'): ")
 
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(strings.ToLower(input))
 
 		if input == "quit" {
-			fmt.Println("\n👋 Exiting...")
 			break
 		}
 
 		if input == "skip" {
-			fmt.Printf("   ⏭️  Skipped %s\n\n", capture.Name)
-			continue
-		}
-
-		// -- Step 1: Wait for DOM to stabilize, including iframes
-		browserutil.WaitForIFrames(page)
-		time.Sleep(1 * time.Second)
-
-		// -- Step 2: Screenshot BEFORE DOM modification --
-		// Taking the screenshot before the inlining preserves visual fidelity
-		screenshotPath := filepath.Join(outDir, capture.Name+".png")
-		if buf, err := page.Screenshot(false, nil); err == nil {
-			if writeErr := os.WriteFile(screenshotPath, buf, 0o644); writeErr != nil {
-				fmt.Printf("   ⚠️  Error saving screenshot: %v\n", err)
-			} else {
-				fmt.Printf("   📸 Screenshot: %s\n", screenshotPath)
-			}
-		} else {
-			fmt.Printf("   ⚠️  Screenshot failed: %v\n", err)
-		}
-
-		// -- Step 3: Flatten shadow DOM + iframes into single HTML
-		html, shadowCount, iframeCount, err := browserutil.FlattenShadowDOM(page)
-		if err != nil {
-			fmt.Printf("   ❌ Error capturing HTML: %v\n\n", err)
+			slog.Info("skipped", slog.String("fixture", capture.Name))
 			continue
 		}
 
-		if shadowCount > 0 || iframeCount > 0 {
-			fmt.Printf("   🔲 Flattened %d shadow root(s) and %d iframe(s) into captured HTML\n", shadowCount, iframeCount)
-		}
-
-		// -- Step 4: Save HTML fixture --
-		htmlPath := filepath.Join(outDir, capture.Name+".html")
-		if err := os.WriteFile(htmlPath, []byte(html), 0o644); err != nil {
-			fmt.Printf("   ❌ Error saving HTML: %v\n\n", err)
-			continue
-		}
-
-		// Get page URL for reference
-		pageURL := page.MustInfo().URL
-
-		fmt.Printf("   ✅ Saved: %s\n", htmlPath)
-		fmt.Printf("   🔗 URL: %s\n\n", pageURL)
+		captureFixture(page, outDir, capture.Name)
 	}
 
 	// Custom capture mode: let the user capture ad-hoc fixtures by name
 	fmt.Println()
-	fmt.Println("────────────────────────────────────────────────────────────────")
+	fmt.Println("----------------------------------------------------------------")
 	fmt.Println("Custom capture mode:")
 	fmt.Println("  Type a fixture name to capture (e.g., \"cookie_popup\")")
 	fmt.Println("  Press ENTER with no name to finish")
-	fmt.Println("────────────────────────────────────────────────────────────────")
+	fmt.Println("----------------------------------------------------------------")
 
 	for {
-		fmt.Print("\n📄 Fixture name (or ENTER to finish): ")
+		fmt.Print("\nFixture name (or ENTER to finish): ")
 		nameInput, _ := reader.ReadString('\n')
 		nameInput = strings.TrimSpace(nameInput)
 
@@ -187,53 +136,54 @@ func main() {
 			break
 		}
 
-		fmt.Printf("   Navigate the browser to the desired state, then press ENTER to capture...")
+		fmt.Printf("  Navigate the browser to the desired state, then press ENTER to capture...")
 		_, _ = reader.ReadString('\n')
 
-		browserutil.WaitForIFrames(page)
-		time.Sleep(1 * time.Second)
-
-		screenshotPath := filepath.Join(outDir, nameInput+".png")
-		if buf, err := page.Screenshot(false, nil); err == nil {
-			if writeErr := os.WriteFile(screenshotPath, buf, 0o644); writeErr != nil {
-				fmt.Printf("   ⚠️  Error saving screenshot: %v\n", writeErr)
-			} else {
-				fmt.Printf("   📸 Screenshot: %s\n", screenshotPath)
-			}
-		} else {
-			fmt.Printf("   ⚠️  Screenshot failed: %v\n", err)
-		}
+		captureFixture(page, outDir, nameInput)
+	}
 
-		html, shadowCount, iframeCount, err := browserutil.FlattenShadowDOM(page)
-		if err != nil {
-			fmt.Printf("   ❌ Error capturing HTML: %v\n", err)
-			continue
-		}
+	// Save metadata
+	saveMetadata(outDir, *bankCode)
 
-		if shadowCount > 0 || iframeCount > 0 {
-			fmt.Printf("   🔲 Flattened %d shadow root(s) and %d iframe(s) into captured HTML\n", shadowCount, iframeCount)
-		}
+	slog.Info("capture complete")
+	slog.Warn("sanitize sensitive data before committing: go run ./scripts/sanitize-patterns -bank=" + *bankCode)
+}
 
-		htmlPath := filepath.Join(outDir, nameInput+".html")
-		if err := os.WriteFile(htmlPath, []byte(html), 0o644); err != nil {
-			fmt.Printf("   ❌ Error saving HTML: %v\n", err)
-			continue
-		}
+// captureFixture saves the page as name.html, flattened, and a screenshot
+// as name.png, logging rather than stopping on failure so the next
+// capture can still be taken.
+func captureFixture(page *rod.Page, outDir, name string) {
+	// -- Step 1: Wait for DOM to stabilize, including iframes
+	waitForFrames(page)
+	time.Sleep(1 * time.Second)
+
+	// -- Step 2: Screenshot BEFORE DOM modification --
+	// Taking the screenshot before the inlining preserves visual fidelity
+	screenshotPath := filepath.Join(outDir, name+".png")
+	if buf, err := page.Screenshot(false, nil); err != nil {
+		slog.Warn("screenshot failed", slog.String("fixture", name), slog.Any("error", err))
+	} else if err := os.WriteFile(screenshotPath, buf, 0o644); err != nil {
+		slog.Warn("save screenshot", slog.String("path", screenshotPath), slog.Any("error", err))
+	} else {
+		slog.Info("screenshot saved", slog.String("path", screenshotPath))
+	}
 
-		pageURL := page.MustInfo().URL
-		fmt.Printf("   ✅ Saved: %s\n", htmlPath)
-		fmt.Printf("   🔗 URL: %s\n", pageURL)
+	// -- Step 3: Flatten shadow DOM + iframes into single HTML
+	html, shadowCount, iframeCount, err := browserutil.FlattenShadowDOM(page)
+	if err != nil {
+		slog.Error("capture HTML", slog.String("fixture", name), slog.Any("error", err))
+		return
 	}
+	slog.Debug("flattened page", slog.Int("shadow_roots", shadowCount), slog.Int("iframes", iframeCount))
 
-	// Save metadata
-	saveMetadata(outDir, *bankCode)
+	// -- Step 4: Save HTML fixture --
+	htmlPath := filepath.Join(outDir, name+".html")
+	if err := os.WriteFile(htmlPath, []byte(html), 0o644); err != nil {
+		slog.Error("save HTML", slog.String("path", htmlPath), slog.Any("error", err))
+		return
+	}
 
-	fmt.Println("════════════════════════════════════════════════════════════════")
-	fmt.Println("✅ Capture complete!")
-	fmt.Println()
-	fmt.Println("⚠️  IMPORTANT: Sanitize sensitive data before committing!")
-	fmt.Println("   Run: go run ./scripts/sanitize-fixtures/main.go -bank=" + *bankCode)
-	fmt.Println("════════════════════════════════════════════════════════════════")
+	slog.Info("fixture saved", slog.String("path", htmlPath), slog.String("url", page.MustInfo().URL))
 }
 
 func saveMetadata(outDir, bankCode string) {
@@ -286,3 +236,32 @@ Target a specific shadow host:
 	metaPath := filepath.Join(outDir, "README.md")
 	_ = os.WriteFile(metaPath, []byte(metadata), 0o644)
 }
+
+// resolveBrowser finds the browser the scrapers would use (BROWSER_* env
+// vars), downloading the pinned revision if the policy allows.
+func resolveBrowser() (string, error) {
+	cfg, err := config.LoadBrowser()
+	if err != nil {
+		return "", err
+	}
+	r, err := factory.Resolver(*cfg)
+	if err != nil {
+		return "", err
+	}
+	res, err := r.Resolve(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return res.Path, nil
+}
+
+// waitForFrames waits for the page and its iframes to settle, warning
+// rather than stopping when they don't: the capture is still worth taking.
+func waitForFrames(page *rod.Page) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	summary, err := browserutil.WaitForIFrames(ctx, page)
+	if err != nil {
+		slog.Warn("frames did not settle", slog.String("frames", summary.String()), slog.Any("error", err))
+	}
+}
@@ -13,16 +13,20 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/stealth"
+	"github.com/aynifx/bank-scraper/internal/config"
+	"github.com/aynifx/bank-scraper/internal/logging"
 	browserutil "github.com/aynifx/bank-scraper/internal/scraper/browser"
+	"github.com/aynifx/bank-scraper/internal/scraper/factory"
 )
 
 // selectorProbe is a known CSS selector to search for in each frame.
@@ -75,11 +79,12 @@ var bbvaPages = []pageToInspect{
 }
 
 func main() {
+	defer logging.Init()()
 	bankCode := flag.String("bank", "", "Bank code: bbva")
 	flag.Parse()
 
 	if *bankCode == "" {
-		fmt.Println("Usage: go run ./scripts/discover-iframes -bank=bbva")
+		fmt.Fprintln(os.Stderr, "Usage: go run ./scripts/discover-iframes -bank=bbva [-v|-q] [-log-json] [-log-file=PATH]")
 		os.Exit(1)
 	}
 
@@ -95,16 +100,12 @@ func main() {
 	fmt.Println()
 
 	// Launch visible browser
-	url := launcher.New().
-		Bin("/usr/bin/google-chrome").
-		Headless(false).
-		Set("disable-blink-features", "AutomationControlled").
-		Set("exclude-switches", "enable-automation").
-		Set("no-first-run").
-		Set("no-default-browser-check").
-		Set("window-size", "1920,1080").
-		Devtools(false).
-		MustLaunch()
+	bin, err := resolveBrowser()
+	if err != nil {
+		slog.Error("find browser", slog.Any("error", err))
+		os.Exit(1)
+	}
+	url := browserutil.NewVisibleLauncher(bin).MustLaunch()
 
 	browser := rod.New().ControlURL(url).MustConnect()
 	defer browser.MustClose()
@@ -131,7 +132,7 @@ func main() {
 		}
 
 		// Wait for DOM stability across all frames
-		browserutil.WaitForIFrames(page)
+		waitForFrames(page)
 		time.Sleep(500 * time.Millisecond)
 
 		pageURL := page.MustInfo().URL
@@ -216,7 +217,7 @@ func getProbes(bankCode string) []selectorProbe {
 	case "bbva":
 		return bbvaProbes
 	default:
-		fmt.Printf("No probes defined for bank %q, using BBVA defaults\n", bankCode)
+		slog.Warn("no probes defined for bank, using BBVA defaults", slog.String("bank", bankCode))
 		return bbvaProbes
 	}
 }
@@ -243,3 +244,32 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// resolveBrowser finds the browser the scrapers would use (BROWSER_* env
+// vars), downloading the pinned revision if the policy allows.
+func resolveBrowser() (string, error) {
+	cfg, err := config.LoadBrowser()
+	if err != nil {
+		return "", err
+	}
+	r, err := factory.Resolver(*cfg)
+	if err != nil {
+		return "", err
+	}
+	res, err := r.Resolve(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return res.Path, nil
+}
+
+// waitForFrames waits for the page and its iframes to settle, warning
+// rather than stopping when they don't: the capture is still worth taking.
+func waitForFrames(page *rod.Page) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	summary, err := browserutil.WaitForIFrames(ctx, page)
+	if err != nil {
+		slog.Warn("frames did not settle", slog.String("frames", summary.String()), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,349 @@
+// record-scenario refreshes a bank's replay recordings from a live run.
+//
+// Usage:
+//
+//	go run ./scripts/record-scenario -bank=bbva
+//
+// It reads the bank's stored credential through the credential manager
+// (DATABASE_URL and ENCRYPTION_KEY from .env), logs in to the real portal in a
+// visible browser and runs the standard flows: login, accounts page,
+// transactions of the first account, logout. Each flow's traffic is
+// sanitized and written to testdata/recordings/{scenario}.har.json, with
+// what the scraper returned in {scenario}.expected.json, so replay tests can
+// assert against it, and listed in testdata/recordings/manifest.json with
+// when it was recorded (see testutil.Manifest).
+//
+// Both files are redacted with a testutil.Redactor: the account numbers and
+// balances the run found, and the names passed with -redact, are replaced by
+// stand-ins. Nothing is written unless every flow succeeds, and the files
+// replace the old ones only once all of them are written.
+//
+// The run logs in to the bank for real, so it refuses to start under CI and
+// asks the operator to confirm first.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aynifx/bank-scraper/internal/config"
+	"github.com/aynifx/bank-scraper/internal/credmgr/crypto"
+	credservice "github.com/aynifx/bank-scraper/internal/credmgr/service"
+	"github.com/aynifx/bank-scraper/internal/logging"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank"
+	"github.com/aynifx/bank-scraper/internal/scraper/bank/bbva"
+	"github.com/aynifx/bank-scraper/internal/scraper/testutil"
+	"github.com/aynifx/bank-scraper/internal/store"
+	"github.com/joho/godotenv"
+)
+
+// scenario is one recorded flow. Scenarios run in order on one session.
+type scenario struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, s bank.Scraper, creds map[string]string, st *state) (any, error)
+}
+
+// state carries what earlier scenarios found to later ones.
+type state struct {
+	accountID string
+}
+
+// scenarios mirror the recordings the replay tests load.
+var scenarios = []scenario{
+	{Name: "login-success", Description: "Senda login with valid credentials", Run: func(ctx context.Context, s bank.Scraper, creds map[string]string, _ *state) (any, error) {
+		_, err := s.Login(ctx, creds)
+		return nil, err // the session is random per run; nothing to assert
+	}},
+	{Name: "accounts-page", Description: "Accounts page with every account's balances", Run: func(ctx context.Context, s bank.Scraper, _ map[string]string, st *state) (any, error) {
+		balances, err := s.GetBalance(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(balances) == 0 {
+			return nil, errors.New("no accounts found")
+		}
+		st.accountID = balances[0].AccountID
+		for i := range balances {
+			balances[i].FetchedAt = time.Time{} // differs per run
+		}
+		return balances, nil
+	}},
+	{Name: "transactions-all", Description: "Latest 50 transactions of the first account", Run: func(ctx context.Context, s bank.Scraper, _ map[string]string, st *state) (any, error) {
+		return s.GetTransactions(ctx, st.accountID, 50)
+	}},
+	{Name: "logout", Description: "Logout from the dashboard", Run: func(ctx context.Context, s bank.Scraper, _ map[string]string, _ *state) (any, error) {
+		return nil, s.Logout(ctx)
+	}},
+}
+
+func main() {
+	defer logging.Init()()
+	bankCode := flag.String("bank", "", "Bank code: bbva")
+	outputDir := flag.String("output", "", "Output directory (default: internal/scraper/bank/{bank}/testdata/recordings)")
+	headless := flag.Bool("headless", false, "Run the browser headless (default: visible, so the run can be supervised)")
+	portalVersion := flag.String("portal-version", "", "Portal version to note in the manifest, if the portal shows one")
+	redact := flag.String("redact", "", "Comma-separated names the pages show to redact (company, signatories)")
+	flag.Parse()
+
+	if err := run(*bankCode, *outputDir, *portalVersion, *headless, strings.Split(*redact, ",")); err != nil {
+		slog.Error("record scenarios", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func run(bankCode, outDir, portalVersion string, headless bool, names []string) error {
+	if bankCode == "" {
+		printUsage()
+		os.Exit(1)
+	}
+	if os.Getenv("CI") != "" {
+		return errors.New("refusing to log in to a live bank under CI")
+	}
+	bankCode = strings.ToLower(bankCode)
+	if outDir == "" {
+		outDir = filepath.Join("internal", "scraper", "bank", bankCode, "testdata", "recordings")
+	}
+
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("load .env: %w", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	creds, err := loadCredentials(ctx, cfg, strings.ToUpper(bankCode))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("This logs in to the LIVE %s portal with the stored credential and\n", strings.ToUpper(bankCode))
+	fmt.Printf("overwrites the recordings in %s:\n", outDir)
+	for _, sc := range scenarios {
+		fmt.Printf("  - %s\n", sc.Name)
+	}
+	fmt.Printf("Type %q to continue: ", bankCode)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(strings.ToLower(input)) != bankCode {
+		return errors.New("not confirmed")
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	rec := testutil.NewRecorder()
+	rec.SetVerbose(slog.Default().Enabled(ctx, slog.LevelDebug))
+
+	var s bank.Scraper
+	switch bankCode {
+	case "bbva":
+		s, err = bbva.NewScraper(
+			bbva.WithHijacker(rec.Middleware()),
+			bbva.WithReadOnly(true),
+			bbva.WithHeadless(headless),
+			bbva.WithTimeout(cfg.ScraperTimeout),
+		)
+	default:
+		return fmt.Errorf("unsupported bank: %s", bankCode)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	manifest, err := testutil.LoadManifest(outDir)
+	if err != nil {
+		return err
+	}
+
+	st := &state{}
+	var recorded []recording
+	for _, sc := range scenarios {
+		slog.Info("recording", slog.String("scenario", sc.Name))
+		result, err := sc.Run(ctx, s, creds, st)
+		har := rec.Take()
+		if err != nil {
+			return fmt.Errorf("%s: %w (nothing written)", sc.Name, err)
+		}
+		recorded = append(recorded, recording{scenario: sc, har: har, result: result})
+	}
+
+	// Every scenario's pages may show what a later one found (the accounts
+	// page's numbers on the dashboard), so redact them all at once.
+	redactor := testutil.NewRedactor()
+	for _, name := range names {
+		redactor.AddName(name)
+	}
+	for _, r := range recorded {
+		register(redactor, r.result)
+	}
+	if err := save(outDir, recorded, redactor, manifest, portalVersion); err != nil {
+		return err
+	}
+
+	slog.Info("done; review the diff before committing: names the scraper doesn't return are only redacted if passed with -redact")
+	return nil
+}
+
+// recording is a scenario's traffic and result, not yet written.
+type recording struct {
+	scenario scenario
+	har      *testutil.HARLog
+	result   any
+}
+
+// register adds the account numbers and balances of a scenario's result to
+// redactor.
+func register(redactor *testutil.Redactor, result any) {
+	switch result := result.(type) {
+	case []bank.Balance:
+		for _, b := range result {
+			redactor.AddAccount(b.AccountID)
+			redactor.AddAmount(b.AvailableBalance)
+			redactor.AddAmount(b.CurrentBalance)
+		}
+	case []bank.Transaction:
+		for _, tx := range result {
+			if tx.BalanceAfter != nil {
+				redactor.AddAmount(*tx.BalanceAfter)
+			}
+		}
+	}
+}
+
+// redactResult returns result with redactor's amounts replaced. Its strings
+// are replaced once it is marshaled.
+func redactResult(redactor *testutil.Redactor, result any) any {
+	switch result := result.(type) {
+	case []bank.Balance:
+		out := slices.Clone(result)
+		for i := range out {
+			out[i].AvailableBalance = redactor.Amount(out[i].AvailableBalance)
+			out[i].CurrentBalance = redactor.Amount(out[i].CurrentBalance)
+		}
+		return out
+	case []bank.Transaction:
+		out := slices.Clone(result)
+		for i := range out {
+			out[i].Amount = redactor.Amount(out[i].Amount)
+			if out[i].BalanceAfter != nil {
+				after := redactor.Amount(*out[i].BalanceAfter)
+				out[i].BalanceAfter = &after
+			}
+		}
+		return out
+	}
+	return result
+}
+
+// loadCredentials reads the bank's active credential through the credential
+// manager, the same path the API uses.
+func loadCredentials(ctx context.Context, cfg *config.Config, bankCode string) (map[string]string, error) {
+	keys, err := crypto.LoadKeyring(cfg.EncryptionKey, store.DefaultProfile, cfg.ProfileEncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("parse encryption key: %w", err)
+	}
+	db, err := store.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	defer db.Close()
+
+	pool := db.Pool()
+	logger := slog.Default()
+	credSvc := credservice.NewCredentialService(
+		store.NewCredentialRepo(pool),
+		credservice.NewAuditWriter(store.NewAuditLogRepo(pool), logger),
+		keys, nil, logger,
+	)
+	creds, err := credSvc.GetCredentials(ctx, bankCode)
+	if err != nil {
+		return nil, fmt.Errorf("get %s credentials: %w", bankCode, err)
+	}
+	return creds, nil
+}
+
+// save writes each scenario's sanitized and redacted HAR and, if it
+// returned anything, its redacted expected result, listing them in the
+// manifest. The files are written to a temporary directory first and moved
+// into dir once all of them are, so a failure leaves dir as it was.
+func save(dir string, recorded []recording, redactor *testutil.Redactor, manifest *testutil.Manifest, portalVersion string) error {
+	tmp, err := os.MkdirTemp(dir, ".record-*")
+	if err != nil {
+		return fmt.Errorf("create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	var files []string
+	for _, r := range recorded {
+		name := r.scenario.Name
+		harPath := testutil.ScenarioPath(tmp, name)
+		if err := testutil.SaveHAR(harPath, redactor.HAR(testutil.SanitizeHAR(r.har))); err != nil {
+			return err
+		}
+		files = append(files, filepath.Base(harPath))
+
+		if r.result != nil {
+			data, err := json.MarshalIndent(redactResult(redactor, r.result), "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshal %s result: %w", name, err)
+			}
+			expected := name + ".expected.json"
+			if err := os.WriteFile(filepath.Join(tmp, expected), []byte(redactor.Text(string(data))+"\n"), 0o644); err != nil {
+				return fmt.Errorf("write %s: %w", expected, err)
+			}
+			files = append(files, expected)
+		}
+
+		manifest.Put(testutil.Scenario{
+			Name:          name,
+			Description:   r.scenario.Description,
+			PortalVersion: portalVersion,
+			Created:       time.Now().UTC().Truncate(time.Second),
+			Entries:       len(r.har.Entries),
+		})
+	}
+	if err := testutil.SaveManifest(tmp, manifest); err != nil {
+		return err
+	}
+	files = append(files, testutil.ManifestName)
+
+	for _, f := range files {
+		if err := os.Rename(filepath.Join(tmp, f), filepath.Join(dir, f)); err != nil {
+			return fmt.Errorf("move %s into place: %w", f, err)
+		}
+		slog.Info("saved", slog.String("path", filepath.Join(dir, f)))
+	}
+	return nil
+}
+
+func printUsage() {
+	fmt.Println("record-scenario - Refresh replay recordings from a live, supervised run")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  go run ./scripts/record-scenario -bank=bbva")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -bank      Bank code (bbva)")
+	fmt.Println("  -output    Output directory (default: internal/scraper/bank/{bank}/testdata/recordings)")
+	fmt.Println("  -headless  Run the browser headless")
+	fmt.Println("  -portal-version  Portal version to note in the manifest")
+	fmt.Println("  -redact    Comma-separated names to redact from the recordings")
+	fmt.Println("  -v, -q     Debug logging (every recorded request), or warnings and errors only")
+	fmt.Println("  -log-json  Log JSON lines; -log-file=PATH appends logs to PATH")
+}
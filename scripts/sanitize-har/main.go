@@ -9,13 +9,17 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 
+	"github.com/aynifx/bank-scraper/internal/logging"
 	"github.com/aynifx/bank-scraper/internal/scraper/testutil"
 )
 
 func main() {
+	defer logging.Init()()
+
 	// Conventional path flags
 	bankCode := flag.String("bank", "", "Bank code: bbva, interbank, bcp")
 	scenario := flag.String("scenario", "", "Scenario name (e.g., login_success)")
@@ -49,42 +53,39 @@ func main() {
 
 	// Check input file exists
 	if _, err := os.Stat(inPath); os.IsNotExist(err) {
-		fmt.Printf("Error: Input file not found: %s\n", inPath)
+		slog.Error("input file not found", slog.String("path", inPath))
 		os.Exit(1)
 	}
 
-	fmt.Printf("Loading HAR file: %s\n", inPath)
-
 	// Load HAR (auto-detects Chrome vs simplified format)
 	har, err := testutil.LoadHAR(inPath)
 	if err != nil {
-		fmt.Printf("Error loading HAR: %v\n", err)
+		slog.Error("load HAR", slog.String("path", inPath), slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	fmt.Printf("Loaded %d entries\n", len(har.Entries))
+	slog.Info("loaded HAR", slog.String("path", inPath), slog.Int("entries", len(har.Entries)))
 
 	// Sanitize
 	sanitized := testutil.SanitizeHAR(har)
 
 	// Count redactions
 	redactionCount := countRedactions(har, sanitized)
-	fmt.Printf("Redacted %d sensitive values\n", redactionCount)
+	slog.Info("redacted sensitive values", slog.Int("count", redactionCount))
 
 	if *dryRun {
-		fmt.Println("\n[DRY RUN] No changes written.")
+		slog.Info("dry run: no changes written")
 		printRedactionSummary(har, sanitized)
 		return
 	}
 
 	// Save sanitized HAR
 	if err := testutil.SaveHAR(outPath, sanitized); err != nil {
-		fmt.Printf("Error saving HAR: %v\n", err)
+		slog.Error("save HAR", slog.String("path", outPath), slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	fmt.Printf("Sanitized HAR saved to: %s\n", outPath)
-	fmt.Println("\nSafe to commit!")
+	slog.Info("sanitized HAR saved; safe to commit", slog.String("path", outPath))
 }
 
 func printUsage() {
@@ -101,6 +102,7 @@ func printUsage() {
 	fmt.Println("  -input     Input HAR file path")
 	fmt.Println("  -output    Output HAR file path (defaults to input)")
 	fmt.Println("  -dry-run   Show redactions without modifying file")
+	fmt.Println("  -v, -q     Debug logging, or warnings and errors only (-log-json, -log-file=PATH)")
 }
 
 func countRedactions(original, sanitized *testutil.HARLog) int {
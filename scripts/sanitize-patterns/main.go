@@ -4,9 +4,12 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+
+	"github.com/aynifx/bank-scraper/internal/logging"
 )
 
 var sanitizePatterns = []struct {
@@ -45,12 +48,13 @@ var sanitizePatterns = []struct {
 }
 
 func main() {
+	defer logging.Init()()
 	bankCode := flag.String("bank", "", "Bank code: bbva, interbank, bcp")
 	dryRun := flag.Bool("dry-run", false, "Show what would be changed without modifying files")
 	flag.Parse()
 
 	if *bankCode == "" {
-		fmt.Println("Usage: go run main.go -bank=bbva [--dry-run]")
+		fmt.Fprintln(os.Stderr, "Usage: go run ./scripts/sanitize-patterns -bank=bbva [-dry-run] [-v|-q] [-log-json] [-log-file=PATH]")
 		os.Exit(1)
 	}
 
@@ -58,64 +62,52 @@ func main() {
 
 	files, err := filepath.Glob(filepath.Join(fixturesDir, "*.html"))
 	if err != nil || len(files) == 0 {
-		fmt.Printf("No HTML files found in %s\n", fixturesDir)
+		slog.Error("no HTML fixtures found", slog.String("dir", fixturesDir))
 		os.Exit(1)
 	}
 
-	fmt.Printf("🔒 Sanitizing fixtures for %s\n", *bankCode)
-	if *dryRun {
-		fmt.Println("    (DRY RUN - no files will be modified)")
-	}
-	fmt.Println()
-
+	slog.Info("sanitizing fixtures", slog.String("bank", *bankCode), slog.Bool("dry_run", *dryRun))
 	for _, file := range files {
 		sanitizeFile(file, *dryRun)
 	}
 
-	fmt.Println()
-	fmt.Println("✅ Sanitization complete!")
 	if *dryRun {
-		fmt.Println("    Run without --dry-run to apply changes")
+		slog.Info("dry run complete; run without -dry-run to apply changes")
+		return
 	}
+	slog.Info("sanitization complete")
 }
 
 func sanitizeFile(path string, dryRun bool) {
 	content, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Printf("❌ Error reading %s: %v\n", path, err)
+		slog.Error("read fixture", slog.String("file", path), slog.Any("error", err))
 		return
 	}
 
-	original := string(content)
-	sanitized := original
-	changes := []string{}
-
+	sanitized := string(content)
+	changed := false
 	for _, pattern := range sanitizePatterns {
-		if pattern.Pattern.MatchString(sanitized) {
-			matches := pattern.Pattern.FindAllString(sanitized, -1)
-			sanitized = pattern.Pattern.ReplaceAllString(sanitized, pattern.Replacement)
-			changes = append(changes, fmt.Sprintf("  - %s: %d matched", pattern.Description, len(matches)))
+		matches := pattern.Pattern.FindAllString(sanitized, -1)
+		if len(matches) == 0 {
+			continue
 		}
+		sanitized = pattern.Pattern.ReplaceAllString(sanitized, pattern.Replacement)
+		changed = true
+		slog.Info("sensitive data found", slog.String("file", filepath.Base(path)),
+			slog.String("pattern", pattern.Description), slog.Int("matches", len(matches)))
 	}
 
-	filename := filepath.Base(path)
-
-	if len(changes) == 0 {
-		fmt.Printf("📄 %s: No sensitive data found\n", filename)
+	if !changed {
+		slog.Debug("no sensitive data found", slog.String("file", filepath.Base(path)))
 		return
 	}
-
-	fmt.Printf("📄 %s: Found sensitive data\n", filename)
-	for _, change := range changes {
-		fmt.Println(change)
+	if dryRun {
+		return
 	}
-
-	// Check if we should write to the original file
-	if !dryRun {
-		if err := os.WriteFile(path, []byte(sanitized), 0o644); err != nil {
-			fmt.Printf("    ❌ Error writing %s: %v\n ", path, err)
-		} else {
-			fmt.Println("    ✅ Sanitized and saved: ", path)
-		}
+	if err := os.WriteFile(path, []byte(sanitized), 0o644); err != nil {
+		slog.Error("write fixture", slog.String("file", path), slog.Any("error", err))
+		return
 	}
+	slog.Info("sanitized", slog.String("file", path))
 }